@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/service"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// errUsage 参数错误，已输出用法说明
+var errUsage = errors.New("参数错误")
+
+// command 运维子命令
+type command struct {
+	summary string
+	run     func(cfg *config.Config, args []string) error
+}
+
+// commands 支持的子命令，不带子命令时等同于 serve
+var commands = map[string]command{
+	"serve":        {"启动 API 服务（默认）", func(cfg *config.Config, _ []string) error { serve(cfg); return nil }},
+	"migrate":      {"执行数据库迁移：up、down、to、force、version", migrateCommand},
+	"create-admin": {"创建全局管理员，邮箱已注册时提升为全局管理员", createAdminCommand},
+	"seed":         {"创建演示工作区（团队、工作流状态和 Issue）", seedCommand},
+	"reindex":      {"重建派生数据（Issue 层级闭包表）", reindexCommand},
+	"run-jobs":     {"立即执行一次定时任务：audit-retention、ldap-sync", runJobsCommand},
+	"backup":       {"将工作区（数据和对象存储文件）导出为备份文件", backupCommand},
+	"restore":      {"校验备份文件并恢复工作区", restoreCommand},
+}
+
+// commandOrder 用法说明中子命令的顺序
+var commandOrder = []string{"serve", "migrate", "create-admin", "seed", "reindex", "run-jobs", "backup", "restore"}
+
+// runCommand 执行子命令，返回进程退出码
+func runCommand(cfg *config.Config, name string, args []string) int {
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return 0
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "未知命令: %s\n\n", name)
+		printUsage()
+		return 2
+	}
+	if err := cmd.run(cfg, args); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			return 2
+		}
+		fmt.Fprintf(os.Stderr, "%s 失败: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+// printUsage 输出子命令列表
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "用法: server [命令] [参数]")
+	fmt.Fprintln(os.Stderr, "\n命令:")
+	for _, name := range commandOrder {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(os.Stderr, "\n使用 server <命令> -h 查看命令参数")
+}
+
+// openDB 连接数据库，运维命令中只输出错误级别的 SQL 日志
+func openDB(cfg *config.Config) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(cfg.DatabaseURL), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Error),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库失败: %w", err)
+	}
+	return db, nil
+}
+
+// createAdminCommand 创建或提升全局管理员
+// 未通过 -password 指定密码时从标准输入读取一行，避免密码出现在进程列表和 shell 历史中
+func createAdminCommand(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	email := fs.String("email", "", "管理员邮箱（必填）")
+	username := fs.String("username", "", "用户名，新建用户时必填")
+	name := fs.String("name", "", "显示名称，默认与用户名相同")
+	password := fs.String("password", "", "密码，为空时从标准输入读取")
+	workspace := fs.String("workspace", "", "新用户加入的工作区标识，不存在时自动创建（默认 default）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	userStore := store.NewUserStore(db)
+
+	// 只有新建用户才需要用户名和密码
+	if _, err := userStore.GetUserByEmail(ctx, strings.TrimSpace(*email)); errors.Is(err, gorm.ErrRecordNotFound) {
+		if *username == "" {
+			return fmt.Errorf("新建用户需要指定 -username")
+		}
+		if *password == "" {
+			fmt.Fprint(os.Stderr, "密码: ")
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				return fmt.Errorf("读取密码失败: %w", err)
+			}
+			*password = strings.TrimRight(line, "\r\n")
+		}
+	}
+
+	adminService := service.NewAdminService(userStore, store.NewWorkspaceStore(db), store.NewWorkspaceMemberStore(db))
+	user, created, err := adminService.CreateGlobalAdmin(ctx, &service.CreateAdminParams{
+		Email:         *email,
+		Username:      *username,
+		Name:          *name,
+		Password:      *password,
+		WorkspaceSlug: *workspace,
+	})
+	if err != nil {
+		return err
+	}
+	if created {
+		fmt.Printf("已创建全局管理员 %s（%s），ID: %s\n", user.Username, user.Email, user.ID)
+	} else {
+		fmt.Printf("已将 %s（%s）提升为全局管理员，ID: %s\n", user.Username, user.Email, user.ID)
+	}
+	return nil
+}
+
+// reindexCommand 重建派生数据
+// 目前只有 Issue 层级闭包表需要重建；Issue 编号和搜索均在查询时计算，没有需要重建的计数器或索引
+func reindexCommand(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("reindex", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法: server reindex [closure]")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	targets := fs.Args()
+	if len(targets) == 0 {
+		targets = []string{"closure"}
+	}
+	for _, target := range targets {
+		if target != "closure" {
+			fs.Usage()
+			return errUsage
+		}
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	rows, err := store.NewIssueStore(db).RebuildClosure(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已重建 Issue 层级闭包表，共 %d 行\n", rows)
+	return nil
+}
+
+// runJobsCommand 立即执行一次定时任务，不指定任务时执行全部
+func runJobsCommand(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("run-jobs", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法: server run-jobs [audit-retention] [ldap-sync]")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	jobs := fs.Args()
+	if len(jobs) == 0 {
+		jobs = []string{"audit-retention", "ldap-sync"}
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	userStore := store.NewUserStore(db)
+	auditService := service.NewAuditService(store.NewAuditLogStore(db), userStore, cfg)
+
+	for _, job := range jobs {
+		switch job {
+		case "audit-retention":
+			deleted, err := auditService.PurgeExpired(ctx)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("audit-retention: 已清理 %d 条过期审计日志\n", deleted)
+		case "ldap-sync":
+			workspaceStore := store.NewWorkspaceStore(db)
+			workspaceMemberStore := store.NewWorkspaceMemberStore(db)
+			jwtService := service.NewJWTService(cfg)
+			sessionService := service.NewSessionService(store.NewSessionStore(db), userStore, workspaceMemberStore, store.NewOAuthStore(db), jwtService, cfg)
+			ldapService := service.NewLDAPServiceWithAudit(cfg, service.NewLDAPDirectory(cfg), userStore, workspaceStore, store.NewTeamStore(db), store.NewTeamMemberStore(db), store.NewUserIdentityStore(db), jwtService, sessionService, nil, nil, auditService)
+			if !ldapService.Enabled() {
+				fmt.Println("ldap-sync: LDAP 未启用，跳过")
+				continue
+			}
+			report, err := ldapService.Sync(ctx, false)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("ldap-sync: 用户 %d，用户组 %d，变更 %v，错误 %d\n", report.Users, report.Groups, report.Counts, len(report.Errors))
+		default:
+			fs.Usage()
+			return errUsage
+		}
+	}
+	return nil
+}
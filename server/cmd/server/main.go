@@ -3,8 +3,7 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,15 +11,17 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/liwei0526vip/mylinear/internal/config"
 	"github.com/liwei0526vip/mylinear/internal/handler"
+	"github.com/liwei0526vip/mylinear/internal/logging"
+	"github.com/liwei0526vip/mylinear/internal/metrics"
 	"github.com/liwei0526vip/mylinear/internal/middleware"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/ratelimit"
 	apiRouter "github.com/liwei0526vip/mylinear/internal/router"
 	"github.com/liwei0526vip/mylinear/internal/service"
 	"github.com/liwei0526vip/mylinear/internal/store"
+	"github.com/liwei0526vip/mylinear/internal/tracing"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -30,23 +31,82 @@ func main() {
 	// 加载配置
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("加载配置失败: %v", err)
+		fatal("加载配置失败", err)
 	}
 
+	// 初始化日志
+	if err := logging.Setup(logging.Config{Level: cfg.LogLevel, Format: cfg.LogFormat}, os.Stdout); err != nil {
+		fatal("日志配置错误", err)
+	}
+
+	// 指定子命令时执行运维命令，否则启动 API 服务
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(cfg, os.Args[1], os.Args[2:]))
+	}
+	serve(cfg)
+}
+
+// serve 启动 API 服务，收到中断信号后优雅关闭
+func serve(cfg *config.Config) {
+	// 初始化链路追踪
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    cfg.TracingExporter,
+		Endpoint:    cfg.TracingEndpoint,
+		ServiceName: cfg.TracingServiceName,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		fatal("TRACING_EXPORTER 配置错误", err)
+	}
+	tracingEnabled := cfg.TracingExporter != "" && cfg.TracingExporter != tracing.ExporterNone
+
 	// 连接数据库
 	db, err := gorm.Open(postgres.Open(cfg.DatabaseURL), &gorm.Config{})
 	if err != nil {
-		log.Printf("警告: 数据库连接失败: %v", err)
+		slog.Warn("数据库连接失败", "error", err)
 		db = nil
 	}
+	if db != nil && cfg.MetricsEnabled {
+		if err := db.Use(metrics.NewGormPlugin()); err != nil {
+			slog.Warn("注册数据库指标插件失败", "error", err)
+		}
+	}
+	if db != nil && tracingEnabled {
+		if err := db.Use(tracing.NewGormPlugin()); err != nil {
+			slog.Warn("注册数据库链路追踪插件失败", "error", err)
+		}
+	}
 
 	// 连接 Redis
 	rdb := redis.NewClient(&redis.Options{
 		Addr: parseRedisAddr(cfg.RedisURL),
 	})
+	if cfg.MetricsEnabled {
+		rdb.AddHook(metrics.NewRedisHook())
+	}
+	if tracingEnabled {
+		rdb.AddHook(tracing.NewRedisHook())
+	}
 	ctx := context.Background()
 	if err := rdb.Ping(ctx).Err(); err != nil {
-		log.Printf("警告: Redis 连接失败: %v", err)
+		slog.Warn("Redis 连接失败", "error", err)
+	}
+
+	// 初始化限流（计数保存在 Redis 中，Redis 不可用时放行请求）
+	authRateRule, err := ratelimit.ParseRule(cfg.RateLimitAuth)
+	if err != nil {
+		fatal("RATE_LIMIT_AUTH 配置错误", err)
+	}
+	writeRateRule, err := ratelimit.ParseRule(cfg.RateLimitWrite)
+	if err != nil {
+		fatal("RATE_LIMIT_WRITE 配置错误", err)
+	}
+	var limiter ratelimit.Limiter
+	if cfg.RateLimitEnabled {
+		limiter, err = ratelimit.NewRedisLimiter(rdb, ratelimit.Algorithm(cfg.RateLimitAlgorithm))
+		if err != nil {
+			fatal("RATE_LIMIT_ALGORITHM 配置错误", err)
+		}
 	}
 
 	// 检查数据库健康状态
@@ -64,7 +124,7 @@ func main() {
 		// 执行数据库迁移
 		if dbHealthy && os.Getenv("SKIP_MIGRATION") != "true" {
 			if err := runMigrations(cfg.DatabaseURL); err != nil {
-				log.Printf("警告: 数据库迁移失败: %v", err)
+				slog.Warn("数据库迁移失败", "error", err)
 			}
 		}
 	}
@@ -77,11 +137,48 @@ func main() {
 
 	// 创建路由
 	router := gin.New()
-	router.Use(gin.Recovery())
+	// 只信任配置的反向代理转发的客户端 IP，否则客户端可以伪造 X-Forwarded-For 绕过按 IP 限流
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		fatal("TRUSTED_PROXIES 配置错误", err)
+	}
+	// 访问日志注册在 Recovery 之前，panic 恢复后返回的 500 也会记录
+	router.Use(middleware.RequestID(), middleware.AccessLog(), gin.Recovery())
+	if tracingEnabled {
+		router.Use(middleware.Tracing())
+	}
+
+	// Prometheus 指标：配置 METRICS_ADDR 时在单独的端口上提供，否则挂载在 API 端口
+	var metricsSrv *http.Server
+	if cfg.MetricsEnabled {
+		router.Use(middleware.Metrics())
+		if cfg.MetricsAddr != "" {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", metrics.Handler(cfg.MetricsToken))
+			metricsSrv = &http.Server{Addr: cfg.MetricsAddr, Handler: mux}
+		} else {
+			router.GET("/metrics", gin.WrapH(metrics.Handler(cfg.MetricsToken)))
+		}
+	}
+
+	// 初始化 AvatarService（可选，需要 MinIO）
+	avatarService, err := service.NewAvatarService(avatarConfig(cfg))
+	if err != nil {
+		slog.Warn("AvatarService 初始化失败，头像上传功能不可用", "error", err)
+	}
 
-	// 注册健康检查端点
-	healthHandler := handler.NewHealthHandler(dbHealthy)
+	// 注册健康检查端点：/livez 只表示进程存活，/readyz 实时检查各依赖
+	healthService := service.NewHealthService(cfg.HealthCheckTimeout,
+		service.DatabaseHealthCheck(db),
+		service.MigrationHealthCheck(db),
+		service.RedisHealthCheck(rdb),
+		service.StorageHealthCheck(avatarService),
+	)
+	healthHandler := handler.NewHealthHandlerWithService(dbHealthy, healthService)
+	router.GET("/livez", healthHandler.Live)
+	router.GET("/readyz", healthHandler.Ready)
 	v1 := router.Group("/api/v1")
+	// 认证后的写请求按用户、API Key 或 OAuth 应用限流
+	v1.Use(middleware.LimitWrites(limiter, writeRateRule))
 	{
 		v1.GET("/health", healthHandler.Check)
 	}
@@ -91,25 +188,33 @@ func main() {
 		// 初始化 Store
 		userStore := store.NewUserStore(db)
 		workspaceStore := store.NewWorkspaceStore(db)
+		workspaceMemberStore := store.NewWorkspaceMemberStore(db)
 		teamStore := store.NewTeamStore(db)
 		teamMemberStore := store.NewTeamMemberStore(db)
 		issueStore := store.NewIssueStore(db)
 		issueSubscriptionStore := store.NewIssueSubscriptionStore(db)
 		activityStore := store.NewActivityStore(db)
 		commentStore := store.NewCommentStore(db)
+		oauthStore := store.NewOAuthStore(db)
 
 		// 初始化服务
 		jwtService := service.NewJWTService(cfg)
-		authService := service.NewAuthService(userStore, workspaceStore, jwtService, rdb, cfg)
+		sessionService := service.NewSessionService(store.NewSessionStore(db), userStore, workspaceMemberStore, oauthStore, jwtService, cfg)
+		// 审计日志 Service（记录登录、权限变更等管理和安全事件）
+		auditService := service.NewAuditService(store.NewAuditLogStore(db), userStore, cfg)
+		// 未配置 SMTP 时邮件输出到日志
+		mailSender := service.NewMailSender(cfg)
+		invitationService := service.NewInvitationService(store.NewInvitationStore(db), userStore, workspaceStore, workspaceMemberStore, teamStore, teamMemberStore, mailSender, cfg)
+		authService := service.NewAuthServiceWithAudit(userStore, workspaceStore, jwtService, sessionService, invitationService, rdb, cfg, auditService)
 		userService := service.NewUserService(userStore)
-		workspaceService := service.NewWorkspaceService(workspaceStore, userStore)
+		workspaceService := service.NewWorkspaceServiceWithAudit(workspaceStore, userStore, workspaceMemberStore, sessionService, auditService)
 
 		// Workflow Service
 		workflowStateStore := store.NewWorkflowStateStore(db)
-		workflowService := service.NewWorkflowService(workflowStateStore, teamStore)
+		workflowService := service.NewWorkflowServiceWithAudit(workflowStateStore, teamStore, auditService)
 
-		teamService := service.NewTeamService(teamStore, teamMemberStore, userStore, workflowService)
-		teamMemberService := service.NewTeamMemberService(teamMemberStore, userStore, teamStore)
+		teamService := service.NewTeamServiceWithAudit(teamStore, teamMemberStore, userStore, workflowService, auditService)
+		teamMemberService := service.NewTeamMemberServiceWithAudit(teamMemberStore, userStore, teamStore, auditService)
 
 		// Label Service
 		labelStore := store.NewLabelStore(db)
@@ -128,21 +233,51 @@ func main() {
 		projectStore := store.NewProjectStore(db)
 		projectService := service.NewProjectService(projectStore, teamMemberStore, userStore)
 
-		// 初始化 AvatarService（可选，需要 MinIO）
-		var avatarService service.AvatarService
-		avatarCfg := &service.AvatarConfig{
-			Endpoint:      cfg.MinioEndpoint,
-			AccessKey:     cfg.MinioAccessKey,
-			SecretKey:     cfg.MinioSecretKey,
-			BucketName:    cfg.MinioBucket,
-			UseSSL:        cfg.MinioUseSSL,
-			AvatarBaseURL: cfg.AvatarBaseURL,
-		}
-		var err error
-		avatarService, err = service.NewAvatarService(avatarCfg)
-		if err != nil {
-			log.Printf("警告: AvatarService 初始化失败: %v，头像上传功能不可用", err)
-		}
+		// 后台任务 Service
+		jobStore := store.NewJobStore(db)
+		jobService := service.NewJobService(jobStore)
+
+		// Issue 导入导出 Service（注册导入任务处理函数）
+		issueTransferService := service.NewIssueTransferServiceWithAudit(issueStore, teamStore, teamMemberStore, workflowStateStore, labelStore, userStore, jobService, activityService, auditService)
+
+		// 外部系统导入 Service（Jira / GitHub / Linear）
+		externalReferenceStore := store.NewExternalReferenceStore(db)
+		importService := service.NewImportService(cfg.ImportUploadDir, teamStore, teamMemberStore, workflowStateStore, labelStore, userStore, issueStore, externalReferenceStore, jobService)
+
+		// Git 集成 Service（GitHub / GitLab Webhook）
+		issueLinkStore := store.NewIssueLinkStore(db)
+		gitIntegrationService := service.NewGitIntegrationService(cfg.GitHubWebhookSecret, cfg.GitLabWebhookToken, issueStore, issueLinkStore, teamStore, teamMemberStore, workflowStateStore, userStore, activityService)
+
+		// Issue 跨团队移动 Service
+		issueMoveService := service.NewIssueMoveService(issueStore, teamStore, teamMemberStore, workflowStateStore, labelStore, activityService)
+
+		// 个人 API Key Service
+		apiKeyStore := store.NewAPIKeyStore(db)
+		apiKeyService := service.NewAPIKeyService(apiKeyStore)
+
+		// OAuth2 授权服务
+		oauthService := service.NewOAuthService(oauthStore, userStore, jwtService)
+
+		// OIDC 单点登录 Service
+		userIdentityStore := store.NewUserIdentityStore(db)
+		oidcService := service.NewOIDCServiceWithAudit(cfg, userStore, workspaceStore, workspaceMemberStore, teamStore, teamMemberStore, userIdentityStore, sessionService, auditService)
+
+		// LDAP 登录和目录同步 Service
+		ldapService := service.NewLDAPServiceWithAudit(cfg, service.NewLDAPDirectory(cfg), userStore, workspaceStore, teamStore, teamMemberStore, userIdentityStore, jwtService, sessionService, jobService, rdb, auditService)
+
+		// 两步验证 Service
+		recoveryCodeStore := store.NewRecoveryCodeStore(db)
+		twoFactorService := service.NewTwoFactorServiceWithAudit(userStore, workspaceStore, recoveryCodeStore, jwtService, sessionService, rdb, cfg, auditService)
+
+		// 密码重置 Service
+		passwordResetService := service.NewPasswordResetService(userStore, store.NewPasswordResetTokenStore(db), sessionService, mailSender, rdb, cfg)
+
+		// 启动后台任务执行器，服务关闭时停止
+		jobCtx, stopJobs := context.WithCancel(context.Background())
+		defer stopJobs()
+		jobService.Start(jobCtx)
+		ldapService.StartPeriodicSync(jobCtx, cfg.LDAPSyncInterval)
+		auditService.StartRetention(jobCtx)
 
 		// 初始化处理器
 		authHandler := handler.NewAuthHandler(authService)
@@ -154,19 +289,26 @@ func main() {
 		// 注册认证路由（公开）
 		authGroup := v1.Group("/auth")
 		{
-			authGroup.POST("/register", authHandler.Register)
-			authGroup.POST("/login", authHandler.Login)
+			// 登录和注册按客户端 IP 限流，防止暴力破解
+			authGroup.POST("/register", middleware.RateLimitByIP(limiter, authRateRule, "register"), authHandler.Register)
+			authGroup.POST("/login", middleware.RateLimitByIP(limiter, authRateRule, "login"), authHandler.Login)
 			authGroup.POST("/refresh", authHandler.Refresh)
 			authGroup.POST("/logout", authHandler.Logout)
 		}
 
 		// 注册用户路由（需认证）
 		usersGroup := v1.Group("/users")
+		usersGroup.Use(func(c *gin.Context) {
+			// API Key 认证需要数据库连接
+			c.Set("db", db)
+		})
 		usersGroup.Use(authMiddleware)
 		{
 			usersGroup.GET("/me", userHandler.GetMe)
 			usersGroup.PATCH("/me", userHandler.UpdateMe)
 			usersGroup.POST("/me/avatar", userHandler.UploadAvatar)
+			// 修改密码会吊销所有会话，API Key / OAuth 令牌需要 admin 范围
+			usersGroup.PUT("/me/password", middleware.RequireScope(model.APIKeyScopeAdmin), authHandler.ChangePassword)
 		}
 
 		// 注册 Workspace 路由
@@ -195,8 +337,50 @@ func main() {
 
 		// 注册 Activity 路由
 		apiRouter.RegisterActivityRoutes(v1, db, jwtService, activityService)
+
+		// 注册 Issue 导入导出路由
+		apiRouter.RegisterIssueTransferRoutes(v1, db, jwtService, issueTransferService)
+
+		// 注册外部系统导入路由
+		apiRouter.RegisterImportRoutes(v1, db, jwtService, importService)
+
+		// 注册后台任务路由
+		apiRouter.RegisterJobRoutes(v1, db, jwtService, jobService)
+
+		// 注册 Git 集成路由
+		apiRouter.RegisterGitIntegrationRoutes(v1, db, jwtService, gitIntegrationService)
+
+		// 注册 Issue 跨团队移动路由
+		apiRouter.RegisterIssueMoveRoutes(v1, db, jwtService, issueMoveService)
+
+		// 注册个人 API Key 路由
+		apiRouter.RegisterAPIKeyRoutes(v1, db, jwtService, apiKeyService)
+
+		// 注册 OAuth2 授权服务路由
+		apiRouter.RegisterOAuthRoutes(v1, db, jwtService, oauthService, limiter, authRateRule)
+
+		// 注册 OIDC 单点登录路由
+		apiRouter.RegisterOIDCRoutes(v1, db, jwtService, oidcService)
+
+		// 注册 LDAP 登录和目录同步路由
+		apiRouter.RegisterLDAPRoutes(v1, db, jwtService, ldapService, limiter, authRateRule)
+
+		// 注册两步验证路由
+		apiRouter.RegisterTwoFactorRoutes(v1, db, jwtService, twoFactorService, limiter, authRateRule)
+
+		// 注册登录会话管理路由
+		apiRouter.RegisterSessionRoutes(v1, db, jwtService, sessionService)
+
+		// 注册忘记密码和重置密码路由
+		apiRouter.RegisterPasswordResetRoutes(v1, passwordResetService, limiter, authRateRule)
+
+		// 注册工作区邀请路由
+		apiRouter.RegisterInvitationRoutes(v1, db, jwtService, invitationService)
+
+		// 注册审计日志路由
+		apiRouter.RegisterAuditLogRoutes(v1, db, jwtService, auditService)
 	} else {
-		log.Println("警告: 数据库不可用，认证和用户 API 不可用")
+		slog.Warn("数据库不可用，认证和用户 API 不可用")
 	}
 
 	// 创建 HTTP 服务器
@@ -207,30 +391,49 @@ func main() {
 
 	// 启动服务器（非阻塞）
 	go func() {
-		log.Printf("服务器启动在端口 %s", cfg.Port)
+		slog.Info("服务器启动", "port", cfg.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("服务器启动失败: %v", err)
+			fatal("服务器启动失败", err)
 		}
 	}()
 
+	if metricsSrv != nil {
+		go func() {
+			slog.Info("指标接口启动", "addr", cfg.MetricsAddr)
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				slog.Warn("指标接口启动失败", "error", err)
+			}
+		}()
+	}
+
 	// 等待中断信号进行优雅关闭
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("正在关闭服务器...")
+	slog.Info("正在关闭服务器...")
 
 	// 给服务器 5 秒时间完成正在处理的请求
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Printf("服务器强制关闭: %v", err)
+		slog.Error("服务器强制关闭", "error", err)
+	}
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			slog.Warn("关闭指标接口失败", "error", err)
+		}
+	}
+
+	// 导出尚未上报的 span
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Warn("关闭链路追踪失败", "error", err)
 	}
 
 	// 关闭 Redis 连接
 	if err := rdb.Close(); err != nil {
-		log.Printf("关闭 Redis 连接失败: %v", err)
+		slog.Warn("关闭 Redis 连接失败", "error", err)
 	}
 
 	// 关闭数据库连接
@@ -238,12 +441,12 @@ func main() {
 		sqlDB, err := db.DB()
 		if err == nil {
 			if err := sqlDB.Close(); err != nil {
-				log.Printf("关闭数据库连接失败: %v", err)
+				slog.Warn("关闭数据库连接失败", "error", err)
 			}
 		}
 	}
 
-	log.Println("服务器已关闭")
+	slog.Info("服务器已关闭")
 }
 
 // parseRedisAddr 从 Redis URL 解析地址
@@ -280,37 +483,20 @@ func parseRedisAddr(redisURL string) string {
 	return addr
 }
 
-// runMigrations 执行数据库迁移
-func runMigrations(databaseURL string) error {
-	// 获取迁移文件路径
-	// 优先使用环境变量 MIGRATIONS_PATH，否则使用默认路径
-	migrationsPath := os.Getenv("MIGRATIONS_PATH")
-	if migrationsPath == "" {
-		// 默认迁移路径（相对于工作目录）
-		migrationsPath = "migrations"
-	}
-
-	// 创建迁移实例
-	m, err := migrate.New(
-		"file://"+migrationsPath,
-		databaseURL,
-	)
-	if err != nil {
-		return fmt.Errorf("创建迁移实例失败: %w", err)
-	}
-	defer m.Close()
-
-	// 执行迁移
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("执行迁移失败: %w", err)
-	}
-
-	version, dirty, err := m.Version()
-	if err != nil {
-		log.Printf("数据库迁移完成，无法获取版本: %v", err)
-	} else {
-		log.Printf("数据库迁移完成，当前版本: %d, dirty: %v", version, dirty)
+// avatarConfig 对象存储配置
+func avatarConfig(cfg *config.Config) *service.AvatarConfig {
+	return &service.AvatarConfig{
+		Endpoint:      cfg.MinioEndpoint,
+		AccessKey:     cfg.MinioAccessKey,
+		SecretKey:     cfg.MinioSecretKey,
+		BucketName:    cfg.MinioBucket,
+		UseSSL:        cfg.MinioUseSSL,
+		AvatarBaseURL: cfg.AvatarBaseURL,
 	}
+}
 
-	return nil
+// fatal 输出错误日志后退出进程
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
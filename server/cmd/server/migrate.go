@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/liwei0526vip/mylinear/internal/config"
+)
+
+// newMigrate 创建迁移实例
+func newMigrate(databaseURL string) (*migrate.Migrate, error) {
+	// 获取迁移文件路径
+	// 优先使用环境变量 MIGRATIONS_PATH，否则使用默认路径
+	migrationsPath := os.Getenv("MIGRATIONS_PATH")
+	if migrationsPath == "" {
+		// 默认迁移路径（相对于工作目录）
+		migrationsPath = "migrations"
+	}
+
+	m, err := migrate.New(
+		"file://"+migrationsPath,
+		databaseURL,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("创建迁移实例失败: %w", err)
+	}
+	return m, nil
+}
+
+// runMigrations 执行数据库迁移
+func runMigrations(databaseURL string) error {
+	m, err := newMigrate(databaseURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	// 执行迁移
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("执行迁移失败: %w", err)
+	}
+
+	version, dirty, err := m.Version()
+	if err != nil {
+		slog.Warn("数据库迁移完成，无法获取版本", "error", err)
+	} else {
+		slog.Info("数据库迁移完成", "version", version, "dirty", dirty)
+	}
+
+	return nil
+}
+
+// migrateCommand 执行 migrate 子命令：
+//
+//	migrate up              执行所有未执行的迁移
+//	migrate down [N|all]    回滚 N 个版本（默认 1），all 回滚全部
+//	migrate to <version>    迁移到指定版本（向上或向下）
+//	migrate force <version> 强制设置版本并清除 dirty 状态（不执行 SQL）
+//	migrate version         查看当前版本
+func migrateCommand(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "用法: server migrate up | down [N|all] | to <version> | force <version> | version")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	m, err := newMigrate(cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	action, rest := fs.Arg(0), fs.Args()[1:]
+	switch action {
+	case "up":
+		err = m.Up()
+	case "down":
+		switch {
+		case len(rest) == 0:
+			err = m.Steps(-1)
+		case rest[0] == "all":
+			err = m.Down()
+		default:
+			var n int
+			n, err = strconv.Atoi(rest[0])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("无效的回滚步数: %s", rest[0])
+			}
+			err = m.Steps(-n)
+		}
+	case "to":
+		if len(rest) != 1 {
+			return fmt.Errorf("请指定目标版本")
+		}
+		version, perr := strconv.ParseUint(rest[0], 10, 64)
+		if perr != nil {
+			return fmt.Errorf("无效的版本号: %s", rest[0])
+		}
+		err = m.Migrate(uint(version))
+	case "force":
+		if len(rest) != 1 {
+			return fmt.Errorf("请指定版本")
+		}
+		version, perr := strconv.Atoi(rest[0])
+		if perr != nil {
+			return fmt.Errorf("无效的版本号: %s", rest[0])
+		}
+		err = m.Force(version)
+	case "version":
+	default:
+		fs.Usage()
+		return errUsage
+	}
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("执行迁移失败: %w", err)
+	}
+
+	version, dirty, err := m.Version()
+	switch {
+	case errors.Is(err, migrate.ErrNilVersion):
+		fmt.Println("当前版本: 无（尚未执行迁移）")
+	case err != nil:
+		return fmt.Errorf("获取迁移版本失败: %w", err)
+	default:
+		fmt.Printf("当前版本: %d, dirty: %v\n", version, dirty)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/service"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/gorm"
+)
+
+// seedTeam 演示团队及其 Issue
+type seedTeam struct {
+	name        string
+	key         string
+	description string
+	issues      []seedIssue
+}
+
+// seedIssue 演示 Issue，state 为工作流状态类型
+type seedIssue struct {
+	title    string
+	state    model.StateType
+	priority int
+}
+
+// demoTeams 演示工作区中的团队和 Issue
+var demoTeams = []seedTeam{
+	{
+		name:        "Engineering",
+		key:         "ENG",
+		description: "产品研发",
+		issues: []seedIssue{
+			{"搭建 CI 流水线", model.StateTypeCompleted, 2},
+			{"登录页支持 SSO", model.StateTypeStarted, 1},
+			{"Issue 列表支持按标签筛选", model.StateTypeUnstarted, 3},
+			{"优化通知推送延迟", model.StateTypeBacklog, 3},
+			{"修复附件上传偶发失败", model.StateTypeUnstarted, 2},
+		},
+	},
+	{
+		name:        "Design",
+		key:         "DES",
+		description: "产品设计",
+		issues: []seedIssue{
+			{"整理组件库色板", model.StateTypeStarted, 3},
+			{"设计空状态插画", model.StateTypeBacklog, 4},
+			{"看板视图交互评审", model.StateTypeCompleted, 2},
+		},
+	},
+}
+
+// seedCommand 创建演示工作区：团队（含默认工作流状态）和若干 Issue
+// 以 -admin 指定的全局管理员身份创建，各数据均经过服务层，与页面操作的结果一致
+func seedCommand(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	adminEmail := fs.String("admin", "", "创建演示数据的全局管理员邮箱（必填，可先用 create-admin 创建）")
+	slug := fs.String("workspace", "demo", "演示工作区标识，已存在时不做任何修改")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *adminEmail == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	db, err := openDB(cfg)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	userStore := store.NewUserStore(db)
+	workspaceStore := store.NewWorkspaceStore(db)
+	workspaceMemberStore := store.NewWorkspaceMemberStore(db)
+	teamStore := store.NewTeamStore(db)
+	teamMemberStore := store.NewTeamMemberStore(db)
+
+	admin, err := userStore.GetUserByEmail(ctx, *adminEmail)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("用户 %s 不存在", *adminEmail)
+	} else if err != nil {
+		return fmt.Errorf("获取用户失败: %w", err)
+	}
+	if admin.Role != model.RoleGlobalAdmin {
+		return fmt.Errorf("用户 %s 不是全局管理员", *adminEmail)
+	}
+
+	if _, err := workspaceStore.GetBySlug(ctx, *slug); err == nil {
+		fmt.Printf("工作区 %s 已存在，跳过\n", *slug)
+		return nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("获取工作区失败: %w", err)
+	}
+
+	jwtService := service.NewJWTService(cfg)
+	sessionService := service.NewSessionService(store.NewSessionStore(db), userStore, workspaceMemberStore, store.NewOAuthStore(db), jwtService, cfg)
+	workspaceService := service.NewWorkspaceService(workspaceStore, userStore, workspaceMemberStore, sessionService)
+	workflowService := service.NewWorkflowService(store.NewWorkflowStateStore(db), teamStore)
+	teamService := service.NewTeamService(teamStore, teamMemberStore, userStore, workflowService)
+	issueService := service.NewIssueService(store.NewIssueStore(db), store.NewIssueSubscriptionStore(db), teamMemberStore)
+
+	ctx = context.WithValue(ctx, "user_id", admin.ID)
+	ctx = context.WithValue(ctx, "user_role", admin.Role)
+	workspace, err := workspaceService.CreateWorkspace(ctx, "Demo Workspace", *slug)
+	if err != nil {
+		return err
+	}
+	// 团队创建在 ctx 中的当前工作区
+	ctx = context.WithValue(ctx, "workspace_id", workspace.ID)
+
+	total := 0
+	for _, t := range demoTeams {
+		team, err := teamService.CreateTeam(ctx, t.name, t.key, t.description, false)
+		if err != nil {
+			return fmt.Errorf("创建团队 %s 失败: %w", t.key, err)
+		}
+		states, err := workflowService.ListStates(ctx, team.ID)
+		if err != nil {
+			return fmt.Errorf("获取团队 %s 工作流状态失败: %w", t.key, err)
+		}
+		stateByType := make(map[model.StateType]uuid.UUID, len(states))
+		for _, state := range states {
+			if _, ok := stateByType[state.Type]; !ok {
+				stateByType[state.Type] = state.ID
+			}
+		}
+
+		for _, i := range t.issues {
+			if _, err := issueService.CreateIssue(ctx, &service.CreateIssueParams{
+				TeamID:   team.ID,
+				Title:    i.title,
+				StatusID: stateByType[i.state],
+				Priority: i.priority,
+			}); err != nil {
+				return fmt.Errorf("创建 Issue %q 失败: %w", i.title, err)
+			}
+			total++
+		}
+	}
+
+	fmt.Printf("已创建演示工作区 %s：%d 个团队，%d 个 Issue\n", workspace.Slug, len(demoTeams), total)
+	return nil
+}
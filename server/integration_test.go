@@ -12,11 +12,13 @@ import (
 	"github.com/google/uuid"
 	"github.com/liwei0526vip/mylinear/internal/config"
 	"github.com/liwei0526vip/mylinear/internal/handler"
+	"github.com/liwei0526vip/mylinear/internal/mail/mailtest"
 	"github.com/liwei0526vip/mylinear/internal/middleware"
 	"github.com/liwei0526vip/mylinear/internal/model"
 	"github.com/liwei0526vip/mylinear/internal/service"
 	"github.com/liwei0526vip/mylinear/internal/store"
 	"github.com/redis/go-redis/v9"
+	"gorm.io/datatypes"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -53,6 +55,9 @@ func setupIntegrationTest(t *testing.T) (*gin.Engine, *gorm.DB, *redis.Client, u
 		db.Create(&workspace)
 	}
 
+	// 允许 example.com 邮箱直接注册
+	db.Model(&workspace).Update("settings", datatypes.JSON(`{"auth":{"allowed_email_domains":["example.com"]}}`))
+
 	// 创建配置
 	cfg := &config.Config{
 		JWTSecret:        "integration-test-secret",
@@ -64,7 +69,9 @@ func setupIntegrationTest(t *testing.T) (*gin.Engine, *gorm.DB, *redis.Client, u
 	userStore := store.NewUserStore(db)
 	workspaceStore := store.NewWorkspaceStore(db)
 	jwtService := service.NewJWTService(cfg)
-	authService := service.NewAuthService(userStore, workspaceStore, jwtService, rdb, cfg)
+	sessionService := service.NewSessionService(store.NewSessionStore(db), userStore, store.NewWorkspaceMemberStore(db), store.NewOAuthStore(db), jwtService, cfg)
+	invitationService := service.NewInvitationService(store.NewInvitationStore(db), userStore, workspaceStore, store.NewWorkspaceMemberStore(db), store.NewTeamStore(db), store.NewTeamMemberStore(db), mailtest.NewSender(), cfg)
+	authService := service.NewAuthService(userStore, workspaceStore, jwtService, sessionService, invitationService, rdb, cfg)
 	userService := service.NewUserService(userStore)
 
 	// 初始化处理器
@@ -0,0 +1,335 @@
+// Package backup 定义工作区备份归档格式：gzip 压缩的 tar 包，
+// 包含每张表的 NDJSON 数据、对象存储中的文件和记录校验和的清单
+//
+// 归档结构：
+//
+//	data/<table>.ndjson   每行一条记录（整行 JSON）
+//	objects/<key>         对象存储中的文件，key 与原存储路径相同
+//	manifest.json         清单，最后写入
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FormatVersion 当前归档格式版本，格式不兼容时递增
+const FormatVersion = 1
+
+// ManifestPath 清单在归档中的路径
+const ManifestPath = "manifest.json"
+
+// maxManifestSize 清单文件的大小上限
+const maxManifestSize = 16 << 20
+
+// ErrChecksumMismatch 归档内容与清单中的校验和不一致
+var ErrChecksumMismatch = errors.New("校验和不一致")
+
+// Manifest 备份清单
+type Manifest struct {
+	// FormatVersion 归档格式版本
+	FormatVersion int `json:"format_version"`
+	// SchemaVersion 备份时的数据库迁移版本，恢复时必须一致
+	SchemaVersion uint      `json:"schema_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Workspace     Workspace `json:"workspace"`
+	// Tables 按恢复顺序排列的表
+	Tables  []Table  `json:"tables"`
+	Objects []Object `json:"objects"`
+	// Files 归档中除清单外的所有文件及其校验和
+	Files []File `json:"files"`
+}
+
+// Workspace 备份的工作区
+type Workspace struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Slug string    `json:"slug"`
+}
+
+// Table 一张表的备份数据
+type Table struct {
+	Name string `json:"name"`
+	Rows int    `json:"rows"`
+}
+
+// Object 对象存储中的文件
+type Object struct {
+	Key string `json:"key"`
+	// URL 备份时数据中引用该文件的地址，恢复时替换为新地址
+	URL         string `json:"url"`
+	ContentType string `json:"content_type,omitempty"`
+}
+
+// File 归档中的文件
+type File struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// TablePath 表数据在归档中的路径
+func TablePath(table string) string {
+	return "data/" + table + ".ndjson"
+}
+
+// ObjectPath 对象在归档中的路径
+func ObjectPath(key string) string {
+	return "objects/" + key
+}
+
+// Writer 写入备份归档
+type Writer struct {
+	gz    *gzip.Writer
+	tw    *tar.Writer
+	files []File
+	now   time.Time
+}
+
+// NewWriter 创建归档写入器，结束时必须调用 Close 写入清单
+func NewWriter(w io.Writer) *Writer {
+	gz := gzip.NewWriter(w)
+	return &Writer{gz: gz, tw: tar.NewWriter(gz), now: time.Now()}
+}
+
+// Add 写入一个文件，内容由 fill 生成
+// tar 头部需要预先知道文件大小，内容先写入临时文件并同时计算校验和
+func (w *Writer) Add(name string, fill func(io.Writer) error) (*File, error) {
+	if err := validPath(name); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp("", "mylinear-backup-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	counter := &countingWriter{}
+	if err := fill(io.MultiWriter(tmp, hash, counter)); err != nil {
+		return nil, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("读取临时文件失败: %w", err)
+	}
+
+	if err := w.tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    counter.n,
+		ModTime: w.now,
+	}); err != nil {
+		return nil, fmt.Errorf("写入归档失败: %w", err)
+	}
+	if _, err := io.Copy(w.tw, tmp); err != nil {
+		return nil, fmt.Errorf("写入归档失败: %w", err)
+	}
+
+	file := File{Path: name, Size: counter.n, SHA256: hex.EncodeToString(hash.Sum(nil))}
+	w.files = append(w.files, file)
+	return &file, nil
+}
+
+// Close 写入清单并结束归档；清单的 Files 和 FormatVersion 由写入器填写
+func (w *Writer) Close(manifest *Manifest) error {
+	manifest.FormatVersion = FormatVersion
+	manifest.Files = w.files
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化清单失败: %w", err)
+	}
+	if err := w.tw.WriteHeader(&tar.Header{
+		Name:    ManifestPath,
+		Mode:    0o644,
+		Size:    int64(len(data)),
+		ModTime: w.now,
+	}); err != nil {
+		return fmt.Errorf("写入清单失败: %w", err)
+	}
+	if _, err := w.tw.Write(data); err != nil {
+		return fmt.Errorf("写入清单失败: %w", err)
+	}
+	if err := w.tw.Close(); err != nil {
+		return fmt.Errorf("写入归档失败: %w", err)
+	}
+	return w.gz.Close()
+}
+
+// Archive 已校验的备份归档，文件解压在临时目录中，使用完毕后需要调用 Close
+type Archive struct {
+	Manifest *Manifest
+	dir      string
+	// files 归档路径 -> 临时文件名；临时文件按序号命名，不使用归档中的路径
+	files map[string]string
+}
+
+// Open 读取归档，解压到临时目录并按清单逐个校验文件大小和 SHA-256
+// 格式版本不支持、文件缺失、多出或校验和不一致时返回错误
+func Open(r io.Reader) (*Archive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("不是有效的备份文件: %w", err)
+	}
+	defer gz.Close()
+
+	dir, err := os.MkdirTemp("", "mylinear-restore-*")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时目录失败: %w", err)
+	}
+	a := &Archive{dir: dir, files: make(map[string]string)}
+	actual := make(map[string]File)
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			a.Close()
+			return nil, fmt.Errorf("读取备份文件失败: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if header.Name == ManifestPath {
+			if a.Manifest != nil {
+				a.Close()
+				return nil, errors.New("备份文件包含多个清单")
+			}
+			manifest := &Manifest{}
+			if err := json.NewDecoder(io.LimitReader(tr, maxManifestSize)).Decode(manifest); err != nil {
+				a.Close()
+				return nil, fmt.Errorf("解析清单失败: %w", err)
+			}
+			a.Manifest = manifest
+			continue
+		}
+		if err := validPath(header.Name); err != nil {
+			a.Close()
+			return nil, err
+		}
+		if _, ok := a.files[header.Name]; ok {
+			a.Close()
+			return nil, fmt.Errorf("备份文件包含重复的路径 %s", header.Name)
+		}
+		file, err := a.extract(header.Name, tr)
+		if err != nil {
+			a.Close()
+			return nil, err
+		}
+		actual[header.Name] = *file
+	}
+
+	if err := a.verify(actual); err != nil {
+		a.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// extract 将文件写入临时目录并计算校验和
+func (a *Archive) extract(name string, r io.Reader) (*File, error) {
+	tmpName := filepath.Join(a.dir, strconv.Itoa(len(a.files)))
+	f, err := os.OpenFile(tmpName, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, hash), r)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %w", name, err)
+	}
+	a.files[name] = tmpName
+	return &File{Path: name, Size: n, SHA256: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// verify 按清单校验文件
+func (a *Archive) verify(actual map[string]File) error {
+	if a.Manifest == nil {
+		return errors.New("备份文件缺少清单")
+	}
+	if a.Manifest.FormatVersion != FormatVersion {
+		return fmt.Errorf("不支持的备份格式版本 %d（当前支持 %d）", a.Manifest.FormatVersion, FormatVersion)
+	}
+
+	listed := make(map[string]bool, len(a.Manifest.Files))
+	for _, expected := range a.Manifest.Files {
+		listed[expected.Path] = true
+		file, ok := actual[expected.Path]
+		if !ok {
+			return fmt.Errorf("备份文件缺少 %s", expected.Path)
+		}
+		if file.Size != expected.Size || file.SHA256 != expected.SHA256 {
+			return fmt.Errorf("%s: %w", expected.Path, ErrChecksumMismatch)
+		}
+	}
+	for name := range actual {
+		if !listed[name] {
+			return fmt.Errorf("备份文件包含清单中没有的文件 %s", name)
+		}
+	}
+	for _, table := range a.Manifest.Tables {
+		if !listed[TablePath(table.Name)] {
+			return fmt.Errorf("备份文件缺少表 %s 的数据", table.Name)
+		}
+	}
+	for _, object := range a.Manifest.Objects {
+		if !listed[ObjectPath(object.Key)] {
+			return fmt.Errorf("备份文件缺少对象 %s", object.Key)
+		}
+	}
+	return nil
+}
+
+// Open 打开归档中的文件
+func (a *Archive) Open(name string) (*os.File, error) {
+	tmpName, ok := a.files[name]
+	if !ok {
+		return nil, fmt.Errorf("备份文件中没有 %s", name)
+	}
+	return os.Open(tmpName)
+}
+
+// Close 删除临时目录
+func (a *Archive) Close() error {
+	return os.RemoveAll(a.dir)
+}
+
+// validPath 归档路径必须是 data/ 或 objects/ 下的相对路径
+func validPath(name string) error {
+	if name == "" || path.IsAbs(name) || path.Clean(name) != name || strings.HasPrefix(name, "../") ||
+		!(strings.HasPrefix(name, "data/") || strings.HasPrefix(name, "objects/")) {
+		return fmt.Errorf("备份文件包含无效的路径 %q", name)
+	}
+	return nil
+}
+
+// countingWriter 统计写入的字节数
+type countingWriter struct {
+	n int64
+}
+
+// Write 累加字节数
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
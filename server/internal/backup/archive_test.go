@@ -0,0 +1,233 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// writeTestArchive 写入包含一张表和一个对象的归档
+func writeTestArchive(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	if _, err := w.Add(TablePath("teams"), func(out io.Writer) error {
+		_, err := io.WriteString(out, "{\"id\":\"1\"}\n{\"id\":\"2\"}\n")
+		return err
+	}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if _, err := w.Add(ObjectPath("avatars/u1/a.png"), func(out io.Writer) error {
+		_, err := out.Write([]byte{0x89, 'P', 'N', 'G'})
+		return err
+	}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	err := w.Close(&Manifest{
+		SchemaVersion: 25,
+		Workspace:     Workspace{ID: uuid.New(), Name: "Acme", Slug: "acme"},
+		Tables:        []Table{{Name: "teams", Rows: 2}},
+		Objects:       []Object{{Key: "avatars/u1/a.png", URL: "http://minio/avatars/u1/a.png", ContentType: "image/png"}},
+	})
+	if err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// rewriteArchive 解压归档，修改后重新打包
+func rewriteArchive(t *testing.T, data []byte, edit func(files map[string][]byte, manifest *Manifest)) []byte {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	files := make(map[string][]byte)
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(tr)
+		files[header.Name] = content
+		names = append(names, header.Name)
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(files[ManifestPath], manifest); err != nil {
+		t.Fatal(err)
+	}
+	edit(files, manifest)
+	files[ManifestPath], _ = json.Marshal(manifest)
+
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for name := range files {
+		if !contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))})
+		tw.Write(content)
+	}
+	tw.Close()
+	gw.Close()
+	return buf.Bytes()
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestArchive_RoundTrip(t *testing.T) {
+	archive, err := Open(bytes.NewReader(writeTestArchive(t)))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer archive.Close()
+
+	m := archive.Manifest
+	if m.FormatVersion != FormatVersion || m.SchemaVersion != 25 || m.Workspace.Slug != "acme" {
+		t.Errorf("清单不一致: %+v", m)
+	}
+	if len(m.Files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(m.Files))
+	}
+
+	f, err := archive.Open(TablePath("teams"))
+	if err != nil {
+		t.Fatalf("Open(teams) error = %v", err)
+	}
+	defer f.Close()
+	content, _ := io.ReadAll(f)
+	if string(content) != "{\"id\":\"1\"}\n{\"id\":\"2\"}\n" {
+		t.Errorf("表数据不一致: %q", content)
+	}
+
+	if _, err := archive.Open("data/missing.ndjson"); err == nil {
+		t.Error("打开不存在的文件应返回错误")
+	}
+}
+
+func TestArchive_Verify(t *testing.T) {
+	original := writeTestArchive(t)
+
+	tests := []struct {
+		name    string
+		edit    func(files map[string][]byte, m *Manifest)
+		wantErr string
+	}{
+		{
+			name: "内容被修改",
+			edit: func(files map[string][]byte, m *Manifest) {
+				files[TablePath("teams")] = []byte("{\"id\":\"3\"}\n{\"id\":\"2\"}\n")
+			},
+			wantErr: "校验和不一致",
+		},
+		{
+			name:    "格式版本不支持",
+			edit:    func(files map[string][]byte, m *Manifest) { m.FormatVersion = FormatVersion + 1 },
+			wantErr: "不支持的备份格式版本",
+		},
+		{
+			name:    "缺少文件",
+			edit:    func(files map[string][]byte, m *Manifest) { delete(files, ObjectPath("avatars/u1/a.png")) },
+			wantErr: "缺少",
+		},
+		{
+			name:    "多出文件",
+			edit:    func(files map[string][]byte, m *Manifest) { files["data/extra.ndjson"] = []byte("{}\n") },
+			wantErr: "清单中没有的文件",
+		},
+		{
+			name:    "表不在文件列表中",
+			edit:    func(files map[string][]byte, m *Manifest) { m.Tables = append(m.Tables, Table{Name: "issues"}) },
+			wantErr: "缺少表 issues",
+		},
+		{
+			name:    "路径越界",
+			edit:    func(files map[string][]byte, m *Manifest) { files["data/../../etc/passwd"] = []byte("x") },
+			wantErr: "无效的路径",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := rewriteArchive(t, original, tt.edit)
+			_, err := Open(bytes.NewReader(data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Open() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// stripManifest 删除归档中的清单
+func stripManifest(t *testing.T, data []byte) []byte {
+	t.Helper()
+	gz, _ := gzip.NewReader(bytes.NewReader(data))
+	tr := tar.NewReader(gz)
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			break
+		}
+		if header.Name == ManifestPath {
+			continue
+		}
+		content, _ := io.ReadAll(tr)
+		tw.WriteHeader(header)
+		tw.Write(content)
+	}
+	tw.Close()
+	gw.Close()
+	return buf.Bytes()
+}
+
+func TestArchive_MissingManifest(t *testing.T) {
+	_, err := Open(bytes.NewReader(stripManifest(t, writeTestArchive(t))))
+	if err == nil || !strings.Contains(err.Error(), "缺少清单") {
+		t.Errorf("Open() error = %v, want 缺少清单", err)
+	}
+}
+
+func TestArchive_ChecksumError(t *testing.T) {
+	data := rewriteArchive(t, writeTestArchive(t), func(files map[string][]byte, m *Manifest) {
+		m.Files[0].SHA256 = strings.Repeat("0", 64)
+	})
+	_, err := Open(bytes.NewReader(data))
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Open() error = %v, want ErrChecksumMismatch", err)
+	}
+}
+
+func TestArchive_NotGzip(t *testing.T) {
+	if _, err := Open(strings.NewReader("not an archive")); err == nil {
+		t.Error("非 gzip 数据应返回错误")
+	}
+}
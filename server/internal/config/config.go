@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -19,21 +20,144 @@ type Config struct {
 	RedisURL string
 
 	// 服务配置
-	Port string
+	Port    string
 	GinMode string
 
 	// MinIO 配置
-	MinioEndpoint   string
-	MinioAccessKey  string
-	MinioSecretKey  string
-	MinioUseSSL     bool
-	MinioBucket     string
-	AvatarBaseURL   string
+	MinioEndpoint  string
+	MinioAccessKey string
+	MinioSecretKey string
+	MinioUseSSL    bool
+	MinioBucket    string
+	AvatarBaseURL  string
 
 	// JWT 配置
 	JWTSecret        string
 	JWTAccessExpiry  time.Duration
 	JWTRefreshExpiry time.Duration
+
+	// 两步验证配置
+	// TOTPIssuer 验证器应用中显示的发行方名称
+	TOTPIssuer string
+	// TOTPEncryptionKey 加密保存 TOTP 密钥，为空时使用 JWT_SECRET（更换 JWT_SECRET 会使已绑定的验证器失效）
+	TOTPEncryptionKey string
+
+	// 邮件配置（SMTPHost 为空时邮件只输出到日志）
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// 密码重置配置
+	// PasswordResetURL 前端重置密码页面地址，邮件中的链接为 <PasswordResetURL>?token=<令牌>
+	PasswordResetURL    string
+	PasswordResetExpiry time.Duration
+
+	// 工作区邀请配置
+	// InvitationURL 前端接受邀请页面地址，邀请链接为 <InvitationURL>?token=<令牌>
+	InvitationURL    string
+	InvitationExpiry time.Duration
+
+	// 导入配置
+	ImportUploadDir string
+
+	// Git 集成配置（为空时不接收对应平台的 Webhook）
+	GitHubWebhookSecret string
+	GitLabWebhookToken  string
+
+	// OIDC 单点登录配置（OIDCIssuerURL 为空时禁用）
+	OIDCIssuerURL    string
+	OIDCClientID     string
+	OIDCClientSecret string
+	// OIDCRedirectURL IdP 回调地址，指向 /api/v1/auth/oidc/callback
+	OIDCRedirectURL string
+	OIDCScopes      []string
+	// OIDCWorkspaceID 自动创建的用户加入的工作区
+	OIDCWorkspaceID string
+	// OIDCGroupsClaim ID Token 中表示用户组的 claim
+	OIDCGroupsClaim string
+	// OIDCRoleMapping IdP 用户组 -> 工作区角色（admin / member / guest）
+	OIDCRoleMapping map[string]string
+	// OIDCTeamMapping IdP 用户组 -> 团队 Key，可追加 :admin 作为团队角色（如 ENG:admin）
+	OIDCTeamMapping map[string]string
+
+	// LDAP 登录与目录同步配置（LDAPURL 为空时禁用）
+	LDAPURL string
+	// LDAPStartTLS 在 ldap:// 连接上启用 StartTLS
+	LDAPStartTLS bool
+	// LDAPInsecureSkipVerify 跳过 TLS 证书校验（仅用于测试环境）
+	LDAPInsecureSkipVerify bool
+	// LDAPBindDN / LDAPBindPassword 用于搜索目录的服务账号
+	LDAPBindDN       string
+	LDAPBindPassword string
+	LDAPBaseDN       string
+	// LDAPUserFilter 用户条目过滤器
+	LDAPUserFilter string
+	// LDAPLoginAttribute 登录名对应的属性（OpenLDAP 通常为 uid 或 mail，AD 为 sAMAccountName）
+	LDAPLoginAttribute string
+	// LDAPUIDAttribute 用户唯一标识属性（OpenLDAP 为 entryUUID，AD 为 objectGUID），为空时使用 DN
+	LDAPUIDAttribute      string
+	LDAPEmailAttribute    string
+	LDAPNameAttribute     string
+	LDAPUsernameAttribute string
+	// LDAPGroupBaseDN 用户组搜索根，为空时使用 LDAPBaseDN
+	LDAPGroupBaseDN string
+	LDAPGroupFilter string
+	// LDAPGroupMemberAttribute 用户组中保存成员 DN 的属性
+	LDAPGroupMemberAttribute string
+	// LDAPTeamMapping 用户组 CN -> 团队 Key，可追加 :admin 作为团队角色（如 ENG:admin）
+	LDAPTeamMapping map[string]string
+	// LDAPWorkspaceID 自动创建的用户加入的工作区
+	LDAPWorkspaceID string
+	// LDAPSyncInterval 定期目录同步间隔，为 0 时只能手动触发
+	LDAPSyncInterval time.Duration
+
+	// 审计日志配置
+	// AuditLogRetention 审计日志保留时长，超过后定期清理；为 0 时永久保留
+	AuditLogRetention time.Duration
+
+	// 限流配置（计数保存在 Redis 中，Redis 不可用时放行请求）
+	RateLimitEnabled bool
+	// RateLimitAlgorithm 限流算法：sliding_window（滑动窗口）或 token_bucket（令牌桶）
+	RateLimitAlgorithm string
+	// RateLimitAuth 登录、注册等公开认证接口按客户端 IP 的限流规则，格式为 次数/时长（如 10/1m），为 0 时不限流
+	RateLimitAuth string
+	// RateLimitWrite 认证后写请求按用户、API Key 或 OAuth 应用的限流规则
+	RateLimitWrite string
+	// LoginLockoutThreshold 同一邮箱（LDAP 为登录名）连续登录或两步验证失败多少次后临时锁定，为 0 时不锁定
+	LoginLockoutThreshold int
+	// LoginLockoutDuration 锁定时长，同时也是累计失败次数的时间窗口
+	LoginLockoutDuration time.Duration
+	// TrustedProxies 信任的反向代理地址或网段，只有来自这些地址的请求才使用 X-Forwarded-For 确定客户端 IP；
+	// 默认不信任任何代理，按 IP 限流以连接的对端地址为准
+	TrustedProxies []string
+
+	// Prometheus 指标配置
+	MetricsEnabled bool
+	// MetricsToken 抓取 /metrics 时需要的 Bearer 令牌，为空时不校验
+	MetricsToken string
+	// MetricsAddr 指标接口单独监听的地址（如 127.0.0.1:9090），为空时挂载在 API 端口的 /metrics 上
+	MetricsAddr string
+
+	// 链路追踪配置
+	// TracingExporter 导出方式：none（不导出）、otlp（OTLP/HTTP 收集器）或 stdout（本地调试）
+	TracingExporter string
+	// TracingEndpoint OTLP/HTTP 收集器地址
+	TracingEndpoint string
+	// TracingServiceName 上报的服务名称
+	TracingServiceName string
+	// TracingSampleRatio 根 span 的采样比例（0~1）
+	TracingSampleRatio float64
+
+	// 日志配置
+	// LogLevel 最低输出级别：debug、info、warn 或 error
+	LogLevel string
+	// LogFormat 输出格式：text 或 json
+	LogFormat string
+
+	// HealthCheckTimeout 就绪检查中每个依赖的超时时间
+	HealthCheckTimeout time.Duration
 }
 
 // 默认配置值
@@ -52,6 +176,60 @@ const (
 	defaultJWTSecret        = ""
 	defaultJWTAccessExpiry  = 15 * time.Minute
 	defaultJWTRefreshExpiry = 7 * 24 * time.Hour
+
+	// 两步验证默认配置
+	defaultTOTPIssuer = "MyLinear"
+
+	// 邮件默认配置
+	defaultSMTPPort = "587"
+	defaultSMTPFrom = "MyLinear <noreply@localhost>"
+
+	// 密码重置默认配置
+	defaultPasswordResetURL    = "http://localhost:5173/reset-password"
+	defaultPasswordResetExpiry = time.Hour
+
+	// 工作区邀请默认配置
+	defaultInvitationURL    = "http://localhost:5173/invite"
+	defaultInvitationExpiry = 7 * 24 * time.Hour
+
+	// 导入文件默认保存目录（相对于工作目录）
+	defaultImportUploadDir = "data/imports"
+
+	// OIDC 默认配置
+	defaultOIDCScopes      = "openid,email,profile"
+	defaultOIDCGroupsClaim = "groups"
+
+	// LDAP 默认配置（适用于 OpenLDAP inetOrgPerson / groupOfNames）
+	defaultLDAPUserFilter           = "(objectClass=inetOrgPerson)"
+	defaultLDAPLoginAttribute       = "uid"
+	defaultLDAPUIDAttribute         = "entryUUID"
+	defaultLDAPEmailAttribute       = "mail"
+	defaultLDAPNameAttribute        = "cn"
+	defaultLDAPUsernameAttribute    = "uid"
+	defaultLDAPGroupFilter          = "(objectClass=groupOfNames)"
+	defaultLDAPGroupMemberAttribute = "member"
+
+	// 审计日志默认保留一年
+	defaultAuditLogRetention = 365 * 24 * time.Hour
+
+	// 限流默认配置
+	defaultRateLimitAlgorithm    = "sliding_window"
+	defaultRateLimitAuth         = "10/1m"
+	defaultRateLimitWrite        = "120/1m"
+	defaultLoginLockoutThreshold = 5
+	defaultLoginLockoutDuration  = 15 * time.Minute
+
+	// 链路追踪默认配置
+	defaultTracingExporter    = "none"
+	defaultTracingEndpoint    = "http://localhost:4318"
+	defaultTracingServiceName = "mylinear"
+
+	// 日志默认配置
+	defaultLogLevel  = "info"
+	defaultLogFormat = "text"
+
+	// 就绪检查默认超时
+	defaultHealthCheckTimeout = 2 * time.Second
 )
 
 // Load 加载配置，优先从环境变量读取，缺失时使用默认值
@@ -61,17 +239,76 @@ func Load() (*Config, error) {
 	_ = godotenv.Load()
 
 	cfg := &Config{
-		DatabaseURL:    getEnv("DATABASE_URL", defaultDatabaseURL),
-		RedisURL:       getEnv("REDIS_URL", defaultRedisURL),
-		Port:           getEnv("PORT", defaultPort),
-		GinMode:        getEnv("GIN_MODE", "debug"),
-		MinioEndpoint:  getEnv("MINIO_ENDPOINT", defaultMinioEndpoint),
-		MinioAccessKey: getEnv("MINIO_ACCESS_KEY", defaultMinioAccessKey),
-		MinioSecretKey: getEnv("MINIO_SECRET_KEY", defaultMinioSecretKey),
-		MinioUseSSL:    getEnvBool("MINIO_USE_SSL", defaultMinioUseSSL),
-		MinioBucket:    getEnv("MINIO_BUCKET", defaultMinioBucket),
-		AvatarBaseURL:  getEnv("AVATAR_BASE_URL", defaultAvatarBaseURL),
-		JWTSecret:      getEnv("JWT_SECRET", defaultJWTSecret),
+		DatabaseURL:              getEnv("DATABASE_URL", defaultDatabaseURL),
+		RedisURL:                 getEnv("REDIS_URL", defaultRedisURL),
+		Port:                     getEnv("PORT", defaultPort),
+		GinMode:                  getEnv("GIN_MODE", "debug"),
+		MinioEndpoint:            getEnv("MINIO_ENDPOINT", defaultMinioEndpoint),
+		MinioAccessKey:           getEnv("MINIO_ACCESS_KEY", defaultMinioAccessKey),
+		MinioSecretKey:           getEnv("MINIO_SECRET_KEY", defaultMinioSecretKey),
+		MinioUseSSL:              getEnvBool("MINIO_USE_SSL", defaultMinioUseSSL),
+		MinioBucket:              getEnv("MINIO_BUCKET", defaultMinioBucket),
+		AvatarBaseURL:            getEnv("AVATAR_BASE_URL", defaultAvatarBaseURL),
+		JWTSecret:                getEnv("JWT_SECRET", defaultJWTSecret),
+		TOTPIssuer:               getEnv("TOTP_ISSUER", defaultTOTPIssuer),
+		TOTPEncryptionKey:        getEnv("TOTP_ENCRYPTION_KEY", ""),
+		SMTPHost:                 getEnv("SMTP_HOST", ""),
+		SMTPPort:                 getEnv("SMTP_PORT", defaultSMTPPort),
+		SMTPUsername:             getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:             getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:                 getEnv("SMTP_FROM", defaultSMTPFrom),
+		PasswordResetURL:         getEnv("PASSWORD_RESET_URL", defaultPasswordResetURL),
+		PasswordResetExpiry:      getEnvDuration("PASSWORD_RESET_EXPIRY", defaultPasswordResetExpiry),
+		InvitationURL:            getEnv("INVITATION_URL", defaultInvitationURL),
+		InvitationExpiry:         getEnvDuration("INVITATION_EXPIRY", defaultInvitationExpiry),
+		ImportUploadDir:          getEnv("IMPORT_UPLOAD_DIR", defaultImportUploadDir),
+		GitHubWebhookSecret:      getEnv("GITHUB_WEBHOOK_SECRET", ""),
+		GitLabWebhookToken:       getEnv("GITLAB_WEBHOOK_TOKEN", ""),
+		OIDCIssuerURL:            getEnv("OIDC_ISSUER_URL", ""),
+		OIDCClientID:             getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:         getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:          getEnv("OIDC_REDIRECT_URL", ""),
+		OIDCScopes:               getEnvList("OIDC_SCOPES", defaultOIDCScopes),
+		OIDCWorkspaceID:          getEnv("OIDC_WORKSPACE_ID", ""),
+		OIDCGroupsClaim:          getEnv("OIDC_GROUPS_CLAIM", defaultOIDCGroupsClaim),
+		OIDCRoleMapping:          getEnvMap("OIDC_ROLE_MAPPING"),
+		OIDCTeamMapping:          getEnvMap("OIDC_TEAM_MAPPING"),
+		LDAPURL:                  getEnv("LDAP_URL", ""),
+		LDAPStartTLS:             getEnvBool("LDAP_START_TLS", false),
+		LDAPInsecureSkipVerify:   getEnvBool("LDAP_INSECURE_SKIP_VERIFY", false),
+		LDAPBindDN:               getEnv("LDAP_BIND_DN", ""),
+		LDAPBindPassword:         getEnv("LDAP_BIND_PASSWORD", ""),
+		LDAPBaseDN:               getEnv("LDAP_BASE_DN", ""),
+		LDAPUserFilter:           getEnv("LDAP_USER_FILTER", defaultLDAPUserFilter),
+		LDAPLoginAttribute:       getEnv("LDAP_LOGIN_ATTRIBUTE", defaultLDAPLoginAttribute),
+		LDAPUIDAttribute:         getEnv("LDAP_UID_ATTRIBUTE", defaultLDAPUIDAttribute),
+		LDAPEmailAttribute:       getEnv("LDAP_EMAIL_ATTRIBUTE", defaultLDAPEmailAttribute),
+		LDAPNameAttribute:        getEnv("LDAP_NAME_ATTRIBUTE", defaultLDAPNameAttribute),
+		LDAPUsernameAttribute:    getEnv("LDAP_USERNAME_ATTRIBUTE", defaultLDAPUsernameAttribute),
+		LDAPGroupBaseDN:          getEnv("LDAP_GROUP_BASE_DN", ""),
+		LDAPGroupFilter:          getEnv("LDAP_GROUP_FILTER", defaultLDAPGroupFilter),
+		LDAPGroupMemberAttribute: getEnv("LDAP_GROUP_MEMBER_ATTRIBUTE", defaultLDAPGroupMemberAttribute),
+		LDAPTeamMapping:          getEnvMap("LDAP_TEAM_MAPPING"),
+		LDAPWorkspaceID:          getEnv("LDAP_WORKSPACE_ID", ""),
+		LDAPSyncInterval:         getEnvDuration("LDAP_SYNC_INTERVAL", 0),
+		AuditLogRetention:        getEnvDuration("AUDIT_LOG_RETENTION", defaultAuditLogRetention),
+		RateLimitEnabled:         getEnvBool("RATE_LIMIT_ENABLED", true),
+		RateLimitAlgorithm:       getEnv("RATE_LIMIT_ALGORITHM", defaultRateLimitAlgorithm),
+		RateLimitAuth:            getEnv("RATE_LIMIT_AUTH", defaultRateLimitAuth),
+		RateLimitWrite:           getEnv("RATE_LIMIT_WRITE", defaultRateLimitWrite),
+		LoginLockoutThreshold:    getEnvInt("LOGIN_LOCKOUT_THRESHOLD", defaultLoginLockoutThreshold),
+		LoginLockoutDuration:     getEnvDuration("LOGIN_LOCKOUT_DURATION", defaultLoginLockoutDuration),
+		TrustedProxies:           getEnvList("TRUSTED_PROXIES", ""),
+		MetricsEnabled:           getEnvBool("METRICS_ENABLED", true),
+		MetricsToken:             getEnv("METRICS_TOKEN", ""),
+		MetricsAddr:              getEnv("METRICS_ADDR", ""),
+		TracingExporter:          getEnv("TRACING_EXPORTER", defaultTracingExporter),
+		TracingEndpoint:          getEnv("TRACING_ENDPOINT", defaultTracingEndpoint),
+		TracingServiceName:       getEnv("TRACING_SERVICE_NAME", defaultTracingServiceName),
+		TracingSampleRatio:       getEnvFloat("TRACING_SAMPLE_RATIO", 1),
+		LogLevel:                 getEnv("LOG_LEVEL", defaultLogLevel),
+		LogFormat:                getEnv("LOG_FORMAT", defaultLogFormat),
+		HealthCheckTimeout:       getEnvDuration("HEALTH_CHECK_TIMEOUT", defaultHealthCheckTimeout),
 	}
 
 	// 解析 JWT 过期时间配置
@@ -93,9 +330,38 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("JWT_REFRESH_EXPIRY (%v) 必须大于 JWT_ACCESS_EXPIRY (%v)", c.JWTRefreshExpiry, c.JWTAccessExpiry)
 	}
 
+	// 启用 OIDC 时必须配置客户端、回调地址和工作区
+	if c.OIDCEnabled() {
+		if c.OIDCClientID == "" || c.OIDCRedirectURL == "" || c.OIDCWorkspaceID == "" {
+			return fmt.Errorf("启用 OIDC 时必须设置 OIDC_CLIENT_ID、OIDC_REDIRECT_URL 和 OIDC_WORKSPACE_ID")
+		}
+	}
+
+	// 启用 LDAP 时必须配置搜索根和工作区
+	if c.LDAPEnabled() {
+		if c.LDAPBaseDN == "" || c.LDAPWorkspaceID == "" {
+			return fmt.Errorf("启用 LDAP 时必须设置 LDAP_BASE_DN 和 LDAP_WORKSPACE_ID")
+		}
+	}
+
 	return nil
 }
 
+// LDAPEnabled 是否启用 LDAP 登录与目录同步
+func (c *Config) LDAPEnabled() bool {
+	return c.LDAPURL != ""
+}
+
+// SMTPEnabled 是否通过 SMTP 发送邮件
+func (c *Config) SMTPEnabled() bool {
+	return c.SMTPHost != ""
+}
+
+// OIDCEnabled 是否启用 OIDC 单点登录
+func (c *Config) OIDCEnabled() bool {
+	return c.OIDCIssuerURL != ""
+}
+
 // getEnv 获取环境变量，如果不存在则返回默认值
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -116,6 +382,30 @@ func getEnvBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getEnvInt 获取整数类型环境变量
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		intValue, err := strconv.Atoi(value)
+		if err != nil {
+			return defaultValue
+		}
+		return intValue
+	}
+	return defaultValue
+}
+
+// getEnvFloat 获取浮点数类型环境变量
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		floatValue, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return defaultValue
+		}
+		return floatValue
+	}
+	return defaultValue
+}
+
 // getEnvDuration 获取时间持续时间类型环境变量
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
@@ -127,3 +417,27 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getEnvList 获取逗号分隔的列表类型环境变量，忽略空项
+func getEnvList(key, defaultValue string) []string {
+	var result []string
+	for _, item := range strings.Split(getEnv(key, defaultValue), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// getEnvMap 获取 key=value 逗号分隔的映射类型环境变量，忽略格式错误的项
+func getEnvMap(key string) map[string]string {
+	result := make(map[string]string)
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		k, v, ok := strings.Cut(item, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if ok && k != "" && v != "" {
+			result[k] = v
+		}
+	}
+	return result
+}
@@ -305,3 +305,326 @@ func TestConfig_GetMinioCredentials(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_ImportUploadDir(t *testing.T) {
+	tests := []struct {
+		name    string
+		envVars map[string]string
+		want    string
+	}{
+		{
+			name:    "使用默认导入目录",
+			envVars: map[string]string{},
+			want:    "data/imports",
+		},
+		{
+			name: "从环境变量读取导入目录",
+			envVars: map[string]string{
+				"IMPORT_UPLOAD_DIR": "/var/lib/mylinear/imports",
+			},
+			want: "/var/lib/mylinear/imports",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			for k, v := range tt.envVars {
+				os.Setenv(k, v)
+			}
+
+			cfg, _ := Load()
+
+			if cfg.ImportUploadDir != tt.want {
+				t.Errorf("ImportUploadDir = %v, want %v", cfg.ImportUploadDir, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_GitWebhookSecrets(t *testing.T) {
+	os.Clearenv()
+	cfg, _ := Load()
+	if cfg.GitHubWebhookSecret != "" || cfg.GitLabWebhookToken != "" {
+		t.Errorf("Webhook secrets should be empty by default, got %q / %q", cfg.GitHubWebhookSecret, cfg.GitLabWebhookToken)
+	}
+
+	os.Setenv("GITHUB_WEBHOOK_SECRET", "gh-secret")
+	os.Setenv("GITLAB_WEBHOOK_TOKEN", "gl-token")
+	cfg, _ = Load()
+	if cfg.GitHubWebhookSecret != "gh-secret" {
+		t.Errorf("GitHubWebhookSecret = %v, want gh-secret", cfg.GitHubWebhookSecret)
+	}
+	if cfg.GitLabWebhookToken != "gl-token" {
+		t.Errorf("GitLabWebhookToken = %v, want gl-token", cfg.GitLabWebhookToken)
+	}
+}
+
+func TestConfig_OIDC(t *testing.T) {
+	os.Clearenv()
+	cfg, _ := Load()
+	if cfg.OIDCEnabled() {
+		t.Error("OIDC should be disabled by default")
+	}
+	if len(cfg.OIDCScopes) != 3 || cfg.OIDCScopes[0] != "openid" {
+		t.Errorf("OIDCScopes = %v, want default scopes", cfg.OIDCScopes)
+	}
+	if cfg.OIDCGroupsClaim != "groups" {
+		t.Errorf("OIDCGroupsClaim = %v, want groups", cfg.OIDCGroupsClaim)
+	}
+
+	os.Setenv("OIDC_ISSUER_URL", "https://idp.example.com")
+	os.Setenv("OIDC_SCOPES", "openid, email ,,groups")
+	os.Setenv("OIDC_ROLE_MAPPING", "idp-admins=admin, idp-guests = guest,broken")
+	os.Setenv("OIDC_TEAM_MAPPING", "eng=ENG:admin")
+	cfg, _ = Load()
+	if !cfg.OIDCEnabled() {
+		t.Error("OIDC should be enabled when OIDC_ISSUER_URL is set")
+	}
+	if len(cfg.OIDCScopes) != 3 || cfg.OIDCScopes[2] != "groups" {
+		t.Errorf("OIDCScopes = %v", cfg.OIDCScopes)
+	}
+	if len(cfg.OIDCRoleMapping) != 2 || cfg.OIDCRoleMapping["idp-guests"] != "guest" {
+		t.Errorf("OIDCRoleMapping = %v", cfg.OIDCRoleMapping)
+	}
+	if cfg.OIDCTeamMapping["eng"] != "ENG:admin" {
+		t.Errorf("OIDCTeamMapping = %v", cfg.OIDCTeamMapping)
+	}
+
+	// 缺少客户端配置时校验失败
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should fail without OIDC client settings")
+	}
+	cfg.OIDCClientID = "client"
+	cfg.OIDCRedirectURL = "https://app.example.com/api/v1/auth/oidc/callback"
+	cfg.OIDCWorkspaceID = "00000000-0000-0000-0000-000000000001"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestConfig_LDAP(t *testing.T) {
+	os.Clearenv()
+	cfg, _ := Load()
+	if cfg.LDAPEnabled() {
+		t.Error("LDAP should be disabled by default")
+	}
+	if cfg.LDAPUserFilter != "(objectClass=inetOrgPerson)" || cfg.LDAPUIDAttribute != "entryUUID" {
+		t.Errorf("unexpected LDAP defaults: filter=%v uid=%v", cfg.LDAPUserFilter, cfg.LDAPUIDAttribute)
+	}
+	if cfg.LDAPSyncInterval != 0 {
+		t.Errorf("LDAPSyncInterval = %v, want 0", cfg.LDAPSyncInterval)
+	}
+
+	os.Setenv("LDAP_URL", "ldap://localhost:1389")
+	os.Setenv("LDAP_SYNC_INTERVAL", "30m")
+	os.Setenv("LDAP_TEAM_MAPPING", "engineers=ENG,leads=ENG:admin")
+	cfg, _ = Load()
+	if !cfg.LDAPEnabled() {
+		t.Error("LDAP should be enabled when LDAP_URL is set")
+	}
+	if cfg.LDAPSyncInterval != 30*time.Minute {
+		t.Errorf("LDAPSyncInterval = %v, want 30m", cfg.LDAPSyncInterval)
+	}
+	if len(cfg.LDAPTeamMapping) != 2 || cfg.LDAPTeamMapping["leads"] != "ENG:admin" {
+		t.Errorf("LDAPTeamMapping = %v", cfg.LDAPTeamMapping)
+	}
+
+	// 缺少搜索根和工作区时校验失败
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should fail without LDAP base DN")
+	}
+	cfg.LDAPBaseDN = "dc=example,dc=org"
+	cfg.LDAPWorkspaceID = "00000000-0000-0000-0000-000000000001"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestConfig_TOTP(t *testing.T) {
+	os.Clearenv()
+	cfg, _ := Load()
+	if cfg.TOTPIssuer != "MyLinear" || cfg.TOTPEncryptionKey != "" {
+		t.Errorf("unexpected TOTP defaults: issuer=%v key=%v", cfg.TOTPIssuer, cfg.TOTPEncryptionKey)
+	}
+
+	os.Setenv("TOTP_ISSUER", "Acme Linear")
+	os.Setenv("TOTP_ENCRYPTION_KEY", "totp-key")
+	cfg, _ = Load()
+	if cfg.TOTPIssuer != "Acme Linear" || cfg.TOTPEncryptionKey != "totp-key" {
+		t.Errorf("unexpected TOTP config: issuer=%v key=%v", cfg.TOTPIssuer, cfg.TOTPEncryptionKey)
+	}
+	os.Clearenv()
+}
+
+func TestConfig_MailAndPasswordReset(t *testing.T) {
+	os.Clearenv()
+	cfg, _ := Load()
+	if cfg.SMTPEnabled() || cfg.SMTPPort != "587" || cfg.SMTPFrom != "MyLinear <noreply@localhost>" {
+		t.Errorf("unexpected SMTP defaults: host=%v port=%v from=%v", cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPFrom)
+	}
+	if cfg.PasswordResetURL != "http://localhost:5173/reset-password" || cfg.PasswordResetExpiry != time.Hour {
+		t.Errorf("unexpected password reset defaults: url=%v expiry=%v", cfg.PasswordResetURL, cfg.PasswordResetExpiry)
+	}
+
+	os.Setenv("SMTP_HOST", "smtp.example.com")
+	os.Setenv("SMTP_PORT", "465")
+	os.Setenv("SMTP_USERNAME", "mailer")
+	os.Setenv("SMTP_PASSWORD", "secret")
+	os.Setenv("SMTP_FROM", "noreply@example.com")
+	os.Setenv("PASSWORD_RESET_URL", "https://linear.example.com/reset-password")
+	os.Setenv("PASSWORD_RESET_EXPIRY", "30m")
+	cfg, _ = Load()
+	if !cfg.SMTPEnabled() || cfg.SMTPPort != "465" || cfg.SMTPUsername != "mailer" || cfg.SMTPPassword != "secret" || cfg.SMTPFrom != "noreply@example.com" {
+		t.Errorf("unexpected SMTP config: %+v", cfg)
+	}
+	if cfg.PasswordResetURL != "https://linear.example.com/reset-password" || cfg.PasswordResetExpiry != 30*time.Minute {
+		t.Errorf("unexpected password reset config: url=%v expiry=%v", cfg.PasswordResetURL, cfg.PasswordResetExpiry)
+	}
+	os.Clearenv()
+}
+
+func TestConfig_Invitation(t *testing.T) {
+	os.Clearenv()
+	cfg, _ := Load()
+	if cfg.InvitationURL != "http://localhost:5173/invite" || cfg.InvitationExpiry != 7*24*time.Hour {
+		t.Errorf("unexpected invitation defaults: url=%v expiry=%v", cfg.InvitationURL, cfg.InvitationExpiry)
+	}
+
+	os.Setenv("INVITATION_URL", "https://linear.example.com/invite")
+	os.Setenv("INVITATION_EXPIRY", "72h")
+	cfg, _ = Load()
+	if cfg.InvitationURL != "https://linear.example.com/invite" || cfg.InvitationExpiry != 72*time.Hour {
+		t.Errorf("unexpected invitation config: url=%v expiry=%v", cfg.InvitationURL, cfg.InvitationExpiry)
+	}
+	os.Clearenv()
+}
+
+func TestConfig_AuditLog(t *testing.T) {
+	os.Clearenv()
+	cfg, _ := Load()
+	if cfg.AuditLogRetention != 365*24*time.Hour {
+		t.Errorf("AuditLogRetention = %v, want 8760h", cfg.AuditLogRetention)
+	}
+
+	os.Setenv("AUDIT_LOG_RETENTION", "720h")
+	cfg, _ = Load()
+	if cfg.AuditLogRetention != 720*time.Hour {
+		t.Errorf("AuditLogRetention = %v, want 720h", cfg.AuditLogRetention)
+	}
+	os.Clearenv()
+}
+
+func TestConfig_RateLimit(t *testing.T) {
+	os.Clearenv()
+	cfg, _ := Load()
+	if !cfg.RateLimitEnabled || cfg.RateLimitAlgorithm != "sliding_window" {
+		t.Errorf("RateLimitEnabled = %v, RateLimitAlgorithm = %q", cfg.RateLimitEnabled, cfg.RateLimitAlgorithm)
+	}
+	if cfg.RateLimitAuth != "10/1m" || cfg.RateLimitWrite != "120/1m" {
+		t.Errorf("RateLimitAuth = %q, RateLimitWrite = %q", cfg.RateLimitAuth, cfg.RateLimitWrite)
+	}
+	if cfg.LoginLockoutThreshold != 5 || cfg.LoginLockoutDuration != 15*time.Minute {
+		t.Errorf("LoginLockoutThreshold = %d, LoginLockoutDuration = %v", cfg.LoginLockoutThreshold, cfg.LoginLockoutDuration)
+	}
+	// 默认不信任任何代理
+	if len(cfg.TrustedProxies) != 0 {
+		t.Errorf("TrustedProxies = %v, want empty", cfg.TrustedProxies)
+	}
+
+	os.Setenv("RATE_LIMIT_ENABLED", "false")
+	os.Setenv("RATE_LIMIT_ALGORITHM", "token_bucket")
+	os.Setenv("LOGIN_LOCKOUT_THRESHOLD", "0")
+	os.Setenv("LOGIN_LOCKOUT_DURATION", "1h")
+	os.Setenv("TRUSTED_PROXIES", "127.0.0.1, 10.0.0.0/8")
+	cfg, _ = Load()
+	if cfg.RateLimitEnabled || cfg.RateLimitAlgorithm != "token_bucket" {
+		t.Errorf("RateLimitEnabled = %v, RateLimitAlgorithm = %q", cfg.RateLimitEnabled, cfg.RateLimitAlgorithm)
+	}
+	if cfg.LoginLockoutThreshold != 0 || cfg.LoginLockoutDuration != time.Hour {
+		t.Errorf("LoginLockoutThreshold = %d, LoginLockoutDuration = %v", cfg.LoginLockoutThreshold, cfg.LoginLockoutDuration)
+	}
+	if len(cfg.TrustedProxies) != 2 || cfg.TrustedProxies[1] != "10.0.0.0/8" {
+		t.Errorf("TrustedProxies = %v", cfg.TrustedProxies)
+	}
+
+	// 格式错误时使用默认值
+	os.Setenv("LOGIN_LOCKOUT_THRESHOLD", "abc")
+	cfg, _ = Load()
+	if cfg.LoginLockoutThreshold != 5 {
+		t.Errorf("LoginLockoutThreshold = %d, want 5", cfg.LoginLockoutThreshold)
+	}
+	os.Clearenv()
+}
+
+func TestConfig_Metrics(t *testing.T) {
+	os.Clearenv()
+	cfg, _ := Load()
+	if !cfg.MetricsEnabled || cfg.MetricsToken != "" || cfg.MetricsAddr != "" {
+		t.Errorf("MetricsEnabled = %v, MetricsToken = %q, MetricsAddr = %q", cfg.MetricsEnabled, cfg.MetricsToken, cfg.MetricsAddr)
+	}
+
+	os.Setenv("METRICS_ENABLED", "false")
+	os.Setenv("METRICS_TOKEN", "scrape-secret")
+	os.Setenv("METRICS_ADDR", "127.0.0.1:9090")
+	cfg, _ = Load()
+	if cfg.MetricsEnabled || cfg.MetricsToken != "scrape-secret" || cfg.MetricsAddr != "127.0.0.1:9090" {
+		t.Errorf("MetricsEnabled = %v, MetricsToken = %q, MetricsAddr = %q", cfg.MetricsEnabled, cfg.MetricsToken, cfg.MetricsAddr)
+	}
+	os.Clearenv()
+}
+
+func TestConfig_Tracing(t *testing.T) {
+	os.Clearenv()
+	cfg, _ := Load()
+	if cfg.TracingExporter != "none" || cfg.TracingEndpoint != "http://localhost:4318" || cfg.TracingServiceName != "mylinear" || cfg.TracingSampleRatio != 1 {
+		t.Errorf("TracingExporter = %q, TracingEndpoint = %q, TracingServiceName = %q, TracingSampleRatio = %v", cfg.TracingExporter, cfg.TracingEndpoint, cfg.TracingServiceName, cfg.TracingSampleRatio)
+	}
+
+	os.Setenv("TRACING_EXPORTER", "otlp")
+	os.Setenv("TRACING_ENDPOINT", "http://otel-collector:4318")
+	os.Setenv("TRACING_SAMPLE_RATIO", "0.25")
+	cfg, _ = Load()
+	if cfg.TracingExporter != "otlp" || cfg.TracingEndpoint != "http://otel-collector:4318" || cfg.TracingSampleRatio != 0.25 {
+		t.Errorf("TracingExporter = %q, TracingEndpoint = %q, TracingSampleRatio = %v", cfg.TracingExporter, cfg.TracingEndpoint, cfg.TracingSampleRatio)
+	}
+
+	// 非法的采样比例使用默认值
+	os.Setenv("TRACING_SAMPLE_RATIO", "abc")
+	cfg, _ = Load()
+	if cfg.TracingSampleRatio != 1 {
+		t.Errorf("TracingSampleRatio = %v, want 1", cfg.TracingSampleRatio)
+	}
+	os.Clearenv()
+}
+
+func TestConfig_Log(t *testing.T) {
+	os.Clearenv()
+	cfg, _ := Load()
+	if cfg.LogLevel != "info" || cfg.LogFormat != "text" {
+		t.Errorf("LogLevel = %q, LogFormat = %q", cfg.LogLevel, cfg.LogFormat)
+	}
+
+	os.Setenv("LOG_LEVEL", "debug")
+	os.Setenv("LOG_FORMAT", "json")
+	cfg, _ = Load()
+	if cfg.LogLevel != "debug" || cfg.LogFormat != "json" {
+		t.Errorf("LogLevel = %q, LogFormat = %q", cfg.LogLevel, cfg.LogFormat)
+	}
+	os.Clearenv()
+}
+
+func TestConfig_HealthCheckTimeout(t *testing.T) {
+	os.Clearenv()
+	cfg, _ := Load()
+	if cfg.HealthCheckTimeout != 2*time.Second {
+		t.Errorf("HealthCheckTimeout = %v, want 2s", cfg.HealthCheckTimeout)
+	}
+
+	os.Setenv("HEALTH_CHECK_TIMEOUT", "500ms")
+	cfg, _ = Load()
+	if cfg.HealthCheckTimeout != 500*time.Millisecond {
+		t.Errorf("HealthCheckTimeout = %v, want 500ms", cfg.HealthCheckTimeout)
+	}
+	os.Clearenv()
+}
@@ -0,0 +1,117 @@
+// Package handler 提供 HTTP 处理器
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/service"
+)
+
+// AuditLogHandler 审计日志处理器
+type AuditLogHandler struct {
+	auditService service.AuditService
+}
+
+// NewAuditLogHandler 创建审计日志处理器
+func NewAuditLogHandler(auditService service.AuditService) *AuditLogHandler {
+	return &AuditLogHandler{auditService: auditService}
+}
+
+// ListAuditLogs 查询当前工作区的审计日志（仅管理员）
+// GET /api/v1/audit-logs?actor_id=&action=&target_type=&target_id=&since=&until=&page=&page_size=
+func (h *AuditLogHandler) ListAuditLogs(c *gin.Context) {
+	query, err := parseAuditLogQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "50"))
+
+	logs, total, err := h.auditService.ListAuditLogs(contextWithUser(c), query, page, pageSize)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"audit_logs": logs,
+		"total":      total,
+		"page":       page,
+	})
+}
+
+// ExportAuditLogs 以 NDJSON 格式导出当前工作区的审计日志（仅管理员），过滤参数与列表接口一致
+// GET /api/v1/audit-logs/export
+func (h *AuditLogHandler) ExportAuditLogs(c *gin.Context) {
+	query, err := parseAuditLogQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("audit-logs-%s.ndjson", time.Now().Format("20060102-150405"))
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := h.auditService.ExportAuditLogs(contextWithUser(c), query, c.Writer); err != nil {
+		// 已开始输出时无法再返回错误响应，只能中断
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			handleError(c, err)
+		}
+		return
+	}
+
+	// 没有任何输出时也返回 200
+	if !c.Writer.Written() {
+		c.Status(http.StatusOK)
+	}
+}
+
+// parseAuditLogQuery 解析审计日志过滤参数；action 可重复或用逗号分隔，时间使用 RFC 3339 格式
+func parseAuditLogQuery(c *gin.Context) (*service.AuditLogQuery, error) {
+	query := &service.AuditLogQuery{
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+	}
+
+	if actorID := c.Query("actor_id"); actorID != "" {
+		id, err := uuid.Parse(actorID)
+		if err != nil {
+			return nil, fmt.Errorf("无效的操作者ID")
+		}
+		query.ActorID = &id
+	}
+
+	for _, value := range c.QueryArray("action") {
+		for _, action := range strings.Split(value, ",") {
+			if action = strings.TrimSpace(action); action != "" {
+				query.Actions = append(query.Actions, model.AuditAction(action))
+			}
+		}
+	}
+
+	for param, target := range map[string]**time.Time{"since": &query.Since, "until": &query.Until} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("无效的时间参数 %s，请使用 RFC 3339 格式", param)
+		}
+		*target = &t
+	}
+
+	return query, nil
+}
@@ -2,7 +2,10 @@
 package handler
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -29,6 +32,8 @@ type RegisterRequest struct {
 	Password    string `json:"password" binding:"required,min=8"`
 	Name        string `json:"name" binding:"required"`
 	WorkspaceID string `json:"workspace_id"`
+	// InviteToken 邀请链接中的令牌，提供时加入邀请所属的工作区，可不传 workspace_id
+	InviteToken string `json:"invite_token"`
 }
 
 // LoginRequest 登录请求
@@ -47,6 +52,12 @@ type LogoutRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
+// ChangePasswordRequest 修改密码请求
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
 // AuthResponse 认证响应
 type AuthResponse struct {
 	AccessToken  string   `json:"access_token"`
@@ -84,9 +95,9 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	// 解析工作区 ID
+	// 解析工作区 ID（使用邀请令牌时由邀请决定）
 	workspaceID, err := uuid.Parse(req.WorkspaceID)
-	if err != nil {
+	if err != nil && req.InviteToken == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "bad_request",
 			"message": "工作区 ID 无效",
@@ -94,14 +105,29 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	user, accessToken, refreshToken, err := h.authService.Register(c.Request.Context(), workspaceID, req.Email, req.Username, req.Password, req.Name)
+	user, accessToken, refreshToken, err := h.authService.Register(contextWithClient(c), workspaceID, req.Email, req.Username, req.Password, req.Name, req.InviteToken)
 	if err != nil {
-		switch err.Error() {
-		case "邮箱已被注册", "用户名已被使用":
+		switch {
+		case err.Error() == "邮箱已被注册" || err.Error() == "用户名已被使用":
 			c.JSON(http.StatusConflict, gin.H{
 				"error":   "conflict",
 				"message": err.Error(),
 			})
+		case errors.Is(err, service.ErrRegistrationNotAllowed):
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "registration_not_allowed",
+				"message": err.Error(),
+			})
+		case errors.Is(err, service.ErrPasswordLoginDisabled):
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "password_login_disabled",
+				"message": err.Error(),
+			})
+		case strings.Contains(err.Error(), "无效"):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "bad_request",
+				"message": err.Error(),
+			})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error":   "internal_error",
@@ -138,8 +164,20 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, accessToken, refreshToken, err := h.authService.Login(c.Request.Context(), req.Email, req.Password)
+	user, accessToken, refreshToken, err := h.authService.Login(contextWithClient(c), req.Email, req.Password)
 	if err != nil {
+		if respondTwoFactorRequired(c, err) {
+			return
+		}
+		var locked *service.AccountLockedError
+		if errors.As(err, &locked) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":   "account_locked",
+				"message": err.Error(),
+			})
+			return
+		}
 		if err.Error() == "邮箱或密码错误" {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "unauthorized",
@@ -147,6 +185,20 @@ func (h *AuthHandler) Login(c *gin.Context) {
 			})
 			return
 		}
+		if errors.Is(err, service.ErrPasswordLoginDisabled) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "password_login_disabled",
+				"message": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrUserDeactivated) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "user_deactivated",
+				"message": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "internal_error",
 			"message": "登录失败",
@@ -181,7 +233,7 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken)
+	accessToken, refreshToken, err := h.authService.RefreshToken(contextWithClient(c), req.RefreshToken)
 	if err != nil {
 		errMsg := err.Error()
 		// 认证相关错误返回 401
@@ -215,6 +267,7 @@ func isAuthError(errMsg string) bool {
 		"无效的令牌类型",
 		"用户不存在",
 		"无效的用户ID",
+		"账号已停用",
 	}
 	for _, e := range authErrors {
 		if strings.Contains(errMsg, e) {
@@ -250,6 +303,48 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	})
 }
 
+// ChangePassword 修改当前用户的密码，所有会话被吊销，返回当前客户端的新令牌
+// PUT /api/v1/users/me/password
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": "请求参数无效",
+		})
+		return
+	}
+
+	if !isValidPassword(req.NewPassword) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": "密码强度不足，需要至少8个字符，包含大小写字母和数字",
+		})
+		return
+	}
+
+	ctx := contextWithUser(c)
+	accessToken, refreshToken, err := h.authService.ChangePassword(ctx, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		if errors.Is(err, service.ErrWrongPassword) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "wrong_password",
+				"message": err.Error(),
+			})
+			return
+		}
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"access_token":  accessToken,
+			"refresh_token": refreshToken,
+		},
+	})
+}
+
 // isValidPassword 验证密码强度
 func isValidPassword(password string) bool {
 	if len(password) < 8 {
@@ -12,10 +12,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/mail/mailtest"
 	"github.com/liwei0526vip/mylinear/internal/model"
 	"github.com/liwei0526vip/mylinear/internal/service"
 	"github.com/liwei0526vip/mylinear/internal/store"
 	"github.com/redis/go-redis/v9"
+	"gorm.io/datatypes"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -62,6 +64,9 @@ func setupHandlerTest(t *testing.T) (*gin.Engine, service.AuthService, context.C
 	handlerTestRedis = rdb
 	handlerTestWorkspaceID = workspace.ID
 
+	// 允许 example.com 邮箱直接注册
+	db.Model(&workspace).Update("settings", datatypes.JSON(`{"auth":{"allowed_email_domains":["example.com"]}}`))
+
 	// 创建服务
 	cfg := &config.Config{
 		JWTSecret:        "handler-test-secret",
@@ -72,7 +77,9 @@ func setupHandlerTest(t *testing.T) (*gin.Engine, service.AuthService, context.C
 	userStore := store.NewUserStore(db)
 	workspaceStore := store.NewWorkspaceStore(db)
 	jwtService := service.NewJWTService(cfg)
-	authService := service.NewAuthService(userStore, workspaceStore, jwtService, rdb, cfg)
+	sessionService := service.NewSessionService(store.NewSessionStore(db), userStore, store.NewWorkspaceMemberStore(db), store.NewOAuthStore(db), jwtService, cfg)
+	invitationService := service.NewInvitationService(store.NewInvitationStore(db), userStore, workspaceStore, store.NewWorkspaceMemberStore(db), store.NewTeamStore(db), store.NewTeamMemberStore(db), mailtest.NewSender(), cfg)
+	authService := service.NewAuthService(userStore, workspaceStore, jwtService, sessionService, invitationService, rdb, cfg)
 
 	// 创建路由
 	router := gin.New()
@@ -223,7 +230,7 @@ func TestAuthHandler_Login_Success(t *testing.T) {
 	prefix := uuid.New().String()[:8]
 	email := prefix + "_login@example.com"
 	password := "Password123!"
-	authService.Register(ctx, handlerTestWorkspaceID, email, prefix+"_loginuser", password, "Login User")
+	authService.Register(ctx, handlerTestWorkspaceID, email, prefix+"_loginuser", password, "Login User", "")
 
 	// 登录
 	body := map[string]string{
@@ -263,7 +270,7 @@ func TestAuthHandler_Login_WrongPassword(t *testing.T) {
 	// 先注册用户
 	prefix := uuid.New().String()[:8]
 	email := prefix + "_wrongpass@example.com"
-	authService.Register(ctx, handlerTestWorkspaceID, email, prefix+"_wrongpassuser", "Password123!", "Wrong Pass User")
+	authService.Register(ctx, handlerTestWorkspaceID, email, prefix+"_wrongpassuser", "Password123!", "Wrong Pass User", "")
 
 	// 用错误密码登录
 	body := map[string]string{
@@ -291,7 +298,7 @@ func TestAuthHandler_Refresh_Success(t *testing.T) {
 
 	// 注册用户并获取刷新令牌
 	prefix := uuid.New().String()[:8]
-	_, _, refreshToken, _ := authService.Register(ctx, handlerTestWorkspaceID, prefix+"_refresh@example.com", prefix+"_refreshuser", "Password123!", "Refresh User")
+	_, _, refreshToken, _ := authService.Register(ctx, handlerTestWorkspaceID, prefix+"_refresh@example.com", prefix+"_refreshuser", "Password123!", "Refresh User", "")
 
 	// 刷新令牌
 	body := map[string]string{
@@ -318,7 +325,7 @@ func TestAuthHandler_Logout_Success(t *testing.T) {
 
 	// 注册用户并获取刷新令牌
 	prefix := uuid.New().String()[:8]
-	_, _, refreshToken, _ := authService.Register(ctx, handlerTestWorkspaceID, prefix+"_logout@example.com", prefix+"_logoutuser", "Password123!", "Logout User")
+	_, _, refreshToken, _ := authService.Register(ctx, handlerTestWorkspaceID, prefix+"_logout@example.com", prefix+"_logoutuser", "Password123!", "Logout User", "")
 
 	// 登出
 	body := map[string]string{
@@ -0,0 +1,155 @@
+// Package handler 提供 HTTP 处理器
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/service"
+)
+
+// Webhook 请求体大小上限（GitHub 最大为 25MB）
+const maxWebhookBodySize = 25 << 20
+
+// GitIntegrationHandler Git 集成处理器
+type GitIntegrationHandler struct {
+	gitService service.GitIntegrationService
+}
+
+// NewGitIntegrationHandler 创建 Git 集成处理器
+func NewGitIntegrationHandler(gitService service.GitIntegrationService) *GitIntegrationHandler {
+	return &GitIntegrationHandler{gitService: gitService}
+}
+
+// GitHubWebhook 接收 GitHub Webhook（pull_request / push）
+// POST /api/v1/webhooks/github?workspace_id=<uuid>，workspace_id 可选，多个工作区使用相同团队 Key 时需要指定
+func (h *GitIntegrationHandler) GitHubWebhook(c *gin.Context) {
+	body, ok := readWebhookBody(c)
+	if !ok {
+		return
+	}
+	if err := h.gitService.VerifyGitHubSignature(body, c.GetHeader("X-Hub-Signature-256")); err != nil {
+		handleWebhookError(c, err)
+		return
+	}
+
+	result, err := h.gitService.HandleGitHubEvent(webhookContext(c), c.GetHeader("X-GitHub-Event"), body)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GitLabWebhook 接收 GitLab Webhook（Merge Request Hook / Push Hook）
+// POST /api/v1/webhooks/gitlab?workspace_id=<uuid>，workspace_id 可选，多个工作区使用相同团队 Key 时需要指定
+func (h *GitIntegrationHandler) GitLabWebhook(c *gin.Context) {
+	body, ok := readWebhookBody(c)
+	if !ok {
+		return
+	}
+	if err := h.gitService.VerifyGitLabToken(c.GetHeader("X-Gitlab-Token")); err != nil {
+		handleWebhookError(c, err)
+		return
+	}
+
+	result, err := h.gitService.HandleGitLabEvent(webhookContext(c), c.GetHeader("X-Gitlab-Event"), body)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// webhookContext 将 URL 中指定的工作区注入上下文，Issue 标识符只在该工作区内解析
+func webhookContext(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+	if workspaceID, err := uuid.Parse(c.Query("workspace_id")); err == nil {
+		ctx = context.WithValue(ctx, "workspace_id", workspaceID)
+	}
+	return ctx
+}
+
+// readWebhookBody 读取原始请求体（签名校验需要原始字节）
+func readWebhookBody(c *gin.Context) ([]byte, bool) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBodySize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无法读取请求体"})
+		return nil, false
+	}
+	if len(body) > maxWebhookBodySize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "请求体过大"})
+		return nil, false
+	}
+	return body, true
+}
+
+// handleWebhookError 处理 Webhook 校验错误，未启用的平台返回 404
+func handleWebhookError(c *gin.Context, err error) {
+	if strings.Contains(err.Error(), "未启用") {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	handleError(c, err)
+}
+
+// GetBranchName 获取 Issue 的建议分支名
+// GET /api/v1/issues/:id/branch-name
+func (h *GitIntegrationHandler) GetBranchName(c *gin.Context) {
+	ctx := contextWithUser(c)
+
+	name, err := h.gitService.SuggestBranchName(ctx, c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"branch_name": name})
+}
+
+// ListIssueLinks 获取 Issue 关联的 PR / 提交
+// GET /api/v1/issues/:id/links
+func (h *GitIntegrationHandler) ListIssueLinks(c *gin.Context) {
+	ctx := contextWithUser(c)
+
+	links, err := h.gitService.ListIssueLinks(ctx, c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"links": links})
+}
+
+// GetGitSettings 获取团队的 Git 集成设置
+// GET /api/v1/teams/:teamId/git-settings
+func (h *GitIntegrationHandler) GetGitSettings(c *gin.Context) {
+	ctx := contextWithUser(c)
+
+	settings, err := h.gitService.GetGitSettings(ctx, c.Param("teamId"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateGitSettings 更新团队的 Git 集成设置
+// PUT /api/v1/teams/:teamId/git-settings
+func (h *GitIntegrationHandler) UpdateGitSettings(c *gin.Context) {
+	var req service.GitSettings
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	ctx := contextWithUser(c)
+	settings, err := h.gitService.UpdateGitSettings(ctx, c.Param("teamId"), &req)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liwei0526vip/mylinear/internal/service"
+)
+
+func TestGitIntegrationHandler_Webhooks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	body := []byte(`{"zen":"Keep it logically awesome."}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name         string
+		githubSecret string
+		gitlabToken  string
+		path         string
+		headers      map[string]string
+		wantStatus   int
+	}{
+		{
+			name:         "GitHub 签名正确",
+			githubSecret: "secret",
+			path:         "/api/v1/webhooks/github",
+			headers:      map[string]string{"X-GitHub-Event": "ping", "X-Hub-Signature-256": signature},
+			wantStatus:   http.StatusOK,
+		},
+		{
+			name:         "GitHub 签名错误",
+			githubSecret: "secret",
+			path:         "/api/v1/webhooks/github",
+			headers:      map[string]string{"X-GitHub-Event": "ping", "X-Hub-Signature-256": "sha256=00"},
+			wantStatus:   http.StatusUnauthorized,
+		},
+		{
+			name:       "GitHub 未启用",
+			path:       "/api/v1/webhooks/github",
+			headers:    map[string]string{"X-GitHub-Event": "ping", "X-Hub-Signature-256": signature},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:        "GitLab Token 正确（不处理的事件）",
+			gitlabToken: "token",
+			path:        "/api/v1/webhooks/gitlab",
+			headers:     map[string]string{"X-Gitlab-Event": "Note Hook", "X-Gitlab-Token": "token"},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "GitLab Token 错误",
+			gitlabToken: "token",
+			path:        "/api/v1/webhooks/gitlab",
+			headers:     map[string]string{"X-Gitlab-Event": "Push Hook", "X-Gitlab-Token": "wrong"},
+			wantStatus:  http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := service.NewGitIntegrationService(tt.githubSecret, tt.gitlabToken, nil, nil, nil, nil, nil, nil, nil)
+			h := NewGitIntegrationHandler(svc)
+			router := gin.New()
+			router.POST("/api/v1/webhooks/github", h.GitHubWebhook)
+			router.POST("/api/v1/webhooks/gitlab", h.GitLabWebhook)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, bytes.NewReader(body))
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
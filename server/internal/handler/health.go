@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/liwei0526vip/mylinear/internal/service"
 )
 
 // HealthResponse 健康检查响应结构
@@ -15,7 +16,8 @@ type HealthResponse struct {
 
 // HealthHandler 健康检查处理器
 type HealthHandler struct {
-	dbHealthy bool
+	dbHealthy     bool
+	healthService service.HealthService
 }
 
 // NewHealthHandler 创建健康检查处理器
@@ -25,6 +27,14 @@ func NewHealthHandler(dbHealthy bool) *HealthHandler {
 	}
 }
 
+// NewHealthHandlerWithService 创建支持就绪检查的健康检查处理器
+func NewHealthHandlerWithService(dbHealthy bool, healthService service.HealthService) *HealthHandler {
+	return &HealthHandler{
+		dbHealthy:     dbHealthy,
+		healthService: healthService,
+	}
+}
+
 // Check 处理 GET /api/v1/health 请求
 func (h *HealthHandler) Check(c *gin.Context) {
 	if !h.dbHealthy {
@@ -39,3 +49,26 @@ func (h *HealthHandler) Check(c *gin.Context) {
 		Status: "ok",
 	})
 }
+
+// Live 处理 GET /livez 请求：进程能响应即为存活，不检查外部依赖，避免依赖故障导致容器被反复重启
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthResponse{
+		Status: "ok",
+	})
+}
+
+// Ready 处理 GET /readyz 请求：实时检查 Postgres、Redis、MinIO 和迁移状态
+// 关键依赖不可用时返回 503；只有非关键依赖不可用时返回 200 并标记降级
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if h.healthService == nil {
+		h.Check(c)
+		return
+	}
+
+	report := h.healthService.Readiness(c.Request.Context())
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
@@ -1,12 +1,14 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/liwei0526vip/mylinear/internal/service"
 )
 
 func TestHealthCheck(t *testing.T) {
@@ -92,3 +94,75 @@ func TestHealthCheck_ResponseContentType(t *testing.T) {
 		t.Errorf("Content-Type = %v, want application/json; charset=utf-8", contentType)
 	}
 }
+
+// stubHealthService 返回固定的就绪检查结果
+type stubHealthService struct {
+	report *service.ReadinessReport
+}
+
+func (s *stubHealthService) Readiness(context.Context) *service.ReadinessReport {
+	return s.report
+}
+
+func TestHealthHandler_Live(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// 依赖不可用时存活检查仍然成功
+	router := gin.New()
+	h := NewHealthHandler(false)
+	router.GET("/livez", h.Live)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Live() status = %v, want 200", w.Code)
+	}
+}
+
+func TestHealthHandler_Ready(t *testing.T) {
+	tests := []struct {
+		name       string
+		report     *service.ReadinessReport
+		wantStatus int
+	}{
+		{
+			name:       "全部正常",
+			report:     &service.ReadinessReport{Status: service.ReadinessOK},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "降级不影响就绪",
+			report:     &service.ReadinessReport{Status: service.ReadinessDegraded, Degraded: []string{"avatar_upload"}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "关键依赖不可用",
+			report:     &service.ReadinessReport{Status: service.ReadinessError},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			router := gin.New()
+			h := NewHealthHandlerWithService(true, &stubHealthService{report: tt.report})
+			router.GET("/readyz", h.Ready)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("Ready() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+
+			var response service.ReadinessReport
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("Failed to parse response: %v", err)
+			}
+			if response.Status != tt.report.Status || len(response.Degraded) != len(tt.report.Degraded) {
+				t.Errorf("Ready() response = %+v", response)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Package handler 提供 HTTP 处理器
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liwei0526vip/mylinear/internal/service"
+)
+
+// 外部导入文件大小上限（100MB）
+const maxExternalImportSize = 100 << 20
+
+// ImportHandler 外部系统导入处理器
+type ImportHandler struct {
+	importService service.ImportService
+}
+
+// NewImportHandler 创建外部系统导入处理器
+func NewImportHandler(importService service.ImportService) *ImportHandler {
+	return &ImportHandler{importService: importService}
+}
+
+// StartImport 上传导出文件并创建导入任务
+// POST /api/v1/teams/:teamId/imports
+// multipart 表单：file（必填）、source（jira_xml / jira_csv / github_json / linear_csv）、options（JSON 对象，可选）
+func (h *ImportHandler) StartImport(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请选择要导入的文件"})
+		return
+	}
+	if fileHeader.Size > maxExternalImportSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "文件大小不能超过 100MB"})
+		return
+	}
+
+	source := c.PostForm("source")
+	if source == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "缺少导入来源"})
+		return
+	}
+
+	var options service.ImportOptions
+	if raw := c.PostForm("options"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &options); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的导入选项"})
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无法读取文件"})
+		return
+	}
+	defer file.Close()
+
+	ctx := contextWithUser(c)
+	job, err := h.importService.StartImport(ctx, &service.ImportParams{
+		TeamID:   c.Param("teamId"),
+		Source:   source,
+		Filename: fileHeader.Filename,
+		File:     file,
+		Options:  options,
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"job_id":       job.ID,
+		"status":       job.Status,
+		"total":        job.Total,
+		"progress_url": fmt.Sprintf("/api/v1/jobs/%s", job.ID),
+	})
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/service"
+)
+
+// InvitationHandler 工作区邀请处理器
+type InvitationHandler struct {
+	invitationService service.InvitationService
+}
+
+// NewInvitationHandler 创建工作区邀请处理器
+func NewInvitationHandler(invitationService service.InvitationService) *InvitationHandler {
+	return &InvitationHandler{invitationService: invitationService}
+}
+
+// CreateInvitationRequest 创建邀请请求
+// 不提供 email 时生成可多人使用的邀请链接
+type CreateInvitationRequest struct {
+	Email   string     `json:"email" binding:"omitempty,email"`
+	Role    model.Role `json:"role"`
+	TeamIDs []string   `json:"team_ids"`
+	// ExpiresInDays 有效期天数（1-30），不提供时使用默认有效期
+	ExpiresInDays int `json:"expires_in_days" binding:"omitempty,min=1,max=30"`
+}
+
+// CreateInvitation 邀请邮箱或生成邀请链接，令牌只在响应中返回一次
+// POST /api/v1/invitations
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	var req CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	ctx := contextWithUser(c)
+	invitation, err := h.invitationService.CreateInvitation(ctx, &service.CreateInvitationParams{
+		Email:     req.Email,
+		Role:      req.Role,
+		TeamIDs:   req.TeamIDs,
+		ExpiresIn: time.Duration(req.ExpiresInDays) * 24 * time.Hour,
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// ListInvitations 获取当前工作区待接受的邀请
+// GET /api/v1/invitations
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	ctx := contextWithUser(c)
+
+	invitations, err := h.invitationService.ListInvitations(ctx)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// ResendInvitation 重新发送邀请邮件，旧链接失效
+// POST /api/v1/invitations/:id/resend
+func (h *InvitationHandler) ResendInvitation(c *gin.Context) {
+	ctx := contextWithUser(c)
+
+	invitation, err := h.invitationService.ResendInvitation(ctx, c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, invitation)
+}
+
+// RevokeInvitation 撤销邀请
+// DELETE /api/v1/invitations/:id
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	ctx := contextWithUser(c)
+
+	if err := h.invitationService.RevokeInvitation(ctx, c.Param("id")); err != nil {
+		handleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetInvitation 获取邀请信息，供接受邀请页面展示（公开）
+// GET /api/v1/auth/invitations/:token
+func (h *InvitationHandler) GetInvitation(c *gin.Context) {
+	invitation, err := h.invitationService.GetInvitation(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, invitation)
+}
+
+// AcceptInvitation 已登录的用户（如其他工作区的成员）接受邀请加入工作区
+// 加入后通过 POST /api/v1/workspaces/:workspaceId/switch 切换到该工作区
+// POST /api/v1/auth/invitations/:token/accept
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	ctx := contextWithUser(c)
+
+	member, err := h.invitationService.AcceptInvitation(ctx, c.Param("token"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, member)
+}
@@ -137,6 +137,45 @@ func (h *IssueHandler) GetIssue(c *gin.Context) {
 	})
 }
 
+// LookupIssue 通过标识符查找 Issue
+// GET /api/v1/issues/lookup/:identifier
+// 使用历史标识符（团队 Key 修改或 Issue 移动前）时 redirected 为 true，identifier 为当前标识符
+func (h *IssueHandler) LookupIssue(c *gin.Context) {
+	requested := c.Param("identifier")
+
+	ctx := h.contextWithAuth(c)
+
+	issue, err := h.issueService.GetIssueByIdentifier(ctx, requested)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	teamKey := ""
+	if issue.Team != nil {
+		teamKey = issue.Team.Key
+	}
+	identifier := issue.Identifier(teamKey)
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":          issue.ID,
+		"identifier":  identifier,
+		"redirected":  !strings.EqualFold(identifier, strings.TrimSpace(requested)),
+		"team_id":     issue.TeamID,
+		"number":      issue.Number,
+		"title":       issue.Title,
+		"description": issue.Description,
+		"status_id":   issue.StatusID,
+		"priority":    issue.Priority,
+		"assignee_id": issue.AssigneeID,
+		"project_id":  issue.ProjectID,
+		"position":    issue.Position,
+		"created_at":  issue.CreatedAt,
+		"updated_at":  issue.UpdatedAt,
+		"created_by":  issue.CreatedByID,
+	})
+}
+
 // ListIssues 获取 Issue 列表
 // GET /api/v1/teams/:teamId/issues
 func (h *IssueHandler) ListIssues(c *gin.Context) {
@@ -390,6 +429,11 @@ func (h *IssueHandler) contextWithAuth(c *gin.Context) context.Context {
 		ctx = context.WithValue(ctx, "user_role", userRole)
 	}
 
+	workspaceID := middleware.GetCurrentWorkspaceID(c)
+	if workspaceID != uuid.Nil {
+		ctx = context.WithValue(ctx, "workspace_id", workspaceID)
+	}
+
 	return ctx
 }
 
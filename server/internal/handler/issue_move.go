@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liwei0526vip/mylinear/internal/service"
+)
+
+// IssueMoveHandler Issue 跨团队移动处理器
+type IssueMoveHandler struct {
+	moveService service.IssueMoveService
+}
+
+// NewIssueMoveHandler 创建 Issue 跨团队移动处理器
+func NewIssueMoveHandler(moveService service.IssueMoveService) *IssueMoveHandler {
+	return &IssueMoveHandler{moveService: moveService}
+}
+
+// MoveIssueRequest 移动 Issue 请求
+type MoveIssueRequest struct {
+	TeamID           string            `json:"team_id" binding:"required"`
+	StatusID         *string           `json:"status_id"`
+	LabelMapping     map[string]string `json:"label_mapping"`
+	IncludeSubIssues bool              `json:"include_sub_issues"`
+}
+
+// MoveIssue 将 Issue 移动到其他团队
+// POST /api/v1/issues/:id/move
+func (h *IssueMoveHandler) MoveIssue(c *gin.Context) {
+	var req MoveIssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	ctx := contextWithUser(c)
+	result, err := h.moveService.MoveIssue(ctx, c.Param("id"), &service.MoveIssueParams{
+		TargetTeamID:     req.TeamID,
+		StatusID:         req.StatusID,
+		LabelMapping:     req.LabelMapping,
+		IncludeSubIssues: req.IncludeSubIssues,
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -62,6 +62,7 @@ func TestMain(m *testing.M) {
 		&model.WorkflowState{},
 		&model.Label{},
 		&model.Issue{},
+		&model.IssueIdentifierAlias{},
 		&model.IssueSubscription{},
 		&model.Notification{},
 		&model.NotificationPreference{},
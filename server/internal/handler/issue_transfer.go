@@ -0,0 +1,161 @@
+// Package handler 提供 HTTP 处理器
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liwei0526vip/mylinear/internal/service"
+)
+
+// 导入文件大小上限（10MB）
+const maxIssueImportSize = 10 << 20
+
+// IssueTransferHandler Issue 导入导出处理器
+type IssueTransferHandler struct {
+	transferService service.IssueTransferService
+}
+
+// NewIssueTransferHandler 创建 Issue 导入导出处理器
+func NewIssueTransferHandler(transferService service.IssueTransferService) *IssueTransferHandler {
+	return &IssueTransferHandler{transferService: transferService}
+}
+
+// ExportIssues 导出 Issue
+// GET /api/v1/teams/:teamId/issues/export?format=csv|ndjson
+func (h *IssueTransferHandler) ExportIssues(c *gin.Context) {
+	teamID := c.Param("teamId")
+	format := c.DefaultQuery("format", service.IssueTransferFormatCSV)
+
+	// 过滤参数与列表接口保持一致
+	filter := &service.IssueFilter{}
+	if statusID := c.Query("status_id"); statusID != "" {
+		filter.StatusID = &statusID
+	}
+	if priorityStr := c.Query("priority"); priorityStr != "" {
+		priority, err := strconv.Atoi(priorityStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的优先级"})
+			return
+		}
+		filter.Priority = &priority
+	}
+	if assigneeID := c.Query("assignee_id"); assigneeID != "" {
+		filter.AssigneeID = &assigneeID
+	}
+	if projectID := c.Query("project_id"); projectID != "" {
+		filter.ProjectID = &projectID
+	}
+	if cycleID := c.Query("cycle_id"); cycleID != "" {
+		filter.CycleID = &cycleID
+	}
+	if labelIDs := c.QueryArray("label_id"); len(labelIDs) > 0 {
+		filter.LabelIDs = labelIDs
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == service.IssueTransferFormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	filename := fmt.Sprintf("issues-%s.%s", time.Now().Format("20060102-150405"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	ctx := contextWithUser(c)
+	if err := h.transferService.ExportIssues(ctx, teamID, filter, format, c.Writer); err != nil {
+		// 已开始输出时无法再返回错误响应，只能中断
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			handleError(c, err)
+		}
+		return
+	}
+
+	// 没有任何输出时也返回 200
+	if !c.Writer.Written() {
+		c.Status(http.StatusOK)
+	}
+}
+
+// ImportIssues 导入 Issue
+// POST /api/v1/teams/:teamId/issues/import
+// multipart 表单：file（必填）、format、mapping（JSON 对象）、dry_run
+func (h *IssueTransferHandler) ImportIssues(c *gin.Context) {
+	teamID := c.Param("teamId")
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请选择要导入的文件"})
+		return
+	}
+	if fileHeader.Size > maxIssueImportSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "文件大小不能超过 10MB"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无法读取文件"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxIssueImportSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无法读取文件"})
+		return
+	}
+
+	// 未指定格式时根据扩展名判断
+	format := c.PostForm("format")
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(fileHeader.Filename)) {
+		case ".ndjson", ".jsonl":
+			format = service.IssueTransferFormatNDJSON
+		default:
+			format = service.IssueTransferFormatCSV
+		}
+	}
+
+	var mapping map[string]string
+	if raw := c.PostForm("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的字段映射"})
+			return
+		}
+	}
+
+	dryRun, _ := strconv.ParseBool(c.DefaultPostForm("dry_run", "false"))
+
+	ctx := contextWithUser(c)
+	report, job, err := h.transferService.ImportIssues(ctx, &service.IssueImportParams{
+		TeamID:  teamID,
+		Format:  format,
+		Data:    data,
+		Mapping: mapping,
+		DryRun:  dryRun,
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	if report != nil {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"job_id": job.ID,
+		"status": job.Status,
+		"total":  job.Total,
+	})
+}
@@ -0,0 +1,50 @@
+// Package handler 提供 HTTP 处理器
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liwei0526vip/mylinear/internal/service"
+)
+
+// JobHandler 后台任务处理器
+type JobHandler struct {
+	jobService service.JobService
+}
+
+// NewJobHandler 创建后台任务处理器
+func NewJobHandler(jobService service.JobService) *JobHandler {
+	return &JobHandler{jobService: jobService}
+}
+
+// GetJob 获取任务状态和结果
+// GET /api/v1/jobs/:id
+func (h *JobHandler) GetJob(c *gin.Context) {
+	ctx := contextWithUser(c)
+
+	job, err := h.jobService.GetJob(ctx, c.Param("id"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	var result interface{}
+	if len(job.Result) > 0 {
+		result = json.RawMessage(job.Result)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":          job.ID,
+		"type":        job.Type,
+		"status":      job.Status,
+		"progress":    job.Progress,
+		"total":       job.Total,
+		"result":      result,
+		"error":       job.Error,
+		"created_at":  job.CreatedAt,
+		"started_at":  job.StartedAt,
+		"finished_at": job.FinishedAt,
+	})
+}
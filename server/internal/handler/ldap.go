@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liwei0526vip/mylinear/internal/ldap"
+	"github.com/liwei0526vip/mylinear/internal/service"
+)
+
+// LDAPHandler LDAP 登录和目录同步处理器
+type LDAPHandler struct {
+	ldapService service.LDAPService
+}
+
+// NewLDAPHandler 创建 LDAP 处理器
+func NewLDAPHandler(ldapService service.LDAPService) *LDAPHandler {
+	return &LDAPHandler{ldapService: ldapService}
+}
+
+// LDAPLoginRequest LDAP 登录请求
+type LDAPLoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// LDAPSyncRequest LDAP 同步请求
+type LDAPSyncRequest struct {
+	DryRun bool `json:"dry_run"`
+}
+
+// Login 使用 LDAP 账号登录
+// POST /api/v1/auth/ldap/login
+func (h *LDAPHandler) Login(c *gin.Context) {
+	if !h.ldapService.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "LDAP 登录未启用"})
+		return
+	}
+
+	var req LDAPLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": "请求参数无效",
+		})
+		return
+	}
+
+	user, accessToken, refreshToken, err := h.ldapService.Login(contextWithClient(c), req.Username, req.Password)
+	if err != nil {
+		if respondTwoFactorRequired(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, ldap.ErrInvalidCredentials):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "用户名或密码错误"})
+		case errors.Is(err, service.ErrUserDeactivated):
+			c.JSON(http.StatusForbidden, gin.H{"error": "user_deactivated", "message": err.Error()})
+		case strings.Contains(err.Error(), "无权限"):
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden", "message": err.Error()})
+		case strings.Contains(err.Error(), "LDAP 认证失败"):
+			c.JSON(http.StatusBadGateway, gin.H{"error": "ldap_unavailable", "message": "LDAP 服务不可用"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal_error", "message": "登录失败"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": AuthResponse{
+			AccessToken:  accessToken,
+			RefreshToken: refreshToken,
+			User: &UserDTO{
+				ID:          user.ID.String(),
+				WorkspaceID: user.WorkspaceID.String(),
+				Email:       user.Email,
+				Username:    user.Username,
+				Name:        user.Name,
+				Role:        string(user.Role),
+			},
+		},
+	})
+}
+
+// Sync 创建 LDAP 目录同步任务（仅管理员），dry_run 为 true 时只生成变更报告
+// POST /api/v1/ldap/sync
+func (h *LDAPHandler) Sync(c *gin.Context) {
+	var req LDAPSyncRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+			return
+		}
+	}
+
+	ctx := contextWithUser(c)
+	job, err := h.ldapService.StartSync(ctx, req.DryRun)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"job_id":       job.ID,
+		"status":       job.Status,
+		"dry_run":      req.DryRun,
+		"progress_url": fmt.Sprintf("/api/v1/jobs/%s", job.ID),
+	})
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liwei0526vip/mylinear/internal/service"
+)
+
+// OAuthHandler OAuth2 授权服务处理器
+type OAuthHandler struct {
+	oauthService service.OAuthService
+}
+
+// NewOAuthHandler 创建 OAuth2 授权服务处理器
+func NewOAuthHandler(oauthService service.OAuthService) *OAuthHandler {
+	return &OAuthHandler{oauthService: oauthService}
+}
+
+// CreateOAuthApplicationRequest 注册 OAuth 应用请求
+type CreateOAuthApplicationRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	RedirectURIs []string `json:"redirect_uris" binding:"required"`
+	Scopes       []string `json:"scopes" binding:"required"`
+	Confidential *bool    `json:"confidential"`
+}
+
+// AuthorizeDecisionRequest 用户确认授权请求
+type AuthorizeDecisionRequest struct {
+	ResponseType        string `json:"response_type"`
+	ClientID            string `json:"client_id" binding:"required"`
+	RedirectURI         string `json:"redirect_uri"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+	Approve             bool   `json:"approve"`
+}
+
+// CreateApplication 注册 OAuth 应用，client_secret 只在响应中返回一次
+// POST /api/v1/oauth/applications
+func (h *OAuthHandler) CreateApplication(c *gin.Context) {
+	var req CreateOAuthApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	// 默认为机密客户端
+	confidential := req.Confidential == nil || *req.Confidential
+	ctx := contextWithUser(c)
+	app, err := h.oauthService.CreateApplication(ctx, &service.CreateOAuthApplicationParams{
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+		Scopes:       req.Scopes,
+		Confidential: confidential,
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, app)
+}
+
+// ListApplications 获取工作区的 OAuth 应用
+// GET /api/v1/oauth/applications
+func (h *OAuthHandler) ListApplications(c *gin.Context) {
+	ctx := contextWithUser(c)
+
+	apps, err := h.oauthService.ListApplications(ctx)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"applications": apps})
+}
+
+// DeleteApplication 删除 OAuth 应用
+// DELETE /api/v1/oauth/applications/:id
+func (h *OAuthHandler) DeleteApplication(c *gin.Context) {
+	ctx := contextWithUser(c)
+
+	if err := h.oauthService.DeleteApplication(ctx, c.Param("id")); err != nil {
+		handleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetAuthorization 校验授权请求，返回确认页需要的应用和权限范围
+// GET /api/v1/oauth/authorize
+func (h *OAuthHandler) GetAuthorization(c *gin.Context) {
+	req := &service.AuthorizeRequest{
+		ResponseType:        c.Query("response_type"),
+		ClientID:            c.Query("client_id"),
+		RedirectURI:         c.Query("redirect_uri"),
+		Scope:               c.Query("scope"),
+		State:               c.Query("state"),
+		CodeChallenge:       c.Query("code_challenge"),
+		CodeChallengeMethod: c.Query("code_challenge_method"),
+	}
+
+	ctx := contextWithUser(c)
+	prompt, err := h.oauthService.PrepareAuthorization(ctx, req)
+	if err != nil {
+		handleOAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, prompt)
+}
+
+// Authorize 用户确认或拒绝授权，返回前端需要跳转的回调地址
+// POST /api/v1/oauth/authorize
+func (h *OAuthHandler) Authorize(c *gin.Context) {
+	var req AuthorizeDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	ctx := contextWithUser(c)
+	redirectURI, err := h.oauthService.Authorize(ctx, &service.AuthorizeRequest{
+		ResponseType:        req.ResponseType,
+		ClientID:            req.ClientID,
+		RedirectURI:         req.RedirectURI,
+		Scope:               req.Scope,
+		State:               req.State,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+	}, req.Approve)
+	if err != nil {
+		handleOAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"redirect_uri": redirectURI})
+}
+
+// Token 令牌端点（application/x-www-form-urlencoded）
+// POST /api/v1/oauth/token
+func (h *OAuthHandler) Token(c *gin.Context) {
+	req := &service.TokenRequest{
+		OAuthClientCredentials: clientCredentials(c),
+		GrantType:              c.PostForm("grant_type"),
+		Code:                   c.PostForm("code"),
+		RedirectURI:            c.PostForm("redirect_uri"),
+		CodeVerifier:           c.PostForm("code_verifier"),
+		RefreshToken:           c.PostForm("refresh_token"),
+		Scope:                  c.PostForm("scope"),
+	}
+
+	// RFC 6749 5.1：令牌响应不得缓存
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	resp, err := h.oauthService.Token(c.Request.Context(), req)
+	if err != nil {
+		handleOAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Revoke 吊销令牌（RFC 7009）
+// POST /api/v1/oauth/revoke
+func (h *OAuthHandler) Revoke(c *gin.Context) {
+	creds := clientCredentials(c)
+	if err := h.oauthService.Revoke(c.Request.Context(), &creds, c.PostForm("token")); err != nil {
+		handleOAuthError(c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// Introspect 查询令牌状态（RFC 7662）
+// POST /api/v1/oauth/introspect
+func (h *OAuthHandler) Introspect(c *gin.Context) {
+	creds := clientCredentials(c)
+	resp, err := h.oauthService.Introspect(c.Request.Context(), &creds, c.PostForm("token"))
+	if err != nil {
+		handleOAuthError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// clientCredentials 读取客户端认证信息，HTTP Basic 优先于表单参数
+func clientCredentials(c *gin.Context) service.OAuthClientCredentials {
+	if clientID, secret, ok := c.Request.BasicAuth(); ok {
+		return service.OAuthClientCredentials{ClientID: clientID, ClientSecret: secret}
+	}
+	return service.OAuthClientCredentials{
+		ClientID:     c.PostForm("client_id"),
+		ClientSecret: c.PostForm("client_secret"),
+	}
+}
+
+// handleOAuthError 按 RFC 6749 5.2 格式返回 OAuth2 协议错误，其他错误按常规处理
+func handleOAuthError(c *gin.Context, err error) {
+	var oauthErr *service.OAuthError
+	if !errors.As(err, &oauthErr) {
+		handleError(c, err)
+		return
+	}
+
+	status := http.StatusBadRequest
+	if oauthErr.Code == service.OAuthErrInvalidClient {
+		status = http.StatusUnauthorized
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	c.JSON(status, gin.H{
+		"error":             oauthErr.Code,
+		"error_description": oauthErr.Description,
+	})
+}
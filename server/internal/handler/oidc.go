@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liwei0526vip/mylinear/internal/service"
+)
+
+const (
+	// oidcStateCookie 保存单点登录临时状态的 Cookie
+	oidcStateCookie = "mylinear_oidc"
+	// oidcStateCookiePath Cookie 只在单点登录回调中发送
+	oidcStateCookiePath = "/api/v1/auth/oidc"
+	// oidcStateCookieMaxAge 登录流程的最长时间（秒）
+	oidcStateCookieMaxAge = 600
+)
+
+// OIDCHandler OIDC 单点登录处理器
+type OIDCHandler struct {
+	oidcService service.OIDCService
+}
+
+// NewOIDCHandler 创建 OIDC 单点登录处理器
+func NewOIDCHandler(oidcService service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{oidcService: oidcService}
+}
+
+// UpdateAuthSettingsRequest 更新认证设置请求
+// 未提供的字段保持不变
+type UpdateAuthSettingsRequest struct {
+	PasswordLoginDisabled *bool     `json:"password_login_disabled"`
+	TwoFactorRequired     *bool     `json:"two_factor_required"`
+	AllowedEmailDomains   *[]string `json:"allowed_email_domains"`
+}
+
+// oidcCookieState Cookie 中保存的登录状态
+type oidcCookieState struct {
+	State        string `json:"s"`
+	Nonce        string `json:"n"`
+	CodeVerifier string `json:"v"`
+	Redirect     string `json:"r,omitempty"`
+}
+
+// Providers 获取登录页可用的登录方式
+// GET /api/v1/auth/providers
+func (h *OIDCHandler) Providers(c *gin.Context) {
+	providers, err := h.oidcService.Providers(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": providers})
+}
+
+// Login 跳转到身份提供方登录
+// GET /api/v1/auth/oidc/login?redirect=/path
+func (h *OIDCHandler) Login(c *gin.Context) {
+	if !h.oidcService.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not_found", "message": "单点登录未启用"})
+		return
+	}
+
+	loginState, authURL, err := h.oidcService.BeginLogin(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "oidc_unavailable", "message": "身份提供方不可用"})
+		return
+	}
+
+	value, err := json.Marshal(oidcCookieState{
+		State:        loginState.State,
+		Nonce:        loginState.Nonce,
+		CodeVerifier: loginState.CodeVerifier,
+		Redirect:     safeRedirect(c.Query("redirect")),
+	})
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	setOIDCStateCookie(c, base64.RawURLEncoding.EncodeToString(value), oidcStateCookieMaxAge)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback 身份提供方回调，完成登录并签发令牌
+// 登录时指定了 redirect 则跳转回前端（令牌放在 URL fragment 中），否则返回 JSON
+// GET /api/v1/auth/oidc/callback
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	cookieState, ok := readOIDCStateCookie(c)
+	// 无论成功与否，登录状态只能使用一次
+	setOIDCStateCookie(c, "", -1)
+
+	if errCode := c.Query("error"); errCode != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errCode, "message": c.Query("error_description")})
+		return
+	}
+	state := c.Query("state")
+	if !ok || state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookieState.State)) != 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_state", "message": "登录状态无效或已过期，请重新登录"})
+		return
+	}
+
+	user, accessToken, refreshToken, err := h.oidcService.CompleteLogin(contextWithClient(c), &service.OIDCLoginState{
+		State:        cookieState.State,
+		Nonce:        cookieState.Nonce,
+		CodeVerifier: cookieState.CodeVerifier,
+	}, c.Query("code"))
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "无权限"):
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden", "message": err.Error()})
+		case strings.Contains(err.Error(), "无效"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad_request", "message": err.Error()})
+		default:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized", "message": "单点登录失败"})
+		}
+		return
+	}
+
+	if cookieState.Redirect != "" {
+		fragment := url.Values{}
+		fragment.Set("access_token", accessToken)
+		fragment.Set("refresh_token", refreshToken)
+		c.Redirect(http.StatusFound, cookieState.Redirect+"#"+fragment.Encode())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": AuthResponse{
+			AccessToken:  accessToken,
+			RefreshToken: refreshToken,
+			User: &UserDTO{
+				ID:          user.ID.String(),
+				WorkspaceID: user.WorkspaceID.String(),
+				Email:       user.Email,
+				Username:    user.Username,
+				Name:        user.Name,
+				Role:        string(user.Role),
+			},
+		},
+	})
+}
+
+// GetSettings 获取工作区认证设置
+// GET /api/v1/auth/settings
+func (h *OIDCHandler) GetSettings(c *gin.Context) {
+	ctx := contextWithUser(c)
+
+	settings, err := h.oidcService.GetAuthSettings(ctx)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings 更新工作区认证设置（仅管理员）
+// PUT /api/v1/auth/settings
+func (h *OIDCHandler) UpdateSettings(c *gin.Context) {
+	var req UpdateAuthSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	ctx := contextWithUser(c)
+	settings, err := h.oidcService.GetAuthSettings(ctx)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	if req.PasswordLoginDisabled != nil {
+		settings.PasswordLoginDisabled = *req.PasswordLoginDisabled
+	}
+	if req.TwoFactorRequired != nil {
+		settings.TwoFactorRequired = *req.TwoFactorRequired
+	}
+	if req.AllowedEmailDomains != nil {
+		settings.AllowedEmailDomains = *req.AllowedEmailDomains
+	}
+
+	settings, err = h.oidcService.UpdateAuthSettings(ctx, settings)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// setOIDCStateCookie 设置或清除（maxAge < 0）登录状态 Cookie
+func setOIDCStateCookie(c *gin.Context, value string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    value,
+		Path:     oidcStateCookiePath,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.Request.TLS != nil,
+		// IdP 回调是跨站的顶层导航，Lax 允许携带 Cookie
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// readOIDCStateCookie 读取登录状态 Cookie
+func readOIDCStateCookie(c *gin.Context) (*oidcCookieState, bool) {
+	value, err := c.Cookie(oidcStateCookie)
+	if err != nil || value == "" {
+		return nil, false
+	}
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, false
+	}
+	var state oidcCookieState
+	if err := json.Unmarshal(data, &state); err != nil || state.State == "" {
+		return nil, false
+	}
+	return &state, true
+}
+
+// safeRedirect 只允许站内相对路径，防止开放重定向
+func safeRedirect(redirect string) string {
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.Contains(redirect, "\\") {
+		return ""
+	}
+	if i := strings.Index(redirect, "#"); i >= 0 {
+		redirect = redirect[:i]
+	}
+	return redirect
+}
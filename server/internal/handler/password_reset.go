@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liwei0526vip/mylinear/internal/service"
+)
+
+// PasswordResetHandler 密码重置处理器
+type PasswordResetHandler struct {
+	passwordResetService service.PasswordResetService
+}
+
+// NewPasswordResetHandler 创建密码重置处理器
+func NewPasswordResetHandler(passwordResetService service.PasswordResetService) *PasswordResetHandler {
+	return &PasswordResetHandler{passwordResetService: passwordResetService}
+}
+
+// ForgotPasswordRequest 忘记密码请求
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest 重置密码请求
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+// ForgotPassword 发送密码重置邮件，无论邮箱是否已注册都返回相同的响应
+// POST /api/v1/auth/password/forgot
+func (h *PasswordResetHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": "请求参数无效",
+		})
+		return
+	}
+
+	if err := h.passwordResetService.RequestReset(c.Request.Context(), req.Email); err != nil {
+		if errors.Is(err, service.ErrPasswordResetRateLimited) {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":   "too_many_requests",
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "internal_error",
+			"message": "发送重置邮件失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"message": "如果该邮箱已注册，重置链接已发送",
+		},
+	})
+}
+
+// ResetPassword 使用邮件中的令牌设置新密码，成功后所有会话被吊销
+// POST /api/v1/auth/password/reset
+func (h *PasswordResetHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": "请求参数无效",
+		})
+		return
+	}
+
+	if !isValidPassword(req.NewPassword) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": "密码强度不足，需要至少8个字符，包含大小写字母和数字",
+		})
+		return
+	}
+
+	if err := h.passwordResetService.ResetPassword(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidPasswordResetToken):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "invalid_token",
+				"message": err.Error(),
+			})
+		case errors.Is(err, service.ErrUserDeactivated):
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "user_deactivated",
+				"message": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "internal_error",
+				"message": "重置密码失败",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"message": "密码已重置，请使用新密码登录",
+		},
+	})
+}
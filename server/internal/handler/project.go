@@ -324,7 +324,12 @@ func (h *ProjectHandler) contextWithAuth(c *gin.Context) context.Context {
 
 	userRole := middleware.GetCurrentUserRole(c)
 	if userRole != "" {
-		ctx = context.WithValue(ctx, "user_role", userRole)
+		ctx = context.WithValue(ctx, "user_role", model.Role(userRole))
+	}
+
+	workspaceID := middleware.GetCurrentWorkspaceID(c)
+	if workspaceID != uuid.Nil {
+		ctx = context.WithValue(ctx, "workspace_id", workspaceID)
 	}
 
 	return ctx
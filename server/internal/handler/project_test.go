@@ -63,6 +63,7 @@ func setupProjectHandlerTestDB() {
 		&model.TeamMember{},
 		&model.WorkflowState{},
 		&model.Issue{},
+		&model.IssueIdentifierAlias{},
 		&model.Project{},
 	)
 	if err != nil {
@@ -429,8 +430,9 @@ func TestProjectHandler_DeleteProject(t *testing.T) {
 		c.Request = req
 		c.Params = gin.Params{{Key: "id", Value: project.ID.String()}}
 		c.Set(middleware.ContextKeyUser, &middleware.UserContext{
-			UserID: fixtures.user.ID.String(),
-			Role:   string(fixtures.user.Role),
+			UserID:      fixtures.user.ID.String(),
+			Role:        string(fixtures.user.Role),
+			WorkspaceID: fixtures.workspace.ID.String(),
 		})
 
 		handler.DeleteProject(c)
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/service"
+)
+
+// SessionHandler 登录会话处理器
+type SessionHandler struct {
+	sessionService service.SessionService
+}
+
+// NewSessionHandler 创建登录会话处理器
+func NewSessionHandler(sessionService service.SessionService) *SessionHandler {
+	return &SessionHandler{sessionService: sessionService}
+}
+
+// ListSessions 获取当前用户的有效会话
+// GET /api/v1/auth/sessions
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	ctx := contextWithUser(c)
+
+	sessions, err := h.sessionService.List(ctx)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// RevokeSession 吊销指定会话
+// DELETE /api/v1/auth/sessions/:id
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的会话ID"})
+		return
+	}
+
+	ctx := contextWithUser(c)
+	if err := h.sessionService.Revoke(ctx, id); err != nil {
+		handleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeOtherSessions 吊销除当前会话外的所有会话
+// DELETE /api/v1/auth/sessions
+func (h *SessionHandler) RevokeOtherSessions(c *gin.Context) {
+	ctx := contextWithUser(c)
+
+	revoked, err := h.sessionService.RevokeOthers(ctx)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+}
+
+// contextWithClient 将客户端的 User-Agent 和 IP 写入 context，创建会话时记录
+func contextWithClient(c *gin.Context) context.Context {
+	ctx := context.WithValue(c.Request.Context(), "user_agent", c.Request.UserAgent())
+	return context.WithValue(ctx, "client_ip", c.ClientIP())
+}
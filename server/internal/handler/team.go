@@ -23,18 +23,21 @@ func NewTeamHandler(teamService service.TeamService) *TeamHandler {
 
 // ListTeams 获取团队列表
 func (h *TeamHandler) ListTeams(c *gin.Context) {
+	// 默认列出当前所在工作区的团队
 	workspaceID := c.Query("workspace_id")
-	if workspaceID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "缺少工作区ID"})
+	if workspaceID != "" && !inCurrentWorkspace(c, workspaceID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "无权限访问此工作区，请先切换工作区"})
 		return
 	}
 
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	// 仅对管理员生效：同时列出未加入的私有团队，以便加入
+	includePrivate := c.Query("include_private") == "true"
 
 	ctx := contextWithUser(c)
 
-	teams, total, err := h.teamService.ListTeams(ctx, workspaceID, page, pageSize)
+	teams, total, err := h.teamService.ListTeams(ctx, workspaceID, includePrivate, page, pageSize)
 	if err != nil {
 		handleError(c, err)
 		return
@@ -48,6 +51,7 @@ func (h *TeamHandler) ListTeams(c *gin.Context) {
 			"name":         team.Name,
 			"key":          team.Key,
 			"description":  team.Description,
+			"is_private":   team.IsPrivate,
 			"created_at":   team.CreatedAt,
 			"updated_at":   team.UpdatedAt,
 		}
@@ -66,7 +70,10 @@ func (h *TeamHandler) CreateTeam(c *gin.Context) {
 		Name        string `json:"name" binding:"required"`
 		Key         string `json:"key" binding:"required"`
 		Description string `json:"description"`
-		WorkspaceID string `json:"workspace_id" binding:"required"`
+		// IsPrivate 私有团队只对成员可见
+		IsPrivate bool `json:"is_private"`
+		// WorkspaceID 可选，指定时必须是当前所在的工作区
+		WorkspaceID string `json:"workspace_id"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -75,9 +82,20 @@ func (h *TeamHandler) CreateTeam(c *gin.Context) {
 	}
 
 	ctx := contextWithUser(c)
-	ctx = context.WithValue(ctx, "workspace_id", uuid.MustParse(req.WorkspaceID))
+	if req.WorkspaceID != "" {
+		workspaceID, err := uuid.Parse(req.WorkspaceID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的工作区ID"})
+			return
+		}
+		if !inCurrentWorkspace(c, req.WorkspaceID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "无权限在此工作区创建团队，请先切换工作区"})
+			return
+		}
+		ctx = context.WithValue(ctx, "workspace_id", workspaceID)
+	}
 
-	team, err := h.teamService.CreateTeam(ctx, req.Name, req.Key, req.Description)
+	team, err := h.teamService.CreateTeam(ctx, req.Name, req.Key, req.Description, req.IsPrivate)
 	if err != nil {
 		handleError(c, err)
 		return
@@ -88,6 +106,7 @@ func (h *TeamHandler) CreateTeam(c *gin.Context) {
 		"workspace_id": team.WorkspaceID,
 		"name":         team.Name,
 		"key":          team.Key,
+		"is_private":   team.IsPrivate,
 		"created_at":   team.CreatedAt,
 	})
 }
@@ -113,6 +132,7 @@ func (h *TeamHandler) GetTeam(c *gin.Context) {
 		"workspace_id": team.WorkspaceID,
 		"name":         team.Name,
 		"key":          team.Key,
+		"is_private":   team.IsPrivate,
 		"created_at":   team.CreatedAt,
 		"updated_at":   team.UpdatedAt,
 	})
@@ -130,6 +150,7 @@ func (h *TeamHandler) UpdateTeam(c *gin.Context) {
 		Name        *string `json:"name"`
 		Key         *string `json:"key"`
 		Description *string `json:"description"`
+		IsPrivate   *bool   `json:"is_private"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -147,6 +168,9 @@ func (h *TeamHandler) UpdateTeam(c *gin.Context) {
 	if req.Description != nil {
 		updates["description"] = *req.Description
 	}
+	if req.IsPrivate != nil {
+		updates["is_private"] = *req.IsPrivate
+	}
 
 	ctx := contextWithUser(c)
 
@@ -161,6 +185,7 @@ func (h *TeamHandler) UpdateTeam(c *gin.Context) {
 		"workspace_id": team.WorkspaceID,
 		"name":         team.Name,
 		"key":          team.Key,
+		"is_private":   team.IsPrivate,
 		"updated_at":   team.UpdatedAt,
 	})
 }
@@ -38,10 +38,11 @@ func (h *TeamMemberHandler) ListMembers(c *gin.Context) {
 	result := make([]gin.H, len(members))
 	for i, member := range members {
 		u := gin.H{
-			"id":        member.UserID.String(),
-			"user_id":   member.UserID.String(),
-			"role":      member.Role,
-			"joined_at": member.JoinedAt,
+			"id":         member.UserID.String(),
+			"user_id":    member.UserID.String(),
+			"role":       member.Role,
+			"joined_at":  member.JoinedAt,
+			"joined_via": member.JoinedVia,
 		}
 		if member.User != nil {
 			u["user"] = gin.H{
@@ -141,3 +142,29 @@ func (h *TeamMemberHandler) UpdateMemberRole(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "角色已更新"})
 }
+
+// JoinTeam 管理员主动加入团队，用于访问未加入的私有团队
+// POST /api/v1/teams/:teamId/join
+func (h *TeamMemberHandler) JoinTeam(c *gin.Context) {
+	teamID := c.Param("teamId")
+	if teamID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "缺少团队ID"})
+		return
+	}
+
+	ctx := contextWithUser(c)
+
+	member, err := h.teamMemberService.JoinTeam(ctx, teamID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"team_id":    member.TeamID,
+		"user_id":    member.UserID,
+		"role":       member.Role,
+		"joined_at":  member.JoinedAt,
+		"joined_via": member.JoinedVia,
+	})
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liwei0526vip/mylinear/internal/service"
+)
+
+// TwoFactorHandler 两步验证处理器
+type TwoFactorHandler struct {
+	twoFactorService service.TwoFactorService
+}
+
+// NewTwoFactorHandler 创建两步验证处理器
+func NewTwoFactorHandler(twoFactorService service.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorService: twoFactorService}
+}
+
+// TwoFactorCodeRequest 验证码请求，code 可以是 6 位验证码或恢复码
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// TwoFactorChallengeRequest 登录中绑定验证器请求
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+// TwoFactorVerifyRequest 两步验证登录请求
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// TwoFactorChallengeResponse 密码验证通过但需要两步验证时的登录响应
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool `json:"two_factor_required"`
+	// EnrollmentRequired 工作区要求两步验证但尚未绑定验证器，需要先调用 /auth/2fa/challenge/setup
+	EnrollmentRequired bool   `json:"enrollment_required"`
+	ChallengeToken     string `json:"challenge_token"`
+}
+
+// TwoFactorLoginResponse 两步验证登录响应；登录中完成绑定时包含恢复码
+type TwoFactorLoginResponse struct {
+	AuthResponse
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// Status 获取当前用户的两步验证状态
+// GET /api/v1/auth/2fa
+func (h *TwoFactorHandler) Status(c *gin.Context) {
+	ctx := contextWithUser(c)
+
+	status, err := h.twoFactorService.Status(ctx)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// Setup 生成验证器密钥，返回 otpauth:// 地址用于扫码
+// POST /api/v1/auth/2fa/setup
+func (h *TwoFactorHandler) Setup(c *gin.Context) {
+	ctx := contextWithUser(c)
+
+	enrollment, err := h.twoFactorService.BeginEnrollment(ctx)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ConfirmSetup 使用验证码确认绑定并启用两步验证，返回恢复码（只显示一次）
+// POST /api/v1/auth/2fa/setup/confirm
+func (h *TwoFactorHandler) ConfirmSetup(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	ctx := contextWithUser(c)
+	codes, err := h.twoFactorService.ConfirmEnrollment(ctx, req.Code)
+	if err != nil {
+		handleTwoFactorError(c, err, http.StatusBadRequest)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// Disable 使用验证码或恢复码关闭两步验证
+// POST /api/v1/auth/2fa/disable
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	ctx := contextWithUser(c)
+	if err := h.twoFactorService.Disable(ctx, req.Code); err != nil {
+		handleTwoFactorError(c, err, http.StatusBadRequest)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧恢复码失效
+// POST /api/v1/auth/2fa/recovery-codes
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	ctx := contextWithUser(c)
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(ctx, req.Code)
+	if err != nil {
+		handleTwoFactorError(c, err, http.StatusBadRequest)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// ChallengeSetup 登录中绑定验证器（工作区要求两步验证但尚未启用时）
+// POST /api/v1/auth/2fa/challenge/setup
+func (h *TwoFactorHandler) ChallengeSetup(c *gin.Context) {
+	var req TwoFactorChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	enrollment, err := h.twoFactorService.BeginChallengeEnrollment(c.Request.Context(), req.ChallengeToken)
+	if err != nil {
+		handleTwoFactorError(c, err, http.StatusUnauthorized)
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// ChallengeVerify 使用挑战令牌和验证码（或恢复码）完成登录
+// POST /api/v1/auth/2fa/challenge/verify
+func (h *TwoFactorHandler) ChallengeVerify(c *gin.Context) {
+	var req TwoFactorVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求参数"})
+		return
+	}
+
+	result, err := h.twoFactorService.CompleteLogin(contextWithClient(c), req.ChallengeToken, req.Code)
+	if err != nil {
+		handleTwoFactorError(c, err, http.StatusUnauthorized)
+		return
+	}
+
+	user := result.User
+	c.JSON(http.StatusOK, gin.H{
+		"data": TwoFactorLoginResponse{
+			AuthResponse: AuthResponse{
+				AccessToken:  result.AccessToken,
+				RefreshToken: result.RefreshToken,
+				User: &UserDTO{
+					ID:          user.ID.String(),
+					WorkspaceID: user.WorkspaceID.String(),
+					Email:       user.Email,
+					Username:    user.Username,
+					Name:        user.Name,
+					Role:        string(user.Role),
+				},
+			},
+			RecoveryCodes: result.RecoveryCodes,
+		},
+	})
+}
+
+// handleTwoFactorError 处理两步验证错误，验证码错误时返回 invalidCodeStatus
+func handleTwoFactorError(c *gin.Context, err error, invalidCodeStatus int) {
+	switch {
+	case errors.Is(err, service.ErrInvalidTwoFactorCode):
+		c.JSON(invalidCodeStatus, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUserDeactivated):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		handleError(c, err)
+	}
+}
+
+// respondTwoFactorRequired 密码验证通过但需要两步验证时返回挑战令牌，返回是否已处理
+func respondTwoFactorRequired(c *gin.Context, err error) bool {
+	var required *service.TwoFactorRequiredError
+	if !errors.As(err, &required) {
+		return false
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data": TwoFactorChallengeResponse{
+			TwoFactorRequired:  true,
+			EnrollmentRequired: required.EnrollmentRequired,
+			ChallengeToken:     required.ChallengeToken,
+		},
+	})
+	return true
+}
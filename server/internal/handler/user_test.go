@@ -13,12 +13,14 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/mail/mailtest"
 	"github.com/liwei0526vip/mylinear/internal/middleware"
 	"github.com/liwei0526vip/mylinear/internal/model"
 	"github.com/liwei0526vip/mylinear/internal/service"
 	"github.com/liwei0526vip/mylinear/internal/store"
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -61,6 +63,9 @@ func setupUserHandlerTest(t *testing.T) (*gin.Engine, service.AuthService, servi
 	userTestRedis = rdb
 	userTestWorkspaceID = workspace.ID
 
+	// 允许 example.com 邮箱直接注册
+	db.Model(&workspace).Update("settings", datatypes.JSON(`{"auth":{"allowed_email_domains":["example.com"]}}`))
+
 	// 创建服务
 	cfg := &config.Config{
 		JWTSecret:        "user-handler-test-secret",
@@ -71,7 +76,9 @@ func setupUserHandlerTest(t *testing.T) (*gin.Engine, service.AuthService, servi
 	userStore := store.NewUserStore(db)
 	workspaceStore := store.NewWorkspaceStore(db)
 	jwtService := service.NewJWTService(cfg)
-	authService := service.NewAuthService(userStore, workspaceStore, jwtService, rdb, cfg)
+	sessionService := service.NewSessionService(store.NewSessionStore(db), userStore, store.NewWorkspaceMemberStore(db), store.NewOAuthStore(db), jwtService, cfg)
+	invitationService := service.NewInvitationService(store.NewInvitationStore(db), userStore, workspaceStore, store.NewWorkspaceMemberStore(db), store.NewTeamStore(db), store.NewTeamMemberStore(db), mailtest.NewSender(), cfg)
+	authService := service.NewAuthService(userStore, workspaceStore, jwtService, sessionService, invitationService, rdb, cfg)
 	userService := service.NewUserService(userStore)
 
 	// 创建路由
@@ -100,7 +107,7 @@ func TestUserHandler_GetMe_Success(t *testing.T) {
 	prefix := uuid.New().String()[:8]
 	email := prefix + "_getme@example.com"
 	password := "Password123!"
-	user, _, _, _ := authService.Register(ctx, userTestWorkspaceID, email, prefix+"_getmeuser", password, "GetMe User")
+	user, _, _, _ := authService.Register(ctx, userTestWorkspaceID, email, prefix+"_getmeuser", password, "GetMe User", "")
 
 	// 生成访问令牌
 	accessToken, _ := jwtService.GenerateAccessToken(user.ID, user.Email, user.Role)
@@ -170,7 +177,7 @@ func TestUserHandler_UpdateMe_Success(t *testing.T) {
 	prefix := uuid.New().String()[:8]
 	email := prefix + "_updateme@example.com"
 	password := "Password123!"
-	user, _, _, err := authService.Register(ctx, userTestWorkspaceID, email, prefix+"_updatemeuser", password, "UpdateMe User")
+	user, _, _, err := authService.Register(ctx, userTestWorkspaceID, email, prefix+"_updatemeuser", password, "UpdateMe User", "")
 	require.NoError(t, err)
 
 	// 生成访问令牌
@@ -224,9 +231,9 @@ func TestUserHandler_UpdateMe_EmailConflict(t *testing.T) {
 	prefix := uuid.New().String()[:8]
 	email1 := prefix + "_conflict1@example.com"
 	email2 := prefix + "_conflict2@example.com"
-	user1, _, _, err := authService.Register(ctx, userTestWorkspaceID, email1, prefix+"_conflict1user", "Password123!", "Conflict User 1")
+	user1, _, _, err := authService.Register(ctx, userTestWorkspaceID, email1, prefix+"_conflict1user", "Password123!", "Conflict User 1", "")
 	require.NoError(t, err)
-	_, _, _, err = authService.Register(ctx, userTestWorkspaceID, email2, prefix+"_conflict2user", "Password123!", "Conflict User 2")
+	_, _, _, err = authService.Register(ctx, userTestWorkspaceID, email2, prefix+"_conflict2user", "Password123!", "Conflict User 2", "")
 	require.NoError(t, err)
 
 	// 生成用户1的访问令牌
@@ -269,9 +276,9 @@ func TestUserHandler_UpdateMe_UsernameConflict(t *testing.T) {
 	prefix := uuid.New().String()[:8]
 	email1 := prefix + "_uconflict1@example.com"
 	email2 := prefix + "_uconflict2@example.com"
-	user1, _, _, err := authService.Register(ctx, userTestWorkspaceID, email1, prefix+"_uconflict1user", "Password123!", "Username Conflict 1")
+	user1, _, _, err := authService.Register(ctx, userTestWorkspaceID, email1, prefix+"_uconflict1user", "Password123!", "Username Conflict 1", "")
 	require.NoError(t, err)
-	_, _, _, err = authService.Register(ctx, userTestWorkspaceID, email2, prefix+"_uconflict2user", "Password123!", "Username Conflict 2")
+	_, _, _, err = authService.Register(ctx, userTestWorkspaceID, email2, prefix+"_uconflict2user", "Password123!", "Username Conflict 2", "")
 	require.NoError(t, err)
 
 	// 生成用户1的访问令牌
@@ -329,7 +336,7 @@ func TestUserHandler_UploadAvatar_FileTooLarge(t *testing.T) {
 	// 注册用户
 	prefix := uuid.New().String()[:8]
 	email := prefix + "_avatar_big@example.com"
-	user, _, _, _ := authService.Register(ctx, userTestWorkspaceID, email, prefix+"_avatarbiguser", "Password123!", "Avatar Big User")
+	user, _, _, _ := authService.Register(ctx, userTestWorkspaceID, email, prefix+"_avatarbiguser", "Password123!", "Avatar Big User", "")
 
 	// 生成访问令牌
 	accessToken, _ := jwtService.GenerateAccessToken(user.ID, user.Email, user.Role)
@@ -372,7 +379,7 @@ func TestUserHandler_UploadAvatar_InvalidFileType(t *testing.T) {
 	// 注册用户
 	prefix := uuid.New().String()[:8]
 	email := prefix + "_avatar_type@example.com"
-	user, _, _, _ := authService.Register(ctx, userTestWorkspaceID, email, prefix+"_avatartypeuser", "Password123!", "Avatar Type User")
+	user, _, _, _ := authService.Register(ctx, userTestWorkspaceID, email, prefix+"_avatartypeuser", "Password123!", "Avatar Type User", "")
 
 	// 生成访问令牌
 	accessToken, _ := jwtService.GenerateAccessToken(user.ID, user.Email, user.Role)
@@ -410,7 +417,7 @@ func TestUserHandler_UploadAvatar_MissingFile(t *testing.T) {
 	// 注册用户
 	prefix := uuid.New().String()[:8]
 	email := prefix + "_avatar_missing@example.com"
-	user, _, _, _ := authService.Register(ctx, userTestWorkspaceID, email, prefix+"_avatarmisssuer", "Password123!", "Avatar Missing User")
+	user, _, _, _ := authService.Register(ctx, userTestWorkspaceID, email, prefix+"_avatarmisssuer", "Password123!", "Avatar Missing User", "")
 
 	// 生成访问令牌
 	accessToken, _ := jwtService.GenerateAccessToken(user.ID, user.Email, user.Role)
@@ -77,7 +77,7 @@ func TestWorkflowHandler_ListStates(t *testing.T) {
 	ctx := context.WithValue(context.Background(), "user_id", admin.ID)
 	ctx = context.WithValue(ctx, "user_role", admin.Role)
 	ctx = context.WithValue(ctx, "workspace_id", workspace.ID)
-	team, err := teamSvc.CreateTeam(ctx, "Test Team", "TT", "")
+	team, err := teamSvc.CreateTeam(ctx, "Test Team", "TT", "", false)
 	require.NoError(t, err)
 
 	workflowHandler := NewWorkflowHandler(workflowSvc)
@@ -98,17 +98,91 @@ func (h *WorkspaceHandler) UpdateWorkspace(c *gin.Context) {
 	})
 }
 
+// ListWorkspaces 获取当前用户加入的工作区
+// GET /api/v1/workspaces
+func (h *WorkspaceHandler) ListWorkspaces(c *gin.Context) {
+	ctx := contextWithUser(c)
+
+	workspaces, err := h.workspaceService.ListWorkspaces(ctx)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"workspaces": workspaces})
+}
+
+// CreateWorkspace 创建工作区，创建者成为该工作区的管理员
+// POST /api/v1/workspaces
+func (h *WorkspaceHandler) CreateWorkspace(c *gin.Context) {
+	var req struct {
+		Name string `json:"name" binding:"required"`
+		// Slug 不提供时根据名称生成
+		Slug string `json:"slug"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求体"})
+		return
+	}
+
+	ctx := contextWithUser(c)
+
+	workspace, err := h.workspaceService.CreateWorkspace(ctx, req.Name, req.Slug)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":         workspace.ID,
+		"name":       workspace.Name,
+		"slug":       workspace.Slug,
+		"logo_url":   workspace.LogoURL,
+		"created_at": workspace.CreatedAt,
+		"updated_at": workspace.UpdatedAt,
+	})
+}
+
+// SwitchWorkspace 切换到指定工作区，返回作用于该工作区的新访问令牌
+// 刷新令牌不变，之后刷新得到的访问令牌同样作用于该工作区
+// POST /api/v1/workspaces/:workspaceId/switch
+func (h *WorkspaceHandler) SwitchWorkspace(c *gin.Context) {
+	ctx := contextWithUser(c)
+
+	switched, err := h.workspaceService.SwitchWorkspace(ctx, c.Param("workspaceId"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, switched)
+}
+
 // contextWithUser 将用户信息注入上下文
 func contextWithUser(c *gin.Context) context.Context {
-	ctx := c.Request.Context()
+	ctx := contextWithClient(c)
 	user := middleware.GetCurrentUser(c)
 	if user != nil {
 		ctx = context.WithValue(ctx, "user_id", uuid.MustParse(user.UserID))
 		ctx = context.WithValue(ctx, "user_role", model.Role(user.Role))
+		if sessionID, err := uuid.Parse(user.SessionID); err == nil {
+			ctx = context.WithValue(ctx, "session_id", sessionID)
+		}
+		if workspaceID, err := uuid.Parse(user.WorkspaceID); err == nil {
+			ctx = context.WithValue(ctx, "workspace_id", workspaceID)
+		}
 	}
 	return ctx
 }
 
+// inCurrentWorkspace 检查工作区是否为当前所在的工作区
+// 令牌未指定工作区（旧令牌、OAuth 令牌）时不做限制，由服务层按主工作区处理
+func inCurrentWorkspace(c *gin.Context, workspaceID string) bool {
+	current := middleware.GetCurrentWorkspaceID(c)
+	return current == uuid.Nil || current.String() == workspaceID
+}
+
 // handleError 处理错误响应
 func handleError(c *gin.Context, err error) {
 	errMsg := err.Error()
@@ -77,7 +77,7 @@ func TestWorkspaceHandler_GetWorkspace(t *testing.T) {
 	jwtService := service.NewJWTService(cfg)
 	workspaceStore := store.NewWorkspaceStore(tx)
 	userStore := store.NewUserStore(tx)
-	workspaceService := service.NewWorkspaceService(workspaceStore, userStore)
+	workspaceService := service.NewWorkspaceService(workspaceStore, userStore, store.NewWorkspaceMemberStore(tx), nil)
 
 	// 创建 handler
 	handler := NewWorkspaceHandler(workspaceService)
@@ -173,7 +173,7 @@ func TestWorkspaceHandler_UpdateWorkspace(t *testing.T) {
 	jwtService := service.NewJWTService(cfg)
 	workspaceStore := store.NewWorkspaceStore(tx)
 	userStore := store.NewUserStore(tx)
-	workspaceService := service.NewWorkspaceService(workspaceStore, userStore)
+	workspaceService := service.NewWorkspaceService(workspaceStore, userStore, store.NewWorkspaceMemberStore(tx), nil)
 
 	// 创建 handler
 	handler := NewWorkspaceHandler(workspaceService)
@@ -0,0 +1,188 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liwei0526vip/mylinear/internal/model"
+)
+
+// githubUser GitHub 用户
+type githubUser struct {
+	Login string `json:"login"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// githubComment GitHub 评论（兼容 REST API 与 gh CLI 两种字段命名）
+type githubComment struct {
+	ID           json.RawMessage `json:"id"`
+	Body         string          `json:"body"`
+	User         *githubUser     `json:"user"`
+	Author       *githubUser     `json:"author"`
+	CreatedAt    string          `json:"created_at"`
+	CreatedAtCLI string          `json:"createdAt"`
+}
+
+// githubIssue GitHub Issue（兼容 REST API 与 `gh issue list --json` 两种格式）
+type githubIssue struct {
+	Number         int    `json:"number"`
+	Title          string `json:"title"`
+	Body           string `json:"body"`
+	State          string `json:"state"`
+	StateReason    string `json:"state_reason"`
+	StateReasonCLI string `json:"stateReason"`
+	Labels         []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Assignee     *githubUser     `json:"assignee"`
+	Assignees    []githubUser    `json:"assignees"`
+	User         *githubUser     `json:"user"`
+	Author       *githubUser     `json:"author"`
+	CreatedAt    string          `json:"created_at"`
+	CreatedAtCLI string          `json:"createdAt"`
+	UpdatedAt    string          `json:"updated_at"`
+	UpdatedAtCLI string          `json:"updatedAt"`
+	ClosedAt     string          `json:"closed_at"`
+	ClosedAtCLI  string          `json:"closedAt"`
+	PullRequest  json.RawMessage `json:"pull_request"`
+	// REST API 中为评论数量，gh CLI 中为评论数组
+	Comments json.RawMessage `json:"comments"`
+	// 部分导出工具会把评论单独放在 comments_data 中
+	CommentsData []githubComment `json:"comments_data"`
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// login 返回用户登录名
+func (u *githubUser) login() string {
+	if u == nil {
+		return ""
+	}
+	return u.Login
+}
+
+// parseGitHubJSON 解析 GitHub Issues JSON 导出（Issue 数组）
+func parseGitHubJSON(r io.Reader) (*Dataset, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("读取 GitHub JSON 文件失败: %w", err)
+	}
+
+	var issues []githubIssue
+	if err := json.Unmarshal(data, &issues); err != nil {
+		// 兼容 {"issues": [...]} 包装格式
+		var wrapped struct {
+			Issues []githubIssue `json:"issues"`
+		}
+		if err2 := json.Unmarshal(data, &wrapped); err2 != nil || wrapped.Issues == nil {
+			return nil, fmt.Errorf("无效的 GitHub JSON 文件: %w", err)
+		}
+		issues = wrapped.Issues
+	}
+
+	ds := &Dataset{}
+	users := make(map[string]bool)
+	addUser := func(u *githubUser) {
+		if u == nil || u.Login == "" || users[u.Login] {
+			return
+		}
+		users[u.Login] = true
+		ds.Users = append(ds.Users, User{Ref: u.Login, Name: firstNonEmpty(u.Name, u.Login), Email: u.Email, Username: u.Login})
+	}
+
+	for _, gi := range issues {
+		// 跳过 Pull Request
+		if len(gi.PullRequest) > 0 && !bytes.Equal(gi.PullRequest, []byte("null")) {
+			continue
+		}
+		if gi.Number == 0 {
+			continue
+		}
+
+		author := gi.User
+		if author == nil {
+			author = gi.Author
+		}
+		assignee := gi.Assignee
+		if assignee == nil && len(gi.Assignees) > 0 {
+			assignee = &gi.Assignees[0]
+		}
+		addUser(author)
+		addUser(assignee)
+
+		issue := Issue{
+			ExternalID:  strconv.Itoa(gi.Number),
+			Title:       strings.TrimSpace(gi.Title),
+			Description: gi.Body,
+			AssigneeRef: assignee.login(),
+			CreatorRef:  author.login(),
+			CreatedAt:   parseTime(firstNonEmpty(gi.CreatedAt, gi.CreatedAtCLI), time.RFC3339),
+			UpdatedAt:   parseTime(firstNonEmpty(gi.UpdatedAt, gi.UpdatedAtCLI), time.RFC3339),
+		}
+		for _, l := range gi.Labels {
+			if l.Name != "" {
+				issue.Labels = append(issue.Labels, l.Name)
+			}
+		}
+
+		closedAt := parseTime(firstNonEmpty(gi.ClosedAt, gi.ClosedAtCLI), time.RFC3339)
+		if strings.EqualFold(gi.State, "closed") {
+			reason := strings.ToLower(firstNonEmpty(gi.StateReason, gi.StateReasonCLI))
+			if reason == "not_planned" {
+				issue.State = "Canceled"
+				issue.StateType = model.StateTypeCanceled
+				issue.CanceledAt = timePtr(closedAt)
+			} else {
+				issue.State = "Done"
+				issue.StateType = model.StateTypeCompleted
+				issue.CompletedAt = timePtr(closedAt)
+			}
+		} else {
+			issue.State = "Todo"
+			issue.StateType = model.StateTypeUnstarted
+		}
+
+		comments := gi.CommentsData
+		if len(gi.Comments) > 0 && gi.Comments[0] == '[' {
+			var inline []githubComment
+			if err := json.Unmarshal(gi.Comments, &inline); err == nil {
+				comments = append(comments, inline...)
+			}
+		}
+		for i, c := range comments {
+			commentAuthor := c.User
+			if commentAuthor == nil {
+				commentAuthor = c.Author
+			}
+			addUser(commentAuthor)
+			externalID := strings.Trim(string(c.ID), "\"")
+			if externalID == "" {
+				externalID = fmt.Sprintf("%d#%d", gi.Number, i+1)
+			}
+			issue.Comments = append(issue.Comments, Comment{
+				ExternalID: externalID,
+				AuthorRef:  commentAuthor.login(),
+				Body:       c.Body,
+				CreatedAt:  parseTime(firstNonEmpty(c.CreatedAt, c.CreatedAtCLI), time.RFC3339),
+			})
+		}
+
+		ds.Issues = append(ds.Issues, issue)
+	}
+
+	return ds, nil
+}
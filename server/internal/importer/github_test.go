@@ -0,0 +1,91 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/liwei0526vip/mylinear/internal/model"
+)
+
+func TestParseGitHubJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantCount int
+		checkFunc func(*testing.T, *Dataset)
+	}{
+		{
+			name: "REST API 格式",
+			data: `[
+				{"number": 12, "title": "崩溃", "body": "详情", "state": "open",
+				 "labels": [{"name": "bug"}], "assignee": {"login": "alice"}, "user": {"login": "bob"},
+				 "created_at": "2023-01-02T10:00:00Z", "updated_at": "2023-01-03T10:00:00Z", "comments": 2,
+				 "comments_data": [{"id": 901, "body": "复现了", "user": {"login": "alice"}, "created_at": "2023-01-02T11:00:00Z"}]},
+				{"number": 13, "title": "PR", "state": "open", "pull_request": {"url": "x"}},
+				{"number": 14, "title": "不做了", "state": "closed", "state_reason": "not_planned",
+				 "user": {"login": "bob"}, "created_at": "2023-01-04T10:00:00Z", "closed_at": "2023-01-05T10:00:00Z"}
+			]`,
+			wantCount: 2,
+			checkFunc: func(t *testing.T, ds *Dataset) {
+				first := ds.Issues[0]
+				if first.ExternalID != "12" || first.AssigneeRef != "alice" || first.CreatorRef != "bob" {
+					t.Errorf("Unexpected issue: %+v", first)
+				}
+				if first.StateType != model.StateTypeUnstarted {
+					t.Errorf("Open issue should be unstarted, got %s", first.StateType)
+				}
+				if len(first.Comments) != 1 || first.Comments[0].ExternalID != "901" {
+					t.Errorf("Unexpected comments: %+v", first.Comments)
+				}
+				second := ds.Issues[1]
+				if second.StateType != model.StateTypeCanceled || second.CanceledAt == nil {
+					t.Errorf("not_planned should map to canceled, got %s", second.StateType)
+				}
+			},
+		},
+		{
+			name: "gh CLI 格式",
+			data: `{"issues": [
+				{"number": 7, "title": "文档", "state": "CLOSED", "stateReason": "COMPLETED",
+				 "author": {"login": "carol"}, "assignees": [{"login": "dave"}],
+				 "createdAt": "2023-02-01T08:00:00Z", "closedAt": "2023-02-02T08:00:00Z",
+				 "comments": [{"id": "IC_abc", "author": {"login": "dave"}, "body": "好的", "createdAt": "2023-02-01T09:00:00Z"}]}
+			]}`,
+			wantCount: 1,
+			checkFunc: func(t *testing.T, ds *Dataset) {
+				issue := ds.Issues[0]
+				if issue.CreatorRef != "carol" || issue.AssigneeRef != "dave" {
+					t.Errorf("Unexpected users: %+v", issue)
+				}
+				if issue.StateType != model.StateTypeCompleted || issue.CompletedAt == nil {
+					t.Errorf("Closed issue should be completed, got %s", issue.StateType)
+				}
+				if len(issue.Comments) != 1 || issue.Comments[0].ExternalID != "IC_abc" {
+					t.Errorf("Unexpected comments: %+v", issue.Comments)
+				}
+				if issue.CreatedAt.Month() != 2 {
+					t.Errorf("Created time not preserved: %v", issue.CreatedAt)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds, err := Parse(SourceGitHubJSON, strings.NewReader(tt.data))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(ds.Issues) != tt.wantCount {
+				t.Fatalf("Expected %d issues, got %d", tt.wantCount, len(ds.Issues))
+			}
+			tt.checkFunc(t, ds)
+		})
+	}
+}
+
+func TestParseGitHubJSON_Invalid(t *testing.T) {
+	if _, err := Parse(SourceGitHubJSON, strings.NewReader(`{"foo": 1}`)); err == nil {
+		t.Error("Expected error for JSON without issues")
+	}
+}
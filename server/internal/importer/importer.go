@@ -0,0 +1,205 @@
+// Package importer 解析其他项目管理工具的导出文件，转换为统一的数据结构
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/liwei0526vip/mylinear/internal/model"
+)
+
+// Source 导入来源
+type Source string
+
+// 支持的导入来源
+const (
+	SourceJiraXML    Source = "jira_xml"
+	SourceJiraCSV    Source = "jira_csv"
+	SourceGitHubJSON Source = "github_json"
+	SourceLinearCSV  Source = "linear_csv"
+)
+
+// Valid 检查导入来源是否有效
+func (s Source) Valid() bool {
+	switch s {
+	case SourceJiraXML, SourceJiraCSV, SourceGitHubJSON, SourceLinearCSV:
+		return true
+	}
+	return false
+}
+
+// Dataset 解析后的统一数据集
+type Dataset struct {
+	Source Source
+	// Users 出现过的外部用户（按 Ref 去重）
+	Users []User
+	// Labels 出现过的标签名（去重）
+	Labels []string
+	// States 出现过的状态（按名称去重）
+	States []State
+	// Issues 按文件中的顺序排列
+	Issues []Issue
+}
+
+// User 外部用户
+type User struct {
+	// Ref 外部系统中引用用户的标识（用户名、登录名或显示名）
+	Ref      string
+	Name     string
+	Email    string
+	Username string
+}
+
+// State 外部状态
+type State struct {
+	Name string
+	Type model.StateType
+}
+
+// Issue 外部 Issue
+type Issue struct {
+	ExternalID       string
+	ParentExternalID string
+	Title            string
+	Description      string
+	State            string
+	StateType        model.StateType
+	Priority         int
+	AssigneeRef      string
+	CreatorRef       string
+	Labels           []string
+	Comments         []Comment
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	CompletedAt      *time.Time
+	CanceledAt       *time.Time
+	DueDate          *time.Time
+}
+
+// Comment 外部评论
+type Comment struct {
+	ExternalID string
+	AuthorRef  string
+	Body       string
+	CreatedAt  time.Time
+}
+
+// Parse 按来源解析导出文件
+func Parse(source Source, r io.Reader) (*Dataset, error) {
+	var (
+		ds  *Dataset
+		err error
+	)
+	switch source {
+	case SourceJiraXML:
+		ds, err = parseJiraXML(r)
+	case SourceJiraCSV:
+		ds, err = parseJiraCSV(r)
+	case SourceGitHubJSON:
+		ds, err = parseGitHubJSON(r)
+	case SourceLinearCSV:
+		ds, err = parseLinearCSV(r)
+	default:
+		return nil, fmt.Errorf("无效的导入来源: %s", source)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ds.Source = source
+	ds.collect()
+	return ds, nil
+}
+
+// collect 从 Issue 中汇总标签和状态，并补全未出现在用户列表中的引用
+func (ds *Dataset) collect() {
+	users := make(map[string]bool, len(ds.Users))
+	for _, u := range ds.Users {
+		users[u.Ref] = true
+	}
+	addUser := func(ref string) {
+		if ref != "" && !users[ref] {
+			users[ref] = true
+			ds.Users = append(ds.Users, User{Ref: ref, Name: ref})
+		}
+	}
+
+	labels := make(map[string]bool)
+	states := make(map[string]bool)
+	for _, issue := range ds.Issues {
+		addUser(issue.AssigneeRef)
+		addUser(issue.CreatorRef)
+		for _, c := range issue.Comments {
+			addUser(c.AuthorRef)
+		}
+		for _, l := range issue.Labels {
+			key := strings.ToLower(l)
+			if !labels[key] {
+				labels[key] = true
+				ds.Labels = append(ds.Labels, l)
+			}
+		}
+		if issue.State != "" && !states[strings.ToLower(issue.State)] {
+			states[strings.ToLower(issue.State)] = true
+			ds.States = append(ds.States, State{Name: issue.State, Type: issue.StateType})
+		}
+	}
+}
+
+// priorityByName 外部优先级名称到内部优先级的映射（小写）
+var priorityByName = map[string]int{
+	"no priority": model.PriorityNone,
+	"none":        model.PriorityNone,
+	"urgent":      model.PriorityUrgent,
+	"highest":     model.PriorityUrgent,
+	"blocker":     model.PriorityUrgent,
+	"high":        model.PriorityHigh,
+	"critical":    model.PriorityHigh,
+	"medium":      model.PriorityMedium,
+	"major":       model.PriorityMedium,
+	"normal":      model.PriorityMedium,
+	"low":         model.PriorityLow,
+	"lowest":      model.PriorityLow,
+	"minor":       model.PriorityLow,
+	"trivial":     model.PriorityLow,
+}
+
+// priorityFromName 将外部优先级名称转换为内部优先级，未知名称返回无优先级
+func priorityFromName(name string) int {
+	return priorityByName[strings.ToLower(strings.TrimSpace(name))]
+}
+
+// parseTime 依次尝试多种时间格式，全部失败时返回零值
+func parseTime(value string, layouts ...string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// timePtr 零值时间返回 nil
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// splitList 拆分以逗号分隔的列表，去除空白和空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,309 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/liwei0526vip/mylinear/internal/model"
+)
+
+// Jira 导出使用的时间格式
+var jiraTimeLayouts = []string{
+	"Mon, 2 Jan 2006 15:04:05 -0700", // XML (RSS) 导出
+	"02/Jan/06 3:04 PM",              // CSV 导出默认格式
+	"2/Jan/06 3:04 PM",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05.000-0700",
+	time.RFC3339,
+}
+
+// jiraCanceledResolutions 视为"已取消"的解决结果（小写）
+var jiraCanceledResolutions = map[string]bool{
+	"won't do":  true,
+	"won't fix": true,
+	"cancelled": true,
+	"canceled":  true,
+	"duplicate": true,
+	"declined":  true,
+}
+
+// jiraStateType 根据 Jira 状态分类（new / indeterminate / done）推断状态类型
+func jiraStateType(category, status, resolution string) model.StateType {
+	switch strings.ToLower(category) {
+	case "done":
+		if jiraCanceledResolutions[strings.ToLower(resolution)] {
+			return model.StateTypeCanceled
+		}
+		return model.StateTypeCompleted
+	case "indeterminate", "in progress":
+		return model.StateTypeStarted
+	case "new", "to do":
+		if strings.Contains(strings.ToLower(status), "backlog") {
+			return model.StateTypeBacklog
+		}
+		return model.StateTypeUnstarted
+	}
+	// 没有分类信息时根据状态名推断
+	return stateTypeFromName(status)
+}
+
+// stateTypeFromName 根据常见的状态名称推断状态类型
+func stateTypeFromName(name string) model.StateType {
+	n := strings.ToLower(name)
+	switch {
+	case strings.Contains(n, "backlog"):
+		return model.StateTypeBacklog
+	case strings.Contains(n, "cancel"), strings.Contains(n, "won't"), strings.Contains(n, "duplicate"):
+		return model.StateTypeCanceled
+	case strings.Contains(n, "done"), strings.Contains(n, "closed"), strings.Contains(n, "resolved"), strings.Contains(n, "complete"):
+		return model.StateTypeCompleted
+	case strings.Contains(n, "progress"), strings.Contains(n, "review"), strings.Contains(n, "started"):
+		return model.StateTypeStarted
+	default:
+		return model.StateTypeUnstarted
+	}
+}
+
+// jiraXMLUser XML 中的用户字段
+type jiraXMLUser struct {
+	Username  string `xml:"username,attr"`
+	AccountID string `xml:"accountid,attr"`
+	Name      string `xml:",chardata"`
+}
+
+// ref 返回用户引用标识
+func (u jiraXMLUser) ref() string {
+	switch {
+	case u.Username != "":
+		return u.Username
+	case u.AccountID != "" && u.AccountID != "-1":
+		return u.AccountID
+	case strings.TrimSpace(u.Name) == "Unassigned":
+		return ""
+	default:
+		return strings.TrimSpace(u.Name)
+	}
+}
+
+// jiraXMLItem XML (RSS) 导出中的单个 Issue
+type jiraXMLItem struct {
+	Key            string `xml:"key"`
+	Summary        string `xml:"summary"`
+	Description    string `xml:"description"`
+	Parent         string `xml:"parent"`
+	Priority       string `xml:"priority"`
+	Status         string `xml:"status"`
+	StatusCategory struct {
+		Key string `xml:"key,attr"`
+	} `xml:"statusCategory"`
+	Resolution string      `xml:"resolution"`
+	Assignee   jiraXMLUser `xml:"assignee"`
+	Reporter   jiraXMLUser `xml:"reporter"`
+	Labels     []string    `xml:"labels>label"`
+	Created    string      `xml:"created"`
+	Updated    string      `xml:"updated"`
+	Resolved   string      `xml:"resolved"`
+	Due        string      `xml:"due"`
+	Comments   []struct {
+		ID      string `xml:"id,attr"`
+		Author  string `xml:"author,attr"`
+		Created string `xml:"created,attr"`
+		Body    string `xml:",chardata"`
+	} `xml:"comments>comment"`
+}
+
+// parseJiraXML 解析 Jira XML (RSS) 导出
+func parseJiraXML(r io.Reader) (*Dataset, error) {
+	var doc struct {
+		Items []jiraXMLItem `xml:"channel>item"`
+	}
+	decoder := xml.NewDecoder(r)
+	// Jira 导出中常见 HTML 实体
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("无效的 Jira XML 文件: %w", err)
+	}
+
+	ds := &Dataset{}
+	users := make(map[string]bool)
+	addUser := func(u jiraXMLUser) {
+		ref := u.ref()
+		if ref == "" || users[ref] {
+			return
+		}
+		users[ref] = true
+		ds.Users = append(ds.Users, User{Ref: ref, Name: strings.TrimSpace(u.Name), Username: u.Username})
+	}
+
+	for _, item := range doc.Items {
+		if item.Key == "" {
+			continue
+		}
+		addUser(item.Assignee)
+		addUser(item.Reporter)
+
+		issue := Issue{
+			ExternalID:       strings.TrimSpace(item.Key),
+			ParentExternalID: strings.TrimSpace(item.Parent),
+			Title:            strings.TrimSpace(item.Summary),
+			Description:      strings.TrimSpace(item.Description),
+			State:            strings.TrimSpace(item.Status),
+			StateType:        jiraStateType(item.StatusCategory.Key, item.Status, item.Resolution),
+			Priority:         priorityFromName(item.Priority),
+			AssigneeRef:      item.Assignee.ref(),
+			CreatorRef:       item.Reporter.ref(),
+			CreatedAt:        parseTime(item.Created, jiraTimeLayouts...),
+			UpdatedAt:        parseTime(item.Updated, jiraTimeLayouts...),
+			DueDate:          timePtr(parseTime(item.Due, jiraTimeLayouts...)),
+		}
+		for _, l := range item.Labels {
+			if l = strings.TrimSpace(l); l != "" {
+				issue.Labels = append(issue.Labels, l)
+			}
+		}
+		setJiraResolved(&issue, parseTime(item.Resolved, jiraTimeLayouts...))
+
+		for _, c := range item.Comments {
+			issue.Comments = append(issue.Comments, Comment{
+				ExternalID: c.ID,
+				AuthorRef:  c.Author,
+				Body:       strings.TrimSpace(c.Body),
+				CreatedAt:  parseTime(c.Created, jiraTimeLayouts...),
+			})
+		}
+
+		ds.Issues = append(ds.Issues, issue)
+	}
+
+	return ds, nil
+}
+
+// setJiraResolved 根据状态类型设置完成或取消时间
+func setJiraResolved(issue *Issue, resolved time.Time) {
+	if resolved.IsZero() {
+		resolved = issue.UpdatedAt
+	}
+	switch issue.StateType {
+	case model.StateTypeCompleted:
+		issue.CompletedAt = timePtr(resolved)
+	case model.StateTypeCanceled:
+		issue.CanceledAt = timePtr(resolved)
+	}
+}
+
+// parseJiraCSV 解析 Jira CSV 导出
+//
+// Jira CSV 中多值字段（Labels、Comment 等）以重复列名的形式出现，
+// 评论格式为 "时间;作者;内容"。
+func parseJiraCSV(r io.Reader) (*Dataset, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("无效的 Jira CSV 文件: %w", err)
+	}
+
+	// 列名 -> 列索引（允许重复）
+	columns := make(map[string][]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = append(columns[name], i)
+	}
+	get := func(record []string, name string) string {
+		for _, i := range columns[name] {
+			if i < len(record) && strings.TrimSpace(record[i]) != "" {
+				return strings.TrimSpace(record[i])
+			}
+		}
+		return ""
+	}
+	getAll := func(record []string, name string) []string {
+		var values []string
+		for _, i := range columns[name] {
+			if i < len(record) && strings.TrimSpace(record[i]) != "" {
+				values = append(values, strings.TrimSpace(record[i]))
+			}
+		}
+		return values
+	}
+
+	if len(columns["issue key"]) == 0 || len(columns["summary"]) == 0 {
+		return nil, fmt.Errorf("无效的 Jira CSV 文件: 缺少 Issue key 或 Summary 列")
+	}
+
+	ds := &Dataset{}
+	// Jira 的 Parent id 引用的是内部数字 ID，需要转换为 Issue key
+	keyByID := make(map[string]string)
+	parentIDs := make(map[int]string)
+
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("无效的 Jira CSV 文件（第 %d 行）: %w", line, err)
+		}
+
+		key := get(record, "issue key")
+		if key == "" {
+			continue
+		}
+		if id := get(record, "issue id"); id != "" {
+			keyByID[id] = key
+		}
+
+		status := get(record, "status")
+		issue := Issue{
+			ExternalID:  key,
+			Title:       get(record, "summary"),
+			Description: get(record, "description"),
+			State:       status,
+			StateType:   jiraStateType(get(record, "status category"), status, get(record, "resolution")),
+			Priority:    priorityFromName(get(record, "priority")),
+			AssigneeRef: get(record, "assignee"),
+			CreatorRef:  get(record, "reporter"),
+			Labels:      getAll(record, "labels"),
+			CreatedAt:   parseTime(get(record, "created"), jiraTimeLayouts...),
+			UpdatedAt:   parseTime(get(record, "updated"), jiraTimeLayouts...),
+			DueDate:     timePtr(parseTime(get(record, "due date"), jiraTimeLayouts...)),
+		}
+		setJiraResolved(&issue, parseTime(get(record, "resolved"), jiraTimeLayouts...))
+
+		if parent := get(record, "parent"); parent != "" {
+			issue.ParentExternalID = parent
+		} else if parentID := get(record, "parent id"); parentID != "" {
+			parentIDs[len(ds.Issues)] = parentID
+		}
+
+		for i, raw := range getAll(record, "comment") {
+			parts := strings.SplitN(raw, ";", 3)
+			comment := Comment{ExternalID: fmt.Sprintf("%s#%d", key, i+1), Body: raw}
+			if len(parts) == 3 {
+				comment.CreatedAt = parseTime(parts[0], jiraTimeLayouts...)
+				comment.AuthorRef = strings.TrimSpace(parts[1])
+				comment.Body = strings.TrimSpace(parts[2])
+			}
+			issue.Comments = append(issue.Comments, comment)
+		}
+
+		ds.Issues = append(ds.Issues, issue)
+	}
+
+	for idx, parentID := range parentIDs {
+		if key, ok := keyByID[parentID]; ok {
+			ds.Issues[idx].ParentExternalID = key
+		}
+	}
+
+	return ds, nil
+}
@@ -0,0 +1,149 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/liwei0526vip/mylinear/internal/model"
+)
+
+const jiraXMLSample = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="0.92">
+<channel>
+  <title>Jira</title>
+  <item>
+    <title>[ENG-1] 登录失败</title>
+    <key id="10001">ENG-1</key>
+    <summary>登录失败</summary>
+    <description>&lt;p&gt;点击登录后报错&nbsp;500&lt;/p&gt;</description>
+    <priority id="2">High</priority>
+    <status id="3">In Progress</status>
+    <statusCategory id="4" key="indeterminate" colorName="yellow"/>
+    <assignee username="alice">Alice</assignee>
+    <reporter username="bob">Bob</reporter>
+    <labels><label>bug</label><label>auth</label></labels>
+    <created>Mon, 2 Jan 2023 10:00:00 +0000</created>
+    <updated>Tue, 3 Jan 2023 11:30:00 +0000</updated>
+    <comments>
+      <comment id="20001" author="alice" created="Mon, 2 Jan 2023 12:00:00 +0000">正在排查</comment>
+    </comments>
+  </item>
+  <item>
+    <key id="10002">ENG-2</key>
+    <summary>补充单元测试</summary>
+    <parent id="10001">ENG-1</parent>
+    <priority id="5">Lowest</priority>
+    <status id="6">Closed</status>
+    <statusCategory id="3" key="done" colorName="green"/>
+    <resolution id="2">Won't Do</resolution>
+    <assignee accountid="-1">Unassigned</assignee>
+    <reporter username="bob">Bob</reporter>
+    <created>Wed, 4 Jan 2023 09:00:00 +0800</created>
+    <updated>Thu, 5 Jan 2023 09:00:00 +0800</updated>
+    <resolved>Thu, 5 Jan 2023 09:00:00 +0800</resolved>
+  </item>
+</channel>
+</rss>`
+
+func TestParseJiraXML(t *testing.T) {
+	ds, err := Parse(SourceJiraXML, strings.NewReader(jiraXMLSample))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(ds.Issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %d", len(ds.Issues))
+	}
+
+	first := ds.Issues[0]
+	if first.ExternalID != "ENG-1" || first.Title != "登录失败" {
+		t.Errorf("Unexpected issue: %+v", first)
+	}
+	if first.StateType != model.StateTypeStarted || first.Priority != model.PriorityHigh {
+		t.Errorf("Expected started/high, got %s/%d", first.StateType, first.Priority)
+	}
+	if first.AssigneeRef != "alice" || first.CreatorRef != "bob" {
+		t.Errorf("Unexpected users: assignee=%q creator=%q", first.AssigneeRef, first.CreatorRef)
+	}
+	if first.CreatedAt.IsZero() || first.CreatedAt.Day() != 2 {
+		t.Errorf("Created time not preserved: %v", first.CreatedAt)
+	}
+	if len(first.Labels) != 2 || len(first.Comments) != 1 || first.Comments[0].ExternalID != "20001" {
+		t.Errorf("Unexpected labels/comments: %v / %+v", first.Labels, first.Comments)
+	}
+	if !strings.Contains(first.Description, "500") {
+		t.Errorf("Description should keep content, got %q", first.Description)
+	}
+
+	second := ds.Issues[1]
+	if second.ParentExternalID != "ENG-1" {
+		t.Errorf("Expected parent ENG-1, got %q", second.ParentExternalID)
+	}
+	if second.StateType != model.StateTypeCanceled || second.CanceledAt == nil {
+		t.Errorf("Won't Do resolution should map to canceled, got %s", second.StateType)
+	}
+	if second.AssigneeRef != "" {
+		t.Errorf("Unassigned should map to empty ref, got %q", second.AssigneeRef)
+	}
+
+	if len(ds.Users) != 2 || len(ds.Labels) != 2 || len(ds.States) != 2 {
+		t.Errorf("Unexpected collected users=%d labels=%d states=%d", len(ds.Users), len(ds.Labels), len(ds.States))
+	}
+}
+
+func TestParseJiraCSV(t *testing.T) {
+	data := "Summary,Issue key,Issue id,Parent id,Status,Status Category,Priority,Assignee,Reporter,Created,Updated,Labels,Labels,Comment\n" +
+		"父任务,OPS-1,100,,To Do,To Do,Medium,alice,bob,02/Jan/23 10:00 AM,03/Jan/23 10:00 AM,infra,,\n" +
+		"子任务,OPS-2,101,100,Done,Done,Highest,,bob,04/Jan/23 2:30 PM,05/Jan/23 2:30 PM,infra,urgent,05/Jan/23 1:00 PM;alice;已完成\n"
+
+	ds, err := Parse(SourceJiraCSV, strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(ds.Issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %d", len(ds.Issues))
+	}
+
+	child := ds.Issues[1]
+	if child.ParentExternalID != "OPS-1" {
+		t.Errorf("Parent id should resolve to issue key, got %q", child.ParentExternalID)
+	}
+	if child.StateType != model.StateTypeCompleted || child.CompletedAt == nil {
+		t.Errorf("Expected completed, got %s", child.StateType)
+	}
+	if child.Priority != model.PriorityUrgent {
+		t.Errorf("Expected urgent, got %d", child.Priority)
+	}
+	if len(child.Labels) != 2 {
+		t.Errorf("Expected 2 labels from repeated columns, got %v", child.Labels)
+	}
+	if len(child.Comments) != 1 || child.Comments[0].AuthorRef != "alice" || child.Comments[0].Body != "已完成" {
+		t.Errorf("Unexpected comments: %+v", child.Comments)
+	}
+	if child.CreatedAt.Hour() != 14 {
+		t.Errorf("Created time not preserved: %v", child.CreatedAt)
+	}
+
+	if ds.Issues[0].StateType != model.StateTypeUnstarted {
+		t.Errorf("To Do should map to unstarted, got %s", ds.Issues[0].StateType)
+	}
+}
+
+func TestParseJira_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		source Source
+		data   string
+	}{
+		{"XML 格式错误", SourceJiraXML, "<rss><channel><item>"},
+		{"CSV 缺少必需列", SourceJiraCSV, "Title,Status\nA,Done\n"},
+		{"未知来源", Source("trello"), "{}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.source, strings.NewReader(tt.data)); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/liwei0526vip/mylinear/internal/model"
+)
+
+// Linear CSV 导出使用的时间格式
+var linearTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05.000Z",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	// JavaScript Date.toString() 格式，括号中的时区名称会被预先去掉
+	"Mon Jan 02 2006 15:04:05 GMT-0700",
+}
+
+// parseLinearTime 解析 Linear 导出的时间
+func parseLinearTime(value string) time.Time {
+	if i := strings.Index(value, " ("); i > 0 {
+		value = value[:i]
+	}
+	return parseTime(value, linearTimeLayouts...)
+}
+
+// parseLinearCSV 解析 Linear CSV 导出
+//
+// Linear CSV 不包含状态分类，根据 Started / Completed / Canceled 列推断；
+// 导出文件不包含评论。
+func parseLinearCSV(r io.Reader) (*Dataset, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("无效的 Linear CSV 文件: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := columns["id"]; !ok {
+		return nil, fmt.Errorf("无效的 Linear CSV 文件: 缺少 ID 列")
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("无效的 Linear CSV 文件: 缺少 Title 列")
+	}
+	get := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	ds := &Dataset{}
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("无效的 Linear CSV 文件（第 %d 行）: %w", line, err)
+		}
+
+		id := get(record, "id")
+		if id == "" {
+			continue
+		}
+
+		status := get(record, "status")
+		issue := Issue{
+			ExternalID:       id,
+			ParentExternalID: get(record, "parent issue"),
+			Title:            get(record, "title"),
+			Description:      get(record, "description"),
+			State:            status,
+			Priority:         priorityFromName(get(record, "priority")),
+			AssigneeRef:      get(record, "assignee"),
+			CreatorRef:       get(record, "creator"),
+			Labels:           splitList(get(record, "labels")),
+			CreatedAt:        parseLinearTime(get(record, "created")),
+			UpdatedAt:        parseLinearTime(get(record, "updated")),
+			CompletedAt:      timePtr(parseLinearTime(get(record, "completed"))),
+			CanceledAt:       timePtr(parseLinearTime(get(record, "canceled"))),
+			DueDate:          timePtr(parseLinearTime(get(record, "due date"))),
+		}
+
+		switch {
+		case issue.CanceledAt != nil:
+			issue.StateType = model.StateTypeCanceled
+		case issue.CompletedAt != nil:
+			issue.StateType = model.StateTypeCompleted
+		case get(record, "started") != "":
+			issue.StateType = model.StateTypeStarted
+		default:
+			issue.StateType = stateTypeFromName(status)
+		}
+
+		ds.Issues = append(ds.Issues, issue)
+	}
+
+	return ds, nil
+}
@@ -0,0 +1,60 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/liwei0526vip/mylinear/internal/model"
+)
+
+func TestParseLinearCSV(t *testing.T) {
+	data := "ID,Team,Title,Description,Status,Priority,Creator,Assignee,Labels,Created,Updated,Started,Completed,Canceled,Due Date,Parent issue\n" +
+		"ENG-1,Engineering,重构登录,旧代码,In Progress,High,alice@example.com,bob@example.com,\"auth, tech debt\",2023-01-02T10:00:00.000Z,2023-01-03T10:00:00.000Z,2023-01-02T12:00:00.000Z,,,2023-02-01,\n" +
+		"ENG-2,Engineering,拆分服务,,Done,No priority,alice@example.com,,,Mon Jan 09 2023 10:00:00 GMT+0800 (China Standard Time),,,2023-01-10T10:00:00.000Z,,,ENG-1\n" +
+		"ENG-3,Engineering,废弃方案,,Backlog,Low,alice@example.com,,,2023-01-11T10:00:00.000Z,,,,,,\n"
+
+	ds, err := Parse(SourceLinearCSV, strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(ds.Issues) != 3 {
+		t.Fatalf("Expected 3 issues, got %d", len(ds.Issues))
+	}
+
+	tests := []struct {
+		idx       int
+		stateType model.StateType
+		priority  int
+		parent    string
+	}{
+		{0, model.StateTypeStarted, model.PriorityHigh, ""},
+		{1, model.StateTypeCompleted, model.PriorityNone, "ENG-1"},
+		{2, model.StateTypeBacklog, model.PriorityLow, ""},
+	}
+	for _, tt := range tests {
+		issue := ds.Issues[tt.idx]
+		if issue.StateType != tt.stateType || issue.Priority != tt.priority || issue.ParentExternalID != tt.parent {
+			t.Errorf("Issue %s: got state=%s priority=%d parent=%q", issue.ExternalID, issue.StateType, issue.Priority, issue.ParentExternalID)
+		}
+	}
+
+	first := ds.Issues[0]
+	if len(first.Labels) != 2 || first.Labels[1] != "tech debt" {
+		t.Errorf("Unexpected labels: %v", first.Labels)
+	}
+	if first.DueDate == nil || first.DueDate.Month() != 2 {
+		t.Errorf("Due date not parsed: %v", first.DueDate)
+	}
+	if ds.Issues[1].CreatedAt.IsZero() {
+		t.Error("JavaScript date format should be parsed")
+	}
+	if len(ds.Users) != 2 {
+		t.Errorf("Expected 2 users, got %d", len(ds.Users))
+	}
+}
+
+func TestParseLinearCSV_MissingColumns(t *testing.T) {
+	if _, err := Parse(SourceLinearCSV, strings.NewReader("Name,Status\nA,Done\n")); err == nil {
+		t.Error("Expected error for CSV without ID column")
+	}
+}
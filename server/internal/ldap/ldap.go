@@ -0,0 +1,250 @@
+// Package ldap 提供基于 LDAP 绑定的登录和目录读取（用户、用户组），
+// 供认证和目录同步使用；测试可使用 ldaptest 中的内存目录
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	ldapv3 "github.com/go-ldap/ldap/v3"
+)
+
+const (
+	// dialTimeout 连接 LDAP 服务器超时时间
+	dialTimeout = 10 * time.Second
+	// requestTimeout 单个 LDAP 请求超时时间
+	requestTimeout = 30 * time.Second
+	// pageSize 分页搜索的每页条目数（AD 默认最多返回 1000 条）
+	pageSize = 500
+	// adAccountDisabled AD userAccountControl 中的 ACCOUNTDISABLE 标志
+	adAccountDisabled = 0x2
+)
+
+// ErrInvalidCredentials 登录名不存在、不唯一或密码错误
+var ErrInvalidCredentials = errors.New("LDAP 用户名或密码错误")
+
+// Config LDAP 连接和属性映射配置
+type Config struct {
+	URL                  string
+	StartTLS             bool
+	InsecureSkipVerify   bool
+	BindDN               string
+	BindPassword         string
+	BaseDN               string
+	UserFilter           string
+	LoginAttribute       string
+	UIDAttribute         string
+	EmailAttribute       string
+	NameAttribute        string
+	UsernameAttribute    string
+	GroupBaseDN          string
+	GroupFilter          string
+	GroupMemberAttribute string
+}
+
+// Entry 目录中的用户
+type Entry struct {
+	DN string
+	// UID 用户唯一标识，DN 变更（改名、移动 OU）时保持不变
+	UID      string
+	Email    string
+	Username string
+	Name     string
+	// Disabled 账号在目录中已停用（AD userAccountControl）
+	Disabled bool
+}
+
+// Group 目录中的用户组
+type Group struct {
+	DN   string
+	Name string
+	// MemberDNs 成员 DN（已规范化，可与 NormalizeDN(Entry.DN) 比较）
+	MemberDNs []string
+}
+
+// Directory 定义 LDAP 目录接口
+type Directory interface {
+	// Authenticate 查找登录名对应的用户并使用其密码绑定
+	Authenticate(ctx context.Context, login, password string) (*Entry, error)
+	// Users 获取所有符合过滤器的用户
+	Users(ctx context.Context) ([]Entry, error)
+	// Groups 获取所有符合过滤器的用户组
+	Groups(ctx context.Context) ([]Group, error)
+}
+
+// client 基于 go-ldap 的目录实现，每次操作使用独立连接
+type client struct {
+	cfg Config
+}
+
+// NewDirectory 创建 LDAP 目录客户端
+func NewDirectory(cfg Config) Directory {
+	if cfg.GroupBaseDN == "" {
+		cfg.GroupBaseDN = cfg.BaseDN
+	}
+	return &client{cfg: cfg}
+}
+
+// Authenticate 查找登录名对应的用户并使用其密码绑定
+func (c *client) Authenticate(ctx context.Context, login, password string) (*Entry, error) {
+	// 空密码会被服务器视为匿名绑定并返回成功（RFC 4513 5.1.2），必须拒绝
+	if login == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	filter := fmt.Sprintf("(&%s(%s=%s))", c.cfg.UserFilter, c.cfg.LoginAttribute, ldapv3.EscapeFilter(login))
+	result, err := conn.Search(c.userSearchRequest(filter, 2))
+	if err != nil && !ldapv3.IsErrorWithCode(err, ldapv3.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("搜索 LDAP 用户失败: %w", err)
+	}
+	if result == nil || len(result.Entries) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+	entry := c.toEntry(result.Entries[0])
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldapv3.IsErrorWithCode(err, ldapv3.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("LDAP 绑定失败: %w", err)
+	}
+	return &entry, nil
+}
+
+// Users 获取所有符合过滤器的用户
+func (c *client) Users(ctx context.Context) ([]Entry, error) {
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	result, err := conn.SearchWithPaging(c.userSearchRequest(c.cfg.UserFilter, 0), pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("搜索 LDAP 用户失败: %w", err)
+	}
+	entries := make([]Entry, 0, len(result.Entries))
+	for _, e := range result.Entries {
+		entries = append(entries, c.toEntry(e))
+	}
+	return entries, nil
+}
+
+// Groups 获取所有符合过滤器的用户组
+func (c *client) Groups(ctx context.Context) ([]Group, error) {
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	req := ldapv3.NewSearchRequest(c.cfg.GroupBaseDN, ldapv3.ScopeWholeSubtree, ldapv3.NeverDerefAliases,
+		0, int(requestTimeout.Seconds()), false, c.cfg.GroupFilter,
+		[]string{"cn", c.cfg.GroupMemberAttribute}, nil)
+	result, err := conn.SearchWithPaging(req, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("搜索 LDAP 用户组失败: %w", err)
+	}
+
+	groups := make([]Group, 0, len(result.Entries))
+	for _, e := range result.Entries {
+		members := e.GetAttributeValues(c.cfg.GroupMemberAttribute)
+		group := Group{DN: e.DN, Name: e.GetAttributeValue("cn"), MemberDNs: make([]string, 0, len(members))}
+		for _, member := range members {
+			group.MemberDNs = append(group.MemberDNs, NormalizeDN(member))
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// connect 建立连接、按需启用 StartTLS 并使用服务账号绑定
+func (c *client) connect(ctx context.Context) (*ldapv3.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// InsecureSkipVerify 只在配置中显式开启时生效
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.cfg.InsecureSkipVerify}
+	conn, err := ldapv3.DialURL(c.cfg.URL,
+		ldapv3.DialWithDialer(&net.Dialer{Timeout: dialTimeout}),
+		ldapv3.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("连接 LDAP 服务器失败: %w", err)
+	}
+	conn.SetTimeout(requestTimeout)
+
+	if c.cfg.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("LDAP StartTLS 失败: %w", err)
+		}
+	}
+
+	if c.cfg.BindDN != "" {
+		if err := conn.Bind(c.cfg.BindDN, c.cfg.BindPassword); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("LDAP 服务账号绑定失败: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// userSearchRequest 构造用户搜索请求
+func (c *client) userSearchRequest(filter string, sizeLimit int) *ldapv3.SearchRequest {
+	attributes := []string{c.cfg.EmailAttribute, c.cfg.NameAttribute, c.cfg.UsernameAttribute, "userAccountControl"}
+	if c.cfg.UIDAttribute != "" {
+		attributes = append(attributes, c.cfg.UIDAttribute)
+	}
+	return ldapv3.NewSearchRequest(c.cfg.BaseDN, ldapv3.ScopeWholeSubtree, ldapv3.NeverDerefAliases,
+		sizeLimit, int(requestTimeout.Seconds()), false, filter, attributes, nil)
+}
+
+// toEntry 按属性映射读取用户条目
+func (c *client) toEntry(e *ldapv3.Entry) Entry {
+	entry := Entry{
+		DN:       e.DN,
+		UID:      e.DN,
+		Email:    strings.ToLower(strings.TrimSpace(e.GetAttributeValue(c.cfg.EmailAttribute))),
+		Username: e.GetAttributeValue(c.cfg.UsernameAttribute),
+		Name:     e.GetAttributeValue(c.cfg.NameAttribute),
+	}
+	if c.cfg.UIDAttribute != "" {
+		// AD objectGUID 是二进制值，使用十六进制表示
+		if raw := e.GetRawAttributeValue(c.cfg.UIDAttribute); len(raw) > 0 {
+			if utf8.Valid(raw) {
+				entry.UID = string(raw)
+			} else {
+				entry.UID = hex.EncodeToString(raw)
+			}
+		}
+	}
+	if uac, err := strconv.Atoi(e.GetAttributeValue("userAccountControl")); err == nil {
+		entry.Disabled = uac&adAccountDisabled != 0
+	}
+	return entry
+}
+
+// NormalizeDN 规范化 DN 以便比较（属性类型和值不区分大小写，去除多余空格）
+func NormalizeDN(dn string) string {
+	parsed, err := ldapv3.ParseDN(dn)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(dn))
+	}
+	return strings.ToLower(parsed.String())
+}
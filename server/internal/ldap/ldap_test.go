@@ -0,0 +1,127 @@
+package ldap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	ldapv3 "github.com/go-ldap/ldap/v3"
+)
+
+func TestNormalizeDN(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"uid=Alice,ou=Users,dc=Example,dc=org", "uid=alice, ou=users, dc=example, dc=org"},
+		{"CN=Bob Smith,OU=Staff,DC=corp,DC=local", "cn=bob smith,ou=staff,dc=corp,dc=local"},
+	}
+	for _, tt := range tests {
+		if NormalizeDN(tt.a) != NormalizeDN(tt.b) {
+			t.Errorf("NormalizeDN(%q) = %q, NormalizeDN(%q) = %q", tt.a, NormalizeDN(tt.a), tt.b, NormalizeDN(tt.b))
+		}
+	}
+}
+
+// TestDirectory_OpenLDAP 针对真实 OpenLDAP 的集成测试，需要设置：
+//
+//	LDAP_TEST_URL=ldap://localhost:1389
+//	LDAP_TEST_BIND_DN=cn=admin,dc=mylinear,dc=local
+//	LDAP_TEST_BIND_PASSWORD=admin
+//	LDAP_TEST_BASE_DN=dc=mylinear,dc=local
+//
+// 可使用 docker compose --profile ldap up -d 启动
+func TestDirectory_OpenLDAP(t *testing.T) {
+	url := os.Getenv("LDAP_TEST_URL")
+	if url == "" {
+		t.Skip("未设置 LDAP_TEST_URL，跳过 OpenLDAP 集成测试")
+	}
+	bindDN := os.Getenv("LDAP_TEST_BIND_DN")
+	bindPassword := os.Getenv("LDAP_TEST_BIND_PASSWORD")
+
+	admin, err := ldapv3.DialURL(url)
+	if err != nil {
+		t.Fatalf("连接 OpenLDAP 失败: %v", err)
+	}
+	defer admin.Close()
+	if err := admin.Bind(bindDN, bindPassword); err != nil {
+		t.Fatalf("管理员绑定失败: %v", err)
+	}
+
+	// 在独立的 OU 下创建测试数据，结束后删除
+	baseDN := fmt.Sprintf("ou=mylinear-test-%d,%s", time.Now().UnixNano(), os.Getenv("LDAP_TEST_BASE_DN"))
+	aliceDN := "uid=alice," + baseDN
+	groupDN := "cn=engineers," + baseDN
+
+	ou := ldapv3.NewAddRequest(baseDN, nil)
+	ou.Attribute("objectClass", []string{"organizationalUnit"})
+	alice := ldapv3.NewAddRequest(aliceDN, nil)
+	alice.Attribute("objectClass", []string{"inetOrgPerson"})
+	alice.Attribute("cn", []string{"Alice Liddell"})
+	alice.Attribute("sn", []string{"Liddell"})
+	alice.Attribute("uid", []string{"alice"})
+	alice.Attribute("mail", []string{"Alice@Example.org"})
+	alice.Attribute("userPassword", []string{"wonderland"})
+	group := ldapv3.NewAddRequest(groupDN, nil)
+	group.Attribute("objectClass", []string{"groupOfNames"})
+	group.Attribute("cn", []string{"engineers"})
+	group.Attribute("member", []string{"UID=alice, " + baseDN})
+	for _, req := range []*ldapv3.AddRequest{ou, alice, group} {
+		if err := admin.Add(req); err != nil {
+			t.Fatalf("创建测试条目 %s 失败: %v", req.DN, err)
+		}
+	}
+	defer func() {
+		for _, dn := range []string{groupDN, aliceDN, baseDN} {
+			_ = admin.Del(ldapv3.NewDelRequest(dn, nil))
+		}
+	}()
+
+	dir := NewDirectory(Config{
+		URL:                  url,
+		BindDN:               bindDN,
+		BindPassword:         bindPassword,
+		BaseDN:               baseDN,
+		UserFilter:           "(objectClass=inetOrgPerson)",
+		LoginAttribute:       "uid",
+		UIDAttribute:         "entryUUID",
+		EmailAttribute:       "mail",
+		NameAttribute:        "cn",
+		UsernameAttribute:    "uid",
+		GroupFilter:          "(objectClass=groupOfNames)",
+		GroupMemberAttribute: "member",
+	})
+	ctx := context.Background()
+
+	entry, err := dir.Authenticate(ctx, "alice", "wonderland")
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if entry.Email != "alice@example.org" || entry.Name != "Alice Liddell" || entry.UID == entry.DN {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+
+	for _, password := range []string{"wrong", ""} {
+		if _, err := dir.Authenticate(ctx, "alice", password); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("密码 %q 应认证失败, got %v", password, err)
+		}
+	}
+	if _, err := dir.Authenticate(ctx, "*", "wonderland"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("登录名中的过滤器字符应被转义, got %v", err)
+	}
+
+	users, err := dir.Users(ctx)
+	if err != nil || len(users) != 1 {
+		t.Fatalf("Users() = %v, err = %v", users, err)
+	}
+
+	groups, err := dir.Groups(ctx)
+	if err != nil || len(groups) != 1 {
+		t.Fatalf("Groups() = %v, err = %v", groups, err)
+	}
+	if len(groups[0].MemberDNs) != 1 || groups[0].MemberDNs[0] != NormalizeDN(entry.DN) {
+		t.Errorf("成员 DN = %v, 期望 %s", groups[0].MemberDNs, NormalizeDN(entry.DN))
+	}
+}
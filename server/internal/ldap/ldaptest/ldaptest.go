@@ -0,0 +1,127 @@
+// Package ldaptest 提供用于测试的内存 LDAP 目录，实现 ldap.Directory
+package ldaptest
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/liwei0526vip/mylinear/internal/ldap"
+)
+
+// user 目录中的用户及其密码
+type user struct {
+	entry    ldap.Entry
+	password string
+}
+
+// Directory 内存 LDAP 目录，登录名为 Entry.Username
+type Directory struct {
+	mu     sync.Mutex
+	users  map[string]*user
+	groups map[string]*ldap.Group
+	// Err 设置后所有操作返回该错误，用于模拟目录不可用
+	Err error
+}
+
+// NewDirectory 创建空目录
+func NewDirectory() *Directory {
+	return &Directory{
+		users:  map[string]*user{},
+		groups: map[string]*ldap.Group{},
+	}
+}
+
+// AddUser 添加或替换用户，以 UID 为键；UID 为空时使用 DN
+func (d *Directory) AddUser(entry ldap.Entry, password string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if entry.UID == "" {
+		entry.UID = entry.DN
+	}
+	d.users[entry.UID] = &user{entry: entry, password: password}
+}
+
+// RemoveUser 删除用户
+func (d *Directory) RemoveUser(uid string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.users, uid)
+}
+
+// SetDisabled 设置用户在目录中的停用状态
+func (d *Directory) SetDisabled(uid string, disabled bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if u, ok := d.users[uid]; ok {
+		u.entry.Disabled = disabled
+	}
+}
+
+// SetGroup 添加或替换用户组，成员为用户 DN
+func (d *Directory) SetGroup(name string, memberDNs ...string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	group := &ldap.Group{DN: "cn=" + name + ",ou=groups,dc=example,dc=org", Name: name}
+	for _, dn := range memberDNs {
+		group.MemberDNs = append(group.MemberDNs, ldap.NormalizeDN(dn))
+	}
+	d.groups[name] = group
+}
+
+// Authenticate 按用户名查找用户并校验密码
+func (d *Directory) Authenticate(ctx context.Context, login, password string) (*ldap.Entry, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	if login == "" || password == "" {
+		return nil, ldap.ErrInvalidCredentials
+	}
+	for _, u := range d.users {
+		if strings.EqualFold(u.entry.Username, login) {
+			if u.password != password {
+				return nil, ldap.ErrInvalidCredentials
+			}
+			entry := u.entry
+			return &entry, nil
+		}
+	}
+	return nil, ldap.ErrInvalidCredentials
+}
+
+// Users 获取所有用户（按 DN 排序）
+func (d *Directory) Users(ctx context.Context) ([]ldap.Entry, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	entries := make([]ldap.Entry, 0, len(d.users))
+	for _, u := range d.users {
+		entries = append(entries, u.entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].DN < entries[j].DN })
+	return entries, nil
+}
+
+// Groups 获取所有用户组（按名称排序）
+func (d *Directory) Groups(ctx context.Context) ([]ldap.Group, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.Err != nil {
+		return nil, d.Err
+	}
+	groups := make([]ldap.Group, 0, len(d.groups))
+	for _, g := range d.groups {
+		group := *g
+		group.MemberDNs = append([]string(nil), g.MemberDNs...)
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups, nil
+}
+
+var _ ldap.Directory = (*Directory)(nil)
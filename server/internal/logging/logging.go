@@ -0,0 +1,99 @@
+// Package logging 基于 log/slog 的结构化日志：按配置选择级别和输出格式，
+// 并自动附加上下文中的请求 ID 和链路追踪 ID
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 输出格式
+const (
+	// FormatText key=value 文本格式，便于本地阅读
+	FormatText = "text"
+	// FormatJSON 每行一个 JSON 对象，便于日志系统采集
+	FormatJSON = "json"
+)
+
+// Config 日志配置
+type Config struct {
+	// Level 最低输出级别：debug、info、warn 或 error
+	Level string
+	// Format 输出格式：text 或 json
+	Format string
+}
+
+// requestIDKey 请求 ID 在 context 中的键
+type requestIDKey struct{}
+
+// New 按配置创建日志记录器
+func New(cfg Config, w io.Writer) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, fmt.Errorf("不支持的日志级别 %q，可选 debug、info、warn 或 error", cfg.Level)
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "", FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("不支持的日志格式 %q，可选 %s 或 %s", cfg.Format, FormatText, FormatJSON)
+	}
+	return slog.New(contextHandler{handler}), nil
+}
+
+// Setup 创建日志记录器并设为默认；标准库 log 包的输出也会转为 info 级别的结构化日志
+func Setup(cfg Config, w io.Writer) error {
+	logger, err := New(cfg, w)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// WithRequestID 将请求 ID 放入 context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID 读取 context 中的请求 ID，没有时返回空字符串
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// contextHandler 在每条日志上附加 context 中的请求 ID 和 trace ID
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle 附加上下文字段后交给底层 Handler 输出
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		r.AddAttrs(slog.String("request_id", requestID))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs 保持包装，避免 logger.With 之后丢失上下文字段
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup 保持包装
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(Config{Level: "warn", Format: FormatJSON}, &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := WithRequestID(context.Background(), "req-123")
+	logger.InfoContext(ctx, "低于级别的日志")
+	logger.With("component", "test").WarnContext(ctx, "发送通知失败", "issue_id", "ISSUE-1")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("输出行数 = %d, want 1: %s", len(lines), buf.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("输出不是 JSON: %v", err)
+	}
+	if entry["msg"] != "发送通知失败" || entry["request_id"] != "req-123" || entry["issue_id"] != "ISSUE-1" || entry["component"] != "test" {
+		t.Errorf("日志内容 = %v", entry)
+	}
+}
+
+func TestNew_Text(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(Config{Level: "DEBUG", Format: FormatText}, &buf)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	logger.DebugContext(context.Background(), "调试")
+	if !strings.Contains(buf.String(), "level=DEBUG") || strings.Contains(buf.String(), "request_id") {
+		t.Errorf("输出 = %s", buf.String())
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	if _, err := New(Config{Level: "verbose", Format: FormatText}, &bytes.Buffer{}); err == nil {
+		t.Error("不支持的级别应返回错误")
+	}
+	if _, err := New(Config{Level: "info", Format: "xml"}, &bytes.Buffer{}); err == nil {
+		t.Error("不支持的格式应返回错误")
+	}
+}
+
+func TestSetup(t *testing.T) {
+	prev := slog.Default()
+	defer slog.SetDefault(prev)
+
+	var buf bytes.Buffer
+	if err := Setup(Config{Level: "info", Format: FormatJSON}, &buf); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	slog.InfoContext(WithRequestID(context.Background(), "req-456"), "默认记录器")
+	if !strings.Contains(buf.String(), `"request_id":"req-456"`) {
+		t.Errorf("输出 = %s", buf.String())
+	}
+}
@@ -0,0 +1,158 @@
+// Package mail 提供邮件发送，Sender 可替换为 SMTP、日志输出或测试用的内存实现（见 mailtest）
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// dialTimeout 连接 SMTP 服务器超时时间
+const dialTimeout = 10 * time.Second
+
+// Message 纯文本邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender 邮件发送接口
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config SMTP 连接配置
+type Config struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	// From 发件人地址，可包含显示名称（如 MyLinear <noreply@example.com>）
+	From string
+}
+
+// SMTPSender 通过 SMTP 发送邮件，端口 465 使用隐式 TLS，其他端口在服务器支持时使用 STARTTLS
+type SMTPSender struct {
+	cfg Config
+}
+
+// NewSMTPSender 创建 SMTP 发送器
+func NewSMTPSender(cfg Config) *SMTPSender {
+	return &SMTPSender{cfg: cfg}
+}
+
+// Send 发送邮件
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	from, err := mail.ParseAddress(s.cfg.From)
+	if err != nil {
+		return fmt.Errorf("发件人地址无效: %w", err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("收件人地址无效: %w", err)
+	}
+
+	client, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP 认证失败: %w", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("SMTP 发送失败: %w", err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("SMTP 发送失败: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP 发送失败: %w", err)
+	}
+	if _, err := w.Write(buildMessage(from, to, msg)); err != nil {
+		w.Close()
+		return fmt.Errorf("SMTP 发送失败: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP 发送失败: %w", err)
+	}
+	return client.Quit()
+}
+
+// dial 连接 SMTP 服务器，连接的截止时间取 ctx 截止时间
+func (s *SMTPSender) dial(ctx context.Context) (*smtp.Client, error) {
+	addr := net.JoinHostPort(s.cfg.Host, s.cfg.Port)
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("连接 SMTP 服务器失败: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	tlsConfig := &tls.Config{ServerName: s.cfg.Host}
+	if s.cfg.Port == "465" {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("连接 SMTP 服务器失败: %w", err)
+	}
+	if ok, _ := client.Extension("STARTTLS"); ok && s.cfg.Port != "465" {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP StartTLS 失败: %w", err)
+		}
+	}
+	return client, nil
+}
+
+// buildMessage 生成 UTF-8 纯文本邮件，正文使用 base64 编码
+func buildMessage(from, to *mail.Address, msg Message) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("From: " + from.String() + "\r\n")
+	buf.WriteString("To: " + to.String() + "\r\n")
+	buf.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", msg.Subject) + "\r\n")
+	buf.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes()
+}
+
+// LogSender 将邮件输出到日志，用于未配置 SMTP 的开发环境
+type LogSender struct{}
+
+// NewLogSender 创建日志发送器
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+// Send 将邮件内容写入日志
+func (LogSender) Send(ctx context.Context, msg Message) error {
+	slog.InfoContext(ctx, "邮件（未配置 SMTP，未实际发送）", "to", msg.To, "subject", msg.Subject, "body", strings.TrimSpace(msg.Body))
+	return nil
+}
@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestBuildMessage(t *testing.T) {
+	from, _ := mail.ParseAddress("MyLinear <noreply@example.com>")
+	to, _ := mail.ParseAddress("alice@example.com")
+	body := strings.Repeat("重置密码链接: https://linear.example.com/reset?token=abc\n", 5)
+
+	raw := buildMessage(from, to, Message{To: to.Address, Subject: "重置密码", Body: body})
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil || subject != "重置密码" {
+		t.Errorf("Subject = %q, %v", subject, err)
+	}
+	if got := parsed.Header.Get("To"); got != "<alice@example.com>" {
+		t.Errorf("To = %q", got)
+	}
+	encoded, _ := io.ReadAll(parsed.Body)
+	for _, line := range strings.Split(strings.TrimSpace(string(encoded)), "\r\n") {
+		if len(line) > 76 {
+			t.Errorf("正文行长度 %d 超过 76", len(line))
+		}
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(encoded), "\r\n", ""))
+	if err != nil || string(decoded) != body {
+		t.Errorf("正文解码结果不一致: %v", err)
+	}
+}
+
+func TestSMTPSender_InvalidAddress(t *testing.T) {
+	sender := NewSMTPSender(Config{Host: "localhost", Port: "25", From: "not an address"})
+	if err := sender.Send(context.Background(), Message{To: "alice@example.com"}); err == nil {
+		t.Error("发件人地址无效时应返回错误")
+	}
+
+	sender = NewSMTPSender(Config{Host: "localhost", Port: "25", From: "noreply@example.com"})
+	if err := sender.Send(context.Background(), Message{To: "alice"}); err == nil {
+		t.Error("收件人地址无效时应返回错误")
+	}
+}
@@ -0,0 +1,50 @@
+// Package mailtest 提供用于测试的内存邮件发送器，实现 mail.Sender
+package mailtest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/liwei0526vip/mylinear/internal/mail"
+)
+
+// Sender 记录所有发送的邮件
+type Sender struct {
+	mu       sync.Mutex
+	messages []mail.Message
+	// Err 设置后 Send 返回该错误，用于模拟发送失败
+	Err error
+}
+
+// NewSender 创建内存发送器
+func NewSender() *Sender {
+	return &Sender{}
+}
+
+// Send 记录邮件
+func (s *Sender) Send(_ context.Context, msg mail.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+// Messages 返回已发送邮件的副本
+func (s *Sender) Messages() []mail.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]mail.Message(nil), s.messages...)
+}
+
+// Last 返回最后一封邮件，没有邮件时 ok 为 false
+func (s *Sender) Last() (mail.Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.messages) == 0 {
+		return mail.Message{}, false
+	}
+	return s.messages[len(s.messages)-1], true
+}
@@ -0,0 +1,168 @@
+// Package metrics 定义服务的 Prometheus 指标，并提供 HTTP、数据库、Redis、对象存储、
+// 通知和后台任务的采集辅助函数；所有指标注册在独立的 Registry 上，通过 Handler 暴露
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace 指标名称前缀
+const namespace = "mylinear"
+
+// Registry 服务的指标注册表，包含 Go 运行时和进程指标
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP 请求总数",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP 请求处理耗时",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "数据库查询耗时",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	dbQueryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "数据库查询错误数（不含记录不存在）",
+	}, []string{"operation", "table"})
+
+	redisCommandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "redis_command_duration_seconds",
+		Help:      "Redis 命令耗时，管道按整体计时",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5},
+	}, []string{"command"})
+
+	redisCommandErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redis_command_errors_total",
+		Help:      "Redis 命令错误数（不含键不存在）",
+	}, []string{"command"})
+
+	storageOperationDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_operation_duration_seconds",
+		Help:      "MinIO 对象存储操作耗时",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	storageOperationErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_operation_errors_total",
+		Help:      "MinIO 对象存储操作错误数",
+	}, []string{"operation"})
+
+	notificationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_created_total",
+		Help:      "创建的站内通知数（按通知类型）",
+	}, []string{"type"})
+
+	jobQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "job_queue_depth",
+		Help:      "等待执行的后台任务数",
+	})
+
+	jobWaitDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_wait_duration_seconds",
+		Help:      "后台任务从创建到开始执行的等待时长",
+		Buckets:   []float64{.01, .1, .5, 1, 5, 10, 30, 60, 300, 900},
+	}, []string{"type"})
+
+	jobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "job_duration_seconds",
+		Help:      "后台任务执行耗时",
+		Buckets:   []float64{.1, .5, 1, 5, 10, 30, 60, 300, 900, 3600},
+	}, []string{"type", "status"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+		dbQueryDuration,
+		dbQueryErrors,
+		redisCommandDuration,
+		redisCommandErrors,
+		storageOperationDuration,
+		storageOperationErrors,
+		notificationsTotal,
+		jobQueueDepth,
+		jobWaitDuration,
+		jobDuration,
+	)
+}
+
+// Handler 返回 Prometheus 抓取接口；token 不为空时要求 Authorization: Bearer <token>
+func Handler(token string) http.Handler {
+	h := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+	if token == "" {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// ObserveHTTPRequest 记录一次 HTTP 请求，route 为路由模板（如 /api/v1/issues/:id）
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequestsTotal.WithLabelValues(method, route, code).Inc()
+	httpRequestDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObserveStorage 记录一次对象存储操作
+func ObserveStorage(operation string, start time.Time, err error) {
+	storageOperationDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		storageOperationErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// NotificationCreated 记录创建了一条通知
+func NotificationCreated(notificationType string) {
+	notificationsTotal.WithLabelValues(notificationType).Inc()
+}
+
+// SetJobQueueDepth 更新等待执行的后台任务数
+func SetJobQueueDepth(depth int) {
+	jobQueueDepth.Set(float64(depth))
+}
+
+// ObserveJob 记录一个后台任务的等待时长和执行耗时
+func ObserveJob(jobType, status string, wait, duration time.Duration) {
+	jobWaitDuration.WithLabelValues(jobType).Observe(wait.Seconds())
+	jobDuration.WithLabelValues(jobType, status).Observe(duration.Seconds())
+}
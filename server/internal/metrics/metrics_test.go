@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// scrape 抓取指标接口，返回状态码和响应内容
+func scrape(t *testing.T, h http.Handler, token string) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	body, _ := io.ReadAll(w.Body)
+	return w.Code, string(body)
+}
+
+func TestHandler_Token(t *testing.T) {
+	h := Handler("scrape-secret")
+
+	if code, _ := scrape(t, h, ""); code != http.StatusUnauthorized {
+		t.Errorf("未提供令牌状态码 = %d, want 401", code)
+	}
+	if code, _ := scrape(t, h, "wrong"); code != http.StatusUnauthorized {
+		t.Errorf("令牌错误状态码 = %d, want 401", code)
+	}
+	if code, body := scrape(t, h, "scrape-secret"); code != http.StatusOK || !strings.Contains(body, "go_goroutines") {
+		t.Errorf("令牌正确状态码 = %d", code)
+	}
+
+	// 未配置令牌时不校验
+	if code, _ := scrape(t, Handler(""), ""); code != http.StatusOK {
+		t.Errorf("未配置令牌状态码 = %d, want 200", code)
+	}
+}
+
+func TestObserve(t *testing.T) {
+	ObserveHTTPRequest(http.MethodGet, "/api/v1/issues/:id", http.StatusOK, 20*time.Millisecond)
+	ObserveStorage("put_object", time.Now(), errors.New("timeout"))
+	NotificationCreated("issue_assigned")
+	SetJobQueueDepth(3)
+	ObserveJob("issue_import", "succeeded", time.Second, 2*time.Second)
+	observeRedis("get", time.Now(), redis.Nil)
+	observeRedis("set", time.Now(), errors.New("connection refused"))
+
+	_, body := scrape(t, Handler(""), "")
+	for _, want := range []string{
+		`mylinear_http_requests_total{method="GET",route="/api/v1/issues/:id",status="200"} 1`,
+		`mylinear_storage_operation_errors_total{operation="put_object"} 1`,
+		`mylinear_notifications_created_total{type="issue_assigned"} 1`,
+		`mylinear_job_queue_depth 3`,
+		`mylinear_job_duration_seconds_count{status="succeeded",type="issue_import"} 1`,
+		`mylinear_redis_command_duration_seconds_count{command="get"} 1`,
+		`mylinear_redis_command_errors_total{command="set"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("指标输出缺少 %s", want)
+		}
+	}
+
+	// 键不存在不计为错误
+	if strings.Contains(body, `mylinear_redis_command_errors_total{command="get"}`) {
+		t.Error("redis.Nil 不应计为错误")
+	}
+}
+
+func TestGormPlugin_Register(t *testing.T) {
+	// 不连接数据库，只验证回调能注册到各类操作上
+	db, err := gorm.Open(postgres.Open("postgres://localhost:1/metrics_test"), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	if err := db.Use(NewGormPlugin()); err != nil {
+		t.Fatalf("db.Use() error = %v", err)
+	}
+	if db.Callback().Query().Get("metrics:after_query") == nil {
+		t.Error("未注册查询回调")
+	}
+}
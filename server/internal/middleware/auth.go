@@ -7,7 +7,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
 	"github.com/liwei0526vip/mylinear/internal/service"
+	"github.com/liwei0526vip/mylinear/internal/store"
 )
 
 // 上下文键
@@ -20,9 +22,39 @@ type UserContext struct {
 	UserID string
 	Email  string
 	Role   string
+	// APIKeyID 通过 API Key 认证时的 Key ID，JWT 认证时为空
+	APIKeyID string
+	// ClientID 通过 OAuth 访问令牌认证时的应用 client_id
+	ClientID string
+	// Scopes API Key / OAuth 令牌的权限范围，登录 JWT 认证时为空（不受范围限制）
+	Scopes []string
+	// SessionID 登录 JWT 所属的会话 ID
+	SessionID string
+	// WorkspaceID 当前所在的工作区，Role 为用户在该工作区的角色；为空时使用用户的主工作区
+	WorkspaceID string
 }
 
-// Auth JWT 认证中间件
+// IsAPIKey 是否通过 API Key 认证
+func (u *UserContext) IsAPIKey() bool {
+	return u.APIKeyID != ""
+}
+
+// IsOAuth 是否通过 OAuth 访问令牌认证
+func (u *UserContext) IsOAuth() bool {
+	return u.ClientID != ""
+}
+
+// HasScope 检查是否拥有指定权限范围，登录 JWT 认证始终拥有全部权限
+func (u *UserContext) HasScope(scope string) bool {
+	if !u.IsAPIKey() && !u.IsOAuth() {
+		return true
+	}
+	return model.ScopesAllow(u.Scopes, scope)
+}
+
+// Auth 认证中间件，支持 Bearer JWT（登录令牌或 OAuth 访问令牌）和 Bearer API Key（mlk_ 前缀）
+// API Key 和 OAuth 令牌默认按请求方法校验权限范围：只读请求需要 read，其他请求需要 write
+// 通过 LimitWrites 启用写请求限流时，认证成功后同时检查写请求频率
 func Auth(jwtService service.JWTService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -48,9 +80,15 @@ func Auth(jwtService service.JWTService) gin.HandlerFunc {
 
 		tokenString := parts[1]
 
+		if model.IsAPIKey(tokenString) {
+			authenticateAPIKey(c, tokenString)
+			return
+		}
+
 		// 验证令牌
 		claims, err := jwtService.ValidateToken(tokenString)
-		if err != nil {
+		// 刷新令牌和两步验证挑战令牌不能用于访问 API
+		if err != nil || claims.Type != service.TokenTypeAccess {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "unauthorized",
 				"message": "认证令牌无效",
@@ -59,18 +97,173 @@ func Auth(jwtService service.JWTService) gin.HandlerFunc {
 			return
 		}
 
+		if claims.IsOAuth() {
+			authenticateOAuthToken(c, claims)
+			return
+		}
+
+		// 会话吊销后，属于该会话的访问令牌立即失效
+		if claims.SessionID != "" && !isSessionActive(c, claims.SessionID) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error":   "unauthorized",
+				"message": "认证令牌已被吊销",
+			})
+			c.Abort()
+			return
+		}
+
 		// 将用户信息存入上下文
 		userCtx := &UserContext{
-			UserID: claims.UserID,
-			Email:  claims.Email,
-			Role:   claims.Role,
+			UserID:      claims.UserID,
+			Email:       claims.Email,
+			Role:        claims.Role,
+			SessionID:   claims.SessionID,
+			WorkspaceID: claims.WorkspaceID,
 		}
 		c.Set(ContextKeyUser, userCtx)
 
+		if !allowWrite(c, userCtx) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// authenticateAPIKey 使用 API Key 认证
+// 未授予 admin 范围的 Key 即使属于管理员，也只拥有普通成员权限
+func authenticateAPIKey(c *gin.Context, rawKey string) {
+	db := GetDB(c)
+	if db == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "unauthorized",
+			"message": "当前接口不支持 API Key 认证",
+		})
+		c.Abort()
+		return
+	}
+
+	key, err := store.AuthenticateAPIKey(c.Request.Context(), db, rawKey)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "unauthorized",
+			"message": "API Key 无效、已过期或已吊销",
+		})
+		c.Abort()
+		return
+	}
+
+	// API Key 始终作用于用户的主工作区
+	userCtx := &UserContext{
+		UserID:      key.UserID.String(),
+		Email:       key.User.Email,
+		Role:        string(model.ScopedRole(key.User.Role, key.Scopes)),
+		APIKeyID:    key.ID.String(),
+		Scopes:      key.Scopes,
+		WorkspaceID: key.User.WorkspaceID.String(),
+	}
+	c.Set(ContextKeyUser, userCtx)
+
+	if !userCtx.HasScope(methodScope(c.Request.Method)) {
+		abortInsufficientScope(c)
+		return
+	}
+
+	if !allowWrite(c, userCtx) {
+		return
+	}
+
+	c.Next()
+}
+
+// authenticateOAuthToken 校验 OAuth 访问令牌对应的授权未被吊销
+// 令牌中的角色在签发时已按权限范围降级
+func authenticateOAuthToken(c *gin.Context, claims *service.TokenClaims) {
+	db := GetDB(c)
+	grantID, err := uuid.Parse(claims.GrantID)
+	if db == nil || err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "unauthorized",
+			"message": "认证令牌无效",
+		})
+		c.Abort()
+		return
+	}
+
+	active, err := store.IsOAuthGrantActive(c.Request.Context(), db, grantID)
+	if err != nil || !active {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "unauthorized",
+			"message": "认证令牌已被吊销",
+		})
+		c.Abort()
+		return
+	}
+
+	userCtx := &UserContext{
+		UserID:   claims.UserID,
+		Email:    claims.Email,
+		Role:     claims.Role,
+		ClientID: claims.ClientID,
+		Scopes:   claims.Scopes,
+	}
+	c.Set(ContextKeyUser, userCtx)
+
+	if !userCtx.HasScope(methodScope(c.Request.Method)) {
+		abortInsufficientScope(c)
+		return
+	}
+
+	if !allowWrite(c, userCtx) {
+		return
+	}
+
+	c.Next()
+}
+
+// isSessionActive 检查登录会话未被吊销
+func isSessionActive(c *gin.Context, sessionID string) bool {
+	db := GetDB(c)
+	id, err := uuid.Parse(sessionID)
+	if db == nil || err != nil {
+		return false
+	}
+	active, err := store.IsSessionActive(c.Request.Context(), db, id)
+	return err == nil && active
+}
+
+// methodScope 请求方法需要的默认权限范围
+func methodScope(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return model.APIKeyScopeRead
+	default:
+		return model.APIKeyScopeWrite
+	}
+}
+
+// RequireScope API Key / OAuth 令牌权限范围中间件，用于需要更高范围的路由组
+// 必须在 Auth 之后使用；登录 JWT 认证不受限制
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := GetCurrentUser(c)
+		if user == nil || !user.HasScope(scope) {
+			abortInsufficientScope(c)
+			return
+		}
 		c.Next()
 	}
 }
 
+// abortInsufficientScope 返回权限范围不足
+func abortInsufficientScope(c *gin.Context) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":   "insufficient_scope",
+		"message": "令牌权限范围不足",
+	})
+	c.Abort()
+}
+
 // GetCurrentUser 获取当前用户上下文
 func GetCurrentUser(c *gin.Context) *UserContext {
 	val, exists := c.Get(ContextKeyUser)
@@ -97,6 +290,19 @@ func GetCurrentUserID(c *gin.Context) uuid.UUID {
 	return userID
 }
 
+// GetCurrentWorkspaceID 获取当前所在的工作区 ID，令牌未指定工作区时返回 uuid.Nil
+func GetCurrentWorkspaceID(c *gin.Context) uuid.UUID {
+	user := GetCurrentUser(c)
+	if user == nil {
+		return uuid.Nil
+	}
+	workspaceID, err := uuid.Parse(user.WorkspaceID)
+	if err != nil {
+		return uuid.Nil
+	}
+	return workspaceID
+}
+
 // GetCurrentUserRole 获取当前用户角色
 func GetCurrentUserRole(c *gin.Context) string {
 	user := GetCurrentUser(c)
@@ -66,6 +66,33 @@ func TestAuth_Middleware(t *testing.T) {
 			wantStatusCode: http.StatusUnauthorized,
 			wantUserID:     false,
 		},
+		{
+			name: "刷新令牌不能访问API",
+			setupAuth: func(req *http.Request) {
+				token, _ := jwtService.GenerateRefreshToken(uuid.New(), uuid.New())
+				req.Header.Set("Authorization", "Bearer "+token)
+			},
+			wantStatusCode: http.StatusUnauthorized,
+			wantUserID:     false,
+		},
+		{
+			name: "无法校验会话时拒绝会话令牌",
+			setupAuth: func(req *http.Request) {
+				token, _ := jwtService.GenerateSessionAccessToken(uuid.New(), "test@example.com", model.RoleMember, uuid.New(), uuid.New())
+				req.Header.Set("Authorization", "Bearer "+token)
+			},
+			wantStatusCode: http.StatusUnauthorized,
+			wantUserID:     false,
+		},
+		{
+			name: "两步验证挑战令牌不能访问API",
+			setupAuth: func(req *http.Request) {
+				token, _ := jwtService.GenerateTwoFactorToken(uuid.New())
+				req.Header.Set("Authorization", "Bearer "+token)
+			},
+			wantStatusCode: http.StatusUnauthorized,
+			wantUserID:     false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -412,3 +439,98 @@ func TestRequireGlobalAdmin(t *testing.T) {
 		})
 	}
 }
+
+// TestAuth_APIKeyWithoutDB 测试未注入数据库连接时拒绝 API Key
+func TestAuth_APIKeyWithoutDB(t *testing.T) {
+	jwtService := service.NewJWTService(&config.Config{JWTSecret: "apikey-test-secret", JWTAccessExpiry: 15 * time.Minute})
+
+	router := gin.New()
+	router.Use(Auth(jwtService))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+model.APIKeyPrefix+"not-a-real-key")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("状态码 = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+// TestRequireScope 测试 API Key 权限范围校验
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *UserContext
+		scope      string
+		wantStatus int
+	}{
+		{"JWT 不受限制", &UserContext{UserID: uuid.New().String()}, model.APIKeyScopeAdmin, http.StatusOK},
+		{"read 范围访问 read", &UserContext{APIKeyID: "k", Scopes: []string{model.APIKeyScopeRead}}, model.APIKeyScopeRead, http.StatusOK},
+		{"read 范围访问 write", &UserContext{APIKeyID: "k", Scopes: []string{model.APIKeyScopeRead}}, model.APIKeyScopeWrite, http.StatusForbidden},
+		{"write 范围访问 admin", &UserContext{APIKeyID: "k", Scopes: []string{model.APIKeyScopeWrite}}, model.APIKeyScopeAdmin, http.StatusForbidden},
+		{"admin 范围访问 write", &UserContext{APIKeyID: "k", Scopes: []string{model.APIKeyScopeAdmin}}, model.APIKeyScopeWrite, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set(ContextKeyUser, tt.user)
+			})
+			router.Use(RequireScope(tt.scope))
+			router.GET("/test", func(c *gin.Context) {
+				c.JSON(http.StatusOK, gin.H{"status": "ok"})
+			})
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("状态码 = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+// TestMethodScope 测试请求方法对应的默认权限范围
+func TestMethodScope(t *testing.T) {
+	tests := map[string]string{
+		http.MethodGet:    model.APIKeyScopeRead,
+		http.MethodHead:   model.APIKeyScopeRead,
+		http.MethodPost:   model.APIKeyScopeWrite,
+		http.MethodPut:    model.APIKeyScopeWrite,
+		http.MethodPatch:  model.APIKeyScopeWrite,
+		http.MethodDelete: model.APIKeyScopeWrite,
+	}
+	for method, want := range tests {
+		if got := methodScope(method); got != want {
+			t.Errorf("methodScope(%s) = %s, want %s", method, got, want)
+		}
+	}
+}
+
+// TestAuth_OAuthTokenWithoutDB 测试无法校验授权状态时拒绝 OAuth 访问令牌
+func TestAuth_OAuthTokenWithoutDB(t *testing.T) {
+	jwtService := service.NewJWTService(&config.Config{JWTSecret: "oauth-test-secret", JWTAccessExpiry: 15 * time.Minute})
+	token, _ := jwtService.GenerateOAuthAccessToken(uuid.New(), "oauth@example.com", model.RoleMember, "client", uuid.New(), []string{model.APIKeyScopeRead})
+
+	router := gin.New()
+	router.Use(Auth(jwtService))
+	router.GET("/test", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("状态码 = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,79 @@
+// Package middleware 提供 HTTP 中间件
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// guestRoutes 访客可以访问的路由（方法 + 路由模板后缀），其余路由一律拒绝
+// 访客可以查看所在团队、创建和编辑 Issue、发表评论，不能删除 Issue，
+// 不能管理标签、工作流状态、项目和团队，也不能查看工作区范围的列表
+var guestRoutes = []string{
+	// 团队列表只返回访客所在的团队
+	"GET /teams",
+	"GET /teams/:teamId",
+	"GET /teams/:teamId/members",
+	"GET /teams/:teamId/workflow-states",
+	"GET /teams/:teamId/labels",
+	"GET /teams/:teamId/projects",
+
+	"GET /teams/:teamId/issues",
+	"POST /teams/:teamId/issues",
+	"GET /issues/:id",
+	"PUT /issues/:id",
+	"PUT /issues/:id/position",
+	"POST /issues/:id/subscribe",
+	"DELETE /issues/:id/subscribe",
+	"GET /issues/:id/subscribers",
+	"GET /issues/:id/activities",
+	"GET /issues/:id/branch-name",
+	"GET /issues/:id/links",
+
+	"GET /issues/:id/comments",
+	"POST /issues/:id/comments",
+	// 只能编辑、删除自己的评论，由评论服务检查
+	"PUT /comments/:commentId",
+	"DELETE /comments/:commentId",
+}
+
+// RestrictGuest 访客权限中间件
+// 访客只能访问 guestRoutes 中的路由，其他角色不受影响；
+// 访客只能访问所在团队的资源，由各路由上的 Authorize 检查。必须在 Auth 之后使用
+func RestrictGuest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !IsGuest(c) {
+			c.Next()
+			return
+		}
+
+		if !guestAllowed(c.Request.Method, c.FullPath()) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "forbidden",
+				"message": "访客无权限执行此操作",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// IsGuest 检查当前用户是否为访客
+func IsGuest(c *gin.Context) bool {
+	return GetCurrentUserRole(c) == "guest"
+}
+
+// guestAllowed 检查路由是否允许访客访问
+func guestAllowed(method, fullPath string) bool {
+	for _, route := range guestRoutes {
+		routeMethod, path, _ := strings.Cut(route, " ")
+		if method == routeMethod && strings.HasSuffix(fullPath, path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestRestrictGuest_WithoutDB(t *testing.T) {
+	guest := &UserContext{UserID: uuid.New().String(), Role: "guest"}
+	member := &UserContext{UserID: uuid.New().String(), Role: "member"}
+	teamID := uuid.New().String()
+
+	tests := []struct {
+		name       string
+		user       *UserContext
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{
+			name:       "成员不受限制",
+			user:       member,
+			method:     http.MethodDelete,
+			path:       "/api/v1/issues/" + uuid.New().String(),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "访客可以获取团队列表",
+			user:       guest,
+			method:     http.MethodGet,
+			path:       "/api/v1/teams",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "访客不能删除 Issue",
+			user:       guest,
+			method:     http.MethodDelete,
+			path:       "/api/v1/issues/" + uuid.New().String(),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "访客不能创建标签",
+			user:       guest,
+			method:     http.MethodPost,
+			path:       "/api/v1/teams/" + teamID + "/labels",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "访客不能创建团队",
+			user:       guest,
+			method:     http.MethodPost,
+			path:       "/api/v1/teams",
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) { c.Set(ContextKeyUser, tt.user) })
+			router.Use(RestrictGuest())
+			handler := func(c *gin.Context) { c.Status(http.StatusOK) }
+			v1 := router.Group("/api/v1")
+			v1.GET("/teams", handler)
+			v1.POST("/teams", handler)
+			v1.POST("/teams/:teamId/labels", handler)
+			v1.DELETE("/issues/:id", handler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v, body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGuestAllowed(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{http.MethodGet, "/api/v1/teams/:teamId/issues", true},
+		{http.MethodPut, "/api/v1/issues/:id", true},
+		{http.MethodPost, "/api/v1/issues/:id/comments", true},
+		{http.MethodGet, "/api/v1/teams/:teamId/issues/export", false},
+		{http.MethodPost, "/api/v1/issues/:id/move", false},
+		{http.MethodPost, "/api/v1/issues/:id/restore", false},
+		{http.MethodPost, "/api/v1/teams/:teamId/workflow-states", false},
+		{http.MethodGet, "/api/v1/projects/:id", false},
+		{http.MethodPost, "/api/v1/workspaces/:workspaceId/projects", false},
+	}
+
+	for _, tt := range tests {
+		if got := guestAllowed(tt.method, tt.path); got != tt.want {
+			t.Errorf("guestAllowed(%s, %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Package middleware 提供 HTTP 中间件
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/gorm"
+)
+
+// ResolveIssueIdentifier Issue 标识符解析中间件
+// 对 /issues/:id 路由，将 :id 中的标识符（如 ENG-42，包括历史标识符）替换为 Issue UUID，
+// 使所有接受 UUID 的 Issue 接口同时支持标识符；标识符只在当前工作区（令牌未指定时为主工作区）内解析
+func ResolveIssueIdentifier() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.Contains(c.FullPath(), "/issues/:id") {
+			c.Next()
+			return
+		}
+
+		id := c.Param("id")
+		if _, err := uuid.Parse(id); err == nil {
+			c.Next()
+			return
+		}
+		teamKey, number, ok := model.ParseIdentifier(id)
+		if !ok {
+			// 既不是 UUID 也不是标识符，交给处理器返回参数错误
+			c.Next()
+			return
+		}
+
+		db := GetDB(c)
+		if db == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "internal_error",
+				"message": "数据库连接不可用",
+			})
+			c.Abort()
+			return
+		}
+
+		// 令牌未指定工作区时先确定主工作区，否则同一标识符在多个工作区存在时无法解析
+		workspaceID, ok := scopeWorkspaceID(c)
+		if !ok {
+			return
+		}
+
+		issueID, err := store.ResolveIssueIdentifier(c.Request.Context(), db, workspaceID, teamKey, number)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error":   "not_found",
+					"message": "Issue 不存在",
+				})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "internal_error",
+					"message": "解析 Issue 标识符失败",
+				})
+			}
+			c.Abort()
+			return
+		}
+
+		setParam(c, "id", issueID.String())
+		c.Next()
+	}
+}
+
+// setParam 替换路由参数
+func setParam(c *gin.Context, key, value string) {
+	for i := range c.Params {
+		if c.Params[i].Key == key {
+			c.Params[i].Value = value
+			return
+		}
+	}
+}
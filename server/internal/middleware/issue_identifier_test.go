@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+)
+
+func TestResolveIssueIdentifier_Passthrough(t *testing.T) {
+	issueID := uuid.New().String()
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantParam  string
+	}{
+		{
+			name:       "UUID 原样传递",
+			path:       "/issues/" + issueID,
+			wantStatus: http.StatusOK,
+			wantParam:  issueID,
+		},
+		{
+			name:       "非标识符交给处理器",
+			path:       "/issues/not-an-identifier",
+			wantStatus: http.StatusOK,
+			wantParam:  "not-an-identifier",
+		},
+		{
+			name:       "标识符但数据库不可用",
+			path:       "/issues/ENG-42",
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "非 Issue 路由不处理",
+			path:       "/projects/ENG-42",
+			wantStatus: http.StatusOK,
+			wantParam:  "ENG-42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ResolveIssueIdentifier())
+			var gotParam string
+			handler := func(c *gin.Context) {
+				gotParam = c.Param("id")
+				c.Status(http.StatusOK)
+			}
+			router.GET("/issues/:id", handler)
+			router.GET("/projects/:id", handler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && gotParam != tt.wantParam {
+				t.Errorf("param id = %q, want %q", gotParam, tt.wantParam)
+			}
+		})
+	}
+}
+
+func TestResolveIssueIdentifier(t *testing.T) {
+	if testPermissionDB == nil {
+		t.Skip("数据库连接不可用，跳过集成测试")
+	}
+
+	tx := testPermissionDB.Begin()
+	defer tx.Rollback()
+
+	// 两个工作区中存在相同的标识符 RI-1
+	prefix := uuid.New().String()[:8]
+	user := &model.User{
+		Email:        prefix + "_resolve@example.com",
+		Username:     prefix + "_resolve",
+		Name:         "Resolve",
+		PasswordHash: "hash",
+		Role:         model.RoleMember,
+	}
+	newIssue := func(name string) (*model.Workspace, *model.Issue) {
+		workspace := &model.Workspace{Name: name + " " + prefix, Slug: name + "-" + prefix}
+		if err := tx.Create(workspace).Error; err != nil {
+			t.Fatalf("创建测试工作区失败: %v", err)
+		}
+		if user.ID == uuid.Nil {
+			user.WorkspaceID = workspace.ID
+			if err := tx.Create(user).Error; err != nil {
+				t.Fatalf("创建测试用户失败: %v", err)
+			}
+		}
+		team := &model.Team{WorkspaceID: workspace.ID, Name: name + " Team " + prefix, Key: "RI"}
+		if err := tx.Create(team).Error; err != nil {
+			t.Fatalf("创建测试团队失败: %v", err)
+		}
+		state := &model.WorkflowState{TeamID: team.ID, Name: "Todo", Type: model.StateTypeUnstarted}
+		if err := tx.Create(state).Error; err != nil {
+			t.Fatalf("创建工作流状态失败: %v", err)
+		}
+		issue := &model.Issue{TeamID: team.ID, Number: 1, Title: "Issue", StatusID: state.ID, CreatedByID: user.ID}
+		if err := tx.Create(issue).Error; err != nil {
+			t.Fatalf("创建测试 Issue 失败: %v", err)
+		}
+		return workspace, issue
+	}
+	_, homeIssue := newIssue("resolve-home")
+	other, otherIssue := newIssue("resolve-other")
+
+	tests := []struct {
+		name        string
+		workspaceID string
+		wantID      uuid.UUID
+	}{
+		{"令牌未指定工作区时在主工作区内解析", "", homeIssue.ID},
+		{"在令牌指定的工作区内解析", other.ID.String(), otherIssue.ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("db", tx)
+				c.Set(ContextKeyUser, &UserContext{UserID: user.ID.String(), Role: string(user.Role), WorkspaceID: tt.workspaceID})
+			})
+			router.Use(ResolveIssueIdentifier())
+			var gotParam string
+			router.GET("/issues/:id", func(c *gin.Context) {
+				gotParam = c.Param("id")
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/issues/RI-1", nil))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %v, want 200, body: %s", w.Code, w.Body.String())
+			}
+			if gotParam != tt.wantID.String() {
+				t.Errorf("param id = %q, want %q", gotParam, tt.wantID)
+			}
+		})
+	}
+}
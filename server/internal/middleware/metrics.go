@@ -0,0 +1,23 @@
+// Package middleware 提供 HTTP 中间件
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liwei0526vip/mylinear/internal/metrics"
+)
+
+// Metrics 记录 HTTP 请求数和耗时，按路由模板而非实际路径分组；未匹配的路由统一记为 unmatched
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liwei0526vip/mylinear/internal/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	router := gin.New()
+	router.Use(Metrics())
+	router.GET("/metrics-test/items/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	for _, path := range []string{"/metrics-test/items/1", "/metrics-test/items/2", "/metrics-test/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	metrics.Handler("").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+
+	// 按路由模板聚合，不同 ID 计入同一条序列
+	if !strings.Contains(string(body), `mylinear_http_requests_total{method="GET",route="/metrics-test/items/:id",status="204"} 2`) {
+		t.Error("缺少按路由模板聚合的请求数")
+	}
+	if !strings.Contains(string(body), `route="unmatched",status="404"`) {
+		t.Error("未匹配的路由应记为 unmatched")
+	}
+}
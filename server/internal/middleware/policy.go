@@ -0,0 +1,123 @@
+// Package middleware 提供 HTTP 中间件
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/policy"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/gorm"
+)
+
+// Authorize 资源访问控制中间件
+// 根据路由参数确定被访问的资源（:commentId、/issues/:id、/issues/lookup/:identifier、
+// /projects/:id、/workflow-states/:id、:teamId），由 policy 判断当前用户能否执行 action；
+// 不属于当前工作区（令牌未指定时为主工作区）的资源按不存在处理。
+// 用户不能查看资源时返回 404，能查看但无权执行操作时返回 403；
+// 参数不是 UUID 时交给处理器返回参数错误。必须在 Auth 和 ResolveIssueIdentifier 之后使用
+func Authorize(action policy.Action) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := GetDB(c)
+		if db == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "internal_error",
+				"message": "数据库连接不可用",
+			})
+			c.Abort()
+			return
+		}
+
+		// 先确定当前工作区，通过标识符查找 Issue 时同样使用
+		workspaceID, ok := scopeWorkspaceID(c)
+		if !ok {
+			return
+		}
+
+		resource, ok, err := routeResource(c, db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "internal_error",
+				"message": "检查权限失败",
+			})
+			c.Abort()
+			return
+		}
+		if !ok {
+			c.Next()
+			return
+		}
+
+		subject := policy.Subject{
+			UserID:      GetCurrentUserID(c),
+			Role:        model.Role(GetCurrentUserRole(c)),
+			WorkspaceID: workspaceID,
+		}
+		err = policy.NewEnforcer(db).Authorize(c.Request.Context(), subject, action, resource)
+		switch {
+		case err == nil:
+			c.Next()
+		case errors.Is(err, policy.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "not_found",
+				"message": err.Error(),
+			})
+			c.Abort()
+		case errors.Is(err, policy.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "forbidden",
+				"message": err.Error(),
+			})
+			c.Abort()
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "internal_error",
+				"message": "检查权限失败",
+			})
+			c.Abort()
+		}
+	}
+}
+
+// routeResource 根据路由参数确定被访问的资源，没有可识别的资源时返回 false
+func routeResource(c *gin.Context, db *gorm.DB) (policy.Resource, bool, error) {
+	fullPath := c.FullPath()
+
+	if id, err := uuid.Parse(c.Param("commentId")); err == nil {
+		return policy.Resource{Type: policy.ResourceComment, ID: id}, true, nil
+	}
+	if strings.Contains(fullPath, "/issues/:id") {
+		id, err := uuid.Parse(c.Param("id"))
+		return policy.Resource{Type: policy.ResourceIssue, ID: id}, err == nil, nil
+	}
+	if strings.Contains(fullPath, "/issues/lookup/:identifier") {
+		teamKey, number, ok := model.ParseIdentifier(c.Param("identifier"))
+		if !ok {
+			return policy.Resource{}, false, nil
+		}
+		id, err := store.ResolveIssueIdentifier(c.Request.Context(), db, GetCurrentWorkspaceID(c), teamKey, number)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return policy.Resource{}, false, nil
+			}
+			return policy.Resource{}, false, err
+		}
+		return policy.Resource{Type: policy.ResourceIssue, ID: id}, true, nil
+	}
+	if strings.Contains(fullPath, "/projects/:id") {
+		id, err := uuid.Parse(c.Param("id"))
+		return policy.Resource{Type: policy.ResourceProject, ID: id}, err == nil, nil
+	}
+	if strings.Contains(fullPath, "/workflow-states/:id") {
+		id, err := uuid.Parse(c.Param("id"))
+		return policy.Resource{Type: policy.ResourceWorkflowState, ID: id}, err == nil, nil
+	}
+	if id, err := uuid.Parse(c.Param("teamId")); err == nil {
+		return policy.Resource{Type: policy.ResourceTeam, ID: id}, true, nil
+	}
+	return policy.Resource{}, false, nil
+}
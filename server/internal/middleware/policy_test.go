@@ -0,0 +1,207 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/policy"
+	"github.com/liwei0526vip/mylinear/internal/service"
+)
+
+// TestAuthorize 测试资源访问控制中间件
+func TestAuthorize(t *testing.T) {
+	if testPermissionDB == nil {
+		t.Skip("数据库连接不可用，跳过集成测试")
+	}
+
+	cfg := &config.Config{
+		JWTSecret:        "authorize-test-secret",
+		JWTAccessExpiry:  15 * time.Minute,
+		JWTRefreshExpiry: 7 * 24 * time.Hour,
+	}
+	jwtService := service.NewJWTService(cfg)
+
+	tx := testPermissionDB.Begin()
+	defer tx.Rollback()
+
+	prefix := uuid.New().String()[:8]
+
+	workspace := &model.Workspace{
+		Name: "Authorize Test " + prefix,
+		Slug: "authorize-test-" + prefix,
+	}
+	if err := tx.Create(workspace).Error; err != nil {
+		t.Fatalf("创建测试工作区失败: %v", err)
+	}
+	team := &model.Team{WorkspaceID: workspace.ID, Name: "Team " + prefix, Key: "AZ"}
+	if err := tx.Create(team).Error; err != nil {
+		t.Fatalf("创建测试团队失败: %v", err)
+	}
+
+	newUser := func(name string, role model.Role) *model.User {
+		user := &model.User{
+			WorkspaceID:  workspace.ID,
+			Email:        prefix + "_" + name + "@example.com",
+			Username:     prefix + "_" + name,
+			Name:         name,
+			PasswordHash: "hash",
+			Role:         role,
+		}
+		if err := tx.Create(user).Error; err != nil {
+			t.Fatalf("创建测试用户失败: %v", err)
+		}
+		return user
+	}
+	owner := newUser("owner", model.RoleMember)
+	member := newUser("member", model.RoleMember)
+	guest := newUser("guest", model.RoleGuest)
+	nonMember := newUser("non", model.RoleMember)
+	nonMemberGuest := newUser("nonguest", model.RoleGuest)
+	admin := newUser("admin", model.RoleAdmin)
+
+	tx.Create(&model.TeamMember{TeamID: team.ID, UserID: owner.ID, Role: model.RoleAdmin, JoinedAt: time.Now()})
+	tx.Create(&model.TeamMember{TeamID: team.ID, UserID: member.ID, Role: model.RoleMember, JoinedAt: time.Now()})
+	tx.Create(&model.TeamMember{TeamID: team.ID, UserID: guest.ID, Role: model.RoleMember, JoinedAt: time.Now()})
+
+	state := &model.WorkflowState{TeamID: team.ID, Name: "Todo", Type: model.StateTypeUnstarted}
+	if err := tx.Create(state).Error; err != nil {
+		t.Fatalf("创建工作流状态失败: %v", err)
+	}
+	issue := &model.Issue{TeamID: team.ID, Number: 1, Title: "Issue", StatusID: state.ID, CreatedByID: owner.ID}
+	if err := tx.Create(issue).Error; err != nil {
+		t.Fatalf("创建测试 Issue 失败: %v", err)
+	}
+	comment := &model.Comment{IssueID: issue.ID, UserID: guest.ID, Body: "comment"}
+	if err := tx.Create(comment).Error; err != nil {
+		t.Fatalf("创建测试评论失败: %v", err)
+	}
+	project := &model.Project{WorkspaceID: workspace.ID, Name: "Project", Teams: pq.StringArray{team.ID.String()}}
+	if err := tx.Create(project).Error; err != nil {
+		t.Fatalf("创建测试项目失败: %v", err)
+	}
+
+	// 私有团队只对成员可见，管理员也需要先加入
+	privateTeam := &model.Team{WorkspaceID: workspace.ID, Name: "Private " + prefix, Key: "AZP", IsPrivate: true}
+	if err := tx.Create(privateTeam).Error; err != nil {
+		t.Fatalf("创建私有团队失败: %v", err)
+	}
+	tx.Create(&model.TeamMember{TeamID: privateTeam.ID, UserID: member.ID, Role: model.RoleMember, JoinedAt: time.Now()})
+
+	// 未关联团队的项目属于整个工作区
+	workspaceProject := &model.Project{WorkspaceID: workspace.ID, Name: "Workspace Project"}
+	if err := tx.Create(workspaceProject).Error; err != nil {
+		t.Fatalf("创建测试项目失败: %v", err)
+	}
+
+	// 其他工作区的管理员
+	otherWorkspace := &model.Workspace{Name: "Authorize Other " + prefix, Slug: "authorize-other-" + prefix}
+	if err := tx.Create(otherWorkspace).Error; err != nil {
+		t.Fatalf("创建测试工作区失败: %v", err)
+	}
+	otherAdmin := &model.User{
+		WorkspaceID:  otherWorkspace.ID,
+		Email:        prefix + "_otheradmin@example.com",
+		Username:     prefix + "_otheradmin",
+		Name:         "otheradmin",
+		PasswordHash: "hash",
+		Role:         model.RoleAdmin,
+	}
+	if err := tx.Create(otherAdmin).Error; err != nil {
+		t.Fatalf("创建测试用户失败: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		user       *model.User
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{"成员查看 Issue", member, http.MethodGet, "/issues/" + issue.ID.String(), http.StatusOK},
+		{"成员删除 Issue", member, http.MethodDelete, "/issues/" + issue.ID.String(), http.StatusOK},
+		{"非成员查看公开团队的 Issue", nonMember, http.MethodGet, "/issues/" + issue.ID.String(), http.StatusOK},
+		{"非成员删除公开团队的 Issue", nonMember, http.MethodDelete, "/issues/" + issue.ID.String(), http.StatusForbidden},
+		{"非成员查看公开团队", nonMember, http.MethodGet, "/teams/" + team.ID.String(), http.StatusOK},
+		{"非成员查看私有团队", nonMember, http.MethodGet, "/teams/" + privateTeam.ID.String(), http.StatusNotFound},
+		{"访客非成员查看公开团队", nonMemberGuest, http.MethodGet, "/teams/" + team.ID.String(), http.StatusNotFound},
+		{"管理员查看 Issue", admin, http.MethodGet, "/issues/" + issue.ID.String(), http.StatusOK},
+		{"访客编辑评论", guest, http.MethodPut, "/comments/" + comment.ID.String(), http.StatusOK},
+		{"非成员编辑评论", nonMember, http.MethodPut, "/comments/" + comment.ID.String(), http.StatusForbidden},
+		{"访客删除 Issue", guest, http.MethodDelete, "/issues/" + issue.ID.String(), http.StatusForbidden},
+		{"成员更新团队设置", member, http.MethodPut, "/teams/" + team.ID.String(), http.StatusForbidden},
+		{"Owner 更新团队设置", owner, http.MethodPut, "/teams/" + team.ID.String(), http.StatusOK},
+		{"成员查看项目", member, http.MethodGet, "/projects/" + project.ID.String(), http.StatusOK},
+		{"成员删除项目", member, http.MethodDelete, "/projects/" + project.ID.String(), http.StatusForbidden},
+		{"非成员查看公开团队的项目", nonMember, http.MethodGet, "/projects/" + project.ID.String(), http.StatusOK},
+		{"访客修改工作流状态", guest, http.MethodPut, "/workflow-states/" + state.ID.String(), http.StatusForbidden},
+		{"通过标识符查找", nonMember, http.MethodGet, "/issues/lookup/AZ-1", http.StatusOK},
+		{"访客通过标识符查找", nonMemberGuest, http.MethodGet, "/issues/lookup/AZ-1", http.StatusNotFound},
+		{"私有团队成员查看团队", member, http.MethodGet, "/teams/" + privateTeam.ID.String(), http.StatusOK},
+		{"管理员未加入私有团队", admin, http.MethodGet, "/teams/" + privateTeam.ID.String(), http.StatusNotFound},
+		{"管理员查看公开团队", admin, http.MethodGet, "/teams/" + team.ID.String(), http.StatusOK},
+		{"不存在的 Issue", member, http.MethodGet, "/issues/" + uuid.New().String(), http.StatusNotFound},
+		{"成员查看工作区项目", member, http.MethodGet, "/projects/" + workspaceProject.ID.String(), http.StatusOK},
+		{"成员修改工作区项目", member, http.MethodPut, "/projects/" + workspaceProject.ID.String(), http.StatusForbidden},
+		{"访客查看工作区项目", guest, http.MethodGet, "/projects/" + workspaceProject.ID.String(), http.StatusNotFound},
+		{"管理员删除工作区项目", admin, http.MethodDelete, "/projects/" + workspaceProject.ID.String(), http.StatusOK},
+		{"其他工作区管理员查看团队", otherAdmin, http.MethodGet, "/teams/" + team.ID.String(), http.StatusNotFound},
+		{"其他工作区管理员修改工作流状态", otherAdmin, http.MethodPut, "/workflow-states/" + state.ID.String(), http.StatusNotFound},
+		{"其他工作区管理员修改项目", otherAdmin, http.MethodPut, "/projects/" + project.ID.String(), http.StatusNotFound},
+		{"其他工作区管理员查看工作区项目", otherAdmin, http.MethodGet, "/projects/" + workspaceProject.ID.String(), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _ := jwtService.GenerateAccessToken(tt.user.ID, tt.user.Email, tt.user.Role)
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("db", tx)
+			})
+			router.Use(Auth(jwtService))
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			router.GET("/issues/:id", Authorize(policy.ActionRead), ok)
+			router.DELETE("/issues/:id", Authorize(policy.ActionManage), ok)
+			router.GET("/issues/lookup/:identifier", Authorize(policy.ActionRead), ok)
+			router.PUT("/comments/:commentId", Authorize(policy.ActionWrite), ok)
+			router.GET("/teams/:teamId", Authorize(policy.ActionRead), ok)
+			router.PUT("/teams/:teamId", Authorize(policy.ActionAdmin), ok)
+			router.GET("/projects/:id", Authorize(policy.ActionRead), ok)
+			router.PUT("/projects/:id", Authorize(policy.ActionManage), ok)
+			router.DELETE("/projects/:id", Authorize(policy.ActionAdmin), ok)
+			router.PUT("/workflow-states/:id", Authorize(policy.ActionManage), ok)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("状态码 = %d, want %d, body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthorize_WithoutDB(t *testing.T) {
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(ContextKeyUser, &UserContext{UserID: uuid.New().String(), Role: "member"})
+	})
+	router.GET("/issues/:id", Authorize(policy.ActionRead), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/issues/"+uuid.New().String(), nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("状态码 = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,96 @@
+// Package middleware 提供 HTTP 中间件
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/ratelimit"
+)
+
+// contextKeyWriteLimit 上下文中保存写请求限流配置的键
+const contextKeyWriteLimit = "write_rate_limit"
+
+// writeLimit 认证后写请求的限流配置
+type writeLimit struct {
+	limiter ratelimit.Limiter
+	rule    ratelimit.Rule
+}
+
+// RateLimitByIP 按客户端 IP 限流，用于登录、注册等未认证接口
+// scope 区分不同接口的计数；limiter 为 nil 或规则未生效时不限流
+func RateLimitByIP(limiter ratelimit.Limiter, rule ratelimit.Rule, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !applyRateLimit(c, limiter, rule, scope+":ip:"+c.ClientIP()) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// LimitWrites 为认证后的写请求启用限流，按 API Key、OAuth 应用或用户分别计数
+// 需要在 Auth 之前注册（通常注册在 /api/v1 上），计数在 Auth 认证成功后进行
+func LimitWrites(limiter ratelimit.Limiter, rule ratelimit.Rule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter != nil && rule.Enabled() {
+			c.Set(contextKeyWriteLimit, &writeLimit{limiter: limiter, rule: rule})
+		}
+		c.Next()
+	}
+}
+
+// allowWrite 检查当前用户的写请求频率，超出限制时中止请求并返回 false
+// 只读请求和未启用写请求限流时直接放行
+func allowWrite(c *gin.Context, user *UserContext) bool {
+	if methodScope(c.Request.Method) == model.APIKeyScopeRead {
+		return true
+	}
+	val, exists := c.Get(contextKeyWriteLimit)
+	if !exists {
+		return true
+	}
+	limit, ok := val.(*writeLimit)
+	if !ok {
+		return true
+	}
+	return applyRateLimit(c, limit.limiter, limit.rule, "write:"+user.rateLimitKey())
+}
+
+// rateLimitKey 写请求限流的计数对象：API Key 和 OAuth 应用独立计数，登录令牌按用户计数
+func (u *UserContext) rateLimitKey() string {
+	switch {
+	case u.IsAPIKey():
+		return "api_key:" + u.APIKeyID
+	case u.IsOAuth():
+		return "oauth:" + u.ClientID + ":" + u.UserID
+	default:
+		return "user:" + u.UserID
+	}
+}
+
+// applyRateLimit 消耗一次额度并写入限流响应头，超出限制时返回 429 并中止请求
+// Redis 不可用时只输出日志并放行，避免限流故障导致整个 API 不可用
+func applyRateLimit(c *gin.Context, limiter ratelimit.Limiter, rule ratelimit.Rule, key string) bool {
+	if limiter == nil || !rule.Enabled() {
+		return true
+	}
+
+	res, err := limiter.Allow(c.Request.Context(), key, rule)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "限流检查失败，已放行", "key", key, "error", err)
+		return true
+	}
+
+	ratelimit.SetHeaders(c.Writer.Header(), rule, res)
+	if !res.Allowed {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error":   "too_many_requests",
+			"message": "请求过于频繁，请稍后再试",
+		})
+		c.Abort()
+		return false
+	}
+	return true
+}
@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/ratelimit"
+	"github.com/liwei0526vip/mylinear/internal/service"
+)
+
+// memoryLimiter 测试用的固定计数限流器
+type memoryLimiter struct {
+	counts map[string]int
+	err    error
+}
+
+func newMemoryLimiter() *memoryLimiter {
+	return &memoryLimiter{counts: make(map[string]int)}
+}
+
+func (l *memoryLimiter) Allow(_ context.Context, key string, rule ratelimit.Rule) (*ratelimit.Result, error) {
+	if l.err != nil {
+		return nil, l.err
+	}
+	l.counts[key]++
+	count := l.counts[key]
+	res := &ratelimit.Result{Allowed: count <= rule.Limit, Limit: rule.Limit, Remaining: max(rule.Limit-count, 0), Reset: rule.Period}
+	if !res.Allowed {
+		res.RetryAfter = rule.Period
+	}
+	return res, nil
+}
+
+func TestRateLimitByIP(t *testing.T) {
+	limiter := newMemoryLimiter()
+	rule := ratelimit.Rule{Limit: 2, Period: time.Minute}
+
+	router := gin.New()
+	router.POST("/login", RateLimitByIP(limiter, rule, "login"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = ip + ":12345"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < rule.Limit; i++ {
+		if w := request("10.0.0.1"); w.Code != http.StatusOK {
+			t.Fatalf("第 %d 次请求状态码 = %d", i+1, w.Code)
+		}
+	}
+
+	w := request("10.0.0.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("超出限制后状态码 = %d, want 429", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "60" || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("响应头 = %v", w.Header())
+	}
+
+	// 其他 IP 不受影响
+	if w := request("10.0.0.2"); w.Code != http.StatusOK {
+		t.Errorf("其他 IP 状态码 = %d", w.Code)
+	}
+
+	// 限流器故障时放行
+	limiter.err = errors.New("redis 不可用")
+	if w := request("10.0.0.1"); w.Code != http.StatusOK {
+		t.Errorf("限流器故障时状态码 = %d, want 200", w.Code)
+	}
+}
+
+func TestRateLimitByIP_UntrustedForwardedFor(t *testing.T) {
+	limiter := newMemoryLimiter()
+	rule := ratelimit.Rule{Limit: 1, Period: time.Minute}
+
+	// 与 main.go 一致：未配置 TRUSTED_PROXIES 时不信任任何代理
+	router := gin.New()
+	if err := router.SetTrustedProxies(nil); err != nil {
+		t.Fatalf("SetTrustedProxies() error = %v", err)
+	}
+	router.POST("/login", RateLimitByIP(limiter, rule, "login"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = "10.0.0.1:12345"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := request("1.1.1.1"); code != http.StatusOK {
+		t.Fatalf("首次请求状态码 = %d", code)
+	}
+	// 伪造 X-Forwarded-For 不能换到新的计数
+	if code := request("2.2.2.2"); code != http.StatusTooManyRequests {
+		t.Errorf("更换 X-Forwarded-For 后状态码 = %d, want 429", code)
+	}
+}
+
+func TestLimitWrites(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret:        "middleware-test-secret",
+		JWTAccessExpiry:  15 * time.Minute,
+		JWTRefreshExpiry: 7 * 24 * time.Hour,
+	}
+	jwtService := service.NewJWTService(cfg)
+	limiter := newMemoryLimiter()
+
+	router := gin.New()
+	api := router.Group("/api")
+	api.Use(LimitWrites(limiter, ratelimit.Rule{Limit: 1, Period: time.Minute}))
+	api.Use(Auth(jwtService))
+	api.GET("/issues", func(c *gin.Context) { c.Status(http.StatusOK) })
+	api.POST("/issues", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	newToken := func() string {
+		token, _ := jwtService.GenerateAccessToken(uuid.New(), "test@example.com", model.RoleMember)
+		return token
+	}
+	request := func(method, token string) int {
+		req := httptest.NewRequest(method, "/api/issues", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	token := newToken()
+	if code := request(http.MethodPost, token); code != http.StatusCreated {
+		t.Fatalf("首次写请求状态码 = %d", code)
+	}
+	if code := request(http.MethodPost, token); code != http.StatusTooManyRequests {
+		t.Errorf("超出限制后写请求状态码 = %d, want 429", code)
+	}
+
+	// 只读请求不计数
+	if code := request(http.MethodGet, token); code != http.StatusOK {
+		t.Errorf("只读请求状态码 = %d, want 200", code)
+	}
+
+	// 按用户分别计数
+	if code := request(http.MethodPost, newToken()); code != http.StatusCreated {
+		t.Errorf("其他用户写请求状态码 = %d", code)
+	}
+
+	// 未认证的请求在认证阶段被拒绝，不消耗额度
+	if code := request(http.MethodPost, "invalid"); code != http.StatusUnauthorized {
+		t.Errorf("无效令牌状态码 = %d, want 401", code)
+	}
+	if len(limiter.counts) != 2 {
+		t.Errorf("计数对象 = %v, want 2 个用户", limiter.counts)
+	}
+}
@@ -0,0 +1,86 @@
+// Package middleware 提供 HTTP 中间件
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/logging"
+)
+
+const (
+	// HeaderRequestID 请求 ID 的请求头和响应头
+	HeaderRequestID = "X-Request-ID"
+	// ContextKeyRequestID gin.Context 中保存请求 ID 的键
+	ContextKeyRequestID = "request_id"
+
+	// maxRequestIDLength 沿用上游请求 ID 的最大长度
+	maxRequestIDLength = 128
+)
+
+// RequestID 为每个请求分配请求 ID：沿用上游网关传入的 X-Request-ID，否则生成 UUID
+// 请求 ID 写入响应头，并放入 c.Request 的上下文，服务层用 slog.*Context 输出的日志会自动带上
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(HeaderRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set(ContextKeyRequestID, requestID)
+		c.Header(HeaderRequestID, requestID)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
+
+// validRequestID 上游请求 ID 只接受长度有限的可见 ASCII 字符，避免日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// AccessLog 每个请求结束后输出一条访问日志，5xx 记为 error、4xx 记为 warn
+// 需要注册在 RequestID 之后，才能带上请求 ID
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Int("size", c.Writer.Size()),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if user := GetCurrentUser(c); user != nil {
+			attrs = append(attrs, slog.String("user_id", user.UserID))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+		slog.LogAttrs(c.Request.Context(), level, "HTTP 请求", attrs...)
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/liwei0526vip/mylinear/internal/logging"
+)
+
+func TestRequestID(t *testing.T) {
+	var fromContext string
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/request-id-test", func(c *gin.Context) {
+		fromContext = logging.RequestID(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name     string
+		upstream string
+		keep     bool
+	}{
+		{"沿用上游请求 ID", "gateway-abc-123", true},
+		{"未传入时生成", "", false},
+		{"包含控制字符时重新生成", "bad\nid", false},
+		{"超长时重新生成", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/request-id-test", nil)
+			if tt.upstream != "" {
+				req.Header.Set(HeaderRequestID, tt.upstream)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			got := w.Header().Get(HeaderRequestID)
+			if got == "" || got != fromContext {
+				t.Fatalf("响应头 = %q, 上下文 = %q", got, fromContext)
+			}
+			if (got == tt.upstream) != tt.keep {
+				t.Errorf("请求 ID = %q, 上游 = %q", got, tt.upstream)
+			}
+		})
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	prev := slog.Default()
+	defer slog.SetDefault(prev)
+	var buf bytes.Buffer
+	if err := logging.Setup(logging.Config{Level: "info", Format: logging.FormatText}, &buf); err != nil {
+		t.Fatalf("logging.Setup() error = %v", err)
+	}
+
+	router := gin.New()
+	router.Use(RequestID(), AccessLog())
+	router.GET("/access-log-test/:id", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/access-log-test/1", nil)
+	req.Header.Set(HeaderRequestID, "req-789")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	out := buf.String()
+	for _, want := range []string{"level=ERROR", "status=500", "route=/access-log-test/:id", "request_id=req-789"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("访问日志缺少 %s: %s", want, out)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Package middleware 提供 HTTP 中间件
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/gorm"
+)
+
+// WorkspaceScope 工作区隔离中间件
+// 路径中的 :workspaceId 必须是当前工作区；通过 :teamId 访问的团队，以及通过 /issues/:id、
+// /projects/:id、/workflow-states/:id、:commentId 访问的 Issue、项目、工作流状态和评论
+// 必须属于当前工作区，否则返回 404，不暴露其他工作区的数据是否存在。
+// 令牌未指定工作区（旧令牌、OAuth 令牌）时按用户的主工作区检查，并写回当前用户信息供后续使用。
+// 必须在 Auth 和 ResolveIssueIdentifier 之后使用
+func WorkspaceScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if IsGlobalAdmin(c) {
+			c.Next()
+			return
+		}
+		workspaceID, ok := scopeWorkspaceID(c)
+		if !ok {
+			return
+		}
+
+		if id, err := uuid.Parse(c.Param("workspaceId")); err == nil && id != workspaceID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "forbidden",
+				"message": "无权限访问此工作区，请先切换工作区",
+			})
+			c.Abort()
+			return
+		}
+
+		if teamID, err := uuid.Parse(c.Param("teamId")); err == nil {
+			if !checkWorkspace(c, workspaceID, "团队不存在", func(db *gorm.DB) (uuid.UUID, error) {
+				return store.TeamWorkspaceID(c.Request.Context(), db, teamID)
+			}) {
+				return
+			}
+		}
+
+		if notFound, lookup := routeWorkspaceLookup(c); lookup != nil {
+			if !checkWorkspace(c, workspaceID, notFound, lookup) {
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// routeWorkspaceLookup 根据路由参数返回查找资源所属工作区的方法，与 routeResource 识别相同的资源
+// 没有需要检查的资源时返回 nil
+func routeWorkspaceLookup(c *gin.Context) (string, func(db *gorm.DB) (uuid.UUID, error)) {
+	ctx := c.Request.Context()
+	fullPath := c.FullPath()
+
+	if commentID, err := uuid.Parse(c.Param("commentId")); err == nil {
+		return "评论不存在", func(db *gorm.DB) (uuid.UUID, error) {
+			teamID, err := store.CommentTeamID(ctx, db, commentID)
+			if err != nil {
+				return uuid.Nil, err
+			}
+			return store.TeamWorkspaceID(ctx, db, teamID)
+		}
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return "", nil
+	}
+	switch {
+	case strings.Contains(fullPath, "/issues/:id"):
+		return "Issue 不存在", func(db *gorm.DB) (uuid.UUID, error) {
+			return store.IssueWorkspaceID(ctx, db, id)
+		}
+	case strings.Contains(fullPath, "/projects/:id"):
+		return "项目不存在", func(db *gorm.DB) (uuid.UUID, error) {
+			return store.ProjectWorkspaceID(ctx, db, id)
+		}
+	case strings.Contains(fullPath, "/workflow-states/:id"):
+		return "工作流状态不存在", func(db *gorm.DB) (uuid.UUID, error) {
+			teamID, err := store.WorkflowStateTeamID(ctx, db, id)
+			if err != nil {
+				return uuid.Nil, err
+			}
+			return store.TeamWorkspaceID(ctx, db, teamID)
+		}
+	}
+	return "", nil
+}
+
+// scopeWorkspaceID 获取请求所在的工作区
+// 令牌未指定工作区时使用用户的主工作区，并写回当前用户信息，使处理器和访问控制使用同一个工作区
+func scopeWorkspaceID(c *gin.Context) (uuid.UUID, bool) {
+	if workspaceID := GetCurrentWorkspaceID(c); workspaceID != uuid.Nil {
+		return workspaceID, true
+	}
+
+	user := GetCurrentUser(c)
+	db := GetDB(c)
+	if user == nil || db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "internal_error",
+			"message": "数据库连接不可用",
+		})
+		c.Abort()
+		return uuid.Nil, false
+	}
+	workspaceID, err := store.UserWorkspaceID(c.Request.Context(), db, GetCurrentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "internal_error",
+			"message": "检查工作区失败",
+		})
+		c.Abort()
+		return uuid.Nil, false
+	}
+	user.WorkspaceID = workspaceID.String()
+	return workspaceID, true
+}
+
+// checkWorkspace 检查资源所属的工作区，不属于当前工作区时中止请求
+// 资源不存在时放行，交给处理器返回原有的错误
+func checkWorkspace(c *gin.Context, workspaceID uuid.UUID, notFound string, lookup func(db *gorm.DB) (uuid.UUID, error)) bool {
+	db := GetDB(c)
+	if db == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "internal_error",
+			"message": "数据库连接不可用",
+		})
+		c.Abort()
+		return false
+	}
+
+	resourceWorkspaceID, err := lookup(db)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return true
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "internal_error",
+			"message": "检查工作区失败",
+		})
+		c.Abort()
+		return false
+	}
+	if resourceWorkspaceID != workspaceID {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": notFound,
+		})
+		c.Abort()
+		return false
+	}
+	return true
+}
@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+)
+
+func TestWorkspaceScope_WithoutDB(t *testing.T) {
+	workspaceID := uuid.New()
+	teamID := uuid.New().String()
+
+	tests := []struct {
+		name       string
+		user       *UserContext
+		path       string
+		wantStatus int
+	}{
+		{
+			name:       "令牌未指定工作区时需要查询主工作区",
+			user:       &UserContext{UserID: uuid.New().String(), Role: "member"},
+			path:       "/teams/" + teamID,
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "全局管理员不检查",
+			user:       &UserContext{UserID: uuid.New().String(), Role: "global_admin", WorkspaceID: workspaceID.String()},
+			path:       "/teams/" + teamID,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "路径中的工作区与当前工作区一致",
+			user:       &UserContext{UserID: uuid.New().String(), Role: "member", WorkspaceID: workspaceID.String()},
+			path:       "/workspaces/" + workspaceID.String() + "/projects",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "路径中的工作区不是当前工作区",
+			user:       &UserContext{UserID: uuid.New().String(), Role: "admin", WorkspaceID: workspaceID.String()},
+			path:       "/workspaces/" + uuid.New().String() + "/projects",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "非 UUID 的团队 ID 交给处理器",
+			user:       &UserContext{UserID: uuid.New().String(), Role: "member", WorkspaceID: workspaceID.String()},
+			path:       "/teams/not-a-uuid",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "需要检查项目但数据库不可用",
+			user:       &UserContext{UserID: uuid.New().String(), Role: "admin", WorkspaceID: workspaceID.String()},
+			path:       "/projects/" + uuid.New().String(),
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "需要检查工作流状态但数据库不可用",
+			user:       &UserContext{UserID: uuid.New().String(), Role: "admin", WorkspaceID: workspaceID.String()},
+			path:       "/workflow-states/" + uuid.New().String(),
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "需要检查评论但数据库不可用",
+			user:       &UserContext{UserID: uuid.New().String(), Role: "member", WorkspaceID: workspaceID.String()},
+			path:       "/comments/" + uuid.New().String(),
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "需要检查团队但数据库不可用",
+			user:       &UserContext{UserID: uuid.New().String(), Role: "member", WorkspaceID: workspaceID.String()},
+			path:       "/teams/" + teamID,
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) { c.Set(ContextKeyUser, tt.user) })
+			router.Use(WorkspaceScope())
+			handler := func(c *gin.Context) { c.Status(http.StatusOK) }
+			router.GET("/teams/:teamId", handler)
+			router.GET("/workspaces/:workspaceId/projects", handler)
+			router.GET("/projects/:id", handler)
+			router.GET("/workflow-states/:id", handler)
+			router.GET("/comments/:commentId", handler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v, body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestWorkspaceScope(t *testing.T) {
+	if testPermissionDB == nil {
+		t.Skip("数据库连接不可用，跳过集成测试")
+	}
+
+	tx := testPermissionDB.Begin()
+	defer tx.Rollback()
+
+	prefix := uuid.New().String()[:8]
+	newWorkspace := func(name, key string) (*model.Workspace, *model.Team) {
+		workspace := &model.Workspace{Name: name + " " + prefix, Slug: name + "-" + prefix}
+		if err := tx.Create(workspace).Error; err != nil {
+			t.Fatalf("创建测试工作区失败: %v", err)
+		}
+		team := &model.Team{WorkspaceID: workspace.ID, Name: name + " Team " + prefix, Key: key}
+		if err := tx.Create(team).Error; err != nil {
+			t.Fatalf("创建测试团队失败: %v", err)
+		}
+		return workspace, team
+	}
+	home, _ := newWorkspace("scope-home", "SH")
+	other, otherTeam := newWorkspace("scope-other", "SO")
+
+	user := &model.User{
+		WorkspaceID:  home.ID,
+		Email:        prefix + "_scope@example.com",
+		Username:     prefix + "_scope",
+		Name:         "Scope",
+		PasswordHash: "hash",
+		Role:         model.RoleAdmin,
+	}
+	if err := tx.Create(user).Error; err != nil {
+		t.Fatalf("创建测试用户失败: %v", err)
+	}
+
+	state := &model.WorkflowState{TeamID: otherTeam.ID, Name: "Todo", Type: model.StateTypeUnstarted}
+	if err := tx.Create(state).Error; err != nil {
+		t.Fatalf("创建工作流状态失败: %v", err)
+	}
+	issue := &model.Issue{TeamID: otherTeam.ID, Number: 1, Title: "Issue", StatusID: state.ID, CreatedByID: user.ID}
+	if err := tx.Create(issue).Error; err != nil {
+		t.Fatalf("创建测试 Issue 失败: %v", err)
+	}
+	comment := &model.Comment{IssueID: issue.ID, UserID: user.ID, Body: "comment"}
+	if err := tx.Create(comment).Error; err != nil {
+		t.Fatalf("创建测试评论失败: %v", err)
+	}
+	project := &model.Project{WorkspaceID: other.ID, Name: "Project"}
+	if err := tx.Create(project).Error; err != nil {
+		t.Fatalf("创建测试项目失败: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		workspaceID string
+		path        string
+		wantStatus  int
+	}{
+		{"其他工作区的项目", home.ID.String(), "/projects/" + project.ID.String(), http.StatusNotFound},
+		{"其他工作区的工作流状态", home.ID.String(), "/workflow-states/" + state.ID.String(), http.StatusNotFound},
+		{"其他工作区的评论", home.ID.String(), "/comments/" + comment.ID.String(), http.StatusNotFound},
+		{"令牌未指定工作区时按主工作区检查", "", "/projects/" + project.ID.String(), http.StatusNotFound},
+		{"当前工作区的项目", other.ID.String(), "/projects/" + project.ID.String(), http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("db", tx)
+				c.Set(ContextKeyUser, &UserContext{UserID: user.ID.String(), Role: string(user.Role), WorkspaceID: tt.workspaceID})
+			})
+			router.Use(WorkspaceScope())
+			handler := func(c *gin.Context) { c.Status(http.StatusOK) }
+			router.GET("/projects/:id", handler)
+			router.GET("/workflow-states/:id", handler)
+			router.GET("/comments/:commentId", handler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v, body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
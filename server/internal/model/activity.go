@@ -115,3 +115,33 @@ type ActivityPayloadComment struct {
 	CommentID      uuid.UUID `json:"comment_id"`
 	CommentPreview string    `json:"comment_preview"`
 }
+
+// ActivityPayloadLink PR / 提交关联 Payload
+type ActivityPayloadLink struct {
+	LinkID   uuid.UUID `json:"link_id"`
+	Provider string    `json:"provider"`
+	Type     string    `json:"type"`
+	URL      string    `json:"url"`
+	Title    string    `json:"title"`
+	State    string    `json:"state,omitempty"`
+	Author   string    `json:"author,omitempty"`
+}
+
+// ActivityPayloadTeam 移动团队 Payload
+type ActivityPayloadTeam struct {
+	OldTeam       *ActivityTeamRef   `json:"old_team"`
+	NewTeam       *ActivityTeamRef   `json:"new_team"`
+	OldIdentifier string             `json:"old_identifier"`
+	NewIdentifier string             `json:"new_identifier"`
+	OldStatus     *ActivityStatusRef `json:"old_status,omitempty"`
+	NewStatus     *ActivityStatusRef `json:"new_status,omitempty"`
+	// DroppedLabels 目标团队中没有对应标签而被移除的标签
+	DroppedLabels []ActivityLabelRef `json:"dropped_labels,omitempty"`
+}
+
+// ActivityTeamRef 团队引用（用于 Payload）
+type ActivityTeamRef struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Key  string    `json:"key"`
+}
@@ -0,0 +1,106 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// APIKeyPrefix API Key 明文前缀，用于区分 API Key 与 JWT
+const APIKeyPrefix = "mlk_"
+
+// APIKeyDisplayLength 列表中展示的明文前缀长度（含 mlk_）
+const APIKeyDisplayLength = 12
+
+// API Key 权限范围，admin 包含 write，write 包含 read
+const (
+	APIKeyScopeRead  = "read"
+	APIKeyScopeWrite = "write"
+	APIKeyScopeAdmin = "admin"
+)
+
+// apiKeyScopeLevels 权限范围等级
+var apiKeyScopeLevels = map[string]int{
+	APIKeyScopeRead:  1,
+	APIKeyScopeWrite: 2,
+	APIKeyScopeAdmin: 3,
+}
+
+// IsValidAPIKeyScope 检查权限范围是否有效
+func IsValidAPIKeyScope(scope string) bool {
+	_, ok := apiKeyScopeLevels[scope]
+	return ok
+}
+
+// ScopesAllow 检查权限范围列表是否满足要求的权限
+func ScopesAllow(scopes []string, required string) bool {
+	need, ok := apiKeyScopeLevels[required]
+	if !ok {
+		return false
+	}
+	for _, scope := range scopes {
+		if apiKeyScopeLevels[scope] >= need {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopedRole 按权限范围计算令牌的有效角色
+// 未授予 admin 范围时，管理员只拥有普通成员权限
+func ScopedRole(role Role, scopes []string) Role {
+	if (role == RoleAdmin || role == RoleGlobalAdmin) && !ScopesAllow(scopes, APIKeyScopeAdmin) {
+		return RoleMember
+	}
+	return role
+}
+
+// HashAPIKey 计算 API Key 的 SHA-256 摘要（十六进制），数据库只保存摘要
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey 检查令牌是否为 API Key 格式
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
+
+// APIKey 个人 API Key
+// 明文只在创建时返回一次，数据库保存 SHA-256 摘要
+type APIKey struct {
+	Model
+	UserID     uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name       string         `gorm:"type:varchar(100);not null" json:"name"`
+	Prefix     string         `gorm:"type:varchar(20);not null" json:"prefix"`
+	KeyHash    string         `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	Scopes     pq.StringArray `gorm:"type:text[];not null" json:"scopes"`
+	ExpiresAt  *time.Time     `gorm:"type:timestamptz" json:"expires_at,omitempty"`
+	LastUsedAt *time.Time     `gorm:"type:timestamptz" json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time     `gorm:"type:timestamptz" json:"revoked_at,omitempty"`
+
+	// 关联关系
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName 指定表名
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// IsActive 检查 API Key 在指定时间是否可用（未吊销且未过期）
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// HasScope 检查 API Key 是否拥有指定权限
+func (k *APIKey) HasScope(scope string) bool {
+	return ScopesAllow(k.Scopes, scope)
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScopesAllow(t *testing.T) {
+	tests := []struct {
+		name     string
+		scopes   []string
+		required string
+		want     bool
+	}{
+		{"read 允许读", []string{APIKeyScopeRead}, APIKeyScopeRead, true},
+		{"read 不允许写", []string{APIKeyScopeRead}, APIKeyScopeWrite, false},
+		{"write 包含 read", []string{APIKeyScopeWrite}, APIKeyScopeRead, true},
+		{"write 不包含 admin", []string{APIKeyScopeWrite}, APIKeyScopeAdmin, false},
+		{"admin 包含全部", []string{APIKeyScopeAdmin}, APIKeyScopeWrite, true},
+		{"多个范围取最高", []string{APIKeyScopeRead, APIKeyScopeAdmin}, APIKeyScopeAdmin, true},
+		{"空范围", nil, APIKeyScopeRead, false},
+		{"未知范围", []string{"superuser"}, APIKeyScopeRead, false},
+		{"未知的要求", []string{APIKeyScopeAdmin}, "superuser", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScopesAllow(tt.scopes, tt.required); got != tt.want {
+				t.Errorf("ScopesAllow(%v, %q) = %v, 期望 %v", tt.scopes, tt.required, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIKey_IsActive(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name string
+		key  APIKey
+		want bool
+	}{
+		{"永不过期", APIKey{}, true},
+		{"未到期", APIKey{ExpiresAt: &future}, true},
+		{"已过期", APIKey{ExpiresAt: &past}, false},
+		{"已吊销", APIKey{RevokedAt: &past}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.key.IsActive(now); got != tt.want {
+				t.Errorf("IsActive() = %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashAPIKey(t *testing.T) {
+	hash := HashAPIKey("mlk_test")
+	if len(hash) != 64 {
+		t.Errorf("摘要长度 = %d, 期望 64", len(hash))
+	}
+	if hash != HashAPIKey("mlk_test") || hash == HashAPIKey("mlk_other") {
+		t.Error("相同输入应得到相同摘要，不同输入应得到不同摘要")
+	}
+	if !IsAPIKey("mlk_abc") || IsAPIKey("eyJhbGciOi") {
+		t.Error("IsAPIKey 判断错误")
+	}
+}
+
+func TestScopedRole(t *testing.T) {
+	tests := []struct {
+		role   Role
+		scopes []string
+		want   Role
+	}{
+		{RoleAdmin, []string{APIKeyScopeWrite}, RoleMember},
+		{RoleGlobalAdmin, []string{APIKeyScopeRead}, RoleMember},
+		{RoleAdmin, []string{APIKeyScopeAdmin}, RoleAdmin},
+		{RoleMember, []string{APIKeyScopeAdmin}, RoleMember},
+		{RoleGuest, []string{APIKeyScopeWrite}, RoleGuest},
+	}
+	for _, tt := range tests {
+		if got := ScopedRole(tt.role, tt.scopes); got != tt.want {
+			t.Errorf("ScopedRole(%s, %v) = %s, 期望 %s", tt.role, tt.scopes, got, tt.want)
+		}
+	}
+}
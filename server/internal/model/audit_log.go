@@ -0,0 +1,77 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// AuditAction 审计事件类型
+type AuditAction string
+
+// 审计事件类型
+const (
+	AuditLoginSucceeded        AuditAction = "auth.login_succeeded"
+	AuditLoginFailed           AuditAction = "auth.login_failed"
+	AuditTokenRefreshed        AuditAction = "auth.token_refreshed"
+	AuditTeamCreated           AuditAction = "team.created"
+	AuditTeamDeleted           AuditAction = "team.deleted"
+	AuditTeamMemberAdded       AuditAction = "team_member.added"
+	AuditTeamMemberRemoved     AuditAction = "team_member.removed"
+	AuditTeamMemberRoleChanged AuditAction = "team_member.role_changed"
+	AuditWorkflowStateCreated  AuditAction = "workflow_state.created"
+	AuditWorkflowStateUpdated  AuditAction = "workflow_state.updated"
+	AuditWorkflowStateDeleted  AuditAction = "workflow_state.deleted"
+	AuditWorkspaceUpdated      AuditAction = "workspace.updated"
+	AuditWorkspaceAuthSettings AuditAction = "workspace.auth_settings_updated"
+	AuditDataExported          AuditAction = "data.exported"
+	AuditDataRestored          AuditAction = "data.restored"
+)
+
+// 审计对象类型
+const (
+	AuditTargetUser          = "user"
+	AuditTargetSession       = "session"
+	AuditTargetTeam          = "team"
+	AuditTargetTeamMember    = "team_member"
+	AuditTargetWorkflowState = "workflow_state"
+	AuditTargetWorkspace     = "workspace"
+	AuditTargetAuditLog      = "audit_log"
+)
+
+// AuditLog 审计日志模型
+// 只允许追加，记录写入后不再修改；WorkspaceID 和 ActorID 不使用外键，删除用户或工作区后仍保留记录
+type AuditLog struct {
+	ID          uuid.UUID   `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WorkspaceID *uuid.UUID  `gorm:"type:uuid;index" json:"workspace_id,omitempty"`
+	ActorID     *uuid.UUID  `gorm:"type:uuid;index" json:"actor_id,omitempty"`
+	Action      AuditAction `gorm:"type:varchar(64);not null;index" json:"action"`
+	TargetType  string      `gorm:"type:varchar(32);not null;default:''" json:"target_type,omitempty"`
+	TargetID    string      `gorm:"type:varchar(64);not null;default:''" json:"target_id,omitempty"`
+	IPAddress   string      `gorm:"type:varchar(64);not null;default:''" json:"ip_address"`
+	UserAgent   string      `gorm:"type:varchar(512);not null;default:''" json:"user_agent"`
+	// Changes 变更前后的值，格式为 {"before": {...}, "after": {...}}
+	Changes   datatypes.JSON `gorm:"type:jsonb" json:"changes,omitempty"`
+	CreatedAt time.Time      `gorm:"not null;default:now();index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// BeforeCreate GORM 钩子，在创建记录前自动生成 UUID
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// AuditChanges 审计日志中变更前后的值，新建时 Before 为空，删除时 After 为空
+type AuditChanges struct {
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
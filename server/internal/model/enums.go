@@ -220,14 +220,14 @@ func (c CycleStatus) Value() (driver.Value, error) {
 type NotificationType string
 
 const (
-	NotificationTypeIssueAssigned       NotificationType = "issue_assigned"        // Issue 分配
-	NotificationTypeIssueMentioned      NotificationType = "issue_mentioned"       // Issue 提及
-	NotificationTypeIssueCommented      NotificationType = "issue_commented"       // Issue 评论
-	NotificationTypeIssueStatusChanged  NotificationType = "issue_status_changed"  // Issue 状态变更
+	NotificationTypeIssueAssigned        NotificationType = "issue_assigned"         // Issue 分配
+	NotificationTypeIssueMentioned       NotificationType = "issue_mentioned"        // Issue 提及
+	NotificationTypeIssueCommented       NotificationType = "issue_commented"        // Issue 评论
+	NotificationTypeIssueStatusChanged   NotificationType = "issue_status_changed"   // Issue 状态变更
 	NotificationTypeIssuePriorityChanged NotificationType = "issue_priority_changed" // Issue 优先级变更
-	NotificationTypeProjectUpdated      NotificationType = "project_updated"       // 项目更新
-	NotificationTypeCycleStarted        NotificationType = "cycle_started"         // 迭代开始
-	NotificationTypeCycleEnded          NotificationType = "cycle_ended"           // 迭代结束
+	NotificationTypeProjectUpdated       NotificationType = "project_updated"        // 项目更新
+	NotificationTypeCycleStarted         NotificationType = "cycle_started"          // 迭代开始
+	NotificationTypeCycleEnded           NotificationType = "cycle_ended"            // 迭代结束
 )
 
 // Valid 验证通知类型是否有效
@@ -269,16 +269,18 @@ func (n NotificationType) Value() (driver.Value, error) {
 type ActivityType string
 
 const (
-	ActivityIssueCreated       ActivityType = "issue_created"        // Issue 创建
-	ActivityTitleChanged       ActivityType = "title_changed"        // 标题变更
-	ActivityDescriptionChanged ActivityType = "description_changed"  // 描述变更
-	ActivityStatusChanged      ActivityType = "status_changed"       // 状态变更
-	ActivityPriorityChanged    ActivityType = "priority_changed"     // 优先级变更
-	ActivityAssigneeChanged    ActivityType = "assignee_changed"     // 负责人变更
-	ActivityDueDateChanged     ActivityType = "due_date_changed"     // 截止日期变更
-	ActivityProjectChanged     ActivityType = "project_changed"      // 项目变更
-	ActivityLabelsChanged      ActivityType = "labels_changed"       // 标签变更
-	ActivityCommentAdded       ActivityType = "comment_added"        // 评论添加
+	ActivityIssueCreated       ActivityType = "issue_created"       // Issue 创建
+	ActivityTitleChanged       ActivityType = "title_changed"       // 标题变更
+	ActivityDescriptionChanged ActivityType = "description_changed" // 描述变更
+	ActivityStatusChanged      ActivityType = "status_changed"      // 状态变更
+	ActivityPriorityChanged    ActivityType = "priority_changed"    // 优先级变更
+	ActivityAssigneeChanged    ActivityType = "assignee_changed"    // 负责人变更
+	ActivityDueDateChanged     ActivityType = "due_date_changed"    // 截止日期变更
+	ActivityProjectChanged     ActivityType = "project_changed"     // 项目变更
+	ActivityLabelsChanged      ActivityType = "labels_changed"      // 标签变更
+	ActivityCommentAdded       ActivityType = "comment_added"       // 评论添加
+	ActivityLinkAdded          ActivityType = "link_added"          // PR / 提交关联
+	ActivityTeamChanged        ActivityType = "team_changed"        // 移动到其他团队
 )
 
 // Valid 验证活动类型是否有效
@@ -287,7 +289,7 @@ func (a ActivityType) Valid() bool {
 	case ActivityIssueCreated, ActivityTitleChanged, ActivityDescriptionChanged,
 		ActivityStatusChanged, ActivityPriorityChanged, ActivityAssigneeChanged,
 		ActivityDueDateChanged, ActivityProjectChanged, ActivityLabelsChanged,
-		ActivityCommentAdded:
+		ActivityCommentAdded, ActivityLinkAdded, ActivityTeamChanged:
 		return true
 	default:
 		return false
@@ -316,6 +318,53 @@ func (a ActivityType) Value() (driver.Value, error) {
 	return string(a), nil
 }
 
+// JobStatus 后台任务状态
+type JobStatus string
+
+const (
+	JobStatusPending   JobStatus = "pending"   // 等待执行
+	JobStatusRunning   JobStatus = "running"   // 执行中
+	JobStatusSucceeded JobStatus = "succeeded" // 执行成功
+	JobStatusFailed    JobStatus = "failed"    // 执行失败
+)
+
+// Valid 验证任务状态是否有效
+func (j JobStatus) Valid() bool {
+	switch j {
+	case JobStatusPending, JobStatusRunning, JobStatusSucceeded, JobStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsFinished 检查任务是否已结束（成功或失败）
+func (j JobStatus) IsFinished() bool {
+	return j == JobStatusSucceeded || j == JobStatusFailed
+}
+
+// Scan 实现 sql.Scanner 接口
+func (j *JobStatus) Scan(value interface{}) error {
+	if value == nil {
+		*j = ""
+		return nil
+	}
+	switch v := value.(type) {
+	case string:
+		*j = JobStatus(v)
+	case []byte:
+		*j = JobStatus(v)
+	default:
+		return fmt.Errorf("无法扫描 JobStatus 类型: %T", value)
+	}
+	return nil
+}
+
+// Value 实现 driver.Valuer 接口
+func (j JobStatus) Value() (driver.Value, error) {
+	return string(j), nil
+}
+
 // Issue 优先级常量
 const (
 	PriorityNone   = 0 // 无优先级
@@ -118,6 +118,34 @@ func TestStateType(t *testing.T) {
 	}
 }
 
+// TestJobStatus 测试 JobStatus 枚举类型
+func TestJobStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       JobStatus
+		wantValid    bool
+		wantFinished bool
+	}{
+		{"等待中", JobStatusPending, true, false},
+		{"执行中", JobStatusRunning, true, false},
+		{"已成功", JobStatusSucceeded, true, true},
+		{"已失败", JobStatusFailed, true, true},
+		{"无效状态", JobStatus("invalid"), false, false},
+		{"空状态", JobStatus(""), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.status.Valid(); got != tt.wantValid {
+				t.Errorf("JobStatus.Valid() = %v, want %v", got, tt.wantValid)
+			}
+			if got := tt.status.IsFinished(); got != tt.wantFinished {
+				t.Errorf("JobStatus.IsFinished() = %v, want %v", got, tt.wantFinished)
+			}
+		})
+	}
+}
+
 // TestIssueRelationType 测试 IssueRelationType 枚举类型
 func TestIssueRelationType(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 外部引用对应的实体类型
+const (
+	ExternalEntityIssue   = "issue"
+	ExternalEntityComment = "comment"
+)
+
+// ExternalReference 外部系统 ID 与本地实体的映射
+// 从 Jira / GitHub / Linear 导入时记录，用于重复导入时跳过已导入的数据
+type ExternalReference struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TeamID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_external_ref_unique" json:"team_id"`
+	Source     string    `gorm:"type:varchar(30);not null;uniqueIndex:idx_external_ref_unique" json:"source"`
+	EntityType string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_external_ref_unique" json:"entity_type"`
+	ExternalID string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_external_ref_unique" json:"external_id"`
+	EntityID   uuid.UUID `gorm:"type:uuid;not null;index" json:"entity_id"`
+	CreatedAt  time.Time `gorm:"not null;default:now()" json:"created_at"`
+
+	// 关联关系
+	Team *Team `gorm:"foreignKey:TeamID;constraint:OnDelete:CASCADE" json:"team,omitempty"`
+}
+
+// TableName 指定表名
+func (ExternalReference) TableName() string {
+	return "external_references"
+}
+
+// BeforeCreate GORM 钩子，在创建记录前自动生成 UUID
+func (r *ExternalReference) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Invitation 工作区邀请，明文令牌只出现在邀请链接中，数据库保存 SHA-256 摘要
+// 指定邮箱的邀请只能由该邮箱注册一次；未指定邮箱的邀请链接在过期或撤销前可被多人使用
+type Invitation struct {
+	Model
+	WorkspaceID uuid.UUID `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	// Email 受邀邮箱（已规范化），为空表示邀请链接
+	Email string `gorm:"type:varchar(255);not null;default:''" json:"email,omitempty"`
+	Role  Role   `gorm:"type:varchar(20);not null;default:'member'" json:"role"`
+	// TeamIDs 注册后自动加入的团队
+	TeamIDs     pq.StringArray `gorm:"type:uuid[];not null;default:'{}'" json:"team_ids"`
+	TokenHash   string         `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	InvitedByID *uuid.UUID     `gorm:"type:uuid" json:"invited_by_id,omitempty"`
+	ExpiresAt   time.Time      `gorm:"type:timestamptz;not null" json:"expires_at"`
+	// SentAt 最近一次发送邀请邮件的时间
+	SentAt     *time.Time `gorm:"type:timestamptz" json:"sent_at,omitempty"`
+	UseCount   int        `gorm:"not null;default:0" json:"use_count"`
+	AcceptedAt *time.Time `gorm:"type:timestamptz" json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `gorm:"type:timestamptz" json:"revoked_at,omitempty"`
+
+	// 关联关系
+	Workspace *Workspace `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName 指定表名
+func (Invitation) TableName() string {
+	return "workspace_invitations"
+}
+
+// IsLink 是否为不限定邮箱的邀请链接
+func (i *Invitation) IsLink() bool {
+	return i.Email == ""
+}
+
+// IsPending 检查邀请在指定时间是否仍可使用（未撤销、未过期，指定邮箱的邀请尚未被接受）
+func (i *Invitation) IsPending(now time.Time) bool {
+	if i.RevokedAt != nil || !now.Before(i.ExpiresAt) {
+		return false
+	}
+	return i.IsLink() || i.AcceptedAt == nil
+}
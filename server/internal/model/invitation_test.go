@@ -0,0 +1,31 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInvitation_IsPending(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name       string
+		invitation Invitation
+		want       bool
+	}{
+		{"邮箱邀请有效", Invitation{Email: "a@example.com", ExpiresAt: future}, true},
+		{"邮箱邀请已接受", Invitation{Email: "a@example.com", ExpiresAt: future, AcceptedAt: &past}, false},
+		{"邀请链接被使用后仍有效", Invitation{ExpiresAt: future, AcceptedAt: &past, UseCount: 3}, true},
+		{"已过期", Invitation{ExpiresAt: past}, false},
+		{"已撤销", Invitation{ExpiresAt: future, RevokedAt: &past}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.invitation.IsPending(now); got != tt.want {
+				t.Errorf("IsPending() = %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -175,3 +175,33 @@ func TestIssueIdentifier(t *testing.T) {
 		})
 	}
 }
+
+// TestParseIdentifier 测试 Issue 标识符解析
+func TestParseIdentifier(t *testing.T) {
+	tests := []struct {
+		identifier string
+		wantKey    string
+		wantNumber int
+		wantOK     bool
+	}{
+		{"ENG-123", "ENG", 123, true},
+		{"eng-42", "ENG", 42, true},
+		{" DEV2-7 ", "DEV2", 7, true},
+		{"E-1", "", 0, false},
+		{"ENG-0", "", 0, false},
+		{"ENG-", "", 0, false},
+		{"1ENG-1", "", 0, false},
+		{"ABCDEFGHIJK-1", "", 0, false},
+		{"550e8400-e29b-41d4-a716-446655440000", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.identifier, func(t *testing.T) {
+			key, number, ok := ParseIdentifier(tt.identifier)
+			if ok != tt.wantOK || key != tt.wantKey || number != tt.wantNumber {
+				t.Errorf("ParseIdentifier(%q) = (%q, %d, %v), 期望 (%q, %d, %v)",
+					tt.identifier, key, number, ok, tt.wantKey, tt.wantNumber, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// identifierPattern Issue 标识符格式：<团队 Key>-<编号>，如 ENG-123
+var identifierPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]{1,9})-([1-9][0-9]*)$`)
+
+// ParseIdentifier 解析 Issue 标识符（不区分大小写），返回大写的团队 Key 和编号
+func ParseIdentifier(identifier string) (teamKey string, number int, ok bool) {
+	m := identifierPattern.FindStringSubmatch(strings.TrimSpace(identifier))
+	if m == nil {
+		return "", 0, false
+	}
+	number, err := strconv.Atoi(m[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return strings.ToUpper(m[1]), number, true
+}
+
+// IssueIdentifierAlias Issue 的历史标识符（Issue 移动到其他团队后保留旧标识符）
+type IssueIdentifierAlias struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	IssueID     uuid.UUID `gorm:"type:uuid;not null;index" json:"issue_id"`
+	WorkspaceID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_issue_alias_identifier" json:"workspace_id"`
+	TeamKey     string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_issue_alias_identifier" json:"team_key"`
+	Number      int       `gorm:"not null;uniqueIndex:idx_issue_alias_identifier" json:"number"`
+	CreatedAt   time.Time `gorm:"not null;default:now()" json:"created_at"`
+
+	// 关联关系
+	Issue *Issue `gorm:"foreignKey:IssueID;constraint:OnDelete:CASCADE" json:"issue,omitempty"`
+}
+
+// TableName 指定表名
+func (IssueIdentifierAlias) TableName() string {
+	return "issue_identifier_aliases"
+}
+
+// BeforeCreate GORM 钩子，在创建记录前自动生成 UUID
+func (a *IssueIdentifierAlias) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// TeamKeyAlias 团队的历史 Key（修改团队 Key 后旧标识符仍可访问）
+type TeamKeyAlias struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	TeamID      uuid.UUID `gorm:"type:uuid;not null;index" json:"team_id"`
+	WorkspaceID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_team_key_aliases_key" json:"workspace_id"`
+	Key         string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_team_key_aliases_key" json:"key"`
+	CreatedAt   time.Time `gorm:"not null;default:now()" json:"created_at"`
+
+	// 关联关系
+	Team *Team `gorm:"foreignKey:TeamID;constraint:OnDelete:CASCADE" json:"team,omitempty"`
+}
+
+// TableName 指定表名
+func (TeamKeyAlias) TableName() string {
+	return "team_key_aliases"
+}
+
+// BeforeCreate GORM 钩子，在创建记录前自动生成 UUID
+func (a *TeamKeyAlias) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Git 平台
+const (
+	GitProviderGitHub = "github"
+	GitProviderGitLab = "gitlab"
+)
+
+// IssueLink 类型
+const (
+	IssueLinkPullRequest = "pull_request"
+	IssueLinkCommit      = "commit"
+)
+
+// PR / MR 状态
+const (
+	IssueLinkStateOpen   = "open"
+	IssueLinkStateMerged = "merged"
+	IssueLinkStateClosed = "closed"
+)
+
+// IssueLink 关联到 Issue 的 PR / 提交
+type IssueLink struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	IssueID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_issue_link_unique" json:"issue_id"`
+	Provider string    `gorm:"type:varchar(20);not null" json:"provider"`
+	Type     string    `gorm:"type:varchar(20);not null" json:"type"`
+	URL      string    `gorm:"type:text;not null;uniqueIndex:idx_issue_link_unique" json:"url"`
+	Title    string    `gorm:"type:varchar(500)" json:"title"`
+	// Ref PR 编号（如 #12）或提交 SHA
+	Ref    string `gorm:"type:varchar(100)" json:"ref"`
+	Branch string `gorm:"type:varchar(255)" json:"branch,omitempty"`
+	// State PR 状态，提交为空
+	State     string    `gorm:"type:varchar(20)" json:"state,omitempty"`
+	Author    string    `gorm:"type:varchar(255)" json:"author,omitempty"`
+	CreatedAt time.Time `gorm:"not null;default:now()" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now()" json:"updated_at"`
+
+	// 关联关系
+	Issue *Issue `gorm:"foreignKey:IssueID;constraint:OnDelete:CASCADE" json:"issue,omitempty"`
+}
+
+// TableName 指定表名
+func (IssueLink) TableName() string {
+	return "issue_links"
+}
+
+// BeforeCreate GORM 钩子，在创建记录前自动生成 UUID
+func (l *IssueLink) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Job 后台任务模型
+// 用于导入、同步等耗时操作，Payload 保存任务输入，Result 保存执行结果
+type Job struct {
+	Model
+	WorkspaceID *uuid.UUID     `gorm:"type:uuid;index" json:"workspace_id,omitempty"`
+	Type        string         `gorm:"type:varchar(50);not null;index" json:"type"`
+	Status      JobStatus      `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	Payload     datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"-"`
+	Progress    int            `gorm:"not null;default:0" json:"progress"`
+	Total       int            `gorm:"not null;default:0" json:"total"`
+	Result      datatypes.JSON `gorm:"type:jsonb" json:"result,omitempty"`
+	Error       *string        `gorm:"type:text" json:"error,omitempty"`
+	CreatedByID *uuid.UUID     `gorm:"type:uuid;index" json:"created_by_id,omitempty"`
+	StartedAt   *time.Time     `gorm:"type:timestamptz" json:"started_at,omitempty"`
+	FinishedAt  *time.Time     `gorm:"type:timestamptz" json:"finished_at,omitempty"`
+
+	// 关联关系
+	CreatedBy *User `gorm:"foreignKey:CreatedByID;constraint:OnDelete:SET NULL" json:"created_by,omitempty"`
+}
+
+// TableName 指定表名
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// IsFinished 检查任务是否已结束
+func (j *Job) IsFinished() bool {
+	return j.Status.IsFinished()
+}
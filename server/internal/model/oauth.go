@@ -0,0 +1,108 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// OAuthRefreshTokenPrefix OAuth 刷新令牌明文前缀
+const OAuthRefreshTokenPrefix = "mlr_"
+
+// PKCE 校验方式，只支持 S256
+const OAuthCodeChallengeS256 = "S256"
+
+// OAuthApplication 注册到工作区的 OAuth2 客户端
+// 机密客户端（Confidential）必须使用 client_secret 认证，公开客户端只依赖 PKCE
+type OAuthApplication struct {
+	Model
+	WorkspaceID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	Name             string         `gorm:"type:varchar(100);not null" json:"name"`
+	ClientID         string         `gorm:"type:varchar(64);not null;uniqueIndex" json:"client_id"`
+	ClientSecretHash string         `gorm:"type:varchar(64)" json:"-"`
+	RedirectURIs     pq.StringArray `gorm:"type:text[];not null" json:"redirect_uris"`
+	// Scopes 应用可以申请的权限范围（read, write, admin）
+	Scopes       pq.StringArray `gorm:"type:text[];not null" json:"scopes"`
+	Confidential bool           `gorm:"not null;default:true" json:"confidential"`
+	CreatedByID  *uuid.UUID     `gorm:"type:uuid" json:"created_by_id,omitempty"`
+
+	// 关联关系
+	Workspace *Workspace `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName 指定表名
+func (OAuthApplication) TableName() string {
+	return "oauth_applications"
+}
+
+// AllowsRedirectURI 检查回调地址是否已注册（精确匹配）
+func (a *OAuthApplication) AllowsRedirectURI(uri string) bool {
+	for _, registered := range a.RedirectURIs {
+		if registered == uri {
+			return true
+		}
+	}
+	return false
+}
+
+// OAuthAuthorizationCode 授权码，只保存摘要，单次使用
+type OAuthAuthorizationCode struct {
+	ID                  uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CodeHash            string         `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	ApplicationID       uuid.UUID      `gorm:"type:uuid;not null;index" json:"application_id"`
+	UserID              uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
+	RedirectURI         string         `gorm:"type:text;not null" json:"redirect_uri"`
+	Scopes              pq.StringArray `gorm:"type:text[];not null" json:"scopes"`
+	CodeChallenge       string         `gorm:"type:varchar(128);not null" json:"-"`
+	CodeChallengeMethod string         `gorm:"type:varchar(10);not null" json:"-"`
+	ExpiresAt           time.Time      `gorm:"type:timestamptz;not null" json:"expires_at"`
+	UsedAt              *time.Time     `gorm:"type:timestamptz" json:"used_at,omitempty"`
+	CreatedAt           time.Time      `gorm:"not null;default:now()" json:"created_at"`
+
+	// 关联关系
+	Application *OAuthApplication `gorm:"foreignKey:ApplicationID;constraint:OnDelete:CASCADE" json:"-"`
+	User        *User             `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName 指定表名
+func (OAuthAuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+// BeforeCreate GORM 钩子，在创建记录前自动生成 UUID
+func (c *OAuthAuthorizationCode) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// OAuthGrant 用户对应用的一次授权
+// 访问令牌为携带 grant_id 的 JWT，吊销授权后访问令牌和刷新令牌同时失效
+type OAuthGrant struct {
+	Model
+	ApplicationID       uuid.UUID      `gorm:"type:uuid;not null;index" json:"application_id"`
+	UserID              uuid.UUID      `gorm:"type:uuid;not null;index" json:"user_id"`
+	AuthorizationCodeID *uuid.UUID     `gorm:"type:uuid;index" json:"-"`
+	Scopes              pq.StringArray `gorm:"type:text[];not null" json:"scopes"`
+	RefreshTokenHash    string         `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	RefreshExpiresAt    time.Time      `gorm:"type:timestamptz;not null" json:"refresh_expires_at"`
+	LastUsedAt          *time.Time     `gorm:"type:timestamptz" json:"last_used_at,omitempty"`
+	RevokedAt           *time.Time     `gorm:"type:timestamptz" json:"revoked_at,omitempty"`
+
+	// 关联关系
+	Application *OAuthApplication `gorm:"foreignKey:ApplicationID;constraint:OnDelete:CASCADE" json:"application,omitempty"`
+	User        *User             `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName 指定表名
+func (OAuthGrant) TableName() string {
+	return "oauth_grants"
+}
+
+// IsActive 检查授权是否有效（未吊销）
+func (g *OAuthGrant) IsActive() bool {
+	return g.RevokedAt == nil
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPasswordResetToken_IsUsable(t *testing.T) {
+	now := time.Now()
+	usedAt := now.Add(-time.Minute)
+
+	tests := []struct {
+		name  string
+		token PasswordResetToken
+		want  bool
+	}{
+		{"有效", PasswordResetToken{ExpiresAt: now.Add(time.Hour)}, true},
+		{"已过期", PasswordResetToken{ExpiresAt: now.Add(-time.Hour)}, false},
+		{"已使用", PasswordResetToken{ExpiresAt: now.Add(time.Hour), UsedAt: &usedAt}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.token.IsUsable(now); got != tt.want {
+				t.Errorf("IsUsable() = %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package model
+
+import "testing"
+
+func TestHashRecoveryCode(t *testing.T) {
+	hash := HashRecoveryCode("abcde-12345")
+	if len(hash) != 64 {
+		t.Errorf("len(hash) = %d, want 64", len(hash))
+	}
+	// 忽略大小写、空格和连字符
+	for _, code := range []string{"ABCDE-12345", "abcde12345", " abcde 12345 "} {
+		if HashRecoveryCode(code) != hash {
+			t.Errorf("HashRecoveryCode(%q) 应与 abcde-12345 相同", code)
+		}
+	}
+	if HashRecoveryCode("abcde-12346") == hash {
+		t.Error("不同恢复码的摘要应不同")
+	}
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 会话吊销原因
+const (
+	SessionRevokeReasonLogout          = "logout"
+	SessionRevokeReasonRevoked         = "revoked"
+	SessionRevokeReasonPasswordChanged = "password_changed"
+	// SessionRevokeReasonTokenReuse 已轮换的刷新令牌被再次使用，可能已泄露
+	SessionRevokeReasonTokenReuse = "token_reuse"
+)
+
+// Session 登录会话，对应一个刷新令牌家族
+// 每次刷新都会轮换刷新令牌，只有最新签发的令牌（RefreshTokenJTI）有效
+type Session struct {
+	Model
+	UserID uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	// WorkspaceID 会话当前所在的工作区，为空时使用用户的主工作区
+	WorkspaceID     *uuid.UUID `gorm:"type:uuid" json:"workspace_id,omitempty"`
+	RefreshTokenJTI string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	UserAgent       string     `gorm:"type:varchar(512);not null;default:''" json:"user_agent"`
+	IPAddress       string     `gorm:"type:varchar(64);not null;default:''" json:"ip_address"`
+	LastUsedAt      time.Time  `gorm:"type:timestamptz;not null" json:"last_used_at"`
+	ExpiresAt       time.Time  `gorm:"type:timestamptz;not null" json:"expires_at"`
+	RevokedAt       *time.Time `gorm:"type:timestamptz" json:"revoked_at,omitempty"`
+	RevokeReason    string     `gorm:"type:varchar(32);not null;default:''" json:"revoke_reason,omitempty"`
+
+	// 关联关系
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName 指定表名
+func (Session) TableName() string {
+	return "user_sessions"
+}
+
+// IsActive 检查会话在指定时间是否可用（未吊销且未过期）
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSession_IsActive(t *testing.T) {
+	now := time.Now()
+	revokedAt := now.Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		session Session
+		want    bool
+	}{
+		{"有效", Session{ExpiresAt: now.Add(time.Hour)}, true},
+		{"已过期", Session{ExpiresAt: now.Add(-time.Hour)}, false},
+		{"已吊销", Session{ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.session.IsActive(now); got != tt.want {
+				t.Errorf("IsActive() = %v, 期望 %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ func TestTableNames(t *testing.T) {
 		expected string
 	}{
 		{"Workspace", Workspace{}, "workspaces"},
+		{"WorkspaceMember", WorkspaceMember{}, "workspace_members"},
 		{"Team", Team{}, "teams"},
 		{"TeamMember", TeamMember{}, "team_members"},
 		{"User", User{}, "users"},
@@ -29,6 +30,16 @@ func TestTableNames(t *testing.T) {
 		{"Attachment", Attachment{}, "attachments"},
 		{"Document", Document{}, "documents"},
 		{"Notification", Notification{}, "notifications"},
+		{"Job", Job{}, "jobs"},
+		{"ExternalReference", ExternalReference{}, "external_references"},
+		{"IssueLink", IssueLink{}, "issue_links"},
+		{"APIKey", APIKey{}, "api_keys"},
+		{"OAuthApplication", OAuthApplication{}, "oauth_applications"},
+		{"OAuthAuthorizationCode", OAuthAuthorizationCode{}, "oauth_authorization_codes"},
+		{"OAuthGrant", OAuthGrant{}, "oauth_grants"},
+		{"IssueIdentifierAlias", IssueIdentifierAlias{}, "issue_identifier_aliases"},
+		{"TeamKeyAlias", TeamKeyAlias{}, "team_key_aliases"},
+		{"AuditLog", AuditLog{}, "audit_logs"},
 	}
 
 	for _, tt := range tests {
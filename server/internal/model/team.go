@@ -7,13 +7,13 @@ import (
 	"gorm.io/datatypes"
 )
 
-// Team 团队模型
+// Team 团队模型，Key 在工作区内唯一
 type Team struct {
 	Model
-	WorkspaceID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"workspace_id"`
+	WorkspaceID      uuid.UUID      `gorm:"type:uuid;not null;index;uniqueIndex:idx_teams_workspace_key,priority:1" json:"workspace_id"`
 	ParentID         *uuid.UUID     `gorm:"type:uuid;index" json:"parent_id,omitempty"`
 	Name             string         `gorm:"type:varchar(255);not null" json:"name"`
-	Key              string         `gorm:"type:varchar(10);uniqueIndex;not null" json:"key"`
+	Key              string         `gorm:"type:varchar(10);uniqueIndex:idx_teams_workspace_key,priority:2;not null" json:"key"`
 	Description      string         `gorm:"type:text" json:"description"`
 	IconURL          *string        `gorm:"type:text" json:"icon_url,omitempty"`
 	Timezone         string         `gorm:"type:varchar(64);not null;default:'UTC'" json:"timezone"`
@@ -42,6 +42,8 @@ type TeamMember struct {
 	UserID   uuid.UUID `gorm:"type:uuid;primaryKey;not null" json:"user_id"`
 	Role     Role      `gorm:"type:varchar(20);not null;default:'member'" json:"role"`
 	JoinedAt time.Time `gorm:"not null;default:now()" json:"joined_at"`
+	// JoinedVia 加入团队的方式，管理员主动加入私有团队时记录为 admin_join
+	JoinedVia TeamJoinMethod `gorm:"type:varchar(20);not null;default:'added'" json:"joined_via"`
 
 	// 关联关系
 	Team *Team `gorm:"foreignKey:TeamID;constraint:OnDelete:CASCADE" json:"team,omitempty"`
@@ -52,3 +54,11 @@ type TeamMember struct {
 func (TeamMember) TableName() string {
 	return "team_members"
 }
+
+// TeamJoinMethod 加入团队的方式
+type TeamJoinMethod string
+
+const (
+	TeamJoinAdded     TeamJoinMethod = "added"      // 创建团队，或由团队 Owner、管理员添加
+	TeamJoinAdminJoin TeamJoinMethod = "admin_join" // 管理员主动加入私有团队
+)
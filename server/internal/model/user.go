@@ -1,6 +1,8 @@
 package model
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 )
@@ -8,6 +10,8 @@ import (
 // User 用户模型
 type User struct {
 	Model
+	// WorkspaceID 主工作区：注册时加入的工作区，登录后默认进入；Role 为用户在主工作区的角色
+	// 加入的其他工作区见 WorkspaceMember
 	WorkspaceID  uuid.UUID      `gorm:"type:uuid;not null;index" json:"workspace_id"`
 	Email        string         `gorm:"type:varchar(255);uniqueIndex;not null" json:"email"`
 	Name         string         `gorm:"type:varchar(255);not null" json:"name"`
@@ -16,6 +20,13 @@ type User struct {
 	PasswordHash string         `gorm:"type:varchar(255);not null" json:"-"`
 	Role         Role           `gorm:"type:varchar(20);not null;default:'member'" json:"role"`
 	Settings     datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"settings,omitempty"`
+	// DeactivatedAt 停用时间（如目录同步中已删除或停用的账号），停用后不能登录
+	DeactivatedAt *time.Time `gorm:"type:timestamptz" json:"deactivated_at,omitempty"`
+	// TOTPSecret 加密保存的 TOTP 密钥；已生成但尚未确认时 TOTPEnabledAt 为空
+	TOTPSecret    string     `gorm:"type:text" json:"-"`
+	TOTPEnabledAt *time.Time `gorm:"type:timestamptz" json:"totp_enabled_at,omitempty"`
+	// TOTPLastStep 最近一次使用的验证码时间步，同一验证码不能重复使用
+	TOTPLastStep int64 `gorm:"not null;default:0" json:"-"`
 
 	// 关联关系
 	Workspace    *Workspace   `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"workspace,omitempty"`
@@ -31,6 +42,11 @@ func (User) TableName() string {
 	return "users"
 }
 
+// TwoFactorEnabled 是否已启用两步验证
+func (u *User) TwoFactorEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
 // IsGlobalAdmin 检查是否为全局管理员
 func (u *User) IsGlobalAdmin() bool {
 	return u.Role == RoleGlobalAdmin
@@ -40,3 +56,8 @@ func (u *User) IsGlobalAdmin() bool {
 func (u *User) IsAdmin() bool {
 	return u.Role == RoleGlobalAdmin || u.Role == RoleAdmin
 }
+
+// IsActive 检查用户是否未被停用
+func (u *User) IsActive() bool {
+	return u.DeactivatedAt == nil
+}
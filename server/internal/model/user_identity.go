@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserIdentity 用户在外部身份提供方（OIDC）的身份
+// 以 (issuer, subject) 唯一标识，邮箱变更不影响关联
+type UserIdentity struct {
+	Model
+	UserID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	Issuer      string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_issuer_subject" json:"issuer"`
+	Subject     string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_issuer_subject" json:"subject"`
+	Email       string     `gorm:"type:varchar(255)" json:"email"`
+	LastLoginAt *time.Time `gorm:"type:timestamptz" json:"last_login_at,omitempty"`
+
+	// 关联关系
+	User *User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName 指定表名
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
@@ -1,6 +1,9 @@
 package model
 
 import (
+	"time"
+
+	"github.com/google/uuid"
 	"gorm.io/datatypes"
 )
 
@@ -22,3 +25,21 @@ type Workspace struct {
 func (Workspace) TableName() string {
 	return "workspaces"
 }
+
+// WorkspaceMember 工作区成员，用户可以加入多个工作区，并在每个工作区拥有独立的角色
+// 用户的主工作区（User.WorkspaceID）同样有一条成员记录，角色以 User.Role 为准
+type WorkspaceMember struct {
+	WorkspaceID uuid.UUID `gorm:"type:uuid;primaryKey;not null" json:"workspace_id"`
+	UserID      uuid.UUID `gorm:"type:uuid;primaryKey;not null;index" json:"user_id"`
+	Role        Role      `gorm:"type:varchar(20);not null;default:'member'" json:"role"`
+	JoinedAt    time.Time `gorm:"not null;default:now()" json:"joined_at"`
+
+	// 关联关系
+	Workspace *Workspace `gorm:"foreignKey:WorkspaceID;constraint:OnDelete:CASCADE" json:"workspace,omitempty"`
+	User      *User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+// TableName 指定表名
+func (WorkspaceMember) TableName() string {
+	return "workspace_members"
+}
@@ -0,0 +1,76 @@
+package oidc
+
+import (
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims ID Token 中用于登录和用户创建的声明
+type Claims struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+	Nonce             string
+	AuthorizedParty   string
+
+	// raw 原始声明，用于读取用户组等自定义声明
+	raw jwt.MapClaims
+}
+
+// newClaims 从原始声明中读取标准字段
+func newClaims(raw jwt.MapClaims) *Claims {
+	return &Claims{
+		Issuer:            stringClaim(raw, "iss"),
+		Subject:           stringClaim(raw, "sub"),
+		Email:             strings.ToLower(stringClaim(raw, "email")),
+		EmailVerified:     boolClaim(raw, "email_verified"),
+		Name:              stringClaim(raw, "name"),
+		PreferredUsername: stringClaim(raw, "preferred_username"),
+		Nonce:             stringClaim(raw, "nonce"),
+		AuthorizedParty:   stringClaim(raw, "azp"),
+		raw:               raw,
+	}
+}
+
+// Strings 读取字符串列表类型的声明（如 groups），兼容单个字符串
+func (c *Claims) Strings(name string) []string {
+	switch v := c.raw[name].(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// stringClaim 读取字符串声明
+func stringClaim(raw jwt.MapClaims, name string) string {
+	s, _ := raw[name].(string)
+	return s
+}
+
+// boolClaim 读取布尔声明，部分 IdP 以字符串 "true" 表示
+func boolClaim(raw jwt.MapClaims, name string) bool {
+	switch v := raw[name].(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	default:
+		return false
+	}
+}
@@ -0,0 +1,65 @@
+package oidc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/liwei0526vip/mylinear/internal/oidc/oidctest"
+)
+
+// TestProvider_KeyRotation 测试 IdP 轮换密钥后重新获取 JWKS
+func TestProvider_KeyRotation(t *testing.T) {
+	idp := oidctest.NewServer("mylinear", "secret")
+	defer idp.Close()
+
+	provider := NewProvider(Config{IssuerURL: idp.Issuer(), ClientID: "mylinear"})
+	ctx := context.Background()
+
+	sign := func() string {
+		raw, err := idp.SignIDToken(jwt.MapClaims{
+			"iss": idp.Issuer(),
+			"aud": "mylinear",
+			"sub": "user-1",
+			"exp": time.Now().Add(time.Minute).Unix(),
+		})
+		if err != nil {
+			t.Fatalf("SignIDToken() error = %v", err)
+		}
+		return raw
+	}
+
+	if _, err := provider.VerifyIDToken(ctx, sign(), ""); err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+
+	// 刚获取过 JWKS 时不会因未知 kid 立即重新获取
+	idp.RotateKey()
+	if _, err := provider.VerifyIDToken(ctx, sign(), ""); err == nil {
+		t.Fatal("最小刷新间隔内不应重新获取 JWKS")
+	}
+
+	// 超过最小刷新间隔后重新获取
+	provider.mu.Lock()
+	provider.keysFetchedAt = time.Now().Add(-jwksMinRefreshInterval)
+	provider.mu.Unlock()
+	if _, err := provider.VerifyIDToken(ctx, sign(), ""); err != nil {
+		t.Errorf("轮换密钥后 VerifyIDToken() error = %v", err)
+	}
+}
+
+func TestJSONWebKeySet_PublicKeys(t *testing.T) {
+	set := jsonWebKeySet{Keys: []jsonWebKey{
+		{Kty: "RSA", Kid: "enc", Use: "enc", N: "AQAB", E: "AQAB"},
+		{Kty: "oct", Kid: "hmac"},
+	}}
+	if _, err := set.publicKeys(); err == nil {
+		t.Error("没有签名密钥时应返回错误")
+	}
+
+	set = jsonWebKeySet{Keys: []jsonWebKey{{Kty: "EC", Kid: "ec", Crv: "P-256", X: "AQAB", Y: "AQAB"}}}
+	if _, err := set.publicKeys(); err == nil {
+		t.Error("不在曲线上的 EC 公钥应返回错误")
+	}
+}
@@ -0,0 +1,323 @@
+// Package oidc 实现 OpenID Connect 依赖方（Relying Party）：
+// 服务发现、授权码 + PKCE、ID Token 校验和 JWKS 缓存
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// jwksCacheTTL JWKS 缓存有效期
+	jwksCacheTTL = time.Hour
+	// jwksMinRefreshInterval 遇到未知 kid 时重新获取 JWKS 的最小间隔，防止被恶意令牌放大请求
+	jwksMinRefreshInterval = time.Minute
+	// clockSkew 校验 exp / iat 时允许的时钟偏差
+	clockSkew = time.Minute
+	// maxResponseSize IdP 响应体大小上限
+	maxResponseSize = 1 << 20
+)
+
+// supportedSigningMethods 支持的 ID Token 签名算法
+var supportedSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// Config 依赖方配置
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// HTTPClient 访问 IdP 使用的客户端，为空时使用带超时的默认客户端
+	HTTPClient *http.Client
+}
+
+// Metadata IdP 发现文档（/.well-known/openid-configuration）中用到的字段
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint,omitempty"`
+}
+
+// Token 令牌端点响应
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Provider OIDC 依赖方，发现文档和 JWKS 在首次使用时获取并缓存
+type Provider struct {
+	cfg    Config
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *Metadata
+	keys     map[string]interface{}
+	// keysFetchedAt 最近一次获取 JWKS 的时间
+	keysFetchedAt time.Time
+}
+
+// NewProvider 创建 OIDC 依赖方
+func NewProvider(cfg Config) *Provider {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	return &Provider{cfg: cfg, client: client}
+}
+
+// Metadata 获取 IdP 发现文档，成功后缓存
+func (p *Provider) Metadata(ctx context.Context) (*Metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.metadataLocked(ctx)
+}
+
+// metadataLocked 获取发现文档，调用方需持有锁
+func (p *Provider) metadataLocked(ctx context.Context) (*Metadata, error) {
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	wellKnown := strings.TrimSuffix(p.cfg.IssuerURL, "/") + "/.well-known/openid-configuration"
+	var metadata Metadata
+	if err := p.getJSON(ctx, wellKnown, &metadata); err != nil {
+		return nil, fmt.Errorf("获取 OIDC 发现文档失败: %w", err)
+	}
+	// OpenID Connect Discovery 4.3：issuer 必须与配置一致
+	if strings.TrimSuffix(metadata.Issuer, "/") != strings.TrimSuffix(p.cfg.IssuerURL, "/") {
+		return nil, fmt.Errorf("OIDC 发现文档 issuer 不匹配: %s", metadata.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("OIDC 发现文档缺少必需的端点")
+	}
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+// AuthCodeURL 生成 IdP 授权地址（授权码 + PKCE S256）
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	metadata, err := p.Metadata(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.cfg.ClientID)
+	params.Set("redirect_uri", p.cfg.RedirectURL)
+	params.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", CodeChallengeS256(codeVerifier))
+	params.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return metadata.AuthorizationEndpoint + sep + params.Encode(), nil
+}
+
+// Exchange 使用授权码和 PKCE verifier 换取令牌
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier string) (*Token, error) {
+	metadata, err := p.Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("client_id", p.cfg.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求 OIDC 令牌端点失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("读取 OIDC 令牌响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+		}
+		_ = json.Unmarshal(body, &oauthErr)
+		return nil, fmt.Errorf("OIDC 令牌端点返回 %d: %s %s", resp.StatusCode, oauthErr.Error, oauthErr.Description)
+	}
+
+	var token Token
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("解析 OIDC 令牌响应失败: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("OIDC 令牌响应缺少 id_token")
+	}
+	return &token, nil
+}
+
+// VerifyIDToken 校验 ID Token 的签名、issuer、audience、有效期和 nonce
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	metadata, err := p.Metadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods(supportedSigningMethods),
+		jwt.WithIssuer(metadata.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ID Token 校验失败: %w", err)
+	}
+
+	result := newClaims(claims)
+	if result.Subject == "" {
+		return nil, fmt.Errorf("ID Token 缺少 sub")
+	}
+	if nonce != "" && result.Nonce != nonce {
+		return nil, fmt.Errorf("ID Token nonce 不匹配")
+	}
+	// OpenID Connect Core 3.1.3.7：存在多个 audience 时 azp 必须为本客户端
+	if aud, _ := claims.GetAudience(); len(aud) > 1 && result.AuthorizedParty != p.cfg.ClientID {
+		return nil, fmt.Errorf("ID Token azp 不匹配")
+	}
+	return result, nil
+}
+
+// key 根据 kid 获取签名公钥，未知 kid 时刷新 JWKS（受最小间隔限制）
+func (p *Provider) key(ctx context.Context, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	expired := now.Sub(p.keysFetchedAt) > jwksCacheTTL
+	if p.keys == nil || expired {
+		if err := p.refreshKeysLocked(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if key, ok := p.lookupKeyLocked(kid); ok {
+		return key, nil
+	}
+	// IdP 可能已轮换密钥
+	if now.Sub(p.keysFetchedAt) >= jwksMinRefreshInterval {
+		if err := p.refreshKeysLocked(ctx); err != nil {
+			return nil, err
+		}
+		if key, ok := p.lookupKeyLocked(kid); ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("找不到 ID Token 签名密钥: %s", kid)
+}
+
+// lookupKeyLocked 查找公钥；没有 kid 且只有一个密钥时使用该密钥
+func (p *Provider) lookupKeyLocked(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// refreshKeysLocked 重新获取 JWKS，调用方需持有锁
+func (p *Provider) refreshKeysLocked(ctx context.Context) error {
+	metadata, err := p.metadataLocked(ctx)
+	if err != nil {
+		return err
+	}
+
+	var set jsonWebKeySet
+	if err := p.getJSON(ctx, metadata.JWKSURI, &set); err != nil {
+		return fmt.Errorf("获取 JWKS 失败: %w", err)
+	}
+	keys, err := set.publicKeys()
+	if err != nil {
+		return err
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	return nil
+}
+
+// getJSON 发送 GET 请求并解析 JSON 响应
+func (p *Provider) getJSON(ctx context.Context, endpoint string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s 返回 %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v)
+}
+
+// NewRandomString 生成 state / nonce / code_verifier 使用的随机字符串（43 个字符）
+func NewRandomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CodeChallengeS256 计算 PKCE code_challenge（RFC 7636 4.2）
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,175 @@
+package oidc_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/liwei0526vip/mylinear/internal/oidc"
+	"github.com/liwei0526vip/mylinear/internal/oidc/oidctest"
+)
+
+func newTestProvider(t *testing.T) (*oidctest.Server, *oidc.Provider) {
+	t.Helper()
+	idp := oidctest.NewServer("mylinear", "secret")
+	t.Cleanup(idp.Close)
+
+	provider := oidc.NewProvider(oidc.Config{
+		IssuerURL:    idp.Issuer(),
+		ClientID:     "mylinear",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/api/v1/auth/oidc/callback",
+	})
+	return idp, provider
+}
+
+// login 走完整授权码流程，返回校验后的声明
+func login(t *testing.T, idp *oidctest.Server, provider *oidc.Provider, verifier, nonce string) (*oidc.Claims, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	code, state, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if state != "state-1" {
+		t.Fatalf("state = %q, 期望 state-1", state)
+	}
+
+	token, err := provider.Exchange(ctx, code, verifier)
+	if err != nil {
+		return nil, err
+	}
+	return provider.VerifyIDToken(ctx, token.IDToken, nonce)
+}
+
+func TestProvider_Login(t *testing.T) {
+	idp, provider := newTestProvider(t)
+	idp.SetClaims(map[string]interface{}{
+		"sub":            "user-1",
+		"email":          "Alice@Example.com",
+		"email_verified": "true",
+		"name":           "Alice",
+		"groups":         []string{"engineering", "admins"},
+	})
+
+	verifier, _ := oidc.NewRandomString()
+	claims, err := login(t, idp, provider, verifier, "nonce-1")
+	if err != nil {
+		t.Fatalf("登录失败: %v", err)
+	}
+	if claims.Subject != "user-1" || claims.Email != "alice@example.com" || !claims.EmailVerified {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+	if groups := claims.Strings("groups"); len(groups) != 2 || groups[1] != "admins" {
+		t.Errorf("groups = %v", groups)
+	}
+	if claims.Issuer != idp.Issuer() {
+		t.Errorf("Issuer = %s, 期望 %s", claims.Issuer, idp.Issuer())
+	}
+}
+
+func TestProvider_ExchangeRequiresPKCE(t *testing.T) {
+	idp, provider := newTestProvider(t)
+	idp.SetClaims(map[string]interface{}{"sub": "user-1"})
+	ctx := context.Background()
+
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", "verifier-aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	if !strings.Contains(authURL, "code_challenge_method=S256") {
+		t.Errorf("授权地址缺少 PKCE 参数: %s", authURL)
+	}
+	code, _, err := idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if _, err := provider.Exchange(ctx, code, "wrong-verifier"); err == nil {
+		t.Error("错误的 code_verifier 应换取失败")
+	}
+}
+
+func TestProvider_VerifyIDToken(t *testing.T) {
+	idp, provider := newTestProvider(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":   idp.Issuer(),
+			"aud":   "mylinear",
+			"sub":   "user-1",
+			"nonce": "nonce-1",
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Minute).Unix(),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(jwt.MapClaims)
+		wantErr bool
+	}{
+		{"有效令牌", func(jwt.MapClaims) {}, false},
+		{"issuer 不匹配", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, true},
+		{"audience 不匹配", func(c jwt.MapClaims) { c["aud"] = "other" }, true},
+		{"已过期", func(c jwt.MapClaims) { c["exp"] = now.Add(-time.Hour).Unix() }, true},
+		{"缺少 exp", func(c jwt.MapClaims) { delete(c, "exp") }, true},
+		{"nonce 不匹配", func(c jwt.MapClaims) { c["nonce"] = "other" }, true},
+		{"缺少 sub", func(c jwt.MapClaims) { delete(c, "sub") }, true},
+		{"多个 audience 缺少 azp", func(c jwt.MapClaims) { c["aud"] = []string{"mylinear", "other"} }, true},
+		{"多个 audience 且 azp 正确", func(c jwt.MapClaims) {
+			c["aud"] = []string{"mylinear", "other"}
+			c["azp"] = "mylinear"
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := valid()
+			tt.mutate(claims)
+			raw, err := idp.SignIDToken(claims)
+			if err != nil {
+				t.Fatalf("SignIDToken() error = %v", err)
+			}
+			_, err = provider.VerifyIDToken(ctx, raw, "nonce-1")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyIDToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// 不接受 HS256 等对称算法
+	hs := jwt.NewWithClaims(jwt.SigningMethodHS256, valid())
+	raw, _ := hs.SignedString([]byte("mylinear"))
+	if _, err := provider.VerifyIDToken(ctx, raw, "nonce-1"); err == nil {
+		t.Error("HS256 签名的令牌应校验失败")
+	}
+}
+
+func TestProvider_DiscoveryIssuerMismatch(t *testing.T) {
+	idp := oidctest.NewServer("mylinear", "secret")
+	defer idp.Close()
+
+	provider := oidc.NewProvider(oidc.Config{
+		IssuerURL: idp.Issuer() + "/tenant",
+		ClientID:  "mylinear",
+	})
+	if _, err := provider.Metadata(context.Background()); err == nil {
+		t.Error("发现文档 issuer 不匹配时应返回错误")
+	}
+}
+
+func TestCodeChallengeS256(t *testing.T) {
+	// RFC 7636 附录 B 示例
+	got := oidc.CodeChallengeS256("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Errorf("CodeChallengeS256() = %s, 期望 %s", got, want)
+	}
+}
@@ -0,0 +1,251 @@
+// Package policy 实现资源级别的访问控制：判断用户能否对团队、Issue、评论、项目、
+// 工作流状态执行某个操作。资源先解析到所属工作区和团队（评论 → Issue → 团队，项目 → 关联团队），
+// 不属于当前工作区的资源视为不存在，再按用户的工作区角色和团队角色决定；私有团队只对成员可见，
+// 公开团队对工作区成员可见（访客除外）
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/gorm"
+)
+
+// Action 对资源执行的操作
+type Action string
+
+const (
+	// ActionRead 查看
+	ActionRead Action = "read"
+	// ActionWrite 创建和编辑 Issue、评论、订阅等日常协作
+	ActionWrite Action = "write"
+	// ActionManage 删除、恢复、移动 Issue，管理标签、工作流状态和项目，导入 Issue
+	ActionManage Action = "manage"
+	// ActionAdmin 管理团队设置、成员、Git 集成设置，删除项目
+	ActionAdmin Action = "admin"
+)
+
+// ResourceType 资源类型
+type ResourceType string
+
+const (
+	ResourceTeam          ResourceType = "team"
+	ResourceIssue         ResourceType = "issue"
+	ResourceComment       ResourceType = "comment"
+	ResourceProject       ResourceType = "project"
+	ResourceWorkflowState ResourceType = "workflow_state"
+)
+
+// Resource 被访问的资源
+type Resource struct {
+	Type ResourceType
+	ID   uuid.UUID
+}
+
+// Subject 访问资源的用户
+type Subject struct {
+	UserID uuid.UUID
+	// Role 用户在当前工作区的角色
+	Role model.Role
+	// WorkspaceID 用户当前所在的工作区，其他工作区的资源视为不存在（全局管理员除外）
+	WorkspaceID uuid.UUID
+}
+
+var (
+	// ErrNotFound 资源不存在，或用户不是资源所属团队的成员（不暴露资源是否存在）
+	ErrNotFound = errors.New("资源不存在")
+	// ErrForbidden 用户可以查看资源，但无权执行该操作
+	ErrForbidden = errors.New("无权限执行此操作")
+)
+
+// Enforcer 定义访问控制接口
+type Enforcer interface {
+	// Authorize 判断用户能否对资源执行操作，允许时返回 nil，否则返回 ErrNotFound 或 ErrForbidden
+	Authorize(ctx context.Context, subject Subject, action Action, resource Resource) error
+}
+
+// enforcer 实现 Enforcer 接口
+type enforcer struct {
+	db *gorm.DB
+}
+
+// NewEnforcer 创建访问控制实例
+func NewEnforcer(db *gorm.DB) Enforcer {
+	return &enforcer{db: db}
+}
+
+// Authorize 判断用户能否对资源执行操作
+func (e *enforcer) Authorize(ctx context.Context, subject Subject, action Action, resource Resource) error {
+	workspaceID, teamIDs, err := e.resolve(ctx, resource)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("解析资源所属工作区和团队失败: %w", err)
+	}
+	if !inWorkspace(subject, workspaceID) {
+		return ErrNotFound
+	}
+
+	// 未关联团队的项目属于整个工作区
+	if len(teamIDs) == 0 {
+		return decideWorkspaceResource(subject.Role, action)
+	}
+
+	teamRole, err := e.teamRole(ctx, subject.UserID, teamIDs)
+	if err != nil {
+		return err
+	}
+
+	if teamRole == "" {
+		public, err := e.anyPublic(ctx, teamIDs)
+		if err != nil {
+			return err
+		}
+		return decideNonMember(subject.Role, public, action)
+	}
+	return decide(subject.Role, teamRole, action)
+}
+
+// resolve 解析资源所属的工作区和团队
+func (e *enforcer) resolve(ctx context.Context, resource Resource) (uuid.UUID, []uuid.UUID, error) {
+	if resource.Type == ResourceProject {
+		workspaceID, err := store.ProjectWorkspaceID(ctx, e.db, resource.ID)
+		if err != nil {
+			return uuid.Nil, nil, err
+		}
+		teamIDs, err := store.ProjectTeamIDs(ctx, e.db, resource.ID)
+		return workspaceID, teamIDs, err
+	}
+
+	var teamID uuid.UUID
+	var err error
+	switch resource.Type {
+	case ResourceTeam:
+		teamID = resource.ID
+	case ResourceIssue:
+		teamID, err = store.IssueTeamID(ctx, e.db, resource.ID)
+	case ResourceComment:
+		teamID, err = store.CommentTeamID(ctx, e.db, resource.ID)
+	case ResourceWorkflowState:
+		teamID, err = store.WorkflowStateTeamID(ctx, e.db, resource.ID)
+	default:
+		return uuid.Nil, nil, fmt.Errorf("未知的资源类型: %s", resource.Type)
+	}
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	// 同时确认团队存在
+	workspaceID, err := store.TeamWorkspaceID(ctx, e.db, teamID)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	return workspaceID, []uuid.UUID{teamID}, nil
+}
+
+// teamRole 获取用户在这些团队中最高的团队角色，都不是成员时返回空字符串
+func (e *enforcer) teamRole(ctx context.Context, userID uuid.UUID, teamIDs []uuid.UUID) (model.Role, error) {
+	var best model.Role
+	for _, teamID := range teamIDs {
+		role, err := store.GetTeamRole(ctx, e.db, userID, teamID)
+		if err != nil {
+			return "", err
+		}
+		if role == model.RoleAdmin {
+			return role, nil
+		}
+		if role != "" {
+			best = role
+		}
+	}
+	return best, nil
+}
+
+// anyPublic 这些团队中是否有公开团队
+func (e *enforcer) anyPublic(ctx context.Context, teamIDs []uuid.UUID) (bool, error) {
+	for _, teamID := range teamIDs {
+		private, err := store.IsTeamPrivate(ctx, e.db, teamID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return false, err
+		}
+		if !private {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// inWorkspace 资源是否属于用户当前所在的工作区，全局管理员可以访问所有工作区
+func inWorkspace(subject Subject, workspaceID uuid.UUID) bool {
+	return subject.Role == model.RoleGlobalAdmin || (subject.WorkspaceID != uuid.Nil && subject.WorkspaceID == workspaceID)
+}
+
+// decideWorkspaceResource 决定能否操作用户不是团队成员的工作区资源（未关联团队的项目、公开团队）
+// 管理员可以执行所有操作，成员只能查看，访客看不到
+func decideWorkspaceResource(role model.Role, action Action) error {
+	switch {
+	case isWorkspaceAdmin(role):
+		return nil
+	case role == model.RoleGuest:
+		return ErrNotFound
+	case action == ActionRead:
+		return nil
+	default:
+		return ErrForbidden
+	}
+}
+
+// decideNonMember 决定非团队成员能否操作团队资源
+// 私有团队只对成员可见（管理员需要先加入）；公开团队管理员可以执行所有操作，成员只能查看，访客看不到
+func decideNonMember(role model.Role, public bool, action Action) error {
+	if !public {
+		return ErrNotFound
+	}
+	return decideWorkspaceResource(role, action)
+}
+
+// decide 根据工作区角色和团队角色决定是否允许操作
+// 不是团队成员时返回 ErrNotFound，不暴露资源是否存在
+func decide(role, teamRole model.Role, action Action) error {
+	if teamRole == "" {
+		return ErrNotFound
+	}
+	if !Allowed(role, teamRole, action) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Allowed 判断工作区角色为 role、团队角色为 teamRole 的用户能否执行操作
+//   - 全局管理员、工作区管理员：所有操作（仅限当前工作区，私有团队需要先加入）
+//   - 访客：查看和编辑（创建 Issue、评论）
+//   - 团队 Owner：所有操作
+//   - 团队成员：除管理团队外的所有操作
+//   - 非团队成员：不能执行任何操作（公开团队的查看权限由 Authorize 处理）
+func Allowed(role, teamRole model.Role, action Action) bool {
+	if isWorkspaceAdmin(role) {
+		return true
+	}
+	if teamRole == "" {
+		return false
+	}
+	if role == model.RoleGuest {
+		return action == ActionRead || action == ActionWrite
+	}
+	if teamRole == model.RoleAdmin {
+		return true
+	}
+	return action != ActionAdmin
+}
+
+// isWorkspaceAdmin 是否为工作区管理员或全局管理员
+func isWorkspaceAdmin(role model.Role) bool {
+	return role == model.RoleAdmin || role == model.RoleGlobalAdmin
+}
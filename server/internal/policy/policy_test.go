@@ -0,0 +1,135 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+)
+
+func TestAllowed(t *testing.T) {
+	actions := []Action{ActionRead, ActionWrite, ActionManage, ActionAdmin}
+
+	tests := []struct {
+		name     string
+		role     model.Role
+		teamRole model.Role
+		// want 依次对应 read、write、manage、admin
+		want [4]bool
+	}{
+		{"全局管理员", model.RoleGlobalAdmin, "", [4]bool{true, true, true, true}},
+		{"工作区管理员", model.RoleAdmin, "", [4]bool{true, true, true, true}},
+		{"团队 Owner", model.RoleMember, model.RoleAdmin, [4]bool{true, true, true, true}},
+		{"团队成员", model.RoleMember, model.RoleMember, [4]bool{true, true, true, false}},
+		{"非团队成员", model.RoleMember, "", [4]bool{false, false, false, false}},
+		{"访客团队成员", model.RoleGuest, model.RoleMember, [4]bool{true, true, false, false}},
+		{"访客不能因团队角色获得管理权限", model.RoleGuest, model.RoleAdmin, [4]bool{true, true, false, false}},
+		{"访客非团队成员", model.RoleGuest, "", [4]bool{false, false, false, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, action := range actions {
+				if got := Allowed(tt.role, tt.teamRole, action); got != tt.want[i] {
+					t.Errorf("Allowed(%s, %q, %s) = %v, want %v", tt.role, tt.teamRole, action, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDecide(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     model.Role
+		teamRole model.Role
+		action   Action
+		wantErr  error
+	}{
+		{"成员可以编辑", model.RoleMember, model.RoleMember, ActionWrite, nil},
+		{"非成员看不到资源", model.RoleMember, "", ActionRead, ErrNotFound},
+		{"成员不能管理团队", model.RoleMember, model.RoleMember, ActionAdmin, ErrForbidden},
+		{"访客不能删除 Issue", model.RoleGuest, model.RoleMember, ActionManage, ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decide(tt.role, tt.teamRole, tt.action)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("decide() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDecideNonMember(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    model.Role
+		public  bool
+		action  Action
+		wantErr error
+	}{
+		{"成员查看公开团队", model.RoleMember, true, ActionRead, nil},
+		{"成员不能编辑公开团队的 Issue", model.RoleMember, true, ActionWrite, ErrForbidden},
+		{"成员看不到私有团队", model.RoleMember, false, ActionRead, ErrNotFound},
+		{"访客看不到公开团队", model.RoleGuest, true, ActionRead, ErrNotFound},
+		{"管理员可以管理公开团队", model.RoleAdmin, true, ActionAdmin, nil},
+		{"管理员看不到未加入的私有团队", model.RoleAdmin, false, ActionRead, ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := decideNonMember(tt.role, tt.public, tt.action); !errors.Is(err, tt.wantErr) {
+				t.Errorf("decideNonMember() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInWorkspace(t *testing.T) {
+	workspaceID := uuid.New()
+
+	tests := []struct {
+		name    string
+		subject Subject
+		want    bool
+	}{
+		{"当前工作区的资源", Subject{Role: model.RoleMember, WorkspaceID: workspaceID}, true},
+		{"其他工作区的管理员", Subject{Role: model.RoleAdmin, WorkspaceID: uuid.New()}, false},
+		{"未确定工作区", Subject{Role: model.RoleAdmin}, false},
+		{"全局管理员", Subject{Role: model.RoleGlobalAdmin, WorkspaceID: uuid.New()}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inWorkspace(tt.subject, workspaceID); got != tt.want {
+				t.Errorf("inWorkspace() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecideWorkspaceResource(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    model.Role
+		action  Action
+		wantErr error
+	}{
+		{"管理员可以删除", model.RoleAdmin, ActionAdmin, nil},
+		{"成员可以查看", model.RoleMember, ActionRead, nil},
+		{"成员不能修改", model.RoleMember, ActionManage, ErrForbidden},
+		{"成员不能删除", model.RoleMember, ActionAdmin, ErrForbidden},
+		{"访客看不到", model.RoleGuest, ActionRead, ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := decideWorkspaceResource(tt.role, tt.action); !errors.Is(err, tt.wantErr) {
+				t.Errorf("decideWorkspaceResource() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
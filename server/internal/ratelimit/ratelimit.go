@@ -0,0 +1,224 @@
+// Package ratelimit 基于 Redis 实现分布式限流，支持滑动窗口和令牌桶两种算法，
+// 多个服务实例共享同一份计数
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix Redis 中限流计数键的前缀
+const keyPrefix = "rate_limit:"
+
+// Algorithm 限流算法
+type Algorithm string
+
+const (
+	// SlidingWindow 滑动窗口：任意 Period 时长内最多 Limit 次请求
+	SlidingWindow Algorithm = "sliding_window"
+	// TokenBucket 令牌桶：容量为 Limit，每 Period/Limit 补充一个令牌，允许短时突发
+	TokenBucket Algorithm = "token_bucket"
+)
+
+// Rule 限流规则：每 Period 时长允许 Limit 次请求
+type Rule struct {
+	Limit  int
+	Period time.Duration
+}
+
+// ParseRule 解析 "次数/时长" 格式的限流规则，如 "10/1m"、"100/h"；空字符串或 "0" 表示不限流
+func ParseRule(s string) (Rule, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return Rule{}, nil
+	}
+
+	limitPart, periodPart, ok := strings.Cut(s, "/")
+	if !ok {
+		return Rule{}, fmt.Errorf("无效的限流规则 %q，格式为 次数/时长（如 10/1m）", s)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(limitPart))
+	if err != nil || limit < 0 {
+		return Rule{}, fmt.Errorf("无效的限流规则 %q: 次数必须为非负整数", s)
+	}
+
+	// 省略数字时按 1 个单位处理（如 "h" 表示 1h）
+	periodPart = strings.TrimSpace(periodPart)
+	if periodPart != "" && !strings.ContainsAny(periodPart[:1], "0123456789") {
+		periodPart = "1" + periodPart
+	}
+	period, err := time.ParseDuration(periodPart)
+	if err != nil || period <= 0 {
+		return Rule{}, fmt.Errorf("无效的限流规则 %q: 时长格式错误", s)
+	}
+
+	return Rule{Limit: limit, Period: period}, nil
+}
+
+// Enabled 规则是否生效
+func (r Rule) Enabled() bool {
+	return r.Limit > 0 && r.Period > 0
+}
+
+// String 返回 "次数/时长" 格式
+func (r Rule) String() string {
+	return fmt.Sprintf("%d/%s", r.Limit, r.Period)
+}
+
+// Result 一次限流检查的结果
+type Result struct {
+	// Allowed 是否放行本次请求
+	Allowed bool
+	// Limit 规则允许的请求次数
+	Limit int
+	// Remaining 本次请求之后剩余的次数
+	Remaining int
+	// Reset 额度完全恢复还需的时长
+	Reset time.Duration
+	// RetryAfter 被拒绝时需要等待的时长，放行时为 0
+	RetryAfter time.Duration
+}
+
+// Limiter 限流器
+type Limiter interface {
+	// Allow 检查 key 在规则下是否还有额度，放行时消耗一次额度
+	Allow(ctx context.Context, key string, rule Rule) (*Result, error)
+}
+
+// slidingWindowScript 使用有序集合记录窗口内每次请求的时间戳（毫秒）
+// 返回 {是否放行, 剩余次数, 额度恢复时长, 重试等待时长}
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+local retry = 0
+if allowed == 0 then
+	retry = reset
+end
+return {allowed, limit - count, reset, retry}
+`)
+
+// tokenBucketScript 在哈希中保存剩余令牌数和上次补充时间（毫秒），按经过的时间补充令牌
+// 返回 {是否放行, 剩余次数, 额度恢复时长, 重试等待时长}
+var tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local interval = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', key, 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) / interval)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) * interval)
+end
+redis.call('HSET', key, 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', key, math.ceil(capacity * interval))
+
+return {allowed, math.floor(tokens), math.ceil((capacity - tokens) * interval), retry}
+`)
+
+// redisLimiter 基于 Redis Lua 脚本的限流器，检查和计数在 Redis 中原子完成
+type redisLimiter struct {
+	rdb       *redis.Client
+	algorithm Algorithm
+}
+
+// NewRedisLimiter 创建基于 Redis 的限流器
+func NewRedisLimiter(rdb *redis.Client, algorithm Algorithm) (Limiter, error) {
+	switch algorithm {
+	case SlidingWindow, TokenBucket:
+	default:
+		return nil, fmt.Errorf("不支持的限流算法 %q，可选 %s 或 %s", algorithm, SlidingWindow, TokenBucket)
+	}
+	return &redisLimiter{rdb: rdb, algorithm: algorithm}, nil
+}
+
+// Allow 检查 key 在规则下是否还有额度，规则未生效时始终放行
+func (l *redisLimiter) Allow(ctx context.Context, key string, rule Rule) (*Result, error) {
+	if !rule.Enabled() {
+		return &Result{Allowed: true, Limit: rule.Limit, Remaining: rule.Limit}, nil
+	}
+
+	now := time.Now().UnixMilli()
+	key = keyPrefix + key
+
+	var cmd *redis.Cmd
+	switch l.algorithm {
+	case TokenBucket:
+		interval := float64(rule.Period.Milliseconds()) / float64(rule.Limit)
+		cmd = tokenBucketScript.Run(ctx, l.rdb, []string{key}, now, rule.Limit, strconv.FormatFloat(interval, 'f', -1, 64))
+	default:
+		member := strconv.FormatInt(now, 10) + "-" + uuid.NewString()
+		cmd = slidingWindowScript.Run(ctx, l.rdb, []string{key}, now, rule.Period.Milliseconds(), rule.Limit, member)
+	}
+
+	values, err := cmd.Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("检查请求频率失败: %w", err)
+	}
+	if len(values) != 4 {
+		return nil, fmt.Errorf("检查请求频率失败: 限流脚本返回值异常")
+	}
+
+	return &Result{
+		Allowed:    values[0] == 1,
+		Limit:      rule.Limit,
+		Remaining:  int(max(values[1], 0)),
+		Reset:      time.Duration(max(values[2], 0)) * time.Millisecond,
+		RetryAfter: time.Duration(max(values[3], 0)) * time.Millisecond,
+	}, nil
+}
+
+// SetHeaders 写入标准限流响应头（RateLimit-Limit / RateLimit-Remaining / RateLimit-Reset / RateLimit-Policy），
+// 被拒绝时同时写入 Retry-After；时长按秒向上取整
+func SetHeaders(h http.Header, rule Rule, res *Result) {
+	h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+	h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", rule.Limit, ceilSeconds(rule.Period)))
+	if !res.Allowed {
+		h.Set("Retry-After", strconv.Itoa(max(ceilSeconds(res.RetryAfter), 1)))
+	}
+}
+
+// ceilSeconds 将时长按秒向上取整
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
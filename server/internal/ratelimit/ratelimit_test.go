@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rule
+		wantErr bool
+	}{
+		{"10/1m", Rule{Limit: 10, Period: time.Minute}, false},
+		{" 100 / h ", Rule{Limit: 100, Period: time.Hour}, false},
+		{"5/30s", Rule{Limit: 5, Period: 30 * time.Second}, false},
+		{"", Rule{}, false},
+		{"0", Rule{}, false},
+		{"10", Rule{}, true},
+		{"abc/1m", Rule{}, true},
+		{"-1/1m", Rule{}, true},
+		{"10/0s", Rule{}, true},
+		{"10/xyz", Rule{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRule(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRule(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRule(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+
+	if (Rule{}).Enabled() || (Rule{Limit: 0, Period: time.Minute}).Enabled() {
+		t.Error("空规则不应生效")
+	}
+}
+
+func TestNewRedisLimiter_UnknownAlgorithm(t *testing.T) {
+	if _, err := NewRedisLimiter(nil, Algorithm("fixed_window")); err == nil {
+		t.Error("不支持的算法应返回错误")
+	}
+}
+
+func TestSetHeaders(t *testing.T) {
+	rule := Rule{Limit: 10, Period: time.Minute}
+
+	h := http.Header{}
+	SetHeaders(h, rule, &Result{Allowed: true, Limit: 10, Remaining: 7, Reset: 1500 * time.Millisecond})
+	want := map[string]string{
+		"RateLimit-Limit":     "10",
+		"RateLimit-Remaining": "7",
+		"RateLimit-Reset":     "2",
+		"RateLimit-Policy":    "10;w=60",
+		"Retry-After":         "",
+	}
+	for k, v := range want {
+		if got := h.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+
+	// 被拒绝时写入 Retry-After，至少为 1 秒
+	h = http.Header{}
+	SetHeaders(h, rule, &Result{Allowed: false, Limit: 10, Reset: 30 * time.Second, RetryAfter: 200 * time.Millisecond})
+	if got := h.Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+}
+
+// testRedis 连接本地 Redis，不可用时跳过测试
+func testRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		t.Skip("Redis 不可用")
+	}
+	t.Cleanup(func() { _ = rdb.Close() })
+	return rdb
+}
+
+func TestRedisLimiter(t *testing.T) {
+	rdb := testRedis(t)
+	rule := Rule{Limit: 3, Period: time.Minute}
+
+	for _, algorithm := range []Algorithm{SlidingWindow, TokenBucket} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			limiter, err := NewRedisLimiter(rdb, algorithm)
+			if err != nil {
+				t.Fatalf("NewRedisLimiter() error = %v", err)
+			}
+			ctx := context.Background()
+			key := "test:" + uuid.NewString()
+			t.Cleanup(func() { rdb.Del(ctx, keyPrefix+key) })
+
+			for i := 0; i < rule.Limit; i++ {
+				res, err := limiter.Allow(ctx, key, rule)
+				if err != nil {
+					t.Fatalf("Allow() error = %v", err)
+				}
+				if !res.Allowed || res.Remaining != rule.Limit-i-1 {
+					t.Fatalf("第 %d 次请求: %+v", i+1, res)
+				}
+			}
+
+			res, err := limiter.Allow(ctx, key, rule)
+			if err != nil {
+				t.Fatalf("Allow() error = %v", err)
+			}
+			if res.Allowed || res.Remaining != 0 {
+				t.Errorf("超出限制后应拒绝: %+v", res)
+			}
+			if res.RetryAfter <= 0 || res.RetryAfter > rule.Period {
+				t.Errorf("RetryAfter = %v", res.RetryAfter)
+			}
+
+			// 不同的 key 互不影响
+			res, err = limiter.Allow(ctx, key+":other", rule)
+			t.Cleanup(func() { rdb.Del(ctx, keyPrefix+key+":other") })
+			if err != nil || !res.Allowed {
+				t.Errorf("其他 key 应放行: %+v, %v", res, err)
+			}
+		})
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/liwei0526vip/mylinear/internal/handler"
 	"github.com/liwei0526vip/mylinear/internal/middleware"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/policy"
+	"github.com/liwei0526vip/mylinear/internal/ratelimit"
 	"github.com/liwei0526vip/mylinear/internal/service"
 	"github.com/liwei0526vip/mylinear/internal/store"
 	"gorm.io/gorm"
@@ -20,8 +23,12 @@ func RegisterWorkspaceRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService servic
 	})
 	workspaceGroup.Use(middleware.Auth(jwtService))
 	{
+		workspaceGroup.GET("", workspaceHandler.ListWorkspaces)
+		workspaceGroup.POST("", middleware.RequireScope(model.APIKeyScopeAdmin), workspaceHandler.CreateWorkspace)
 		workspaceGroup.GET("/:id", workspaceHandler.GetWorkspace)
-		workspaceGroup.PUT("/:id", workspaceHandler.UpdateWorkspace)
+		workspaceGroup.PUT("/:id", middleware.RequireScope(model.APIKeyScopeAdmin), workspaceHandler.UpdateWorkspace)
+		// 与 POST /workspaces/:workspaceId/projects 共用参数名
+		workspaceGroup.POST("/:workspaceId/switch", workspaceHandler.SwitchWorkspace)
 	}
 }
 
@@ -34,12 +41,14 @@ func RegisterTeamRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.JWT
 		c.Set("db", db)
 	})
 	teamsGroup.Use(middleware.Auth(jwtService))
+	teamsGroup.Use(middleware.WorkspaceScope())
+	teamsGroup.Use(middleware.RestrictGuest())
 	{
 		teamsGroup.GET("", teamHandler.ListTeams)
 		teamsGroup.POST("", teamHandler.CreateTeam)
-		teamsGroup.GET("/:teamId", teamHandler.GetTeam)
-		teamsGroup.PUT("/:teamId", teamHandler.UpdateTeam)
-		teamsGroup.DELETE("/:teamId", teamHandler.DeleteTeam)
+		teamsGroup.GET("/:teamId", middleware.Authorize(policy.ActionRead), teamHandler.GetTeam)
+		teamsGroup.PUT("/:teamId", middleware.Authorize(policy.ActionAdmin), teamHandler.UpdateTeam)
+		teamsGroup.DELETE("/:teamId", middleware.Authorize(policy.ActionAdmin), teamHandler.DeleteTeam)
 	}
 }
 
@@ -52,11 +61,15 @@ func RegisterTeamMemberRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService servi
 		c.Set("db", db)
 	})
 	teamsGroup.Use(middleware.Auth(jwtService))
+	teamsGroup.Use(middleware.WorkspaceScope())
+	teamsGroup.Use(middleware.RestrictGuest())
 	{
-		teamsGroup.GET("/:teamId/members", teamMemberHandler.ListMembers)
-		teamsGroup.POST("/:teamId/members", teamMemberHandler.AddMember)
-		teamsGroup.DELETE("/:teamId/members/:userId", teamMemberHandler.RemoveMember)
-		teamsGroup.PUT("/:teamId/members/:userId", teamMemberHandler.UpdateMemberRole)
+		teamsGroup.GET("/:teamId/members", middleware.Authorize(policy.ActionRead), teamMemberHandler.ListMembers)
+		teamsGroup.POST("/:teamId/members", middleware.Authorize(policy.ActionAdmin), teamMemberHandler.AddMember)
+		teamsGroup.DELETE("/:teamId/members/:userId", middleware.Authorize(policy.ActionAdmin), teamMemberHandler.RemoveMember)
+		teamsGroup.PUT("/:teamId/members/:userId", middleware.Authorize(policy.ActionAdmin), teamMemberHandler.UpdateMemberRole)
+		// 管理员主动加入团队（私有团队对未加入的管理员不可见，因此不经过 Authorize）
+		teamsGroup.POST("/:teamId/join", middleware.RequireAdmin(), teamMemberHandler.JoinTeam)
 	}
 }
 
@@ -69,11 +82,13 @@ func RegisterWorkflowRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service
 		c.Set("db", db)
 	})
 	workflowGroup.Use(middleware.Auth(jwtService))
+	workflowGroup.Use(middleware.WorkspaceScope())
+	workflowGroup.Use(middleware.RestrictGuest())
 	{
-		workflowGroup.GET("/teams/:teamId/workflow-states", workflowHandler.ListStates)
-		workflowGroup.POST("/teams/:teamId/workflow-states", workflowHandler.CreateState)
-		workflowGroup.PUT("/workflow-states/:id", workflowHandler.UpdateState)
-		workflowGroup.DELETE("/workflow-states/:id", workflowHandler.DeleteState)
+		workflowGroup.GET("/teams/:teamId/workflow-states", middleware.Authorize(policy.ActionRead), workflowHandler.ListStates)
+		workflowGroup.POST("/teams/:teamId/workflow-states", middleware.Authorize(policy.ActionManage), workflowHandler.CreateState)
+		workflowGroup.PUT("/workflow-states/:id", middleware.Authorize(policy.ActionManage), workflowHandler.UpdateState)
+		workflowGroup.DELETE("/workflow-states/:id", middleware.Authorize(policy.ActionManage), workflowHandler.DeleteState)
 	}
 }
 
@@ -86,9 +101,11 @@ func RegisterLabelRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.JW
 		c.Set("db", db)
 	})
 	labelGroup.Use(middleware.Auth(jwtService))
+	labelGroup.Use(middleware.WorkspaceScope())
+	labelGroup.Use(middleware.RestrictGuest())
 	{
-		labelGroup.GET("/teams/:teamId/labels", labelHandler.ListLabels)
-		labelGroup.POST("/teams/:teamId/labels", labelHandler.CreateLabel)
+		labelGroup.GET("/teams/:teamId/labels", middleware.Authorize(policy.ActionRead), labelHandler.ListLabels)
+		labelGroup.POST("/teams/:teamId/labels", middleware.Authorize(policy.ActionManage), labelHandler.CreateLabel)
 	}
 }
 
@@ -101,26 +118,32 @@ func RegisterIssueRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.JW
 		c.Set("db", db)
 	})
 	issueGroup.Use(middleware.Auth(jwtService))
+	issueGroup.Use(middleware.ResolveIssueIdentifier())
+	issueGroup.Use(middleware.WorkspaceScope())
+	issueGroup.Use(middleware.RestrictGuest())
 	{
 		// 团队内 Issue 操作
-		issueGroup.GET("/teams/:teamId/issues", issueHandler.ListIssues)
-		issueGroup.POST("/teams/:teamId/issues", issueHandler.CreateIssue)
+		issueGroup.GET("/teams/:teamId/issues", middleware.Authorize(policy.ActionRead), issueHandler.ListIssues)
+		issueGroup.POST("/teams/:teamId/issues", middleware.Authorize(policy.ActionWrite), issueHandler.CreateIssue)
 
-		// Issue CRUD
-		issueGroup.GET("/issues/:id", issueHandler.GetIssue)
-		issueGroup.PUT("/issues/:id", issueHandler.UpdateIssue)
-		issueGroup.DELETE("/issues/:id", issueHandler.DeleteIssue)
+		// 通过标识符查找（如 ENG-42）
+		issueGroup.GET("/issues/lookup/:identifier", middleware.Authorize(policy.ActionRead), issueHandler.LookupIssue)
+
+		// Issue CRUD（:id 同时支持 UUID 和标识符）
+		issueGroup.GET("/issues/:id", middleware.Authorize(policy.ActionRead), issueHandler.GetIssue)
+		issueGroup.PUT("/issues/:id", middleware.Authorize(policy.ActionWrite), issueHandler.UpdateIssue)
+		issueGroup.DELETE("/issues/:id", middleware.Authorize(policy.ActionManage), issueHandler.DeleteIssue)
 
 		// Issue 位置更新
-		issueGroup.PUT("/issues/:id/position", issueHandler.UpdatePosition)
+		issueGroup.PUT("/issues/:id/position", middleware.Authorize(policy.ActionWrite), issueHandler.UpdatePosition)
 
 		// Issue 订阅
-		issueGroup.POST("/issues/:id/subscribe", issueHandler.Subscribe)
-		issueGroup.DELETE("/issues/:id/subscribe", issueHandler.Unsubscribe)
-		issueGroup.GET("/issues/:id/subscribers", issueHandler.ListSubscribers)
+		issueGroup.POST("/issues/:id/subscribe", middleware.Authorize(policy.ActionWrite), issueHandler.Subscribe)
+		issueGroup.DELETE("/issues/:id/subscribe", middleware.Authorize(policy.ActionWrite), issueHandler.Unsubscribe)
+		issueGroup.GET("/issues/:id/subscribers", middleware.Authorize(policy.ActionRead), issueHandler.ListSubscribers)
 
 		// Issue 恢复
-		issueGroup.POST("/issues/:id/restore", issueHandler.RestoreIssue)
+		issueGroup.POST("/issues/:id/restore", middleware.Authorize(policy.ActionManage), issueHandler.RestoreIssue)
 	}
 }
 
@@ -133,23 +156,25 @@ func RegisterProjectRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.
 		c.Set("db", db)
 	})
 	projectGroup.Use(middleware.Auth(jwtService))
+	projectGroup.Use(middleware.WorkspaceScope())
+	projectGroup.Use(middleware.RestrictGuest())
 	{
 		// 工作区内创建项目
 		projectGroup.POST("/workspaces/:workspaceId/projects", projectHandler.CreateProject)
 
 		// 团队项目列表
-		projectGroup.GET("/teams/:teamId/projects", projectHandler.ListTeamProjects)
+		projectGroup.GET("/teams/:teamId/projects", middleware.Authorize(policy.ActionRead), projectHandler.ListTeamProjects)
 
 		// Project CRUD
-		projectGroup.GET("/projects/:id", projectHandler.GetProject)
-		projectGroup.PUT("/projects/:id", projectHandler.UpdateProject)
-		projectGroup.DELETE("/projects/:id", projectHandler.DeleteProject)
+		projectGroup.GET("/projects/:id", middleware.Authorize(policy.ActionRead), projectHandler.GetProject)
+		projectGroup.PUT("/projects/:id", middleware.Authorize(policy.ActionManage), projectHandler.UpdateProject)
+		projectGroup.DELETE("/projects/:id", middleware.Authorize(policy.ActionAdmin), projectHandler.DeleteProject)
 
 		// Project 进度
-		projectGroup.GET("/projects/:id/progress", projectHandler.GetProjectProgress)
+		projectGroup.GET("/projects/:id/progress", middleware.Authorize(policy.ActionRead), projectHandler.GetProjectProgress)
 
 		// Project Issue 列表
-		projectGroup.GET("/projects/:id/issues", projectHandler.ListProjectIssues)
+		projectGroup.GET("/projects/:id/issues", middleware.Authorize(policy.ActionRead), projectHandler.ListProjectIssues)
 	}
 }
 
@@ -162,14 +187,17 @@ func RegisterCommentRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.
 		c.Set("db", db)
 	})
 	commentGroup.Use(middleware.Auth(jwtService))
+	commentGroup.Use(middleware.ResolveIssueIdentifier())
+	commentGroup.Use(middleware.WorkspaceScope())
+	commentGroup.Use(middleware.RestrictGuest())
 	{
 		// Issue 评论 (使用 :id 参数名与 Issue 路由一致)
-		commentGroup.GET("/issues/:id/comments", commentHandler.ListIssueComments)
-		commentGroup.POST("/issues/:id/comments", commentHandler.CreateComment)
+		commentGroup.GET("/issues/:id/comments", middleware.Authorize(policy.ActionRead), commentHandler.ListIssueComments)
+		commentGroup.POST("/issues/:id/comments", middleware.Authorize(policy.ActionWrite), commentHandler.CreateComment)
 
 		// 评论 CRUD
-		commentGroup.PUT("/comments/:commentId", commentHandler.UpdateComment)
-		commentGroup.DELETE("/comments/:commentId", commentHandler.DeleteComment)
+		commentGroup.PUT("/comments/:commentId", middleware.Authorize(policy.ActionWrite), commentHandler.UpdateComment)
+		commentGroup.DELETE("/comments/:commentId", middleware.Authorize(policy.ActionWrite), commentHandler.DeleteComment)
 	}
 }
 
@@ -182,8 +210,300 @@ func RegisterActivityRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service
 		c.Set("db", db)
 	})
 	activityGroup.Use(middleware.Auth(jwtService))
+	activityGroup.Use(middleware.ResolveIssueIdentifier())
+	activityGroup.Use(middleware.WorkspaceScope())
+	activityGroup.Use(middleware.RestrictGuest())
 	{
 		// Issue 活动 (使用 :id 参数名与 Issue 路由一致)
-		activityGroup.GET("/issues/:id/activities", activityHandler.ListIssueActivities)
+		activityGroup.GET("/issues/:id/activities", middleware.Authorize(policy.ActionRead), activityHandler.ListIssueActivities)
+	}
+}
+
+// RegisterIssueTransferRoutes 注册 Issue 导入导出路由
+func RegisterIssueTransferRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.JWTService, transferService service.IssueTransferService) {
+	transferHandler := handler.NewIssueTransferHandler(transferService)
+
+	transferGroup := rg.Group("")
+	transferGroup.Use(func(c *gin.Context) {
+		c.Set("db", db)
+	})
+	transferGroup.Use(middleware.Auth(jwtService))
+	transferGroup.Use(middleware.WorkspaceScope())
+	transferGroup.Use(middleware.RestrictGuest())
+	{
+		transferGroup.GET("/teams/:teamId/issues/export", middleware.Authorize(policy.ActionRead), transferHandler.ExportIssues)
+		transferGroup.POST("/teams/:teamId/issues/import", middleware.Authorize(policy.ActionManage), transferHandler.ImportIssues)
+	}
+}
+
+// RegisterJobRoutes 注册后台任务路由
+func RegisterJobRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.JWTService, jobService service.JobService) {
+	jobHandler := handler.NewJobHandler(jobService)
+
+	jobGroup := rg.Group("/jobs")
+	jobGroup.Use(func(c *gin.Context) {
+		c.Set("db", db)
+	})
+	jobGroup.Use(middleware.Auth(jwtService))
+	jobGroup.Use(middleware.WorkspaceScope())
+	{
+		jobGroup.GET("/:id", jobHandler.GetJob)
+	}
+}
+
+// RegisterImportRoutes 注册外部系统导入路由
+func RegisterImportRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.JWTService, importService service.ImportService) {
+	importHandler := handler.NewImportHandler(importService)
+
+	importGroup := rg.Group("")
+	importGroup.Use(func(c *gin.Context) {
+		c.Set("db", db)
+	})
+	importGroup.Use(middleware.Auth(jwtService))
+	importGroup.Use(middleware.WorkspaceScope())
+	importGroup.Use(middleware.RestrictGuest())
+	{
+		// 导入进度通过 GET /jobs/:id 查询
+		importGroup.POST("/teams/:teamId/imports", middleware.Authorize(policy.ActionManage), importHandler.StartImport)
+	}
+}
+
+// RegisterGitIntegrationRoutes 注册 Git 集成路由
+func RegisterGitIntegrationRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.JWTService, gitService service.GitIntegrationService) {
+	gitHandler := handler.NewGitIntegrationHandler(gitService)
+
+	// Webhook 通过签名 / Token 校验，不使用 JWT 认证
+	webhookGroup := rg.Group("/webhooks")
+	{
+		webhookGroup.POST("/github", gitHandler.GitHubWebhook)
+		webhookGroup.POST("/gitlab", gitHandler.GitLabWebhook)
+	}
+
+	gitGroup := rg.Group("")
+	gitGroup.Use(func(c *gin.Context) {
+		c.Set("db", db)
+	})
+	gitGroup.Use(middleware.Auth(jwtService))
+	gitGroup.Use(middleware.ResolveIssueIdentifier())
+	gitGroup.Use(middleware.WorkspaceScope())
+	gitGroup.Use(middleware.RestrictGuest())
+	{
+		gitGroup.GET("/issues/:id/branch-name", middleware.Authorize(policy.ActionRead), gitHandler.GetBranchName)
+		gitGroup.GET("/issues/:id/links", middleware.Authorize(policy.ActionRead), gitHandler.ListIssueLinks)
+		gitGroup.GET("/teams/:teamId/git-settings", middleware.Authorize(policy.ActionRead), gitHandler.GetGitSettings)
+		gitGroup.PUT("/teams/:teamId/git-settings", middleware.Authorize(policy.ActionAdmin), gitHandler.UpdateGitSettings)
+	}
+}
+
+// RegisterIssueMoveRoutes 注册 Issue 跨团队移动路由
+func RegisterIssueMoveRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.JWTService, moveService service.IssueMoveService) {
+	moveHandler := handler.NewIssueMoveHandler(moveService)
+
+	moveGroup := rg.Group("")
+	moveGroup.Use(func(c *gin.Context) {
+		c.Set("db", db)
+	})
+	moveGroup.Use(middleware.Auth(jwtService))
+	moveGroup.Use(middleware.ResolveIssueIdentifier())
+	moveGroup.Use(middleware.WorkspaceScope())
+	moveGroup.Use(middleware.RestrictGuest())
+	{
+		moveGroup.POST("/issues/:id/move", middleware.Authorize(policy.ActionManage), moveHandler.MoveIssue)
+	}
+}
+
+// RegisterAPIKeyRoutes 注册个人 API Key 路由
+func RegisterAPIKeyRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.JWTService, apiKeyService service.APIKeyService) {
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+
+	apiKeyGroup := rg.Group("/api-keys")
+	apiKeyGroup.Use(func(c *gin.Context) {
+		c.Set("db", db)
+	})
+	apiKeyGroup.Use(middleware.Auth(jwtService))
+	// 使用 API Key 管理 API Key 需要 admin 范围，避免泄露的低权限 Key 签发新 Key
+	apiKeyGroup.Use(middleware.RequireScope(model.APIKeyScopeAdmin))
+	{
+		apiKeyGroup.GET("", apiKeyHandler.ListAPIKeys)
+		apiKeyGroup.POST("", apiKeyHandler.CreateAPIKey)
+		apiKeyGroup.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
+	}
+}
+
+// RegisterOAuthRoutes 注册 OAuth2 授权服务路由，令牌端点按 authRule 限流
+func RegisterOAuthRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.JWTService, oauthService service.OAuthService, limiter ratelimit.Limiter, authRule ratelimit.Rule) {
+	oauthHandler := handler.NewOAuthHandler(oauthService)
+
+	// 令牌端点使用客户端认证，不使用用户认证；按客户端 IP 限流，防止暴力破解客户端密钥和授权码
+	tokenGroup := rg.Group("/oauth")
+	{
+		tokenGroup.POST("/token", middleware.RateLimitByIP(limiter, authRule, "oauth_token"), oauthHandler.Token)
+		tokenGroup.POST("/revoke", oauthHandler.Revoke)
+		tokenGroup.POST("/introspect", oauthHandler.Introspect)
+	}
+
+	// 授权确认和应用管理只允许用户本人操作，API Key / OAuth 令牌需要 admin 范围
+	oauthGroup := rg.Group("/oauth")
+	oauthGroup.Use(func(c *gin.Context) {
+		c.Set("db", db)
+	})
+	oauthGroup.Use(middleware.Auth(jwtService))
+	oauthGroup.Use(middleware.RequireScope(model.APIKeyScopeAdmin))
+	{
+		oauthGroup.GET("/authorize", oauthHandler.GetAuthorization)
+		oauthGroup.POST("/authorize", oauthHandler.Authorize)
+		oauthGroup.GET("/applications", oauthHandler.ListApplications)
+		oauthGroup.POST("/applications", oauthHandler.CreateApplication)
+		oauthGroup.DELETE("/applications/:id", oauthHandler.DeleteApplication)
+	}
+}
+
+// RegisterOIDCRoutes 注册 OIDC 单点登录路由
+func RegisterOIDCRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.JWTService, oidcService service.OIDCService) {
+	oidcHandler := handler.NewOIDCHandler(oidcService)
+
+	// 登录流程公开访问
+	publicGroup := rg.Group("/auth")
+	{
+		publicGroup.GET("/providers", oidcHandler.Providers)
+		publicGroup.GET("/oidc/login", oidcHandler.Login)
+		publicGroup.GET("/oidc/callback", oidcHandler.Callback)
+	}
+
+	// 认证设置需要 admin 范围
+	settingsGroup := rg.Group("/auth/settings")
+	settingsGroup.Use(func(c *gin.Context) {
+		c.Set("db", db)
+	})
+	settingsGroup.Use(middleware.Auth(jwtService))
+	settingsGroup.Use(middleware.RequireScope(model.APIKeyScopeAdmin))
+	{
+		settingsGroup.GET("", oidcHandler.GetSettings)
+		settingsGroup.PUT("", oidcHandler.UpdateSettings)
+	}
+}
+
+// RegisterLDAPRoutes 注册 LDAP 登录和目录同步路由，登录按 authRule 限流
+func RegisterLDAPRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.JWTService, ldapService service.LDAPService, limiter ratelimit.Limiter, authRule ratelimit.Rule) {
+	ldapHandler := handler.NewLDAPHandler(ldapService)
+
+	// 登录公开访问，按客户端 IP 限流
+	rg.POST("/auth/ldap/login", middleware.RateLimitByIP(limiter, authRule, "ldap_login"), ldapHandler.Login)
+
+	// 目录同步需要 admin 范围
+	ldapGroup := rg.Group("/ldap")
+	ldapGroup.Use(func(c *gin.Context) {
+		c.Set("db", db)
+	})
+	ldapGroup.Use(middleware.Auth(jwtService))
+	ldapGroup.Use(middleware.RequireScope(model.APIKeyScopeAdmin))
+	{
+		ldapGroup.POST("/sync", ldapHandler.Sync)
+	}
+}
+
+// RegisterTwoFactorRoutes 注册两步验证路由，登录第二步按 authRule 限流
+func RegisterTwoFactorRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.JWTService, twoFactorService service.TwoFactorService, limiter ratelimit.Limiter, authRule ratelimit.Rule) {
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService)
+
+	// 登录第二步使用挑战令牌，公开访问；按客户端 IP 限流
+	challengeGroup := rg.Group("/auth/2fa/challenge")
+	{
+		challengeGroup.POST("/setup", middleware.RateLimitByIP(limiter, authRule, "2fa_setup"), twoFactorHandler.ChallengeSetup)
+		challengeGroup.POST("/verify", middleware.RateLimitByIP(limiter, authRule, "2fa_verify"), twoFactorHandler.ChallengeVerify)
+	}
+
+	// 绑定和关闭只允许用户本人操作，API Key / OAuth 令牌需要 admin 范围
+	twoFactorGroup := rg.Group("/auth/2fa")
+	twoFactorGroup.Use(func(c *gin.Context) {
+		c.Set("db", db)
+	})
+	twoFactorGroup.Use(middleware.Auth(jwtService))
+	twoFactorGroup.Use(middleware.RequireScope(model.APIKeyScopeAdmin))
+	{
+		twoFactorGroup.GET("", twoFactorHandler.Status)
+		twoFactorGroup.POST("/setup", twoFactorHandler.Setup)
+		twoFactorGroup.POST("/setup/confirm", twoFactorHandler.ConfirmSetup)
+		twoFactorGroup.POST("/disable", twoFactorHandler.Disable)
+		twoFactorGroup.POST("/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+	}
+}
+
+// RegisterSessionRoutes 注册登录会话管理路由
+func RegisterSessionRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.JWTService, sessionService service.SessionService) {
+	sessionHandler := handler.NewSessionHandler(sessionService)
+
+	// 会话管理只允许用户本人操作，API Key / OAuth 令牌需要 admin 范围
+	sessionGroup := rg.Group("/auth/sessions")
+	sessionGroup.Use(func(c *gin.Context) {
+		c.Set("db", db)
+	})
+	sessionGroup.Use(middleware.Auth(jwtService))
+	sessionGroup.Use(middleware.RequireScope(model.APIKeyScopeAdmin))
+	{
+		sessionGroup.GET("", sessionHandler.ListSessions)
+		sessionGroup.DELETE("", sessionHandler.RevokeOtherSessions)
+		sessionGroup.DELETE("/:id", sessionHandler.RevokeSession)
+	}
+}
+
+// RegisterPasswordResetRoutes 注册忘记密码和重置密码路由（公开访问），按 authRule 限流；
+// 忘记密码另外由 PasswordResetService 按邮箱限制发送频率
+func RegisterPasswordResetRoutes(rg *gin.RouterGroup, passwordResetService service.PasswordResetService, limiter ratelimit.Limiter, authRule ratelimit.Rule) {
+	passwordResetHandler := handler.NewPasswordResetHandler(passwordResetService)
+
+	passwordGroup := rg.Group("/auth/password")
+	{
+		passwordGroup.POST("/forgot", middleware.RateLimitByIP(limiter, authRule, "password_forgot"), passwordResetHandler.ForgotPassword)
+		passwordGroup.POST("/reset", middleware.RateLimitByIP(limiter, authRule, "password_reset"), passwordResetHandler.ResetPassword)
+	}
+}
+
+// RegisterInvitationRoutes 注册工作区邀请路由
+func RegisterInvitationRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.JWTService, invitationService service.InvitationService) {
+	invitationHandler := handler.NewInvitationHandler(invitationService)
+
+	// 接受邀请页面使用邀请令牌查询，公开访问
+	rg.GET("/auth/invitations/:token", invitationHandler.GetInvitation)
+
+	// 已有账号的用户登录后接受邀请
+	acceptGroup := rg.Group("/auth/invitations")
+	acceptGroup.Use(func(c *gin.Context) {
+		c.Set("db", db)
+	})
+	acceptGroup.Use(middleware.Auth(jwtService))
+	{
+		acceptGroup.POST("/:token/accept", invitationHandler.AcceptInvitation)
+	}
+
+	// 邀请可以授予管理员角色，API Key / OAuth 令牌需要 admin 范围
+	invitationGroup := rg.Group("/invitations")
+	invitationGroup.Use(func(c *gin.Context) {
+		c.Set("db", db)
+	})
+	invitationGroup.Use(middleware.Auth(jwtService))
+	invitationGroup.Use(middleware.RequireScope(model.APIKeyScopeAdmin))
+	{
+		invitationGroup.GET("", invitationHandler.ListInvitations)
+		invitationGroup.POST("", invitationHandler.CreateInvitation)
+		invitationGroup.POST("/:id/resend", invitationHandler.ResendInvitation)
+		invitationGroup.DELETE("/:id", invitationHandler.RevokeInvitation)
+	}
+}
+
+// RegisterAuditLogRoutes 注册审计日志路由
+func RegisterAuditLogRoutes(rg *gin.RouterGroup, db *gorm.DB, jwtService service.JWTService, auditService service.AuditService) {
+	auditLogHandler := handler.NewAuditLogHandler(auditService)
+
+	// 审计日志包含登录 IP 等敏感信息，API Key / OAuth 令牌需要 admin 范围
+	auditGroup := rg.Group("/audit-logs")
+	auditGroup.Use(func(c *gin.Context) {
+		c.Set("db", db)
+	})
+	auditGroup.Use(middleware.Auth(jwtService))
+	auditGroup.Use(middleware.RequireScope(model.APIKeyScopeAdmin))
+	{
+		auditGroup.GET("", auditLogHandler.ListAuditLogs)
+		auditGroup.GET("/export", auditLogHandler.ExportAuditLogs)
 	}
 }
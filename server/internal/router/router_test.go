@@ -70,7 +70,7 @@ func TestWorkspaceRoutes(t *testing.T) {
 	// 设置服务和处理器
 	workspaceStore := store.NewWorkspaceStore(tx)
 	userStore := store.NewUserStore(tx)
-	workspaceService := service.NewWorkspaceService(workspaceStore, userStore)
+	workspaceService := service.NewWorkspaceService(workspaceStore, userStore, store.NewWorkspaceMemberStore(tx), nil)
 	workspaceHandler := handler.NewWorkspaceHandler(workspaceService)
 
 	// 生成 token
@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/liwei0526vip/mylinear/internal/model"
 	"github.com/liwei0526vip/mylinear/internal/store"
+	"github.com/liwei0526vip/mylinear/internal/tracing"
 )
 
 // ActivityService 定义活动业务逻辑接口
@@ -31,11 +32,17 @@ func NewActivityService(activityStore store.ActivityStore) ActivityService {
 
 // RecordActivity 记录活动
 func (s *activityService) RecordActivity(ctx context.Context, activity *model.Activity) error {
+	ctx, span := tracing.Start(ctx, "ActivityService.RecordActivity")
+	defer span.End()
+
 	return s.activityStore.CreateActivity(ctx, activity)
 }
 
 // GetIssueActivities 获取 Issue 的活动列表
 func (s *activityService) GetIssueActivities(ctx context.Context, issueID uuid.UUID, page, pageSize int, types []model.ActivityType) ([]model.Activity, int64, error) {
+	ctx, span := tracing.Start(ctx, "ActivityService.GetIssueActivities")
+	defer span.End()
+
 	if page <= 0 {
 		page = 1
 	}
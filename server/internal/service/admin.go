@@ -0,0 +1,166 @@
+// Package service 提供业务逻辑层
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// defaultAdminWorkspaceSlug create-admin 未指定工作区时使用的工作区标识
+const defaultAdminWorkspaceSlug = "default"
+
+// CreateAdminParams 创建全局管理员参数
+type CreateAdminParams struct {
+	Email    string
+	Username string
+	Name     string
+	Password string
+	// WorkspaceSlug 新用户加入的工作区，不存在时自动创建；为空时使用 default
+	WorkspaceSlug string
+}
+
+// AdminService 定义运维命令（create-admin 等）使用的管理服务接口
+// 只在命令行中使用，不经过 HTTP 认证，因此不做权限检查
+type AdminService interface {
+	// CreateGlobalAdmin 创建全局管理员；邮箱已注册时将该用户提升为全局管理员，created 为 false
+	CreateGlobalAdmin(ctx context.Context, params *CreateAdminParams) (user *model.User, created bool, err error)
+}
+
+// adminService 实现 AdminService 接口
+type adminService struct {
+	userStore            store.UserStore
+	workspaceStore       store.WorkspaceStore
+	workspaceMemberStore store.WorkspaceMemberStore
+}
+
+// NewAdminService 创建管理服务
+func NewAdminService(userStore store.UserStore, workspaceStore store.WorkspaceStore, workspaceMemberStore store.WorkspaceMemberStore) AdminService {
+	return &adminService{
+		userStore:            userStore,
+		workspaceStore:       workspaceStore,
+		workspaceMemberStore: workspaceMemberStore,
+	}
+}
+
+// CreateGlobalAdmin 创建或提升全局管理员
+func (s *adminService) CreateGlobalAdmin(ctx context.Context, params *CreateAdminParams) (*model.User, bool, error) {
+	email := strings.TrimSpace(params.Email)
+	if !emailRegex.MatchString(email) {
+		return nil, false, fmt.Errorf("邮箱格式无效")
+	}
+
+	existing, err := s.userStore.GetUserByEmail(ctx, email)
+	if err == nil {
+		return s.promote(ctx, existing)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, fmt.Errorf("获取用户失败: %w", err)
+	}
+
+	// 新建用户
+	if !usernameRegex.MatchString(params.Username) {
+		return nil, false, fmt.Errorf("用户名格式无效，只能包含字母、数字、下划线和连字符，长度3-50")
+	}
+	if _, err := s.userStore.GetUserByUsername(ctx, params.Username); err == nil {
+		return nil, false, fmt.Errorf("用户名已被使用")
+	}
+	if err := validatePassword(params.Password); err != nil {
+		return nil, false, err
+	}
+
+	workspace, err := s.ensureWorkspace(ctx, params.WorkspaceSlug)
+	if err != nil {
+		return nil, false, err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(params.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, false, fmt.Errorf("密码哈希失败: %w", err)
+	}
+	name := params.Name
+	if name == "" {
+		name = params.Username
+	}
+	user := &model.User{
+		WorkspaceID:  workspace.ID,
+		Email:        email,
+		Username:     params.Username,
+		Name:         name,
+		PasswordHash: string(passwordHash),
+		Role:         model.RoleGlobalAdmin,
+	}
+	if err := s.userStore.CreateUser(ctx, user); err != nil {
+		return nil, false, fmt.Errorf("创建用户失败: %w", err)
+	}
+	if err := s.addWorkspaceAdmin(ctx, user); err != nil {
+		return nil, false, err
+	}
+	return user, true, nil
+}
+
+// promote 将已有用户提升为全局管理员，已停用的账号不允许提升
+func (s *adminService) promote(ctx context.Context, user *model.User) (*model.User, bool, error) {
+	if user.DeactivatedAt != nil {
+		return nil, false, fmt.Errorf("用户 %s 已停用", user.Email)
+	}
+	if user.Role != model.RoleGlobalAdmin {
+		user.Role = model.RoleGlobalAdmin
+		if err := s.userStore.UpdateUser(ctx, user); err != nil {
+			return nil, false, fmt.Errorf("更新用户角色失败: %w", err)
+		}
+	}
+	if err := s.addWorkspaceAdmin(ctx, user); err != nil {
+		return nil, false, err
+	}
+	return user, false, nil
+}
+
+// addWorkspaceAdmin 确保用户是所属工作区的成员（已是成员时不修改角色）
+func (s *adminService) addWorkspaceAdmin(ctx context.Context, user *model.User) error {
+	member := &model.WorkspaceMember{
+		WorkspaceID: user.WorkspaceID,
+		UserID:      user.ID,
+		Role:        model.RoleAdmin,
+		JoinedAt:    time.Now(),
+	}
+	if err := s.workspaceMemberStore.Add(ctx, member); err != nil {
+		return fmt.Errorf("添加工作区成员失败: %w", err)
+	}
+	return nil
+}
+
+// ensureWorkspace 获取工作区，不存在时创建
+func (s *adminService) ensureWorkspace(ctx context.Context, slug string) (*model.Workspace, error) {
+	if slug == "" {
+		slug = defaultAdminWorkspaceSlug
+	}
+	if !workspaceSlugRegex.MatchString(slug) {
+		return nil, fmt.Errorf("无效的工作区标识: 只能包含小写字母、数字和连字符，最长 50 个字符")
+	}
+
+	workspace, err := s.workspaceStore.GetBySlug(ctx, slug)
+	if err == nil {
+		return workspace, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("获取工作区失败: %w", err)
+	}
+
+	name := "MyLinear Workspace"
+	if slug != defaultAdminWorkspaceSlug {
+		name = slug
+	}
+	workspace = &model.Workspace{Name: name, Slug: slug}
+	if err := s.workspaceStore.Create(ctx, workspace); err != nil {
+		return nil, fmt.Errorf("创建工作区失败: %w", err)
+	}
+	return workspace, nil
+}
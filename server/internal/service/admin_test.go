@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+)
+
+func TestAdminService_CreateGlobalAdmin(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	ctx := context.Background()
+	userStore := store.NewUserStore(tx)
+	workspaceMemberStore := store.NewWorkspaceMemberStore(tx)
+	adminService := NewAdminService(userStore, store.NewWorkspaceStore(tx), workspaceMemberStore)
+
+	suffix := uuid.NewString()[:8]
+	params := &CreateAdminParams{
+		Email:         "admin-" + suffix + "@example.com",
+		Username:      "admin-" + suffix,
+		Password:      "Password123",
+		WorkspaceSlug: "ops-" + suffix,
+	}
+
+	// 弱密码不能创建
+	weak := *params
+	weak.Password = "123"
+	if _, _, err := adminService.CreateGlobalAdmin(ctx, &weak); err == nil {
+		t.Error("弱密码应返回错误")
+	}
+
+	user, created, err := adminService.CreateGlobalAdmin(ctx, params)
+	if err != nil {
+		t.Fatalf("CreateGlobalAdmin() error = %v", err)
+	}
+	if !created || user.Role != model.RoleGlobalAdmin || user.Name != params.Username {
+		t.Errorf("created = %v, user = %+v", created, user)
+	}
+	member, err := workspaceMemberStore.Get(ctx, user.WorkspaceID, user.ID)
+	if err != nil || member.Role != model.RoleAdmin {
+		t.Errorf("工作区成员 = %+v, err = %v", member, err)
+	}
+
+	// 已有用户提升为全局管理员
+	user.Role = model.RoleMember
+	if err := userStore.UpdateUser(ctx, user); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	promoted, created, err := adminService.CreateGlobalAdmin(ctx, &CreateAdminParams{Email: params.Email})
+	if err != nil {
+		t.Fatalf("CreateGlobalAdmin() error = %v", err)
+	}
+	if created || promoted.ID != user.ID || promoted.Role != model.RoleGlobalAdmin {
+		t.Errorf("created = %v, user = %+v", created, promoted)
+	}
+}
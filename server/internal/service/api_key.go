@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+)
+
+// apiKeySecretBytes API Key 随机部分的字节数
+const apiKeySecretBytes = 32
+
+// CreateAPIKeyParams 创建 API Key 参数
+type CreateAPIKeyParams struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+// CreatedAPIKey 新建的 API Key，Key 为明文，只在创建时返回一次
+type CreatedAPIKey struct {
+	*model.APIKey
+	Key string `json:"key"`
+}
+
+// APIKeyService 定义个人 API Key 服务接口
+type APIKeyService interface {
+	// CreateAPIKey 为当前用户创建 API Key
+	CreateAPIKey(ctx context.Context, params *CreateAPIKeyParams) (*CreatedAPIKey, error)
+	// ListAPIKeys 获取当前用户的 API Key（不含明文）
+	ListAPIKeys(ctx context.Context) ([]model.APIKey, error)
+	// RevokeAPIKey 吊销当前用户的 API Key
+	RevokeAPIKey(ctx context.Context, id string) error
+}
+
+// apiKeyService 实现 APIKeyService 接口
+type apiKeyService struct {
+	apiKeyStore store.APIKeyStore
+}
+
+// NewAPIKeyService 创建 API Key 服务实例
+func NewAPIKeyService(apiKeyStore store.APIKeyStore) APIKeyService {
+	return &apiKeyService{apiKeyStore: apiKeyStore}
+}
+
+// CreateAPIKey 为当前用户创建 API Key
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, params *CreateAPIKeyParams) (*CreatedAPIKey, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+	userRole, _ := ctx.Value("user_role").(model.Role)
+
+	name := strings.TrimSpace(params.Name)
+	if name == "" || len(name) > 100 {
+		return nil, fmt.Errorf("无效的名称: 长度必须为 1-100 个字符")
+	}
+
+	scopes, err := normalizeAPIKeyScopes(params.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	// admin 范围只授予管理员，Key 的权限不能超过用户本身
+	if model.ScopesAllow(scopes, model.APIKeyScopeAdmin) &&
+		userRole != model.RoleAdmin && userRole != model.RoleGlobalAdmin {
+		return nil, fmt.Errorf("无权限创建 admin 范围的 API Key")
+	}
+
+	if params.ExpiresAt != nil && !params.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("无效的过期时间: 必须晚于当前时间")
+	}
+
+	rawKey, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key := &model.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    rawKey[:model.APIKeyDisplayLength],
+		KeyHash:   model.HashAPIKey(rawKey),
+		Scopes:    scopes,
+		ExpiresAt: params.ExpiresAt,
+	}
+	if err := s.apiKeyStore.Create(ctx, key); err != nil {
+		return nil, err
+	}
+	return &CreatedAPIKey{APIKey: key, Key: rawKey}, nil
+}
+
+// ListAPIKeys 获取当前用户的 API Key（不含明文）
+func (s *apiKeyService) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+	return s.apiKeyStore.ListByUser(ctx, userID)
+}
+
+// RevokeAPIKey 吊销当前用户的 API Key
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, id string) error {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return fmt.Errorf("未认证")
+	}
+
+	keyID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("无效的 API Key ID")
+	}
+	key, err := s.apiKeyStore.GetByID(ctx, keyID)
+	// 不暴露其他用户的 Key 是否存在
+	if err != nil || key.UserID != userID {
+		return fmt.Errorf("API Key 不存在")
+	}
+	return s.apiKeyStore.Revoke(ctx, keyID)
+}
+
+// normalizeAPIKeyScopes 校验并去重权限范围
+func normalizeAPIKeyScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("无效的权限范围: 至少需要一个")
+	}
+	result := make([]string, 0, len(scopes))
+	seen := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !model.IsValidAPIKeyScope(scope) {
+			return nil, fmt.Errorf("无效的权限范围: %s", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			result = append(result, scope)
+		}
+	}
+	return result, nil
+}
+
+// generateAPIKey 生成 API Key 明文：mlk_ + 32 字节随机数的 base64url 编码
+func generateAPIKey() (string, error) {
+	token, err := randomURLToken(apiKeySecretBytes)
+	if err != nil {
+		return "", fmt.Errorf("生成 API Key 失败: %w", err)
+	}
+	return model.APIKeyPrefix + token, nil
+}
+
+// randomURLToken 生成指定字节数的随机数并做 base64url 编码（无填充）
+func randomURLToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+)
+
+// TestNormalizeAPIKeyScopes 测试权限范围校验与去重
+func TestNormalizeAPIKeyScopes(t *testing.T) {
+	scopes, err := normalizeAPIKeyScopes([]string{"Read", "write", "read"})
+	if err != nil {
+		t.Fatalf("normalizeAPIKeyScopes() error = %v", err)
+	}
+	if len(scopes) != 2 || scopes[0] != model.APIKeyScopeRead || scopes[1] != model.APIKeyScopeWrite {
+		t.Errorf("scopes = %v", scopes)
+	}
+
+	if _, err := normalizeAPIKeyScopes(nil); err == nil {
+		t.Error("空范围应返回错误")
+	}
+	if _, err := normalizeAPIKeyScopes([]string{"delete"}); err == nil {
+		t.Error("未知范围应返回错误")
+	}
+}
+
+// TestGenerateAPIKey 测试 API Key 明文格式
+func TestGenerateAPIKey(t *testing.T) {
+	key, err := generateAPIKey()
+	if err != nil {
+		t.Fatalf("generateAPIKey() error = %v", err)
+	}
+	if !strings.HasPrefix(key, model.APIKeyPrefix) || len(key) != len(model.APIKeyPrefix)+43 {
+		t.Errorf("key 格式错误: %s", key)
+	}
+	other, _ := generateAPIKey()
+	if key == other {
+		t.Error("两次生成的 Key 不应相同")
+	}
+}
+
+func TestAPIKeyService(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupIssueServiceFixtures(t, tx)
+	apiKeyStore := store.NewAPIKeyStore(tx)
+	svc := NewAPIKeyService(apiKeyStore)
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+	created, err := svc.CreateAPIKey(f.ctx, &CreateAPIKeyParams{
+		Name:      "CI",
+		Scopes:    []string{model.APIKeyScopeWrite},
+		ExpiresAt: &expiresAt,
+	})
+	if err != nil {
+		t.Fatalf("CreateAPIKey() error = %v", err)
+	}
+	if created.Key == "" || created.KeyHash != model.HashAPIKey(created.Key) {
+		t.Error("应返回明文 Key 并保存其摘要")
+	}
+	if !strings.HasPrefix(created.Key, created.Prefix) {
+		t.Errorf("Prefix %s 应为明文前缀", created.Prefix)
+	}
+
+	// 明文 Key 可用于认证
+	if _, err := store.AuthenticateAPIKey(context.Background(), tx, created.Key); err != nil {
+		t.Errorf("AuthenticateAPIKey() error = %v", err)
+	}
+
+	keys, err := svc.ListAPIKeys(f.ctx)
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("ListAPIKeys() = %d, err = %v", len(keys), err)
+	}
+
+	// 普通成员不能创建 admin 范围的 Key
+	memberCtx := context.WithValue(context.Background(), "user_id", f.user2ID)
+	memberCtx = context.WithValue(memberCtx, "user_role", model.RoleMember)
+	if _, err := svc.CreateAPIKey(memberCtx, &CreateAPIKeyParams{Name: "Admin", Scopes: []string{model.APIKeyScopeAdmin}}); err == nil {
+		t.Error("普通成员创建 admin Key 应返回错误")
+	}
+
+	// 过期时间必须晚于当前时间
+	past := time.Now().Add(-time.Minute)
+	if _, err := svc.CreateAPIKey(f.ctx, &CreateAPIKeyParams{Name: "Old", Scopes: []string{model.APIKeyScopeRead}, ExpiresAt: &past}); err == nil {
+		t.Error("过期时间早于当前时间应返回错误")
+	}
+
+	// 不能吊销其他用户的 Key
+	if err := svc.RevokeAPIKey(memberCtx, created.ID.String()); err == nil {
+		t.Error("吊销其他用户的 Key 应返回错误")
+	}
+
+	if err := svc.RevokeAPIKey(f.ctx, created.ID.String()); err != nil {
+		t.Fatalf("RevokeAPIKey() error = %v", err)
+	}
+	if _, err := store.AuthenticateAPIKey(context.Background(), tx, created.Key); err == nil {
+		t.Error("吊销后的 Key 不应通过认证")
+	}
+}
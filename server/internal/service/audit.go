@@ -0,0 +1,269 @@
+// Package service 提供业务逻辑层
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+)
+
+const (
+	// auditExportBatchSize 导出审计日志时每批读取的数量
+	auditExportBatchSize = 500
+	// auditRetentionInterval 清理过期审计日志的间隔
+	auditRetentionInterval = time.Hour
+)
+
+// AuditEntry 待记录的审计事件
+type AuditEntry struct {
+	// WorkspaceID 事件所属工作区，为空时使用当前所在的工作区
+	WorkspaceID uuid.UUID
+	// ActorID 操作者，为空时使用当前用户（登录等未认证请求需要显式指定）
+	ActorID    uuid.UUID
+	Action     model.AuditAction
+	TargetType string
+	TargetID   string
+	// Before / After 变更前后的值，会序列化为 JSON
+	Before interface{}
+	After  interface{}
+}
+
+// AuditLogQuery 审计日志查询条件
+type AuditLogQuery struct {
+	ActorID    *uuid.UUID          `json:"actor_id,omitempty"`
+	Actions    []model.AuditAction `json:"actions,omitempty"`
+	TargetType string              `json:"target_type,omitempty"`
+	TargetID   string              `json:"target_id,omitempty"`
+	Since      *time.Time          `json:"since,omitempty"`
+	Until      *time.Time          `json:"until,omitempty"`
+}
+
+// AuditService 定义审计日志服务接口
+type AuditService interface {
+	// Record 记录审计事件，IP 和 User-Agent 从 ctx 中读取；记录失败只输出日志，不影响业务操作
+	Record(ctx context.Context, entry *AuditEntry)
+	// ListAuditLogs 查询当前工作区的审计日志（仅管理员）
+	ListAuditLogs(ctx context.Context, query *AuditLogQuery, page, pageSize int) ([]model.AuditLog, int64, error)
+	// ExportAuditLogs 将当前工作区符合条件的审计日志以 NDJSON 格式写入 w（仅管理员）
+	ExportAuditLogs(ctx context.Context, query *AuditLogQuery, w io.Writer) error
+	// PurgeExpired 删除超过保留时长的审计日志，返回删除数量
+	PurgeExpired(ctx context.Context) (int64, error)
+	// StartRetention 定期清理超过保留时长的审计日志，直到 ctx 结束
+	StartRetention(ctx context.Context)
+}
+
+// auditService 实现 AuditService 接口
+type auditService struct {
+	auditLogStore store.AuditLogStore
+	userStore     store.UserStore
+	cfg           *config.Config
+}
+
+// NewAuditService 创建审计日志服务实例
+func NewAuditService(auditLogStore store.AuditLogStore, userStore store.UserStore, cfg *config.Config) AuditService {
+	return &auditService{
+		auditLogStore: auditLogStore,
+		userStore:     userStore,
+		cfg:           cfg,
+	}
+}
+
+// Record 记录审计事件
+func (s *auditService) Record(ctx context.Context, entry *AuditEntry) {
+	auditLog := &model.AuditLog{
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+	}
+	auditLog.UserAgent, auditLog.IPAddress = clientInfo(ctx)
+
+	workspaceID := entry.WorkspaceID
+	if workspaceID == uuid.Nil {
+		workspaceID, _ = ctx.Value("workspace_id").(uuid.UUID)
+	}
+	if workspaceID != uuid.Nil {
+		auditLog.WorkspaceID = &workspaceID
+	}
+
+	actorID := entry.ActorID
+	if actorID == uuid.Nil {
+		actorID, _ = ctx.Value("user_id").(uuid.UUID)
+	}
+	if actorID != uuid.Nil {
+		auditLog.ActorID = &actorID
+	}
+
+	if entry.Before != nil || entry.After != nil {
+		changes, err := json.Marshal(&model.AuditChanges{Before: entry.Before, After: entry.After})
+		if err != nil {
+			slog.WarnContext(ctx, "序列化审计日志失败", "action", entry.Action, "error", err)
+		} else {
+			auditLog.Changes = changes
+		}
+	}
+
+	// 业务操作已经完成，请求取消时也要保留审计记录
+	if err := s.auditLogStore.Create(context.WithoutCancel(ctx), auditLog); err != nil {
+		slog.ErrorContext(ctx, "记录审计日志失败", "action", entry.Action, "error", err)
+	}
+}
+
+// ListAuditLogs 查询当前工作区的审计日志
+func (s *auditService) ListAuditLogs(ctx context.Context, query *AuditLogQuery, page, pageSize int) ([]model.AuditLog, int64, error) {
+	filter, err := s.adminFilter(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 50
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	return s.auditLogStore.List(ctx, filter, page, pageSize)
+}
+
+// ExportAuditLogs 将当前工作区符合条件的审计日志以 NDJSON 格式写入 w
+func (s *auditService) ExportAuditLogs(ctx context.Context, query *AuditLogQuery, w io.Writer) error {
+	filter, err := s.adminFilter(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	// 导出审计日志本身也是一次数据导出
+	s.Record(ctx, &AuditEntry{
+		WorkspaceID: filter.WorkspaceID,
+		Action:      model.AuditDataExported,
+		TargetType:  model.AuditTargetAuditLog,
+		After:       map[string]interface{}{"format": IssueTransferFormatNDJSON, "filter": query},
+	})
+
+	encoder := json.NewEncoder(w)
+	return s.auditLogStore.FindInBatches(ctx, filter, auditExportBatchSize, func(logs []model.AuditLog) error {
+		for i := range logs {
+			if err := encoder.Encode(&logs[i]); err != nil {
+				return fmt.Errorf("写入导出数据失败: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// PurgeExpired 删除超过保留时长的审计日志
+func (s *auditService) PurgeExpired(ctx context.Context) (int64, error) {
+	if s.cfg == nil || s.cfg.AuditLogRetention <= 0 {
+		return 0, nil
+	}
+	return s.auditLogStore.DeleteBefore(ctx, time.Now().Add(-s.cfg.AuditLogRetention))
+}
+
+// StartRetention 定期清理超过保留时长的审计日志，直到 ctx 结束
+func (s *auditService) StartRetention(ctx context.Context) {
+	if s.cfg == nil || s.cfg.AuditLogRetention <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(auditRetentionInterval)
+		defer ticker.Stop()
+		for {
+			if deleted, err := s.PurgeExpired(ctx); err != nil {
+				slog.WarnContext(ctx, "清理过期审计日志失败", "error", err)
+			} else if deleted > 0 {
+				slog.InfoContext(ctx, "已清理过期审计日志", "deleted", deleted)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// adminFilter 检查当前用户是否为管理员，并构造限定在当前工作区的查询条件
+func (s *auditService) adminFilter(ctx context.Context, query *AuditLogQuery) (*store.AuditLogFilter, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+	userRole, _ := ctx.Value("user_role").(model.Role)
+	if userRole != model.RoleAdmin && userRole != model.RoleGlobalAdmin {
+		return nil, fmt.Errorf("无权限查看审计日志")
+	}
+
+	user, err := s.userStore.GetUserByID(ctx, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("用户不存在")
+	}
+
+	filter := &store.AuditLogFilter{WorkspaceID: activeWorkspaceID(ctx, user)}
+	if query != nil {
+		if query.Since != nil && query.Until != nil && !query.Since.Before(*query.Until) {
+			return nil, fmt.Errorf("无效的时间范围: since 必须早于 until")
+		}
+		filter.ActorID = query.ActorID
+		filter.Actions = query.Actions
+		filter.TargetType = query.TargetType
+		filter.TargetID = query.TargetID
+		filter.Since = query.Since
+		filter.Until = query.Until
+	}
+	return filter, nil
+}
+
+// recordAudit 在配置了审计服务时记录审计事件
+func recordAudit(ctx context.Context, auditService AuditService, entry *AuditEntry) {
+	if auditService == nil {
+		return
+	}
+	auditService.Record(ctx, entry)
+}
+
+// 审计日志中记录的登录方式
+const (
+	auditLoginPassword  = "password"
+	auditLoginTwoFactor = "two_factor"
+	auditLoginOIDC      = "oidc"
+	auditLoginLDAP      = "ldap"
+)
+
+// recordLogin 记录登录结果；user 为空表示账号不存在，err 为空表示登录成功
+func recordLogin(ctx context.Context, auditService AuditService, method, login string, user *model.User, err error) {
+	if auditService == nil {
+		return
+	}
+
+	values := map[string]interface{}{"method": method}
+	if login != "" {
+		values["login"] = login
+	}
+	entry := &AuditEntry{
+		Action:     model.AuditLoginSucceeded,
+		TargetType: model.AuditTargetUser,
+		After:      values,
+	}
+	if err != nil {
+		entry.Action = model.AuditLoginFailed
+		values["reason"] = err.Error()
+	}
+	if user != nil {
+		entry.WorkspaceID = user.WorkspaceID
+		entry.ActorID = user.ID
+		entry.TargetID = user.ID.String()
+	}
+	auditService.Record(ctx, entry)
+}
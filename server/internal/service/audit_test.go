@@ -0,0 +1,118 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+)
+
+func TestAuditService_RecordAndList(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupIssueServiceFixtures(t, tx)
+
+	userStore := store.NewUserStore(tx)
+	auditService := NewAuditService(store.NewAuditLogStore(tx), userStore, &config.Config{AuditLogRetention: time.Hour})
+	teamMemberStore := store.NewTeamMemberStore(tx)
+	teamMemberService := NewTeamMemberServiceWithAudit(teamMemberStore, userStore, store.NewTeamStore(tx), auditService)
+
+	// 修改成员角色时记录变更前后的值和客户端信息
+	ctx := context.WithValue(f.ctx, "client_ip", "10.0.0.1")
+	ctx = context.WithValue(ctx, "user_agent", "audit-test")
+	if err := teamMemberService.AddMember(ctx, f.team.ID.String(), f.user2ID.String(), model.RoleMember); err != nil {
+		t.Fatalf("AddMember() error = %v", err)
+	}
+	if err := teamMemberService.UpdateRole(ctx, f.team.ID.String(), f.user2ID.String(), model.RoleAdmin); err != nil {
+		t.Fatalf("UpdateRole() error = %v", err)
+	}
+
+	logs, total, err := auditService.ListAuditLogs(f.ctx, &AuditLogQuery{
+		Actions: []model.AuditAction{model.AuditTeamMemberRoleChanged},
+	}, 1, 10)
+	if err != nil {
+		t.Fatalf("ListAuditLogs() error = %v", err)
+	}
+	if total != 1 || len(logs) != 1 {
+		t.Fatalf("total = %d, want 1", total)
+	}
+	entry := logs[0]
+	if entry.ActorID == nil || *entry.ActorID != f.userID || entry.TargetID != f.user2ID.String() {
+		t.Errorf("entry = %+v", entry)
+	}
+	if entry.WorkspaceID == nil || *entry.WorkspaceID != f.workspaceID {
+		t.Errorf("WorkspaceID = %v, want %v", entry.WorkspaceID, f.workspaceID)
+	}
+	if entry.IPAddress != "10.0.0.1" || entry.UserAgent != "audit-test" {
+		t.Errorf("IPAddress = %q, UserAgent = %q", entry.IPAddress, entry.UserAgent)
+	}
+	var changes struct {
+		Before map[string]interface{} `json:"before"`
+		After  map[string]interface{} `json:"after"`
+	}
+	if err := json.Unmarshal(entry.Changes, &changes); err != nil {
+		t.Fatalf("解析变更内容失败: %v", err)
+	}
+	if changes.Before["role"] != string(model.RoleMember) || changes.After["role"] != string(model.RoleAdmin) {
+		t.Errorf("changes = %s", entry.Changes)
+	}
+
+	// 普通成员不能查看审计日志
+	memberCtx := context.WithValue(context.Background(), "user_id", f.user2ID)
+	memberCtx = context.WithValue(memberCtx, "user_role", model.RoleMember)
+	if _, _, err := auditService.ListAuditLogs(memberCtx, nil, 1, 10); err == nil || !strings.Contains(err.Error(), "无权限") {
+		t.Errorf("普通成员查看审计日志应返回无权限, got %v", err)
+	}
+}
+
+func TestAuditService_LoginAndExport(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupIssueServiceFixtures(t, tx)
+
+	userStore := store.NewUserStore(tx)
+	cfg := &config.Config{JWTSecret: "audit-test-secret", JWTAccessExpiry: 15 * time.Minute, JWTRefreshExpiry: time.Hour}
+	auditService := NewAuditService(store.NewAuditLogStore(tx), userStore, cfg)
+	jwtService := NewJWTService(cfg)
+	sessionService := NewSessionService(store.NewSessionStore(tx), userStore, store.NewWorkspaceMemberStore(tx), store.NewOAuthStore(tx), jwtService, cfg)
+	authService := NewAuthServiceWithAudit(userStore, store.NewWorkspaceStore(tx), jwtService, sessionService, nil, nil, cfg, auditService)
+
+	user, err := userStore.GetUserByID(context.Background(), f.userID.String())
+	if err != nil {
+		t.Fatalf("获取用户失败: %v", err)
+	}
+	if _, _, _, err := authService.Login(context.Background(), user.Email, "wrong-password"); err == nil {
+		t.Fatal("密码错误时应登录失败")
+	}
+
+	var buf bytes.Buffer
+	if err := auditService.ExportAuditLogs(f.ctx, &AuditLogQuery{ActorID: &f.userID}, &buf); err != nil {
+		t.Fatalf("ExportAuditLogs() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("导出 %d 行, want 2: %s", len(lines), buf.String())
+	}
+
+	// 按时间顺序导出：先是登录失败，然后是本次导出
+	var first, second model.AuditLog
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("解析导出数据失败: %v", err)
+	}
+	_ = json.Unmarshal([]byte(lines[1]), &second)
+	if first.Action != model.AuditLoginFailed || second.Action != model.AuditDataExported {
+		t.Errorf("actions = %s, %s", first.Action, second.Action)
+	}
+
+	// 未配置保留时长时不清理
+	purged, err := NewAuditService(store.NewAuditLogStore(tx), userStore, &config.Config{}).PurgeExpired(context.Background())
+	if err != nil || purged != 0 {
+		t.Errorf("PurgeExpired() = %d, %v", purged, err)
+	}
+}
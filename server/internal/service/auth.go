@@ -3,7 +3,10 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
 	"regexp"
 	"strings"
 	"time"
@@ -19,43 +22,80 @@ import (
 
 // 密码强度正则表达式
 var (
-	hasUpper   = regexp.MustCompile(`[A-Z]`)
-	hasLower   = regexp.MustCompile(`[a-z]`)
-	hasDigit   = regexp.MustCompile(`[0-9]`)
-	emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+	hasUpper      = regexp.MustCompile(`[A-Z]`)
+	hasLower      = regexp.MustCompile(`[a-z]`)
+	hasDigit      = regexp.MustCompile(`[0-9]`)
+	emailRegex    = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 	usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{3,50}$`)
 )
 
+// ErrUserDeactivated 用户已停用
+var ErrUserDeactivated = errors.New("账号已停用")
+
+// ErrWrongPassword 当前密码错误
+var ErrWrongPassword = errors.New("当前密码错误")
+
+// ErrLegacyRefreshToken 引入会话之前签发的刷新令牌已停用，需要重新登录
+var ErrLegacyRefreshToken = errors.New("令牌已失效，请重新登录")
+
+// AccountLockedError 同一邮箱连续登录失败次数过多，暂时禁止登录
+type AccountLockedError struct {
+	// RetryAfter 距离解除锁定的时长
+	RetryAfter time.Duration
+}
+
+// Error 实现 error 接口
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("登录失败次数过多，账号已临时锁定，请 %d 分钟后再试", int(math.Ceil(e.RetryAfter.Minutes())))
+}
+
 // AuthService 定义认证服务接口
 type AuthService interface {
-	Register(ctx context.Context, workspaceID uuid.UUID, email, username, password, name string) (*model.User, string, string, error)
+	// Register 注册新用户；inviteToken 为空时需要邮箱已被邀请或域名在工作区允许范围内
+	Register(ctx context.Context, workspaceID uuid.UUID, email, username, password, name, inviteToken string) (*model.User, string, string, error)
 	Login(ctx context.Context, email, password string) (*model.User, string, string, error)
 	RefreshToken(ctx context.Context, refreshToken string) (string, string, error)
 	Logout(ctx context.Context, refreshToken string) error
+	// ChangePassword 修改当前用户的密码并吊销所有会话和 OAuth 授权，返回新会话的访问令牌和刷新令牌
+	ChangePassword(ctx context.Context, currentPassword, newPassword string) (string, string, error)
 }
 
 // authService 实现 AuthService 接口
 type authService struct {
-	userStore      store.UserStore
-	workspaceStore store.WorkspaceStore
-	jwtService     JWTService
-	redis          *redis.Client
-	cfg            *config.Config
+	userStore         store.UserStore
+	workspaceStore    store.WorkspaceStore
+	jwtService        JWTService
+	sessionService    SessionService
+	invitationService InvitationService
+	redis             *redis.Client
+	cfg               *config.Config
+	auditService      AuditService
+	lockout           loginLockout
 }
 
 // NewAuthService 创建认证服务实例
-func NewAuthService(userStore store.UserStore, workspaceStore store.WorkspaceStore, jwtService JWTService, redis *redis.Client, cfg *config.Config) AuthService {
+func NewAuthService(userStore store.UserStore, workspaceStore store.WorkspaceStore, jwtService JWTService, sessionService SessionService, invitationService InvitationService, redis *redis.Client, cfg *config.Config) AuthService {
 	return &authService{
-		userStore:      userStore,
-		workspaceStore: workspaceStore,
-		jwtService:     jwtService,
-		redis:          redis,
-		cfg:            cfg,
+		userStore:         userStore,
+		workspaceStore:    workspaceStore,
+		jwtService:        jwtService,
+		sessionService:    sessionService,
+		invitationService: invitationService,
+		redis:             redis,
+		cfg:               cfg,
+		lockout:           loginLockout{redis: redis, cfg: cfg},
 	}
 }
 
+// NewAuthServiceWithAudit 创建记录登录和令牌刷新审计日志的认证服务实例
+func NewAuthServiceWithAudit(userStore store.UserStore, workspaceStore store.WorkspaceStore, jwtService JWTService, sessionService SessionService, invitationService InvitationService, redis *redis.Client, cfg *config.Config, auditService AuditService) AuthService {
+	s := NewAuthService(userStore, workspaceStore, jwtService, sessionService, invitationService, redis, cfg).(*authService)
+	s.auditService = auditService
+	return s
+}
+
 // Register 注册新用户
-func (s *authService) Register(ctx context.Context, workspaceID uuid.UUID, email, username, password, name string) (*model.User, string, string, error) {
+func (s *authService) Register(ctx context.Context, workspaceID uuid.UUID, email, username, password, name, inviteToken string) (*model.User, string, string, error) {
 	// 验证邮箱格式
 	if !emailRegex.MatchString(email) {
 		return nil, "", "", fmt.Errorf("邮箱格式无效")
@@ -67,7 +107,7 @@ func (s *authService) Register(ctx context.Context, workspaceID uuid.UUID, email
 	}
 
 	// 验证密码强度
-	if err := s.validatePassword(password); err != nil {
+	if err := validatePassword(password); err != nil {
 		return nil, "", "", err
 	}
 
@@ -83,9 +123,26 @@ func (s *authService) Register(ctx context.Context, workspaceID uuid.UUID, email
 		return nil, "", "", fmt.Errorf("用户名已被使用")
 	}
 
+	// 检查是否被邀请，确定加入的工作区和角色
+	grant, err := s.invitationService.AuthorizeRegistration(ctx, workspaceID, email, inviteToken)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	// 工作区禁用密码登录时不能自助注册密码账号，只能通过单点登录加入
+	settings, err := workspaceAuthSettings(ctx, s.workspaceStore, grant.WorkspaceID)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if settings.PasswordLoginDisabled {
+		return nil, "", "", ErrPasswordLoginDisabled
+	}
+
 	// 确保 workspace 存在（私有部署：首次注册时自动创建）
-	if err := s.ensureWorkspaceExists(ctx, workspaceID); err != nil {
-		return nil, "", "", fmt.Errorf("确保工作区存在失败: %w", err)
+	if grant.Bootstrap {
+		if err := s.ensureWorkspaceExists(ctx, grant.WorkspaceID); err != nil {
+			return nil, "", "", fmt.Errorf("确保工作区存在失败: %w", err)
+		}
 	}
 
 	// 哈希密码
@@ -96,29 +153,28 @@ func (s *authService) Register(ctx context.Context, workspaceID uuid.UUID, email
 
 	// 创建用户
 	user := &model.User{
-		WorkspaceID:  workspaceID,
+		WorkspaceID:  grant.WorkspaceID,
 		Email:        email,
 		Username:     username,
 		Name:         name,
 		PasswordHash: string(passwordHash),
-		Role:         model.RoleAdmin, // 私有部署，注册用户默认为管理员
+		Role:         grant.Role,
 	}
 
 	if err := s.userStore.CreateUser(ctx, user); err != nil {
 		return nil, "", "", fmt.Errorf("创建用户失败: %w", err)
 	}
-
-	// 生成令牌
-	accessToken, err := s.jwtService.GenerateAccessToken(user.ID, user.Email, user.Role)
-	if err != nil {
-		return nil, "", "", fmt.Errorf("生成访问令牌失败: %w", err)
+	if err := s.invitationService.CompleteRegistration(ctx, grant, user); err != nil {
+		return nil, "", "", err
 	}
 
-	refreshToken, err := s.jwtService.GenerateRefreshToken(user.ID)
+	// 创建会话并生成令牌
+	accessToken, refreshToken, err := s.sessionService.Create(ctx, user)
 	if err != nil {
-		return nil, "", "", fmt.Errorf("生成刷新令牌失败: %w", err)
+		return nil, "", "", err
 	}
 
+	recordLogin(ctx, s.auditService, auditLoginPassword, email, user, nil)
 	return user, accessToken, refreshToken, nil
 }
 
@@ -127,23 +183,58 @@ func (s *authService) Login(ctx context.Context, email, password string) (*model
 	// 查找用户
 	user, err := s.userStore.GetUserByEmail(ctx, email)
 	if err != nil {
-		return nil, "", "", fmt.Errorf("邮箱或密码错误")
+		user = nil
+	}
+
+	// 锁定期间不再校验密码
+	if err := s.lockout.check(ctx, email); err != nil {
+		recordLogin(ctx, s.auditService, auditLoginPassword, email, user, err)
+		return nil, "", "", err
+	}
+
+	if user == nil {
+		// 不存在的邮箱同样计入失败次数，避免通过锁定行为判断邮箱是否已注册
+		s.lockout.recordFailure(ctx, email)
+		err = fmt.Errorf("邮箱或密码错误")
+		recordLogin(ctx, s.auditService, auditLoginPassword, email, nil, err)
+		return nil, "", "", err
 	}
 
 	// 验证密码
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return nil, "", "", fmt.Errorf("邮箱或密码错误")
+		s.lockout.recordFailure(ctx, email)
+		err = fmt.Errorf("邮箱或密码错误")
+		recordLogin(ctx, s.auditService, auditLoginPassword, email, user, err)
+		return nil, "", "", err
 	}
+	s.lockout.reset(ctx, email)
 
-	// 生成令牌
-	accessToken, err := s.jwtService.GenerateAccessToken(user.ID, user.Email, user.Role)
-	if err != nil {
-		return nil, "", "", fmt.Errorf("生成访问令牌失败: %w", err)
+	if !user.IsActive() {
+		recordLogin(ctx, s.auditService, auditLoginPassword, email, user, ErrUserDeactivated)
+		return nil, "", "", ErrUserDeactivated
+	}
+
+	// 工作区启用单点登录并禁用密码登录时，只有全局管理员可以使用密码登录（避免 IdP 故障时无法管理）
+	if !user.IsGlobalAdmin() {
+		settings, err := workspaceAuthSettings(ctx, s.workspaceStore, user.WorkspaceID)
+		if err != nil {
+			return nil, "", "", err
+		}
+		if settings.PasswordLoginDisabled {
+			recordLogin(ctx, s.auditService, auditLoginPassword, email, user, ErrPasswordLoginDisabled)
+			return nil, "", "", ErrPasswordLoginDisabled
+		}
+	}
+
+	// 启用两步验证时先签发挑战令牌，验证通过后才签发访问令牌（登录成功在两步验证完成时记录）
+	if err := requireTwoFactor(ctx, s.workspaceStore, s.jwtService, user); err != nil {
+		return nil, "", "", err
 	}
 
-	refreshToken, err := s.jwtService.GenerateRefreshToken(user.ID)
+	// 创建会话并生成令牌
+	accessToken, refreshToken, err := s.sessionService.Create(ctx, user)
 	if err != nil {
-		return nil, "", "", fmt.Errorf("生成刷新令牌失败: %w", err)
+		return nil, "", "", err
 	}
 
 	return user, accessToken, refreshToken, nil
@@ -162,46 +253,42 @@ func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (st
 		return "", "", fmt.Errorf("无效的令牌类型，请使用刷新令牌")
 	}
 
-	// 检查令牌是否在黑名单中
-	isBlacklisted, err := s.isTokenBlacklisted(ctx, claims.JTI)
-	if err != nil {
-		return "", "", fmt.Errorf("检查令牌状态失败: %w", err)
-	}
-	if isBlacklisted {
-		return "", "", fmt.Errorf("令牌已失效")
-	}
-
-	// 将旧令牌加入黑名单
-	if err := s.blacklistToken(ctx, claims.JTI, s.cfg.JWTRefreshExpiry); err != nil {
-		return "", "", fmt.Errorf("令牌失效处理失败: %w", err)
+	// 引入会话之前签发的刷新令牌不绑定会话，无法随修改密码、吊销会话失效，不再接受
+	if claims.SessionID == "" {
+		return "", "", ErrLegacyRefreshToken
 	}
 
-	// 获取用户信息
-	userID, err := uuid.Parse(claims.UserID)
+	accessToken, newRefreshToken, err := s.sessionService.Refresh(ctx, claims)
 	if err != nil {
-		return "", "", fmt.Errorf("无效的用户ID")
+		return "", "", err
 	}
+	s.recordRefresh(ctx, claims)
+	return accessToken, newRefreshToken, nil
+}
 
-	user, err := s.userStore.GetUserByID(ctx, userID.String())
-	if err != nil {
-		return "", "", fmt.Errorf("用户不存在")
+// recordRefresh 记录令牌刷新的审计日志
+func (s *authService) recordRefresh(ctx context.Context, claims *TokenClaims) {
+	if s.auditService == nil {
+		return
 	}
-
-	// 生成新令牌
-	newAccessToken, err := s.jwtService.GenerateAccessToken(user.ID, user.Email, user.Role)
+	user, err := s.userStore.GetUserByID(ctx, claims.UserID)
 	if err != nil {
-		return "", "", fmt.Errorf("生成访问令牌失败: %w", err)
+		return
 	}
-
-	newRefreshToken, err := s.jwtService.GenerateRefreshToken(user.ID)
-	if err != nil {
-		return "", "", fmt.Errorf("生成刷新令牌失败: %w", err)
+	entry := &AuditEntry{
+		WorkspaceID: user.WorkspaceID,
+		ActorID:     user.ID,
+		Action:      model.AuditTokenRefreshed,
+		TargetType:  model.AuditTargetSession,
+		TargetID:    claims.SessionID,
 	}
-
-	return newAccessToken, newRefreshToken, nil
+	if workspaceID, err := uuid.Parse(claims.WorkspaceID); err == nil {
+		entry.WorkspaceID = workspaceID
+	}
+	s.auditService.Record(ctx, entry)
 }
 
-// Logout 用户登出
+// Logout 用户登出，吊销刷新令牌所属的会话
 func (s *authService) Logout(ctx context.Context, refreshToken string) error {
 	// 验证令牌
 	claims, err := s.jwtService.ValidateToken(refreshToken)
@@ -209,12 +296,46 @@ func (s *authService) Logout(ctx context.Context, refreshToken string) error {
 		return fmt.Errorf("令牌验证失败: %w", err)
 	}
 
-	// 将令牌加入黑名单
-	if err := s.blacklistToken(ctx, claims.JTI, s.cfg.JWTRefreshExpiry); err != nil {
-		return fmt.Errorf("令牌失效处理失败: %w", err)
+	// 旧版本签发的令牌已无法用于刷新，无需处理
+	if claims.SessionID == "" {
+		return nil
 	}
+	return s.sessionService.Logout(ctx, claims)
+}
 
-	return nil
+// ChangePassword 修改当前用户的密码并吊销所有会话和 OAuth 授权，返回新会话的访问令牌和刷新令牌
+func (s *authService) ChangePassword(ctx context.Context, currentPassword, newPassword string) (string, string, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return "", "", fmt.Errorf("未认证")
+	}
+	user, err := s.userStore.GetUserByID(ctx, userID.String())
+	if err != nil {
+		return "", "", fmt.Errorf("用户不存在")
+	}
+
+	// 通过 OIDC / LDAP 创建的用户没有本地密码，CompareHashAndPassword 同样会失败
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)); err != nil {
+		return "", "", ErrWrongPassword
+	}
+	if err := validatePassword(newPassword); err != nil {
+		return "", "", err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return "", "", fmt.Errorf("密码哈希失败: %w", err)
+	}
+	user.PasswordHash = string(passwordHash)
+	if err := s.userStore.UpdateUser(ctx, user); err != nil {
+		return "", "", fmt.Errorf("更新密码失败: %w", err)
+	}
+
+	// 密码可能已泄露，吊销所有会话（包括当前会话），再为当前客户端创建新会话
+	if err := s.sessionService.RevokeAll(ctx, user.ID, model.SessionRevokeReasonPasswordChanged); err != nil {
+		return "", "", err
+	}
+	return s.sessionService.Create(ctx, user)
 }
 
 // ensureWorkspaceExists 确保工作区存在（私有部署：首次注册时自动创建）
@@ -228,12 +349,17 @@ func (s *authService) ensureWorkspaceExists(ctx context.Context, workspaceID uui
 
 	// 如果是"未找到"错误，创建新的 workspace
 	if err == gorm.ErrRecordNotFound {
+		// 已有其他工作区使用 default 时在 Slug 后附加 ID 前缀
+		slug := "default"
+		if _, err := s.workspaceStore.GetBySlug(ctx, slug); err == nil {
+			slug += "-" + workspaceID.String()[:8]
+		}
 		workspace := &model.Workspace{
 			Model: model.Model{
 				ID: workspaceID,
 			},
 			Name: "MyLinear Workspace",
-			Slug: "default",
+			Slug: slug,
 		}
 		if err := s.workspaceStore.Create(ctx, workspace); err != nil {
 			return fmt.Errorf("创建工作区失败: %w", err)
@@ -246,7 +372,7 @@ func (s *authService) ensureWorkspaceExists(ctx context.Context, workspaceID uui
 }
 
 // validatePassword 验证密码强度
-func (s *authService) validatePassword(password string) error {
+func validatePassword(password string) error {
 	if len(password) < 8 {
 		return fmt.Errorf("密码长度至少8个字符")
 	}
@@ -262,20 +388,67 @@ func (s *authService) validatePassword(password string) error {
 	return nil
 }
 
-// isTokenBlacklisted 检查令牌是否在黑名单中
-func (s *authService) isTokenBlacklisted(ctx context.Context, jti string) (bool, error) {
-	key := fmt.Sprintf("token_blacklist:%s", jti)
-	result, err := s.redis.Exists(ctx, key).Result()
+// loginLockout 连续登录失败锁定，密码、LDAP 登录和两步验证共用同一组计数（按邮箱或登录名）
+// Redis 不可用或未配置阈值时不锁定
+type loginLockout struct {
+	redis *redis.Client
+	cfg   *config.Config
+}
+
+// enabled 是否启用连续登录失败锁定
+func (l loginLockout) enabled() bool {
+	return l.redis != nil && l.cfg != nil && l.cfg.LoginLockoutThreshold > 0 && l.cfg.LoginLockoutDuration > 0
+}
+
+// check 检查邮箱是否因连续登录失败被锁定，Redis 不可用时不阻止登录
+func (l loginLockout) check(ctx context.Context, email string) error {
+	if !l.enabled() {
+		return nil
+	}
+	ttl, err := l.redis.PTTL(ctx, "login_lockout:"+NormalizeEmail(email)).Result()
+	if err != nil {
+		slog.WarnContext(ctx, "检查登录锁定状态失败", "error", err)
+		return nil
+	}
+	if ttl > 0 {
+		return &AccountLockedError{RetryAfter: ttl}
+	}
+	return nil
+}
+
+// recordFailure 累计登录失败次数，在锁定时长内达到阈值后锁定该邮箱
+func (l loginLockout) recordFailure(ctx context.Context, email string) {
+	if !l.enabled() {
+		return
+	}
+	email = NormalizeEmail(email)
+	key := "login_failures:" + email
+	count, err := l.redis.Incr(ctx, key).Result()
 	if err != nil {
-		return false, err
+		slog.WarnContext(ctx, "记录登录失败次数失败", "error", err)
+		return
+	}
+	if count == 1 {
+		l.redis.Expire(ctx, key, l.cfg.LoginLockoutDuration)
+	}
+	if count < int64(l.cfg.LoginLockoutThreshold) {
+		return
+	}
+
+	pipe := l.redis.TxPipeline()
+	pipe.Set(ctx, "login_lockout:"+email, "1", l.cfg.LoginLockoutDuration)
+	pipe.Del(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		slog.WarnContext(ctx, "锁定账号失败", "error", err)
 	}
-	return result > 0, nil
 }
 
-// blacklistToken 将令牌加入黑名单
-func (s *authService) blacklistToken(ctx context.Context, jti string, expiry time.Duration) error {
-	key := fmt.Sprintf("token_blacklist:%s", jti)
-	return s.redis.Set(ctx, key, "1", expiry).Err()
+// reset 验证通过后清除累计的失败次数
+func (l loginLockout) reset(ctx context.Context, email string) {
+	if !l.enabled() {
+		return
+	}
+	l.redis.Del(ctx, "login_failures:"+NormalizeEmail(email))
 }
 
 // NormalizeEmail 规范化邮箱地址
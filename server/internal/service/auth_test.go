@@ -2,13 +2,18 @@ package service
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/mail/mailtest"
 	"github.com/liwei0526vip/mylinear/internal/model"
 	"github.com/liwei0526vip/mylinear/internal/store"
 	"github.com/redis/go-redis/v9"
+	"gorm.io/datatypes"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -73,6 +78,9 @@ func setupAuthTest(t *testing.T) (AuthService, context.Context, func()) {
 	authTestRedis = rdb
 	authTestWorkspaceID = workspace.ID
 
+	// 允许 example.com 邮箱直接注册
+	db.Model(&workspace).Update("settings", datatypes.JSON(`{"auth":{"allowed_email_domains":["example.com"]}}`))
+
 	// 创建服务
 	cfg := &config.Config{
 		JWTSecret:        "auth-test-secret-key",
@@ -83,7 +91,9 @@ func setupAuthTest(t *testing.T) (AuthService, context.Context, func()) {
 	userStore := store.NewUserStore(db)
 	workspaceStore := store.NewWorkspaceStore(db)
 	jwtService := NewJWTService(cfg)
-	authService := NewAuthService(userStore, workspaceStore, jwtService, rdb, cfg)
+	sessionService := NewSessionService(store.NewSessionStore(db), userStore, store.NewWorkspaceMemberStore(db), store.NewOAuthStore(db), jwtService, cfg)
+	invitationService := NewInvitationService(store.NewInvitationStore(db), userStore, workspaceStore, store.NewWorkspaceMemberStore(db), store.NewTeamStore(db), store.NewTeamMemberStore(db), mailtest.NewSender(), cfg)
+	authService := NewAuthService(userStore, workspaceStore, jwtService, sessionService, invitationService, rdb, cfg)
 
 	// 返回清理函数
 	cleanup := func() {
@@ -121,7 +131,7 @@ func TestAuthService_Register_Success(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			user, accessToken, refreshToken, err := authService.Register(ctx, authTestWorkspaceID, tt.email, tt.username, tt.password, tt.fullName)
+			user, accessToken, refreshToken, err := authService.Register(ctx, authTestWorkspaceID, tt.email, tt.username, tt.password, tt.fullName, "")
 
 			if err != nil {
 				t.Errorf("Register() error = %v", err)
@@ -169,13 +179,13 @@ func TestAuthService_Register_DuplicateEmail(t *testing.T) {
 	email := prefix + "_dupemail@example.com"
 
 	// 第一次注册
-	_, _, _, err := authService.Register(ctx, authTestWorkspaceID, email, prefix+"_user1", "Password123!", "User 1")
+	_, _, _, err := authService.Register(ctx, authTestWorkspaceID, email, prefix+"_user1", "Password123!", "User 1", "")
 	if err != nil {
 		t.Fatalf("第一次注册失败: %v", err)
 	}
 
 	// 尝试用相同邮箱注册
-	_, _, _, err = authService.Register(ctx, authTestWorkspaceID, email, prefix+"_user2", "Password123!", "User 2")
+	_, _, _, err = authService.Register(ctx, authTestWorkspaceID, email, prefix+"_user2", "Password123!", "User 2", "")
 	if err == nil {
 		t.Error("Register() 应该返回错误，邮箱重复")
 	}
@@ -189,13 +199,13 @@ func TestAuthService_Register_DuplicateUsername(t *testing.T) {
 	username := prefix + "_dupuser"
 
 	// 第一次注册
-	_, _, _, err := authService.Register(ctx, authTestWorkspaceID, prefix+"_email1@example.com", username, "Password123!", "User 1")
+	_, _, _, err := authService.Register(ctx, authTestWorkspaceID, prefix+"_email1@example.com", username, "Password123!", "User 1", "")
 	if err != nil {
 		t.Fatalf("第一次注册失败: %v", err)
 	}
 
 	// 尝试用相同用户名注册
-	_, _, _, err = authService.Register(ctx, authTestWorkspaceID, prefix+"_email2@example.com", username, "Password123!", "User 2")
+	_, _, _, err = authService.Register(ctx, authTestWorkspaceID, prefix+"_email2@example.com", username, "Password123!", "User 2", "")
 	if err == nil {
 		t.Error("Register() 应该返回错误，用户名重复")
 	}
@@ -220,7 +230,7 @@ func TestAuthService_Register_WeakPassword(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, _, _, err := authService.Register(ctx, authTestWorkspaceID, prefix+tt.name+"@example.com", prefix+tt.name, tt.password, "Test User")
+			_, _, _, err := authService.Register(ctx, authTestWorkspaceID, prefix+tt.name+"@example.com", prefix+tt.name, tt.password, "Test User", "")
 			if err == nil {
 				t.Errorf("Register() 应该拒绝弱密码: %s", tt.password)
 			}
@@ -246,7 +256,7 @@ func TestAuthService_Register_InvalidEmail(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, _, _, err := authService.Register(ctx, authTestWorkspaceID, tt.email, prefix+tt.name, "Password123!", "Test User")
+			_, _, _, err := authService.Register(ctx, authTestWorkspaceID, tt.email, prefix+tt.name, "Password123!", "Test User", "")
 			if err == nil {
 				t.Errorf("Register() 应该拒绝无效邮箱: %s", tt.email)
 			}
@@ -272,7 +282,7 @@ func TestAuthService_Register_InvalidUsername(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, _, _, err := authService.Register(ctx, authTestWorkspaceID, prefix+tt.name+"@example.com", tt.username, "Password123!", "Test User")
+			_, _, _, err := authService.Register(ctx, authTestWorkspaceID, prefix+tt.name+"@example.com", tt.username, "Password123!", "Test User", "")
 			if err == nil {
 				t.Errorf("Register() 应该拒绝无效用户名: %s", tt.username)
 			}
@@ -293,7 +303,7 @@ func TestAuthService_Login_Success(t *testing.T) {
 	password := "Password123!"
 
 	// 先注册用户
-	_, _, _, err := authService.Register(ctx, authTestWorkspaceID, email, prefix+"_loginuser", password, "Login Test User")
+	_, _, _, err := authService.Register(ctx, authTestWorkspaceID, email, prefix+"_loginuser", password, "Login Test User", "")
 	if err != nil {
 		t.Fatalf("注册用户失败: %v", err)
 	}
@@ -332,7 +342,7 @@ func TestAuthService_Login_WrongPassword(t *testing.T) {
 	password := "Password123!"
 
 	// 先注册用户
-	_, _, _, err := authService.Register(ctx, authTestWorkspaceID, email, prefix+"_wrongpassuser", password, "Wrong Pass User")
+	_, _, _, err := authService.Register(ctx, authTestWorkspaceID, email, prefix+"_wrongpassuser", password, "Wrong Pass User", "")
 	if err != nil {
 		t.Fatalf("注册用户失败: %v", err)
 	}
@@ -355,6 +365,47 @@ func TestAuthService_Login_NonExistentEmail(t *testing.T) {
 	}
 }
 
+func TestAuthService_Login_Lockout(t *testing.T) {
+	svc, ctx, cleanup := setupAuthTest(t)
+	defer cleanup()
+
+	// 连续失败 3 次后锁定
+	s := svc.(*authService)
+	cfg := *s.cfg
+	cfg.LoginLockoutThreshold = 3
+	cfg.LoginLockoutDuration = time.Minute
+	s.cfg = &cfg
+
+	prefix := uuid.New().String()[:8]
+	email := prefix + "_lockout@example.com"
+	password := "Password123!"
+	if _, _, _, err := s.Register(ctx, authTestWorkspaceID, email, prefix+"_lockoutuser", password, "Lockout User", ""); err != nil {
+		t.Fatalf("注册用户失败: %v", err)
+	}
+	defer authTestRedis.Del(ctx, "login_lockout:"+email, "login_failures:"+email)
+
+	// 成功登录会清除之前的失败次数
+	_, _, _, _ = s.Login(ctx, email, "WrongPassword123!")
+	_, _, _, _ = s.Login(ctx, email, "WrongPassword123!")
+	if _, _, _, err := s.Login(ctx, email, password); err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, _, _, _ = s.Login(ctx, strings.ToUpper(email), "WrongPassword123!")
+	}
+
+	// 锁定期间密码正确也不能登录
+	_, _, _, err := s.Login(ctx, email, password)
+	var locked *AccountLockedError
+	if !errors.As(err, &locked) {
+		t.Fatalf("Login() error = %v, want AccountLockedError", err)
+	}
+	if locked.RetryAfter <= 0 || locked.RetryAfter > time.Minute {
+		t.Errorf("RetryAfter = %v", locked.RetryAfter)
+	}
+}
+
 // =============================================================================
 // RefreshToken 测试
 // =============================================================================
@@ -368,7 +419,7 @@ func TestAuthService_RefreshToken_Success(t *testing.T) {
 	password := "Password123!"
 
 	// 注册用户
-	_, _, refreshToken, err := authService.Register(ctx, authTestWorkspaceID, email, prefix+"_refreshuser", password, "Refresh Test User")
+	_, _, refreshToken, err := authService.Register(ctx, authTestWorkspaceID, email, prefix+"_refreshuser", password, "Refresh Test User", "")
 	if err != nil {
 		t.Fatalf("注册用户失败: %v", err)
 	}
@@ -413,7 +464,7 @@ func TestAuthService_RefreshToken_AccessTokenUsed(t *testing.T) {
 	password := "Password123!"
 
 	// 注册用户
-	_, accessToken, _, err := authService.Register(ctx, authTestWorkspaceID, email, prefix+"_accesstokenuser", password, "Access Token User")
+	_, accessToken, _, err := authService.Register(ctx, authTestWorkspaceID, email, prefix+"_accesstokenuser", password, "Access Token User", "")
 	if err != nil {
 		t.Fatalf("注册用户失败: %v", err)
 	}
@@ -438,7 +489,7 @@ func TestAuthService_Logout_Success(t *testing.T) {
 	password := "Password123!"
 
 	// 注册用户
-	_, _, refreshToken, err := authService.Register(ctx, authTestWorkspaceID, email, prefix+"_logoutuser", password, "Logout Test User")
+	_, _, refreshToken, err := authService.Register(ctx, authTestWorkspaceID, email, prefix+"_logoutuser", password, "Logout Test User", "")
 	if err != nil {
 		t.Fatalf("注册用户失败: %v", err)
 	}
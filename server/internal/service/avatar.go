@@ -4,14 +4,19 @@ package service
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/metrics"
+	"github.com/liwei0526vip/mylinear/internal/tracing"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // AvatarConfig 头像服务配置
@@ -28,8 +33,21 @@ type AvatarConfig struct {
 type AvatarService interface {
 	// UploadAvatar 上传头像
 	UploadAvatar(ctx context.Context, userID string, file io.Reader, filename string, contentType string) (string, error)
+	// Ping 检查对象存储是否可用（存储桶是否存在）
+	Ping(ctx context.Context) error
+	// ObjectKey 从本服务生成的 URL 中解析对象路径，不是本存储桶的 URL 时 ok 为 false
+	ObjectKey(url string) (key string, ok bool)
+	// ObjectURL 返回对象的访问 URL
+	ObjectURL(key string) string
+	// GetObject 读取对象，对象不存在时返回 ErrObjectNotFound
+	GetObject(ctx context.Context, key string) (io.ReadCloser, string, error)
+	// PutObject 写入对象（备份恢复使用，不做文件类型校验）
+	PutObject(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
 }
 
+// ErrObjectNotFound 对象存储中不存在该对象
+var ErrObjectNotFound = errors.New("对象不存在")
+
 // avatarService 头像服务实现
 type avatarService struct {
 	client    *minio.Client
@@ -50,7 +68,9 @@ func NewAvatarService(cfg *AvatarConfig) (AvatarService, error) {
 
 	// 确保存储桶存在
 	ctx := context.Background()
+	start := time.Now()
 	exists, err := client.BucketExists(ctx, cfg.BucketName)
+	metrics.ObserveStorage("bucket_exists", start, err)
 	if err != nil {
 		return nil, fmt.Errorf("检查存储桶失败: %w", err)
 	}
@@ -93,16 +113,83 @@ func (s *avatarService) UploadAvatar(ctx context.Context, userID string, file io
 	objectName := generateAvatarPath(userID, filename)
 
 	// 上传到 MinIO
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "minio.PutObject", attribute.String("storage.bucket", s.bucket), attribute.String("storage.object", objectName))
 	_, err = s.client.PutObject(ctx, s.bucket, objectName, reader, -1, minio.PutObjectOptions{
 		ContentType: contentType,
 	})
+	tracing.RecordError(span, err)
+	span.End()
+	metrics.ObserveStorage("put_object", start, err)
 	if err != nil {
 		return "", fmt.Errorf("上传文件失败: %w", err)
 	}
 
 	// 返回头像 URL
-	avatarURL := fmt.Sprintf("%s/%s/%s", s.baseURL, s.bucket, objectName)
-	return avatarURL, nil
+	return s.ObjectURL(objectName), nil
+}
+
+// ObjectKey 从 URL 中解析对象路径
+func (s *avatarService) ObjectKey(url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, s.ObjectURL(""))
+	if !ok || key == "" {
+		return "", false
+	}
+	return key, true
+}
+
+// ObjectURL 返回对象的访问 URL
+func (s *avatarService) ObjectURL(key string) string {
+	return fmt.Sprintf("%s/%s/%s", s.baseURL, s.bucket, key)
+}
+
+// GetObject 读取对象
+func (s *avatarService) GetObject(ctx context.Context, key string) (io.ReadCloser, string, error) {
+	start := time.Now()
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		metrics.ObserveStorage("get_object", start, err)
+		return nil, "", fmt.Errorf("读取对象失败: %w", err)
+	}
+	// GetObject 不会访问存储，通过 Stat 确认对象存在
+	info, err := object.Stat()
+	metrics.ObserveStorage("get_object", start, err)
+	if err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, "", ErrObjectNotFound
+		}
+		return nil, "", fmt.Errorf("读取对象失败: %w", err)
+	}
+	return object, info.ContentType, nil
+}
+
+// PutObject 写入对象
+func (s *avatarService) PutObject(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "minio.PutObject", attribute.String("storage.bucket", s.bucket), attribute.String("storage.object", key))
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{ContentType: contentType})
+	tracing.RecordError(span, err)
+	span.End()
+	metrics.ObserveStorage("put_object", start, err)
+	if err != nil {
+		return fmt.Errorf("上传文件失败: %w", err)
+	}
+	return nil
+}
+
+// Ping 检查对象存储是否可用
+func (s *avatarService) Ping(ctx context.Context) error {
+	start := time.Now()
+	exists, err := s.client.BucketExists(ctx, s.bucket)
+	metrics.ObserveStorage("bucket_exists", start, err)
+	if err != nil {
+		return fmt.Errorf("检查存储桶失败: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("存储桶 %s 不存在", s.bucket)
+	}
+	return nil
 }
 
 // validateMagicNumber 验证文件的 magic number
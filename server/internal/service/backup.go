@@ -0,0 +1,647 @@
+// Package service 提供业务逻辑层
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/backup"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/gorm"
+)
+
+// 备份范围的子查询，@workspace 为工作区 ID
+const (
+	backupTeamsSQL    = "SELECT id FROM teams WHERE workspace_id = @workspace"
+	backupIssuesSQL   = "SELECT id FROM issues WHERE team_id IN (" + backupTeamsSQL + ")"
+	backupProjectsSQL = "SELECT id FROM projects WHERE workspace_id = @workspace"
+	backupInTeams     = "team_id IN (" + backupTeamsSQL + ")"
+	backupInIssues    = "issue_id IN (" + backupIssuesSQL + ")"
+)
+
+// backupUsersSQL 工作区成员，以及工作区数据中引用到的其他用户（如已移出工作区的评论作者）
+const backupUsersSQL = "id IN (" +
+	"SELECT user_id FROM workspace_members WHERE workspace_id = @workspace" +
+	" UNION SELECT id FROM users WHERE workspace_id = @workspace" +
+	" UNION SELECT user_id FROM team_members WHERE " + backupInTeams +
+	" UNION SELECT created_by_id FROM issues WHERE " + backupInTeams +
+	" UNION SELECT assignee_id FROM issues WHERE " + backupInTeams +
+	" UNION SELECT lead_id FROM projects WHERE workspace_id = @workspace" +
+	" UNION SELECT created_by_id FROM documents WHERE workspace_id = @workspace" +
+	" UNION SELECT user_id FROM comments WHERE " + backupInIssues +
+	" UNION SELECT user_id FROM attachments WHERE " + backupInIssues +
+	" UNION SELECT actor_id FROM activities WHERE " + backupInIssues +
+	" UNION SELECT changed_by_id FROM issue_status_history WHERE " + backupInIssues +
+	" UNION SELECT user_id FROM issue_subscriptions WHERE " + backupInIssues +
+	")"
+
+// backupTable 备份的表
+type backupTable struct {
+	name string
+	// where 导出条件
+	where string
+	// parent 同表中的父记录列，恢复时先写入父记录
+	parent string
+	// urls 引用对象存储文件的列
+	urls []string
+}
+
+// backupTables 按恢复顺序（被引用的表在前）排列的备份范围
+// 会话、API Key、OAuth、邀请、通知、后台任务和审计日志属于账号或运行状态，不在备份范围内；
+// Issue 编号和搜索在查询时计算，无需额外的计数器或索引
+var backupTables = []backupTable{
+	{name: "workspaces", where: "id = @workspace", urls: []string{"logo_url"}},
+	{name: "users", where: backupUsersSQL, urls: []string{"avatar_url"}},
+	{name: "workspace_members", where: "workspace_id = @workspace"},
+	{name: "teams", where: "workspace_id = @workspace", parent: "parent_id", urls: []string{"icon_url"}},
+	{name: "team_members", where: backupInTeams},
+	{name: "team_key_aliases", where: "workspace_id = @workspace"},
+	{name: "workflow_states", where: backupInTeams},
+	{name: "workflow_transitions", where: backupInTeams},
+	{name: "labels", where: "workspace_id = @workspace", parent: "parent_id"},
+	{name: "projects", where: "workspace_id = @workspace"},
+	{name: "milestones", where: "project_id IN (" + backupProjectsSQL + ")"},
+	{name: "cycles", where: backupInTeams},
+	{name: "issues", where: backupInTeams, parent: "parent_id"},
+	{name: "issue_closure", where: "ancestor_id IN (" + backupIssuesSQL + ") AND descendant_id IN (" + backupIssuesSQL + ")"},
+	{name: "issue_relations", where: backupInIssues + " AND related_issue_id IN (" + backupIssuesSQL + ")"},
+	{name: "issue_subscriptions", where: backupInIssues},
+	{name: "issue_status_history", where: backupInIssues},
+	{name: "issue_identifier_aliases", where: "workspace_id = @workspace"},
+	{name: "issue_links", where: backupInIssues},
+	{name: "comments", where: backupInIssues, parent: "parent_id"},
+	{name: "attachments", where: backupInIssues, urls: []string{"url"}},
+	{name: "activities", where: backupInIssues},
+	{name: "documents", where: "workspace_id = @workspace"},
+	{name: "external_references", where: backupInTeams},
+}
+
+// BackupOptions 备份参数
+type BackupOptions struct {
+	// SkipObjects 只备份数据，不备份对象存储中的文件
+	SkipObjects bool
+}
+
+// RestoreOptions 恢复参数
+type RestoreOptions struct {
+	// Slug 恢复后的工作区标识，为空时使用备份中的标识
+	Slug string
+	// SkipObjects 不恢复对象存储中的文件，数据中的 URL 保持不变
+	SkipObjects bool
+}
+
+// RestoreReport 恢复结果
+type RestoreReport struct {
+	WorkspaceID uuid.UUID `json:"workspace_id"`
+	Slug        string    `json:"slug"`
+	// Rows 每张表写入的行数
+	Rows map[string]int `json:"rows"`
+	// RemappedIDs 与已有数据冲突而重新生成的 ID 数量
+	RemappedIDs int `json:"remapped_ids"`
+	// MatchedUsers 按邮箱匹配到已有用户的数量，这些用户不会重复创建
+	MatchedUsers int `json:"matched_users"`
+	// RenamedUsers 用户名冲突而改名的用户：原用户名 -> 新用户名
+	RenamedUsers map[string]string `json:"renamed_users,omitempty"`
+	Objects      int               `json:"objects"`
+}
+
+// BackupService 定义工作区备份恢复服务接口
+// 只在命令行中使用，不经过 HTTP 认证，因此不做权限检查
+type BackupService interface {
+	// Backup 将工作区的数据和文件导出为备份归档写入 w
+	Backup(ctx context.Context, slug string, w io.Writer, opts *BackupOptions) (*backup.Manifest, error)
+	// Restore 校验备份归档并恢复为新的工作区；ID 与已有数据冲突时重新生成，所有数据在一个事务中写入
+	Restore(ctx context.Context, r io.Reader, opts *RestoreOptions) (*RestoreReport, error)
+}
+
+// backupService 实现 BackupService 接口
+type backupService struct {
+	backupStore    store.BackupStore
+	workspaceStore store.WorkspaceStore
+	userStore      store.UserStore
+	// storage 对象存储，为 nil 时只能跳过文件
+	storage      AvatarService
+	auditService AuditService
+}
+
+// NewBackupService 创建备份恢复服务
+func NewBackupService(backupStore store.BackupStore, workspaceStore store.WorkspaceStore, userStore store.UserStore, storage AvatarService) BackupService {
+	return &backupService{
+		backupStore:    backupStore,
+		workspaceStore: workspaceStore,
+		userStore:      userStore,
+		storage:        storage,
+	}
+}
+
+// NewBackupServiceWithAudit 创建记录审计日志的备份恢复服务
+func NewBackupServiceWithAudit(backupStore store.BackupStore, workspaceStore store.WorkspaceStore, userStore store.UserStore, storage AvatarService, auditService AuditService) BackupService {
+	s := NewBackupService(backupStore, workspaceStore, userStore, storage).(*backupService)
+	s.auditService = auditService
+	return s
+}
+
+// Backup 导出工作区
+func (s *backupService) Backup(ctx context.Context, slug string, w io.Writer, opts *BackupOptions) (*backup.Manifest, error) {
+	if opts == nil {
+		opts = &BackupOptions{}
+	}
+	if !opts.SkipObjects && s.storage == nil {
+		return nil, errors.New("对象存储不可用，无法备份文件")
+	}
+
+	workspace, err := s.workspaceStore.GetBySlug(ctx, slug)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("工作区 %s 不存在", slug)
+	} else if err != nil {
+		return nil, fmt.Errorf("获取工作区失败: %w", err)
+	}
+
+	version, dirty, err := s.backupStore.SchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, fmt.Errorf("迁移版本 %d 处于 dirty 状态，需要修复后再备份", version)
+	}
+
+	manifest := &backup.Manifest{
+		SchemaVersion: version,
+		CreatedAt:     time.Now().UTC(),
+		Workspace:     backup.Workspace{ID: workspace.ID, Name: workspace.Name, Slug: workspace.Slug},
+	}
+	archive := backup.NewWriter(w)
+	// objects 对象路径 -> 数据中引用它的 URL
+	objects := make(map[string]string)
+	args := []interface{}{sql.Named("workspace", workspace.ID)}
+
+	err = s.backupStore.Snapshot(ctx, func(tx store.BackupStore) error {
+		for _, table := range backupTables {
+			rows := 0
+			_, err := archive.Add(backup.TablePath(table.name), func(out io.Writer) error {
+				return tx.ExportRows(ctx, table.name, table.where, args, func(row json.RawMessage) error {
+					rows++
+					if !opts.SkipObjects && len(table.urls) > 0 {
+						if err := s.collectObjects(table, row, objects); err != nil {
+							return err
+						}
+					}
+					if _, err := out.Write(row); err != nil {
+						return err
+					}
+					_, err := out.Write([]byte{'\n'})
+					return err
+				})
+			})
+			if err != nil {
+				return err
+			}
+			manifest.Tables = append(manifest.Tables, backup.Table{Name: table.name, Rows: rows})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		object, err := s.backupObject(ctx, archive, key)
+		if errors.Is(err, ErrObjectNotFound) {
+			// 数据中的 URL 指向已删除的文件，恢复后同样无法访问
+			slog.WarnContext(ctx, "备份时对象不存在，已跳过", "key", key)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		object.URL = objects[key]
+		manifest.Objects = append(manifest.Objects, *object)
+	}
+
+	if err := archive.Close(manifest); err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditService, &AuditEntry{
+		WorkspaceID: workspace.ID,
+		Action:      model.AuditDataExported,
+		TargetType:  model.AuditTargetWorkspace,
+		TargetID:    workspace.ID.String(),
+		After:       map[string]interface{}{"format": "backup", "schema_version": version, "objects": len(manifest.Objects)},
+	})
+	return manifest, nil
+}
+
+// collectObjects 记录行中引用的本存储桶文件
+func (s *backupService) collectObjects(table backupTable, row json.RawMessage, objects map[string]string) error {
+	var values map[string]interface{}
+	if err := json.Unmarshal(row, &values); err != nil {
+		return fmt.Errorf("解析 %s 数据失败: %w", table.name, err)
+	}
+	for _, column := range table.urls {
+		url, _ := values[column].(string)
+		if key, ok := s.storage.ObjectKey(url); ok {
+			objects[key] = url
+		}
+	}
+	return nil
+}
+
+// backupObject 将对象写入归档
+func (s *backupService) backupObject(ctx context.Context, archive *backup.Writer, key string) (*backup.Object, error) {
+	reader, contentType, err := s.storage.GetObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	if _, err := archive.Add(backup.ObjectPath(key), func(out io.Writer) error {
+		_, err := io.Copy(out, reader)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("备份对象 %s 失败: %w", key, err)
+	}
+	return &backup.Object{Key: key, ContentType: contentType}, nil
+}
+
+// Restore 恢复工作区
+func (s *backupService) Restore(ctx context.Context, r io.Reader, opts *RestoreOptions) (*RestoreReport, error) {
+	if opts == nil {
+		opts = &RestoreOptions{}
+	}
+	archive, err := backup.Open(r)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	manifest := archive.Manifest
+	version, dirty, err := s.backupStore.SchemaVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, fmt.Errorf("迁移版本 %d 处于 dirty 状态，需要修复后再恢复", version)
+	}
+	if manifest.SchemaVersion != version {
+		return nil, fmt.Errorf("备份的数据库版本 %d 与当前版本 %d 不一致，请先将数据库迁移到版本 %d", manifest.SchemaVersion, version, manifest.SchemaVersion)
+	}
+	restoreObjects := !opts.SkipObjects && len(manifest.Objects) > 0
+	if restoreObjects && s.storage == nil {
+		return nil, errors.New("对象存储不可用，无法恢复文件")
+	}
+
+	data, err := loadBackupTables(archive)
+	if err != nil {
+		return nil, err
+	}
+	workspaces := data["workspaces"]
+	if len(workspaces) != 1 {
+		return nil, fmt.Errorf("备份中应有 1 个工作区，实际为 %d 个", len(workspaces))
+	}
+
+	slug := opts.Slug
+	if slug == "" {
+		slug = manifest.Workspace.Slug
+	}
+	if !workspaceSlugRegex.MatchString(slug) {
+		return nil, fmt.Errorf("无效的工作区标识: 只能包含小写字母、数字和连字符，最长 50 个字符")
+	}
+	if _, err := s.workspaceStore.GetBySlug(ctx, slug); err == nil {
+		return nil, fmt.Errorf("工作区标识 %s 已被使用，请指定新的标识", slug)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("获取工作区失败: %w", err)
+	}
+	workspaces[0]["slug"] = slug
+
+	report := &RestoreReport{Slug: slug, Rows: make(map[string]int), RenamedUsers: make(map[string]string)}
+	ids := make(map[string]string)
+	// skipped 按邮箱匹配到已有用户，不再写入
+	skipped, err := s.matchUsers(ctx, data["users"], ids, report)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.assignIDs(ctx, data, ids, skipped, report); err != nil {
+		return nil, err
+	}
+	report.WorkspaceID, _ = uuid.Parse(ids[fmt.Sprint(workspaces[0]["id"])])
+
+	urls := make(map[string]string)
+	if restoreObjects {
+		for _, object := range manifest.Objects {
+			urls[object.URL] = s.storage.ObjectURL(object.Key)
+		}
+	}
+	for _, table := range backupTables {
+		for i, row := range data[table.name] {
+			data[table.name][i] = remapRow(row, ids, table.urls, urls)
+		}
+		if table.parent != "" {
+			data[table.name] = sortByParent(data[table.name], table.parent)
+		}
+	}
+	if err := s.fixHomeWorkspaces(ctx, data["users"], report.WorkspaceID); err != nil {
+		return nil, err
+	}
+
+	err = s.backupStore.Transaction(ctx, func(tx store.BackupStore) error {
+		for _, table := range backupTables {
+			for _, row := range data[table.name] {
+				if id, ok := row["id"].(string); ok && skipped[id] {
+					continue
+				}
+				encoded, err := json.Marshal(row)
+				if err != nil {
+					return fmt.Errorf("序列化 %s 数据失败: %w", table.name, err)
+				}
+				if err := tx.InsertRow(ctx, table.name, encoded); err != nil {
+					return err
+				}
+				report.Rows[table.name]++
+			}
+		}
+		// 文件在事务提交前上传，上传失败时数据一并回滚（已上传的文件不会被引用）
+		if restoreObjects {
+			for _, object := range manifest.Objects {
+				if err := s.restoreObject(ctx, archive, &object); err != nil {
+					return err
+				}
+				report.Objects++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recordAudit(ctx, s.auditService, &AuditEntry{
+		WorkspaceID: report.WorkspaceID,
+		Action:      model.AuditDataRestored,
+		TargetType:  model.AuditTargetWorkspace,
+		TargetID:    report.WorkspaceID.String(),
+		After: map[string]interface{}{
+			"source_workspace_id": manifest.Workspace.ID,
+			"backup_created_at":   manifest.CreatedAt,
+			"remapped_ids":        report.RemappedIDs,
+		},
+	})
+	return report, nil
+}
+
+// loadBackupTables 读取归档中的表数据；只接受备份范围内的表
+func loadBackupTables(archive *backup.Archive) (map[string][]map[string]interface{}, error) {
+	known := make(map[string]bool, len(backupTables))
+	for _, table := range backupTables {
+		known[table.name] = true
+	}
+
+	data := make(map[string][]map[string]interface{})
+	for _, table := range archive.Manifest.Tables {
+		if !known[table.Name] {
+			return nil, fmt.Errorf("备份中包含不支持的表 %s", table.Name)
+		}
+		f, err := archive.Open(backup.TablePath(table.Name))
+		if err != nil {
+			return nil, err
+		}
+		rows, err := decodeRows(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("读取表 %s 失败: %w", table.Name, err)
+		}
+		if len(rows) != table.Rows {
+			return nil, fmt.Errorf("表 %s 的行数 %d 与清单中的 %d 不一致", table.Name, len(rows), table.Rows)
+		}
+		data[table.Name] = rows
+	}
+	return data, nil
+}
+
+// decodeRows 读取 NDJSON，数字保留原始文本，避免大整数丢失精度
+func decodeRows(r io.Reader) ([]map[string]interface{}, error) {
+	var rows []map[string]interface{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64<<20)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		var row map[string]interface{}
+		if err := decoder.Decode(&row); err != nil {
+			return nil, fmt.Errorf("第 %d 行: %w", len(rows)+1, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
+
+// matchUsers 按邮箱匹配已有用户；未匹配的用户如果用户名已被占用则改名
+func (s *backupService) matchUsers(ctx context.Context, users []map[string]interface{}, ids map[string]string, report *RestoreReport) (map[string]bool, error) {
+	skipped := make(map[string]bool)
+	for _, row := range users {
+		id, _ := row["id"].(string)
+		email, _ := row["email"].(string)
+		existing, err := s.userStore.GetUserByEmail(ctx, email)
+		if err == nil {
+			ids[id] = existing.ID.String()
+			skipped[id] = true
+			report.MatchedUsers++
+			continue
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("获取用户失败: %w", err)
+		}
+
+		username, _ := row["username"].(string)
+		if _, err := s.userStore.GetUserByUsername(ctx, username); errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("获取用户失败: %w", err)
+		}
+		renamed, err := s.freeUsername(ctx, username)
+		if err != nil {
+			return nil, err
+		}
+		row["username"] = renamed
+		report.RenamedUsers[username] = renamed
+	}
+	return skipped, nil
+}
+
+// freeUsername 在用户名后追加随机后缀，直到没有冲突
+func (s *backupService) freeUsername(ctx context.Context, username string) (string, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		suffix := make([]byte, 3)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", fmt.Errorf("生成用户名失败: %w", err)
+		}
+		// 用户名最长 50 个字符，后缀占 7 个
+		base := username
+		if len(base) > 43 {
+			base = base[:43]
+		}
+		candidate := base + "-" + hex.EncodeToString(suffix)
+		if _, err := s.userStore.GetUserByUsername(ctx, candidate); errors.Is(err, gorm.ErrRecordNotFound) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("无法为用户 %s 生成不冲突的用户名", username)
+}
+
+// assignIDs 为每行分配恢复后的 ID：不冲突时保留原 ID，否则生成新 ID
+func (s *backupService) assignIDs(ctx context.Context, data map[string][]map[string]interface{}, ids map[string]string, skipped map[string]bool, report *RestoreReport) error {
+	for _, table := range backupTables {
+		var tableIDs []string
+		for _, row := range data[table.name] {
+			if id, ok := row["id"].(string); ok && !skipped[id] {
+				tableIDs = append(tableIDs, id)
+			}
+		}
+		if len(tableIDs) == 0 {
+			continue
+		}
+		existing, err := s.backupStore.ExistingIDs(ctx, table.name, tableIDs)
+		if err != nil {
+			return err
+		}
+		for _, id := range tableIDs {
+			if existing[id] {
+				ids[id] = uuid.NewString()
+				report.RemappedIDs++
+			} else {
+				ids[id] = id
+			}
+		}
+	}
+	return nil
+}
+
+// fixHomeWorkspaces 主工作区不在备份中且目标库中也不存在的用户，以恢复的工作区作为主工作区
+func (s *backupService) fixHomeWorkspaces(ctx context.Context, users []map[string]interface{}, workspaceID uuid.UUID) error {
+	var homes []string
+	for _, row := range users {
+		if home, ok := row["workspace_id"].(string); ok && home != workspaceID.String() {
+			homes = append(homes, home)
+		}
+	}
+	if len(homes) == 0 {
+		return nil
+	}
+	existing, err := s.backupStore.ExistingIDs(ctx, "workspaces", homes)
+	if err != nil {
+		return err
+	}
+	for _, row := range users {
+		if home, ok := row["workspace_id"].(string); ok && home != workspaceID.String() && !existing[home] {
+			row["workspace_id"] = workspaceID.String()
+		}
+	}
+	return nil
+}
+
+// restoreObject 将归档中的文件上传到对象存储
+func (s *backupService) restoreObject(ctx context.Context, archive *backup.Archive, object *backup.Object) error {
+	f, err := archive.Open(backup.ObjectPath(object.Key))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("读取对象 %s 失败: %w", object.Key, err)
+	}
+	if err := s.storage.PutObject(ctx, object.Key, f, info.Size(), object.ContentType); err != nil {
+		return fmt.Errorf("恢复对象 %s 失败: %w", object.Key, err)
+	}
+	return nil
+}
+
+// remapRow 替换行中所有引用备份内 ID 的值（包括 JSON 列和数组列中的 ID），并将 URL 列替换为新地址
+func remapRow(row map[string]interface{}, ids map[string]string, urlColumns []string, urls map[string]string) map[string]interface{} {
+	remapped := remapValue(row, ids).(map[string]interface{})
+	for _, column := range urlColumns {
+		if url, ok := remapped[column].(string); ok {
+			if newURL, ok := urls[url]; ok {
+				remapped[column] = newURL
+			}
+		}
+	}
+	return remapped
+}
+
+// remapValue 递归替换与备份内 ID 完全相同的字符串
+func remapValue(value interface{}, ids map[string]string) interface{} {
+	switch v := value.(type) {
+	case string:
+		if id, ok := ids[v]; ok {
+			return id
+		}
+		return v
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = remapValue(item, ids)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = remapValue(item, ids)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// sortByParent 调整顺序使父记录排在子记录之前；父记录不在本表数据中时视为根
+func sortByParent(rows []map[string]interface{}, column string) []map[string]interface{} {
+	index := make(map[string]int, len(rows))
+	for i, row := range rows {
+		if id, ok := row["id"].(string); ok {
+			index[id] = i
+		}
+	}
+
+	sorted := make([]map[string]interface{}, 0, len(rows))
+	// state: 0 未访问，1 访问中（遇到环时直接输出），2 已输出
+	state := make([]int, len(rows))
+	var visit func(i int)
+	visit = func(i int) {
+		if state[i] != 0 {
+			return
+		}
+		state[i] = 1
+		if parent, ok := rows[i][column].(string); ok {
+			if p, ok := index[parent]; ok {
+				visit(p)
+			}
+		}
+		state[i] = 2
+		sorted = append(sorted, rows[i])
+	}
+	for i := range rows {
+		visit(i)
+	}
+	return sorted
+}
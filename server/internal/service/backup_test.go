@@ -0,0 +1,188 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/backup"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackupStore 只返回迁移版本的 BackupStore
+type fakeBackupStore struct {
+	version uint
+	dirty   bool
+}
+
+func (f *fakeBackupStore) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	return f.version, f.dirty, nil
+}
+
+func (f *fakeBackupStore) ExportRows(ctx context.Context, table, where string, args []interface{}, fn func(row json.RawMessage) error) error {
+	return nil
+}
+
+func (f *fakeBackupStore) ExistingIDs(ctx context.Context, table string, ids []string) (map[string]bool, error) {
+	return map[string]bool{}, nil
+}
+
+func (f *fakeBackupStore) InsertRow(ctx context.Context, table string, row json.RawMessage) error {
+	return nil
+}
+
+func (f *fakeBackupStore) Snapshot(ctx context.Context, fn func(tx store.BackupStore) error) error {
+	return fn(f)
+}
+
+func (f *fakeBackupStore) Transaction(ctx context.Context, fn func(tx store.BackupStore) error) error {
+	return fn(f)
+}
+
+// testBackupArchive 生成只包含工作区的备份归档
+func testBackupArchive(t *testing.T, schemaVersion uint) []byte {
+	t.Helper()
+	workspaceID := uuid.New()
+	var buf bytes.Buffer
+	w := backup.NewWriter(&buf)
+	_, err := w.Add(backup.TablePath("workspaces"), func(out io.Writer) error {
+		_, err := io.WriteString(out, `{"id":"`+workspaceID.String()+`","name":"Acme","slug":"acme"}`+"\n")
+		return err
+	})
+	require.NoError(t, err)
+	require.NoError(t, w.Close(&backup.Manifest{
+		SchemaVersion: schemaVersion,
+		Workspace:     backup.Workspace{ID: workspaceID, Name: "Acme", Slug: "acme"},
+		Tables:        []backup.Table{{Name: "workspaces", Rows: 1}},
+	}))
+	return buf.Bytes()
+}
+
+func TestBackupService_Restore_SchemaVersion(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("版本不一致", func(t *testing.T) {
+		svc := NewBackupService(&fakeBackupStore{version: 26}, nil, nil, nil)
+		_, err := svc.Restore(ctx, bytes.NewReader(testBackupArchive(t, 25)), nil)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "备份的数据库版本 25 与当前版本 26 不一致")
+		}
+	})
+
+	t.Run("迁移处于 dirty 状态", func(t *testing.T) {
+		svc := NewBackupService(&fakeBackupStore{version: 25, dirty: true}, nil, nil, nil)
+		_, err := svc.Restore(ctx, bytes.NewReader(testBackupArchive(t, 25)), nil)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "dirty")
+		}
+	})
+
+	t.Run("归档损坏", func(t *testing.T) {
+		svc := NewBackupService(&fakeBackupStore{version: 25}, nil, nil, nil)
+		_, err := svc.Restore(ctx, strings.NewReader("broken"), nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestBackupService_Backup_RequiresStorage(t *testing.T) {
+	svc := NewBackupService(&fakeBackupStore{version: 25}, nil, nil, nil)
+	_, err := svc.Backup(context.Background(), "acme", io.Discard, nil)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "对象存储不可用")
+	}
+}
+
+func TestRemapRow(t *testing.T) {
+	oldIssue, newIssue := uuid.NewString(), uuid.NewString()
+	oldLabel, newLabel := uuid.NewString(), uuid.NewString()
+	kept := uuid.NewString()
+	ids := map[string]string{oldIssue: newIssue, oldLabel: newLabel, kept: kept}
+
+	rows, err := decodeRows(strings.NewReader(`{"id":"` + oldIssue + `","labels":["` + oldLabel + `","` + kept + `"],` +
+		`"payload":{"issue_id":"` + oldIssue + `","text":"` + oldIssue + ` 之外的文字"},"number":9007199254740993,` +
+		`"avatar_url":"http://old/avatars/a.png"}` + "\n"))
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+
+	row := remapRow(rows[0], ids, []string{"avatar_url"}, map[string]string{"http://old/avatars/a.png": "http://new/avatars/a.png"})
+	assert.Equal(t, newIssue, row["id"])
+	assert.Equal(t, []interface{}{newLabel, kept}, row["labels"])
+	payload := row["payload"].(map[string]interface{})
+	assert.Equal(t, newIssue, payload["issue_id"])
+	// 只替换完全相同的字符串
+	assert.Equal(t, oldIssue+" 之外的文字", payload["text"])
+	assert.Equal(t, "http://new/avatars/a.png", row["avatar_url"])
+
+	// 大整数保持精度
+	encoded, err := json.Marshal(row)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"number":9007199254740993`)
+}
+
+func TestSortByParent(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"id": "grandchild", "parent_id": "child"},
+		{"id": "child", "parent_id": "root"},
+		{"id": "orphan", "parent_id": "outside"},
+		{"id": "root", "parent_id": nil},
+		// 环不会导致死循环
+		{"id": "a", "parent_id": "b"},
+		{"id": "b", "parent_id": "a"},
+	}
+
+	sorted := sortByParent(rows, "parent_id")
+	require.Len(t, sorted, len(rows))
+	position := make(map[string]int)
+	for i, row := range sorted {
+		position[row["id"].(string)] = i
+	}
+	assert.Less(t, position["root"], position["child"])
+	assert.Less(t, position["child"], position["grandchild"])
+}
+
+func TestBackupTables_Order(t *testing.T) {
+	// 有父记录的表和被引用的表必须排在引用它的表之前
+	position := make(map[string]int)
+	for i, table := range backupTables {
+		_, dup := position[table.name]
+		assert.False(t, dup, "表 %s 重复", table.name)
+		position[table.name] = i
+	}
+	before := [][2]string{
+		{"workspaces", "users"},
+		{"users", "workspace_members"},
+		{"teams", "workflow_states"},
+		{"workflow_states", "issues"},
+		{"projects", "milestones"},
+		{"milestones", "issues"},
+		{"cycles", "issues"},
+		{"issues", "comments"},
+		{"issues", "documents"},
+		{"projects", "documents"},
+	}
+	for _, pair := range before {
+		assert.Less(t, position[pair[0]], position[pair[1]], "%s 应在 %s 之前", pair[0], pair[1])
+	}
+}
+
+func TestAvatarService_ObjectKey(t *testing.T) {
+	svc := &avatarService{bucket: "mylinear", baseURL: "http://localhost:9000"}
+
+	url := svc.ObjectURL("avatars/u1/a.png")
+	assert.Equal(t, "http://localhost:9000/mylinear/avatars/u1/a.png", url)
+
+	key, ok := svc.ObjectKey(url)
+	assert.True(t, ok)
+	assert.Equal(t, "avatars/u1/a.png", key)
+
+	for _, other := range []string{"", "https://gravatar.com/avatar/x", "http://localhost:9000/other/a.png", "http://localhost:9000/mylinear/"} {
+		_, ok := svc.ObjectKey(other)
+		assert.False(t, ok, other)
+	}
+}
@@ -4,10 +4,12 @@ package service
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	"github.com/google/uuid"
 	"github.com/liwei0526vip/mylinear/internal/model"
 	"github.com/liwei0526vip/mylinear/internal/store"
+	"github.com/liwei0526vip/mylinear/internal/tracing"
 )
 
 // CommentService 定义评论业务逻辑接口
@@ -65,6 +67,9 @@ func NewCommentServiceWithNotification(
 
 // CreateComment 创建评论
 func (s *commentService) CreateComment(ctx context.Context, issueID, userID uuid.UUID, body string, parentID *uuid.UUID) (*model.Comment, error) {
+	ctx, span := tracing.Start(ctx, "CommentService.CreateComment")
+	defer span.End()
+
 	// 创建评论
 	comment := &model.Comment{
 		IssueID:  issueID,
@@ -80,7 +85,7 @@ func (s *commentService) CreateComment(ctx context.Context, issueID, userID uuid
 	// 自动订阅评论者
 	if err := s.subscriptionStore.Subscribe(ctx, issueID, userID); err != nil {
 		// 订阅失败不影响评论创建，记录日志即可
-		// TODO: 添加日志记录
+		slog.WarnContext(ctx, "评论者自动订阅 Issue 失败", "issue_id", issueID, "user_id", userID, "error", err)
 	}
 
 	// 解析 @mentions
@@ -92,24 +97,35 @@ func (s *commentService) CreateComment(ctx context.Context, issueID, userID uuid
 			continue // 用户不存在，跳过
 		}
 		// 自动订阅被提及的用户
-		_ = s.subscriptionStore.Subscribe(ctx, issueID, user.ID)
+		if err := s.subscriptionStore.Subscribe(ctx, issueID, user.ID); err != nil {
+			slog.WarnContext(ctx, "被提及用户自动订阅 Issue 失败", "issue_id", issueID, "user_id", user.ID, "error", err)
+		}
 	}
 
 	// 触发通知
 	if s.notificationService != nil {
 		// 获取 Issue 信息
 		issue, err := s.issueStore.GetByID(ctx, issueID)
-		if err == nil {
+		if err != nil {
+			slog.WarnContext(ctx, "获取 Issue 失败，跳过评论通知", "issue_id", issueID, "user_id", userID, "error", err)
+		} else {
 			// 发送 @mention 通知
-			_ = s.notificationService.NotifyIssueMentioned(ctx, userID, usernames, issueID, issue.Title)
+			if err := s.notificationService.NotifyIssueMentioned(ctx, userID, usernames, issueID, issue.Title); err != nil {
+				slog.WarnContext(ctx, "发送提及通知失败", "issue_id", issueID, "user_id", userID, "error", err)
+			}
 
 			// 获取订阅者列表并发送评论通知
-			subscribers, _ := s.subscriptionStore.ListSubscribers(ctx, issueID)
+			subscribers, err := s.subscriptionStore.ListSubscribers(ctx, issueID)
+			if err != nil {
+				slog.WarnContext(ctx, "获取 Issue 订阅者失败", "issue_id", issueID, "error", err)
+			}
 			subscriberIDs := make([]uuid.UUID, len(subscribers))
 			for i, sub := range subscribers {
 				subscriberIDs[i] = sub.ID
 			}
-			_ = s.notificationService.NotifySubscribers(ctx, userID, subscriberIDs, model.NotificationTypeIssueCommented, issueID, "Issue 有新评论", body)
+			if err := s.notificationService.NotifySubscribers(ctx, userID, subscriberIDs, model.NotificationTypeIssueCommented, issueID, "Issue 有新评论", body); err != nil {
+				slog.WarnContext(ctx, "发送评论通知失败", "issue_id", issueID, "user_id", userID, "error", err)
+			}
 		}
 	}
 
@@ -118,6 +134,9 @@ func (s *commentService) CreateComment(ctx context.Context, issueID, userID uuid
 
 // UpdateComment 更新评论（仅作者可更新）
 func (s *commentService) UpdateComment(ctx context.Context, commentID, userID uuid.UUID, body string) (*model.Comment, error) {
+	ctx, span := tracing.Start(ctx, "CommentService.UpdateComment")
+	defer span.End()
+
 	// 获取评论
 	comment, err := s.commentStore.GetCommentByID(ctx, commentID)
 	if err != nil {
@@ -140,6 +159,9 @@ func (s *commentService) UpdateComment(ctx context.Context, commentID, userID uu
 
 // DeleteComment 删除评论（仅作者或管理员可删除）
 func (s *commentService) DeleteComment(ctx context.Context, commentID, userID uuid.UUID) error {
+	ctx, span := tracing.Start(ctx, "CommentService.DeleteComment")
+	defer span.End()
+
 	// 获取评论
 	comment, err := s.commentStore.GetCommentByID(ctx, commentID)
 	if err != nil {
@@ -167,6 +189,9 @@ func (s *commentService) DeleteComment(ctx context.Context, commentID, userID uu
 
 // GetCommentsByIssueID 获取 Issue 的评论列表
 func (s *commentService) GetCommentsByIssueID(ctx context.Context, issueID uuid.UUID, page, pageSize int) ([]model.Comment, int64, error) {
+	ctx, span := tracing.Start(ctx, "CommentService.GetCommentsByIssueID")
+	defer span.End()
+
 	if page <= 0 {
 		page = 1
 	}
@@ -0,0 +1,921 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/datatypes"
+)
+
+// GitSettings 团队的 Git 集成设置，保存在 Team.WorkflowSettings 的 "git" 字段中
+type GitSettings struct {
+	// DisableAutoTransition 为 true 时只关联 PR / 提交，不变更 Issue 状态
+	DisableAutoTransition bool `json:"disable_auto_transition"`
+	// PROpenedStateID PR 打开时移动到的状态，为空时使用团队第一个"进行中"状态
+	PROpenedStateID *uuid.UUID `json:"pr_opened_state_id,omitempty"`
+	// PRMergedStateID PR 合并时移动到的状态，为空时使用团队第一个"已完成"状态
+	PRMergedStateID *uuid.UUID `json:"pr_merged_state_id,omitempty"`
+	// PRClosedStateID PR 未合并关闭时移动到的状态，为空时不变更
+	PRClosedStateID *uuid.UUID `json:"pr_closed_state_id,omitempty"`
+}
+
+// GitWebhookResult Webhook 处理结果
+type GitWebhookResult struct {
+	Event string `json:"event"`
+	// Issues 事件中识别出并成功关联的 Issue 标识符
+	Issues []string `json:"issues"`
+	// LinksCreated 新建的链接数
+	LinksCreated int `json:"links_created"`
+	// Transitions 状态变更的 Issue 数
+	Transitions int `json:"transitions"`
+	// Ignored 事件被忽略的原因
+	Ignored string `json:"ignored,omitempty"`
+}
+
+// GitIntegrationService 定义 Git 集成服务接口
+type GitIntegrationService interface {
+	// VerifyGitHubSignature 校验 GitHub Webhook 的 X-Hub-Signature-256 签名
+	VerifyGitHubSignature(body []byte, signature string) error
+	// VerifyGitLabToken 校验 GitLab Webhook 的 X-Gitlab-Token
+	VerifyGitLabToken(token string) error
+	// HandleGitHubEvent 处理 GitHub Webhook 事件（X-GitHub-Event）
+	// ctx 中的 workspace_id 指定标识符所属的工作区，未指定时在所有工作区中查找唯一匹配，
+	// 匹配到多个工作区的标识符不做关联，并在结果的 Ignored 中说明
+	HandleGitHubEvent(ctx context.Context, event string, body []byte) (*GitWebhookResult, error)
+	// HandleGitLabEvent 处理 GitLab Webhook 事件（X-Gitlab-Event），工作区规则同 HandleGitHubEvent
+	HandleGitLabEvent(ctx context.Context, event string, body []byte) (*GitWebhookResult, error)
+	// SuggestBranchName 为 Issue 生成建议的分支名
+	SuggestBranchName(ctx context.Context, issueID string) (string, error)
+	// ListIssueLinks 获取 Issue 关联的 PR / 提交
+	ListIssueLinks(ctx context.Context, issueID string) ([]model.IssueLink, error)
+	// GetGitSettings 获取团队的 Git 集成设置
+	GetGitSettings(ctx context.Context, teamID string) (*GitSettings, error)
+	// UpdateGitSettings 更新团队的 Git 集成设置
+	UpdateGitSettings(ctx context.Context, teamID string, settings *GitSettings) (*GitSettings, error)
+}
+
+// gitIntegrationService 实现 GitIntegrationService 接口
+type gitIntegrationService struct {
+	githubSecret       string
+	gitlabToken        string
+	issueStore         store.IssueStore
+	issueLinkStore     store.IssueLinkStore
+	teamStore          store.TeamStore
+	teamMemberStore    store.TeamMemberStore
+	workflowStateStore store.WorkflowStateStore
+	userStore          store.UserStore
+	activityService    ActivityService
+}
+
+// NewGitIntegrationService 创建 Git 集成服务实例
+// githubSecret / gitlabToken 为空时拒绝对应平台的 Webhook
+func NewGitIntegrationService(githubSecret, gitlabToken string, issueStore store.IssueStore, issueLinkStore store.IssueLinkStore, teamStore store.TeamStore, teamMemberStore store.TeamMemberStore, workflowStateStore store.WorkflowStateStore, userStore store.UserStore, activityService ActivityService) GitIntegrationService {
+	return &gitIntegrationService{
+		githubSecret:       githubSecret,
+		gitlabToken:        gitlabToken,
+		issueStore:         issueStore,
+		issueLinkStore:     issueLinkStore,
+		teamStore:          teamStore,
+		teamMemberStore:    teamMemberStore,
+		workflowStateStore: workflowStateStore,
+		userStore:          userStore,
+		activityService:    activityService,
+	}
+}
+
+// =============================================================================
+// 签名校验
+// =============================================================================
+
+// VerifyGitHubSignature 校验 GitHub Webhook 的 X-Hub-Signature-256 签名
+func (s *gitIntegrationService) VerifyGitHubSignature(body []byte, signature string) error {
+	if s.githubSecret == "" {
+		return fmt.Errorf("GitHub Webhook 未启用")
+	}
+	sig, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return fmt.Errorf("未认证: 缺少有效的 Webhook 签名")
+	}
+	expected, err := hex.DecodeString(sig)
+	if err != nil {
+		return fmt.Errorf("未认证: 缺少有效的 Webhook 签名")
+	}
+	mac := hmac.New(sha256.New, []byte(s.githubSecret))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return fmt.Errorf("未认证: Webhook 签名不匹配")
+	}
+	return nil
+}
+
+// VerifyGitLabToken 校验 GitLab Webhook 的 X-Gitlab-Token
+func (s *gitIntegrationService) VerifyGitLabToken(token string) error {
+	if s.gitlabToken == "" {
+		return fmt.Errorf("GitLab Webhook 未启用")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.gitlabToken)) != 1 {
+		return fmt.Errorf("未认证: Webhook Token 不匹配")
+	}
+	return nil
+}
+
+// =============================================================================
+// 标识符解析
+// =============================================================================
+
+// issueIdentifierPattern 匹配 Issue 标识符（如 ENG-123），分支名中常为小写
+var issueIdentifierPattern = regexp.MustCompile(`(?i)(?:^|[^A-Za-z0-9])([A-Z][A-Z0-9]{1,9})-(\d+)`)
+
+// closingKeywordPattern 匹配提交信息中的关闭关键字（如 "fixes ENG-123"）
+var closingKeywordPattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s*:?\s+([A-Z][A-Z0-9]{1,9})-(\d+)`)
+
+// issueRef 解析出的 Issue 引用
+type issueRef struct {
+	TeamKey string
+	Number  int
+}
+
+// String 返回标识符（如 ENG-123）
+func (r issueRef) String() string {
+	return r.TeamKey + "-" + strconv.Itoa(r.Number)
+}
+
+// ParseIssueIdentifiers 从分支名、PR 标题、提交信息等文本中解析 Issue 标识符（去重，保持出现顺序）
+func ParseIssueIdentifiers(texts ...string) []string {
+	refs := parseIssueRefs(texts...)
+	identifiers := make([]string, len(refs))
+	for i, ref := range refs {
+		identifiers[i] = ref.String()
+	}
+	return identifiers
+}
+
+// parseIssueRefs 从文本中解析 Issue 引用（去重，保持出现顺序）
+func parseIssueRefs(texts ...string) []issueRef {
+	var refs []issueRef
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		for _, m := range issueIdentifierPattern.FindAllStringSubmatch(text, -1) {
+			number, err := strconv.Atoi(m[2])
+			if err != nil || number <= 0 {
+				continue
+			}
+			ref := issueRef{TeamKey: strings.ToUpper(m[1]), Number: number}
+			if !seen[ref.String()] {
+				seen[ref.String()] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	return refs
+}
+
+// parseClosingRefs 解析带关闭关键字的 Issue 引用
+func parseClosingRefs(text string) map[string]bool {
+	closing := make(map[string]bool)
+	for _, m := range closingKeywordPattern.FindAllStringSubmatch(text, -1) {
+		if number, err := strconv.Atoi(m[2]); err == nil {
+			closing[issueRef{TeamKey: strings.ToUpper(m[1]), Number: number}.String()] = true
+		}
+	}
+	return closing
+}
+
+// =============================================================================
+// 事件处理
+// =============================================================================
+
+// gitAction PR / MR 事件归一化后的动作
+type gitAction string
+
+const (
+	gitActionOpened  gitAction = "opened"
+	gitActionUpdated gitAction = "updated"
+	gitActionMerged  gitAction = "merged"
+	gitActionClosed  gitAction = "closed"
+)
+
+// gitPullRequest 归一化的 PR / MR 事件
+type gitPullRequest struct {
+	Provider    string
+	Action      gitAction
+	Draft       bool
+	Number      int
+	URL         string
+	Title       string
+	Body        string
+	Branch      string
+	Author      string
+	AuthorEmail string
+}
+
+// gitCommit 归一化的提交
+type gitCommit struct {
+	SHA         string
+	Message     string
+	URL         string
+	Author      string
+	AuthorEmail string
+}
+
+// gitPush 归一化的推送事件
+type gitPush struct {
+	Provider      string
+	Branch        string
+	DefaultBranch string
+	Commits       []gitCommit
+}
+
+// githubUserPayload GitHub 用户
+type githubUserPayload struct {
+	Login string `json:"login"`
+	Email string `json:"email"`
+}
+
+// HandleGitHubEvent 处理 GitHub Webhook 事件（X-GitHub-Event）
+func (s *gitIntegrationService) HandleGitHubEvent(ctx context.Context, event string, body []byte) (*GitWebhookResult, error) {
+	switch event {
+	case "ping":
+		return &GitWebhookResult{Event: event, Ignored: "ping"}, nil
+	case "pull_request":
+		var payload struct {
+			Action      string `json:"action"`
+			PullRequest struct {
+				Number  int    `json:"number"`
+				HTMLURL string `json:"html_url"`
+				Title   string `json:"title"`
+				Body    string `json:"body"`
+				Draft   bool   `json:"draft"`
+				Merged  bool   `json:"merged"`
+				Head    struct {
+					Ref string `json:"ref"`
+				} `json:"head"`
+				User githubUserPayload `json:"user"`
+			} `json:"pull_request"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("无效的 Webhook 数据: %w", err)
+		}
+		pr := payload.PullRequest
+		var action gitAction
+		switch payload.Action {
+		case "opened", "reopened", "ready_for_review":
+			action = gitActionOpened
+		case "edited", "synchronize", "converted_to_draft":
+			action = gitActionUpdated
+		case "closed":
+			action = gitActionClosed
+			if pr.Merged {
+				action = gitActionMerged
+			}
+		default:
+			return &GitWebhookResult{Event: event, Ignored: "不处理的动作: " + payload.Action}, nil
+		}
+		return s.handlePullRequest(ctx, event, &gitPullRequest{
+			Provider:    model.GitProviderGitHub,
+			Action:      action,
+			Draft:       pr.Draft,
+			Number:      pr.Number,
+			URL:         pr.HTMLURL,
+			Title:       pr.Title,
+			Body:        pr.Body,
+			Branch:      pr.Head.Ref,
+			Author:      pr.User.Login,
+			AuthorEmail: pr.User.Email,
+		})
+	case "push":
+		var payload struct {
+			Ref        string `json:"ref"`
+			Repository struct {
+				DefaultBranch string `json:"default_branch"`
+			} `json:"repository"`
+			Commits []struct {
+				ID      string `json:"id"`
+				Message string `json:"message"`
+				URL     string `json:"url"`
+				Author  struct {
+					Name     string `json:"name"`
+					Email    string `json:"email"`
+					Username string `json:"username"`
+				} `json:"author"`
+			} `json:"commits"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("无效的 Webhook 数据: %w", err)
+		}
+		push := &gitPush{
+			Provider:      model.GitProviderGitHub,
+			Branch:        strings.TrimPrefix(payload.Ref, "refs/heads/"),
+			DefaultBranch: payload.Repository.DefaultBranch,
+		}
+		for _, c := range payload.Commits {
+			push.Commits = append(push.Commits, gitCommit{
+				SHA:         c.ID,
+				Message:     c.Message,
+				URL:         c.URL,
+				Author:      firstNonEmptyString(c.Author.Username, c.Author.Name),
+				AuthorEmail: c.Author.Email,
+			})
+		}
+		return s.handlePush(ctx, event, push)
+	default:
+		return &GitWebhookResult{Event: event, Ignored: "不处理的事件类型"}, nil
+	}
+}
+
+// HandleGitLabEvent 处理 GitLab Webhook 事件（X-Gitlab-Event）
+func (s *gitIntegrationService) HandleGitLabEvent(ctx context.Context, event string, body []byte) (*GitWebhookResult, error) {
+	switch event {
+	case "Merge Request Hook":
+		var payload struct {
+			User struct {
+				Username string `json:"username"`
+				Email    string `json:"email"`
+			} `json:"user"`
+			ObjectAttributes struct {
+				IID            int    `json:"iid"`
+				URL            string `json:"url"`
+				Title          string `json:"title"`
+				Description    string `json:"description"`
+				SourceBranch   string `json:"source_branch"`
+				Action         string `json:"action"`
+				Draft          bool   `json:"draft"`
+				WorkInProgress bool   `json:"work_in_progress"`
+			} `json:"object_attributes"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("无效的 Webhook 数据: %w", err)
+		}
+		mr := payload.ObjectAttributes
+		var action gitAction
+		switch mr.Action {
+		case "open", "reopen":
+			action = gitActionOpened
+		case "update":
+			action = gitActionUpdated
+		case "merge":
+			action = gitActionMerged
+		case "close":
+			action = gitActionClosed
+		default:
+			return &GitWebhookResult{Event: event, Ignored: "不处理的动作: " + mr.Action}, nil
+		}
+		return s.handlePullRequest(ctx, event, &gitPullRequest{
+			Provider:    model.GitProviderGitLab,
+			Action:      action,
+			Draft:       mr.Draft || mr.WorkInProgress,
+			Number:      mr.IID,
+			URL:         mr.URL,
+			Title:       mr.Title,
+			Body:        mr.Description,
+			Branch:      mr.SourceBranch,
+			Author:      payload.User.Username,
+			AuthorEmail: payload.User.Email,
+		})
+	case "Push Hook":
+		var payload struct {
+			Ref     string `json:"ref"`
+			Project struct {
+				DefaultBranch string `json:"default_branch"`
+			} `json:"project"`
+			Commits []struct {
+				ID      string `json:"id"`
+				Message string `json:"message"`
+				URL     string `json:"url"`
+				Author  struct {
+					Name  string `json:"name"`
+					Email string `json:"email"`
+				} `json:"author"`
+			} `json:"commits"`
+		}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("无效的 Webhook 数据: %w", err)
+		}
+		push := &gitPush{
+			Provider:      model.GitProviderGitLab,
+			Branch:        strings.TrimPrefix(payload.Ref, "refs/heads/"),
+			DefaultBranch: payload.Project.DefaultBranch,
+		}
+		for _, c := range payload.Commits {
+			push.Commits = append(push.Commits, gitCommit{
+				SHA:         c.ID,
+				Message:     c.Message,
+				URL:         c.URL,
+				Author:      c.Author.Name,
+				AuthorEmail: c.Author.Email,
+			})
+		}
+		return s.handlePush(ctx, event, push)
+	default:
+		return &GitWebhookResult{Event: event, Ignored: "不处理的事件类型"}, nil
+	}
+}
+
+// handlePullRequest 关联 PR 并按设置变更 Issue 状态
+func (s *gitIntegrationService) handlePullRequest(ctx context.Context, event string, pr *gitPullRequest) (*GitWebhookResult, error) {
+	result := &GitWebhookResult{Event: event, Issues: []string{}}
+
+	refs := parseIssueRefs(pr.Branch, pr.Title, pr.Body)
+	if len(refs) == 0 {
+		result.Ignored = "未找到 Issue 标识符"
+		return result, nil
+	}
+
+	state := model.IssueLinkStateOpen
+	switch pr.Action {
+	case gitActionMerged:
+		state = model.IssueLinkStateMerged
+	case gitActionClosed:
+		state = model.IssueLinkStateClosed
+	}
+
+	workspaceID, _ := ctx.Value("workspace_id").(uuid.UUID)
+	var ambiguous []string
+	for _, ref := range refs {
+		issue, err := s.issueStore.GetByIdentifier(ctx, workspaceID, ref.TeamKey, ref.Number)
+		if err != nil {
+			if errors.Is(err, store.ErrIdentifierAmbiguous) {
+				ambiguous = append(ambiguous, ref.String())
+			}
+			continue
+		}
+		result.Issues = append(result.Issues, ref.String())
+
+		actorID := s.resolveActor(ctx, issue, pr.Author, pr.AuthorEmail)
+		link := &model.IssueLink{
+			IssueID:  issue.ID,
+			Provider: pr.Provider,
+			Type:     model.IssueLinkPullRequest,
+			URL:      pr.URL,
+			Title:    pr.Title,
+			Ref:      "#" + strconv.Itoa(pr.Number),
+			Branch:   pr.Branch,
+			State:    state,
+			Author:   pr.Author,
+		}
+		created, err := s.saveLink(ctx, issue.ID, actorID, link)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			result.LinksCreated++
+		}
+
+		// 草稿 PR 和普通更新只关联，不变更状态
+		if pr.Draft || pr.Action == gitActionUpdated {
+			continue
+		}
+		moved, err := s.transition(ctx, issue, pr.Action, actorID)
+		if err != nil {
+			return nil, err
+		}
+		if moved {
+			result.Transitions++
+		}
+	}
+
+	result.Ignored = ambiguousIgnored(ambiguous)
+	return result, nil
+}
+
+// handlePush 关联提交；推送到默认分支且提交信息包含关闭关键字时视为合并
+func (s *gitIntegrationService) handlePush(ctx context.Context, event string, push *gitPush) (*GitWebhookResult, error) {
+	result := &GitWebhookResult{Event: event, Issues: []string{}}
+	linked := make(map[string]bool)
+	ambiguous := make(map[string]bool)
+	workspaceID, _ := ctx.Value("workspace_id").(uuid.UUID)
+
+	for _, commit := range push.Commits {
+		closing := map[string]bool{}
+		if push.DefaultBranch != "" && push.Branch == push.DefaultBranch {
+			closing = parseClosingRefs(commit.Message)
+		}
+
+		for _, ref := range parseIssueRefs(push.Branch, commit.Message) {
+			issue, err := s.issueStore.GetByIdentifier(ctx, workspaceID, ref.TeamKey, ref.Number)
+			if err != nil {
+				if errors.Is(err, store.ErrIdentifierAmbiguous) {
+					ambiguous[ref.String()] = true
+				}
+				continue
+			}
+			if !linked[ref.String()] {
+				linked[ref.String()] = true
+				result.Issues = append(result.Issues, ref.String())
+			}
+
+			actorID := s.resolveActor(ctx, issue, commit.Author, commit.AuthorEmail)
+			title, _, _ := strings.Cut(commit.Message, "\n")
+			shortSHA := commit.SHA
+			if len(shortSHA) > 7 {
+				shortSHA = shortSHA[:7]
+			}
+			created, err := s.saveLink(ctx, issue.ID, actorID, &model.IssueLink{
+				IssueID:  issue.ID,
+				Provider: push.Provider,
+				Type:     model.IssueLinkCommit,
+				URL:      commit.URL,
+				Title:    strings.TrimSpace(title),
+				Ref:      shortSHA,
+				Branch:   push.Branch,
+				Author:   commit.Author,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if created {
+				result.LinksCreated++
+			}
+
+			if closing[ref.String()] {
+				moved, err := s.transition(ctx, issue, gitActionMerged, actorID)
+				if err != nil {
+					return nil, err
+				}
+				if moved {
+					result.Transitions++
+				}
+			}
+		}
+	}
+
+	refs := make([]string, 0, len(ambiguous))
+	for ref := range ambiguous {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	result.Ignored = ambiguousIgnored(refs)
+	if len(result.Issues) == 0 && result.Ignored == "" {
+		result.Ignored = "未找到 Issue 标识符"
+	}
+	return result, nil
+}
+
+// ambiguousIgnored 未指定工作区时，多个工作区存在相同标识符的 Issue 无法关联，提示在 Webhook 地址中指定工作区
+func ambiguousIgnored(refs []string) string {
+	if len(refs) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("标识符 %s 匹配到多个工作区的 Issue，请在 Webhook 地址中添加 ?workspace_id= 指定工作区", strings.Join(refs, "、"))
+}
+
+// saveLink 保存链接，新建时记录活动
+func (s *gitIntegrationService) saveLink(ctx context.Context, issueID, actorID uuid.UUID, link *model.IssueLink) (bool, error) {
+	if link.URL == "" {
+		return false, nil
+	}
+	created, err := s.issueLinkStore.Upsert(ctx, link)
+	if err != nil {
+		return false, err
+	}
+	if created {
+		s.recordActivity(ctx, issueID, actorID, model.ActivityLinkAdded, &model.ActivityPayloadLink{
+			LinkID:   link.ID,
+			Provider: link.Provider,
+			Type:     link.Type,
+			URL:      link.URL,
+			Title:    link.Title,
+			State:    link.State,
+			Author:   link.Author,
+		})
+	}
+	return created, nil
+}
+
+// stateTypeRank 状态类型的推进顺序，自动流转只向前推进
+var stateTypeRank = map[model.StateType]int{
+	model.StateTypeBacklog:   0,
+	model.StateTypeUnstarted: 1,
+	model.StateTypeStarted:   2,
+	model.StateTypeCompleted: 3,
+	model.StateTypeCanceled:  3,
+}
+
+// transition 根据团队设置变更 Issue 状态，返回是否发生变更
+func (s *gitIntegrationService) transition(ctx context.Context, issue *model.Issue, action gitAction, actorID uuid.UUID) (bool, error) {
+	team := issue.Team
+	if team == nil {
+		var err error
+		if team, err = s.teamStore.GetByID(ctx, issue.TeamID.String()); err != nil {
+			return false, nil
+		}
+	}
+	settings, err := parseGitSettings(team.WorkflowSettings)
+	if err != nil || settings.DisableAutoTransition {
+		return false, nil
+	}
+
+	target, err := s.targetState(ctx, issue.TeamID, settings, action)
+	if err != nil || target == nil || target.ID == issue.StatusID {
+		return false, err
+	}
+
+	// PR 重新打开等场景不应把已完成的 Issue 拉回进行中；显式配置的关闭状态除外
+	if issue.Status != nil && action != gitActionClosed && stateTypeRank[target.Type] < stateTypeRank[issue.Status.Type] {
+		return false, nil
+	}
+
+	oldStatus := issue.Status
+	issue.StatusID = target.ID
+	issue.Status = target
+	now := time.Now()
+	issue.CompletedAt = nil
+	issue.CancelledAt = nil
+	switch target.Type {
+	case model.StateTypeCompleted:
+		issue.CompletedAt = &now
+	case model.StateTypeCanceled:
+		issue.CancelledAt = &now
+	}
+	if err := s.issueStore.Update(ctx, issue); err != nil {
+		return false, fmt.Errorf("更新 Issue 状态失败: %w", err)
+	}
+
+	payload := &model.ActivityPayloadStatus{
+		NewStatus: &model.ActivityStatusRef{ID: target.ID, Name: target.Name, Color: target.Color},
+	}
+	if oldStatus != nil {
+		payload.OldStatus = &model.ActivityStatusRef{ID: oldStatus.ID, Name: oldStatus.Name, Color: oldStatus.Color}
+	}
+	s.recordActivity(ctx, issue.ID, actorID, model.ActivityStatusChanged, payload)
+	return true, nil
+}
+
+// targetState 获取动作对应的目标状态，nil 表示不变更
+func (s *gitIntegrationService) targetState(ctx context.Context, teamID uuid.UUID, settings *GitSettings, action gitAction) (*model.WorkflowState, error) {
+	var (
+		stateID     *uuid.UUID
+		defaultType model.StateType
+	)
+	switch action {
+	case gitActionOpened:
+		stateID, defaultType = settings.PROpenedStateID, model.StateTypeStarted
+	case gitActionMerged:
+		stateID, defaultType = settings.PRMergedStateID, model.StateTypeCompleted
+	case gitActionClosed:
+		stateID = settings.PRClosedStateID
+	default:
+		return nil, nil
+	}
+
+	if stateID != nil {
+		state, err := s.workflowStateStore.GetByID(ctx, *stateID)
+		if err != nil || state.TeamID != teamID {
+			// 配置的状态已被删除，忽略
+			return nil, nil
+		}
+		return state, nil
+	}
+	if defaultType == "" {
+		return nil, nil
+	}
+
+	states, err := s.workflowStateStore.ListByTeamID(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("获取工作流状态失败: %w", err)
+	}
+	for _, state := range states {
+		if state.Type == defaultType {
+			return state, nil
+		}
+	}
+	return nil, nil
+}
+
+// resolveActor 根据 Git 作者匹配系统用户，匹配不到时使用 Issue 创建者
+func (s *gitIntegrationService) resolveActor(ctx context.Context, issue *model.Issue, username, email string) uuid.UUID {
+	if email != "" {
+		if user, err := s.userStore.GetUserByEmail(ctx, email); err == nil && user != nil {
+			return user.ID
+		}
+	}
+	if username != "" {
+		if user, err := s.userStore.GetUserByUsername(ctx, username); err == nil && user != nil {
+			return user.ID
+		}
+	}
+	return issue.CreatedByID
+}
+
+// recordActivity 记录活动，失败只记录日志
+func (s *gitIntegrationService) recordActivity(ctx context.Context, issueID, actorID uuid.UUID, activityType model.ActivityType, payload interface{}) {
+	if s.activityService == nil {
+		return
+	}
+	activity := &model.Activity{IssueID: issueID, Type: activityType, ActorID: actorID}
+	if data, err := json.Marshal(payload); err == nil {
+		activity.Payload = data
+	}
+	if err := s.activityService.RecordActivity(ctx, activity); err != nil {
+		slog.WarnContext(ctx, "记录 Git 活动失败", "issue_id", activity.IssueID, "error", err)
+	}
+}
+
+// =============================================================================
+// 分支名 / 链接 / 设置
+// =============================================================================
+
+// maxBranchSlugLength 分支名中标题部分的最大长度
+const maxBranchSlugLength = 50
+
+// branchSlugPattern 分支名中不允许的字符
+var branchSlugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// BranchName 生成分支名：<用户名>/<标识符>-<标题>，如 alice/eng-123-fix-login
+// 标题中的非 ASCII 字符会被去掉，标题为空时只保留标识符
+func BranchName(username, identifier, title string) string {
+	slug := strings.Trim(branchSlugPattern.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(slug) > maxBranchSlugLength {
+		slug = strings.TrimRight(slug[:maxBranchSlugLength], "-")
+	}
+
+	name := strings.ToLower(identifier)
+	if slug != "" {
+		name += "-" + slug
+	}
+	if username = strings.Trim(branchSlugPattern.ReplaceAllString(strings.ToLower(username), "-"), "-"); username != "" {
+		name = username + "/" + name
+	}
+	return name
+}
+
+// SuggestBranchName 为 Issue 生成建议的分支名（使用当前用户的用户名作为前缀）
+func (s *gitIntegrationService) SuggestBranchName(ctx context.Context, issueID string) (string, error) {
+	issue, err := s.getAccessibleIssue(ctx, issueID)
+	if err != nil {
+		return "", err
+	}
+
+	userID, _ := ctx.Value("user_id").(uuid.UUID)
+	username := ""
+	if user, err := s.userStore.GetUserByID(ctx, userID.String()); err == nil && user != nil {
+		username = user.Username
+	}
+
+	teamKey := ""
+	if issue.Team != nil {
+		teamKey = issue.Team.Key
+	}
+	return BranchName(username, issue.Identifier(teamKey), issue.Title), nil
+}
+
+// ListIssueLinks 获取 Issue 关联的 PR / 提交
+func (s *gitIntegrationService) ListIssueLinks(ctx context.Context, issueID string) ([]model.IssueLink, error) {
+	issue, err := s.getAccessibleIssue(ctx, issueID)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueLinkStore.ListByIssue(ctx, issue.ID)
+}
+
+// getAccessibleIssue 获取当前用户可访问的 Issue（团队成员或管理员）
+func (s *gitIntegrationService) getAccessibleIssue(ctx context.Context, issueID string) (*model.Issue, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+	userRole, _ := ctx.Value("user_role").(model.Role)
+
+	id, err := uuid.Parse(issueID)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 Issue ID")
+	}
+	issue, err := s.issueStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("Issue 不存在")
+	}
+
+	if userRole != model.RoleAdmin && userRole != model.RoleGlobalAdmin {
+		role, err := s.teamMemberStore.GetRole(ctx, issue.TeamID.String(), userID.String())
+		if err != nil || role == "" {
+			return nil, fmt.Errorf("无权限访问此 Issue")
+		}
+	}
+	return issue, nil
+}
+
+// GetGitSettings 获取团队的 Git 集成设置
+func (s *gitIntegrationService) GetGitSettings(ctx context.Context, teamID string) (*GitSettings, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+	userRole, _ := ctx.Value("user_role").(model.Role)
+
+	team, err := s.teamStore.GetByID(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("团队不存在")
+	}
+	if userRole != model.RoleAdmin && userRole != model.RoleGlobalAdmin {
+		role, err := s.teamMemberStore.GetRole(ctx, teamID, userID.String())
+		if err != nil || role == "" {
+			return nil, fmt.Errorf("无权限访问此团队")
+		}
+	}
+
+	return parseGitSettings(team.WorkflowSettings)
+}
+
+// UpdateGitSettings 更新团队的 Git 集成设置（Team Owner 或管理员）
+func (s *gitIntegrationService) UpdateGitSettings(ctx context.Context, teamID string, settings *GitSettings) (*GitSettings, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+	userRole, _ := ctx.Value("user_role").(model.Role)
+
+	team, err := s.teamStore.GetByID(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("团队不存在")
+	}
+	if userRole != model.RoleAdmin && userRole != model.RoleGlobalAdmin {
+		role, err := s.teamMemberStore.GetRole(ctx, teamID, userID.String())
+		if err != nil || role != model.RoleAdmin {
+			return nil, fmt.Errorf("无权限更新此团队")
+		}
+	}
+
+	// 校验配置的状态属于该团队
+	for _, stateID := range []*uuid.UUID{settings.PROpenedStateID, settings.PRMergedStateID, settings.PRClosedStateID} {
+		if stateID == nil {
+			continue
+		}
+		state, err := s.workflowStateStore.GetByID(ctx, *stateID)
+		if err != nil || state.TeamID != team.ID {
+			return nil, fmt.Errorf("无效的工作流状态: %s", stateID)
+		}
+	}
+
+	data, err := mergeGitSettings(team.WorkflowSettings, settings)
+	if err != nil {
+		return nil, err
+	}
+	team.WorkflowSettings = data
+	if err := s.teamStore.Update(ctx, team); err != nil {
+		return nil, fmt.Errorf("更新团队失败: %w", err)
+	}
+	return settings, nil
+}
+
+// parseGitSettings 从 WorkflowSettings 中读取 Git 设置，未配置时返回默认值
+func parseGitSettings(data datatypes.JSON) (*GitSettings, error) {
+	settings := &GitSettings{}
+	if len(data) == 0 {
+		return settings, nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("解析团队工作流设置失败: %w", err)
+	}
+	if git, ok := raw["git"]; ok {
+		if err := json.Unmarshal(git, settings); err != nil {
+			return nil, fmt.Errorf("解析团队 Git 设置失败: %w", err)
+		}
+	}
+	return settings, nil
+}
+
+// mergeGitSettings 将 Git 设置写回 WorkflowSettings，保留其他字段
+func mergeGitSettings(data datatypes.JSON, settings *GitSettings) (datatypes.JSON, error) {
+	raw := make(map[string]json.RawMessage)
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("解析团队工作流设置失败: %w", err)
+		}
+	}
+	git, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("序列化 Git 设置失败: %w", err)
+	}
+	raw["git"] = git
+	merged, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("序列化团队工作流设置失败: %w", err)
+	}
+	return merged, nil
+}
+
+// firstNonEmptyString 返回第一个非空字符串
+func firstNonEmptyString(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/gorm"
+)
+
+// TestGitIntegrationService_Interface 测试 GitIntegrationService 接口定义存在
+func TestGitIntegrationService_Interface(t *testing.T) {
+	var _ GitIntegrationService = (*gitIntegrationService)(nil)
+}
+
+func TestParseIssueIdentifiers(t *testing.T) {
+	tests := []struct {
+		name  string
+		texts []string
+		want  []string
+	}{
+		{"分支名（小写）", []string{"alice/eng-123-fix-login"}, []string{"ENG-123"}},
+		{"PR 标题", []string{"[ENG-7] 修复登录问题"}, []string{"ENG-7"}},
+		{"多个标识符去重", []string{"ENG-1 and ENG-2", "Fixes ENG-1"}, []string{"ENG-1", "ENG-2"}},
+		{"数字开头的 Key 不匹配", []string{"1AB-2"}, nil},
+		{"单字符 Key 不匹配", []string{"A-1"}, nil},
+		{"版本号不匹配", []string{"release 1.2-3"}, nil},
+		{"无标识符", []string{"update readme"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseIssueIdentifiers(tt.texts...)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseIssueIdentifiers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseClosingRefs(t *testing.T) {
+	got := parseClosingRefs("Fixes ENG-1, refs ENG-2\n\nCloses: eng-3")
+	want := map[string]bool{"ENG-1": true, "ENG-3": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseClosingRefs() = %v, want %v", got, want)
+	}
+}
+
+func TestBranchName(t *testing.T) {
+	tests := []struct {
+		name                        string
+		username, identifier, title string
+		want                        string
+	}{
+		{"普通标题", "alice", "ENG-123", "Fix login redirect", "alice/eng-123-fix-login-redirect"},
+		{"特殊字符", "Bob.Smith", "ENG-1", "Add `--dry-run` flag!", "bob-smith/eng-1-add-dry-run-flag"},
+		{"中文标题", "alice", "ENG-2", "修复登录问题", "alice/eng-2"},
+		{"无用户名", "", "ENG-3", "Docs", "eng-3-docs"},
+		{"超长标题截断", "a", "ENG-4", strings.Repeat("word ", 20), "a/eng-4-" + strings.TrimRight(strings.Repeat("word-", 10), "-")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BranchName(tt.username, tt.identifier, tt.title); got != tt.want {
+				t.Errorf("BranchName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitIntegrationService_VerifyGitHubSignature(t *testing.T) {
+	body := []byte(`{"action":"opened"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	valid := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	svc := &gitIntegrationService{githubSecret: "secret"}
+	if err := svc.VerifyGitHubSignature(body, valid); err != nil {
+		t.Errorf("Expected valid signature, got %v", err)
+	}
+	for _, sig := range []string{"", "sha1=abc", "sha256=zz", "sha256=" + strings.Repeat("0", 64)} {
+		if err := svc.VerifyGitHubSignature(body, sig); err == nil || !strings.Contains(err.Error(), "未认证") {
+			t.Errorf("Expected 未认证 error for %q, got %v", sig, err)
+		}
+	}
+
+	disabled := &gitIntegrationService{}
+	if err := disabled.VerifyGitHubSignature(body, valid); err == nil || !strings.Contains(err.Error(), "未启用") {
+		t.Errorf("Expected 未启用 error, got %v", err)
+	}
+}
+
+func TestGitIntegrationService_VerifyGitLabToken(t *testing.T) {
+	svc := &gitIntegrationService{gitlabToken: "token"}
+	if err := svc.VerifyGitLabToken("token"); err != nil {
+		t.Errorf("Expected valid token, got %v", err)
+	}
+	if err := svc.VerifyGitLabToken("wrong"); err == nil {
+		t.Error("Expected error for wrong token")
+	}
+	disabled := &gitIntegrationService{}
+	if err := disabled.VerifyGitLabToken(""); err == nil || !strings.Contains(err.Error(), "未启用") {
+		t.Errorf("Expected 未启用 error, got %v", err)
+	}
+}
+
+func TestMergeGitSettings_PreservesOtherKeys(t *testing.T) {
+	stateID := uuid.New()
+	merged, err := mergeGitSettings([]byte(`{"auto_archive_days":30}`), &GitSettings{PRMergedStateID: &stateID})
+	if err != nil {
+		t.Fatalf("mergeGitSettings() error = %v", err)
+	}
+
+	var raw map[string]json.RawMessage
+	json.Unmarshal(merged, &raw)
+	if string(raw["auto_archive_days"]) != "30" {
+		t.Errorf("Other settings should be preserved, got %s", merged)
+	}
+
+	settings, err := parseGitSettings(merged)
+	if err != nil {
+		t.Fatalf("parseGitSettings() error = %v", err)
+	}
+	if settings.PRMergedStateID == nil || *settings.PRMergedStateID != stateID {
+		t.Errorf("Unexpected settings: %+v", settings)
+	}
+}
+
+func TestGitIntegrationService_HandleGitHubEvent(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupGitFixtures(t, tx)
+
+	prEvent := func(action string, merged bool) []byte {
+		data, _ := json.Marshal(map[string]interface{}{
+			"action": action,
+			"pull_request": map[string]interface{}{
+				"number":   42,
+				"html_url": "https://github.com/acme/app/pull/42",
+				"title":    "Fix login",
+				"merged":   merged,
+				"head":     map[string]interface{}{"ref": "alice/tst-1-fix-login"},
+				"user":     map[string]interface{}{"login": f.username},
+			},
+		})
+		return data
+	}
+	reload := func() *model.Issue {
+		issue, err := f.issueStore.GetByID(f.ctx, f.issue.ID)
+		if err != nil {
+			t.Fatalf("获取 Issue 失败: %v", err)
+		}
+		return issue
+	}
+
+	// PR 打开：关联并移动到进行中
+	result, err := f.service.HandleGitHubEvent(context.Background(), "pull_request", prEvent("opened", false))
+	if err != nil {
+		t.Fatalf("HandleGitHubEvent() error = %v", err)
+	}
+	if result.LinksCreated != 1 || result.Transitions != 1 || len(result.Issues) != 1 || result.Issues[0] != "TST-1" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if issue := reload(); issue.StatusID != f.startedState.ID {
+		t.Errorf("Expected issue in started state, got %v", issue.StatusID)
+	}
+
+	// PR 合并：不重复创建链接，移动到已完成
+	result, err = f.service.HandleGitHubEvent(context.Background(), "pull_request", prEvent("closed", true))
+	if err != nil {
+		t.Fatalf("HandleGitHubEvent() error = %v", err)
+	}
+	if result.LinksCreated != 0 || result.Transitions != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	issue := reload()
+	if issue.StatusID != f.doneState.ID || issue.CompletedAt == nil {
+		t.Errorf("Expected issue completed, got %v", issue.StatusID)
+	}
+
+	// 重新打开不会把已完成的 Issue 拉回进行中
+	result, _ = f.service.HandleGitHubEvent(context.Background(), "pull_request", prEvent("reopened", false))
+	if result.Transitions != 0 {
+		t.Errorf("Reopened PR should not move completed issue back, got %+v", result)
+	}
+
+	links, _ := store.NewIssueLinkStore(tx).ListByIssue(f.ctx, f.issue.ID)
+	if len(links) != 1 || links[0].State != model.IssueLinkStateOpen || links[0].Ref != "#42" {
+		t.Errorf("Unexpected links: %+v", links)
+	}
+
+	var activities []model.Activity
+	tx.Where("issue_id = ?", f.issue.ID).Find(&activities)
+	counts := map[model.ActivityType]int{}
+	for _, a := range activities {
+		counts[a.Type]++
+		if a.ActorID != f.userID {
+			t.Errorf("Expected actor resolved from PR author, got %v", a.ActorID)
+		}
+	}
+	if counts[model.ActivityLinkAdded] != 1 || counts[model.ActivityStatusChanged] != 2 {
+		t.Errorf("Unexpected activities: %v", counts)
+	}
+}
+
+func TestGitIntegrationService_HandleGitLabPush(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupGitFixtures(t, tx)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"ref":     "refs/heads/main",
+		"project": map[string]interface{}{"default_branch": "main"},
+		"commits": []map[string]interface{}{
+			{
+				"id":      "0123456789abcdef",
+				"message": "Fixes TST-1\n\nlong description",
+				"url":     "https://gitlab.com/acme/app/-/commit/0123456789abcdef",
+				"author":  map[string]interface{}{"name": "Someone", "email": "nobody@example.com"},
+			},
+		},
+	})
+
+	result, err := f.service.HandleGitLabEvent(context.Background(), "Push Hook", body)
+	if err != nil {
+		t.Fatalf("HandleGitLabEvent() error = %v", err)
+	}
+	if result.LinksCreated != 1 || result.Transitions != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	links, _ := store.NewIssueLinkStore(tx).ListByIssue(f.ctx, f.issue.ID)
+	if len(links) != 1 || links[0].Type != model.IssueLinkCommit || links[0].Ref != "0123456" || links[0].Title != "Fixes TST-1" {
+		t.Errorf("Unexpected links: %+v", links)
+	}
+}
+
+func TestGitIntegrationService_AmbiguousIdentifier(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupGitFixtures(t, tx)
+
+	// 另一个工作区也有 TST-1
+	other := &model.Workspace{Name: "Other", Slug: "other-" + uuid.New().String()[:8]}
+	if err := tx.Create(other).Error; err != nil {
+		t.Fatalf("创建工作区失败: %v", err)
+	}
+	otherTeam := &model.Team{WorkspaceID: other.ID, Name: "Other", Key: f.team.Key}
+	if err := tx.Create(otherTeam).Error; err != nil {
+		t.Fatalf("创建团队失败: %v", err)
+	}
+	otherIssue := &model.Issue{TeamID: otherTeam.ID, Title: "Other", StatusID: f.status.ID, CreatedByID: f.userID}
+	if err := f.issueStore.Create(f.ctx, otherIssue); err != nil {
+		t.Fatalf("创建 Issue 失败: %v", err)
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"ref":     "refs/heads/feature",
+		"project": map[string]interface{}{"default_branch": "main"},
+		"commits": []map[string]interface{}{
+			{"id": "0123456789abcdef", "message": "Work on TST-1", "url": "https://gitlab.com/acme/app/-/commit/0123456789abcdef"},
+		},
+	})
+
+	// 未指定工作区时不关联，并说明原因
+	result, err := f.service.HandleGitLabEvent(context.Background(), "Push Hook", body)
+	if err != nil {
+		t.Fatalf("HandleGitLabEvent() error = %v", err)
+	}
+	if result.LinksCreated != 0 || !strings.Contains(result.Ignored, "TST-1") || !strings.Contains(result.Ignored, "workspace_id") {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	// 指定工作区后只关联该工作区的 Issue
+	ctx := context.WithValue(context.Background(), "workspace_id", f.workspaceID)
+	result, err = f.service.HandleGitLabEvent(ctx, "Push Hook", body)
+	if err != nil {
+		t.Fatalf("HandleGitLabEvent() error = %v", err)
+	}
+	if result.LinksCreated != 1 || result.Ignored != "" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	links, _ := store.NewIssueLinkStore(tx).ListByIssue(f.ctx, f.issue.ID)
+	if len(links) != 1 {
+		t.Errorf("Expected link on workspace issue, got %+v", links)
+	}
+}
+
+func TestGitIntegrationService_DisableAutoTransition(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupGitFixtures(t, tx)
+
+	if _, err := f.service.UpdateGitSettings(f.ctx, f.team.ID.String(), &GitSettings{DisableAutoTransition: true}); err != nil {
+		t.Fatalf("UpdateGitSettings() error = %v", err)
+	}
+
+	body := []byte(`{"object_attributes":{"iid":3,"url":"https://gitlab.com/acme/app/-/merge_requests/3","title":"TST-1 改进","source_branch":"feature","action":"merge"}}`)
+	result, err := f.service.HandleGitLabEvent(context.Background(), "Merge Request Hook", body)
+	if err != nil {
+		t.Fatalf("HandleGitLabEvent() error = %v", err)
+	}
+	if result.LinksCreated != 1 || result.Transitions != 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
+
+func TestGitIntegrationService_SuggestBranchName(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupGitFixtures(t, tx)
+
+	name, err := f.service.SuggestBranchName(f.ctx, f.issue.ID.String())
+	if err != nil {
+		t.Fatalf("SuggestBranchName() error = %v", err)
+	}
+	if want := strings.ToLower(f.username) + "/tst-1-fix-login"; name != want {
+		t.Errorf("SuggestBranchName() = %v, want %v", name, want)
+	}
+
+	outsider := context.WithValue(context.WithValue(context.Background(), "user_id", f.user2ID), "user_role", model.RoleMember)
+	if _, err := f.service.SuggestBranchName(outsider, f.issue.ID.String()); err == nil || !strings.Contains(err.Error(), "无权限") {
+		t.Errorf("Expected 无权限 error, got %v", err)
+	}
+}
+
+// =============================================================================
+// 测试辅助
+// =============================================================================
+
+type gitFixtures struct {
+	*issueServiceFixtures
+	username     string
+	issue        *model.Issue
+	startedState *model.WorkflowState
+	doneState    *model.WorkflowState
+	service      GitIntegrationService
+}
+
+func setupGitFixtures(t *testing.T, db *gorm.DB) *gitFixtures {
+	base := setupIssueServiceFixtures(t, db)
+
+	startedState := &model.WorkflowState{TeamID: base.team.ID, Name: "In Progress", Type: model.StateTypeStarted, Position: 2}
+	doneState := &model.WorkflowState{TeamID: base.team.ID, Name: "Done", Type: model.StateTypeCompleted, Position: 3}
+	for _, state := range []*model.WorkflowState{startedState, doneState} {
+		if err := db.Create(state).Error; err != nil {
+			t.Fatalf("创建工作流状态失败: %v", err)
+		}
+	}
+
+	issue := &model.Issue{TeamID: base.team.ID, Title: "Fix login", StatusID: base.status.ID, CreatedByID: base.userID}
+	if err := base.issueStore.Create(base.ctx, issue); err != nil {
+		t.Fatalf("创建 Issue 失败: %v", err)
+	}
+
+	var user model.User
+	if err := db.Where("id = ?", base.userID).First(&user).Error; err != nil {
+		t.Fatalf("获取用户失败: %v", err)
+	}
+
+	svc := NewGitIntegrationService(
+		"secret",
+		"token",
+		base.issueStore,
+		store.NewIssueLinkStore(db),
+		store.NewTeamStore(db),
+		store.NewTeamMemberStore(db),
+		store.NewWorkflowStateStore(db),
+		store.NewUserStore(db),
+		NewActivityService(store.NewActivityStore(db)),
+	)
+
+	return &gitFixtures{
+		issueServiceFixtures: base,
+		username:             user.Username,
+		issue:                issue,
+		startedState:         startedState,
+		doneState:            doneState,
+		service:              svc,
+	}
+}
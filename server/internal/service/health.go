@@ -0,0 +1,222 @@
+// Package service 提供业务逻辑层
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// defaultHealthCheckTimeout 单个依赖检查的默认超时时间
+const defaultHealthCheckTimeout = 2 * time.Second
+
+// 依赖和整体状态
+const (
+	// HealthStatusUp 依赖正常
+	HealthStatusUp = "up"
+	// HealthStatusDown 依赖不可用
+	HealthStatusDown = "down"
+
+	// ReadinessOK 所有依赖正常
+	ReadinessOK = "ok"
+	// ReadinessDegraded 关键依赖正常，但部分非关键依赖不可用，相关功能降级
+	ReadinessDegraded = "degraded"
+	// ReadinessError 关键依赖不可用，不应接收流量
+	ReadinessError = "error"
+)
+
+// HealthCheck 单个依赖的就绪检查
+type HealthCheck struct {
+	// Name 依赖名称，作为响应中的键
+	Name string
+	// Critical 关键依赖不可用时就绪检查失败；非关键依赖不可用只标记降级
+	Critical bool
+	// Feature 非关键依赖不可用时受影响的功能说明
+	Feature string
+	// Check 执行检查，可以返回附加信息（如迁移版本）
+	Check func(ctx context.Context) (map[string]interface{}, error)
+}
+
+// DependencyStatus 单个依赖的检查结果
+type DependencyStatus struct {
+	Status    string                 `json:"status"`
+	Critical  bool                   `json:"critical"`
+	LatencyMS int64                  `json:"latency_ms"`
+	Error     string                 `json:"error,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// ReadinessReport 就绪检查结果
+type ReadinessReport struct {
+	Status string `json:"status"`
+	// Degraded 因非关键依赖不可用而降级的功能
+	Degraded []string                     `json:"degraded,omitempty"`
+	Checks   map[string]*DependencyStatus `json:"checks"`
+}
+
+// Ready 是否可以接收流量
+func (r *ReadinessReport) Ready() bool {
+	return r.Status != ReadinessError
+}
+
+// HealthService 定义健康检查服务接口
+type HealthService interface {
+	// Readiness 并发检查所有依赖，每个依赖单独超时
+	Readiness(ctx context.Context) *ReadinessReport
+}
+
+// healthService 实现 HealthService 接口
+type healthService struct {
+	checks  []HealthCheck
+	timeout time.Duration
+}
+
+// NewHealthService 创建健康检查服务，timeout 为 0 时使用默认超时
+func NewHealthService(timeout time.Duration, checks ...HealthCheck) HealthService {
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+	return &healthService{checks: checks, timeout: timeout}
+}
+
+// Readiness 并发检查所有依赖
+func (s *healthService) Readiness(ctx context.Context) *ReadinessReport {
+	results := make([]*DependencyStatus, len(s.checks))
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = s.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := &ReadinessReport{Status: ReadinessOK, Checks: make(map[string]*DependencyStatus, len(s.checks))}
+	for i, check := range s.checks {
+		result := results[i]
+		report.Checks[check.Name] = result
+		if result.Status == HealthStatusUp {
+			continue
+		}
+		if check.Critical {
+			report.Status = ReadinessError
+			continue
+		}
+		if report.Status == ReadinessOK {
+			report.Status = ReadinessDegraded
+		}
+		if check.Feature != "" {
+			report.Degraded = append(report.Degraded, check.Feature)
+		}
+	}
+	return report
+}
+
+// run 在超时时间内执行单个检查
+func (s *healthService) run(ctx context.Context, check HealthCheck) *DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := check.Check(ctx)
+	result := &DependencyStatus{
+		Status:    HealthStatusUp,
+		Critical:  check.Critical,
+		LatencyMS: time.Since(start).Milliseconds(),
+		Details:   details,
+	}
+	if err != nil {
+		result.Status = HealthStatusDown
+		result.Error = err.Error()
+		if errors.Is(err, context.DeadlineExceeded) {
+			result.Error = fmt.Sprintf("检查超时（%s）", s.timeout)
+		}
+	}
+	return result
+}
+
+// DatabaseHealthCheck Postgres 连接检查
+func DatabaseHealthCheck(db *gorm.DB) HealthCheck {
+	return HealthCheck{
+		Name:     "database",
+		Critical: true,
+		Check: func(ctx context.Context) (map[string]interface{}, error) {
+			if db == nil {
+				return nil, errors.New("数据库未连接")
+			}
+			sqlDB, err := db.DB()
+			if err != nil {
+				return nil, err
+			}
+			if err := sqlDB.PingContext(ctx); err != nil {
+				return nil, err
+			}
+			stats := sqlDB.Stats()
+			return map[string]interface{}{
+				"open_connections": stats.OpenConnections,
+				"in_use":           stats.InUse,
+			}, nil
+		},
+	}
+}
+
+// MigrationHealthCheck 读取 golang-migrate 记录的迁移版本，迁移处于 dirty 状态（上次执行中途失败）时视为不可用
+func MigrationHealthCheck(db *gorm.DB) HealthCheck {
+	return HealthCheck{
+		Name:     "migrations",
+		Critical: true,
+		Check: func(ctx context.Context) (map[string]interface{}, error) {
+			if db == nil {
+				return nil, errors.New("数据库未连接")
+			}
+			var state struct {
+				Version int64
+				Dirty   bool
+			}
+			result := db.WithContext(ctx).Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&state)
+			if result.Error != nil {
+				return nil, fmt.Errorf("读取迁移版本失败: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return nil, errors.New("尚未执行数据库迁移")
+			}
+			details := map[string]interface{}{"version": state.Version, "dirty": state.Dirty}
+			if state.Dirty {
+				return details, fmt.Errorf("迁移版本 %d 处于 dirty 状态，需要人工修复", state.Version)
+			}
+			return details, nil
+		},
+	}
+}
+
+// RedisHealthCheck Redis 连接检查；令牌黑名单和登录锁定依赖 Redis，视为关键依赖
+func RedisHealthCheck(rdb *redis.Client) HealthCheck {
+	return HealthCheck{
+		Name:     "redis",
+		Critical: true,
+		Check: func(ctx context.Context) (map[string]interface{}, error) {
+			return nil, rdb.Ping(ctx).Err()
+		},
+	}
+}
+
+// StorageHealthCheck MinIO 检查；不可用时只影响头像上传，不影响就绪
+// avatarService 为 nil 表示启动时初始化失败
+func StorageHealthCheck(avatarService AvatarService) HealthCheck {
+	return HealthCheck{
+		Name:    "storage",
+		Feature: "avatar_upload",
+		Check: func(ctx context.Context) (map[string]interface{}, error) {
+			if avatarService == nil {
+				return nil, errors.New("对象存储未初始化，头像上传不可用")
+			}
+			return nil, avatarService.Ping(ctx)
+		},
+	}
+}
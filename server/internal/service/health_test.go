@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// staticCheck 返回固定结果的依赖检查
+func staticCheck(name string, critical bool, err error) HealthCheck {
+	return HealthCheck{
+		Name:     name,
+		Critical: critical,
+		Feature:  name + "_feature",
+		Check: func(context.Context) (map[string]interface{}, error) {
+			return map[string]interface{}{"name": name}, err
+		},
+	}
+}
+
+func TestHealthService_Readiness(t *testing.T) {
+	tests := []struct {
+		name         string
+		checks       []HealthCheck
+		wantStatus   string
+		wantReady    bool
+		wantDegraded []string
+	}{
+		{
+			name:       "全部正常",
+			checks:     []HealthCheck{staticCheck("database", true, nil), staticCheck("storage", false, nil)},
+			wantStatus: ReadinessOK,
+			wantReady:  true,
+		},
+		{
+			name:         "非关键依赖不可用时降级",
+			checks:       []HealthCheck{staticCheck("database", true, nil), staticCheck("storage", false, errors.New("连接被拒绝"))},
+			wantStatus:   ReadinessDegraded,
+			wantReady:    true,
+			wantDegraded: []string{"storage_feature"},
+		},
+		{
+			name:         "关键依赖不可用",
+			checks:       []HealthCheck{staticCheck("database", true, errors.New("连接被拒绝")), staticCheck("storage", false, errors.New("连接被拒绝"))},
+			wantStatus:   ReadinessError,
+			wantReady:    false,
+			wantDegraded: []string{"storage_feature"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewHealthService(time.Second, tt.checks...).Readiness(context.Background())
+			if report.Status != tt.wantStatus || report.Ready() != tt.wantReady {
+				t.Errorf("Status = %s, Ready = %v", report.Status, report.Ready())
+			}
+			if len(report.Degraded) != len(tt.wantDegraded) {
+				t.Errorf("Degraded = %v, want %v", report.Degraded, tt.wantDegraded)
+			}
+			if len(report.Checks) != len(tt.checks) || report.Checks["database"].Details["name"] != "database" {
+				t.Errorf("Checks = %v", report.Checks)
+			}
+		})
+	}
+}
+
+func TestHealthService_Timeout(t *testing.T) {
+	slow := HealthCheck{
+		Name:     "redis",
+		Critical: true,
+		Check: func(ctx context.Context) (map[string]interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	report := NewHealthService(20*time.Millisecond, slow).Readiness(context.Background())
+	if report.Ready() || report.Checks["redis"].Status != HealthStatusDown || report.Checks["redis"].Error == "" {
+		t.Errorf("超时的关键依赖应视为不可用: %+v", report.Checks["redis"])
+	}
+}
+
+func TestDatabaseHealthCheck(t *testing.T) {
+	if _, err := DatabaseHealthCheck(testSvcDB).Check(context.Background()); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+	if _, err := DatabaseHealthCheck(nil).Check(context.Background()); err == nil {
+		t.Error("未连接数据库时应返回错误")
+	}
+}
+
+func TestStorageHealthCheck_NotInitialized(t *testing.T) {
+	check := StorageHealthCheck(nil)
+	if check.Critical {
+		t.Error("对象存储不应是关键依赖")
+	}
+	if _, err := check.Check(context.Background()); err == nil {
+		t.Error("未初始化时应返回错误")
+	}
+}
@@ -0,0 +1,569 @@
+// Package service 提供业务逻辑层
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/importer"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+)
+
+// JobTypeExternalImport 外部系统导入任务类型
+const JobTypeExternalImport = "external_import"
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	// StateMapping 外部状态名 -> 本地状态名
+	StateMapping map[string]string `json:"state_mapping,omitempty"`
+	// UserMapping 外部用户标识 -> 本地用户邮箱或用户名
+	UserMapping map[string]string `json:"user_mapping,omitempty"`
+}
+
+// ImportParams 导入参数
+type ImportParams struct {
+	TeamID   string
+	Source   string
+	Filename string
+	File     io.Reader
+	Options  ImportOptions
+}
+
+// ImportReport 导入结果
+type ImportReport struct {
+	IssuesCreated   int               `json:"issues_created"`
+	IssuesSkipped   int               `json:"issues_skipped"`
+	CommentsCreated int               `json:"comments_created"`
+	CommentsSkipped int               `json:"comments_skipped"`
+	LabelsCreated   int               `json:"labels_created"`
+	ParentLinks     int               `json:"parent_links"`
+	StateMapping    map[string]string `json:"state_mapping"`
+	UnmappedUsers   []string          `json:"unmapped_users"`
+	Errors          []string          `json:"errors"`
+}
+
+// ImportService 定义外部系统导入服务接口
+type ImportService interface {
+	// StartImport 保存上传文件并创建导入任务
+	StartImport(ctx context.Context, params *ImportParams) (*model.Job, error)
+}
+
+// externalImportPayload 导入任务参数
+type externalImportPayload struct {
+	TeamID   string        `json:"team_id"`
+	Source   string        `json:"source"`
+	FilePath string        `json:"file_path"`
+	Options  ImportOptions `json:"options"`
+}
+
+// importService 实现 ImportService 接口
+type importService struct {
+	uploadDir          string
+	teamStore          store.TeamStore
+	teamMemberStore    store.TeamMemberStore
+	workflowStateStore store.WorkflowStateStore
+	labelStore         store.LabelStore
+	userStore          store.UserStore
+	issueStore         store.IssueStore
+	refStore           store.ExternalReferenceStore
+	jobService         JobService
+}
+
+// NewImportService 创建导入服务实例，并注册导入任务处理函数
+func NewImportService(
+	uploadDir string,
+	teamStore store.TeamStore,
+	teamMemberStore store.TeamMemberStore,
+	workflowStateStore store.WorkflowStateStore,
+	labelStore store.LabelStore,
+	userStore store.UserStore,
+	issueStore store.IssueStore,
+	refStore store.ExternalReferenceStore,
+	jobService JobService,
+) ImportService {
+	s := &importService{
+		uploadDir:          uploadDir,
+		teamStore:          teamStore,
+		teamMemberStore:    teamMemberStore,
+		workflowStateStore: workflowStateStore,
+		labelStore:         labelStore,
+		userStore:          userStore,
+		issueStore:         issueStore,
+		refStore:           refStore,
+		jobService:         jobService,
+	}
+	if jobService != nil {
+		jobService.RegisterHandler(JobTypeExternalImport, s.runImportJob)
+	}
+	return s
+}
+
+// StartImport 保存上传文件并创建导入任务
+func (s *importService) StartImport(ctx context.Context, params *ImportParams) (*model.Job, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+	userRole, _ := ctx.Value("user_role").(model.Role)
+
+	source := importer.Source(params.Source)
+	if !source.Valid() {
+		return nil, fmt.Errorf("无效的导入来源: %s", params.Source)
+	}
+
+	if _, err := uuid.Parse(params.TeamID); err != nil {
+		return nil, fmt.Errorf("无效的团队 ID")
+	}
+	team, err := s.teamStore.GetByID(ctx, params.TeamID)
+	if err != nil {
+		return nil, fmt.Errorf("团队不存在")
+	}
+
+	// 导入会批量写入数据，仅限团队 Owner 或工作区管理员
+	if userRole != model.RoleAdmin && userRole != model.RoleGlobalAdmin {
+		role, _ := s.teamMemberStore.GetRole(ctx, params.TeamID, userID.String())
+		if role != model.RoleAdmin {
+			return nil, fmt.Errorf("无权限导入数据到此团队")
+		}
+	}
+
+	if s.jobService == nil {
+		return nil, fmt.Errorf("后台任务服务不可用")
+	}
+
+	filePath, err := s.saveUpload(params.Filename, params.File)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先解析一遍，文件无效时直接返回错误而不是创建注定失败的任务
+	dataset, err := parseImportFile(source, filePath)
+	if err != nil {
+		_ = os.Remove(filePath)
+		return nil, err
+	}
+	if len(dataset.Issues) == 0 {
+		_ = os.Remove(filePath)
+		return nil, fmt.Errorf("无效的导入文件: 没有可导入的 Issue")
+	}
+
+	workspaceID := team.WorkspaceID
+	job := &model.Job{
+		WorkspaceID: &workspaceID,
+		Type:        JobTypeExternalImport,
+		Total:       len(dataset.Issues),
+	}
+	payload := &externalImportPayload{
+		TeamID:   team.ID.String(),
+		Source:   string(source),
+		FilePath: filePath,
+		Options:  params.Options,
+	}
+	if err := s.jobService.Enqueue(ctx, job, payload); err != nil {
+		_ = os.Remove(filePath)
+		return nil, fmt.Errorf("创建导入任务失败: %w", err)
+	}
+
+	return job, nil
+}
+
+// saveUpload 将上传文件保存到导入目录
+func (s *importService) saveUpload(filename string, r io.Reader) (string, error) {
+	if err := os.MkdirAll(s.uploadDir, 0o750); err != nil {
+		return "", fmt.Errorf("创建导入目录失败: %w", err)
+	}
+
+	path := filepath.Join(s.uploadDir, uuid.New().String()+strings.ToLower(filepath.Ext(filename)))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o640)
+	if err != nil {
+		return "", fmt.Errorf("保存导入文件失败: %w", err)
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		_ = os.Remove(path)
+		return "", fmt.Errorf("保存导入文件失败: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(path)
+		return "", fmt.Errorf("保存导入文件失败: %w", err)
+	}
+	return path, nil
+}
+
+// parseImportFile 解析导入文件
+func parseImportFile(source importer.Source, path string) (*importer.Dataset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开导入文件失败: %w", err)
+	}
+	defer f.Close()
+	return importer.Parse(source, f)
+}
+
+// runImportJob 执行导入任务
+//
+// 已导入的 Issue 和评论通过外部引用跳过，因此任务中断（服务重启）后重新执行
+// 会从上次的位置继续，重复上传同一文件也不会产生重复数据。
+func (s *importService) runImportJob(ctx context.Context, job *model.Job, progress JobProgressFunc) (interface{}, error) {
+	var payload externalImportPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("解析任务参数失败: %w", err)
+	}
+	if job.CreatedByID == nil {
+		return nil, fmt.Errorf("任务缺少创建者")
+	}
+
+	team, err := s.teamStore.GetByID(ctx, payload.TeamID)
+	if err != nil {
+		return nil, fmt.Errorf("团队不存在")
+	}
+
+	dataset, err := parseImportFile(importer.Source(payload.Source), payload.FilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := s.newImportRun(ctx, team, dataset, payload.Options, *job.CreatedByID)
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(dataset.Issues)
+	for i := range dataset.Issues {
+		if err := ctx.Err(); err != nil {
+			// 服务关闭时任务标记为失败；重新导入时按外部引用跳过已导入的 Issue
+			return run.report, err
+		}
+		run.importIssue(ctx, &dataset.Issues[i])
+		progress(i+1, total)
+	}
+	run.linkParents(ctx, dataset.Issues)
+
+	// 导入成功后删除上传文件
+	if err := os.Remove(payload.FilePath); err != nil && !os.IsNotExist(err) {
+		slog.WarnContext(ctx, "删除导入文件失败", "path", payload.FilePath, "error", err)
+	}
+
+	return run.report, nil
+}
+
+// importRun 单次导入的上下文，缓存状态、标签和用户的映射
+type importRun struct {
+	s         *importService
+	team      *model.Team
+	source    string
+	creatorID uuid.UUID
+	options   ImportOptions
+
+	states       []*model.WorkflowState
+	defaultState *model.WorkflowState
+	stateByExt   map[string]*model.WorkflowState
+	labels       map[string]*model.Label
+	users        map[string]*uuid.UUID
+	extUsers     map[string]importer.User
+	issueIDs     map[string]uuid.UUID
+
+	report *ImportReport
+}
+
+// newImportRun 加载团队数据并创建导入上下文
+func (s *importService) newImportRun(ctx context.Context, team *model.Team, dataset *importer.Dataset, options ImportOptions, creatorID uuid.UUID) (*importRun, error) {
+	states, err := s.workflowStateStore.ListByTeamID(ctx, team.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取工作流状态失败: %w", err)
+	}
+	if len(states) == 0 {
+		return nil, fmt.Errorf("团队没有可用的工作流状态")
+	}
+	labels, err := s.labelStore.ListForTeam(ctx, team.WorkspaceID, team.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取标签列表失败: %w", err)
+	}
+
+	run := &importRun{
+		s:          s,
+		team:       team,
+		source:     string(dataset.Source),
+		creatorID:  creatorID,
+		options:    options,
+		states:     states,
+		stateByExt: make(map[string]*model.WorkflowState),
+		labels:     make(map[string]*model.Label, len(labels)),
+		users:      make(map[string]*uuid.UUID),
+		extUsers:   make(map[string]importer.User, len(dataset.Users)),
+		issueIDs:   make(map[string]uuid.UUID, len(dataset.Issues)),
+		report: &ImportReport{
+			StateMapping:  make(map[string]string),
+			UnmappedUsers: []string{},
+			Errors:        []string{},
+		},
+	}
+
+	for _, st := range states {
+		if st.IsDefault && run.defaultState == nil {
+			run.defaultState = st
+		}
+	}
+	if run.defaultState == nil {
+		run.defaultState = states[0]
+	}
+	for _, l := range labels {
+		key := strings.ToLower(l.Name)
+		if existing, ok := run.labels[key]; ok && existing.TeamID != nil {
+			continue
+		}
+		run.labels[key] = l
+	}
+	for _, u := range dataset.Users {
+		run.extUsers[u.Ref] = u
+	}
+	for _, st := range dataset.States {
+		local := run.mapState(st)
+		run.stateByExt[strings.ToLower(st.Name)] = local
+		run.report.StateMapping[st.Name] = local.Name
+	}
+
+	return run, nil
+}
+
+// mapState 将外部状态映射到本地状态：显式映射 > 同名状态 > 同类型的第一个状态 > 默认状态
+func (r *importRun) mapState(st importer.State) *model.WorkflowState {
+	name := st.Name
+	if mapped, ok := r.options.StateMapping[st.Name]; ok {
+		name = mapped
+	}
+	for _, local := range r.states {
+		if strings.EqualFold(local.Name, name) {
+			return local
+		}
+	}
+	for _, local := range r.states {
+		if local.Type == st.Type {
+			return local
+		}
+	}
+	return r.defaultState
+}
+
+// stateFor 获取 Issue 对应的本地状态
+func (r *importRun) stateFor(issue *importer.Issue) *model.WorkflowState {
+	if local, ok := r.stateByExt[strings.ToLower(issue.State)]; ok {
+		return local
+	}
+	return r.mapState(importer.State{Name: issue.State, Type: issue.StateType})
+}
+
+// userFor 将外部用户映射到工作区内的本地用户：显式映射 > 邮箱 > 用户名，找不到时返回 nil
+func (r *importRun) userFor(ctx context.Context, ref string) *uuid.UUID {
+	if ref == "" {
+		return nil
+	}
+	if id, ok := r.users[ref]; ok {
+		return id
+	}
+
+	var candidates []string
+	if mapped, ok := r.options.UserMapping[ref]; ok {
+		candidates = append(candidates, mapped)
+	}
+	if ext, ok := r.extUsers[ref]; ok {
+		candidates = append(candidates, ext.Email, ext.Username)
+	}
+	candidates = append(candidates, ref)
+
+	var found *uuid.UUID
+	for _, c := range candidates {
+		if c == "" {
+			continue
+		}
+		// 只匹配团队所属工作区的成员
+		user, err := r.s.userStore.GetWorkspaceUser(ctx, r.team.WorkspaceID, c)
+		if err == nil && user != nil {
+			found = &user.ID
+			break
+		}
+	}
+
+	r.users[ref] = found
+	if found == nil {
+		r.report.UnmappedUsers = append(r.report.UnmappedUsers, ref)
+	}
+	return found
+}
+
+// labelIDsFor 获取标签 ID，团队中不存在的标签会自动创建
+func (r *importRun) labelIDsFor(ctx context.Context, names []string) []string {
+	var ids []string
+	for _, name := range names {
+		key := strings.ToLower(name)
+		label, ok := r.labels[key]
+		if !ok {
+			teamID := r.team.ID
+			label = &model.Label{
+				WorkspaceID: r.team.WorkspaceID,
+				TeamID:      &teamID,
+				Name:        name,
+			}
+			if err := r.s.labelStore.Create(ctx, label); err != nil {
+				r.report.Errors = append(r.report.Errors, fmt.Sprintf("创建标签 %s 失败: %v", name, err))
+				continue
+			}
+			r.labels[key] = label
+			r.report.LabelsCreated++
+		}
+		ids = append(ids, label.ID.String())
+	}
+	return ids
+}
+
+// importIssue 导入单个 Issue 及其评论
+func (r *importRun) importIssue(ctx context.Context, ext *importer.Issue) {
+	issueID, err := r.ensureIssue(ctx, ext)
+	if err != nil {
+		r.report.Errors = append(r.report.Errors, fmt.Sprintf("%s: %v", ext.ExternalID, err))
+		return
+	}
+	r.issueIDs[ext.ExternalID] = issueID
+
+	for _, c := range ext.Comments {
+		if err := r.ensureComment(ctx, issueID, &c); err != nil {
+			r.report.Errors = append(r.report.Errors, fmt.Sprintf("%s 评论 %s: %v", ext.ExternalID, c.ExternalID, err))
+		}
+	}
+}
+
+// ensureIssue 创建 Issue；已导入过的直接返回已有 ID
+func (r *importRun) ensureIssue(ctx context.Context, ext *importer.Issue) (uuid.UUID, error) {
+	ref, err := r.s.refStore.Find(ctx, r.team.ID, r.source, model.ExternalEntityIssue, ext.ExternalID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if ref != nil {
+		r.report.IssuesSkipped++
+		return ref.EntityID, nil
+	}
+
+	title := ext.Title
+	if title == "" {
+		title = ext.ExternalID
+	}
+	if runes := []rune(title); len(runes) > 500 {
+		title = string(runes[:500])
+	}
+
+	issue := &model.Issue{
+		TeamID:      r.team.ID,
+		Title:       title,
+		StatusID:    r.stateFor(ext).ID,
+		Priority:    ext.Priority,
+		AssigneeID:  r.userFor(ctx, ext.AssigneeRef),
+		Labels:      r.labelIDsFor(ctx, ext.Labels),
+		DueDate:     ext.DueDate,
+		CompletedAt: ext.CompletedAt,
+		CancelledAt: ext.CanceledAt,
+		CreatedByID: r.creatorID,
+	}
+	if ext.Description != "" {
+		desc := ext.Description
+		issue.Description = &desc
+	}
+	if creator := r.userFor(ctx, ext.CreatorRef); creator != nil {
+		issue.CreatedByID = *creator
+	}
+	// 保留原始创建和更新时间
+	issue.CreatedAt = ext.CreatedAt
+	issue.UpdatedAt = ext.UpdatedAt
+	if issue.UpdatedAt.IsZero() {
+		issue.UpdatedAt = issue.CreatedAt
+	}
+
+	newRef := &model.ExternalReference{
+		TeamID:     r.team.ID,
+		Source:     r.source,
+		ExternalID: ext.ExternalID,
+	}
+	if err := r.s.refStore.CreateIssue(ctx, issue, newRef); err != nil {
+		return uuid.Nil, err
+	}
+
+	r.report.IssuesCreated++
+	return issue.ID, nil
+}
+
+// ensureComment 创建评论；已导入过的跳过
+func (r *importRun) ensureComment(ctx context.Context, issueID uuid.UUID, ext *importer.Comment) error {
+	if strings.TrimSpace(ext.Body) == "" {
+		return nil
+	}
+
+	ref, err := r.s.refStore.Find(ctx, r.team.ID, r.source, model.ExternalEntityComment, ext.ExternalID)
+	if err != nil {
+		return err
+	}
+	if ref != nil {
+		r.report.CommentsSkipped++
+		return nil
+	}
+
+	comment := &model.Comment{
+		IssueID:   issueID,
+		UserID:    r.creatorID,
+		Body:      ext.Body,
+		CreatedAt: ext.CreatedAt,
+		UpdatedAt: ext.CreatedAt,
+	}
+	if author := r.userFor(ctx, ext.AuthorRef); author != nil {
+		comment.UserID = *author
+	} else if ext.AuthorRef != "" {
+		// 找不到对应用户时以导入者身份创建，并保留原作者
+		comment.Body = fmt.Sprintf("> 原作者: %s\n\n%s", ext.AuthorRef, ext.Body)
+	}
+
+	newRef := &model.ExternalReference{
+		TeamID:     r.team.ID,
+		Source:     r.source,
+		ExternalID: ext.ExternalID,
+	}
+	if err := r.s.refStore.CreateComment(ctx, comment, newRef); err != nil {
+		return err
+	}
+
+	r.report.CommentsCreated++
+	return nil
+}
+
+// linkParents 在所有 Issue 导入后建立父子关系
+func (r *importRun) linkParents(ctx context.Context, issues []importer.Issue) {
+	for _, ext := range issues {
+		if ext.ParentExternalID == "" {
+			continue
+		}
+		childID, ok := r.issueIDs[ext.ExternalID]
+		if !ok {
+			continue
+		}
+		parentID, ok := r.issueIDs[ext.ParentExternalID]
+		if !ok {
+			// 父 Issue 可能在之前的导入中创建
+			ref, err := r.s.refStore.Find(ctx, r.team.ID, r.source, model.ExternalEntityIssue, ext.ParentExternalID)
+			if err != nil || ref == nil {
+				r.report.Errors = append(r.report.Errors, fmt.Sprintf("%s: 父 Issue %s 不存在", ext.ExternalID, ext.ParentExternalID))
+				continue
+			}
+			parentID = ref.EntityID
+		}
+		if err := r.s.issueStore.UpdateParent(ctx, childID, &parentID); err != nil {
+			r.report.Errors = append(r.report.Errors, fmt.Sprintf("%s: 设置父 Issue 失败: %v", ext.ExternalID, err))
+			continue
+		}
+		r.report.ParentLinks++
+	}
+}
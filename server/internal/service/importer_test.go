@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liwei0526vip/mylinear/internal/importer"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/gorm"
+)
+
+// TestImportService_Interface 测试 ImportService 接口定义存在
+func TestImportService_Interface(t *testing.T) {
+	var _ ImportService = (*importService)(nil)
+}
+
+func TestImportService_StartImport(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupImportFixtures(t, tx)
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		source      string
+		data        string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:   "创建导入任务",
+			ctx:    f.ctx,
+			source: string(importer.SourceLinearCSV),
+			data:   "ID,Title\nABC-1,第一条\n",
+		},
+		{
+			name:        "无效来源",
+			ctx:         f.ctx,
+			source:      "trello",
+			data:        "ID,Title\nABC-1,第一条\n",
+			wantErr:     true,
+			errContains: "无效的导入来源",
+		},
+		{
+			name:        "无法解析的文件",
+			ctx:         f.ctx,
+			source:      string(importer.SourceGitHubJSON),
+			data:        "not json",
+			wantErr:     true,
+			errContains: "无效",
+		},
+		{
+			name: "非团队 Owner 无权限",
+			ctx: func() context.Context {
+				ctx := context.WithValue(context.Background(), "user_id", f.user2ID)
+				return context.WithValue(ctx, "user_role", model.RoleMember)
+			}(),
+			source:      string(importer.SourceLinearCSV),
+			data:        "ID,Title\nABC-1,第一条\n",
+			wantErr:     true,
+			errContains: "无权限",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, err := f.service.StartImport(tt.ctx, &ImportParams{
+				TeamID:   f.team.ID.String(),
+				Source:   tt.source,
+				Filename: "export.csv",
+				File:     strings.NewReader(tt.data),
+			})
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("StartImport() error = %v", err)
+			}
+			if job.Type != JobTypeExternalImport || job.Total != 1 {
+				t.Errorf("Unexpected job: %+v", job)
+			}
+		})
+	}
+}
+
+func TestImportService_RunImportJob_Idempotent(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupImportFixtures(t, tx)
+
+	data := "ID,Title,Status,Priority,Creator,Assignee,Labels,Created,Completed,Parent issue\n" +
+		"ABC-1,父任务,Todo,High," + f.username + ",nobody,\"feature, infra\",2022-05-01T08:00:00.000Z,,\n" +
+		"ABC-2,子任务,Done,Low,,,,2022-05-02T08:00:00.000Z,2022-05-03T08:00:00.000Z,ABC-1\n"
+
+	svc := f.service.(*importService)
+	run := func() *ImportReport {
+		job, err := f.service.StartImport(f.ctx, &ImportParams{
+			TeamID:   f.team.ID.String(),
+			Source:   string(importer.SourceLinearCSV),
+			Filename: "linear.csv",
+			File:     strings.NewReader(data),
+		})
+		if err != nil {
+			t.Fatalf("StartImport() error = %v", err)
+		}
+		stored, err := f.jobStore.GetByID(f.ctx, job.ID)
+		if err != nil {
+			t.Fatalf("获取任务失败: %v", err)
+		}
+		result, err := svc.runImportJob(f.ctx, stored, func(done, total int) {})
+		if err != nil {
+			t.Fatalf("runImportJob() error = %v", err)
+		}
+		return result.(*ImportReport)
+	}
+
+	first := run()
+	if first.IssuesCreated != 2 || first.ParentLinks != 1 || first.LabelsCreated != 2 {
+		t.Errorf("Unexpected first report: %+v", first)
+	}
+	if len(first.UnmappedUsers) != 1 || first.UnmappedUsers[0] != "nobody" {
+		t.Errorf("Expected unmapped user nobody, got %v", first.UnmappedUsers)
+	}
+
+	var issues []model.Issue
+	tx.Where("team_id = ?", f.team.ID).Order("number ASC").Find(&issues)
+	if len(issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %d", len(issues))
+	}
+	if !issues[0].CreatedAt.Equal(time.Date(2022, 5, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("Original creation time not preserved: %v", issues[0].CreatedAt)
+	}
+	if issues[0].CreatedByID != f.userID || issues[0].Priority != model.PriorityHigh {
+		t.Errorf("Unexpected first issue: %+v", issues[0])
+	}
+	if issues[1].ParentID == nil || *issues[1].ParentID != issues[0].ID {
+		t.Error("Parent link not created")
+	}
+	if issues[1].StatusID != f.doneState.ID || issues[1].CompletedAt == nil {
+		t.Error("Done issue should map to the completed state")
+	}
+
+	// 再次导入同一文件不应产生重复数据
+	second := run()
+	if second.IssuesCreated != 0 || second.IssuesSkipped != 2 {
+		t.Errorf("Re-run should skip existing issues, got %+v", second)
+	}
+	var count int64
+	tx.Model(&model.Issue{}).Where("team_id = ?", f.team.ID).Count(&count)
+	if count != 2 {
+		t.Errorf("Expected 2 issues after re-run, got %d", count)
+	}
+}
+
+// =============================================================================
+// 测试辅助
+// =============================================================================
+
+type importFixtures struct {
+	*issueServiceFixtures
+	username  string
+	doneState *model.WorkflowState
+	jobStore  store.JobStore
+	service   ImportService
+}
+
+func setupImportFixtures(t *testing.T, db *gorm.DB) *importFixtures {
+	base := setupIssueServiceFixtures(t, db)
+
+	doneState := &model.WorkflowState{
+		TeamID:   base.team.ID,
+		Name:     "Completed",
+		Type:     model.StateTypeCompleted,
+		Position: 10,
+	}
+	if err := db.Create(doneState).Error; err != nil {
+		t.Fatalf("创建工作流状态失败: %v", err)
+	}
+
+	var user model.User
+	if err := db.Where("id = ?", base.userID).First(&user).Error; err != nil {
+		t.Fatalf("获取用户失败: %v", err)
+	}
+
+	jobStore := store.NewJobStore(db)
+	svc := NewImportService(
+		t.TempDir(),
+		store.NewTeamStore(db),
+		store.NewTeamMemberStore(db),
+		store.NewWorkflowStateStore(db),
+		store.NewLabelStore(db),
+		store.NewUserStore(db),
+		base.issueStore,
+		store.NewExternalReferenceStore(db),
+		NewJobService(jobStore),
+	)
+
+	return &importFixtures{
+		issueServiceFixtures: base,
+		username:             user.Username,
+		doneState:            doneState,
+		jobStore:             jobStore,
+		service:              svc,
+	}
+}
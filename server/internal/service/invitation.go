@@ -0,0 +1,562 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/mail"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/gorm"
+)
+
+const (
+	// invitationTokenBytes 邀请令牌随机部分的字节数
+	invitationTokenBytes = 32
+	// invitationMaxExpiry 邀请的最长有效期
+	invitationMaxExpiry = 30 * 24 * time.Hour
+	// invitationSendTimeout 发送邀请邮件的超时时间
+	invitationSendTimeout = 30 * time.Second
+)
+
+// emailDomainRegex 邮箱域名格式
+var emailDomainRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)+$`)
+
+// ErrInvalidInvitation 邀请不存在、已撤销、已过期或已被接受
+var ErrInvalidInvitation = errors.New("邀请无效或已过期")
+
+// ErrRegistrationNotAllowed 邮箱未被邀请且域名不在允许注册的范围内
+var ErrRegistrationNotAllowed = errors.New("无权限注册: 该邮箱未被邀请")
+
+// CreateInvitationParams 创建邀请参数
+type CreateInvitationParams struct {
+	// Email 为空时创建邀请链接
+	Email   string
+	Role    model.Role
+	TeamIDs []string
+	// ExpiresIn 有效期，为 0 时使用配置的默认值
+	ExpiresIn time.Duration
+}
+
+// CreatedInvitation 新建或重新发送的邀请，Token 和 URL 只在此时返回
+type CreatedInvitation struct {
+	*model.Invitation
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+// InvitationPreview 接受邀请页面展示的信息
+type InvitationPreview struct {
+	WorkspaceID   uuid.UUID  `json:"workspace_id"`
+	WorkspaceName string     `json:"workspace_name"`
+	Email         string     `json:"email,omitempty"`
+	Role          model.Role `json:"role"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+}
+
+// RegistrationGrant 注册许可：新用户加入的工作区和角色
+type RegistrationGrant struct {
+	WorkspaceID uuid.UUID
+	Role        model.Role
+	// Bootstrap 系统中还没有任何用户，注册用户成为管理员
+	Bootstrap  bool
+	invitation *model.Invitation
+}
+
+// InvitationService 定义工作区邀请服务接口
+type InvitationService interface {
+	// CreateInvitation 邀请邮箱或生成邀请链接（仅管理员）；指定邮箱时发送邀请邮件
+	CreateInvitation(ctx context.Context, params *CreateInvitationParams) (*CreatedInvitation, error)
+	// ListInvitations 获取当前工作区待接受的邀请（仅管理员）
+	ListInvitations(ctx context.Context) ([]model.Invitation, error)
+	// ResendInvitation 更换令牌、重新计算有效期并重新发送邀请邮件，旧链接失效（仅管理员）
+	ResendInvitation(ctx context.Context, id string) (*CreatedInvitation, error)
+	// RevokeInvitation 撤销邀请（仅管理员）
+	RevokeInvitation(ctx context.Context, id string) error
+	// GetInvitation 根据邀请令牌获取邀请信息（公开）
+	GetInvitation(ctx context.Context, token string) (*InvitationPreview, error)
+	// AuthorizeRegistration 检查邮箱能否注册，返回加入的工作区和角色
+	// 提供邀请令牌时使用该邀请，否则依次检查：工作区尚无成员、邮箱有待接受的邀请、邮箱域名在允许范围内
+	AuthorizeRegistration(ctx context.Context, workspaceID uuid.UUID, email, token string) (*RegistrationGrant, error)
+	// CompleteRegistration 用户创建后接受邀请并加入邀请指定的团队
+	CompleteRegistration(ctx context.Context, grant *RegistrationGrant, user *model.User) error
+	// AcceptInvitation 已登录的用户接受邀请，以邀请中的角色加入工作区和团队
+	AcceptInvitation(ctx context.Context, token string) (*model.WorkspaceMember, error)
+}
+
+// invitationService 实现 InvitationService 接口
+type invitationService struct {
+	invitationStore      store.InvitationStore
+	userStore            store.UserStore
+	workspaceStore       store.WorkspaceStore
+	workspaceMemberStore store.WorkspaceMemberStore
+	teamStore            store.TeamStore
+	teamMemberStore      store.TeamMemberStore
+	sender               mail.Sender
+	cfg                  *config.Config
+}
+
+// NewInvitationService 创建工作区邀请服务实例
+func NewInvitationService(invitationStore store.InvitationStore, userStore store.UserStore, workspaceStore store.WorkspaceStore, workspaceMemberStore store.WorkspaceMemberStore, teamStore store.TeamStore, teamMemberStore store.TeamMemberStore, sender mail.Sender, cfg *config.Config) InvitationService {
+	return &invitationService{
+		invitationStore:      invitationStore,
+		userStore:            userStore,
+		workspaceStore:       workspaceStore,
+		workspaceMemberStore: workspaceMemberStore,
+		teamStore:            teamStore,
+		teamMemberStore:      teamMemberStore,
+		sender:               sender,
+		cfg:                  cfg,
+	}
+}
+
+// CreateInvitation 邀请邮箱或生成邀请链接
+func (s *invitationService) CreateInvitation(ctx context.Context, params *CreateInvitationParams) (*CreatedInvitation, error) {
+	admin, err := s.currentAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	workspaceID := activeWorkspaceID(ctx, admin)
+
+	email := NormalizeEmail(params.Email)
+	if email != "" {
+		if !emailRegex.MatchString(email) {
+			return nil, fmt.Errorf("邮箱格式无效")
+		}
+		// 其他工作区的用户可以被邀请，登录后接受邀请加入
+		if user, err := s.userStore.GetUserByEmail(ctx, email); err == nil {
+			member, err := s.isMember(ctx, workspaceID, user)
+			if err != nil {
+				return nil, err
+			}
+			if member {
+				return nil, fmt.Errorf("该邮箱的用户已存在于此工作区")
+			}
+		}
+	}
+
+	role := params.Role
+	if role == "" {
+		role = model.RoleMember
+	}
+	// 全局管理员不能通过邀请授予
+	if role != model.RoleAdmin && role != model.RoleMember && role != model.RoleGuest {
+		return nil, fmt.Errorf("无效的角色: %s", role)
+	}
+
+	teamIDs, err := s.validateTeams(ctx, workspaceID, params.TeamIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresIn := params.ExpiresIn
+	if expiresIn == 0 {
+		expiresIn = s.cfg.InvitationExpiry
+	}
+	if expiresIn < 0 || expiresIn > invitationMaxExpiry {
+		return nil, fmt.Errorf("无效的有效期: 最长 30 天")
+	}
+
+	token, tokenHash, err := generateInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// 同一邮箱只保留最新的邀请
+	if email != "" {
+		if err := s.invitationStore.RevokePendingByEmail(ctx, workspaceID, email); err != nil {
+			return nil, err
+		}
+	}
+	invitation := &model.Invitation{
+		WorkspaceID: workspaceID,
+		Email:       email,
+		Role:        role,
+		TeamIDs:     teamIDs,
+		TokenHash:   tokenHash,
+		InvitedByID: &admin.ID,
+		ExpiresAt:   time.Now().Add(expiresIn),
+	}
+	if err := s.invitationStore.Create(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	created := &CreatedInvitation{Invitation: invitation, Token: token, URL: s.invitationURL(token)}
+	if !invitation.IsLink() {
+		s.send(ctx, admin, created)
+	}
+	return created, nil
+}
+
+// ListInvitations 获取当前工作区待接受的邀请
+func (s *invitationService) ListInvitations(ctx context.Context) ([]model.Invitation, error) {
+	admin, err := s.currentAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.invitationStore.ListPending(ctx, activeWorkspaceID(ctx, admin))
+}
+
+// ResendInvitation 更换令牌、重新计算有效期并重新发送邀请邮件
+func (s *invitationService) ResendInvitation(ctx context.Context, id string) (*CreatedInvitation, error) {
+	admin, err := s.currentAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	invitation, err := s.getInvitation(ctx, activeWorkspaceID(ctx, admin), id)
+	if err != nil {
+		return nil, err
+	}
+	if invitation.IsLink() {
+		return nil, fmt.Errorf("无效的操作: 邀请链接没有收件人，不能重新发送")
+	}
+	if invitation.RevokedAt != nil || invitation.AcceptedAt != nil {
+		return nil, fmt.Errorf("无效的操作: 邀请已撤销或已被接受")
+	}
+
+	token, tokenHash, err := generateInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+	// 有效期按原邀请的时长重新计算
+	expiresIn := invitation.ExpiresAt.Sub(invitation.CreatedAt)
+	if expiresIn <= 0 || expiresIn > invitationMaxExpiry {
+		expiresIn = s.cfg.InvitationExpiry
+	}
+	invitation.TokenHash = tokenHash
+	invitation.ExpiresAt = time.Now().Add(expiresIn)
+	if err := s.invitationStore.UpdateToken(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	created := &CreatedInvitation{Invitation: invitation, Token: token, URL: s.invitationURL(token)}
+	s.send(ctx, admin, created)
+	return created, nil
+}
+
+// RevokeInvitation 撤销邀请
+func (s *invitationService) RevokeInvitation(ctx context.Context, id string) error {
+	admin, err := s.currentAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	invitation, err := s.getInvitation(ctx, activeWorkspaceID(ctx, admin), id)
+	if err != nil {
+		return err
+	}
+	return s.invitationStore.Revoke(ctx, invitation.ID)
+}
+
+// GetInvitation 根据邀请令牌获取邀请信息
+func (s *invitationService) GetInvitation(ctx context.Context, token string) (*InvitationPreview, error) {
+	invitation, err := s.pendingByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	workspace, err := s.workspaceStore.GetByID(ctx, invitation.WorkspaceID.String())
+	if err != nil {
+		return nil, ErrInvalidInvitation
+	}
+	return &InvitationPreview{
+		WorkspaceID:   workspace.ID,
+		WorkspaceName: workspace.Name,
+		Email:         invitation.Email,
+		Role:          invitation.Role,
+		ExpiresAt:     invitation.ExpiresAt,
+	}, nil
+}
+
+// AuthorizeRegistration 检查邮箱能否注册，返回加入的工作区和角色
+func (s *invitationService) AuthorizeRegistration(ctx context.Context, workspaceID uuid.UUID, email, token string) (*RegistrationGrant, error) {
+	email = NormalizeEmail(email)
+
+	if token != "" {
+		invitation, err := s.pendingByToken(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		if !invitation.IsLink() && invitation.Email != email {
+			return nil, fmt.Errorf("无效的邀请: 注册邮箱与受邀邮箱不一致")
+		}
+		return &RegistrationGrant{WorkspaceID: invitation.WorkspaceID, Role: invitation.Role, invitation: invitation}, nil
+	}
+
+	// 私有部署首次注册：系统中还没有任何用户时，注册用户成为管理员（工作区不存在时由注册流程创建）
+	// 已有用户后，其他空工作区或不存在的工作区不能再通过注册获得管理员
+	hasUsers, err := s.userStore.HasUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !hasUsers {
+		return &RegistrationGrant{WorkspaceID: workspaceID, Role: model.RoleAdmin, Bootstrap: true}, nil
+	}
+	workspace, err := s.workspaceStore.GetByID(ctx, workspaceID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRegistrationNotAllowed
+		}
+		return nil, fmt.Errorf("获取工作区失败: %w", err)
+	}
+
+	// 直接注册的受邀用户同样使用邀请中的角色和团队
+	invitation, err := s.invitationStore.GetPendingByEmail(ctx, workspace.ID, email)
+	if err == nil {
+		return &RegistrationGrant{WorkspaceID: workspace.ID, Role: invitation.Role, invitation: invitation}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("获取邀请失败: %w", err)
+	}
+
+	settings, err := parseAuthSettings(workspace.Settings)
+	if err != nil {
+		return nil, err
+	}
+	if emailDomainAllowed(email, settings.AllowedEmailDomains) {
+		return &RegistrationGrant{WorkspaceID: workspace.ID, Role: model.RoleMember}, nil
+	}
+	return nil, ErrRegistrationNotAllowed
+}
+
+// CompleteRegistration 用户创建后接受邀请并加入邀请指定的团队
+func (s *invitationService) CompleteRegistration(ctx context.Context, grant *RegistrationGrant, user *model.User) error {
+	invitation := grant.invitation
+	if invitation == nil {
+		return nil
+	}
+	if _, err := s.invitationStore.Accept(ctx, invitation.ID); err != nil {
+		return err
+	}
+	return s.joinTeams(ctx, invitation, user.ID)
+}
+
+// AcceptInvitation 已登录的用户接受邀请，以邀请中的角色加入工作区和团队
+func (s *invitationService) AcceptInvitation(ctx context.Context, token string) (*model.WorkspaceMember, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+	user, err := s.userStore.GetUserByID(ctx, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("用户不存在")
+	}
+
+	invitation, err := s.pendingByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !invitation.IsLink() && invitation.Email != NormalizeEmail(user.Email) {
+		return nil, fmt.Errorf("无效的邀请: 当前账号与受邀邮箱不一致")
+	}
+	member, err := s.isMember(ctx, invitation.WorkspaceID, user)
+	if err != nil {
+		return nil, err
+	}
+	if member {
+		return nil, fmt.Errorf("无效的操作: 你已是该工作区的成员")
+	}
+
+	// 并发使用同一邀请时只有一个请求成功
+	accepted, err := s.invitationStore.Accept(ctx, invitation.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !accepted {
+		return nil, ErrInvalidInvitation
+	}
+
+	membership := &model.WorkspaceMember{
+		WorkspaceID: invitation.WorkspaceID,
+		UserID:      user.ID,
+		Role:        invitation.Role,
+		JoinedAt:    time.Now(),
+	}
+	if err := s.workspaceMemberStore.Add(ctx, membership); err != nil {
+		return nil, err
+	}
+	if err := s.joinTeams(ctx, invitation, user.ID); err != nil {
+		return nil, err
+	}
+	return membership, nil
+}
+
+// joinTeams 将用户加入邀请指定的团队
+func (s *invitationService) joinTeams(ctx context.Context, invitation *model.Invitation, userID uuid.UUID) error {
+	for _, teamID := range invitation.TeamIDs {
+		// 邀请创建后团队可能已被删除
+		team, err := s.teamStore.GetByID(ctx, teamID)
+		if err != nil || team.WorkspaceID != invitation.WorkspaceID {
+			continue
+		}
+		member := &model.TeamMember{TeamID: team.ID, UserID: userID, Role: model.RoleMember}
+		if err := s.teamMemberStore.Add(ctx, member); err != nil {
+			return fmt.Errorf("加入团队失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// isMember 检查用户是否已是工作区成员（主工作区或已加入的工作区）
+func (s *invitationService) isMember(ctx context.Context, workspaceID uuid.UUID, user *model.User) (bool, error) {
+	if user.WorkspaceID == workspaceID {
+		return true, nil
+	}
+	_, err := s.workspaceMemberStore.Get(ctx, workspaceID, user.ID)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return false, fmt.Errorf("获取工作区成员失败: %w", err)
+}
+
+// currentAdmin 获取当前用户，只有工作区管理员可以管理邀请
+func (s *invitationService) currentAdmin(ctx context.Context) (*model.User, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+	userRole, _ := ctx.Value("user_role").(model.Role)
+	if userRole != model.RoleAdmin && userRole != model.RoleGlobalAdmin {
+		return nil, fmt.Errorf("无权限管理邀请")
+	}
+	user, err := s.userStore.GetUserByID(ctx, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("用户不存在")
+	}
+	return user, nil
+}
+
+// getInvitation 获取当前工作区的邀请，不暴露其他工作区的邀请是否存在
+func (s *invitationService) getInvitation(ctx context.Context, workspaceID uuid.UUID, id string) (*model.Invitation, error) {
+	invitationID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("无效的邀请ID")
+	}
+	invitation, err := s.invitationStore.GetByID(ctx, invitationID)
+	if err != nil || invitation.WorkspaceID != workspaceID {
+		return nil, fmt.Errorf("邀请不存在")
+	}
+	return invitation, nil
+}
+
+// pendingByToken 根据令牌获取待接受的邀请
+func (s *invitationService) pendingByToken(ctx context.Context, token string) (*model.Invitation, error) {
+	invitation, err := s.invitationStore.GetByTokenHash(ctx, model.HashAPIKey(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, fmt.Errorf("获取邀请失败: %w", err)
+	}
+	if !invitation.IsPending(time.Now()) {
+		return nil, ErrInvalidInvitation
+	}
+	return invitation, nil
+}
+
+// validateTeams 校验邀请的团队属于当前工作区，返回去重后的团队 ID
+func (s *invitationService) validateTeams(ctx context.Context, workspaceID uuid.UUID, teamIDs []string) ([]string, error) {
+	result := make([]string, 0, len(teamIDs))
+	seen := make(map[uuid.UUID]bool, len(teamIDs))
+	for _, id := range teamIDs {
+		teamID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("无效的团队ID: %s", id)
+		}
+		if seen[teamID] {
+			continue
+		}
+		team, err := s.teamStore.GetByID(ctx, teamID.String())
+		if err != nil || team.WorkspaceID != workspaceID {
+			return nil, fmt.Errorf("无效的团队ID: %s", id)
+		}
+		seen[teamID] = true
+		result = append(result, teamID.String())
+	}
+	return result, nil
+}
+
+// send 异步发送邀请邮件，发送失败只记录日志（管理员可以重新发送或复制链接）
+func (s *invitationService) send(ctx context.Context, inviter *model.User, invitation *CreatedInvitation) {
+	workspaceName := "MyLinear"
+	if workspace, err := s.workspaceStore.GetByID(ctx, invitation.WorkspaceID.String()); err == nil {
+		workspaceName = workspace.Name
+	}
+	msg := mail.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("%s 邀请你加入 %s", inviter.Name, workspaceName),
+		Body: fmt.Sprintf("你好：\n\n%s 邀请你加入 MyLinear 工作区「%s」。请在 %s 前打开以下链接完成注册：\n\n%s\n\n如果你不认识邀请人，请忽略本邮件。\n",
+			inviter.Name, workspaceName, invitation.ExpiresAt.Format("2006-01-02 15:04 MST"), invitation.URL),
+	}
+
+	now := time.Now()
+	invitation.SentAt = &now
+	if err := s.invitationStore.MarkSent(ctx, invitation.ID, now); err != nil {
+		slog.WarnContext(ctx, "记录邀请发送时间失败", "invitation_id", invitation.ID, "error", err)
+	}
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), invitationSendTimeout)
+		defer cancel()
+		if err := s.sender.Send(sendCtx, msg); err != nil {
+			slog.ErrorContext(ctx, "发送邀请邮件失败", "invitation_id", invitation.ID, "error", err)
+		}
+	}()
+}
+
+// invitationURL 生成邀请链接
+func (s *invitationService) invitationURL(token string) string {
+	return s.cfg.InvitationURL + "?token=" + url.QueryEscape(token)
+}
+
+// generateInvitationToken 生成随机邀请令牌，返回令牌和保存用的摘要
+func generateInvitationToken() (string, string, error) {
+	token, err := randomURLToken(invitationTokenBytes)
+	if err != nil {
+		return "", "", fmt.Errorf("生成邀请令牌失败: %w", err)
+	}
+	return token, model.HashAPIKey(token), nil
+}
+
+// normalizeEmailDomains 校验并规范化允许注册的邮箱域名（小写、去掉前导 @、去重）
+func normalizeEmailDomains(domains []string) ([]string, error) {
+	result := make([]string, 0, len(domains))
+	seen := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "@")
+		if domain == "" {
+			continue
+		}
+		if !emailDomainRegex.MatchString(domain) {
+			return nil, fmt.Errorf("无效的邮箱域名: %s", domain)
+		}
+		if !seen[domain] {
+			seen[domain] = true
+			result = append(result, domain)
+		}
+	}
+	return result, nil
+}
+
+// emailDomainAllowed 检查邮箱域名是否在允许范围内（精确匹配，不包含子域名）
+func emailDomainAllowed(email string, domains []string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range domains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/mail/mailtest"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/gorm"
+)
+
+func TestNormalizeEmailDomains(t *testing.T) {
+	domains, err := normalizeEmailDomains([]string{" Example.COM ", "@corp.example.com", "", "example.com"})
+	if err != nil {
+		t.Fatalf("normalizeEmailDomains() error = %v", err)
+	}
+	if strings.Join(domains, ",") != "example.com,corp.example.com" {
+		t.Errorf("normalizeEmailDomains() = %v", domains)
+	}
+
+	for _, invalid := range []string{"localhost", "exa mple.com", "-example.com", "*.example.com"} {
+		if _, err := normalizeEmailDomains([]string{invalid}); err == nil {
+			t.Errorf("normalizeEmailDomains(%q) 应返回错误", invalid)
+		}
+	}
+}
+
+func TestEmailDomainAllowed(t *testing.T) {
+	domains := []string{"example.com"}
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"alice@example.com", true},
+		{"alice@EXAMPLE.com", true},
+		{"alice@dev.example.com", false},
+		{"alice@example.com.evil.io", false},
+		{"alice", false},
+	}
+	for _, tt := range tests {
+		if got := emailDomainAllowed(tt.email, domains); got != tt.want {
+			t.Errorf("emailDomainAllowed(%q) = %v, want %v", tt.email, got, tt.want)
+		}
+	}
+	if emailDomainAllowed("alice@example.com", nil) {
+		t.Error("未配置域名时不应允许")
+	}
+}
+
+func TestGenerateInvitationToken(t *testing.T) {
+	token, hash, err := generateInvitationToken()
+	if err != nil {
+		t.Fatalf("generateInvitationToken() error = %v", err)
+	}
+	if len(token) != 43 || hash != model.HashAPIKey(token) {
+		t.Errorf("token = %q, hash = %q", token, hash)
+	}
+}
+
+type invitationFixtures struct {
+	*sessionFixtures
+	sender      *mailtest.Sender
+	invitations InvitationService
+	auth        AuthService
+}
+
+func setupInvitationFixtures(t *testing.T, db *gorm.DB) *invitationFixtures {
+	f := setupSessionFixtures(t, db)
+	f.cfg.InvitationURL = "https://linear.example.com/invite"
+	f.cfg.InvitationExpiry = 7 * 24 * time.Hour
+
+	userStore := store.NewUserStore(db)
+	workspaceStore := store.NewWorkspaceStore(db)
+	sender := mailtest.NewSender()
+	invitations := NewInvitationService(store.NewInvitationStore(db), userStore, workspaceStore, store.NewWorkspaceMemberStore(db), store.NewTeamStore(db), store.NewTeamMemberStore(db), sender, f.cfg)
+
+	return &invitationFixtures{
+		sessionFixtures: f,
+		sender:          sender,
+		invitations:     invitations,
+		auth:            NewAuthService(userStore, workspaceStore, f.jwtService, f.service, invitations, nil, f.cfg),
+	}
+}
+
+func TestInvitationService_Manage(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupInvitationFixtures(t, tx)
+
+	memberCtx := context.WithValue(context.Background(), "user_id", f.user2ID)
+	memberCtx = context.WithValue(memberCtx, "user_role", model.RoleMember)
+	if _, err := f.invitations.CreateInvitation(memberCtx, &CreateInvitationParams{Email: "new@example.com"}); err == nil || !strings.Contains(err.Error(), "无权限") {
+		t.Errorf("普通成员不能创建邀请, err = %v", err)
+	}
+
+	if _, err := f.invitations.CreateInvitation(f.ctx, &CreateInvitationParams{Email: f.user.Email}); err == nil || !strings.Contains(err.Error(), "已存在") {
+		t.Errorf("已注册的邮箱不能邀请, err = %v", err)
+	}
+	if _, err := f.invitations.CreateInvitation(f.ctx, &CreateInvitationParams{Email: "new@example.com", Role: model.RoleGlobalAdmin}); err == nil {
+		t.Error("不能通过邀请授予全局管理员")
+	}
+	if _, err := f.invitations.CreateInvitation(f.ctx, &CreateInvitationParams{Email: "new@example.com", TeamIDs: []string{uuid.New().String()}}); err == nil {
+		t.Error("不存在的团队应返回错误")
+	}
+	if _, err := f.invitations.CreateInvitation(f.ctx, &CreateInvitationParams{Email: "new@example.com", ExpiresIn: 31 * 24 * time.Hour}); err == nil {
+		t.Error("有效期超过 30 天应返回错误")
+	}
+
+	created, err := f.invitations.CreateInvitation(f.ctx, &CreateInvitationParams{
+		Email:   " New@Example.com ",
+		Role:    model.RoleGuest,
+		TeamIDs: []string{f.team.ID.String(), f.team.ID.String()},
+	})
+	if err != nil {
+		t.Fatalf("CreateInvitation() error = %v", err)
+	}
+	if created.Email != "new@example.com" || created.Role != model.RoleGuest || len(created.TeamIDs) != 1 {
+		t.Errorf("Unexpected invitation: %+v", created.Invitation)
+	}
+	if !strings.HasPrefix(created.URL, "https://linear.example.com/invite?token=") || created.SentAt == nil {
+		t.Errorf("URL = %s, SentAt = %v", created.URL, created.SentAt)
+	}
+	messages := waitForMail(t, f.sender, 1)
+	if messages[0].To != "new@example.com" || !strings.Contains(messages[0].Body, created.URL) {
+		t.Errorf("邀请邮件 = %+v", messages[0])
+	}
+
+	// 邀请链接不发送邮件
+	link, err := f.invitations.CreateInvitation(f.ctx, &CreateInvitationParams{ExpiresIn: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("创建邀请链接失败: %v", err)
+	}
+	if !link.IsLink() || link.Role != model.RoleMember || link.SentAt != nil {
+		t.Errorf("Unexpected link: %+v", link.Invitation)
+	}
+	if _, err := f.invitations.ResendInvitation(f.ctx, link.ID.String()); err == nil {
+		t.Error("邀请链接不能重新发送")
+	}
+
+	list, err := f.invitations.ListInvitations(f.ctx)
+	if err != nil || len(list) != 2 {
+		t.Fatalf("ListInvitations() = %d, err = %v", len(list), err)
+	}
+
+	// 重新发送后旧链接失效
+	resent, err := f.invitations.ResendInvitation(f.ctx, created.ID.String())
+	if err != nil {
+		t.Fatalf("ResendInvitation() error = %v", err)
+	}
+	waitForMail(t, f.sender, 2)
+	if _, err := f.invitations.GetInvitation(context.Background(), created.Token); !errors.Is(err, ErrInvalidInvitation) {
+		t.Errorf("重新发送后旧令牌应失效, err = %v", err)
+	}
+	preview, err := f.invitations.GetInvitation(context.Background(), resent.Token)
+	if err != nil {
+		t.Fatalf("GetInvitation() error = %v", err)
+	}
+	if preview.WorkspaceID != f.workspaceID || preview.Email != "new@example.com" || preview.Role != model.RoleGuest {
+		t.Errorf("Unexpected preview: %+v", preview)
+	}
+
+	if err := f.invitations.RevokeInvitation(f.ctx, link.ID.String()); err != nil {
+		t.Fatalf("RevokeInvitation() error = %v", err)
+	}
+	if _, err := f.invitations.GetInvitation(context.Background(), link.Token); !errors.Is(err, ErrInvalidInvitation) {
+		t.Errorf("撤销后邀请应失效, err = %v", err)
+	}
+	if err := f.invitations.RevokeInvitation(f.ctx, uuid.New().String()); err == nil || !strings.Contains(err.Error(), "不存在") {
+		t.Errorf("不存在的邀请应返回错误, err = %v", err)
+	}
+}
+
+func TestInvitationService_Registration(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupInvitationFixtures(t, tx)
+	ctx := context.Background()
+	prefix := uuid.New().String()[:8]
+
+	// 工作区已有成员，未被邀请的邮箱不能注册
+	if _, _, _, err := f.auth.Register(ctx, f.workspaceID, prefix+"_stranger@example.com", prefix+"_stranger", "Password123", "Stranger", ""); !errors.Is(err, ErrRegistrationNotAllowed) {
+		t.Errorf("未被邀请的邮箱注册 err = %v", err)
+	}
+
+	// 邮件邀请：注册邮箱必须与受邀邮箱一致，注册后加入指定团队
+	invited, err := f.invitations.CreateInvitation(f.ctx, &CreateInvitationParams{
+		Email:   prefix + "_invited@example.com",
+		Role:    model.RoleGuest,
+		TeamIDs: []string{f.team.ID.String()},
+	})
+	if err != nil {
+		t.Fatalf("CreateInvitation() error = %v", err)
+	}
+	if _, _, _, err := f.auth.Register(ctx, uuid.Nil, prefix+"_other@example.com", prefix+"_other", "Password123", "Other", invited.Token); err == nil || !strings.Contains(err.Error(), "无效") {
+		t.Errorf("邮箱不一致应返回错误, err = %v", err)
+	}
+	user, _, _, err := f.auth.Register(ctx, uuid.Nil, strings.ToUpper(prefix)+"_INVITED@example.com", prefix+"_invited", "Password123", "Invited", invited.Token)
+	if err != nil {
+		t.Fatalf("通过邀请注册失败: %v", err)
+	}
+	if user.WorkspaceID != f.workspaceID || user.Role != model.RoleGuest {
+		t.Errorf("WorkspaceID = %v, Role = %s", user.WorkspaceID, user.Role)
+	}
+	if role, err := store.NewTeamMemberStore(tx).GetRole(ctx, f.team.ID.String(), user.ID.String()); err != nil || role != model.RoleMember {
+		t.Errorf("团队角色 = %s, err = %v", role, err)
+	}
+	if _, err := f.invitations.GetInvitation(ctx, invited.Token); !errors.Is(err, ErrInvalidInvitation) {
+		t.Errorf("邮件邀请只能使用一次, err = %v", err)
+	}
+
+	// 已邀请的邮箱不带令牌直接注册同样使用邀请中的角色
+	direct, err := f.invitations.CreateInvitation(f.ctx, &CreateInvitationParams{Email: prefix + "_direct@example.com", Role: model.RoleAdmin})
+	if err != nil {
+		t.Fatalf("CreateInvitation() error = %v", err)
+	}
+	user, _, _, err = f.auth.Register(ctx, f.workspaceID, prefix+"_direct@example.com", prefix+"_direct", "Password123", "Direct", "")
+	if err != nil {
+		t.Fatalf("受邀邮箱直接注册失败: %v", err)
+	}
+	if user.Role != model.RoleAdmin {
+		t.Errorf("Role = %s, 期望 admin", user.Role)
+	}
+	if _, err := f.invitations.GetInvitation(ctx, direct.Token); !errors.Is(err, ErrInvalidInvitation) {
+		t.Errorf("直接注册后邀请应被接受, err = %v", err)
+	}
+
+	// 邀请链接可多次使用
+	link, err := f.invitations.CreateInvitation(f.ctx, &CreateInvitationParams{})
+	if err != nil {
+		t.Fatalf("创建邀请链接失败: %v", err)
+	}
+	for _, name := range []string{"link1", "link2"} {
+		if _, _, _, err := f.auth.Register(ctx, uuid.Nil, prefix+"_"+name+"@other.io", prefix+"_"+name, "Password123", name, link.Token); err != nil {
+			t.Fatalf("通过邀请链接注册失败: %v", err)
+		}
+	}
+	if err := f.invitations.RevokeInvitation(f.ctx, link.ID.String()); err != nil {
+		t.Fatalf("RevokeInvitation() error = %v", err)
+	}
+	if _, _, _, err := f.auth.Register(ctx, uuid.Nil, prefix+"_link3@other.io", prefix+"_link3", "Password123", "link3", link.Token); !errors.Is(err, ErrInvalidInvitation) {
+		t.Errorf("撤销后邀请链接应失效, err = %v", err)
+	}
+
+	// 允许的邮箱域名可直接注册为普通成员
+	oidcService := NewOIDCService(f.cfg, store.NewUserStore(tx), store.NewWorkspaceStore(tx), nil, nil, nil, nil, nil)
+	if _, err := oidcService.UpdateAuthSettings(f.ctx, &AuthSettings{AllowedEmailDomains: []string{"Example.com"}}); err != nil {
+		t.Fatalf("UpdateAuthSettings() error = %v", err)
+	}
+	user, _, _, err = f.auth.Register(ctx, f.workspaceID, prefix+"_domain@example.com", prefix+"_domain", "Password123", "Domain", "")
+	if err != nil {
+		t.Fatalf("允许域名的邮箱注册失败: %v", err)
+	}
+	if user.Role != model.RoleMember {
+		t.Errorf("Role = %s, 期望 member", user.Role)
+	}
+	if _, _, _, err := f.auth.Register(ctx, f.workspaceID, prefix+"_domain@sub.example.com", prefix+"_subdomain", "Password123", "Sub", ""); !errors.Is(err, ErrRegistrationNotAllowed) {
+		t.Errorf("子域名不应被允许, err = %v", err)
+	}
+}
+
+func TestInvitationService_AcceptExistingUser(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupInvitationFixtures(t, tx)
+
+	// 其他工作区的用户
+	other := &model.Workspace{Name: "Other", Slug: "other-" + uuid.New().String()[:8]}
+	if err := tx.Create(other).Error; err != nil {
+		t.Fatalf("创建工作区失败: %v", err)
+	}
+	outsider := &model.User{
+		WorkspaceID: other.ID, Email: "outsider-" + uuid.New().String()[:8] + "@example.com",
+		Username: "outsider_" + uuid.New().String()[:8], Name: "Outsider", PasswordHash: "hash", Role: model.RoleAdmin,
+	}
+	if err := store.NewUserStore(tx).CreateUser(context.Background(), outsider); err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	outsiderCtx := context.WithValue(context.Background(), "user_id", outsider.ID)
+
+	created, err := f.invitations.CreateInvitation(f.ctx, &CreateInvitationParams{
+		Email:   outsider.Email,
+		Role:    model.RoleGuest,
+		TeamIDs: []string{f.team.ID.String()},
+	})
+	if err != nil {
+		t.Fatalf("其他工作区的用户可以被邀请, err = %v", err)
+	}
+
+	// 受邀邮箱与当前账号不一致
+	user2Ctx := context.WithValue(context.Background(), "user_id", f.user2ID)
+	if _, err := f.invitations.AcceptInvitation(user2Ctx, created.Token); err == nil || !strings.Contains(err.Error(), "不一致") {
+		t.Errorf("其他账号不能接受指定邮箱的邀请, err = %v", err)
+	}
+
+	member, err := f.invitations.AcceptInvitation(outsiderCtx, created.Token)
+	if err != nil {
+		t.Fatalf("AcceptInvitation() error = %v", err)
+	}
+	if member.WorkspaceID != f.workspaceID || member.Role != model.RoleGuest {
+		t.Errorf("Unexpected member: %+v", member)
+	}
+	if _, err := store.NewTeamMemberStore(tx).GetRole(context.Background(), f.team.ID.String(), outsider.ID.String()); err != nil {
+		t.Errorf("应加入邀请指定的团队: %v", err)
+	}
+	if _, err := f.invitations.AcceptInvitation(outsiderCtx, created.Token); !errors.Is(err, ErrInvalidInvitation) {
+		t.Errorf("邀请只能接受一次, err = %v", err)
+	}
+
+	// 已是成员时不能再邀请或接受邀请链接
+	if _, err := f.invitations.CreateInvitation(f.ctx, &CreateInvitationParams{Email: outsider.Email}); err == nil || !strings.Contains(err.Error(), "已存在") {
+		t.Errorf("已加入的用户不能再邀请, err = %v", err)
+	}
+	link, err := f.invitations.CreateInvitation(f.ctx, &CreateInvitationParams{})
+	if err != nil {
+		t.Fatalf("创建邀请链接失败: %v", err)
+	}
+	if _, err := f.invitations.AcceptInvitation(outsiderCtx, link.Token); err == nil || !strings.Contains(err.Error(), "已是该工作区的成员") {
+		t.Errorf("已是成员时不能接受邀请, err = %v", err)
+	}
+}
+
+func TestInvitationService_Bootstrap(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupInvitationFixtures(t, tx)
+
+	// 已有用户后，空工作区和不存在的工作区都不能通过注册获得管理员
+	workspace := &model.Workspace{Name: "Empty Workspace", Slug: uuid.New().String()[:8] + "_empty"}
+	if err := tx.Create(workspace).Error; err != nil {
+		t.Fatalf("创建工作区失败: %v", err)
+	}
+	if _, err := f.invitations.AuthorizeRegistration(context.Background(), workspace.ID, "first@other.io", ""); !errors.Is(err, ErrRegistrationNotAllowed) {
+		t.Errorf("空工作区 err = %v", err)
+	}
+	missing := uuid.New()
+	if _, err := f.invitations.AuthorizeRegistration(context.Background(), missing, "first@other.io", ""); !errors.Is(err, ErrRegistrationNotAllowed) {
+		t.Errorf("不存在的工作区 err = %v", err)
+	}
+
+	// 系统中还没有用户时，首个注册用户成为管理员，工作区由注册流程创建
+	invitations := f.invitations.(*invitationService)
+	invitations.userStore = noUsersStore{invitations.userStore}
+	grant, err := invitations.AuthorizeRegistration(context.Background(), missing, "first@other.io", "")
+	if err != nil || !grant.Bootstrap || grant.Role != model.RoleAdmin || grant.WorkspaceID != missing {
+		t.Errorf("grant = %+v, err = %v", grant, err)
+	}
+
+	if _, err := f.invitations.AuthorizeRegistration(context.Background(), f.workspaceID, "first@other.io", "unknown-token"); !errors.Is(err, ErrInvalidInvitation) {
+		t.Errorf("无效令牌 err = %v", err)
+	}
+}
+
+// noUsersStore 模拟尚未有任何用户的系统
+type noUsersStore struct {
+	store.UserStore
+}
+
+func (noUsersStore) HasUsers(ctx context.Context) (bool, error) {
+	return false, nil
+}
@@ -5,10 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 
 	"github.com/google/uuid"
 	"github.com/liwei0526vip/mylinear/internal/model"
 	"github.com/liwei0526vip/mylinear/internal/store"
+	"github.com/liwei0526vip/mylinear/internal/tracing"
 )
 
 // IssueFilter Issue 过滤条件
@@ -41,6 +43,8 @@ type IssueService interface {
 	CreateIssue(ctx context.Context, params *CreateIssueParams) (*model.Issue, error)
 	// GetIssue 获取 Issue
 	GetIssue(ctx context.Context, issueID string) (*model.Issue, error)
+	// GetIssueByIdentifier 通过标识符（如 ENG-42，支持历史标识符）获取 Issue
+	GetIssueByIdentifier(ctx context.Context, identifier string) (*model.Issue, error)
 	// ListIssues 获取 Issue 列表
 	ListIssues(ctx context.Context, teamID string, filter *IssueFilter, page, pageSize int) ([]model.Issue, int64, error)
 	// UpdateIssue 更新 Issue
@@ -100,6 +104,9 @@ func NewIssueServiceWithNotification(issueStore store.IssueStore, subscriptionSt
 
 // CreateIssue 创建 Issue
 func (s *issueService) CreateIssue(ctx context.Context, params *CreateIssueParams) (*model.Issue, error) {
+	ctx, span := tracing.Start(ctx, "IssueService.CreateIssue")
+	defer span.End()
+
 	// 获取当前用户信息
 	userID, ok := ctx.Value("user_id").(uuid.UUID)
 	if !ok {
@@ -139,6 +146,7 @@ func (s *issueService) CreateIssue(ctx context.Context, params *CreateIssueParam
 	// 创建者自动订阅
 	if err := s.subscriptionStore.Subscribe(ctx, issue.ID, userID); err != nil {
 		// 订阅失败不影响创建，记录日志即可
+		slog.WarnContext(ctx, "创建者自动订阅 Issue 失败", "issue_id", issue.ID, "user_id", userID, "error", err)
 	}
 
 	// 记录 Issue 创建活动
@@ -150,6 +158,7 @@ func (s *issueService) CreateIssue(ctx context.Context, params *CreateIssueParam
 		}
 		if err := s.activityService.RecordActivity(ctx, activity); err != nil {
 			// 活动记录失败不影响创建
+			slog.WarnContext(ctx, "记录 Issue 创建活动失败", "issue_id", issue.ID, "user_id", userID, "error", err)
 		}
 	}
 
@@ -158,6 +167,9 @@ func (s *issueService) CreateIssue(ctx context.Context, params *CreateIssueParam
 
 // GetIssue 获取 Issue
 func (s *issueService) GetIssue(ctx context.Context, issueID string) (*model.Issue, error) {
+	ctx, span := tracing.Start(ctx, "IssueService.GetIssue")
+	defer span.End()
+
 	// 解析 Issue ID
 	id, err := uuid.Parse(issueID)
 	if err != nil {
@@ -172,15 +184,42 @@ func (s *issueService) GetIssue(ctx context.Context, issueID string) (*model.Iss
 	return issue, nil
 }
 
+// GetIssueByIdentifier 通过标识符（如 ENG-42，支持历史标识符）获取 Issue
+func (s *issueService) GetIssueByIdentifier(ctx context.Context, identifier string) (*model.Issue, error) {
+	ctx, span := tracing.Start(ctx, "IssueService.GetIssueByIdentifier")
+	defer span.End()
+
+	teamKey, number, ok := model.ParseIdentifier(identifier)
+	if !ok {
+		return nil, fmt.Errorf("无效的 Issue 标识符")
+	}
+
+	// 标识符只在当前工作区内唯一
+	workspaceID, _ := ctx.Value("workspace_id").(uuid.UUID)
+	issue, err := s.issueStore.ResolveIdentifier(ctx, workspaceID, teamKey, number)
+	if err != nil {
+		return nil, fmt.Errorf("Issue 不存在")
+	}
+
+	return issue, nil
+}
+
 // ListIssues 获取 Issue 列表
 func (s *issueService) ListIssues(ctx context.Context, teamID string, filter *IssueFilter, page, pageSize int) ([]model.Issue, int64, error) {
+	ctx, span := tracing.Start(ctx, "IssueService.ListIssues")
+	defer span.End()
+
 	// 解析 Team ID
 	teamUUID, err := uuid.Parse(teamID)
 	if err != nil {
 		return nil, 0, fmt.Errorf("无效的团队 ID")
 	}
 
-	// 转换过滤器
+	return s.issueStore.List(ctx, teamUUID, toStoreIssueFilter(filter), page, pageSize)
+}
+
+// toStoreIssueFilter 将服务层过滤条件转换为存储层过滤条件
+func toStoreIssueFilter(filter *IssueFilter) *store.IssueFilter {
 	storeFilter := &store.IssueFilter{}
 	if filter != nil {
 		if filter.StatusID != nil {
@@ -214,11 +253,14 @@ func (s *issueService) ListIssues(ctx context.Context, teamID string, filter *Is
 		}
 	}
 
-	return s.issueStore.List(ctx, teamUUID, storeFilter, page, pageSize)
+	return storeFilter
 }
 
 // UpdateIssue 更新 Issue
 func (s *issueService) UpdateIssue(ctx context.Context, issueID string, updates map[string]interface{}) (*model.Issue, error) {
+	ctx, span := tracing.Start(ctx, "IssueService.UpdateIssue")
+	defer span.End()
+
 	// 解析 Issue ID
 	id, err := uuid.Parse(issueID)
 	if err != nil {
@@ -366,7 +408,10 @@ func (s *issueService) UpdateIssue(ctx context.Context, issueID string, updates
 	// 触发通知
 	if s.notificationService != nil {
 		// 获取订阅者列表
-		subscribers, _ := s.subscriptionStore.ListSubscribers(ctx, issue.ID)
+		subscribers, err := s.subscriptionStore.ListSubscribers(ctx, issue.ID)
+		if err != nil {
+			slog.WarnContext(ctx, "获取 Issue 订阅者失败", "issue_id", issue.ID, "error", err)
+		}
 		subscriberIDs := make([]uuid.UUID, len(subscribers))
 		for i, sub := range subscribers {
 			subscriberIDs[i] = sub.ID
@@ -374,17 +419,23 @@ func (s *issueService) UpdateIssue(ctx context.Context, issueID string, updates
 
 		// 指派通知
 		if hasAssigneeChange {
-			_ = s.notificationService.NotifyIssueAssigned(ctx, userID, issue.AssigneeID, issue.ID, issue.Title)
+			if err := s.notificationService.NotifyIssueAssigned(ctx, userID, issue.AssigneeID, issue.ID, issue.Title); err != nil {
+				slog.WarnContext(ctx, "发送指派通知失败", "issue_id", issue.ID, "user_id", userID, "error", err)
+			}
 		}
 
 		// 状态变更通知订阅者
 		if hasStatusChange {
-			_ = s.notificationService.NotifySubscribers(ctx, userID, subscriberIDs, model.NotificationTypeIssueStatusChanged, issue.ID, "Issue 状态已更新", fmt.Sprintf("Issue «%s» 状态已变更", issue.Title))
+			if err := s.notificationService.NotifySubscribers(ctx, userID, subscriberIDs, model.NotificationTypeIssueStatusChanged, issue.ID, "Issue 状态已更新", fmt.Sprintf("Issue «%s» 状态已变更", issue.Title)); err != nil {
+				slog.WarnContext(ctx, "发送状态变更通知失败", "issue_id", issue.ID, "user_id", userID, "error", err)
+			}
 		}
 
 		// 优先级变更通知订阅者
 		if hasPriorityChange {
-			_ = s.notificationService.NotifySubscribers(ctx, userID, subscriberIDs, model.NotificationTypeIssuePriorityChanged, issue.ID, "Issue 优先级已更新", fmt.Sprintf("Issue «%s» 优先级已变更", issue.Title))
+			if err := s.notificationService.NotifySubscribers(ctx, userID, subscriberIDs, model.NotificationTypeIssuePriorityChanged, issue.ID, "Issue 优先级已更新", fmt.Sprintf("Issue «%s» 优先级已变更", issue.Title)); err != nil {
+				slog.WarnContext(ctx, "发送优先级变更通知失败", "issue_id", issue.ID, "user_id", userID, "error", err)
+			}
 		}
 	}
 
@@ -405,12 +456,16 @@ func (s *issueService) recordActivity(ctx context.Context, issueID, actorID uuid
 
 	if payload != nil {
 		payloadBytes, err := jsonMarshal(payload)
-		if err == nil {
+		if err != nil {
+			slog.WarnContext(ctx, "序列化活动内容失败", "issue_id", issueID, "type", activityType, "error", err)
+		} else {
 			activity.Payload = payloadBytes
 		}
 	}
 
-	_ = s.activityService.RecordActivity(ctx, activity)
+	if err := s.activityService.RecordActivity(ctx, activity); err != nil {
+		slog.WarnContext(ctx, "记录 Issue 活动失败", "issue_id", issueID, "user_id", actorID, "type", activityType, "error", err)
+	}
 }
 
 // uuidPtrEqual 比较两个 uuid 指针是否相等
@@ -431,6 +486,9 @@ func jsonMarshal(v interface{}) ([]byte, error) {
 
 // DeleteIssue 删除 Issue
 func (s *issueService) DeleteIssue(ctx context.Context, issueID string) error {
+	ctx, span := tracing.Start(ctx, "IssueService.DeleteIssue")
+	defer span.End()
+
 	// 解析 Issue ID
 	id, err := uuid.Parse(issueID)
 	if err != nil {
@@ -448,6 +506,9 @@ func (s *issueService) DeleteIssue(ctx context.Context, issueID string) error {
 
 // RestoreIssue 恢复已删除的 Issue
 func (s *issueService) RestoreIssue(ctx context.Context, issueID string) error {
+	ctx, span := tracing.Start(ctx, "IssueService.RestoreIssue")
+	defer span.End()
+
 	// 解析 Issue ID
 	id, err := uuid.Parse(issueID)
 	if err != nil {
@@ -504,6 +565,9 @@ func (s *issueService) ListSubscribers(ctx context.Context, issueID string) ([]m
 
 // UpdatePosition 更新 Issue 位置
 func (s *issueService) UpdatePosition(ctx context.Context, issueID string, position float64, statusID *string) error {
+	ctx, span := tracing.Start(ctx, "IssueService.UpdatePosition")
+	defer span.End()
+
 	// 解析 Issue ID
 	id, err := uuid.Parse(issueID)
 	if err != nil {
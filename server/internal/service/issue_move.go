@@ -0,0 +1,395 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+)
+
+// MoveIssueParams 移动 Issue 参数
+type MoveIssueParams struct {
+	TargetTeamID string
+	// StatusID 显式指定目标状态（必须属于目标团队），为空时按状态类型映射；只作用于被移动的 Issue 本身
+	StatusID *string
+	// LabelMapping 源标签 ID -> 目标团队可用的标签 ID
+	// 未映射的团队标签按名称匹配目标团队标签，匹配不到则移除；工作区标签保持不变
+	LabelMapping map[string]string
+	// IncludeSubIssues 同时移动同一团队下的所有子 Issue（递归）
+	IncludeSubIssues bool
+}
+
+// MovedIssue 单个 Issue 的移动结果
+type MovedIssue struct {
+	IssueID       uuid.UUID `json:"issue_id"`
+	OldIdentifier string    `json:"old_identifier"`
+	NewIdentifier string    `json:"new_identifier"`
+	StatusID      uuid.UUID `json:"status_id"`
+	// DroppedLabels 目标团队中没有对应标签而被移除的标签名
+	DroppedLabels []string `json:"dropped_labels,omitempty"`
+}
+
+// MoveIssueResult 移动结果
+type MoveIssueResult struct {
+	Issue *model.Issue `json:"issue"`
+	Moved []MovedIssue `json:"moved"`
+}
+
+// IssueMoveService 定义 Issue 跨团队移动服务接口
+type IssueMoveService interface {
+	// MoveIssue 将 Issue 移动到其他团队
+	MoveIssue(ctx context.Context, issueID string, params *MoveIssueParams) (*MoveIssueResult, error)
+}
+
+// issueMoveService 实现 IssueMoveService 接口
+type issueMoveService struct {
+	issueStore         store.IssueStore
+	teamStore          store.TeamStore
+	teamMemberStore    store.TeamMemberStore
+	workflowStateStore store.WorkflowStateStore
+	labelStore         store.LabelStore
+	activityService    ActivityService
+}
+
+// NewIssueMoveService 创建 Issue 跨团队移动服务实例
+func NewIssueMoveService(issueStore store.IssueStore, teamStore store.TeamStore, teamMemberStore store.TeamMemberStore, workflowStateStore store.WorkflowStateStore, labelStore store.LabelStore, activityService ActivityService) IssueMoveService {
+	return &issueMoveService{
+		issueStore:         issueStore,
+		teamStore:          teamStore,
+		teamMemberStore:    teamMemberStore,
+		workflowStateStore: workflowStateStore,
+		labelStore:         labelStore,
+		activityService:    activityService,
+	}
+}
+
+// MoveIssue 将 Issue 移动到其他团队
+//
+// 在目标团队分配新编号，状态映射到目标工作流中相同类型的状态，
+// 团队标签按映射或名称转换，旧标识符保留为历史标识符。
+func (s *issueMoveService) MoveIssue(ctx context.Context, issueID string, params *MoveIssueParams) (*MoveIssueResult, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+
+	id, err := uuid.Parse(issueID)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 Issue ID")
+	}
+	issue, err := s.issueStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("Issue 不存在")
+	}
+
+	targetTeam, err := s.teamStore.GetByID(ctx, params.TargetTeamID)
+	if err != nil {
+		return nil, fmt.Errorf("目标团队不存在")
+	}
+	if targetTeam.ID == issue.TeamID {
+		return nil, fmt.Errorf("无效的目标团队: Issue 已属于该团队")
+	}
+	sourceTeam := issue.Team
+	if sourceTeam == nil {
+		if sourceTeam, err = s.teamStore.GetByID(ctx, issue.TeamID.String()); err != nil {
+			return nil, fmt.Errorf("团队不存在")
+		}
+	}
+	if sourceTeam.WorkspaceID != targetTeam.WorkspaceID {
+		return nil, fmt.Errorf("无效的目标团队: 不能移动到其他工作区")
+	}
+
+	// 权限检查：需要同时是源团队和目标团队的成员；当前工作区的管理员可以跳过公开团队，
+	// 私有团队同样需要先加入，不能借移动把 Issue 放进未加入的私有团队
+	admin := isAdminOfWorkspace(ctx, sourceTeam.WorkspaceID)
+	for _, team := range []*model.Team{sourceTeam, targetTeam} {
+		if admin && !team.IsPrivate {
+			continue
+		}
+		role, err := s.teamMemberStore.GetRole(ctx, team.ID.String(), userID.String())
+		if err != nil || role == "" {
+			return nil, fmt.Errorf("无权限移动此 Issue")
+		}
+	}
+
+	// 目标团队状态
+	targetStates, err := s.workflowStateStore.ListByTeamID(ctx, targetTeam.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取工作流状态失败: %w", err)
+	}
+	if len(targetStates) == 0 {
+		return nil, fmt.Errorf("无效的目标团队: 没有工作流状态")
+	}
+	var explicitState *model.WorkflowState
+	if params.StatusID != nil && *params.StatusID != "" {
+		for _, state := range targetStates {
+			if state.ID.String() == *params.StatusID {
+				explicitState = state
+			}
+		}
+		if explicitState == nil {
+			return nil, fmt.Errorf("无效的状态: 不属于目标团队")
+		}
+	}
+
+	labels, err := s.newLabelMapper(ctx, targetTeam, params.LabelMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	// 需要移动的 Issue：自身 + 同团队的子 Issue
+	issues := []*model.Issue{issue}
+	if params.IncludeSubIssues {
+		children, err := s.collectSubIssues(ctx, issue)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, children...)
+	}
+
+	now := time.Now()
+	moves := make([]store.IssueMove, 0, len(issues))
+	payloads := make([]*model.ActivityPayloadTeam, 0, len(issues))
+	result := &MoveIssueResult{}
+	for i, current := range issues {
+		oldStatus := current.Status
+		newStatus := explicitState
+		if i > 0 || newStatus == nil {
+			newStatus = mapWorkflowState(oldStatus, targetStates)
+		}
+
+		newLabels, dropped, err := labels.mapLabels(ctx, current.Labels)
+		if err != nil {
+			return nil, err
+		}
+
+		move := store.IssueMove{
+			Issue:      current,
+			OldTeamKey: sourceTeam.Key,
+			OldNumber:  current.Number,
+		}
+		current.TeamID = targetTeam.ID
+		current.StatusID = newStatus.ID
+		current.Labels = newLabels
+		// 迭代属于团队，移动后清空
+		current.CycleID = nil
+		applyStateTimestamps(current, newStatus.Type, now)
+		moves = append(moves, move)
+
+		payload := &model.ActivityPayloadTeam{
+			OldTeam:       &model.ActivityTeamRef{ID: sourceTeam.ID, Name: sourceTeam.Name, Key: sourceTeam.Key},
+			NewTeam:       &model.ActivityTeamRef{ID: targetTeam.ID, Name: targetTeam.Name, Key: targetTeam.Key},
+			OldIdentifier: current.Identifier(sourceTeam.Key),
+			NewStatus:     &model.ActivityStatusRef{ID: newStatus.ID, Name: newStatus.Name, Color: newStatus.Color},
+		}
+		if oldStatus != nil {
+			payload.OldStatus = &model.ActivityStatusRef{ID: oldStatus.ID, Name: oldStatus.Name, Color: oldStatus.Color}
+		}
+		moved := MovedIssue{
+			IssueID:       current.ID,
+			OldIdentifier: payload.OldIdentifier,
+			StatusID:      newStatus.ID,
+		}
+		for _, label := range dropped {
+			payload.DroppedLabels = append(payload.DroppedLabels, model.ActivityLabelRef{ID: label.ID, Name: label.Name, Color: label.Color})
+			moved.DroppedLabels = append(moved.DroppedLabels, label.Name)
+		}
+		payloads = append(payloads, payload)
+		result.Moved = append(result.Moved, moved)
+	}
+
+	if err := s.issueStore.MoveToTeams(ctx, moves); err != nil {
+		return nil, fmt.Errorf("移动 Issue 失败: %w", err)
+	}
+
+	for i, move := range moves {
+		newIdentifier := move.Issue.Identifier(targetTeam.Key)
+		result.Moved[i].NewIdentifier = newIdentifier
+		payloads[i].NewIdentifier = newIdentifier
+		s.recordActivity(ctx, move.Issue.ID, userID, payloads[i])
+	}
+
+	result.Issue, err = s.issueStore.GetByID(ctx, issue.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取 Issue 失败: %w", err)
+	}
+	return result, nil
+}
+
+// collectSubIssues 递归收集与父 Issue 同团队的子 Issue（广度优先）
+func (s *issueMoveService) collectSubIssues(ctx context.Context, root *model.Issue) ([]*model.Issue, error) {
+	var result []*model.Issue
+	visited := map[uuid.UUID]bool{root.ID: true}
+	queue := []uuid.UUID{root.ID}
+	for len(queue) > 0 {
+		parentID := queue[0]
+		queue = queue[1:]
+
+		children, err := s.issueStore.ListChildren(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range children {
+			child := &children[i]
+			if visited[child.ID] || child.TeamID != root.TeamID {
+				continue
+			}
+			visited[child.ID] = true
+			result = append(result, child)
+			queue = append(queue, child.ID)
+		}
+	}
+	return result, nil
+}
+
+// mapWorkflowState 在目标团队中选择对应的状态：
+// 同类型且同名 > 同类型中位置最靠前 > 目标团队默认状态 > 第一个状态
+func mapWorkflowState(source *model.WorkflowState, targets []*model.WorkflowState) *model.WorkflowState {
+	if source != nil {
+		var sameType *model.WorkflowState
+		for _, state := range targets {
+			if state.Type != source.Type {
+				continue
+			}
+			if strings.EqualFold(state.Name, source.Name) {
+				return state
+			}
+			if sameType == nil {
+				sameType = state
+			}
+		}
+		if sameType != nil {
+			return sameType
+		}
+	}
+	for _, state := range targets {
+		if state.IsDefault {
+			return state
+		}
+	}
+	return targets[0]
+}
+
+// applyStateTimestamps 根据新状态类型设置完成 / 取消时间
+func applyStateTimestamps(issue *model.Issue, stateType model.StateType, now time.Time) {
+	switch stateType {
+	case model.StateTypeCompleted:
+		if issue.CompletedAt == nil {
+			issue.CompletedAt = &now
+		}
+		issue.CancelledAt = nil
+	case model.StateTypeCanceled:
+		if issue.CancelledAt == nil {
+			issue.CancelledAt = &now
+		}
+		issue.CompletedAt = nil
+	default:
+		issue.CompletedAt = nil
+		issue.CancelledAt = nil
+	}
+}
+
+// labelMapper 将源团队标签转换为目标团队可用的标签
+type labelMapper struct {
+	labelStore store.LabelStore
+	// available 目标团队可用的标签（工作区标签 + 目标团队标签）
+	available map[uuid.UUID]*model.Label
+	// byName 目标团队可用标签按名称（小写）索引，团队标签优先
+	byName  map[string]*model.Label
+	mapping map[uuid.UUID]uuid.UUID
+}
+
+// newLabelMapper 创建标签转换器并校验显式映射
+func (s *issueMoveService) newLabelMapper(ctx context.Context, targetTeam *model.Team, mapping map[string]string) (*labelMapper, error) {
+	targetLabels, err := s.labelStore.ListForTeam(ctx, targetTeam.WorkspaceID, targetTeam.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取标签失败: %w", err)
+	}
+
+	m := &labelMapper{
+		labelStore: s.labelStore,
+		available:  make(map[uuid.UUID]*model.Label, len(targetLabels)),
+		byName:     make(map[string]*model.Label, len(targetLabels)),
+		mapping:    make(map[uuid.UUID]uuid.UUID, len(mapping)),
+	}
+	for _, label := range targetLabels {
+		m.available[label.ID] = label
+		key := strings.ToLower(label.Name)
+		if existing, ok := m.byName[key]; !ok || (existing.TeamID == nil && label.TeamID != nil) {
+			m.byName[key] = label
+		}
+	}
+
+	for from, to := range mapping {
+		fromID, err := uuid.Parse(from)
+		if err != nil {
+			return nil, fmt.Errorf("无效的标签映射: %s", from)
+		}
+		toID, err := uuid.Parse(to)
+		if err != nil || m.available[toID] == nil {
+			return nil, fmt.Errorf("无效的标签映射: %s 不是目标团队可用的标签", to)
+		}
+		m.mapping[fromID] = toID
+	}
+	return m, nil
+}
+
+// mapLabels 转换 Issue 的标签，返回新标签列表和被移除的标签
+func (m *labelMapper) mapLabels(ctx context.Context, labelIDs pq.StringArray) (pq.StringArray, []*model.Label, error) {
+	result := pq.StringArray{}
+	seen := make(map[uuid.UUID]bool)
+	var dropped []*model.Label
+	add := func(id uuid.UUID) {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id.String())
+		}
+	}
+
+	for _, raw := range labelIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			continue
+		}
+		if to, ok := m.mapping[id]; ok {
+			add(to)
+			continue
+		}
+		if m.available[id] != nil {
+			add(id)
+			continue
+		}
+
+		label, err := m.labelStore.GetByID(ctx, id)
+		if err != nil {
+			// 标签已被删除，直接移除
+			continue
+		}
+		if match, ok := m.byName[strings.ToLower(label.Name)]; ok {
+			add(match.ID)
+			continue
+		}
+		dropped = append(dropped, label)
+	}
+	return result, dropped, nil
+}
+
+// recordActivity 记录移动团队活动
+func (s *issueMoveService) recordActivity(ctx context.Context, issueID, actorID uuid.UUID, payload *model.ActivityPayloadTeam) {
+	if s.activityService == nil {
+		return
+	}
+	activity := &model.Activity{IssueID: issueID, Type: model.ActivityTeamChanged, ActorID: actorID}
+	if data, err := jsonMarshal(payload); err == nil {
+		activity.Payload = data
+	}
+	if err := s.activityService.RecordActivity(ctx, activity); err != nil {
+		slog.WarnContext(ctx, "记录移动团队活动失败", "issue_id", issueID, "user_id", actorID, "error", err)
+	}
+}
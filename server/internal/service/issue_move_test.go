@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/gorm"
+)
+
+// TestMapWorkflowState 测试目标团队状态映射
+func TestMapWorkflowState(t *testing.T) {
+	backlog := &model.WorkflowState{ID: uuid.New(), Name: "Backlog", Type: model.StateTypeBacklog}
+	todo := &model.WorkflowState{ID: uuid.New(), Name: "Todo", Type: model.StateTypeUnstarted, IsDefault: true}
+	inProgress := &model.WorkflowState{ID: uuid.New(), Name: "In Progress", Type: model.StateTypeStarted}
+	inReview := &model.WorkflowState{ID: uuid.New(), Name: "In Review", Type: model.StateTypeStarted}
+	targets := []*model.WorkflowState{backlog, todo, inProgress, inReview}
+
+	tests := []struct {
+		name   string
+		source *model.WorkflowState
+		want   *model.WorkflowState
+	}{
+		{"同类型同名优先", &model.WorkflowState{Name: "in review", Type: model.StateTypeStarted}, inReview},
+		{"同类型取第一个", &model.WorkflowState{Name: "Doing", Type: model.StateTypeStarted}, inProgress},
+		{"无同类型使用默认状态", &model.WorkflowState{Name: "Done", Type: model.StateTypeCompleted}, todo},
+		{"无源状态使用默认状态", nil, todo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mapWorkflowState(tt.source, targets); got != tt.want {
+				t.Errorf("mapWorkflowState() = %s, 期望 %s", got.Name, tt.want.Name)
+			}
+		})
+	}
+
+	if got := mapWorkflowState(nil, []*model.WorkflowState{backlog, inProgress}); got != backlog {
+		t.Errorf("没有默认状态时应返回第一个状态, got %s", got.Name)
+	}
+}
+
+type issueMoveFixtures struct {
+	*issueServiceFixtures
+	targetTeam    *model.Team
+	targetBacklog *model.WorkflowState
+	targetStarted *model.WorkflowState
+	service       IssueMoveService
+}
+
+func setupIssueMoveFixtures(t *testing.T, db *gorm.DB) *issueMoveFixtures {
+	base := setupIssueServiceFixtures(t, db)
+
+	targetTeam := &model.Team{WorkspaceID: base.workspaceID, Name: base.team.Name + "_Target", Key: "TGT"}
+	if err := db.Create(targetTeam).Error; err != nil {
+		t.Fatalf("创建目标团队失败: %v", err)
+	}
+	targetBacklog := &model.WorkflowState{TeamID: targetTeam.ID, Name: "Icebox", Type: model.StateTypeBacklog, Position: 0, IsDefault: true}
+	targetStarted := &model.WorkflowState{TeamID: targetTeam.ID, Name: "Doing", Type: model.StateTypeStarted, Position: 1}
+	for _, state := range []*model.WorkflowState{targetBacklog, targetStarted} {
+		if err := db.Create(state).Error; err != nil {
+			t.Fatalf("创建工作流状态失败: %v", err)
+		}
+	}
+
+	svc := NewIssueMoveService(
+		base.issueStore,
+		store.NewTeamStore(db),
+		store.NewTeamMemberStore(db),
+		store.NewWorkflowStateStore(db),
+		store.NewLabelStore(db),
+		NewActivityService(store.NewActivityStore(db)),
+	)
+
+	return &issueMoveFixtures{
+		issueServiceFixtures: base,
+		targetTeam:           targetTeam,
+		targetBacklog:        targetBacklog,
+		targetStarted:        targetStarted,
+		service:              svc,
+	}
+}
+
+func TestIssueMoveService_MoveIssue(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupIssueMoveFixtures(t, tx)
+
+	// 标签：工作区标签保留；源团队 "Bug" 按名称匹配目标团队 "bug"；源团队 "Infra" 被移除
+	workspaceLabel := &model.Label{WorkspaceID: f.workspaceID, Name: "Customer"}
+	sourceBug := &model.Label{WorkspaceID: f.workspaceID, TeamID: &f.team.ID, Name: "Bug"}
+	sourceInfra := &model.Label{WorkspaceID: f.workspaceID, TeamID: &f.team.ID, Name: "Infra"}
+	targetBug := &model.Label{WorkspaceID: f.workspaceID, TeamID: &f.targetTeam.ID, Name: "bug"}
+	for _, label := range []*model.Label{workspaceLabel, sourceBug, sourceInfra, targetBug} {
+		if err := tx.Create(label).Error; err != nil {
+			t.Fatalf("创建标签失败: %v", err)
+		}
+	}
+
+	parent := &model.Issue{
+		TeamID:      f.team.ID,
+		Title:       "Parent",
+		StatusID:    f.status.ID,
+		CreatedByID: f.userID,
+		Labels:      pq.StringArray{workspaceLabel.ID.String(), sourceBug.ID.String(), sourceInfra.ID.String()},
+	}
+	if err := f.issueStore.Create(f.ctx, parent); err != nil {
+		t.Fatalf("创建 Issue 失败: %v", err)
+	}
+	child := &model.Issue{TeamID: f.team.ID, Title: "Child", StatusID: f.status.ID, CreatedByID: f.userID, ParentID: &parent.ID}
+	if err := f.issueStore.Create(f.ctx, child); err != nil {
+		t.Fatalf("创建子 Issue 失败: %v", err)
+	}
+
+	result, err := f.service.MoveIssue(f.ctx, parent.ID.String(), &MoveIssueParams{
+		TargetTeamID:     f.targetTeam.ID.String(),
+		IncludeSubIssues: true,
+	})
+	if err != nil {
+		t.Fatalf("MoveIssue() error = %v", err)
+	}
+	if len(result.Moved) != 2 {
+		t.Fatalf("期望移动 2 个 Issue, got %d", len(result.Moved))
+	}
+	if result.Moved[0].OldIdentifier != "TST-1" || result.Moved[0].NewIdentifier != "TGT-1" {
+		t.Errorf("Unexpected identifiers: %+v", result.Moved[0])
+	}
+	if result.Moved[1].NewIdentifier != "TGT-2" {
+		t.Errorf("子 Issue 期望 TGT-2, got %s", result.Moved[1].NewIdentifier)
+	}
+	if len(result.Moved[0].DroppedLabels) != 1 || result.Moved[0].DroppedLabels[0] != "Infra" {
+		t.Errorf("期望移除 Infra 标签, got %v", result.Moved[0].DroppedLabels)
+	}
+
+	moved := result.Issue
+	if moved.TeamID != f.targetTeam.ID || moved.StatusID != f.targetBacklog.ID {
+		t.Errorf("Issue 未移动到目标团队的 Backlog 状态: team=%v status=%v", moved.TeamID, moved.StatusID)
+	}
+	wantLabels := map[string]bool{workspaceLabel.ID.String(): true, targetBug.ID.String(): true}
+	if len(moved.Labels) != len(wantLabels) {
+		t.Errorf("Labels = %v, 期望 %v", moved.Labels, wantLabels)
+	}
+	for _, id := range moved.Labels {
+		if !wantLabels[id] {
+			t.Errorf("Unexpected label %s", id)
+		}
+	}
+
+	// 旧标识符仍然可以解析
+	resolved, err := f.issueStore.ResolveIdentifier(f.ctx, f.workspaceID, "TST", 1)
+	if err != nil || resolved.ID != parent.ID {
+		t.Errorf("旧标识符 TST-1 应解析到原 Issue, err = %v", err)
+	}
+
+	// 记录活动
+	var count int64
+	tx.Model(&model.Activity{}).Where("issue_id = ? AND type = ?", parent.ID, model.ActivityTeamChanged).Count(&count)
+	if count != 1 {
+		t.Errorf("期望记录 1 条 team_changed 活动, got %d", count)
+	}
+
+	// 源团队的新 Issue 不会复用被移走的编号
+	next := &model.Issue{TeamID: f.team.ID, Title: "Next", StatusID: f.status.ID, CreatedByID: f.userID}
+	if err := f.issueStore.Create(f.ctx, next); err != nil {
+		t.Fatalf("创建 Issue 失败: %v", err)
+	}
+	if next.Number <= 2 {
+		t.Errorf("新 Issue 编号不应复用历史标识符, got %d", next.Number)
+	}
+}
+
+func TestIssueMoveService_MoveIssue_Validation(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupIssueMoveFixtures(t, tx)
+
+	issue := &model.Issue{TeamID: f.team.ID, Title: "Issue", StatusID: f.status.ID, CreatedByID: f.userID}
+	if err := f.issueStore.Create(f.ctx, issue); err != nil {
+		t.Fatalf("创建 Issue 失败: %v", err)
+	}
+
+	memberCtx := context.WithValue(context.Background(), "user_id", f.user2ID)
+	memberCtx = context.WithValue(memberCtx, "user_role", model.RoleMember)
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		params *MoveIssueParams
+	}{
+		{"同一团队", f.ctx, &MoveIssueParams{TargetTeamID: f.team.ID.String()}},
+		{"目标团队不存在", f.ctx, &MoveIssueParams{TargetTeamID: uuid.New().String()}},
+		{"状态不属于目标团队", f.ctx, &MoveIssueParams{TargetTeamID: f.targetTeam.ID.String(), StatusID: strPtr(f.status.ID.String())}},
+		{"无效的标签映射", f.ctx, &MoveIssueParams{TargetTeamID: f.targetTeam.ID.String(), LabelMapping: map[string]string{uuid.New().String(): uuid.New().String()}}},
+		{"非团队成员", memberCtx, &MoveIssueParams{TargetTeamID: f.targetTeam.ID.String()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := f.service.MoveIssue(tt.ctx, issue.ID.String(), tt.params); err == nil {
+				t.Error("MoveIssue() 期望返回错误")
+			}
+		})
+	}
+
+	// 显式指定目标状态
+	result, err := f.service.MoveIssue(f.ctx, issue.ID.String(), &MoveIssueParams{
+		TargetTeamID: f.targetTeam.ID.String(),
+		StatusID:     strPtr(f.targetStarted.ID.String()),
+	})
+	if err != nil {
+		t.Fatalf("MoveIssue() error = %v", err)
+	}
+	if result.Issue.StatusID != f.targetStarted.ID {
+		t.Errorf("StatusID = %v, 期望 %v", result.Issue.StatusID, f.targetStarted.ID)
+	}
+}
+
+func TestIssueMoveService_MoveIssue_AdminScope(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupIssueMoveFixtures(t, tx)
+
+	issue := &model.Issue{TeamID: f.team.ID, Title: "Issue", StatusID: f.status.ID, CreatedByID: f.userID}
+	if err := f.issueStore.Create(f.ctx, issue); err != nil {
+		t.Fatalf("创建 Issue 失败: %v", err)
+	}
+	params := &MoveIssueParams{TargetTeamID: f.targetTeam.ID.String()}
+
+	// 其他工作区的管理员不能跳过团队成员检查
+	otherCtx := context.WithValue(f.ctx, "workspace_id", uuid.New())
+	if _, err := f.service.MoveIssue(otherCtx, issue.ID.String(), params); err == nil {
+		t.Error("其他工作区的管理员不应能移动 Issue")
+	}
+
+	// 管理员不能移动到未加入的私有团队
+	if err := tx.Model(f.targetTeam).Update("is_private", true).Error; err != nil {
+		t.Fatalf("设置私有团队失败: %v", err)
+	}
+	if _, err := f.service.MoveIssue(f.ctx, issue.ID.String(), params); err == nil {
+		t.Error("管理员不应能移动到未加入的私有团队")
+	}
+
+	// 加入后可以移动
+	if err := tx.Create(&model.TeamMember{TeamID: f.targetTeam.ID, UserID: f.userID, Role: model.RoleMember}).Error; err != nil {
+		t.Fatalf("加入私有团队失败: %v", err)
+	}
+	if _, err := f.service.MoveIssue(f.ctx, issue.ID.String(), params); err != nil {
+		t.Errorf("MoveIssue() error = %v", err)
+	}
+}
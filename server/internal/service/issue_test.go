@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -177,6 +179,66 @@ func TestIssueService_GetIssue(t *testing.T) {
 	}
 }
 
+func TestIssueService_GetIssueByIdentifier(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	fixtures := setupIssueServiceFixtures(t, tx)
+	ctx := fixtures.ctx
+
+	issue, _ := fixtures.issueService.CreateIssue(ctx, &CreateIssueParams{
+		TeamID:   fixtures.team.ID,
+		Title:    "测试标识符查找",
+		StatusID: fixtures.status.ID,
+	})
+
+	tests := []struct {
+		name        string
+		identifier  string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:       "正常查找",
+			identifier: fmt.Sprintf("TST-%d", issue.Number),
+		},
+		{
+			name:       "小写标识符",
+			identifier: fmt.Sprintf("tst-%d", issue.Number),
+		},
+		{
+			name:        "编号不存在",
+			identifier:  fmt.Sprintf("TST-%d", issue.Number+1000),
+			wantErr:     true,
+			errContains: "不存在",
+		},
+		{
+			name:        "无效标识符",
+			identifier:  "not-valid",
+			wantErr:     true,
+			errContains: "无效",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fixtures.issueService.GetIssueByIdentifier(ctx, tt.identifier)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %v", tt.errContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetIssueByIdentifier() error = %v", err)
+			}
+			if got.ID != issue.ID {
+				t.Errorf("GetIssueByIdentifier() ID = %v, want %v", got.ID, issue.ID)
+			}
+		})
+	}
+}
+
 // =============================================================================
 // ListIssues 测试
 // =============================================================================
@@ -509,7 +571,7 @@ func setupIssueServiceFixtures(t *testing.T, db *gorm.DB) *issueServiceFixtures
 		t.Fatalf("创建工作区失败: %v", err)
 	}
 
-	// 创建用户
+	// 创建用户（同时加入工作区）
 	userID := uuid.New()
 	user := &model.User{
 		WorkspaceID:  workspace.ID,
@@ -520,7 +582,7 @@ func setupIssueServiceFixtures(t *testing.T, db *gorm.DB) *issueServiceFixtures
 		Role:         model.RoleAdmin,
 	}
 	user.ID = userID
-	if err := db.Create(user).Error; err != nil {
+	if err := store.NewUserStore(db).CreateUser(ctx, user); err != nil {
 		t.Fatalf("创建用户失败: %v", err)
 	}
 
@@ -535,7 +597,7 @@ func setupIssueServiceFixtures(t *testing.T, db *gorm.DB) *issueServiceFixtures
 		Role:         model.RoleMember,
 	}
 	user2.ID = user2ID
-	if err := db.Create(user2).Error; err != nil {
+	if err := store.NewUserStore(db).CreateUser(ctx, user2); err != nil {
 		t.Fatalf("创建第二个用户失败: %v", err)
 	}
 
@@ -0,0 +1,837 @@
+// Package service 提供业务逻辑层
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+)
+
+// 导入导出格式
+const (
+	IssueTransferFormatCSV    = "csv"
+	IssueTransferFormatNDJSON = "ndjson"
+)
+
+// JobTypeIssueImport Issue 导入任务类型
+const JobTypeIssueImport = "issue_import"
+
+// 导入可映射的目标字段
+const (
+	IssueImportFieldTitle       = "title"
+	IssueImportFieldDescription = "description"
+	IssueImportFieldStatus      = "status"
+	IssueImportFieldPriority    = "priority"
+	IssueImportFieldAssignee    = "assignee"
+	IssueImportFieldLabels      = "labels"
+	IssueImportFieldDueDate     = "due_date"
+	IssueImportFieldCompletedAt = "completed_at"
+	IssueImportFieldCancelledAt = "cancelled_at"
+)
+
+const (
+	// 导出时每批读取的 Issue 数量
+	issueExportBatchSize = 500
+	// 试运行返回的预览行数
+	issueImportPreviewSize = 20
+	// 单次导入的最大行数
+	issueImportMaxRows = 10000
+)
+
+// issueExportColumns CSV 导出列顺序
+var issueExportColumns = []string{
+	"identifier", "title", "description", "status", "priority", "assignee",
+	"labels", "due_date", "created_at", "updated_at", "completed_at", "cancelled_at",
+}
+
+// issueImportFields 支持导入的目标字段
+var issueImportFields = map[string]bool{
+	IssueImportFieldTitle:       true,
+	IssueImportFieldDescription: true,
+	IssueImportFieldStatus:      true,
+	IssueImportFieldPriority:    true,
+	IssueImportFieldAssignee:    true,
+	IssueImportFieldLabels:      true,
+	IssueImportFieldDueDate:     true,
+	IssueImportFieldCompletedAt: true,
+	IssueImportFieldCancelledAt: true,
+}
+
+// priorityNames 优先级名称（导入时可使用名称代替数字）
+var priorityNames = map[string]int{
+	"none":   model.PriorityNone,
+	"urgent": model.PriorityUrgent,
+	"high":   model.PriorityHigh,
+	"medium": model.PriorityMedium,
+	"low":    model.PriorityLow,
+}
+
+// IssueExportRecord 导出的单条 Issue 记录
+type IssueExportRecord struct {
+	Identifier  string     `json:"identifier"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Priority    int        `json:"priority"`
+	Assignee    string     `json:"assignee"`
+	Labels      []string   `json:"labels"`
+	DueDate     string     `json:"due_date,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+}
+
+// IssueImportParams 导入参数
+type IssueImportParams struct {
+	TeamID string
+	Format string
+	Data   []byte
+	// Mapping 源列名 -> 目标字段，为空时按同名列映射
+	Mapping map[string]string
+	DryRun  bool
+}
+
+// IssueImportRowError 行级错误
+type IssueImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// IssueImportPreview 试运行时的行预览
+type IssueImportPreview struct {
+	Row      int      `json:"row"`
+	Title    string   `json:"title"`
+	Status   string   `json:"status"`
+	Priority int      `json:"priority"`
+	Assignee string   `json:"assignee,omitempty"`
+	Labels   []string `json:"labels,omitempty"`
+	DueDate  string   `json:"due_date,omitempty"`
+}
+
+// IssueImportReport 导入报告
+type IssueImportReport struct {
+	DryRun  bool                  `json:"dry_run"`
+	Total   int                   `json:"total"`
+	Valid   int                   `json:"valid"`
+	Created int                   `json:"created"`
+	Errors  []IssueImportRowError `json:"errors"`
+	Preview []IssueImportPreview  `json:"preview,omitempty"`
+}
+
+// IssueTransferService 定义 Issue 导入导出服务接口
+type IssueTransferService interface {
+	// ExportIssues 将符合条件的 Issue 以 CSV 或 NDJSON 格式写入 w
+	ExportIssues(ctx context.Context, teamID string, filter *IssueFilter, format string, w io.Writer) error
+	// ImportIssues 导入 Issue；试运行时直接返回报告，否则创建后台任务
+	ImportIssues(ctx context.Context, params *IssueImportParams) (*IssueImportReport, *model.Job, error)
+}
+
+// issueImportPayload 导入任务参数
+type issueImportPayload struct {
+	TeamID string              `json:"team_id"`
+	Rows   []map[string]string `json:"rows"`
+}
+
+// issueTransferService 实现 IssueTransferService 接口
+type issueTransferService struct {
+	issueStore         store.IssueStore
+	teamStore          store.TeamStore
+	teamMemberStore    store.TeamMemberStore
+	workflowStateStore store.WorkflowStateStore
+	labelStore         store.LabelStore
+	userStore          store.UserStore
+	jobService         JobService
+	activityService    ActivityService
+	auditService       AuditService
+}
+
+// NewIssueTransferService 创建 Issue 导入导出服务实例，并注册导入任务处理函数
+func NewIssueTransferService(
+	issueStore store.IssueStore,
+	teamStore store.TeamStore,
+	teamMemberStore store.TeamMemberStore,
+	workflowStateStore store.WorkflowStateStore,
+	labelStore store.LabelStore,
+	userStore store.UserStore,
+	jobService JobService,
+	activityService ActivityService,
+) IssueTransferService {
+	s := &issueTransferService{
+		issueStore:         issueStore,
+		teamStore:          teamStore,
+		teamMemberStore:    teamMemberStore,
+		workflowStateStore: workflowStateStore,
+		labelStore:         labelStore,
+		userStore:          userStore,
+		jobService:         jobService,
+		activityService:    activityService,
+	}
+	if jobService != nil {
+		jobService.RegisterHandler(JobTypeIssueImport, s.runImportJob)
+	}
+	return s
+}
+
+// NewIssueTransferServiceWithAudit 创建记录导出审计日志的 Issue 导入导出服务实例
+func NewIssueTransferServiceWithAudit(
+	issueStore store.IssueStore,
+	teamStore store.TeamStore,
+	teamMemberStore store.TeamMemberStore,
+	workflowStateStore store.WorkflowStateStore,
+	labelStore store.LabelStore,
+	userStore store.UserStore,
+	jobService JobService,
+	activityService ActivityService,
+	auditService AuditService,
+) IssueTransferService {
+	s := NewIssueTransferService(issueStore, teamStore, teamMemberStore, workflowStateStore, labelStore, userStore, jobService, activityService).(*issueTransferService)
+	s.auditService = auditService
+	return s
+}
+
+// ExportIssues 将符合条件的 Issue 以 CSV 或 NDJSON 格式写入 w
+func (s *issueTransferService) ExportIssues(ctx context.Context, teamID string, filter *IssueFilter, format string, w io.Writer) error {
+	if format != IssueTransferFormatCSV && format != IssueTransferFormatNDJSON {
+		return fmt.Errorf("无效的导出格式: %s", format)
+	}
+
+	team, err := s.authorizeTeam(ctx, teamID)
+	if err != nil {
+		return err
+	}
+
+	labelNames, err := s.labelNamesByID(ctx, team)
+	if err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditService, &AuditEntry{
+		WorkspaceID: team.WorkspaceID,
+		Action:      model.AuditDataExported,
+		TargetType:  model.AuditTargetTeam,
+		TargetID:    team.ID.String(),
+		After:       map[string]interface{}{"resource": "issues", "format": format, "filter": filter},
+	})
+
+	var csvWriter *csv.Writer
+	var jsonEncoder *json.Encoder
+	if format == IssueTransferFormatCSV {
+		csvWriter = csv.NewWriter(w)
+		if err := csvWriter.Write(issueExportColumns); err != nil {
+			return fmt.Errorf("写入导出数据失败: %w", err)
+		}
+	} else {
+		jsonEncoder = json.NewEncoder(w)
+	}
+
+	err = s.issueStore.FindInBatches(ctx, team.ID, toStoreIssueFilter(filter), issueExportBatchSize, func(issues []model.Issue) error {
+		for i := range issues {
+			record := buildIssueExportRecord(&issues[i], team.Key, labelNames)
+			if csvWriter != nil {
+				if err := csvWriter.Write(record.csvRow()); err != nil {
+					return fmt.Errorf("写入导出数据失败: %w", err)
+				}
+				continue
+			}
+			if err := jsonEncoder.Encode(record); err != nil {
+				return fmt.Errorf("写入导出数据失败: %w", err)
+			}
+		}
+		// 每批刷新一次，保证流式输出
+		if csvWriter != nil {
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if csvWriter != nil {
+		csvWriter.Flush()
+		return csvWriter.Error()
+	}
+	return nil
+}
+
+// ImportIssues 导入 Issue；试运行时直接返回报告，否则创建后台任务
+func (s *issueTransferService) ImportIssues(ctx context.Context, params *IssueImportParams) (*IssueImportReport, *model.Job, error) {
+	team, err := s.authorizeTeam(ctx, params.TeamID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for column, field := range params.Mapping {
+		if !issueImportFields[field] {
+			return nil, nil, fmt.Errorf("无效的字段映射: %s -> %s", column, field)
+		}
+	}
+
+	rows, err := parseIssueImportRows(params.Format, params.Data, params.Mapping)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("无效的导入数据: 没有可导入的行")
+	}
+	if len(rows) > issueImportMaxRows {
+		return nil, nil, fmt.Errorf("无效的导入数据: 单次最多导入 %d 行", issueImportMaxRows)
+	}
+
+	if params.DryRun {
+		resolver, err := s.newImportResolver(ctx, team)
+		if err != nil {
+			return nil, nil, err
+		}
+		report := &IssueImportReport{DryRun: true, Total: len(rows), Errors: []IssueImportRowError{}}
+		for i, row := range rows {
+			resolved, rowErrs := resolver.resolve(ctx, i+1, row)
+			if len(rowErrs) > 0 {
+				report.Errors = append(report.Errors, rowErrs...)
+				continue
+			}
+			report.Valid++
+			if len(report.Preview) < issueImportPreviewSize {
+				report.Preview = append(report.Preview, resolved.preview)
+			}
+		}
+		return report, nil, nil
+	}
+
+	if s.jobService == nil {
+		return nil, nil, fmt.Errorf("后台任务服务不可用")
+	}
+
+	workspaceID := team.WorkspaceID
+	job := &model.Job{
+		WorkspaceID: &workspaceID,
+		Type:        JobTypeIssueImport,
+		Total:       len(rows),
+	}
+	payload := &issueImportPayload{TeamID: team.ID.String(), Rows: rows}
+	if err := s.jobService.Enqueue(ctx, job, payload); err != nil {
+		return nil, nil, fmt.Errorf("创建导入任务失败: %w", err)
+	}
+
+	return nil, job, nil
+}
+
+// runImportJob 执行导入任务，返回包含行级错误的导入报告
+func (s *issueTransferService) runImportJob(ctx context.Context, job *model.Job, progress JobProgressFunc) (interface{}, error) {
+	var payload issueImportPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("解析任务参数失败: %w", err)
+	}
+
+	if job.CreatedByID == nil {
+		return nil, fmt.Errorf("任务缺少创建者")
+	}
+	creatorID := *job.CreatedByID
+
+	team, err := s.teamStore.GetByID(ctx, payload.TeamID)
+	if err != nil {
+		return nil, fmt.Errorf("团队不存在")
+	}
+
+	resolver, err := s.newImportResolver(ctx, team)
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(payload.Rows)
+	report := &IssueImportReport{Total: total, Errors: []IssueImportRowError{}}
+	for i, row := range payload.Rows {
+		// 服务停止时中断导入，已导入的行保留在报告中
+		if err := ctx.Err(); err != nil {
+			return report, fmt.Errorf("导入已中断（已处理 %d/%d 行）: %w", i, total, err)
+		}
+
+		rowNum := i + 1
+		resolved, rowErrs := resolver.resolve(ctx, rowNum, row)
+		if len(rowErrs) > 0 {
+			report.Errors = append(report.Errors, rowErrs...)
+		} else {
+			report.Valid++
+			issue := resolved.issue
+			issue.TeamID = team.ID
+			issue.CreatedByID = creatorID
+			if err := s.issueStore.Create(ctx, issue); err != nil {
+				report.Errors = append(report.Errors, IssueImportRowError{Row: rowNum, Message: err.Error()})
+			} else {
+				report.Created++
+				s.recordCreated(ctx, issue.ID, creatorID)
+			}
+		}
+
+		if rowNum%50 == 0 || rowNum == total {
+			progress(rowNum, total)
+		}
+	}
+
+	return report, nil
+}
+
+// recordCreated 记录 Issue 创建活动
+func (s *issueTransferService) recordCreated(ctx context.Context, issueID, actorID uuid.UUID) {
+	if s.activityService == nil {
+		return
+	}
+	activity := &model.Activity{
+		IssueID: issueID,
+		Type:    model.ActivityIssueCreated,
+		ActorID: actorID,
+	}
+	if err := s.activityService.RecordActivity(ctx, activity); err != nil {
+		slog.WarnContext(ctx, "记录 Issue 创建活动失败", "issue_id", issueID, "user_id", actorID, "error", err)
+	}
+}
+
+// authorizeTeam 获取团队并校验当前用户可以访问
+func (s *issueTransferService) authorizeTeam(ctx context.Context, teamID string) (*model.Team, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+
+	if _, err := uuid.Parse(teamID); err != nil {
+		return nil, fmt.Errorf("无效的团队 ID")
+	}
+
+	team, err := s.teamStore.GetByID(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("团队不存在")
+	}
+
+	role, _ := s.teamMemberStore.GetRole(ctx, teamID, userID.String())
+	if role != "" {
+		return team, nil
+	}
+	// 管理员可以访问当前工作区的公开团队，私有团队需要先加入
+	if !team.IsPrivate && isAdminOfWorkspace(ctx, team.WorkspaceID) {
+		return team, nil
+	}
+	return nil, fmt.Errorf("无权限访问此团队")
+}
+
+// labelNamesByID 获取团队可用标签的 ID -> 名称映射
+func (s *issueTransferService) labelNamesByID(ctx context.Context, team *model.Team) (map[string]string, error) {
+	labels, err := s.labelStore.ListForTeam(ctx, team.WorkspaceID, team.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取标签列表失败: %w", err)
+	}
+	names := make(map[string]string, len(labels))
+	for _, l := range labels {
+		names[l.ID.String()] = l.Name
+	}
+	return names, nil
+}
+
+// buildIssueExportRecord 构建导出记录
+func buildIssueExportRecord(issue *model.Issue, teamKey string, labelNames map[string]string) *IssueExportRecord {
+	record := &IssueExportRecord{
+		Identifier:  issue.Identifier(teamKey),
+		Title:       issue.Title,
+		Priority:    issue.Priority,
+		Labels:      []string{},
+		CreatedAt:   issue.CreatedAt,
+		UpdatedAt:   issue.UpdatedAt,
+		CompletedAt: issue.CompletedAt,
+		CancelledAt: issue.CancelledAt,
+	}
+	if issue.Description != nil {
+		record.Description = *issue.Description
+	}
+	if issue.Status != nil {
+		record.Status = issue.Status.Name
+	}
+	if issue.Assignee != nil {
+		record.Assignee = issue.Assignee.Username
+	}
+	if issue.DueDate != nil {
+		record.DueDate = issue.DueDate.Format("2006-01-02")
+	}
+	for _, id := range issue.Labels {
+		if name, ok := labelNames[id]; ok {
+			record.Labels = append(record.Labels, name)
+		}
+	}
+	return record
+}
+
+// csvRow 按 issueExportColumns 的顺序输出 CSV 行
+func (r *IssueExportRecord) csvRow() []string {
+	return []string{
+		r.Identifier,
+		r.Title,
+		r.Description,
+		r.Status,
+		strconv.Itoa(r.Priority),
+		r.Assignee,
+		strings.Join(r.Labels, ","),
+		r.DueDate,
+		r.CreatedAt.Format(time.RFC3339),
+		r.UpdatedAt.Format(time.RFC3339),
+		formatOptionalTime(r.CompletedAt),
+		formatOptionalTime(r.CancelledAt),
+	}
+}
+
+// formatOptionalTime 格式化可选时间
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// parseIssueImportRows 解析导入数据，并按映射转换为 目标字段 -> 值 的行
+func parseIssueImportRows(format string, data []byte, mapping map[string]string) ([]map[string]string, error) {
+	var raw []map[string]string
+	var err error
+	switch format {
+	case IssueTransferFormatCSV:
+		raw, err = parseCSVRows(data)
+	case IssueTransferFormatNDJSON:
+		raw, err = parseNDJSONRows(data)
+	default:
+		return nil, fmt.Errorf("无效的导入格式: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]map[string]string, 0, len(raw))
+	for _, r := range raw {
+		row := make(map[string]string)
+		for column, value := range r {
+			field := column
+			if len(mapping) > 0 {
+				mapped, ok := mapping[column]
+				if !ok {
+					continue
+				}
+				field = mapped
+			}
+			if issueImportFields[field] {
+				row[field] = strings.TrimSpace(value)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseCSVRows 解析带表头的 CSV
+func parseCSVRows(data []byte) ([]map[string]string, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无效的 CSV 数据: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+
+	var rows []map[string]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("无效的 CSV 数据: %w", err)
+		}
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				row[column] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseNDJSONRows 解析 NDJSON，每行一个 JSON 对象
+func parseNDJSONRows(data []byte) ([]map[string]string, error) {
+	var rows []map[string]string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &obj); err != nil {
+			return nil, fmt.Errorf("无效的 NDJSON 数据（第 %d 行）: %w", line, err)
+		}
+		row := make(map[string]string, len(obj))
+		for k, v := range obj {
+			row[k] = jsonValueToString(v)
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("无效的 NDJSON 数据: %w", err)
+	}
+	return rows, nil
+}
+
+// jsonValueToString 将 JSON 值转换为字符串，数组以逗号连接
+func jsonValueToString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	case []interface{}:
+		parts := make([]string, 0, len(val))
+		for _, item := range val {
+			parts = append(parts, jsonValueToString(item))
+		}
+		return strings.Join(parts, ",")
+	default:
+		b, _ := json.Marshal(val)
+		return string(b)
+	}
+}
+
+// resolvedImportRow 解析后的导入行
+type resolvedImportRow struct {
+	issue   *model.Issue
+	preview IssueImportPreview
+}
+
+// importResolver 按名称解析状态、用户和标签
+type importResolver struct {
+	userStore    store.UserStore
+	workspaceID  uuid.UUID
+	states       map[string]*model.WorkflowState
+	defaultState *model.WorkflowState
+	labels       map[string]*model.Label
+	users        map[string]*model.User
+	missingUsers map[string]bool
+	// now 导入时间，完成或取消状态的行未提供时间时使用
+	now time.Time
+}
+
+// newImportResolver 加载团队的状态和标签，创建解析器
+func (s *issueTransferService) newImportResolver(ctx context.Context, team *model.Team) (*importResolver, error) {
+	states, err := s.workflowStateStore.ListByTeamID(ctx, team.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取工作流状态失败: %w", err)
+	}
+	labels, err := s.labelStore.ListForTeam(ctx, team.WorkspaceID, team.ID)
+	if err != nil {
+		return nil, fmt.Errorf("获取标签列表失败: %w", err)
+	}
+
+	r := &importResolver{
+		userStore:    s.userStore,
+		workspaceID:  team.WorkspaceID,
+		states:       make(map[string]*model.WorkflowState, len(states)),
+		labels:       make(map[string]*model.Label, len(labels)),
+		users:        make(map[string]*model.User),
+		missingUsers: make(map[string]bool),
+		now:          time.Now(),
+	}
+	for _, st := range states {
+		r.states[strings.ToLower(st.Name)] = st
+		if st.IsDefault && r.defaultState == nil {
+			r.defaultState = st
+		}
+	}
+	if r.defaultState == nil && len(states) > 0 {
+		r.defaultState = states[0]
+	}
+	for _, l := range labels {
+		key := strings.ToLower(l.Name)
+		// 同名时团队标签优先于工作区标签
+		if existing, ok := r.labels[key]; ok && existing.TeamID != nil {
+			continue
+		}
+		r.labels[key] = l
+	}
+	return r, nil
+}
+
+// resolve 解析单行数据，返回 Issue 或行级错误
+func (r *importResolver) resolve(ctx context.Context, rowNum int, row map[string]string) (*resolvedImportRow, []IssueImportRowError) {
+	var errs []IssueImportRowError
+	addErr := func(field, msg string) {
+		errs = append(errs, IssueImportRowError{Row: rowNum, Field: field, Message: msg})
+	}
+
+	issue := &model.Issue{}
+	preview := IssueImportPreview{Row: rowNum}
+
+	issue.Title = row[IssueImportFieldTitle]
+	if issue.Title == "" {
+		addErr(IssueImportFieldTitle, "标题不能为空")
+	} else if len([]rune(issue.Title)) > 500 {
+		addErr(IssueImportFieldTitle, "标题长度不能超过 500 个字符")
+	}
+	preview.Title = issue.Title
+
+	if desc := row[IssueImportFieldDescription]; desc != "" {
+		issue.Description = &desc
+	}
+
+	var state *model.WorkflowState
+	if name := row[IssueImportFieldStatus]; name != "" {
+		if st, ok := r.states[strings.ToLower(name)]; ok {
+			state = st
+		} else {
+			addErr(IssueImportFieldStatus, fmt.Sprintf("状态不存在: %s", name))
+		}
+	} else if r.defaultState != nil {
+		state = r.defaultState
+	} else {
+		addErr(IssueImportFieldStatus, "团队没有可用的工作流状态")
+	}
+	if state != nil {
+		issue.StatusID = state.ID
+		preview.Status = state.Name
+		// 完成或取消状态需要对应的时间，否则统计和按完成时间筛选时会被当作未完成
+		switch {
+		case state.IsCompletedType():
+			issue.CompletedAt = r.terminalTime(row, IssueImportFieldCompletedAt, addErr)
+		case state.IsCanceledType():
+			issue.CancelledAt = r.terminalTime(row, IssueImportFieldCancelledAt, addErr)
+		}
+	}
+
+	if value := row[IssueImportFieldPriority]; value != "" {
+		priority, err := parseImportPriority(value)
+		if err != nil {
+			addErr(IssueImportFieldPriority, err.Error())
+		} else {
+			issue.Priority = priority
+			preview.Priority = priority
+		}
+	}
+
+	if name := row[IssueImportFieldAssignee]; name != "" {
+		user := r.findUser(ctx, name)
+		if user == nil {
+			addErr(IssueImportFieldAssignee, fmt.Sprintf("用户不存在: %s", name))
+		} else {
+			issue.AssigneeID = &user.ID
+			preview.Assignee = user.Username
+		}
+	}
+
+	if value := row[IssueImportFieldLabels]; value != "" {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			label, ok := r.labels[strings.ToLower(name)]
+			if !ok {
+				addErr(IssueImportFieldLabels, fmt.Sprintf("标签不存在: %s", name))
+				continue
+			}
+			issue.Labels = append(issue.Labels, label.ID.String())
+			preview.Labels = append(preview.Labels, label.Name)
+		}
+	}
+
+	if value := row[IssueImportFieldDueDate]; value != "" {
+		due, err := parseImportDate(value)
+		if err != nil {
+			addErr(IssueImportFieldDueDate, fmt.Sprintf("无效的日期: %s", value))
+		} else {
+			issue.DueDate = &due
+			preview.DueDate = due.Format("2006-01-02")
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return &resolvedImportRow{issue: issue, preview: preview}, nil
+}
+
+// terminalTime 解析行中的完成或取消时间，未提供时使用导入时间
+func (r *importResolver) terminalTime(row map[string]string, field string, addErr func(field, msg string)) *time.Time {
+	value := row[field]
+	if value == "" {
+		now := r.now
+		return &now
+	}
+	t, err := parseImportDate(value)
+	if err != nil {
+		addErr(field, fmt.Sprintf("无效的时间: %s", value))
+		return nil
+	}
+	return &t
+}
+
+// findUser 在团队所属工作区的成员中按用户名或邮箱查找用户（带缓存）
+// 其他工作区的用户视为不存在，避免跨工作区指派和通过试运行探测邮箱
+func (r *importResolver) findUser(ctx context.Context, name string) *model.User {
+	key := strings.ToLower(name)
+	if user, ok := r.users[key]; ok {
+		return user
+	}
+	if r.missingUsers[key] {
+		return nil
+	}
+
+	user, err := r.userStore.GetWorkspaceUser(ctx, r.workspaceID, name)
+	if err != nil || user == nil {
+		r.missingUsers[key] = true
+		return nil
+	}
+
+	r.users[key] = user
+	return user
+}
+
+// parseImportPriority 解析优先级，支持数字（0-4）或名称
+func parseImportPriority(value string) (int, error) {
+	if p, ok := priorityNames[strings.ToLower(value)]; ok {
+		return p, nil
+	}
+	p, err := strconv.Atoi(value)
+	if err != nil || !model.PriorityIsValid(p) {
+		return 0, fmt.Errorf("无效的优先级: %s", value)
+	}
+	return p, nil
+}
+
+// parseImportDate 解析日期，支持 YYYY-MM-DD 和 RFC3339
+func parseImportDate(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
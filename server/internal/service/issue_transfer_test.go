@@ -0,0 +1,454 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/gorm"
+)
+
+// TestIssueTransferService_Interface 测试 IssueTransferService 接口定义存在
+func TestIssueTransferService_Interface(t *testing.T) {
+	var _ IssueTransferService = (*issueTransferService)(nil)
+}
+
+// =============================================================================
+// 解析函数测试
+// =============================================================================
+
+func TestParseIssueImportRows(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      string
+		data        string
+		mapping     map[string]string
+		wantRows    []map[string]string
+		wantErr     bool
+		errContains string
+	}{
+		{
+			name:   "CSV 同名列映射",
+			format: IssueTransferFormatCSV,
+			data:   "title,status,unknown\n修复登录,Todo,x\n",
+			wantRows: []map[string]string{
+				{"title": "修复登录", "status": "Todo"},
+			},
+		},
+		{
+			name:    "CSV 自定义列映射",
+			format:  IssueTransferFormatCSV,
+			data:    "Summary,State,Owner\n修复登录, Todo ,alice\n",
+			mapping: map[string]string{"Summary": "title", "State": "status", "Owner": "assignee"},
+			wantRows: []map[string]string{
+				{"title": "修复登录", "status": "Todo", "assignee": "alice"},
+			},
+		},
+		{
+			name:   "NDJSON 数组转为逗号分隔",
+			format: IssueTransferFormatNDJSON,
+			data:   "{\"title\":\"A\",\"priority\":2,\"labels\":[\"bug\",\"ui\"]}\n\n{\"title\":\"B\"}\n",
+			wantRows: []map[string]string{
+				{"title": "A", "priority": "2", "labels": "bug,ui"},
+				{"title": "B"},
+			},
+		},
+		{
+			name:        "NDJSON 格式错误",
+			format:      IssueTransferFormatNDJSON,
+			data:        "{\"title\":\"A\"}\nnot json\n",
+			wantErr:     true,
+			errContains: "第 2 行",
+		},
+		{
+			name:        "不支持的格式",
+			format:      "xlsx",
+			data:        "title\nA\n",
+			wantErr:     true,
+			errContains: "无效的导入格式",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseIssueImportRows(tt.format, []byte(tt.data), tt.mapping)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), tt.errContains) {
+					t.Errorf("Expected error containing %q, got %q", tt.errContains, err.Error())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(rows) != len(tt.wantRows) {
+				t.Fatalf("Expected %d rows, got %d", len(tt.wantRows), len(rows))
+			}
+			for i, want := range tt.wantRows {
+				if len(rows[i]) != len(want) {
+					t.Errorf("Row %d: expected %v, got %v", i, want, rows[i])
+					continue
+				}
+				for k, v := range want {
+					if rows[i][k] != v {
+						t.Errorf("Row %d field %s: expected %q, got %q", i, k, v, rows[i][k])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParseImportPriority(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{"0", model.PriorityNone, false},
+		{"2", model.PriorityHigh, false},
+		{"Urgent", model.PriorityUrgent, false},
+		{"low", model.PriorityLow, false},
+		{"5", 0, true},
+		{"critical", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseImportPriority(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseImportPriority(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseImportPriority(%q) = %d, want %d", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// ExportIssues 测试
+// =============================================================================
+
+func TestIssueTransferService_ExportIssues(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupIssueTransferFixtures(t, tx)
+
+	desc := "登录页面报错"
+	due := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	issue := &model.Issue{
+		TeamID:      f.team.ID,
+		Title:       "修复登录",
+		Description: &desc,
+		StatusID:    f.status.ID,
+		Priority:    model.PriorityHigh,
+		AssigneeID:  &f.userID,
+		Labels:      []string{f.label.ID.String()},
+		DueDate:     &due,
+		CreatedByID: f.userID,
+	}
+	if err := f.issueStore.Create(f.ctx, issue); err != nil {
+		t.Fatalf("创建 Issue 失败: %v", err)
+	}
+
+	t.Run("导出 CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := f.service.ExportIssues(f.ctx, f.team.ID.String(), nil, IssueTransferFormatCSV, &buf); err != nil {
+			t.Fatalf("ExportIssues() error = %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("Expected header + 1 row, got %d lines", len(lines))
+		}
+		if !strings.HasPrefix(lines[0], "identifier,title,") {
+			t.Errorf("Unexpected header: %s", lines[0])
+		}
+		for _, want := range []string{"TST-1", "修复登录", "Backlog", f.username, "bug", "2025-03-01"} {
+			if !strings.Contains(lines[1], want) {
+				t.Errorf("Expected row to contain %q, got %s", want, lines[1])
+			}
+		}
+	})
+
+	t.Run("导出 NDJSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := f.service.ExportIssues(f.ctx, f.team.ID.String(), nil, IssueTransferFormatNDJSON, &buf); err != nil {
+			t.Fatalf("ExportIssues() error = %v", err)
+		}
+		var record IssueExportRecord
+		if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &record); err != nil {
+			t.Fatalf("解析 NDJSON 失败: %v", err)
+		}
+		if record.Identifier != "TST-1" || record.Status != "Backlog" || record.Assignee != f.username {
+			t.Errorf("Unexpected record: %+v", record)
+		}
+		if len(record.Labels) != 1 || record.Labels[0] != "bug" {
+			t.Errorf("Expected labels [bug], got %v", record.Labels)
+		}
+	})
+
+	t.Run("无效格式", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := f.service.ExportIssues(f.ctx, f.team.ID.String(), nil, "xml", &buf)
+		if err == nil || !strings.Contains(err.Error(), "无效") {
+			t.Errorf("Expected invalid format error, got %v", err)
+		}
+	})
+
+	t.Run("非团队成员无权限", func(t *testing.T) {
+		var buf bytes.Buffer
+		ctx := context.WithValue(context.Background(), "user_id", f.user2ID)
+		ctx = context.WithValue(ctx, "user_role", model.RoleMember)
+		err := f.service.ExportIssues(ctx, f.team.ID.String(), nil, IssueTransferFormatCSV, &buf)
+		if err == nil || !strings.Contains(err.Error(), "无权限") {
+			t.Errorf("Expected permission error, got %v", err)
+		}
+	})
+}
+
+// =============================================================================
+// ImportIssues 测试
+// =============================================================================
+
+func TestIssueTransferService_ImportIssues_DryRun(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupIssueTransferFixtures(t, tx)
+
+	data := "Summary,State,Owner,Tags,Priority\n" +
+		"修复登录,backlog," + f.username + ",bug,high\n" +
+		",Backlog,,,\n" +
+		"未知状态,Doing,nobody,missing,9\n"
+	mapping := map[string]string{
+		"Summary":  IssueImportFieldTitle,
+		"State":    IssueImportFieldStatus,
+		"Owner":    IssueImportFieldAssignee,
+		"Tags":     IssueImportFieldLabels,
+		"Priority": IssueImportFieldPriority,
+	}
+
+	report, job, err := f.service.ImportIssues(f.ctx, &IssueImportParams{
+		TeamID:  f.team.ID.String(),
+		Format:  IssueTransferFormatCSV,
+		Data:    []byte(data),
+		Mapping: mapping,
+		DryRun:  true,
+	})
+	if err != nil {
+		t.Fatalf("ImportIssues() error = %v", err)
+	}
+	if job != nil {
+		t.Error("Dry run should not create a job")
+	}
+	if report.Total != 3 || report.Valid != 1 {
+		t.Errorf("Expected total=3 valid=1, got total=%d valid=%d", report.Total, report.Valid)
+	}
+	if len(report.Preview) != 1 || report.Preview[0].Assignee != f.username || report.Preview[0].Priority != model.PriorityHigh {
+		t.Errorf("Unexpected preview: %+v", report.Preview)
+	}
+
+	// 第 2 行缺标题，第 3 行状态/用户/标签/优先级均无效
+	errFields := map[int][]string{}
+	for _, e := range report.Errors {
+		errFields[e.Row] = append(errFields[e.Row], e.Field)
+	}
+	if len(errFields[2]) != 1 || errFields[2][0] != IssueImportFieldTitle {
+		t.Errorf("Row 2: expected title error, got %v", errFields[2])
+	}
+	if len(errFields[3]) != 4 {
+		t.Errorf("Row 3: expected 4 errors, got %v", errFields[3])
+	}
+
+	// 试运行不应创建 Issue
+	var count int64
+	tx.Model(&model.Issue{}).Where("team_id = ?", f.team.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("Dry run created %d issues", count)
+	}
+}
+
+func TestIssueTransferService_ImportIssues_OtherWorkspaceAssignee(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupIssueTransferFixtures(t, tx)
+
+	// 其他工作区的用户按不存在处理，不能被指派
+	other := &model.Workspace{Name: "Other", Slug: "other-" + uuid.New().String()[:8]}
+	if err := tx.Create(other).Error; err != nil {
+		t.Fatalf("创建工作区失败: %v", err)
+	}
+	outsider := &model.User{
+		WorkspaceID:  other.ID,
+		Email:        "outsider-" + uuid.New().String()[:8] + "@example.com",
+		Username:     "outsider" + uuid.New().String()[:8],
+		Name:         "Outsider",
+		PasswordHash: "hash",
+		Role:         model.RoleMember,
+	}
+	if err := store.NewUserStore(tx).CreateUser(f.ctx, outsider); err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+
+	data := "title,assignee\n" +
+		"按邮箱," + outsider.Email + "\n" +
+		"按用户名," + outsider.Username + "\n"
+	report, _, err := f.service.ImportIssues(f.ctx, &IssueImportParams{
+		TeamID: f.team.ID.String(),
+		Format: IssueTransferFormatCSV,
+		Data:   []byte(data),
+		DryRun: true,
+	})
+	if err != nil {
+		t.Fatalf("ImportIssues() error = %v", err)
+	}
+	if report.Valid != 0 || len(report.Errors) != 2 {
+		t.Fatalf("Expected 2 assignee errors, got valid=%d errors=%+v", report.Valid, report.Errors)
+	}
+	for _, e := range report.Errors {
+		if e.Field != IssueImportFieldAssignee {
+			t.Errorf("Row %d: expected assignee error, got %+v", e.Row, e)
+		}
+	}
+}
+
+func TestIssueTransferService_ImportIssues_Job(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupIssueTransferFixtures(t, tx)
+	done := &model.WorkflowState{TeamID: f.team.ID, Name: "Shipped", Type: model.StateTypeCompleted}
+	if err := tx.Create(done).Error; err != nil {
+		t.Fatalf("创建完成状态失败: %v", err)
+	}
+
+	data := "{\"title\":\"第一条\",\"labels\":[\"bug\"],\"due_date\":\"2025-03-01\"}\n" +
+		"{\"title\":\"第二条\",\"assignee\":\"" + f.username + "\"}\n" +
+		"{\"title\":\"第三条\",\"status\":\"Nope\"}\n" +
+		"{\"title\":\"第四条\",\"status\":\"shipped\",\"completed_at\":\"2025-02-01T10:00:00Z\"}\n" +
+		"{\"title\":\"第五条\",\"status\":\"Shipped\"}\n"
+
+	_, job, err := f.service.ImportIssues(f.ctx, &IssueImportParams{
+		TeamID: f.team.ID.String(),
+		Format: IssueTransferFormatNDJSON,
+		Data:   []byte(data),
+	})
+	if err != nil {
+		t.Fatalf("ImportIssues() error = %v", err)
+	}
+	if job == nil || job.Status != model.JobStatusPending {
+		t.Fatalf("Expected pending job, got %+v", job)
+	}
+
+	// 直接同步执行任务，避免测试依赖后台协程
+	svc := f.service.(*issueTransferService)
+	stored, err := f.jobStore.GetByID(f.ctx, job.ID)
+	if err != nil {
+		t.Fatalf("获取任务失败: %v", err)
+	}
+	var lastDone int
+	result, err := svc.runImportJob(f.ctx, stored, func(done, total int) { lastDone = done })
+	if err != nil {
+		t.Fatalf("runImportJob() error = %v", err)
+	}
+
+	report := result.(*IssueImportReport)
+	if report.Created != 4 || len(report.Errors) != 1 || report.Errors[0].Row != 3 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if lastDone != 5 {
+		t.Errorf("Expected progress 5, got %d", lastDone)
+	}
+
+	var issues []model.Issue
+	tx.Where("team_id = ?", f.team.ID).Order("number ASC").Find(&issues)
+	if len(issues) != 4 {
+		t.Fatalf("Expected 4 issues, got %d", len(issues))
+	}
+	if issues[0].CompletedAt != nil || issues[0].CancelledAt != nil {
+		t.Error("Open issue should not have completed_at or cancelled_at")
+	}
+	// 完成状态的行使用提供的完成时间，未提供时使用导入时间
+	if issues[2].StatusID != done.ID || issues[2].CompletedAt == nil || !issues[2].CompletedAt.Equal(time.Date(2025, 2, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected completed_at 2025-02-01T10:00:00Z, got %v", issues[2].CompletedAt)
+	}
+	if issues[3].CompletedAt == nil {
+		t.Error("Completed issue without completed_at should use the import time")
+	}
+	if issues[0].StatusID != f.status.ID {
+		t.Error("Issue without status should use the team's default state")
+	}
+	if len(issues[0].Labels) != 1 || issues[0].Labels[0] != f.label.ID.String() {
+		t.Errorf("Expected label %s, got %v", f.label.ID, issues[0].Labels)
+	}
+	if issues[1].AssigneeID == nil || *issues[1].AssigneeID != f.userID {
+		t.Error("Assignee should be resolved by username")
+	}
+}
+
+// =============================================================================
+// 测试辅助
+// =============================================================================
+
+type issueTransferFixtures struct {
+	*issueServiceFixtures
+	username string
+	label    *model.Label
+	jobStore store.JobStore
+	service  IssueTransferService
+}
+
+func setupIssueTransferFixtures(t *testing.T, db *gorm.DB) *issueTransferFixtures {
+	base := setupIssueServiceFixtures(t, db)
+
+	// 将状态设为默认状态
+	if err := db.Model(base.status).Update("is_default", true).Error; err != nil {
+		t.Fatalf("更新默认状态失败: %v", err)
+	}
+
+	label := &model.Label{
+		WorkspaceID: base.workspaceID,
+		TeamID:      &base.team.ID,
+		Name:        "bug",
+	}
+	if err := db.Create(label).Error; err != nil {
+		t.Fatalf("创建标签失败: %v", err)
+	}
+
+	var user model.User
+	if err := db.Where("id = ?", base.userID).First(&user).Error; err != nil {
+		t.Fatalf("获取用户失败: %v", err)
+	}
+
+	jobStore := store.NewJobStore(db)
+	svc := NewIssueTransferService(
+		base.issueStore,
+		store.NewTeamStore(db),
+		store.NewTeamMemberStore(db),
+		store.NewWorkflowStateStore(db),
+		store.NewLabelStore(db),
+		store.NewUserStore(db),
+		NewJobService(jobStore),
+		nil,
+	)
+
+	return &issueTransferFixtures{
+		issueServiceFixtures: base,
+		username:             user.Username,
+		label:                label,
+		jobStore:             jobStore,
+		service:              svc,
+	}
+}
@@ -0,0 +1,252 @@
+// Package service 提供业务逻辑层
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/metrics"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+)
+
+// 默认任务队列容量
+const defaultJobQueueSize = 100
+
+// JobProgressFunc 任务进度回调
+type JobProgressFunc func(done, total int)
+
+// JobHandlerFunc 任务处理函数，返回值会被序列化为任务结果
+type JobHandlerFunc func(ctx context.Context, job *model.Job, progress JobProgressFunc) (interface{}, error)
+
+// JobService 定义后台任务服务接口
+type JobService interface {
+	// RegisterHandler 注册任务类型的处理函数
+	RegisterHandler(jobType string, handler JobHandlerFunc)
+	// Enqueue 创建任务并放入队列
+	Enqueue(ctx context.Context, job *model.Job, payload interface{}) error
+	// GetJob 获取任务（仅创建者或任务所属工作区的管理员可查看）
+	GetJob(ctx context.Context, jobID string) (*model.Job, error)
+	// Start 启动后台执行协程，恢复重启前尚未开始的任务，并将执行中被中断的任务标记为失败
+	Start(ctx context.Context)
+}
+
+// jobService 实现 JobService 接口
+type jobService struct {
+	jobStore store.JobStore
+
+	mu       sync.RWMutex
+	handlers map[string]JobHandlerFunc
+	queue    chan uuid.UUID
+}
+
+// NewJobService 创建后台任务服务实例
+func NewJobService(jobStore store.JobStore) JobService {
+	return &jobService{
+		jobStore: jobStore,
+		handlers: make(map[string]JobHandlerFunc),
+		queue:    make(chan uuid.UUID, defaultJobQueueSize),
+	}
+}
+
+// RegisterHandler 注册任务类型的处理函数
+func (s *jobService) RegisterHandler(jobType string, handler JobHandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[jobType] = handler
+}
+
+// Enqueue 创建任务并放入队列
+func (s *jobService) Enqueue(ctx context.Context, job *model.Job, payload interface{}) error {
+	if job.Type == "" {
+		return fmt.Errorf("任务类型不能为空")
+	}
+
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("序列化任务参数失败: %w", err)
+		}
+		job.Payload = data
+	}
+
+	if job.CreatedByID == nil {
+		if userID, ok := ctx.Value("user_id").(uuid.UUID); ok {
+			job.CreatedByID = &userID
+		}
+	}
+
+	job.Status = model.JobStatusPending
+	if err := s.jobStore.Create(ctx, job); err != nil {
+		return err
+	}
+
+	select {
+	case s.queue <- job.ID:
+		metrics.SetJobQueueDepth(len(s.queue))
+	default:
+		// 队列已满，任务保持 pending，下次启动时恢复执行
+		slog.WarnContext(ctx, "任务队列已满，任务将延迟执行", "job_id", job.ID)
+	}
+
+	return nil
+}
+
+// GetJob 获取任务（仅创建者或任务所属工作区的管理员可查看）
+func (s *jobService) GetJob(ctx context.Context, jobID string) (*model.Job, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+
+	id, err := uuid.Parse(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("无效的任务 ID")
+	}
+
+	job, err := s.jobStore.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("任务不存在")
+	}
+
+	if job.CreatedByID != nil && *job.CreatedByID == userID {
+		return job, nil
+	}
+	// 管理员只能查看当前工作区的任务，不属于任何工作区的任务只有全局管理员可以查看
+	workspaceID := uuid.Nil
+	if job.WorkspaceID != nil {
+		workspaceID = *job.WorkspaceID
+	}
+	if !isAdminOfWorkspace(ctx, workspaceID) {
+		return nil, fmt.Errorf("无权限查看此任务")
+	}
+	return job, nil
+}
+
+// Start 启动后台执行协程，恢复重启前尚未开始的任务，并将执行中被中断的任务标记为失败
+func (s *jobService) Start(ctx context.Context) {
+	go s.worker(ctx)
+
+	jobs, err := s.jobStore.ListUnfinished(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "恢复未完成任务失败", "error", err)
+		return
+	}
+
+	// 重启前正在执行的任务可能已产生部分结果，重新执行会重复写入，直接标记为失败
+	pending := make([]uuid.UUID, 0, len(jobs))
+	for _, job := range jobs {
+		if job.Status != model.JobStatusRunning {
+			pending = append(pending, job.ID)
+			continue
+		}
+		msg := fmt.Sprintf("服务重启导致任务中断（已处理 %d/%d），请确认已完成的部分后重新提交", job.Progress, job.Total)
+		if err := s.jobStore.Finish(ctx, job.ID, model.JobStatusFailed, nil, &msg); err != nil {
+			slog.WarnContext(ctx, "更新任务状态失败", "job_id", job.ID, "error", err)
+		}
+	}
+
+	go func() {
+		for _, id := range pending {
+			select {
+			case s.queue <- id:
+				metrics.SetJobQueueDepth(len(s.queue))
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// worker 顺序执行队列中的任务
+func (s *jobService) worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-s.queue:
+			metrics.SetJobQueueDepth(len(s.queue))
+			s.run(ctx, id)
+		}
+	}
+}
+
+// run 执行单个任务
+func (s *jobService) run(ctx context.Context, id uuid.UUID) {
+	job, err := s.jobStore.GetByID(ctx, id)
+	if err != nil {
+		slog.WarnContext(ctx, "加载任务失败", "job_id", id, "error", err)
+		return
+	}
+	if job.IsFinished() {
+		return
+	}
+
+	s.mu.RLock()
+	handler, ok := s.handlers[job.Type]
+	s.mu.RUnlock()
+	if !ok {
+		msg := fmt.Sprintf("未注册的任务类型: %s", job.Type)
+		if err := s.jobStore.Finish(ctx, id, model.JobStatusFailed, nil, &msg); err != nil {
+			slog.WarnContext(ctx, "更新任务状态失败", "job_id", id, "error", err)
+		}
+		return
+	}
+
+	if err := s.jobStore.MarkRunning(ctx, id); err != nil {
+		slog.WarnContext(ctx, "更新任务状态失败", "job_id", id, "error", err)
+		return
+	}
+
+	// 任务以创建者身份执行
+	jobCtx := ctx
+	if job.CreatedByID != nil {
+		jobCtx = context.WithValue(jobCtx, "user_id", *job.CreatedByID)
+	}
+
+	progress := func(done, total int) {
+		if err := s.jobStore.UpdateProgress(ctx, id, done, total); err != nil {
+			slog.WarnContext(ctx, "更新任务进度失败", "job_id", id, "error", err)
+		}
+	}
+
+	start := time.Now()
+	result, runErr := s.safeRun(jobCtx, handler, job, progress)
+
+	var resultBytes []byte
+	if result != nil {
+		resultBytes, err = json.Marshal(result)
+		if err != nil {
+			slog.WarnContext(ctx, "序列化任务结果失败", "job_id", id, "error", err)
+		}
+	}
+
+	status := model.JobStatusSucceeded
+	var errMsg *string
+	if runErr != nil {
+		status = model.JobStatusFailed
+		msg := runErr.Error()
+		errMsg = &msg
+	}
+	metrics.ObserveJob(job.Type, string(status), start.Sub(job.CreatedAt), time.Since(start))
+
+	// 服务停止导致任务中断时仍然保存已执行部分的结果
+	if err := s.jobStore.Finish(context.WithoutCancel(ctx), id, status, resultBytes, errMsg); err != nil {
+		slog.WarnContext(ctx, "更新任务状态失败", "job_id", id, "error", err)
+	}
+}
+
+// safeRun 执行处理函数并捕获 panic，避免单个任务拖垮后台协程
+func (s *jobService) safeRun(ctx context.Context, handler JobHandlerFunc, job *model.Job, progress JobProgressFunc) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("任务执行异常: %v", r)
+		}
+	}()
+	return handler(ctx, job, progress)
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeJobStore 内存中的 JobStore
+type fakeJobStore struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]*model.Job
+}
+
+func newFakeJobStore(jobs ...*model.Job) *fakeJobStore {
+	f := &fakeJobStore{jobs: make(map[uuid.UUID]*model.Job)}
+	for _, job := range jobs {
+		f.jobs[job.ID] = job
+	}
+	return f
+}
+
+func (f *fakeJobStore) Create(ctx context.Context, job *model.Job) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	job.ID = uuid.New()
+	f.jobs[job.ID] = job
+	return nil
+}
+
+func (f *fakeJobStore) GetByID(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	job := *f.jobs[id]
+	return &job, nil
+}
+
+func (f *fakeJobStore) MarkRunning(ctx context.Context, id uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.jobs[id].Status = model.JobStatusRunning
+	return nil
+}
+
+func (f *fakeJobStore) UpdateProgress(ctx context.Context, id uuid.UUID, progress, total int) error {
+	return nil
+}
+
+func (f *fakeJobStore) Finish(ctx context.Context, id uuid.UUID, status model.JobStatus, result []byte, errMsg *string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.jobs[id].Status = status
+	f.jobs[id].Error = errMsg
+	return nil
+}
+
+func (f *fakeJobStore) ListUnfinished(ctx context.Context) ([]model.Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var jobs []model.Job
+	for _, job := range f.jobs {
+		if !job.IsFinished() {
+			jobs = append(jobs, *job)
+		}
+	}
+	return jobs, nil
+}
+
+func (f *fakeJobStore) status(id uuid.UUID) model.JobStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.jobs[id].Status
+}
+
+func TestJobService_Start_FailsInterruptedJobs(t *testing.T) {
+	running := &model.Job{Model: model.Model{ID: uuid.New()}, Type: "test", Status: model.JobStatusRunning, Progress: 3, Total: 10}
+	pending := &model.Job{Model: model.Model{ID: uuid.New()}, Type: "test", Status: model.JobStatusPending}
+	jobStore := newFakeJobStore(running, pending)
+
+	svc := NewJobService(jobStore)
+	var mu sync.Mutex
+	var executed []uuid.UUID
+	svc.RegisterHandler("test", func(ctx context.Context, job *model.Job, progress JobProgressFunc) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		executed = append(executed, job.ID)
+		return nil, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc.Start(ctx)
+
+	// 执行中被中断的任务直接标记为失败，不会重新执行
+	assert.Equal(t, model.JobStatusFailed, jobStore.status(running.ID))
+	require.NotNil(t, jobStore.jobs[running.ID].Error)
+	assert.Contains(t, *jobStore.jobs[running.ID].Error, "3/10")
+
+	// 尚未开始的任务恢复执行
+	assert.Eventually(t, func() bool {
+		return jobStore.status(pending.ID) == model.JobStatusSucceeded
+	}, time.Second, 10*time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []uuid.UUID{pending.ID}, executed)
+}
+
+func TestJobService_GetJob(t *testing.T) {
+	creatorID := uuid.New()
+	workspaceID := uuid.New()
+	job := &model.Job{Model: model.Model{ID: uuid.New()}, Type: "test", WorkspaceID: &workspaceID, CreatedByID: &creatorID}
+	systemJob := &model.Job{Model: model.Model{ID: uuid.New()}, Type: "test"}
+	svc := NewJobService(newFakeJobStore(job, systemJob))
+
+	userCtx := func(userID uuid.UUID, role model.Role, workspaceID uuid.UUID) context.Context {
+		ctx := context.WithValue(context.Background(), "user_id", userID)
+		ctx = context.WithValue(ctx, "user_role", role)
+		return context.WithValue(ctx, "workspace_id", workspaceID)
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		job     *model.Job
+		wantErr bool
+	}{
+		{"创建者", userCtx(creatorID, model.RoleMember, workspaceID), job, false},
+		{"其他成员", userCtx(uuid.New(), model.RoleMember, workspaceID), job, true},
+		{"当前工作区的管理员", userCtx(uuid.New(), model.RoleAdmin, workspaceID), job, false},
+		{"其他工作区的管理员", userCtx(uuid.New(), model.RoleAdmin, uuid.New()), job, true},
+		{"不属于工作区的任务", userCtx(uuid.New(), model.RoleAdmin, workspaceID), systemJob, true},
+		{"全局管理员", userCtx(uuid.New(), model.RoleGlobalAdmin, uuid.New()), systemJob, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.GetJob(tt.ctx, tt.job.ID.String())
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
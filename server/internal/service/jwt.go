@@ -3,6 +3,7 @@ package service
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -11,19 +12,55 @@ import (
 	"github.com/liwei0526vip/mylinear/internal/model"
 )
 
+// TokenTypeAccess 访问令牌类型，只有访问令牌可用于调用 API
+const TokenTypeAccess = "access"
+
+// TokenTypeTwoFactor 两步验证挑战令牌类型，密码验证通过后签发，只能用于完成两步验证
+const TokenTypeTwoFactor = "2fa"
+
+// twoFactorTokenExpiry 两步验证挑战令牌有效期
+const twoFactorTokenExpiry = 5 * time.Minute
+
 // TokenClaims 令牌声明结构
 type TokenClaims struct {
 	UserID string
 	Email  string
 	Role   string
-	Type   string // "access" 或 "refresh"
+	Type   string // "access"、"refresh" 或 "2fa"
 	JTI    string // 令牌唯一标识符
+
+	// SessionID 登录会话 ID，会话吊销后令牌失效；旧版本签发的令牌为空
+	SessionID string
+	// WorkspaceID 令牌所属的工作区，Role 为用户在该工作区的角色；为空时使用用户的主工作区
+	WorkspaceID string
+
+	// OAuth2 访问令牌专有字段，普通登录令牌为空
+	ClientID string   // 签发给的 OAuth 应用
+	GrantID  string   // 对应的 OAuth 授权，吊销授权后令牌失效
+	Scopes   []string // 权限范围
+
+	ExpiresAt time.Time // 过期时间
+}
+
+// IsOAuth 是否为签发给 OAuth 应用的访问令牌
+func (c *TokenClaims) IsOAuth() bool {
+	return c.ClientID != ""
 }
 
 // JWTService 定义 JWT 服务接口
 type JWTService interface {
 	GenerateAccessToken(userID uuid.UUID, email string, role model.Role) (string, error)
-	GenerateRefreshToken(userID uuid.UUID) (string, error)
+	// GenerateSessionAccessToken 生成属于登录会话的访问令牌，会话吊销后失效
+	// role 为用户在 workspaceID 工作区的角色
+	GenerateSessionAccessToken(userID uuid.UUID, email string, role model.Role, workspaceID, sessionID uuid.UUID) (string, error)
+	// GenerateRefreshToken 生成属于登录会话的刷新令牌
+	GenerateRefreshToken(userID, sessionID uuid.UUID) (string, error)
+	// GenerateTwoFactorToken 生成两步验证挑战令牌（有效期 5 分钟）
+	GenerateTwoFactorToken(userID uuid.UUID) (string, error)
+	// GenerateOAuthAccessToken 为 OAuth 应用签发代表用户的访问令牌
+	GenerateOAuthAccessToken(userID uuid.UUID, email string, role model.Role, clientID string, grantID uuid.UUID, scopes []string) (string, error)
+	// AccessTokenExpiry 访问令牌有效期
+	AccessTokenExpiry() time.Duration
 	ValidateToken(tokenString string) (*TokenClaims, error)
 	GetTokenClaims(tokenString string) (*TokenClaims, error)
 }
@@ -46,6 +83,12 @@ func NewJWTService(cfg *config.Config) JWTService {
 
 // GenerateAccessToken 生成访问令牌
 func (s *jwtService) GenerateAccessToken(userID uuid.UUID, email string, role model.Role) (string, error) {
+	return s.GenerateSessionAccessToken(userID, email, role, uuid.Nil, uuid.Nil)
+}
+
+// GenerateSessionAccessToken 生成属于登录会话的访问令牌
+// workspaceID / sessionID 为 uuid.Nil 时不写入对应声明
+func (s *jwtService) GenerateSessionAccessToken(userID uuid.UUID, email string, role model.Role, workspaceID, sessionID uuid.UUID) (string, error) {
 	now := time.Now()
 	claims := jwt.MapClaims{
 		"sub":   userID.String(),
@@ -54,15 +97,46 @@ func (s *jwtService) GenerateAccessToken(userID uuid.UUID, email string, role mo
 		"exp":   now.Add(s.accessExpiry).Unix(),
 		"iat":   now.Unix(),
 		"jti":   uuid.New().String(),
-		"type":  "access",
+		"type":  TokenTypeAccess,
+	}
+	if sessionID != uuid.Nil {
+		claims["sid"] = sessionID.String()
+	}
+	if workspaceID != uuid.Nil {
+		claims["wid"] = workspaceID.String()
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(s.secret)
 }
 
-// GenerateRefreshToken 生成刷新令牌
-func (s *jwtService) GenerateRefreshToken(userID uuid.UUID) (string, error) {
+// GenerateOAuthAccessToken 为 OAuth 应用签发代表用户的访问令牌
+func (s *jwtService) GenerateOAuthAccessToken(userID uuid.UUID, email string, role model.Role, clientID string, grantID uuid.UUID, scopes []string) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":       userID.String(),
+		"email":     email,
+		"role":      string(role),
+		"exp":       now.Add(s.accessExpiry).Unix(),
+		"iat":       now.Unix(),
+		"jti":       uuid.New().String(),
+		"type":      TokenTypeAccess,
+		"client_id": clientID,
+		"grant_id":  grantID.String(),
+		"scope":     strings.Join(scopes, " "),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.secret)
+}
+
+// AccessTokenExpiry 访问令牌有效期
+func (s *jwtService) AccessTokenExpiry() time.Duration {
+	return s.accessExpiry
+}
+
+// GenerateRefreshToken 生成属于登录会话的刷新令牌
+func (s *jwtService) GenerateRefreshToken(userID, sessionID uuid.UUID) (string, error) {
 	now := time.Now()
 	claims := jwt.MapClaims{
 		"sub":  userID.String(),
@@ -70,6 +144,22 @@ func (s *jwtService) GenerateRefreshToken(userID uuid.UUID) (string, error) {
 		"exp":  now.Add(s.refreshExpiry).Unix(),
 		"iat":  now.Unix(),
 		"jti":  uuid.New().String(),
+		"sid":  sessionID.String(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.secret)
+}
+
+// GenerateTwoFactorToken 生成两步验证挑战令牌（有效期 5 分钟）
+func (s *jwtService) GenerateTwoFactorToken(userID uuid.UUID) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":  userID.String(),
+		"type": TokenTypeTwoFactor,
+		"exp":  now.Add(twoFactorTokenExpiry).Unix(),
+		"iat":  now.Unix(),
+		"jti":  uuid.New().String(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -123,7 +213,12 @@ func (s *jwtService) GetTokenClaims(tokenString string) (*TokenClaims, error) {
 	}
 
 	result := &TokenClaims{
-		JTI: getStringClaim(claims, "jti"),
+		JTI:         getStringClaim(claims, "jti"),
+		SessionID:   getStringClaim(claims, "sid"),
+		WorkspaceID: getStringClaim(claims, "wid"),
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		result.ExpiresAt = exp.Time
 	}
 
 	if sub, ok := claims["sub"]; ok {
@@ -138,6 +233,11 @@ func (s *jwtService) GetTokenClaims(tokenString string) (*TokenClaims, error) {
 	if tokenType, ok := claims["type"]; ok {
 		result.Type = fmt.Sprintf("%v", tokenType)
 	}
+	if clientID, ok := claims["client_id"]; ok {
+		result.ClientID = fmt.Sprintf("%v", clientID)
+		result.GrantID = getStringClaim(claims, "grant_id")
+		result.Scopes = strings.Fields(getStringClaim(claims, "scope"))
+	}
 
 	return result, nil
 }
@@ -150,7 +150,7 @@ func TestJWTService_GenerateRefreshToken(t *testing.T) {
 
 	userID := uuid.New()
 
-	token, err := service.GenerateRefreshToken(userID)
+	token, err := service.GenerateRefreshToken(userID, uuid.New())
 	if err != nil {
 		t.Errorf("GenerateRefreshToken() error = %v", err)
 		return
@@ -171,8 +171,9 @@ func TestJWTService_GenerateRefreshToken_Claims(t *testing.T) {
 	service := NewJWTService(cfg)
 
 	userID := uuid.New()
+	sessionID := uuid.New()
 
-	tokenString, err := service.GenerateRefreshToken(userID)
+	tokenString, err := service.GenerateRefreshToken(userID, sessionID)
 	if err != nil {
 		t.Fatalf("GenerateRefreshToken() error = %v", err)
 	}
@@ -209,6 +210,42 @@ func TestJWTService_GenerateRefreshToken_Claims(t *testing.T) {
 	if claims["jti"] == nil {
 		t.Error("缺少 jti claim")
 	}
+
+	// 验证 sid (会话ID)
+	if claims["sid"] != sessionID.String() {
+		t.Errorf("sid = %v, want %v", claims["sid"], sessionID.String())
+	}
+}
+
+// TestJWTService_GenerateSessionAccessToken 测试会话访问令牌包含会话 ID 和工作区 ID
+func TestJWTService_GenerateSessionAccessToken(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret:        "test-secret-for-session",
+		JWTAccessExpiry:  15 * time.Minute,
+		JWTRefreshExpiry: 7 * 24 * time.Hour,
+	}
+	service := NewJWTService(cfg)
+
+	sessionID := uuid.New()
+	workspaceID := uuid.New()
+	token, err := service.GenerateSessionAccessToken(uuid.New(), "session@example.com", model.RoleMember, workspaceID, sessionID)
+	if err != nil {
+		t.Fatalf("GenerateSessionAccessToken() error = %v", err)
+	}
+	claims, err := service.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if claims.Type != TokenTypeAccess || claims.SessionID != sessionID.String() || claims.WorkspaceID != workspaceID.String() {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	// 不关联会话的访问令牌没有 sid / wid
+	token, _ = service.GenerateAccessToken(uuid.New(), "session@example.com", model.RoleMember)
+	claims, _ = service.ValidateToken(token)
+	if claims.SessionID != "" || claims.WorkspaceID != "" {
+		t.Errorf("SessionID = %q, WorkspaceID = %q, want empty", claims.SessionID, claims.WorkspaceID)
+	}
 }
 
 // TestJWTService_ValidateToken 测试令牌验证
@@ -308,3 +345,67 @@ func TestJWTService_GetTokenClaims(t *testing.T) {
 		t.Errorf("Role = %v, want %v", claims.Role, role)
 	}
 }
+
+// TestJWTService_GenerateOAuthAccessToken 测试 OAuth 访问令牌 claims
+func TestJWTService_GenerateOAuthAccessToken(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret:        "test-secret-key-for-oauth",
+		JWTAccessExpiry:  15 * time.Minute,
+		JWTRefreshExpiry: 7 * 24 * time.Hour,
+	}
+	service := NewJWTService(cfg)
+
+	userID := uuid.New()
+	grantID := uuid.New()
+	tokenString, err := service.GenerateOAuthAccessToken(userID, "oauth@example.com", model.RoleMember, "client-1", grantID, []string{model.APIKeyScopeRead, model.APIKeyScopeWrite})
+	if err != nil {
+		t.Fatalf("GenerateOAuthAccessToken() error = %v", err)
+	}
+
+	claims, err := service.ValidateToken(tokenString)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if !claims.IsOAuth() || claims.ClientID != "client-1" || claims.GrantID != grantID.String() {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+	if len(claims.Scopes) != 2 || claims.Scopes[1] != model.APIKeyScopeWrite {
+		t.Errorf("Scopes = %v", claims.Scopes)
+	}
+	if service.AccessTokenExpiry() != 15*time.Minute {
+		t.Errorf("AccessTokenExpiry() = %v", service.AccessTokenExpiry())
+	}
+
+	// 普通登录令牌不是 OAuth 令牌
+	plain, _ := service.GenerateAccessToken(userID, "oauth@example.com", model.RoleMember)
+	plainClaims, _ := service.ValidateToken(plain)
+	if plainClaims.IsOAuth() {
+		t.Error("普通访问令牌不应识别为 OAuth 令牌")
+	}
+}
+
+// TestJWTService_GenerateTwoFactorToken 测试两步验证挑战令牌
+func TestJWTService_GenerateTwoFactorToken(t *testing.T) {
+	cfg := &config.Config{
+		JWTSecret:        "test-secret-for-2fa",
+		JWTAccessExpiry:  15 * time.Minute,
+		JWTRefreshExpiry: 7 * 24 * time.Hour,
+	}
+	service := NewJWTService(cfg)
+	userID := uuid.New()
+
+	token, err := service.GenerateTwoFactorToken(userID)
+	if err != nil {
+		t.Fatalf("GenerateTwoFactorToken() error = %v", err)
+	}
+	claims, err := service.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	if claims.Type != TokenTypeTwoFactor || claims.UserID != userID.String() {
+		t.Errorf("Unexpected claims: %+v", claims)
+	}
+	if claims.ExpiresAt.After(time.Now().Add(twoFactorTokenExpiry + time.Second)) {
+		t.Errorf("ExpiresAt = %v, 有效期不应超过 %v", claims.ExpiresAt, twoFactorTokenExpiry)
+	}
+}
@@ -0,0 +1,624 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/ldap"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// JobTypeLDAPSync LDAP 目录同步任务类型
+const JobTypeLDAPSync = "ldap_sync"
+
+// ldapIdentityIssuer LDAP 身份在 UserIdentity 中的 issuer，subject 为目录中的 UID
+const ldapIdentityIssuer = "ldap"
+
+// LDAP 同步变更类型
+const (
+	LDAPSyncCreateUser       = "create_user"
+	LDAPSyncLinkUser         = "link_user"
+	LDAPSyncUpdateUser       = "update_user"
+	LDAPSyncDeactivateUser   = "deactivate_user"
+	LDAPSyncReactivateUser   = "reactivate_user"
+	LDAPSyncAddTeamMember    = "add_team_member"
+	LDAPSyncUpdateTeamMember = "update_team_member"
+	LDAPSyncRemoveTeamMember = "remove_team_member"
+	LDAPSyncSkip             = "skip"
+)
+
+// LDAPSyncChange 一次同步中的单项变更
+type LDAPSyncChange struct {
+	Action string     `json:"action"`
+	Email  string     `json:"email,omitempty"`
+	DN     string     `json:"dn,omitempty"`
+	Team   string     `json:"team,omitempty"`
+	Role   model.Role `json:"role,omitempty"`
+	Reason string     `json:"reason,omitempty"`
+}
+
+// LDAPSyncReport 同步结果；DryRun 为 true 时只列出将要执行的变更
+type LDAPSyncReport struct {
+	DryRun  bool             `json:"dry_run"`
+	Users   int              `json:"users"`
+	Groups  int              `json:"groups"`
+	Changes []LDAPSyncChange `json:"changes"`
+	Counts  map[string]int   `json:"counts"`
+	Errors  []string         `json:"errors"`
+}
+
+// add 记录一项变更
+func (r *LDAPSyncReport) add(change LDAPSyncChange) {
+	r.Changes = append(r.Changes, change)
+	r.Counts[change.Action]++
+}
+
+// LDAPService 定义 LDAP 登录和目录同步服务接口
+type LDAPService interface {
+	// Enabled 是否启用 LDAP
+	Enabled() bool
+	// Login 使用 LDAP 账号登录，首次登录时自动创建用户
+	Login(ctx context.Context, login, password string) (*model.User, string, string, error)
+	// Sync 同步目录中的用户和用户组映射的团队成员
+	Sync(ctx context.Context, dryRun bool) (*LDAPSyncReport, error)
+	// StartSync 创建同步任务（仅管理员）
+	StartSync(ctx context.Context, dryRun bool) (*model.Job, error)
+	// StartPeriodicSync 按固定间隔创建同步任务，直到 ctx 结束
+	StartPeriodicSync(ctx context.Context, interval time.Duration)
+}
+
+// ldapSyncPayload 同步任务参数
+type ldapSyncPayload struct {
+	DryRun bool `json:"dry_run"`
+}
+
+// ldapSyncedUser 同步中目录用户对应的本地用户；试运行时待创建的用户为 nil
+type ldapSyncedUser struct {
+	entry ldap.Entry
+	user  *model.User
+}
+
+// ldapService 实现 LDAPService 接口
+type ldapService struct {
+	cfg             *config.Config
+	directory       ldap.Directory
+	userStore       store.UserStore
+	workspaceStore  store.WorkspaceStore
+	teamStore       store.TeamStore
+	teamMemberStore store.TeamMemberStore
+	identityStore   store.UserIdentityStore
+	jwtService      JWTService
+	sessionService  SessionService
+	jobService      JobService
+	auditService    AuditService
+	lockout         loginLockout
+}
+
+// NewLDAPDirectory 根据配置创建 LDAP 目录客户端，未配置 LDAP 时返回 nil
+func NewLDAPDirectory(cfg *config.Config) ldap.Directory {
+	if !cfg.LDAPEnabled() {
+		return nil
+	}
+	return ldap.NewDirectory(ldap.Config{
+		URL:                  cfg.LDAPURL,
+		StartTLS:             cfg.LDAPStartTLS,
+		InsecureSkipVerify:   cfg.LDAPInsecureSkipVerify,
+		BindDN:               cfg.LDAPBindDN,
+		BindPassword:         cfg.LDAPBindPassword,
+		BaseDN:               cfg.LDAPBaseDN,
+		UserFilter:           cfg.LDAPUserFilter,
+		LoginAttribute:       cfg.LDAPLoginAttribute,
+		UIDAttribute:         cfg.LDAPUIDAttribute,
+		EmailAttribute:       cfg.LDAPEmailAttribute,
+		NameAttribute:        cfg.LDAPNameAttribute,
+		UsernameAttribute:    cfg.LDAPUsernameAttribute,
+		GroupBaseDN:          cfg.LDAPGroupBaseDN,
+		GroupFilter:          cfg.LDAPGroupFilter,
+		GroupMemberAttribute: cfg.LDAPGroupMemberAttribute,
+	})
+}
+
+// NewLDAPService 创建 LDAP 服务实例，并注册同步任务处理函数；directory 为 nil 时 LDAP 不可用，
+// redis 为 nil 时不锁定连续登录失败的账号
+func NewLDAPService(cfg *config.Config, directory ldap.Directory, userStore store.UserStore, workspaceStore store.WorkspaceStore, teamStore store.TeamStore, teamMemberStore store.TeamMemberStore, identityStore store.UserIdentityStore, jwtService JWTService, sessionService SessionService, jobService JobService, redis *redis.Client) LDAPService {
+	s := &ldapService{
+		cfg:             cfg,
+		directory:       directory,
+		userStore:       userStore,
+		workspaceStore:  workspaceStore,
+		teamStore:       teamStore,
+		teamMemberStore: teamMemberStore,
+		identityStore:   identityStore,
+		jwtService:      jwtService,
+		sessionService:  sessionService,
+		jobService:      jobService,
+		lockout:         loginLockout{redis: redis, cfg: cfg},
+	}
+	if jobService != nil {
+		jobService.RegisterHandler(JobTypeLDAPSync, s.runSyncJob)
+	}
+	return s
+}
+
+// NewLDAPServiceWithAudit 创建记录登录审计日志的 LDAP 服务实例
+func NewLDAPServiceWithAudit(cfg *config.Config, directory ldap.Directory, userStore store.UserStore, workspaceStore store.WorkspaceStore, teamStore store.TeamStore, teamMemberStore store.TeamMemberStore, identityStore store.UserIdentityStore, jwtService JWTService, sessionService SessionService, jobService JobService, redis *redis.Client, auditService AuditService) LDAPService {
+	s := NewLDAPService(cfg, directory, userStore, workspaceStore, teamStore, teamMemberStore, identityStore, jwtService, sessionService, jobService, redis).(*ldapService)
+	s.auditService = auditService
+	return s
+}
+
+// Enabled 是否启用 LDAP
+func (s *ldapService) Enabled() bool {
+	return s.directory != nil
+}
+
+// Login 使用 LDAP 账号登录，首次登录时自动创建用户
+//
+// 团队成员关系由目录同步维护，登录时不做变更。连续登录失败按登录名锁定，
+// 登录名是邮箱时与密码登录共用失败计数。
+func (s *ldapService) Login(ctx context.Context, login, password string) (*model.User, string, string, error) {
+	if !s.Enabled() {
+		return nil, "", "", fmt.Errorf("LDAP 登录未启用")
+	}
+
+	// 锁定期间不再请求目录校验密码
+	if err := s.lockout.check(ctx, login); err != nil {
+		recordLogin(ctx, s.auditService, auditLoginLDAP, login, nil, err)
+		return nil, "", "", err
+	}
+
+	entry, err := s.directory.Authenticate(ctx, login, password)
+	if err != nil {
+		if errors.Is(err, ldap.ErrInvalidCredentials) {
+			s.lockout.recordFailure(ctx, login)
+			recordLogin(ctx, s.auditService, auditLoginLDAP, login, nil, err)
+			return nil, "", "", err
+		}
+		return nil, "", "", fmt.Errorf("LDAP 认证失败: %w", err)
+	}
+	s.lockout.reset(ctx, login)
+	if entry.Disabled {
+		recordLogin(ctx, s.auditService, auditLoginLDAP, login, nil, ErrUserDeactivated)
+		return nil, "", "", ErrUserDeactivated
+	}
+
+	workspaceID, err := s.workspaceID(ctx)
+	if err != nil {
+		return nil, "", "", err
+	}
+	user, _, err := s.resolveUser(ctx, entry, workspaceID, false)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if user == nil || !user.IsActive() {
+		recordLogin(ctx, s.auditService, auditLoginLDAP, login, user, ErrUserDeactivated)
+		return nil, "", "", ErrUserDeactivated
+	}
+	if err := requireTwoFactor(ctx, s.workspaceStore, s.jwtService, user); err != nil {
+		return nil, "", "", err
+	}
+
+	accessToken, refreshToken, err := s.sessionService.Create(ctx, user)
+	if err != nil {
+		return nil, "", "", err
+	}
+	recordLogin(ctx, s.auditService, auditLoginLDAP, login, user, nil)
+	return user, accessToken, refreshToken, nil
+}
+
+// Sync 同步目录中的用户和用户组映射的团队成员：
+//   - 目录中的用户：创建、按邮箱关联已有用户、更新邮箱和姓名、按目录状态停用或恢复
+//   - 曾经同步过但已从目录中删除的用户：停用
+//   - LDAP_TEAM_MAPPING 中的团队：成员与映射用户组一致，只移除由 LDAP 管理的用户
+//
+// 全局管理员不会被停用。dryRun 为 true 时不做任何修改，只返回将要执行的变更。
+func (s *ldapService) Sync(ctx context.Context, dryRun bool) (*LDAPSyncReport, error) {
+	if !s.Enabled() {
+		return nil, fmt.Errorf("LDAP 未启用")
+	}
+	workspaceID, err := s.workspaceID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.directory.Users(ctx)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := s.directory.Groups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	identities, err := s.identityStore.ListByIssuer(ctx, ldapIdentityIssuer)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LDAPSyncReport{
+		DryRun:  dryRun,
+		Users:   len(entries),
+		Groups:  len(groups),
+		Changes: []LDAPSyncChange{},
+		Counts:  map[string]int{},
+		Errors:  []string{},
+	}
+
+	// managed 由 LDAP 管理的用户，团队同步只移除这些用户
+	managed := make(map[uuid.UUID]bool, len(identities))
+	for _, identity := range identities {
+		managed[identity.UserID] = true
+	}
+	seen := make(map[string]bool, len(entries))
+	// active 目录中未停用的用户，按规范化 DN 索引，用于匹配用户组成员
+	active := make(map[string]*ldapSyncedUser, len(entries))
+
+	for _, entry := range entries {
+		entry := entry
+		entry.Email = NormalizeEmail(entry.Email)
+		seen[entry.UID] = true
+
+		user, action, err := s.resolveUser(ctx, &entry, workspaceID, dryRun)
+		if err != nil {
+			report.add(LDAPSyncChange{Action: LDAPSyncSkip, Email: entry.Email, DN: entry.DN, Reason: err.Error()})
+			continue
+		}
+		if action != "" {
+			report.add(LDAPSyncChange{Action: action, Email: entry.Email, DN: entry.DN})
+		}
+		if user != nil {
+			managed[user.ID] = true
+			s.syncUser(ctx, report, user, &entry, dryRun)
+		}
+		if !entry.Disabled && (user != nil || action == LDAPSyncCreateUser) {
+			active[ldap.NormalizeDN(entry.DN)] = &ldapSyncedUser{entry: entry, user: user}
+		}
+	}
+
+	for _, identity := range identities {
+		if seen[identity.Subject] {
+			continue
+		}
+		user, err := s.userStore.GetUserByID(ctx, identity.UserID.String())
+		if err != nil || !user.IsActive() || user.Role == model.RoleGlobalAdmin {
+			continue
+		}
+		now := time.Now()
+		user.DeactivatedAt = &now
+		report.add(LDAPSyncChange{Action: LDAPSyncDeactivateUser, Email: user.Email, Reason: "目录中已不存在"})
+		if !dryRun {
+			if err := s.userStore.UpdateUser(ctx, user); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("停用用户 %s 失败: %v", user.Email, err))
+			}
+		}
+	}
+
+	s.syncTeams(ctx, report, workspaceID, groups, active, managed, dryRun)
+	return report, nil
+}
+
+// resolveUser 查找目录用户对应的本地用户：
+// 已关联的身份 -> 同邮箱用户（自动关联） -> 自动创建新用户（目录中已停用的账号不创建）
+//
+// 返回的 action 为 LDAPSyncLinkUser、LDAPSyncCreateUser 或空；试运行时待创建的用户为 nil。
+func (s *ldapService) resolveUser(ctx context.Context, entry *ldap.Entry, workspaceID uuid.UUID, dryRun bool) (*model.User, string, error) {
+	identity, err := s.identityStore.GetBySubject(ctx, ldapIdentityIssuer, entry.UID)
+	if err == nil {
+		user, err := s.userStore.GetUserByID(ctx, identity.UserID.String())
+		if err != nil {
+			return nil, "", fmt.Errorf("获取用户失败: %w", err)
+		}
+		return user, "", nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", fmt.Errorf("查询外部身份失败: %w", err)
+	}
+
+	email := NormalizeEmail(entry.Email)
+	if email == "" || !emailRegex.MatchString(email) {
+		return nil, "", fmt.Errorf("无权限: LDAP 账号缺少有效的邮箱")
+	}
+
+	action := LDAPSyncLinkUser
+	user, err := s.userStore.GetUserByEmail(ctx, email)
+	switch {
+	case err == nil:
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if entry.Disabled {
+			return nil, "", nil
+		}
+		action = LDAPSyncCreateUser
+		if dryRun {
+			return nil, action, nil
+		}
+		user, err = s.provisionUser(ctx, entry, email, workspaceID)
+		if err != nil {
+			return nil, "", err
+		}
+	default:
+		return nil, "", fmt.Errorf("查询用户失败: %w", err)
+	}
+
+	if dryRun {
+		return user, action, nil
+	}
+	identity = &model.UserIdentity{
+		UserID:  user.ID,
+		Issuer:  ldapIdentityIssuer,
+		Subject: entry.UID,
+		Email:   email,
+	}
+	if err := s.identityStore.Create(ctx, identity); err != nil {
+		return nil, "", err
+	}
+	return user, action, nil
+}
+
+// provisionUser 创建目录用户，默认角色为普通成员
+func (s *ldapService) provisionUser(ctx context.Context, entry *ldap.Entry, email string, workspaceID uuid.UUID) (*model.User, error) {
+	username, err := uniqueUsername(ctx, s.userStore, firstNonEmptyString(entry.Username, strings.Split(email, "@")[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	user := &model.User{
+		WorkspaceID: workspaceID,
+		Email:       email,
+		Username:    username,
+		Name:        firstNonEmptyString(entry.Name, username),
+		// LDAP 用户没有本地密码，密码登录始终失败
+		PasswordHash: "",
+		Role:         model.RoleMember,
+	}
+	if err := s.userStore.CreateUser(ctx, user); err != nil {
+		return nil, fmt.Errorf("创建用户失败: %w", err)
+	}
+	return user, nil
+}
+
+// syncUser 按目录更新用户的邮箱、姓名和停用状态
+func (s *ldapService) syncUser(ctx context.Context, report *LDAPSyncReport, user *model.User, entry *ldap.Entry, dryRun bool) {
+	updated := false
+	if entry.Email != "" && user.Email != entry.Email {
+		if other, err := s.userStore.GetUserByEmail(ctx, entry.Email); err == nil && other.ID != user.ID {
+			report.add(LDAPSyncChange{Action: LDAPSyncSkip, Email: entry.Email, DN: entry.DN, Reason: "邮箱已被其他用户使用"})
+		} else {
+			user.Email = entry.Email
+			updated = true
+		}
+	}
+	if entry.Name != "" && user.Name != entry.Name {
+		user.Name = entry.Name
+		updated = true
+	}
+	if updated {
+		report.add(LDAPSyncChange{Action: LDAPSyncUpdateUser, Email: user.Email, DN: entry.DN})
+	}
+
+	switch {
+	case entry.Disabled && user.IsActive() && user.Role != model.RoleGlobalAdmin:
+		now := time.Now()
+		user.DeactivatedAt = &now
+		updated = true
+		report.add(LDAPSyncChange{Action: LDAPSyncDeactivateUser, Email: user.Email, DN: entry.DN, Reason: "目录中已停用"})
+	case !entry.Disabled && !user.IsActive():
+		user.DeactivatedAt = nil
+		updated = true
+		report.add(LDAPSyncChange{Action: LDAPSyncReactivateUser, Email: user.Email, DN: entry.DN})
+	}
+
+	if updated && !dryRun {
+		if err := s.userStore.UpdateUser(ctx, user); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("更新用户 %s 失败: %v", user.Email, err))
+		}
+	}
+}
+
+// syncTeams 使映射团队的成员与 LDAP 用户组一致；多个用户组映射到同一团队时取最高角色
+func (s *ldapService) syncTeams(ctx context.Context, report *LDAPSyncReport, workspaceID uuid.UUID, groups []ldap.Group, active map[string]*ldapSyncedUser, managed map[uuid.UUID]bool, dryRun bool) {
+	type desiredMember struct {
+		synced *ldapSyncedUser
+		role   model.Role
+	}
+	// desired 团队标识 -> 邮箱 -> 期望的成员
+	desired := map[string]map[string]*desiredMember{}
+	for _, mapping := range s.cfg.LDAPTeamMapping {
+		key, _ := parseTeamMapping(mapping)
+		desired[key] = map[string]*desiredMember{}
+	}
+	for _, group := range groups {
+		mapping, ok := s.cfg.LDAPTeamMapping[group.Name]
+		if !ok {
+			continue
+		}
+		key, role := parseTeamMapping(mapping)
+		for _, dn := range group.MemberDNs {
+			synced, ok := active[dn]
+			if !ok {
+				continue
+			}
+			current := desired[key][synced.entry.Email]
+			if current == nil || mappedRoleLevels[role] > mappedRoleLevels[current.role] {
+				desired[key][synced.entry.Email] = &desiredMember{synced: synced, role: role}
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		team, err := s.teamStore.GetByKey(ctx, workspaceID.String(), key)
+		if err != nil {
+			report.add(LDAPSyncChange{Action: LDAPSyncSkip, Team: key, Reason: "团队不存在"})
+			continue
+		}
+		members, err := s.teamMemberStore.List(ctx, team.ID.String())
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("查询团队 %s 成员失败: %v", key, err))
+			continue
+		}
+		currentRoles := make(map[uuid.UUID]model.Role, len(members))
+		for _, member := range members {
+			currentRoles[member.UserID] = member.Role
+		}
+
+		emails := make([]string, 0, len(desired[key]))
+		for email := range desired[key] {
+			emails = append(emails, email)
+		}
+		sort.Strings(emails)
+
+		wanted := map[uuid.UUID]bool{}
+		for _, email := range emails {
+			member := desired[key][email]
+			change := LDAPSyncChange{Email: email, DN: member.synced.entry.DN, Team: key, Role: member.role}
+			user := member.synced.user
+			if user == nil {
+				// 试运行中待创建的用户
+				change.Action = LDAPSyncAddTeamMember
+				report.add(change)
+				continue
+			}
+			wanted[user.ID] = true
+
+			var err error
+			role, ok := currentRoles[user.ID]
+			switch {
+			case !ok:
+				change.Action = LDAPSyncAddTeamMember
+				report.add(change)
+				if !dryRun {
+					err = s.teamMemberStore.Add(ctx, &model.TeamMember{TeamID: team.ID, UserID: user.ID, Role: member.role})
+				}
+			case role != member.role:
+				change.Action = LDAPSyncUpdateTeamMember
+				report.add(change)
+				if !dryRun {
+					err = s.teamMemberStore.UpdateRole(ctx, team.ID.String(), user.ID.String(), member.role)
+				}
+			}
+			if err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("同步团队 %s 成员 %s 失败: %v", key, email, err))
+			}
+		}
+
+		// 不在映射用户组中的 LDAP 用户移出团队，本地创建的用户不受影响
+		for _, member := range members {
+			if !managed[member.UserID] || wanted[member.UserID] {
+				continue
+			}
+			report.add(LDAPSyncChange{Action: LDAPSyncRemoveTeamMember, Email: member.User.Email, Team: key, Role: member.Role})
+			if dryRun {
+				continue
+			}
+			if err := s.teamMemberStore.Remove(ctx, team.ID.String(), member.UserID.String()); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("移除团队 %s 成员 %s 失败: %v", key, member.User.Email, err))
+			}
+		}
+	}
+}
+
+// StartSync 创建同步任务（仅管理员），结果可通过任务查询接口获取
+func (s *ldapService) StartSync(ctx context.Context, dryRun bool) (*model.Job, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+	userRole, _ := ctx.Value("user_role").(model.Role)
+	if userRole != model.RoleAdmin && userRole != model.RoleGlobalAdmin {
+		return nil, fmt.Errorf("无权限执行 LDAP 同步")
+	}
+	if !s.Enabled() {
+		return nil, fmt.Errorf("LDAP 未启用")
+	}
+	// 工作区管理员只能同步自己所在的工作区
+	if userRole == model.RoleAdmin {
+		user, err := s.userStore.GetUserByID(ctx, userID.String())
+		if err != nil || user.WorkspaceID.String() != s.cfg.LDAPWorkspaceID {
+			return nil, fmt.Errorf("无权限执行 LDAP 同步")
+		}
+	}
+	return s.enqueueSync(ctx, dryRun)
+}
+
+// StartPeriodicSync 按固定间隔创建同步任务，直到 ctx 结束
+func (s *ldapService) StartPeriodicSync(ctx context.Context, interval time.Duration) {
+	if !s.Enabled() || s.jobService == nil || interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.enqueueSync(ctx, false); err != nil {
+					slog.WarnContext(ctx, "创建 LDAP 同步任务失败", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// enqueueSync 创建同步任务
+func (s *ldapService) enqueueSync(ctx context.Context, dryRun bool) (*model.Job, error) {
+	if s.jobService == nil {
+		return nil, fmt.Errorf("后台任务服务不可用")
+	}
+	workspaceID, err := uuid.Parse(s.cfg.LDAPWorkspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 LDAP 工作区 ID: %w", err)
+	}
+	job := &model.Job{
+		WorkspaceID: &workspaceID,
+		Type:        JobTypeLDAPSync,
+	}
+	if err := s.jobService.Enqueue(ctx, job, &ldapSyncPayload{DryRun: dryRun}); err != nil {
+		return nil, fmt.Errorf("创建同步任务失败: %w", err)
+	}
+	return job, nil
+}
+
+// runSyncJob 执行同步任务
+func (s *ldapService) runSyncJob(ctx context.Context, job *model.Job, progress JobProgressFunc) (interface{}, error) {
+	var payload ldapSyncPayload
+	if len(job.Payload) > 0 {
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("解析任务参数失败: %w", err)
+		}
+	}
+	report, err := s.Sync(ctx, payload.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	progress(report.Users, report.Users)
+	return report, nil
+}
+
+// workspaceID 获取 LDAP 用户所属的工作区
+func (s *ldapService) workspaceID(ctx context.Context) (uuid.UUID, error) {
+	workspaceID, err := uuid.Parse(s.cfg.LDAPWorkspaceID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("无效的 LDAP 工作区 ID: %w", err)
+	}
+	if _, err := s.workspaceStore.GetByID(ctx, workspaceID.String()); err != nil {
+		return uuid.Nil, fmt.Errorf("LDAP 工作区不存在")
+	}
+	return workspaceID, nil
+}
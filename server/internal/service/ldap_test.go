@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/ldap"
+	"github.com/liwei0526vip/mylinear/internal/ldap/ldaptest"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+func TestLDAPService_Disabled(t *testing.T) {
+	svc := NewLDAPService(&config.Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	if svc.Enabled() {
+		t.Error("未配置目录时不应启用")
+	}
+	if _, _, _, err := svc.Login(context.Background(), "alice", "secret"); err == nil {
+		t.Error("未启用时 Login 应返回错误")
+	}
+	if _, err := svc.Sync(context.Background(), true); err == nil {
+		t.Error("未启用时 Sync 应返回错误")
+	}
+}
+
+type ldapFixtures struct {
+	*issueServiceFixtures
+	dir     *ldaptest.Directory
+	cfg     *config.Config
+	service LDAPService
+	prefix  string
+}
+
+func setupLDAPFixtures(t *testing.T, db *gorm.DB) *ldapFixtures {
+	base := setupIssueServiceFixtures(t, db)
+	dir := ldaptest.NewDirectory()
+
+	cfg := &config.Config{
+		JWTSecret:        "ldap-test-secret",
+		JWTAccessExpiry:  15 * time.Minute,
+		JWTRefreshExpiry: 7 * 24 * time.Hour,
+		LDAPURL:          "ldap://localhost:1389",
+		LDAPBaseDN:       "dc=example,dc=org",
+		LDAPWorkspaceID:  base.workspaceID.String(),
+		LDAPTeamMapping: map[string]string{
+			"engineers": base.team.Key + ":admin",
+			"staff":     base.team.Key,
+		},
+	}
+	jwtService := NewJWTService(cfg)
+	svc := NewLDAPService(cfg, dir,
+		store.NewUserStore(db),
+		store.NewWorkspaceStore(db),
+		store.NewTeamStore(db),
+		store.NewTeamMemberStore(db),
+		store.NewUserIdentityStore(db),
+		jwtService,
+		NewSessionService(store.NewSessionStore(db), store.NewUserStore(db), store.NewWorkspaceMemberStore(db), store.NewOAuthStore(db), jwtService, cfg),
+		nil,
+		nil,
+	)
+
+	return &ldapFixtures{
+		issueServiceFixtures: base,
+		dir:                  dir,
+		cfg:                  cfg,
+		service:              svc,
+		prefix:               strings.ToLower(base.team.Name),
+	}
+}
+
+// addUser 向目录添加用户，DN 为 uid=<name>,ou=users,dc=example,dc=org
+func (f *ldapFixtures) addUser(name string) ldap.Entry {
+	entry := ldap.Entry{
+		DN:       "uid=" + name + ",ou=users,dc=example,dc=org",
+		UID:      f.prefix + "-" + name,
+		Email:    f.prefix + "_" + name + "@example.com",
+		Username: name,
+		Name:     strings.ToUpper(name[:1]) + name[1:],
+	}
+	f.dir.AddUser(entry, name+"-password")
+	return entry
+}
+
+func TestLDAPService_Login(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupLDAPFixtures(t, tx)
+	alice := f.addUser("alice")
+
+	user, accessToken, refreshToken, err := f.service.Login(context.Background(), "alice", "alice-password")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if accessToken == "" || refreshToken == "" {
+		t.Error("登录应返回令牌")
+	}
+	if user.Email != alice.Email || user.Name != "Alice" || user.Role != model.RoleMember || user.WorkspaceID != f.workspaceID {
+		t.Errorf("Unexpected user: %+v", user)
+	}
+
+	// 再次登录返回同一用户
+	again, _, _, err := f.service.Login(context.Background(), "ALICE", "alice-password")
+	if err != nil || again.ID != user.ID {
+		t.Errorf("再次登录应返回同一用户, got %v, err = %v", again, err)
+	}
+
+	if _, _, _, err := f.service.Login(context.Background(), "alice", "wrong"); !errors.Is(err, ldap.ErrInvalidCredentials) {
+		t.Errorf("密码错误应返回 ErrInvalidCredentials, got %v", err)
+	}
+
+	f.dir.SetDisabled(alice.UID, true)
+	if _, _, _, err := f.service.Login(context.Background(), "alice", "alice-password"); !errors.Is(err, ErrUserDeactivated) {
+		t.Errorf("目录中已停用的账号应无法登录, got %v", err)
+	}
+
+	// LDAP 用户没有本地密码
+	if _, _, _, err := NewAuthService(store.NewUserStore(tx), store.NewWorkspaceStore(tx), NewJWTService(f.cfg), nil, nil, nil, f.cfg).Login(context.Background(), alice.Email, ""); err == nil {
+		t.Error("LDAP 用户不应能使用密码登录")
+	}
+}
+
+func TestLDAPService_Login_Lockout(t *testing.T) {
+	rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	defer rdb.Close()
+	ctx := context.Background()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		t.Skip("无法连接 Redis")
+	}
+
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupLDAPFixtures(t, tx)
+	// 连续失败 3 次后锁定
+	f.cfg.LoginLockoutThreshold = 3
+	f.cfg.LoginLockoutDuration = time.Minute
+	f.service.(*ldapService).lockout = loginLockout{redis: rdb, cfg: f.cfg}
+
+	login := f.prefix + "-locked"
+	f.addUser(login)
+	defer rdb.Del(ctx, "login_lockout:"+login, "login_failures:"+login)
+
+	for i := 0; i < 3; i++ {
+		if _, _, _, err := f.service.Login(ctx, login, "wrong"); !errors.Is(err, ldap.ErrInvalidCredentials) {
+			t.Fatalf("密码错误应返回 ErrInvalidCredentials, got %v", err)
+		}
+	}
+
+	// 锁定期间密码正确也不能登录
+	var locked *AccountLockedError
+	if _, _, _, err := f.service.Login(ctx, login, login+"-password"); !errors.As(err, &locked) {
+		t.Errorf("Login() error = %v, want AccountLockedError", err)
+	}
+}
+
+func TestLDAPService_SyncDryRun(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupLDAPFixtures(t, tx)
+	alice := f.addUser("alice")
+	bob := f.addUser("bob")
+	f.dir.SetGroup("engineers", alice.DN)
+	f.dir.SetGroup("staff", alice.DN, bob.DN)
+
+	report, err := f.service.Sync(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if !report.DryRun || report.Counts[LDAPSyncCreateUser] != 2 || report.Counts[LDAPSyncAddTeamMember] != 2 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	for _, change := range report.Changes {
+		if change.Action == LDAPSyncAddTeamMember && change.Email == alice.Email && change.Role != model.RoleAdmin {
+			t.Errorf("多个用户组映射到同一团队时应取最高角色, got %s", change.Role)
+		}
+	}
+
+	// 试运行不做任何修改
+	if _, err := store.NewUserStore(tx).GetUserByEmail(f.ctx, alice.Email); err == nil {
+		t.Error("试运行不应创建用户")
+	}
+}
+
+func TestLDAPService_Sync(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupLDAPFixtures(t, tx)
+	userStore := store.NewUserStore(tx)
+	memberStore := store.NewTeamMemberStore(tx)
+	teamID := f.team.ID.String()
+
+	alice := f.addUser("alice")
+	bob := f.addUser("bob")
+	carol := f.addUser("carol")
+	f.dir.SetDisabled(carol.UID, true)
+	f.dir.SetGroup("engineers", alice.DN)
+	f.dir.SetGroup("staff", alice.DN, bob.DN, carol.DN)
+
+	// 已有本地用户按邮箱关联
+	user2, err := userStore.GetUserByID(f.ctx, f.user2ID.String())
+	if err != nil {
+		t.Fatalf("获取用户失败: %v", err)
+	}
+	dave := ldap.Entry{DN: "uid=dave,ou=users,dc=example,dc=org", UID: f.prefix + "-dave", Email: user2.Email, Username: "dave", Name: user2.Name}
+	f.dir.AddUser(dave, "dave-password")
+
+	report, err := f.service.Sync(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if report.Counts[LDAPSyncCreateUser] != 2 || report.Counts[LDAPSyncLinkUser] != 1 || len(report.Errors) != 0 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	aliceUser, err := userStore.GetUserByEmail(f.ctx, alice.Email)
+	if err != nil {
+		t.Fatalf("应创建 alice: %v", err)
+	}
+	bobUser, err := userStore.GetUserByEmail(f.ctx, bob.Email)
+	if err != nil {
+		t.Fatalf("应创建 bob: %v", err)
+	}
+	if _, err := userStore.GetUserByEmail(f.ctx, carol.Email); err == nil {
+		t.Error("目录中已停用的账号不应被创建")
+	}
+	if role, err := memberStore.GetRole(f.ctx, teamID, aliceUser.ID.String()); err != nil || role != model.RoleAdmin {
+		t.Errorf("alice 团队角色 = %s, err = %v, 期望 admin", role, err)
+	}
+	if role, err := memberStore.GetRole(f.ctx, teamID, bobUser.ID.String()); err != nil || role != model.RoleMember {
+		t.Errorf("bob 团队角色 = %s, err = %v, 期望 member", role, err)
+	}
+
+	// 关联的 dave 不在映射用户组中，加入团队后会被移除
+	if err := memberStore.Add(f.ctx, &model.TeamMember{TeamID: f.team.ID, UserID: f.user2ID, Role: model.RoleMember}); err != nil {
+		t.Fatalf("添加团队成员失败: %v", err)
+	}
+
+	// 目录变更：bob 被删除，alice 改名并移出 engineers，dave 被停用
+	f.dir.RemoveUser(bob.UID)
+	alice.Name = "Alice Liddell"
+	f.dir.AddUser(alice, "alice-password")
+	f.dir.SetGroup("engineers")
+	f.dir.SetDisabled(dave.UID, true)
+
+	// 再次同步前先试运行，确认不做修改
+	preview, err := f.service.Sync(context.Background(), true)
+	if err != nil {
+		t.Fatalf("Sync(dryRun) error = %v", err)
+	}
+	if preview.Counts[LDAPSyncDeactivateUser] != 2 || preview.Counts[LDAPSyncUpdateTeamMember] != 1 || preview.Counts[LDAPSyncRemoveTeamMember] != 2 {
+		t.Errorf("Unexpected preview: %+v", preview)
+	}
+	if u, _ := userStore.GetUserByID(f.ctx, bobUser.ID.String()); u == nil || !u.IsActive() {
+		t.Error("试运行不应停用用户")
+	}
+
+	report, err = f.service.Sync(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if report.Counts[LDAPSyncUpdateUser] != 1 || report.Counts[LDAPSyncDeactivateUser] != 2 || len(report.Errors) != 0 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	aliceUser, _ = userStore.GetUserByID(f.ctx, aliceUser.ID.String())
+	if aliceUser.Name != "Alice Liddell" {
+		t.Errorf("Name = %s, 期望 Alice Liddell", aliceUser.Name)
+	}
+	if role, _ := memberStore.GetRole(f.ctx, teamID, aliceUser.ID.String()); role != model.RoleMember {
+		t.Errorf("alice 团队角色 = %s, 期望 member", role)
+	}
+	bobUser, _ = userStore.GetUserByID(f.ctx, bobUser.ID.String())
+	if bobUser.IsActive() {
+		t.Error("目录中已删除的用户应被停用")
+	}
+	if _, err := memberStore.GetRole(f.ctx, teamID, bobUser.ID.String()); err == nil {
+		t.Error("已删除的用户应被移出团队")
+	}
+	if u, _ := userStore.GetUserByID(f.ctx, f.user2ID.String()); u == nil || u.IsActive() {
+		t.Error("目录中已停用的用户应被停用")
+	}
+	if _, err := memberStore.GetRole(f.ctx, teamID, f.userID.String()); err != nil {
+		t.Error("非 LDAP 管理的本地用户不应被移出团队")
+	}
+
+	// 目录中重新启用后恢复
+	f.dir.SetDisabled(dave.UID, false)
+	report, err = f.service.Sync(context.Background(), false)
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if report.Counts[LDAPSyncReactivateUser] != 1 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if u, _ := userStore.GetUserByID(f.ctx, f.user2ID.String()); u == nil || !u.IsActive() {
+		t.Error("目录中重新启用的用户应被恢复")
+	}
+}
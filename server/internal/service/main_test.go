@@ -41,6 +41,22 @@ func TestMain(m *testing.M) {
 	testSvcDB = testDB
 
 	// 统一清理和迁移
+	testDB.Exec("DROP TABLE IF EXISTS audit_logs CASCADE")
+	testDB.Exec("DROP TABLE IF EXISTS user_identities CASCADE")
+	testDB.Exec("DROP TABLE IF EXISTS user_recovery_codes CASCADE")
+	testDB.Exec("DROP TABLE IF EXISTS user_sessions CASCADE")
+	testDB.Exec("DROP TABLE IF EXISTS password_reset_tokens CASCADE")
+	testDB.Exec("DROP TABLE IF EXISTS workspace_invitations CASCADE")
+	testDB.Exec("DROP TABLE IF EXISTS workspace_members CASCADE")
+	testDB.Exec("DROP TABLE IF EXISTS oauth_grants CASCADE")
+	testDB.Exec("DROP TABLE IF EXISTS oauth_authorization_codes CASCADE")
+	testDB.Exec("DROP TABLE IF EXISTS oauth_applications CASCADE")
+	testDB.Exec("DROP TABLE IF EXISTS api_keys CASCADE")
+	testDB.Exec("DROP TABLE IF EXISTS team_key_aliases CASCADE")
+	testDB.Exec("DROP TABLE IF EXISTS issue_identifier_aliases CASCADE")
+	testDB.Exec("DROP TABLE IF EXISTS issue_links CASCADE")
+	testDB.Exec("DROP TABLE IF EXISTS external_references CASCADE")
+	testDB.Exec("DROP TABLE IF EXISTS jobs CASCADE")
 	testDB.Exec("DROP TABLE IF EXISTS comments CASCADE")
 	testDB.Exec("DROP TABLE IF EXISTS activities CASCADE")
 	testDB.Exec("DROP TABLE IF EXISTS projects CASCADE")
@@ -58,6 +74,7 @@ func TestMain(m *testing.M) {
 	err = testDB.AutoMigrate(
 		&model.Workspace{},
 		&model.User{},
+		&model.WorkspaceMember{},
 		&model.Team{},
 		&model.TeamMember{},
 		&model.WorkflowState{},
@@ -69,6 +86,21 @@ func TestMain(m *testing.M) {
 		&model.Comment{},
 		&model.Notification{},
 		&model.NotificationPreference{},
+		&model.Job{},
+		&model.ExternalReference{},
+		&model.IssueLink{},
+		&model.IssueIdentifierAlias{},
+		&model.TeamKeyAlias{},
+		&model.APIKey{},
+		&model.OAuthApplication{},
+		&model.OAuthAuthorizationCode{},
+		&model.OAuthGrant{},
+		&model.UserIdentity{},
+		&model.RecoveryCode{},
+		&model.Session{},
+		&model.PasswordResetToken{},
+		&model.Invitation{},
+		&model.AuditLog{},
 	)
 	if err != nil {
 		fmt.Printf("自动迁移失败: %v\n", err)
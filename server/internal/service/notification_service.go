@@ -4,10 +4,13 @@ package service
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/metrics"
 	"github.com/liwei0526vip/mylinear/internal/model"
 	"github.com/liwei0526vip/mylinear/internal/store"
+	"github.com/liwei0526vip/mylinear/internal/tracing"
 )
 
 // NotificationService 定义通知服务接口
@@ -50,6 +53,9 @@ func NewNotificationService(notificationStore store.NotificationStore, preferenc
 
 // CreateNotification 创建通知
 func (s *notificationService) CreateNotification(ctx context.Context, notification *model.Notification) error {
+	ctx, span := tracing.Start(ctx, "NotificationService.CreateNotification")
+	defer span.End()
+
 	if notification == nil {
 		return fmt.Errorf("通知不能为 nil")
 	}
@@ -67,11 +73,18 @@ func (s *notificationService) CreateNotification(ctx context.Context, notificati
 		return fmt.Errorf("无效的通知类型: %s", notification.Type)
 	}
 
-	return s.notificationStore.CreateNotification(ctx, notification)
+	if err := s.notificationStore.CreateNotification(ctx, notification); err != nil {
+		return err
+	}
+	metrics.NotificationCreated(string(notification.Type))
+	return nil
 }
 
 // NotifyIssueAssigned 通知用户被分配了 Issue
 func (s *notificationService) NotifyIssueAssigned(ctx context.Context, actorID uuid.UUID, assigneeID *uuid.UUID, issueID uuid.UUID, issueTitle string) error {
+	ctx, span := tracing.Start(ctx, "NotificationService.NotifyIssueAssigned")
+	defer span.End()
+
 	// 取消指派不通知
 	if assigneeID == nil {
 		return nil
@@ -104,6 +117,9 @@ func (s *notificationService) NotifyIssueAssigned(ctx context.Context, actorID u
 
 // NotifyIssueMentioned 通知用户在 Issue 中被 @mention
 func (s *notificationService) NotifyIssueMentioned(ctx context.Context, actorID uuid.UUID, mentionedUsernames []string, issueID uuid.UUID, issueTitle string) error {
+	ctx, span := tracing.Start(ctx, "NotificationService.NotifyIssueMentioned")
+	defer span.End()
+
 	if len(mentionedUsernames) == 0 {
 		return nil
 	}
@@ -131,6 +147,7 @@ func (s *notificationService) NotifyIssueMentioned(ctx context.Context, actorID
 		// 检查用户是否启用该类型通知
 		userEnabled, err := s.preferenceStore.IsEnabled(ctx, user.ID, model.NotificationChannelInApp, model.NotificationTypeIssueMentioned)
 		if err != nil {
+			slog.WarnContext(ctx, "检查通知偏好失败", "issue_id", issueID, "user_id", user.ID, "type", model.NotificationTypeIssueMentioned, "error", err)
 			continue
 		}
 		if !userEnabled {
@@ -147,6 +164,7 @@ func (s *notificationService) NotifyIssueMentioned(ctx context.Context, actorID
 
 		if err := s.CreateNotification(ctx, notification); err != nil {
 			// 单个通知创建失败不影响其他通知
+			slog.WarnContext(ctx, "创建提及通知失败", "issue_id", issueID, "user_id", user.ID, "error", err)
 			continue
 		}
 	}
@@ -156,6 +174,9 @@ func (s *notificationService) NotifyIssueMentioned(ctx context.Context, actorID
 
 // NotifySubscribers 通知订阅者
 func (s *notificationService) NotifySubscribers(ctx context.Context, actorID uuid.UUID, subscriberIDs []uuid.UUID, notifyType model.NotificationType, issueID uuid.UUID, title string, body string) error {
+	ctx, span := tracing.Start(ctx, "NotificationService.NotifySubscribers")
+	defer span.End()
+
 	if len(subscriberIDs) == 0 {
 		return nil
 	}
@@ -169,6 +190,7 @@ func (s *notificationService) NotifySubscribers(ctx context.Context, actorID uui
 		// 检查用户是否启用该类型通知
 		enabled, err := s.preferenceStore.IsEnabled(ctx, subscriberID, model.NotificationChannelInApp, notifyType)
 		if err != nil {
+			slog.WarnContext(ctx, "检查通知偏好失败", "issue_id", issueID, "user_id", subscriberID, "type", notifyType, "error", err)
 			continue
 		}
 		if !enabled {
@@ -186,6 +208,7 @@ func (s *notificationService) NotifySubscribers(ctx context.Context, actorID uui
 
 		if err := s.CreateNotification(ctx, notification); err != nil {
 			// 单个通知创建失败不影响其他通知
+			slog.WarnContext(ctx, "创建订阅通知失败", "issue_id", issueID, "user_id", subscriberID, "type", notifyType, "error", err)
 			continue
 		}
 	}
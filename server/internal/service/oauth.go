@@ -0,0 +1,672 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/gorm"
+)
+
+const (
+	// oauthCodeTTL 授权码有效期
+	oauthCodeTTL = 10 * time.Minute
+	// oauthRefreshTokenTTL 刷新令牌有效期，每次刷新后重新计算
+	oauthRefreshTokenTTL = 30 * 24 * time.Hour
+	// oauthTokenBytes 授权码、刷新令牌、client_secret 随机部分的字节数
+	oauthTokenBytes = 32
+	// oauthClientSecretPrefix client_secret 明文前缀
+	oauthClientSecretPrefix = "mls_"
+)
+
+// OAuth2 错误码（RFC 6749 5.2 / 4.1.2.1）
+const (
+	OAuthErrInvalidRequest          = "invalid_request"
+	OAuthErrInvalidClient           = "invalid_client"
+	OAuthErrInvalidGrant            = "invalid_grant"
+	OAuthErrUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthErrUnsupportedResponseType = "unsupported_response_type"
+	OAuthErrInvalidScope            = "invalid_scope"
+	OAuthErrAccessDenied            = "access_denied"
+)
+
+// OAuth2 授权类型
+const (
+	OAuthGrantAuthorizationCode = "authorization_code"
+	OAuthGrantRefreshToken      = "refresh_token"
+)
+
+// pkceVerifierPattern RFC 7636 4.1：43-128 个非保留字符
+var pkceVerifierPattern = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+
+// OAuthError OAuth2 协议错误，令牌端点按 RFC 6749 格式返回
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+// Error 实现 error 接口
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// newOAuthError 创建 OAuth2 协议错误
+func newOAuthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+// CreateOAuthApplicationParams 注册 OAuth 应用参数
+type CreateOAuthApplicationParams struct {
+	Name         string
+	RedirectURIs []string
+	Scopes       []string
+	// Confidential 机密客户端（服务端应用）会获得 client_secret；公开客户端（SPA / 原生应用）只使用 PKCE
+	Confidential bool
+}
+
+// CreatedOAuthApplication 新注册的应用，ClientSecret 为明文，只在创建时返回一次
+type CreatedOAuthApplication struct {
+	*model.OAuthApplication
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// AuthorizeRequest 授权请求参数（RFC 6749 4.1.1 + RFC 7636 4.3）
+type AuthorizeRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// AuthorizationPrompt 用户确认页需要的信息
+type AuthorizationPrompt struct {
+	Application *model.OAuthApplication `json:"application"`
+	Scopes      []string                `json:"scopes"`
+	RedirectURI string                  `json:"redirect_uri"`
+	State       string                  `json:"state,omitempty"`
+}
+
+// OAuthClientCredentials 客户端认证信息（表单参数或 HTTP Basic）
+type OAuthClientCredentials struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// TokenRequest 令牌请求参数
+type TokenRequest struct {
+	OAuthClientCredentials
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string
+}
+
+// TokenResponse 令牌响应（RFC 6749 5.1）
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope"`
+}
+
+// IntrospectionResponse 令牌自省响应（RFC 7662 2.2）
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Subject   string `json:"sub,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+// OAuthService 定义 OAuth2 授权服务接口
+type OAuthService interface {
+	// CreateApplication 注册 OAuth 应用（工作区管理员）
+	CreateApplication(ctx context.Context, params *CreateOAuthApplicationParams) (*CreatedOAuthApplication, error)
+	// ListApplications 获取当前工作区的 OAuth 应用（工作区管理员）
+	ListApplications(ctx context.Context) ([]model.OAuthApplication, error)
+	// DeleteApplication 删除 OAuth 应用，已签发的令牌全部失效（工作区管理员）
+	DeleteApplication(ctx context.Context, id string) error
+
+	// PrepareAuthorization 校验授权请求，返回确认页信息
+	PrepareAuthorization(ctx context.Context, req *AuthorizeRequest) (*AuthorizationPrompt, error)
+	// Authorize 用户确认或拒绝授权，返回带授权码（或错误）的回调地址
+	Authorize(ctx context.Context, req *AuthorizeRequest, approved bool) (string, error)
+
+	// Token 令牌端点：授权码换令牌、刷新令牌
+	Token(ctx context.Context, req *TokenRequest) (*TokenResponse, error)
+	// Revoke 吊销访问令牌或刷新令牌（RFC 7009），未知令牌同样视为成功
+	Revoke(ctx context.Context, creds *OAuthClientCredentials, token string) error
+	// Introspect 查询令牌状态（RFC 7662），只能查询签发给自身的令牌
+	Introspect(ctx context.Context, creds *OAuthClientCredentials, token string) (*IntrospectionResponse, error)
+}
+
+// oauthService 实现 OAuthService 接口
+type oauthService struct {
+	oauthStore store.OAuthStore
+	userStore  store.UserStore
+	jwtService JWTService
+}
+
+// NewOAuthService 创建 OAuth2 授权服务实例
+func NewOAuthService(oauthStore store.OAuthStore, userStore store.UserStore, jwtService JWTService) OAuthService {
+	return &oauthService{
+		oauthStore: oauthStore,
+		userStore:  userStore,
+		jwtService: jwtService,
+	}
+}
+
+// CreateApplication 注册 OAuth 应用
+func (s *oauthService) CreateApplication(ctx context.Context, params *CreateOAuthApplicationParams) (*CreatedOAuthApplication, error) {
+	user, err := s.currentAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(params.Name)
+	if name == "" || len(name) > 100 {
+		return nil, fmt.Errorf("无效的名称: 长度必须为 1-100 个字符")
+	}
+	if len(params.RedirectURIs) == 0 {
+		return nil, fmt.Errorf("无效的回调地址: 至少需要一个")
+	}
+	for _, uri := range params.RedirectURIs {
+		if err := validateRedirectURI(uri); err != nil {
+			return nil, err
+		}
+	}
+	scopes, err := normalizeAPIKeyScopes(params.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	clientID, err := randomURLToken(16)
+	if err != nil {
+		return nil, fmt.Errorf("生成 client_id 失败: %w", err)
+	}
+	app := &model.OAuthApplication{
+		WorkspaceID:  activeWorkspaceID(ctx, user),
+		Name:         name,
+		ClientID:     clientID,
+		RedirectURIs: params.RedirectURIs,
+		Scopes:       scopes,
+		Confidential: params.Confidential,
+		CreatedByID:  &user.ID,
+	}
+
+	var secret string
+	if params.Confidential {
+		token, err := randomURLToken(oauthTokenBytes)
+		if err != nil {
+			return nil, fmt.Errorf("生成 client_secret 失败: %w", err)
+		}
+		secret = oauthClientSecretPrefix + token
+		app.ClientSecretHash = model.HashAPIKey(secret)
+	}
+
+	if err := s.oauthStore.CreateApplication(ctx, app); err != nil {
+		return nil, err
+	}
+	return &CreatedOAuthApplication{OAuthApplication: app, ClientSecret: secret}, nil
+}
+
+// ListApplications 获取当前工作区的 OAuth 应用
+func (s *oauthService) ListApplications(ctx context.Context) ([]model.OAuthApplication, error) {
+	user, err := s.currentAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.oauthStore.ListApplications(ctx, activeWorkspaceID(ctx, user))
+}
+
+// DeleteApplication 删除 OAuth 应用
+func (s *oauthService) DeleteApplication(ctx context.Context, id string) error {
+	user, err := s.currentAdmin(ctx)
+	if err != nil {
+		return err
+	}
+
+	appID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("无效的应用 ID")
+	}
+	app, err := s.oauthStore.GetApplicationByID(ctx, appID)
+	if err != nil || app.WorkspaceID != activeWorkspaceID(ctx, user) {
+		return fmt.Errorf("OAuth 应用不存在")
+	}
+	return s.oauthStore.DeleteApplication(ctx, appID)
+}
+
+// currentAdmin 获取当前用户并校验为工作区管理员
+func (s *oauthService) currentAdmin(ctx context.Context) (*model.User, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+	userRole, _ := ctx.Value("user_role").(model.Role)
+	if userRole != model.RoleAdmin && userRole != model.RoleGlobalAdmin {
+		return nil, fmt.Errorf("无权限管理 OAuth 应用")
+	}
+
+	user, err := s.userStore.GetUserByID(ctx, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("用户不存在")
+	}
+	return user, nil
+}
+
+// PrepareAuthorization 校验授权请求，返回确认页信息
+func (s *oauthService) PrepareAuthorization(ctx context.Context, req *AuthorizeRequest) (*AuthorizationPrompt, error) {
+	_, app, redirectURI, scopes, err := s.validateAuthorization(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &AuthorizationPrompt{
+		Application: app,
+		Scopes:      scopes,
+		RedirectURI: redirectURI,
+		State:       req.State,
+	}, nil
+}
+
+// Authorize 用户确认或拒绝授权，返回带授权码（或错误）的回调地址
+func (s *oauthService) Authorize(ctx context.Context, req *AuthorizeRequest, approved bool) (string, error) {
+	user, app, redirectURI, scopes, err := s.validateAuthorization(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+	if !approved {
+		params.Set("error", OAuthErrAccessDenied)
+		params.Set("error_description", "用户拒绝了授权")
+		return appendQuery(redirectURI, params), nil
+	}
+
+	code, err := randomURLToken(oauthTokenBytes)
+	if err != nil {
+		return "", fmt.Errorf("生成授权码失败: %w", err)
+	}
+	authCode := &model.OAuthAuthorizationCode{
+		CodeHash:            model.HashAPIKey(code),
+		ApplicationID:       app.ID,
+		UserID:              user.ID,
+		RedirectURI:         redirectURI,
+		Scopes:              scopes,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: model.OAuthCodeChallengeS256,
+		ExpiresAt:           time.Now().Add(oauthCodeTTL),
+	}
+	if err := s.oauthStore.CreateAuthorizationCode(ctx, authCode); err != nil {
+		return "", err
+	}
+
+	params.Set("code", code)
+	return appendQuery(redirectURI, params), nil
+}
+
+// validateAuthorization 校验授权请求：应用、回调地址、响应类型、PKCE 和权限范围
+func (s *oauthService) validateAuthorization(ctx context.Context, req *AuthorizeRequest) (*model.User, *model.OAuthApplication, string, []string, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, nil, "", nil, fmt.Errorf("未认证")
+	}
+	user, err := s.userStore.GetUserByID(ctx, userID.String())
+	if err != nil {
+		return nil, nil, "", nil, fmt.Errorf("用户不存在")
+	}
+
+	app, err := s.oauthStore.GetApplicationByClientID(ctx, req.ClientID)
+	if err != nil {
+		return nil, nil, "", nil, fmt.Errorf("OAuth 应用不存在")
+	}
+	// OAuth 令牌作用于用户的主工作区
+	if app.WorkspaceID != user.WorkspaceID {
+		return nil, nil, "", nil, fmt.Errorf("无权限授权其他工作区的应用")
+	}
+
+	// 只注册了一个回调地址时可以省略
+	redirectURI := req.RedirectURI
+	if redirectURI == "" && len(app.RedirectURIs) == 1 {
+		redirectURI = app.RedirectURIs[0]
+	}
+	if !app.AllowsRedirectURI(redirectURI) {
+		return nil, nil, "", nil, fmt.Errorf("无效的回调地址: 未注册")
+	}
+
+	if req.ResponseType != "code" {
+		return nil, nil, "", nil, newOAuthError(OAuthErrUnsupportedResponseType, "只支持 response_type=code")
+	}
+	if req.CodeChallengeMethod != model.OAuthCodeChallengeS256 || !pkceVerifierPattern.MatchString(req.CodeChallenge) {
+		return nil, nil, "", nil, newOAuthError(OAuthErrInvalidRequest, "必须使用 PKCE（code_challenge_method=S256）")
+	}
+
+	scopes, err := parseRequestedScopes(req.Scope, app.Scopes)
+	if err != nil {
+		return nil, nil, "", nil, err
+	}
+	// 授权范围不能超过用户本身的权限
+	if model.ScopesAllow(scopes, model.APIKeyScopeAdmin) &&
+		user.Role != model.RoleAdmin && user.Role != model.RoleGlobalAdmin {
+		return nil, nil, "", nil, newOAuthError(OAuthErrInvalidScope, "只有管理员可以授予 admin 范围")
+	}
+
+	return user, app, redirectURI, scopes, nil
+}
+
+// Token 令牌端点：授权码换令牌、刷新令牌
+func (s *oauthService) Token(ctx context.Context, req *TokenRequest) (*TokenResponse, error) {
+	app, err := s.authenticateClient(ctx, &req.OAuthClientCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case OAuthGrantAuthorizationCode:
+		return s.exchangeAuthorizationCode(ctx, app, req)
+	case OAuthGrantRefreshToken:
+		return s.refreshToken(ctx, app, req)
+	default:
+		return nil, newOAuthError(OAuthErrUnsupportedGrantType, "只支持 authorization_code 和 refresh_token")
+	}
+}
+
+// exchangeAuthorizationCode 使用授权码和 PKCE verifier 换取令牌
+func (s *oauthService) exchangeAuthorizationCode(ctx context.Context, app *model.OAuthApplication, req *TokenRequest) (*TokenResponse, error) {
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, newOAuthError(OAuthErrInvalidRequest, "缺少 code 或 code_verifier")
+	}
+
+	code, err := s.oauthStore.ConsumeAuthorizationCode(ctx, model.HashAPIKey(req.Code))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, store.ErrOAuthCodeUsed) {
+			return nil, newOAuthError(OAuthErrInvalidGrant, "授权码无效或已使用")
+		}
+		return nil, fmt.Errorf("获取授权码失败: %w", err)
+	}
+	if code.ApplicationID != app.ID {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "授权码不属于该应用")
+	}
+	if time.Now().After(code.ExpiresAt) {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "授权码已过期")
+	}
+	if req.RedirectURI != code.RedirectURI {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "redirect_uri 与授权请求不一致")
+	}
+	if !verifyPKCE(req.CodeVerifier, code.CodeChallenge) {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "code_verifier 校验失败")
+	}
+
+	user, err := s.userStore.GetUserByID(ctx, code.UserID.String())
+	if err != nil || !user.IsActive() {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "授权用户不存在或已停用")
+	}
+
+	refreshToken, err := randomURLToken(oauthTokenBytes)
+	if err != nil {
+		return nil, fmt.Errorf("生成刷新令牌失败: %w", err)
+	}
+	refreshToken = model.OAuthRefreshTokenPrefix + refreshToken
+	grant := &model.OAuthGrant{
+		ApplicationID:       app.ID,
+		UserID:              user.ID,
+		AuthorizationCodeID: &code.ID,
+		Scopes:              code.Scopes,
+		RefreshTokenHash:    model.HashAPIKey(refreshToken),
+		RefreshExpiresAt:    time.Now().Add(oauthRefreshTokenTTL),
+	}
+	if err := s.oauthStore.CreateGrant(ctx, grant); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(app, user, grant.ID, code.Scopes, refreshToken)
+}
+
+// refreshToken 使用刷新令牌换取新令牌，刷新令牌每次使用后轮换
+func (s *oauthService) refreshToken(ctx context.Context, app *model.OAuthApplication, req *TokenRequest) (*TokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, newOAuthError(OAuthErrInvalidRequest, "缺少 refresh_token")
+	}
+
+	oldHash := model.HashAPIKey(req.RefreshToken)
+	grant, err := s.oauthStore.GetGrantByRefreshToken(ctx, oldHash)
+	if err != nil || grant.ApplicationID != app.ID || !grant.IsActive() || time.Now().After(grant.RefreshExpiresAt) {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "刷新令牌无效、已过期或已吊销")
+	}
+
+	// 可以申请更小的范围，但不能超过原授权
+	scopes := []string(grant.Scopes)
+	if req.Scope != "" {
+		if scopes, err = parseRequestedScopes(req.Scope, grant.Scopes); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := s.userStore.GetUserByID(ctx, grant.UserID.String())
+	if err != nil || !user.IsActive() {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "授权用户不存在或已停用")
+	}
+
+	newToken, err := randomURLToken(oauthTokenBytes)
+	if err != nil {
+		return nil, fmt.Errorf("生成刷新令牌失败: %w", err)
+	}
+	newToken = model.OAuthRefreshTokenPrefix + newToken
+	err = s.oauthStore.RotateRefreshToken(ctx, grant.ID, oldHash, model.HashAPIKey(newToken), time.Now().Add(oauthRefreshTokenTTL))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, newOAuthError(OAuthErrInvalidGrant, "刷新令牌已被使用")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(app, user, grant.ID, scopes, newToken)
+}
+
+// issueTokens 签发访问令牌，角色按权限范围降级
+func (s *oauthService) issueTokens(app *model.OAuthApplication, user *model.User, grantID uuid.UUID, scopes []string, refreshToken string) (*TokenResponse, error) {
+	role := model.ScopedRole(user.Role, scopes)
+	accessToken, err := s.jwtService.GenerateOAuthAccessToken(user.ID, user.Email, role, app.ClientID, grantID, scopes)
+	if err != nil {
+		return nil, fmt.Errorf("签发访问令牌失败: %w", err)
+	}
+	return &TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.jwtService.AccessTokenExpiry().Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        strings.Join(scopes, " "),
+	}, nil
+}
+
+// Revoke 吊销访问令牌或刷新令牌，两者都会吊销整个授权
+func (s *oauthService) Revoke(ctx context.Context, creds *OAuthClientCredentials, token string) error {
+	app, err := s.authenticateClient(ctx, creds)
+	if err != nil {
+		return err
+	}
+
+	grant := s.lookupGrant(ctx, app, token)
+	if grant == nil {
+		// RFC 7009 2.2：无效令牌也返回成功
+		return nil
+	}
+	return s.oauthStore.RevokeGrant(ctx, grant.ID)
+}
+
+// Introspect 查询令牌状态，只能查询签发给自身的令牌
+func (s *oauthService) Introspect(ctx context.Context, creds *OAuthClientCredentials, token string) (*IntrospectionResponse, error) {
+	app, err := s.authenticateClient(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	inactive := &IntrospectionResponse{Active: false}
+	grant := s.lookupGrant(ctx, app, token)
+	if grant == nil || !grant.IsActive() {
+		return inactive, nil
+	}
+
+	resp := &IntrospectionResponse{
+		Active:   true,
+		ClientID: app.ClientID,
+		Subject:  grant.UserID.String(),
+		Scope:    strings.Join(grant.Scopes, " "),
+	}
+	if strings.HasPrefix(token, model.OAuthRefreshTokenPrefix) {
+		if time.Now().After(grant.RefreshExpiresAt) {
+			return inactive, nil
+		}
+		resp.TokenType = "refresh_token"
+		resp.ExpiresAt = grant.RefreshExpiresAt.Unix()
+	} else {
+		claims, _ := s.jwtService.ValidateToken(token)
+		resp.TokenType = "access_token"
+		resp.Username = claims.Email
+		resp.Scope = strings.Join(claims.Scopes, " ")
+		resp.ExpiresAt = claims.ExpiresAt.Unix()
+	}
+	return resp, nil
+}
+
+// lookupGrant 根据访问令牌或刷新令牌查找属于该应用的授权，找不到返回 nil
+func (s *oauthService) lookupGrant(ctx context.Context, app *model.OAuthApplication, token string) *model.OAuthGrant {
+	var grant *model.OAuthGrant
+	if strings.HasPrefix(token, model.OAuthRefreshTokenPrefix) {
+		found, err := s.oauthStore.GetGrantByRefreshToken(ctx, model.HashAPIKey(token))
+		if err != nil {
+			return nil
+		}
+		grant = found
+	} else {
+		claims, err := s.jwtService.ValidateToken(token)
+		if err != nil || !claims.IsOAuth() || claims.ClientID != app.ClientID {
+			return nil
+		}
+		grantID, err := uuid.Parse(claims.GrantID)
+		if err != nil {
+			return nil
+		}
+		if grant, err = s.oauthStore.GetGrantByID(ctx, grantID); err != nil {
+			return nil
+		}
+	}
+
+	if grant.ApplicationID != app.ID {
+		return nil
+	}
+	return grant
+}
+
+// authenticateClient 认证客户端：机密客户端必须提供正确的 client_secret
+func (s *oauthService) authenticateClient(ctx context.Context, creds *OAuthClientCredentials) (*model.OAuthApplication, error) {
+	if creds.ClientID == "" {
+		return nil, newOAuthError(OAuthErrInvalidClient, "缺少 client_id")
+	}
+	app, err := s.oauthStore.GetApplicationByClientID(ctx, creds.ClientID)
+	if err != nil {
+		return nil, newOAuthError(OAuthErrInvalidClient, "客户端认证失败")
+	}
+	if app.Confidential {
+		given := model.HashAPIKey(creds.ClientSecret)
+		if creds.ClientSecret == "" || subtle.ConstantTimeCompare([]byte(given), []byte(app.ClientSecretHash)) != 1 {
+			return nil, newOAuthError(OAuthErrInvalidClient, "客户端认证失败")
+		}
+	}
+	return app, nil
+}
+
+// parseRequestedScopes 解析空格分隔的权限范围，必须是允许范围的子集
+// 未指定时默认为 read（应用允许时）
+func parseRequestedScopes(scope string, allowed []string) ([]string, error) {
+	requested := strings.Fields(scope)
+	if len(requested) == 0 {
+		requested = []string{model.APIKeyScopeRead}
+	}
+
+	scopes, err := normalizeAPIKeyScopes(requested)
+	if err != nil {
+		return nil, newOAuthError(OAuthErrInvalidScope, err.Error())
+	}
+	for _, s := range scopes {
+		found := false
+		for _, a := range allowed {
+			if a == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, newOAuthError(OAuthErrInvalidScope, "超出允许的权限范围: "+s)
+		}
+	}
+	return scopes, nil
+}
+
+// verifyPKCE 校验 code_verifier（S256）
+func verifyPKCE(verifier, challenge string) bool {
+	if !pkceVerifierPattern.MatchString(verifier) {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+// validateRedirectURI 校验回调地址：必须是绝对地址且不含片段
+// http 只允许本机地址，其他自定义 scheme 用于原生应用
+func validateRedirectURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme == "" || u.Fragment != "" {
+		return fmt.Errorf("无效的回调地址: %s", uri)
+	}
+	switch u.Scheme {
+	case "https":
+		if u.Host == "" {
+			return fmt.Errorf("无效的回调地址: %s", uri)
+		}
+	case "http":
+		host := u.Hostname()
+		if host != "localhost" && host != "127.0.0.1" && host != "::1" {
+			return fmt.Errorf("无效的回调地址: http 只允许本机地址")
+		}
+	}
+	return nil
+}
+
+// appendQuery 在回调地址上追加查询参数，保留已有参数
+func appendQuery(uri string, params url.Values) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	query := u.Query()
+	for key, values := range params {
+		for _, v := range values {
+			query.Add(key, v)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+)
+
+// pkceChallenge 计算 S256 code_challenge
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func TestVerifyPKCE(t *testing.T) {
+	verifier := strings.Repeat("a", 43)
+	challenge := pkceChallenge(verifier)
+
+	if !verifyPKCE(verifier, challenge) {
+		t.Error("正确的 verifier 应通过校验")
+	}
+	if verifyPKCE(strings.Repeat("b", 43), challenge) {
+		t.Error("错误的 verifier 不应通过校验")
+	}
+	if verifyPKCE("short", pkceChallenge("short")) {
+		t.Error("过短的 verifier 不应通过校验")
+	}
+}
+
+func TestValidateRedirectURI(t *testing.T) {
+	tests := []struct {
+		uri     string
+		wantErr bool
+	}{
+		{"https://app.example.com/callback", false},
+		{"http://localhost:3000/callback", false},
+		{"http://127.0.0.1/callback", false},
+		{"com.example.app:/oauth", false},
+		{"http://app.example.com/callback", true},
+		{"https://app.example.com/callback#frag", true},
+		{"/relative", true},
+		{"https:///no-host", true},
+	}
+	for _, tt := range tests {
+		err := validateRedirectURI(tt.uri)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateRedirectURI(%q) error = %v, wantErr %v", tt.uri, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseRequestedScopes(t *testing.T) {
+	allowed := []string{model.APIKeyScopeRead, model.APIKeyScopeWrite}
+
+	scopes, err := parseRequestedScopes("", allowed)
+	if err != nil || len(scopes) != 1 || scopes[0] != model.APIKeyScopeRead {
+		t.Errorf("默认范围 = %v, err = %v", scopes, err)
+	}
+
+	scopes, err = parseRequestedScopes("read write", allowed)
+	if err != nil || len(scopes) != 2 {
+		t.Errorf("scopes = %v, err = %v", scopes, err)
+	}
+
+	var oauthErr *OAuthError
+	if _, err := parseRequestedScopes("admin", allowed); !errors.As(err, &oauthErr) || oauthErr.Code != OAuthErrInvalidScope {
+		t.Errorf("超出允许范围应返回 invalid_scope, got %v", err)
+	}
+}
+
+func TestAppendQuery(t *testing.T) {
+	got := appendQuery("https://app.example.com/cb?tenant=1", url.Values{"code": {"abc"}, "state": {"xyz"}})
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	q := u.Query()
+	if q.Get("tenant") != "1" || q.Get("code") != "abc" || q.Get("state") != "xyz" {
+		t.Errorf("appendQuery() = %s", got)
+	}
+}
+
+func TestOAuthService_AuthorizationCodeFlow(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupIssueServiceFixtures(t, tx)
+	jwtService := NewJWTService(&config.Config{
+		JWTSecret:        "oauth-flow-secret",
+		JWTAccessExpiry:  15 * time.Minute,
+		JWTRefreshExpiry: 7 * 24 * time.Hour,
+	})
+	svc := NewOAuthService(store.NewOAuthStore(tx), store.NewUserStore(tx), jwtService)
+
+	app, err := svc.CreateApplication(f.ctx, &CreateOAuthApplicationParams{
+		Name:         "Internal Tool",
+		RedirectURIs: []string{"https://tool.example.com/callback"},
+		Scopes:       []string{model.APIKeyScopeRead, model.APIKeyScopeWrite},
+		Confidential: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateApplication() error = %v", err)
+	}
+	if app.ClientSecret == "" || app.ClientSecretHash != model.HashAPIKey(app.ClientSecret) {
+		t.Fatal("机密客户端应返回 client_secret 并保存其摘要")
+	}
+
+	verifier := strings.Repeat("v", 64)
+	authReq := &AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            app.ClientID,
+		Scope:               "read write",
+		State:               "xyz",
+		CodeChallenge:       pkceChallenge(verifier),
+		CodeChallengeMethod: model.OAuthCodeChallengeS256,
+	}
+
+	prompt, err := svc.PrepareAuthorization(f.ctx, authReq)
+	if err != nil {
+		t.Fatalf("PrepareAuthorization() error = %v", err)
+	}
+	if prompt.RedirectURI != "https://tool.example.com/callback" || len(prompt.Scopes) != 2 {
+		t.Errorf("Unexpected prompt: %+v", prompt)
+	}
+
+	// 拒绝授权
+	denied, err := svc.Authorize(f.ctx, authReq, false)
+	if err != nil || !strings.Contains(denied, "error=access_denied") {
+		t.Errorf("拒绝授权应回调 access_denied, got %s, err = %v", denied, err)
+	}
+
+	redirect, err := svc.Authorize(f.ctx, authReq, true)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	u, _ := url.Parse(redirect)
+	code := u.Query().Get("code")
+	if code == "" || u.Query().Get("state") != "xyz" {
+		t.Fatalf("回调地址缺少 code 或 state: %s", redirect)
+	}
+
+	creds := OAuthClientCredentials{ClientID: app.ClientID, ClientSecret: app.ClientSecret}
+
+	// 错误的 verifier
+	if _, err := svc.Token(context.Background(), &TokenRequest{
+		OAuthClientCredentials: creds,
+		GrantType:              OAuthGrantAuthorizationCode,
+		Code:                   code,
+		RedirectURI:            prompt.RedirectURI,
+		CodeVerifier:           strings.Repeat("w", 64),
+	}); err == nil {
+		t.Error("错误的 code_verifier 应返回错误")
+	}
+
+	// 授权码已在上一次请求中被消费，需要重新授权
+	redirect, _ = svc.Authorize(f.ctx, authReq, true)
+	u, _ = url.Parse(redirect)
+	code = u.Query().Get("code")
+
+	// 错误的 client_secret
+	if _, err := svc.Token(context.Background(), &TokenRequest{
+		OAuthClientCredentials: OAuthClientCredentials{ClientID: app.ClientID, ClientSecret: "wrong"},
+		GrantType:              OAuthGrantAuthorizationCode,
+		Code:                   code,
+		RedirectURI:            prompt.RedirectURI,
+		CodeVerifier:           verifier,
+	}); err == nil {
+		t.Error("错误的 client_secret 应返回错误")
+	}
+
+	tokens, err := svc.Token(context.Background(), &TokenRequest{
+		OAuthClientCredentials: creds,
+		GrantType:              OAuthGrantAuthorizationCode,
+		Code:                   code,
+		RedirectURI:            prompt.RedirectURI,
+		CodeVerifier:           verifier,
+	})
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" || tokens.Scope != "read write" {
+		t.Errorf("Unexpected tokens: %+v", tokens)
+	}
+
+	// 访问令牌可通过 JWTService 校验，管理员未授予 admin 范围时降级为成员
+	claims, err := jwtService.ValidateToken(tokens.AccessToken)
+	if err != nil || claims.ClientID != app.ClientID || claims.Role != string(model.RoleMember) {
+		t.Errorf("Unexpected claims: %+v, err = %v", claims, err)
+	}
+
+	introspection, err := svc.Introspect(context.Background(), &creds, tokens.AccessToken)
+	if err != nil || !introspection.Active || introspection.TokenType != "access_token" {
+		t.Errorf("Introspect() = %+v, err = %v", introspection, err)
+	}
+
+	// 刷新令牌轮换，旧令牌失效
+	refreshed, err := svc.Token(context.Background(), &TokenRequest{
+		OAuthClientCredentials: creds,
+		GrantType:              OAuthGrantRefreshToken,
+		RefreshToken:           tokens.RefreshToken,
+		Scope:                  "read",
+	})
+	if err != nil {
+		t.Fatalf("refresh Token() error = %v", err)
+	}
+	if refreshed.RefreshToken == tokens.RefreshToken || refreshed.Scope != "read" {
+		t.Errorf("Unexpected refreshed tokens: %+v", refreshed)
+	}
+	if _, err := svc.Token(context.Background(), &TokenRequest{
+		OAuthClientCredentials: creds,
+		GrantType:              OAuthGrantRefreshToken,
+		RefreshToken:           tokens.RefreshToken,
+	}); err == nil {
+		t.Error("旧刷新令牌不应再可用")
+	}
+
+	// 吊销后访问令牌和刷新令牌都失效
+	if err := svc.Revoke(context.Background(), &creds, refreshed.AccessToken); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	introspection, _ = svc.Introspect(context.Background(), &creds, refreshed.RefreshToken)
+	if introspection.Active {
+		t.Error("吊销后刷新令牌应为 inactive")
+	}
+	grantID := claims.GrantID
+	var grant model.OAuthGrant
+	if err := tx.Where("id = ?", grantID).First(&grant).Error; err != nil || grant.RevokedAt == nil {
+		t.Errorf("授权应已吊销, err = %v", err)
+	}
+}
+
+func TestOAuthService_ApplicationPermissions(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupIssueServiceFixtures(t, tx)
+	jwtService := NewJWTService(&config.Config{JWTSecret: "oauth-perm-secret", JWTAccessExpiry: 15 * time.Minute})
+	svc := NewOAuthService(store.NewOAuthStore(tx), store.NewUserStore(tx), jwtService)
+
+	memberCtx := context.WithValue(context.Background(), "user_id", f.user2ID)
+	memberCtx = context.WithValue(memberCtx, "user_role", model.RoleMember)
+
+	params := &CreateOAuthApplicationParams{
+		Name:         "Tool",
+		RedirectURIs: []string{"https://tool.example.com/callback"},
+		Scopes:       []string{model.APIKeyScopeRead},
+	}
+	if _, err := svc.CreateApplication(memberCtx, params); err == nil {
+		t.Error("普通成员不能注册 OAuth 应用")
+	}
+
+	app, err := svc.CreateApplication(f.ctx, params)
+	if err != nil {
+		t.Fatalf("CreateApplication() error = %v", err)
+	}
+	if app.ClientSecret != "" {
+		t.Error("公开客户端不应有 client_secret")
+	}
+
+	// 应用不允许 write 范围
+	_, err = svc.PrepareAuthorization(memberCtx, &AuthorizeRequest{
+		ResponseType:        "code",
+		ClientID:            app.ClientID,
+		Scope:               "write",
+		CodeChallenge:       pkceChallenge(strings.Repeat("a", 43)),
+		CodeChallengeMethod: model.OAuthCodeChallengeS256,
+	})
+	var oauthErr *OAuthError
+	if !errors.As(err, &oauthErr) || oauthErr.Code != OAuthErrInvalidScope {
+		t.Errorf("期望 invalid_scope, got %v", err)
+	}
+
+	// 未使用 PKCE
+	_, err = svc.PrepareAuthorization(memberCtx, &AuthorizeRequest{ResponseType: "code", ClientID: app.ClientID})
+	if !errors.As(err, &oauthErr) || oauthErr.Code != OAuthErrInvalidRequest {
+		t.Errorf("期望 invalid_request, got %v", err)
+	}
+
+	apps, err := svc.ListApplications(f.ctx)
+	if err != nil || len(apps) != 1 {
+		t.Errorf("ListApplications() = %d, err = %v", len(apps), err)
+	}
+	if err := svc.DeleteApplication(f.ctx, app.ID.String()); err != nil {
+		t.Errorf("DeleteApplication() error = %v", err)
+	}
+}
@@ -0,0 +1,509 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/oidc"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ErrPasswordLoginDisabled 工作区禁用了密码登录
+var ErrPasswordLoginDisabled = errors.New("密码登录已禁用，请使用单点登录")
+
+// usernameInvalidChars 用户名中不允许的字符
+var usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// mappedRoleLevels 用户组映射角色的优先级，多个用户组命中时取最高的角色
+var mappedRoleLevels = map[model.Role]int{
+	model.RoleGuest:  1,
+	model.RoleMember: 2,
+	model.RoleAdmin:  3,
+}
+
+// AuthSettings 工作区认证设置，保存在 Workspace.Settings["auth"]
+type AuthSettings struct {
+	// PasswordLoginDisabled 禁用密码登录，只允许单点登录（全局管理员除外）
+	PasswordLoginDisabled bool `json:"password_login_disabled"`
+	// TwoFactorRequired 要求密码和 LDAP 登录的用户启用两步验证（单点登录的多因素认证由 IdP 负责）
+	TwoFactorRequired bool `json:"two_factor_required"`
+	// AllowedEmailDomains 无需邀请即可注册的邮箱域名（注册为普通成员），为空时只能通过邀请注册
+	AllowedEmailDomains []string `json:"allowed_email_domains"`
+}
+
+// AuthProviders 登录页可用的登录方式
+type AuthProviders struct {
+	Password bool `json:"password"`
+	OIDC     bool `json:"oidc"`
+	LDAP     bool `json:"ldap"`
+}
+
+// OIDCLoginState 一次单点登录流程的临时状态，由调用方在回调前保存（如 Cookie）
+type OIDCLoginState struct {
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+// OIDCService 定义 OIDC 单点登录服务接口
+type OIDCService interface {
+	// Enabled 是否启用 OIDC
+	Enabled() bool
+	// Providers 获取登录页可用的登录方式
+	Providers(ctx context.Context) (*AuthProviders, error)
+	// BeginLogin 生成登录状态和 IdP 授权地址
+	BeginLogin(ctx context.Context) (*OIDCLoginState, string, error)
+	// CompleteLogin 使用回调中的授权码完成登录，返回用户和令牌
+	CompleteLogin(ctx context.Context, loginState *OIDCLoginState, code string) (*model.User, string, string, error)
+	// GetAuthSettings 获取当前用户所在工作区的认证设置
+	GetAuthSettings(ctx context.Context) (*AuthSettings, error)
+	// UpdateAuthSettings 更新当前用户所在工作区的认证设置（仅管理员）
+	UpdateAuthSettings(ctx context.Context, settings *AuthSettings) (*AuthSettings, error)
+}
+
+// oidcService 实现 OIDCService 接口
+type oidcService struct {
+	cfg                  *config.Config
+	provider             *oidc.Provider
+	userStore            store.UserStore
+	workspaceStore       store.WorkspaceStore
+	workspaceMemberStore store.WorkspaceMemberStore
+	teamStore            store.TeamStore
+	teamMemberStore      store.TeamMemberStore
+	identityStore        store.UserIdentityStore
+	sessionService       SessionService
+	auditService         AuditService
+}
+
+// NewOIDCService 创建 OIDC 单点登录服务实例，未配置 issuer 时单点登录不可用
+func NewOIDCService(cfg *config.Config, userStore store.UserStore, workspaceStore store.WorkspaceStore, workspaceMemberStore store.WorkspaceMemberStore, teamStore store.TeamStore, teamMemberStore store.TeamMemberStore, identityStore store.UserIdentityStore, sessionService SessionService) OIDCService {
+	s := &oidcService{
+		cfg:                  cfg,
+		userStore:            userStore,
+		workspaceStore:       workspaceStore,
+		workspaceMemberStore: workspaceMemberStore,
+		teamStore:            teamStore,
+		teamMemberStore:      teamMemberStore,
+		identityStore:        identityStore,
+		sessionService:       sessionService,
+	}
+	if cfg.OIDCEnabled() {
+		s.provider = oidc.NewProvider(oidc.Config{
+			IssuerURL:    cfg.OIDCIssuerURL,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       cfg.OIDCScopes,
+		})
+	}
+	return s
+}
+
+// NewOIDCServiceWithAudit 创建记录认证设置变更审计日志的 OIDC 服务实例
+func NewOIDCServiceWithAudit(cfg *config.Config, userStore store.UserStore, workspaceStore store.WorkspaceStore, workspaceMemberStore store.WorkspaceMemberStore, teamStore store.TeamStore, teamMemberStore store.TeamMemberStore, identityStore store.UserIdentityStore, sessionService SessionService, auditService AuditService) OIDCService {
+	s := NewOIDCService(cfg, userStore, workspaceStore, workspaceMemberStore, teamStore, teamMemberStore, identityStore, sessionService).(*oidcService)
+	s.auditService = auditService
+	return s
+}
+
+// Enabled 是否启用 OIDC
+func (s *oidcService) Enabled() bool {
+	return s.provider != nil
+}
+
+// Providers 获取登录页可用的登录方式
+func (s *oidcService) Providers(ctx context.Context) (*AuthProviders, error) {
+	providers := &AuthProviders{Password: true, OIDC: s.Enabled(), LDAP: s.cfg.LDAPEnabled()}
+	if !providers.OIDC && !providers.LDAP {
+		return providers, nil
+	}
+
+	workspaceID := s.cfg.OIDCWorkspaceID
+	if !providers.OIDC {
+		workspaceID = s.cfg.LDAPWorkspaceID
+	}
+	workspace, err := s.workspaceStore.GetByID(ctx, workspaceID)
+	if err != nil {
+		// 工作区尚未创建时使用默认设置
+		return providers, nil
+	}
+	settings, err := parseAuthSettings(workspace.Settings)
+	if err != nil {
+		return nil, err
+	}
+	providers.Password = !settings.PasswordLoginDisabled
+	return providers, nil
+}
+
+// BeginLogin 生成登录状态和 IdP 授权地址
+func (s *oidcService) BeginLogin(ctx context.Context) (*OIDCLoginState, string, error) {
+	if !s.Enabled() {
+		return nil, "", fmt.Errorf("单点登录未启用")
+	}
+
+	loginState := &OIDCLoginState{}
+	for _, field := range []*string{&loginState.State, &loginState.Nonce, &loginState.CodeVerifier} {
+		value, err := oidc.NewRandomString()
+		if err != nil {
+			return nil, "", fmt.Errorf("生成登录状态失败: %w", err)
+		}
+		*field = value
+	}
+
+	authURL, err := s.provider.AuthCodeURL(ctx, loginState.State, loginState.Nonce, loginState.CodeVerifier)
+	if err != nil {
+		return nil, "", err
+	}
+	return loginState, authURL, nil
+}
+
+// CompleteLogin 使用回调中的授权码完成登录，返回用户和令牌
+func (s *oidcService) CompleteLogin(ctx context.Context, loginState *OIDCLoginState, code string) (*model.User, string, string, error) {
+	if !s.Enabled() {
+		return nil, "", "", fmt.Errorf("单点登录未启用")
+	}
+	if loginState == nil || code == "" {
+		return nil, "", "", fmt.Errorf("无效的登录请求")
+	}
+
+	token, err := s.provider.Exchange(ctx, code, loginState.CodeVerifier)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("单点登录认证失败: %w", err)
+	}
+	claims, err := s.provider.VerifyIDToken(ctx, token.IDToken, loginState.Nonce)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("单点登录认证失败: %w", err)
+	}
+
+	user, err := s.resolveUser(ctx, claims)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if !user.IsActive() {
+		recordLogin(ctx, s.auditService, auditLoginOIDC, user.Email, user, ErrUserDeactivated)
+		return nil, "", "", fmt.Errorf("无权限: %w", ErrUserDeactivated)
+	}
+
+	groups := claims.Strings(s.cfg.OIDCGroupsClaim)
+	if err := s.syncRole(ctx, user, groups); err != nil {
+		return nil, "", "", err
+	}
+	s.syncTeams(ctx, user, groups)
+
+	accessToken, refreshToken, err := s.sessionService.Create(ctx, user)
+	if err != nil {
+		return nil, "", "", err
+	}
+	recordLogin(ctx, s.auditService, auditLoginOIDC, user.Email, user, nil)
+	return user, accessToken, refreshToken, nil
+}
+
+// resolveUser 查找 IdP 身份对应的用户：
+// 已关联的身份 -> 邮箱已验证的同邮箱用户（自动关联） -> 自动创建新用户
+func (s *oidcService) resolveUser(ctx context.Context, claims *oidc.Claims) (*model.User, error) {
+	email := NormalizeEmail(claims.Email)
+
+	identity, err := s.identityStore.GetBySubject(ctx, claims.Issuer, claims.Subject)
+	if err == nil {
+		user, err := s.userStore.GetUserByID(ctx, identity.UserID.String())
+		if err != nil {
+			return nil, fmt.Errorf("获取用户失败: %w", err)
+		}
+		if err := s.identityStore.TouchLogin(ctx, identity.ID, email); err != nil {
+			slog.WarnContext(ctx, "更新外部身份登录时间失败", "identity_id", identity.ID, "error", err)
+		}
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("查询外部身份失败: %w", err)
+	}
+
+	if email == "" || !emailRegex.MatchString(email) {
+		return nil, fmt.Errorf("无权限: 身份提供方未返回有效的邮箱")
+	}
+
+	user, err := s.userStore.GetUserByEmail(ctx, email)
+	switch {
+	case err == nil:
+		// 未验证的邮箱可能被冒用，不能关联到已有账号
+		if !claims.EmailVerified {
+			return nil, fmt.Errorf("无权限: 邮箱未经身份提供方验证，无法关联已有账号")
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		user, err = s.provisionUser(ctx, claims, email)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
+
+	identity = &model.UserIdentity{
+		UserID:  user.ID,
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		Email:   email,
+	}
+	if err := s.identityStore.Create(ctx, identity); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// provisionUser 首次单点登录时自动创建用户，默认角色为普通成员（随后按用户组映射同步）
+func (s *oidcService) provisionUser(ctx context.Context, claims *oidc.Claims, email string) (*model.User, error) {
+	workspaceID, err := uuid.Parse(s.cfg.OIDCWorkspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("无效的 OIDC 工作区 ID: %w", err)
+	}
+	if _, err := s.workspaceStore.GetByID(ctx, workspaceID.String()); err != nil {
+		return nil, fmt.Errorf("OIDC 工作区不存在")
+	}
+
+	username, err := uniqueUsername(ctx, s.userStore, firstNonEmptyString(claims.PreferredUsername, strings.Split(email, "@")[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	user := &model.User{
+		WorkspaceID: workspaceID,
+		Email:       email,
+		Username:    username,
+		Name:        firstNonEmptyString(claims.Name, username),
+		// 单点登录用户没有本地密码，密码登录始终失败
+		PasswordHash: "",
+		Role:         model.RoleMember,
+	}
+	if err := s.userStore.CreateUser(ctx, user); err != nil {
+		return nil, fmt.Errorf("创建用户失败: %w", err)
+	}
+	return user, nil
+}
+
+// uniqueUsername 由外部身份提供的名称生成合法且未被占用的用户名
+func uniqueUsername(ctx context.Context, userStore store.UserStore, base string) (string, error) {
+	base = usernameInvalidChars.ReplaceAllString(base, "")
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	for len(base) < 3 {
+		base += "_"
+	}
+
+	candidate := base
+	for i := 2; i <= 100; i++ {
+		if _, err := userStore.GetUserByUsername(ctx, candidate); errors.Is(err, gorm.ErrRecordNotFound) {
+			return candidate, nil
+		} else if err != nil {
+			return "", fmt.Errorf("查询用户名失败: %w", err)
+		}
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+	return base + "-" + uuid.New().String()[:8], nil
+}
+
+// syncRole 按用户组映射同步用户在 OIDC 工作区的角色，未配置映射时保持不变；全局管理员不会被降级
+// 角色写入 OIDC 工作区的成员记录；只有 OIDC 工作区是用户的主工作区时才同时修改用户自身的角色
+func (s *oidcService) syncRole(ctx context.Context, user *model.User, groups []string) error {
+	if len(s.cfg.OIDCRoleMapping) == 0 || user.Role == model.RoleGlobalAdmin {
+		return nil
+	}
+	workspaceID, err := uuid.Parse(s.cfg.OIDCWorkspaceID)
+	if err != nil {
+		return fmt.Errorf("无效的 OIDC 工作区 ID: %w", err)
+	}
+
+	role := model.RoleMember
+	matched := false
+	for _, group := range groups {
+		mapped := model.Role(s.cfg.OIDCRoleMapping[group])
+		level, ok := mappedRoleLevels[mapped]
+		if !ok {
+			continue
+		}
+		if !matched || level > mappedRoleLevels[role] {
+			role = mapped
+			matched = true
+		}
+	}
+
+	if err := s.workspaceMemberStore.SetRole(ctx, workspaceID, user.ID, role); err != nil {
+		return err
+	}
+	if user.WorkspaceID != workspaceID || user.Role == role {
+		return nil
+	}
+	user.Role = role
+	if err := s.userStore.UpdateUser(ctx, user); err != nil {
+		return fmt.Errorf("更新用户角色失败: %w", err)
+	}
+	return nil
+}
+
+// syncTeams 按用户组映射加入 OIDC 工作区的团队；只新增成员或提升角色，不会移除已有成员关系
+func (s *oidcService) syncTeams(ctx context.Context, user *model.User, groups []string) {
+	for _, group := range groups {
+		mapping, ok := s.cfg.OIDCTeamMapping[group]
+		if !ok {
+			continue
+		}
+		key, role := parseTeamMapping(mapping)
+
+		team, err := s.teamStore.GetByKey(ctx, s.cfg.OIDCWorkspaceID, key)
+		if err != nil {
+			slog.WarnContext(ctx, "OIDC 用户组映射的团队不存在", "group", group, "team_key", key)
+			continue
+		}
+
+		members, err := s.teamMemberStore.List(ctx, team.ID.String())
+		if err != nil {
+			slog.WarnContext(ctx, "查询团队成员失败", "team_key", key, "error", err)
+			continue
+		}
+		var current model.Role
+		for _, member := range members {
+			if member.UserID == user.ID {
+				current = member.Role
+				break
+			}
+		}
+
+		switch {
+		case current == "":
+			err = s.teamMemberStore.Add(ctx, &model.TeamMember{TeamID: team.ID, UserID: user.ID, Role: role})
+		case role == model.RoleAdmin && current != model.RoleAdmin:
+			err = s.teamMemberStore.UpdateRole(ctx, team.ID.String(), user.ID.String(), role)
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "同步团队成员失败", "team_key", key, "user_id", user.ID, "error", err)
+		}
+	}
+}
+
+// parseTeamMapping 解析 "KEY[:admin]" 形式的团队映射，返回团队标识和团队角色
+func parseTeamMapping(mapping string) (string, model.Role) {
+	key, roleName, _ := strings.Cut(mapping, ":")
+	if roleName == string(model.RoleAdmin) {
+		return key, model.RoleAdmin
+	}
+	return key, model.RoleMember
+}
+
+// GetAuthSettings 获取当前用户所在工作区的认证设置
+func (s *oidcService) GetAuthSettings(ctx context.Context) (*AuthSettings, error) {
+	workspace, err := s.currentWorkspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return parseAuthSettings(workspace.Settings)
+}
+
+// UpdateAuthSettings 更新当前用户所在工作区的认证设置（仅管理员）
+func (s *oidcService) UpdateAuthSettings(ctx context.Context, settings *AuthSettings) (*AuthSettings, error) {
+	userRole, _ := ctx.Value("user_role").(model.Role)
+	if userRole != model.RoleAdmin && userRole != model.RoleGlobalAdmin {
+		return nil, fmt.Errorf("无权限修改认证设置")
+	}
+	// 未启用单点登录时禁用密码登录会导致所有人无法登录
+	if settings.PasswordLoginDisabled && !s.Enabled() && !s.cfg.LDAPEnabled() {
+		return nil, fmt.Errorf("无效的认证设置: 未启用 OIDC 或 LDAP 时不能禁用密码登录")
+	}
+
+	domains, err := normalizeEmailDomains(settings.AllowedEmailDomains)
+	if err != nil {
+		return nil, err
+	}
+	settings.AllowedEmailDomains = domains
+
+	workspace, err := s.currentWorkspace(ctx)
+	if err != nil {
+		return nil, err
+	}
+	before, err := parseAuthSettings(workspace.Settings)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := mergeAuthSettings(workspace.Settings, settings)
+	if err != nil {
+		return nil, err
+	}
+	workspace.Settings = merged
+	if err := s.workspaceStore.Update(ctx, workspace); err != nil {
+		return nil, fmt.Errorf("更新认证设置失败: %w", err)
+	}
+
+	recordAudit(ctx, s.auditService, &AuditEntry{
+		WorkspaceID: workspace.ID,
+		Action:      model.AuditWorkspaceAuthSettings,
+		TargetType:  model.AuditTargetWorkspace,
+		TargetID:    workspace.ID.String(),
+		Before:      before,
+		After:       settings,
+	})
+	return settings, nil
+}
+
+// currentWorkspace 获取当前用户所在的工作区（切换后的工作区）
+func (s *oidcService) currentWorkspace(ctx context.Context) (*model.Workspace, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+	user, err := s.userStore.GetUserByID(ctx, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("用户不存在")
+	}
+	workspace, err := s.workspaceStore.GetByID(ctx, activeWorkspaceID(ctx, user).String())
+	if err != nil {
+		return nil, fmt.Errorf("工作区不存在")
+	}
+	return workspace, nil
+}
+
+// parseAuthSettings 从 Workspace.Settings 读取认证设置
+func parseAuthSettings(data datatypes.JSON) (*AuthSettings, error) {
+	settings := &AuthSettings{}
+	if len(data) == 0 {
+		return settings, nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("解析工作区设置失败: %w", err)
+	}
+	if auth, ok := raw["auth"]; ok {
+		if err := json.Unmarshal(auth, settings); err != nil {
+			return nil, fmt.Errorf("解析工作区认证设置失败: %w", err)
+		}
+	}
+	return settings, nil
+}
+
+// mergeAuthSettings 将认证设置写回 Workspace.Settings，保留其他字段
+func mergeAuthSettings(data datatypes.JSON, settings *AuthSettings) (datatypes.JSON, error) {
+	raw := make(map[string]json.RawMessage)
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("解析工作区设置失败: %w", err)
+		}
+	}
+	auth, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("序列化认证设置失败: %w", err)
+	}
+	raw["auth"] = auth
+	merged, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("序列化工作区设置失败: %w", err)
+	}
+	return merged, nil
+}
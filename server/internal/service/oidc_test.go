@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/mail/mailtest"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/oidc/oidctest"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestAuthSettings(t *testing.T) {
+	original := datatypes.JSON(`{"theme":"dark","auth":{"password_login_disabled":false}}`)
+
+	merged, err := mergeAuthSettings(original, &AuthSettings{PasswordLoginDisabled: true})
+	if err != nil {
+		t.Fatalf("mergeAuthSettings() error = %v", err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(merged, &raw); err != nil {
+		t.Fatalf("解析合并结果失败: %v", err)
+	}
+	if raw["theme"] != "dark" {
+		t.Errorf("合并后应保留其他设置, got %v", raw)
+	}
+
+	settings, err := parseAuthSettings(merged)
+	if err != nil || !settings.PasswordLoginDisabled {
+		t.Errorf("parseAuthSettings() = %+v, err = %v", settings, err)
+	}
+
+	settings, err = parseAuthSettings(nil)
+	if err != nil || settings.PasswordLoginDisabled {
+		t.Errorf("空设置应默认允许密码登录, got %+v, err = %v", settings, err)
+	}
+}
+
+func TestOIDCService_Disabled(t *testing.T) {
+	svc := NewOIDCService(&config.Config{}, nil, nil, nil, nil, nil, nil, nil)
+
+	if svc.Enabled() {
+		t.Error("未配置 issuer 时不应启用")
+	}
+	providers, err := svc.Providers(context.Background())
+	if err != nil || !providers.Password || providers.OIDC {
+		t.Errorf("Providers() = %+v, err = %v", providers, err)
+	}
+	if _, _, err := svc.BeginLogin(context.Background()); err == nil {
+		t.Error("未启用时 BeginLogin 应返回错误")
+	}
+
+	adminCtx := context.WithValue(context.Background(), "user_role", model.RoleAdmin)
+	if _, err := svc.UpdateAuthSettings(adminCtx, &AuthSettings{PasswordLoginDisabled: true}); err == nil {
+		t.Error("未启用 OIDC 时不能禁用密码登录")
+	}
+}
+
+type oidcFixtures struct {
+	*issueServiceFixtures
+	idp     *oidctest.Server
+	cfg     *config.Config
+	service OIDCService
+}
+
+func setupOIDCFixtures(t *testing.T, db *gorm.DB) *oidcFixtures {
+	base := setupIssueServiceFixtures(t, db)
+
+	idp := oidctest.NewServer("mylinear", "secret")
+	t.Cleanup(idp.Close)
+
+	cfg := &config.Config{
+		JWTSecret:        "oidc-test-secret",
+		JWTAccessExpiry:  15 * time.Minute,
+		JWTRefreshExpiry: 7 * 24 * time.Hour,
+		OIDCIssuerURL:    idp.Issuer(),
+		OIDCClientID:     "mylinear",
+		OIDCClientSecret: "secret",
+		OIDCRedirectURL:  "http://localhost:8080/api/v1/auth/oidc/callback",
+		OIDCWorkspaceID:  base.workspaceID.String(),
+		OIDCGroupsClaim:  "groups",
+		OIDCRoleMapping:  map[string]string{"idp-admins": "admin", "idp-guests": "guest"},
+		OIDCTeamMapping:  map[string]string{"engineering": base.team.Key + ":admin"},
+	}
+	svc := NewOIDCService(cfg,
+		store.NewUserStore(db),
+		store.NewWorkspaceStore(db),
+		store.NewWorkspaceMemberStore(db),
+		store.NewTeamStore(db),
+		store.NewTeamMemberStore(db),
+		store.NewUserIdentityStore(db),
+		NewSessionService(store.NewSessionStore(db), store.NewUserStore(db), store.NewWorkspaceMemberStore(db), store.NewOAuthStore(db), NewJWTService(cfg), cfg),
+	)
+
+	return &oidcFixtures{issueServiceFixtures: base, idp: idp, cfg: cfg, service: svc}
+}
+
+// ssoLogin 使用指定声明完成一次单点登录
+func (f *oidcFixtures) ssoLogin(t *testing.T, claims map[string]interface{}) (*model.User, error) {
+	t.Helper()
+	f.idp.SetClaims(claims)
+
+	loginState, authURL, err := f.service.BeginLogin(context.Background())
+	if err != nil {
+		t.Fatalf("BeginLogin() error = %v", err)
+	}
+	code, state, err := f.idp.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if state != loginState.State {
+		t.Fatalf("state = %s, 期望 %s", state, loginState.State)
+	}
+
+	user, accessToken, refreshToken, err := f.service.CompleteLogin(context.Background(), loginState, code)
+	if err == nil && (accessToken == "" || refreshToken == "") {
+		t.Error("登录成功应返回令牌")
+	}
+	return user, err
+}
+
+func TestOIDCService_ProvisionUser(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupOIDCFixtures(t, tx)
+	email := strings.ToLower(f.team.Name) + "_sso@example.com"
+
+	user, err := f.ssoLogin(t, map[string]interface{}{
+		"sub":                "sso-user-1",
+		"email":              email,
+		"email_verified":     true,
+		"name":               "SSO User",
+		"preferred_username": "sso user!",
+		"groups":             []string{"idp-guests", "idp-admins", "engineering"},
+	})
+	if err != nil {
+		t.Fatalf("CompleteLogin() error = %v", err)
+	}
+	if user.Email != email || user.Username != "ssouser" || user.Name != "SSO User" {
+		t.Errorf("Unexpected user: %+v", user)
+	}
+	if user.WorkspaceID != f.workspaceID {
+		t.Errorf("WorkspaceID = %v, 期望 %v", user.WorkspaceID, f.workspaceID)
+	}
+	// 多个用户组命中时取最高角色
+	if user.Role != model.RoleAdmin {
+		t.Errorf("Role = %s, 期望 admin", user.Role)
+	}
+	role, err := store.NewTeamMemberStore(tx).GetRole(f.ctx, f.team.ID.String(), user.ID.String())
+	if err != nil || role != model.RoleAdmin {
+		t.Errorf("团队角色 = %s, err = %v, 期望 admin", role, err)
+	}
+
+	// 再次登录按 (issuer, sub) 找到同一用户，即使邮箱已变更；角色随用户组同步
+	again, err := f.ssoLogin(t, map[string]interface{}{
+		"sub":    "sso-user-1",
+		"email":  "renamed_" + email,
+		"groups": []string{"idp-guests"},
+	})
+	if err != nil {
+		t.Fatalf("再次登录失败: %v", err)
+	}
+	if again.ID != user.ID {
+		t.Errorf("再次登录应返回同一用户")
+	}
+	if again.Role != model.RoleGuest {
+		t.Errorf("Role = %s, 期望 guest", again.Role)
+	}
+	// 团队成员关系不会被移除
+	if role, err := store.NewTeamMemberStore(tx).GetRole(f.ctx, f.team.ID.String(), user.ID.String()); err != nil || role != model.RoleAdmin {
+		t.Errorf("团队成员关系不应被移除, 团队角色 = %s, err = %v", role, err)
+	}
+
+	// 单点登录用户没有本地密码
+	if _, _, _, err := NewAuthService(store.NewUserStore(tx), store.NewWorkspaceStore(tx), NewJWTService(f.cfg), nil, nil, nil, f.cfg).Login(context.Background(), email, ""); err == nil {
+		t.Error("单点登录用户不应能使用密码登录")
+	}
+}
+
+func TestOIDCService_LinkExistingUser(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupOIDCFixtures(t, tx)
+	existing, err := store.NewUserStore(tx).GetUserByID(f.ctx, f.user2ID.String())
+	if err != nil {
+		t.Fatalf("获取用户失败: %v", err)
+	}
+
+	// 未验证的邮箱不能关联已有账号
+	if _, err := f.ssoLogin(t, map[string]interface{}{
+		"sub":   "sso-link",
+		"email": existing.Email,
+	}); err == nil || !strings.Contains(err.Error(), "无权限") {
+		t.Errorf("未验证邮箱应被拒绝, err = %v", err)
+	}
+
+	user, err := f.ssoLogin(t, map[string]interface{}{
+		"sub":            "sso-link",
+		"email":          strings.ToUpper(existing.Email),
+		"email_verified": "true",
+	})
+	if err != nil {
+		t.Fatalf("CompleteLogin() error = %v", err)
+	}
+	if user.ID != existing.ID {
+		t.Errorf("应关联到已有用户 %v, got %v", existing.ID, user.ID)
+	}
+	// 没有命中任何用户组时回落为普通成员
+	if user.Role != model.RoleMember {
+		t.Errorf("Role = %s, 期望 member", user.Role)
+	}
+}
+
+func TestOIDCService_PasswordLoginDisabled(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupOIDCFixtures(t, tx)
+	userStore := store.NewUserStore(tx)
+	jwtService := NewJWTService(f.cfg)
+	workspaceStore := store.NewWorkspaceStore(tx)
+	invitationService := NewInvitationService(store.NewInvitationStore(tx), userStore, workspaceStore, store.NewWorkspaceMemberStore(tx), store.NewTeamStore(tx), store.NewTeamMemberStore(tx), mailtest.NewSender(), f.cfg)
+	authService := NewAuthService(userStore, workspaceStore, jwtService, NewSessionService(store.NewSessionStore(tx), userStore, store.NewWorkspaceMemberStore(tx), store.NewOAuthStore(tx), jwtService, f.cfg), invitationService, nil, f.cfg)
+
+	// 设置已知密码，工作区已有成员，需允许该邮箱域名注册
+	if _, err := f.service.UpdateAuthSettings(f.ctx, &AuthSettings{AllowedEmailDomains: []string{"example.com"}}); err != nil {
+		t.Fatalf("UpdateAuthSettings() error = %v", err)
+	}
+	member, _ := userStore.GetUserByID(f.ctx, f.user2ID.String())
+	if _, _, _, err := authService.Register(context.Background(), f.workspaceID, "sso_pw_"+member.Email, "sso_pw_"+member.Username[:8], "Password123", "PW User", ""); err != nil {
+		t.Fatalf("注册用户失败: %v", err)
+	}
+
+	memberCtx := context.WithValue(context.Background(), "user_id", f.user2ID)
+	memberCtx = context.WithValue(memberCtx, "user_role", model.RoleMember)
+	if _, err := f.service.UpdateAuthSettings(memberCtx, &AuthSettings{PasswordLoginDisabled: true}); err == nil {
+		t.Error("普通成员不能修改认证设置")
+	}
+
+	if _, err := f.service.UpdateAuthSettings(f.ctx, &AuthSettings{PasswordLoginDisabled: true}); err != nil {
+		t.Fatalf("UpdateAuthSettings() error = %v", err)
+	}
+	settings, err := f.service.GetAuthSettings(f.ctx)
+	if err != nil || !settings.PasswordLoginDisabled {
+		t.Errorf("GetAuthSettings() = %+v, err = %v", settings, err)
+	}
+	providers, err := f.service.Providers(context.Background())
+	if err != nil || providers.Password || !providers.OIDC {
+		t.Errorf("Providers() = %+v, err = %v", providers, err)
+	}
+
+	if _, _, _, err := authService.Login(context.Background(), "sso_pw_"+member.Email, "Password123"); !errors.Is(err, ErrPasswordLoginDisabled) {
+		t.Errorf("禁用密码登录后 Login() error = %v", err)
+	}
+	// 也不能再自助注册密码账号
+	if _, _, _, err := authService.Register(context.Background(), f.workspaceID, "sso_pw2_"+member.Email, "sso_pw2_"+member.Username[:8], "Password123", "PW User 2", ""); !errors.Is(err, ErrPasswordLoginDisabled) {
+		t.Errorf("禁用密码登录后 Register() error = %v", err)
+	}
+}
+
+func TestOIDCService_SyncRoleOtherHomeWorkspace(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+
+	f := setupOIDCFixtures(t, tx)
+
+	// 主工作区不是 OIDC 工作区的用户
+	home := &model.Workspace{Name: "Home", Slug: "home-" + uuid.New().String()[:8]}
+	if err := tx.Create(home).Error; err != nil {
+		t.Fatalf("创建工作区失败: %v", err)
+	}
+	outsider := &model.User{
+		WorkspaceID:  home.ID,
+		Email:        "home-" + uuid.New().String()[:8] + "@example.com",
+		Username:     "home" + uuid.New().String()[:8],
+		Name:         "Home User",
+		PasswordHash: "hash",
+		Role:         model.RoleMember,
+	}
+	if err := store.NewUserStore(tx).CreateUser(f.ctx, outsider); err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+
+	user, err := f.ssoLogin(t, map[string]interface{}{
+		"sub":            "sso-home",
+		"email":          outsider.Email,
+		"email_verified": "true",
+		"groups":         []string{"idp-admins"},
+	})
+	if err != nil {
+		t.Fatalf("CompleteLogin() error = %v", err)
+	}
+
+	// 主工作区的角色不变，OIDC 工作区中成为管理员
+	if user.Role != model.RoleMember {
+		t.Errorf("主工作区角色 = %s, 期望 member", user.Role)
+	}
+	memberStore := store.NewWorkspaceMemberStore(tx)
+	member, err := memberStore.Get(f.ctx, f.workspaceID, user.ID)
+	if err != nil || member.Role != model.RoleAdmin {
+		t.Errorf("OIDC 工作区成员 = %+v, err = %v, 期望 admin", member, err)
+	}
+	member, err = memberStore.Get(f.ctx, home.ID, user.ID)
+	if err != nil || member.Role != model.RoleMember {
+		t.Errorf("主工作区成员 = %+v, err = %v, 期望 member", member, err)
+	}
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/mail"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// passwordResetTokenBytes 重置令牌随机部分的字节数
+	passwordResetTokenBytes = 32
+	// passwordResetMaxRequests 每个邮箱在时间窗口内允许的重置请求次数
+	passwordResetMaxRequests = 3
+	// passwordResetRateWindow 重置请求频率限制的时间窗口
+	passwordResetRateWindow = time.Hour
+	// passwordResetSendTimeout 发送重置邮件的超时时间
+	passwordResetSendTimeout = 30 * time.Second
+)
+
+// ErrPasswordResetRateLimited 同一邮箱请求重置过于频繁
+var ErrPasswordResetRateLimited = errors.New("请求过于频繁，请稍后再试")
+
+// ErrInvalidPasswordResetToken 重置令牌不存在、已使用或已过期
+var ErrInvalidPasswordResetToken = errors.New("重置链接无效或已过期")
+
+// PasswordResetService 定义密码重置服务接口
+type PasswordResetService interface {
+	// RequestReset 向邮箱对应的用户发送重置链接
+	// 为避免暴露邮箱是否已注册，用户不存在或不能重置时同样返回 nil
+	RequestReset(ctx context.Context, email string) error
+	// ResetPassword 使用重置令牌设置新密码，令牌只能使用一次，成功后吊销用户的所有会话和 OAuth 授权
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+// passwordResetService 实现 PasswordResetService 接口
+type passwordResetService struct {
+	userStore      store.UserStore
+	tokenStore     store.PasswordResetTokenStore
+	sessionService SessionService
+	sender         mail.Sender
+	redis          *redis.Client
+	cfg            *config.Config
+}
+
+// NewPasswordResetService 创建密码重置服务实例；redis 为 nil 时不限制请求频率
+func NewPasswordResetService(userStore store.UserStore, tokenStore store.PasswordResetTokenStore, sessionService SessionService, sender mail.Sender, redis *redis.Client, cfg *config.Config) PasswordResetService {
+	return &passwordResetService{
+		userStore:      userStore,
+		tokenStore:     tokenStore,
+		sessionService: sessionService,
+		sender:         sender,
+		redis:          redis,
+		cfg:            cfg,
+	}
+}
+
+// NewMailSender 根据配置创建邮件发送器，未配置 SMTP 时邮件只输出到日志
+func NewMailSender(cfg *config.Config) mail.Sender {
+	if !cfg.SMTPEnabled() {
+		return mail.NewLogSender()
+	}
+	return mail.NewSMTPSender(mail.Config{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.SMTPFrom,
+	})
+}
+
+// RequestReset 向邮箱对应的用户发送重置链接
+func (s *passwordResetService) RequestReset(ctx context.Context, email string) error {
+	email = NormalizeEmail(email)
+	if err := s.checkRateLimit(ctx, email); err != nil {
+		return err
+	}
+
+	user, err := s.userStore.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("获取用户失败: %w", err)
+	}
+	// 停用用户和通过 OIDC / LDAP 创建、没有本地密码的用户不能重置
+	if !user.IsActive() || user.PasswordHash == "" {
+		return nil
+	}
+
+	token, tokenHash, err := generatePasswordResetToken()
+	if err != nil {
+		return err
+	}
+	// 只有最新的重置链接有效
+	if err := s.tokenStore.InvalidateByUser(ctx, user.ID); err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(s.cfg.PasswordResetExpiry)
+	if err := s.tokenStore.Create(ctx, &model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return err
+	}
+
+	// 异步发送，响应时间不因邮箱是否已注册而不同
+	msg := s.resetMessage(user, token)
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), passwordResetSendTimeout)
+		defer cancel()
+		if err := s.sender.Send(sendCtx, msg); err != nil {
+			slog.ErrorContext(ctx, "发送密码重置邮件失败", "user_id", user.ID, "error", err)
+		}
+	}()
+	return nil
+}
+
+// ResetPassword 使用重置令牌设置新密码
+func (s *passwordResetService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	// 先校验密码强度，避免弱密码消耗令牌
+	if err := validatePassword(newPassword); err != nil {
+		return err
+	}
+
+	resetToken, err := s.tokenStore.Use(ctx, hashPasswordResetToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidPasswordResetToken
+		}
+		return err
+	}
+	user, err := s.userStore.GetUserByID(ctx, resetToken.UserID.String())
+	if err != nil {
+		return ErrInvalidPasswordResetToken
+	}
+	if !user.IsActive() {
+		return ErrUserDeactivated
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("密码哈希失败: %w", err)
+	}
+	user.PasswordHash = string(passwordHash)
+	if err := s.userStore.UpdateUser(ctx, user); err != nil {
+		return fmt.Errorf("更新密码失败: %w", err)
+	}
+
+	// 其他未使用的重置链接、所有会话和 OAuth 授权一并失效
+	if err := s.tokenStore.InvalidateByUser(ctx, user.ID); err != nil {
+		return err
+	}
+	return s.sessionService.RevokeAll(ctx, user.ID, model.SessionRevokeReasonPasswordChanged)
+}
+
+// checkRateLimit 按邮箱限制重置请求频率，不存在的邮箱同样计数
+func (s *passwordResetService) checkRateLimit(ctx context.Context, email string) error {
+	if s.redis == nil {
+		return nil
+	}
+	key := "password_reset_requests:" + email
+	count, err := s.redis.Incr(ctx, key).Result()
+	if err != nil {
+		return fmt.Errorf("检查请求频率失败: %w", err)
+	}
+	if count == 1 {
+		if err := s.redis.Expire(ctx, key, passwordResetRateWindow).Err(); err != nil {
+			return fmt.Errorf("检查请求频率失败: %w", err)
+		}
+	}
+	if count > passwordResetMaxRequests {
+		return ErrPasswordResetRateLimited
+	}
+	return nil
+}
+
+// resetMessage 生成重置密码邮件
+func (s *passwordResetService) resetMessage(user *model.User, token string) mail.Message {
+	link := s.cfg.PasswordResetURL + "?token=" + url.QueryEscape(token)
+	return mail.Message{
+		To:      user.Email,
+		Subject: "重置 MyLinear 密码",
+		Body: fmt.Sprintf("%s，你好：\n\n我们收到了重置你的账号密码的请求。请在 %s 内打开以下链接设置新密码：\n\n%s\n\n链接只能使用一次。如果这不是你本人的操作，请忽略本邮件，你的密码不会被修改。\n",
+			user.Name, formatResetExpiry(s.cfg.PasswordResetExpiry), link),
+	}
+}
+
+// formatResetExpiry 将有效期格式化为邮件中显示的文字
+func formatResetExpiry(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d 小时", d/time.Hour)
+	}
+	return fmt.Sprintf("%d 分钟", d/time.Minute)
+}
+
+// generatePasswordResetToken 生成随机重置令牌，返回令牌和保存用的哈希
+func generatePasswordResetToken() (string, string, error) {
+	token, err := randomURLToken(passwordResetTokenBytes)
+	if err != nil {
+		return "", "", fmt.Errorf("生成重置令牌失败: %w", err)
+	}
+	return token, hashPasswordResetToken(token), nil
+}
+
+// hashPasswordResetToken 计算令牌的 SHA-256 哈希（十六进制）
+func hashPasswordResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
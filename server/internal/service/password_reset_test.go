@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/mail"
+	"github.com/liwei0526vip/mylinear/internal/mail/mailtest"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestGeneratePasswordResetToken(t *testing.T) {
+	token, hash, err := generatePasswordResetToken()
+	if err != nil {
+		t.Fatalf("generatePasswordResetToken() error = %v", err)
+	}
+	if len(token) != 43 || len(hash) != 64 {
+		t.Errorf("len(token) = %d, len(hash) = %d", len(token), len(hash))
+	}
+	if hash != hashPasswordResetToken(token) {
+		t.Error("哈希应可由令牌重新计算")
+	}
+	other, _, _ := generatePasswordResetToken()
+	if other == token {
+		t.Error("每次生成的令牌应不同")
+	}
+}
+
+func TestFormatResetExpiry(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{time.Hour, "1 小时"},
+		{24 * time.Hour, "24 小时"},
+		{30 * time.Minute, "30 分钟"},
+		{90 * time.Minute, "90 分钟"},
+	}
+	for _, tt := range tests {
+		if got := formatResetExpiry(tt.d); got != tt.want {
+			t.Errorf("formatResetExpiry(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestNewMailSender(t *testing.T) {
+	if _, ok := NewMailSender(&config.Config{}).(*mail.LogSender); !ok {
+		t.Error("未配置 SMTP 时应使用 LogSender")
+	}
+	if _, ok := NewMailSender(&config.Config{SMTPHost: "smtp.example.com", SMTPPort: "587"}).(*mail.SMTPSender); !ok {
+		t.Error("配置 SMTP 后应使用 SMTPSender")
+	}
+}
+
+// waitForMail 等待异步发送的邮件
+func waitForMail(t *testing.T, sender *mailtest.Sender, count int) []mail.Message {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if messages := sender.Messages(); len(messages) >= count {
+			return messages
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("未收到第 %d 封邮件", count)
+	return nil
+}
+
+// resetTokenFromMail 从重置邮件的链接中提取令牌
+func resetTokenFromMail(t *testing.T, msg mail.Message) string {
+	t.Helper()
+	for _, field := range strings.Fields(msg.Body) {
+		if u, err := url.Parse(field); err == nil && u.Query().Get("token") != "" {
+			return u.Query().Get("token")
+		}
+	}
+	t.Fatalf("邮件中没有重置链接: %s", msg.Body)
+	return ""
+}
+
+func TestPasswordResetService_RateLimit(t *testing.T) {
+	rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	defer rdb.Close()
+	ctx := context.Background()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		t.Skip("无法连接 Redis")
+	}
+
+	svc := &passwordResetService{redis: rdb}
+	email := NormalizeEmail(time.Now().Format("20060102150405.000000") + "_reset@example.com")
+	other := "other_" + email
+	defer rdb.Del(ctx, "password_reset_requests:"+email, "password_reset_requests:"+other)
+
+	for i := 0; i < passwordResetMaxRequests; i++ {
+		if err := svc.checkRateLimit(ctx, email); err != nil {
+			t.Fatalf("第 %d 次请求 error = %v", i+1, err)
+		}
+	}
+	if err := svc.checkRateLimit(ctx, email); !errors.Is(err, ErrPasswordResetRateLimited) {
+		t.Errorf("超出次数后应返回 ErrPasswordResetRateLimited, got %v", err)
+	}
+	// 其他邮箱不受影响
+	if err := svc.checkRateLimit(ctx, other); err != nil {
+		t.Errorf("其他邮箱 error = %v", err)
+	}
+}
+
+func TestPasswordResetService(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupSessionFixtures(t, tx)
+	f.cfg.PasswordResetURL = "https://linear.example.com/reset-password"
+	f.cfg.PasswordResetExpiry = time.Hour
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("OldPassword1"), bcrypt.MinCost)
+	f.user.PasswordHash = string(hash)
+	userStore := store.NewUserStore(tx)
+	if err := userStore.UpdateUser(f.ctx, f.user); err != nil {
+		t.Fatalf("设置密码失败: %v", err)
+	}
+	_, _, refreshToken := f.login(t, "Chrome")
+
+	sender := mailtest.NewSender()
+	svc := NewPasswordResetService(userStore, store.NewPasswordResetTokenStore(tx), f.service, sender, nil, f.cfg)
+	ctx := context.Background()
+
+	// 未注册的邮箱不发送邮件，也不返回错误
+	if err := svc.RequestReset(ctx, "nobody@example.com"); err != nil {
+		t.Fatalf("RequestReset() 未注册邮箱 error = %v", err)
+	}
+
+	// 邮箱大小写不敏感；新的请求使旧链接失效
+	if err := svc.RequestReset(ctx, " "+strings.ToUpper(f.user.Email)+" "); err != nil {
+		t.Fatalf("RequestReset() error = %v", err)
+	}
+	if err := svc.RequestReset(ctx, f.user.Email); err != nil {
+		t.Fatalf("RequestReset() error = %v", err)
+	}
+	messages := waitForMail(t, sender, 2)
+	if len(messages) != 2 || messages[1].To != f.user.Email || !strings.Contains(messages[1].Body, "1 小时") {
+		t.Fatalf("messages = %+v", messages)
+	}
+	oldToken := resetTokenFromMail(t, messages[0])
+	token := resetTokenFromMail(t, messages[1])
+
+	if err := svc.ResetPassword(ctx, oldToken, "NewPassword1"); !errors.Is(err, ErrInvalidPasswordResetToken) {
+		t.Errorf("旧链接应失效, got %v", err)
+	}
+	// 弱密码不消耗令牌
+	if err := svc.ResetPassword(ctx, token, "weak"); err == nil {
+		t.Error("新密码强度不足应返回错误")
+	}
+	if err := svc.ResetPassword(ctx, token, "NewPassword1"); err != nil {
+		t.Fatalf("ResetPassword() error = %v", err)
+	}
+	if err := svc.ResetPassword(ctx, token, "OtherPassword1"); !errors.Is(err, ErrInvalidPasswordResetToken) {
+		t.Errorf("令牌只能使用一次, got %v", err)
+	}
+
+	if _, _, _, err := f.authService.Login(ctx, f.user.Email, "NewPassword1"); err != nil {
+		t.Errorf("重置后应能使用新密码登录: %v", err)
+	}
+	if _, _, err := f.authService.RefreshToken(ctx, refreshToken); err == nil {
+		t.Error("重置密码后旧刷新令牌应失效")
+	}
+}
+
+func TestPasswordResetService_SkipsUsersWithoutPassword(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupSessionFixtures(t, tx)
+
+	// 通过 SSO 创建的用户没有本地密码
+	f.user.PasswordHash = ""
+	userStore := store.NewUserStore(tx)
+	if err := userStore.UpdateUser(f.ctx, f.user); err != nil {
+		t.Fatalf("更新用户失败: %v", err)
+	}
+
+	sender := mailtest.NewSender()
+	svc := NewPasswordResetService(userStore, store.NewPasswordResetTokenStore(tx), f.service, sender, nil, f.cfg)
+	if err := svc.RequestReset(context.Background(), f.user.Email); err != nil {
+		t.Fatalf("RequestReset() error = %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if len(sender.Messages()) != 0 {
+		t.Error("没有本地密码的用户不应收到重置邮件")
+	}
+
+	f.user.PasswordHash = "hash"
+	now := time.Now()
+	f.user.DeactivatedAt = &now
+	if err := userStore.UpdateUser(f.ctx, f.user); err != nil {
+		t.Fatalf("更新用户失败: %v", err)
+	}
+	if err := svc.RequestReset(context.Background(), f.user.Email); err != nil {
+		t.Fatalf("RequestReset() error = %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if len(sender.Messages()) != 0 {
+		t.Error("停用用户不应收到重置邮件")
+	}
+}
@@ -227,9 +227,8 @@ func (s *projectService) DeleteProject(ctx context.Context, projectID uuid.UUID)
 		return ErrProjectNotFound
 	}
 
-	// 检查权限：Workspace Admin 或 Global Admin 可以直接删除
-	user, err := s.userStore.GetUserByID(ctx, userID.String())
-	if err == nil && (user.Role == model.RoleAdmin || user.Role == model.RoleGlobalAdmin) {
+	// 检查权限：项目所属工作区的管理员或全局管理员可以直接删除
+	if isAdminOfWorkspace(ctx, project.WorkspaceID) {
 		return s.projectStore.SoftDelete(ctx, projectID)
 	}
 
@@ -251,7 +250,7 @@ func (s *projectService) DeleteProject(ctx context.Context, projectID uuid.UUID)
 			return ErrProjectNotAuthorized
 		}
 	} else {
-		// 如果项目没有关联团队，只有 Workspace Admin 可以删除（上面已通过）
+		// 如果项目没有关联团队，只有工作区管理员可以删除（上面已通过）
 		// 普通用户无权删除
 		return ErrProjectNotAuthorized
 	}
@@ -276,6 +275,14 @@ func (s *projectService) GetProjectProgress(ctx context.Context, projectID uuid.
 
 // ListProjectIssues 获取项目关联的 Issue 列表
 func (s *projectService) ListProjectIssues(ctx context.Context, projectID uuid.UUID, filter *store.IssueFilter, page, pageSize int) ([]model.Issue, int64, error) {
+	// 项目可以关联多个团队，不返回用户不在其中的私有团队的 Issue
+	if userID, ok := ctx.Value("user_id").(uuid.UUID); ok {
+		if filter == nil {
+			filter = &store.IssueFilter{}
+		}
+		filter.VisibleTo = &userID
+	}
+
 	issues, total, err := s.projectStore.ListIssues(ctx, projectID, filter, page, pageSize)
 	if err != nil {
 		return nil, 0, fmt.Errorf("获取项目 Issue 列表失败: %w", err)
@@ -221,7 +221,7 @@ func TestProjectService_DeleteProject(t *testing.T) {
 	ctx := context.Background()
 
 	// 准备测试数据
-	workspace, adminUser, team := setupProjectServiceFixtures(t, tx)
+	workspace, _, team := setupProjectServiceFixtures(t, tx)
 
 	// 创建普通成员
 	memberUser := &model.User{
@@ -242,6 +242,17 @@ func TestProjectService_DeleteProject(t *testing.T) {
 	}
 	assert.NoError(t, tx.Create(membership).Error)
 
+	// 创建不属于团队的工作区管理员
+	workspaceAdmin := &model.User{
+		WorkspaceID:  workspace.ID,
+		Email:        "wsadmin-" + uuid.New().String()[:8] + "@example.com",
+		Username:     "wsadmin" + uuid.New().String()[:8],
+		Name:         "Workspace Admin",
+		PasswordHash: "hash",
+		Role:         model.RoleAdmin,
+	}
+	assert.NoError(t, tx.Create(workspaceAdmin).Error)
+
 	// 创建测试项目（关联团队）
 	project := &model.Project{
 		WorkspaceID: workspace.ID,
@@ -252,24 +263,39 @@ func TestProjectService_DeleteProject(t *testing.T) {
 	assert.NoError(t, projectStore.Create(ctx, project))
 
 	tests := []struct {
-		name      string
-		projectID uuid.UUID
-		userID    uuid.UUID
-		wantErr   bool
-		errMsg    string
+		name        string
+		projectID   uuid.UUID
+		userID      uuid.UUID
+		userRole    model.Role
+		workspaceID uuid.UUID
+		wantErr     bool
+		errMsg      string
 	}{
 		{
-			name:      "权限校验-非Admin拒绝",
-			projectID: project.ID,
-			userID:    memberUser.ID,
-			wantErr:   true,
-			errMsg:    "权限不足",
+			name:        "权限校验-非Admin拒绝",
+			projectID:   project.ID,
+			userID:      memberUser.ID,
+			userRole:    model.RoleMember,
+			workspaceID: workspace.ID,
+			wantErr:     true,
+			errMsg:      "权限不足",
 		},
 		{
-			name:      "Admin可以删除",
-			projectID: project.ID,
-			userID:    adminUser.ID,
-			wantErr:   false,
+			name:        "其他工作区的Admin拒绝",
+			projectID:   project.ID,
+			userID:      workspaceAdmin.ID,
+			userRole:    model.RoleAdmin,
+			workspaceID: uuid.New(),
+			wantErr:     true,
+			errMsg:      "权限不足",
+		},
+		{
+			name:        "Admin可以删除",
+			projectID:   project.ID,
+			userID:      workspaceAdmin.ID,
+			userRole:    model.RoleAdmin,
+			workspaceID: workspace.ID,
+			wantErr:     false,
 		},
 	}
 
@@ -277,6 +303,8 @@ func TestProjectService_DeleteProject(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// 设置用户上下文
 			userCtx := context.WithValue(ctx, "user_id", tt.userID)
+			userCtx = context.WithValue(userCtx, "user_role", tt.userRole)
+			userCtx = context.WithValue(userCtx, "workspace_id", tt.workspaceID)
 
 			err := service.DeleteProject(userCtx, tt.projectID)
 
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/gorm"
+)
+
+// maxUserAgentLength 保存的 User-Agent 最大长度
+const maxUserAgentLength = 512
+
+// ErrRefreshTokenReused 已轮换的刷新令牌被再次使用，整个会话已吊销
+var ErrRefreshTokenReused = errors.New("令牌已失效: 刷新令牌被重复使用，会话已吊销")
+
+// SessionInfo 会话列表项
+type SessionInfo struct {
+	model.Session
+	// Current 是否为发起请求的会话
+	Current bool `json:"current"`
+}
+
+// SessionService 定义登录会话服务接口
+// 每次登录创建一个会话，会话内的刷新令牌每次刷新都会轮换
+type SessionService interface {
+	// Create 为用户创建会话，返回访问令牌和刷新令牌
+	// 设备信息从 ctx 的 user_agent / client_ip 中读取
+	Create(ctx context.Context, user *model.User) (string, string, error)
+	// Refresh 使用刷新令牌换取新的令牌；已轮换的旧令牌再次使用时吊销整个会话
+	Refresh(ctx context.Context, claims *TokenClaims) (string, string, error)
+	// Logout 吊销刷新令牌所属的会话
+	Logout(ctx context.Context, claims *TokenClaims) error
+	// List 获取当前用户的有效会话
+	List(ctx context.Context) ([]SessionInfo, error)
+	// Revoke 吊销当前用户的指定会话
+	Revoke(ctx context.Context, id uuid.UUID) error
+	// RevokeOthers 吊销当前用户除当前会话外的所有会话，返回吊销数量
+	RevokeOthers(ctx context.Context) (int64, error)
+	// RevokeAll 吊销用户的所有会话和 OAuth 授权（修改密码、重置密码）
+	// 第三方应用持有的令牌同样可能落入冒用者手中，改密后需要用户重新授权
+	RevokeAll(ctx context.Context, userID uuid.UUID, reason string) error
+	// SwitchWorkspace 将当前会话切换到指定工作区，返回作用于该工作区的新访问令牌
+	// 之后刷新得到的访问令牌同样作用于该工作区
+	SwitchWorkspace(ctx context.Context, workspaceID uuid.UUID) (string, model.Role, error)
+}
+
+// sessionService 实现 SessionService 接口
+type sessionService struct {
+	sessionStore         store.SessionStore
+	userStore            store.UserStore
+	workspaceMemberStore store.WorkspaceMemberStore
+	oauthStore           store.OAuthStore
+	jwtService           JWTService
+	cfg                  *config.Config
+}
+
+// NewSessionService 创建会话服务实例
+func NewSessionService(sessionStore store.SessionStore, userStore store.UserStore, workspaceMemberStore store.WorkspaceMemberStore, oauthStore store.OAuthStore, jwtService JWTService, cfg *config.Config) SessionService {
+	return &sessionService{
+		sessionStore:         sessionStore,
+		userStore:            userStore,
+		workspaceMemberStore: workspaceMemberStore,
+		oauthStore:           oauthStore,
+		jwtService:           jwtService,
+		cfg:                  cfg,
+	}
+}
+
+// Create 为用户创建会话，返回访问令牌和刷新令牌；新会话位于用户的主工作区
+func (s *sessionService) Create(ctx context.Context, user *model.User) (string, string, error) {
+	now := time.Now()
+	userAgent, ip := clientInfo(ctx)
+	workspaceID := user.WorkspaceID
+	session := &model.Session{
+		Model:       model.Model{ID: uuid.New()},
+		UserID:      user.ID,
+		WorkspaceID: &workspaceID,
+		UserAgent:   userAgent,
+		IPAddress:   ip,
+		LastUsedAt:  now,
+		ExpiresAt:   now.Add(s.cfg.JWTRefreshExpiry),
+	}
+
+	refreshToken, jti, err := s.generateRefreshToken(user.ID, session.ID)
+	if err != nil {
+		return "", "", err
+	}
+	session.RefreshTokenJTI = jti
+	if err := s.sessionStore.Create(ctx, session); err != nil {
+		return "", "", err
+	}
+
+	accessToken, err := s.jwtService.GenerateSessionAccessToken(user.ID, user.Email, user.Role, user.WorkspaceID, session.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("生成访问令牌失败: %w", err)
+	}
+	return accessToken, refreshToken, nil
+}
+
+// Refresh 使用刷新令牌换取新的令牌；已轮换的旧令牌再次使用时吊销整个会话
+func (s *sessionService) Refresh(ctx context.Context, claims *TokenClaims) (string, string, error) {
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return "", "", fmt.Errorf("令牌已失效")
+	}
+	session, err := s.sessionStore.GetByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", "", fmt.Errorf("令牌已失效")
+		}
+		return "", "", fmt.Errorf("获取会话失败: %w", err)
+	}
+	if session.UserID.String() != claims.UserID || !session.IsActive(time.Now()) {
+		return "", "", fmt.Errorf("令牌已失效")
+	}
+	// 旧令牌被再次使用说明令牌可能已泄露，吊销整个令牌家族
+	if session.RefreshTokenJTI != claims.JTI {
+		return "", "", s.revokeReused(ctx, session.ID)
+	}
+
+	user, err := s.userStore.GetUserByID(ctx, session.UserID.String())
+	if err != nil {
+		return "", "", fmt.Errorf("用户不存在")
+	}
+	if !user.IsActive() {
+		return "", "", ErrUserDeactivated
+	}
+
+	// 会话所在工作区的成员资格已被移除时回到主工作区
+	workspaceID := user.WorkspaceID
+	if session.WorkspaceID != nil {
+		workspaceID = *session.WorkspaceID
+	}
+	role, err := s.workspaceRole(ctx, user, workspaceID)
+	if err != nil {
+		workspaceID, role = user.WorkspaceID, user.Role
+		if err := s.sessionStore.SetWorkspace(ctx, session.ID, workspaceID); err != nil {
+			return "", "", err
+		}
+	}
+
+	refreshToken, jti, err := s.generateRefreshToken(user.ID, session.ID)
+	if err != nil {
+		return "", "", err
+	}
+	now := time.Now()
+	session.RefreshTokenJTI = jti
+	session.UserAgent, session.IPAddress = clientInfo(ctx)
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(s.cfg.JWTRefreshExpiry)
+	rotated, err := s.sessionStore.Rotate(ctx, session, claims.JTI)
+	if err != nil {
+		return "", "", err
+	}
+	// 并发请求已使用同一令牌完成刷新
+	if !rotated {
+		return "", "", s.revokeReused(ctx, session.ID)
+	}
+
+	accessToken, err := s.jwtService.GenerateSessionAccessToken(user.ID, user.Email, role, workspaceID, session.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("生成访问令牌失败: %w", err)
+	}
+	return accessToken, refreshToken, nil
+}
+
+// Logout 吊销刷新令牌所属的会话
+func (s *sessionService) Logout(ctx context.Context, claims *TokenClaims) error {
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return fmt.Errorf("令牌已失效")
+	}
+	session, err := s.sessionStore.GetByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("获取会话失败: %w", err)
+	}
+	if session.UserID.String() != claims.UserID {
+		return nil
+	}
+	return s.sessionStore.Revoke(ctx, session.ID, model.SessionRevokeReasonLogout)
+}
+
+// List 获取当前用户的有效会话
+func (s *sessionService) List(ctx context.Context) ([]SessionInfo, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+	sessions, err := s.sessionStore.ListActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	currentID, _ := ctx.Value("session_id").(uuid.UUID)
+	result := make([]SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, SessionInfo{Session: session, Current: session.ID == currentID})
+	}
+	return result, nil
+}
+
+// Revoke 吊销当前用户的指定会话
+func (s *sessionService) Revoke(ctx context.Context, id uuid.UUID) error {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return fmt.Errorf("未认证")
+	}
+	session, err := s.sessionStore.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("会话不存在")
+		}
+		return fmt.Errorf("获取会话失败: %w", err)
+	}
+	// 不暴露其他用户的会话是否存在
+	if session.UserID != userID {
+		return fmt.Errorf("会话不存在")
+	}
+	return s.sessionStore.Revoke(ctx, session.ID, model.SessionRevokeReasonRevoked)
+}
+
+// RevokeOthers 吊销当前用户除当前会话外的所有会话，返回吊销数量
+// 使用 API Key 等不属于会话的凭证调用时吊销全部会话
+func (s *sessionService) RevokeOthers(ctx context.Context) (int64, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return 0, fmt.Errorf("未认证")
+	}
+	currentID, _ := ctx.Value("session_id").(uuid.UUID)
+	return s.sessionStore.RevokeByUser(ctx, userID, currentID, model.SessionRevokeReasonRevoked)
+}
+
+// RevokeAll 吊销用户的所有会话和 OAuth 授权
+func (s *sessionService) RevokeAll(ctx context.Context, userID uuid.UUID, reason string) error {
+	if _, err := s.sessionStore.RevokeByUser(ctx, userID, uuid.Nil, reason); err != nil {
+		return err
+	}
+	return s.oauthStore.RevokeGrantsByUser(ctx, userID)
+}
+
+// SwitchWorkspace 将当前会话切换到指定工作区，返回新的访问令牌和用户在该工作区的角色
+// API Key 等不属于会话的凭证不能切换工作区
+func (s *sessionService) SwitchWorkspace(ctx context.Context, workspaceID uuid.UUID) (string, model.Role, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return "", "", fmt.Errorf("未认证")
+	}
+	sessionID, ok := ctx.Value("session_id").(uuid.UUID)
+	if !ok {
+		return "", "", fmt.Errorf("无效的操作: 只有登录会话可以切换工作区")
+	}
+
+	user, err := s.userStore.GetUserByID(ctx, userID.String())
+	if err != nil {
+		return "", "", fmt.Errorf("用户不存在")
+	}
+	role, err := s.workspaceRole(ctx, user, workspaceID)
+	if err != nil {
+		return "", "", err
+	}
+	if err := s.sessionStore.SetWorkspace(ctx, sessionID, workspaceID); err != nil {
+		return "", "", err
+	}
+
+	accessToken, err := s.jwtService.GenerateSessionAccessToken(user.ID, user.Email, role, workspaceID, sessionID)
+	if err != nil {
+		return "", "", fmt.Errorf("生成访问令牌失败: %w", err)
+	}
+	return accessToken, role, nil
+}
+
+// workspaceRole 获取用户在工作区的角色
+// 主工作区使用用户自身的角色；全局管理员在任何工作区都是全局管理员
+func (s *sessionService) workspaceRole(ctx context.Context, user *model.User, workspaceID uuid.UUID) (model.Role, error) {
+	if workspaceID == user.WorkspaceID {
+		return user.Role, nil
+	}
+	member, err := s.workspaceMemberStore.Get(ctx, workspaceID, user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("获取工作区成员失败: %w", err)
+	}
+	if user.Role == model.RoleGlobalAdmin {
+		return model.RoleGlobalAdmin, nil
+	}
+	if member == nil {
+		return "", fmt.Errorf("无权限访问此工作区")
+	}
+	return member.Role, nil
+}
+
+// generateRefreshToken 生成会话的刷新令牌，返回令牌和令牌 ID
+func (s *sessionService) generateRefreshToken(userID, sessionID uuid.UUID) (string, string, error) {
+	refreshToken, err := s.jwtService.GenerateRefreshToken(userID, sessionID)
+	if err != nil {
+		return "", "", fmt.Errorf("生成刷新令牌失败: %w", err)
+	}
+	claims, err := s.jwtService.GetTokenClaims(refreshToken)
+	if err != nil {
+		return "", "", fmt.Errorf("生成刷新令牌失败: %w", err)
+	}
+	return refreshToken, claims.JTI, nil
+}
+
+// revokeReused 检测到刷新令牌重复使用时吊销会话
+func (s *sessionService) revokeReused(ctx context.Context, sessionID uuid.UUID) error {
+	if err := s.sessionStore.Revoke(ctx, sessionID, model.SessionRevokeReasonTokenReuse); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// clientInfo 从 ctx 中读取客户端的 User-Agent 和 IP
+func clientInfo(ctx context.Context) (string, string) {
+	userAgent, _ := ctx.Value("user_agent").(string)
+	ip, _ := ctx.Value("client_ip").(string)
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+	}
+	return userAgent, ip
+}
@@ -0,0 +1,362 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func TestClientInfo(t *testing.T) {
+	ctx := context.WithValue(context.Background(), "user_agent", "Mozilla/5.0")
+	ctx = context.WithValue(ctx, "client_ip", "10.0.0.1")
+	if ua, ip := clientInfo(ctx); ua != "Mozilla/5.0" || ip != "10.0.0.1" {
+		t.Errorf("clientInfo() = %q, %q", ua, ip)
+	}
+
+	// 过长的 User-Agent 截断后仍是有效的 UTF-8
+	long := strings.Repeat("a", maxUserAgentLength-1) + "设备"
+	ua, _ := clientInfo(context.WithValue(context.Background(), "user_agent", long))
+	if len(ua) != maxUserAgentLength-1 {
+		t.Errorf("len(ua) = %d, want %d", len(ua), maxUserAgentLength-1)
+	}
+}
+
+type sessionFixtures struct {
+	*issueServiceFixtures
+	cfg          *config.Config
+	service      SessionService
+	authService  AuthService
+	sessionStore store.SessionStore
+	jwtService   JWTService
+	user         *model.User
+}
+
+func setupSessionFixtures(t *testing.T, db *gorm.DB) *sessionFixtures {
+	base := setupIssueServiceFixtures(t, db)
+	cfg := &config.Config{
+		JWTSecret:        "session-test-secret",
+		JWTAccessExpiry:  15 * time.Minute,
+		JWTRefreshExpiry: 7 * 24 * time.Hour,
+	}
+	userStore := store.NewUserStore(db)
+	sessionStore := store.NewSessionStore(db)
+	jwtService := NewJWTService(cfg)
+	svc := NewSessionService(sessionStore, userStore, store.NewWorkspaceMemberStore(db), store.NewOAuthStore(db), jwtService, cfg)
+
+	user, err := userStore.GetUserByID(base.ctx, base.userID.String())
+	if err != nil {
+		t.Fatalf("获取用户失败: %v", err)
+	}
+
+	return &sessionFixtures{
+		issueServiceFixtures: base,
+		cfg:                  cfg,
+		service:              svc,
+		authService:          NewAuthService(userStore, store.NewWorkspaceStore(db), jwtService, svc, nil, nil, cfg),
+		sessionStore:         sessionStore,
+		jwtService:           jwtService,
+		user:                 user,
+	}
+}
+
+// login 模拟从指定设备登录，返回访问令牌和刷新令牌的 claims
+func (f *sessionFixtures) login(t *testing.T, userAgent string) (*TokenClaims, *TokenClaims, string) {
+	t.Helper()
+	ctx := context.WithValue(context.Background(), "user_agent", userAgent)
+	ctx = context.WithValue(ctx, "client_ip", "127.0.0.1")
+	accessToken, refreshToken, err := f.service.Create(ctx, f.user)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	access, _ := f.jwtService.ValidateToken(accessToken)
+	refresh, _ := f.jwtService.ValidateToken(refreshToken)
+	return access, refresh, refreshToken
+}
+
+// userCtx 返回属于指定会话的当前用户上下文
+func (f *sessionFixtures) userCtx(sessionID string) context.Context {
+	return context.WithValue(f.ctx, "session_id", uuid.MustParse(sessionID))
+}
+
+func TestSessionService_CreateAndRefresh(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupSessionFixtures(t, tx)
+
+	access, refresh, refreshToken := f.login(t, "Mozilla/5.0 (Macintosh)")
+	if access.SessionID == "" || access.SessionID != refresh.SessionID {
+		t.Fatalf("访问令牌和刷新令牌应属于同一会话: %q, %q", access.SessionID, refresh.SessionID)
+	}
+	session, err := f.sessionStore.GetByID(context.Background(), uuid.MustParse(refresh.SessionID))
+	if err != nil {
+		t.Fatalf("会话未保存: %v", err)
+	}
+	if session.UserAgent != "Mozilla/5.0 (Macintosh)" || session.IPAddress != "127.0.0.1" || session.RefreshTokenJTI != refresh.JTI {
+		t.Errorf("session = %+v", session)
+	}
+
+	// 刷新后令牌轮换，会话不变
+	newAccessToken, newRefreshToken, err := f.authService.RefreshToken(context.Background(), refreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken() error = %v", err)
+	}
+	newAccess, _ := f.jwtService.ValidateToken(newAccessToken)
+	newRefresh, _ := f.jwtService.ValidateToken(newRefreshToken)
+	if newRefresh.SessionID != refresh.SessionID || newAccess.SessionID != refresh.SessionID {
+		t.Error("刷新后应保持同一会话")
+	}
+	if newRefresh.JTI == refresh.JTI {
+		t.Error("刷新后应签发新的刷新令牌")
+	}
+
+	// 旧令牌再次使用：吊销整个会话，新令牌也失效
+	if _, _, err := f.authService.RefreshToken(context.Background(), refreshToken); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("重复使用旧令牌应返回 ErrRefreshTokenReused, got %v", err)
+	}
+	if _, _, err := f.authService.RefreshToken(context.Background(), newRefreshToken); err == nil {
+		t.Error("检测到重复使用后，会话内的新令牌也应失效")
+	}
+	session, _ = f.sessionStore.GetByID(context.Background(), session.ID)
+	if session.RevokedAt == nil || session.RevokeReason != model.SessionRevokeReasonTokenReuse {
+		t.Errorf("会话应因令牌重复使用被吊销: %+v", session)
+	}
+}
+
+func TestSessionService_Logout(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupSessionFixtures(t, tx)
+
+	_, refresh, refreshToken := f.login(t, "curl/8.0")
+	if err := f.authService.Logout(context.Background(), refreshToken); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	session, _ := f.sessionStore.GetByID(context.Background(), uuid.MustParse(refresh.SessionID))
+	if session.RevokedAt == nil || session.RevokeReason != model.SessionRevokeReasonLogout {
+		t.Errorf("登出后会话应被吊销: %+v", session)
+	}
+	if _, _, err := f.authService.RefreshToken(context.Background(), refreshToken); err == nil {
+		t.Error("登出后刷新令牌应失效")
+	}
+}
+
+func TestSessionService_ListAndRevoke(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupSessionFixtures(t, tx)
+
+	current, _, _ := f.login(t, "Chrome")
+	other, _, _ := f.login(t, "Firefox")
+	third, _, _ := f.login(t, "Safari")
+	ctx := f.userCtx(current.SessionID)
+
+	sessions, err := f.service.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(sessions) != 3 {
+		t.Fatalf("len(sessions) = %d, want 3", len(sessions))
+	}
+	for _, session := range sessions {
+		if session.Current != (session.ID.String() == current.SessionID) {
+			t.Errorf("会话 %s Current = %v", session.UserAgent, session.Current)
+		}
+	}
+
+	// 其他用户不能吊销
+	otherUserCtx := context.WithValue(context.Background(), "user_id", f.user2ID)
+	if err := f.service.Revoke(otherUserCtx, uuid.MustParse(other.SessionID)); err == nil {
+		t.Error("不能吊销其他用户的会话")
+	}
+
+	if err := f.service.Revoke(ctx, uuid.MustParse(other.SessionID)); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if active, _ := store.IsSessionActive(context.Background(), tx, uuid.MustParse(other.SessionID)); active {
+		t.Error("吊销后会话应失效")
+	}
+
+	revoked, err := f.service.RevokeOthers(ctx)
+	if err != nil {
+		t.Fatalf("RevokeOthers() error = %v", err)
+	}
+	if revoked != 1 {
+		t.Errorf("revoked = %d, want 1", revoked)
+	}
+	if active, _ := store.IsSessionActive(context.Background(), tx, uuid.MustParse(third.SessionID)); active {
+		t.Error("其他会话应被吊销")
+	}
+	if active, _ := store.IsSessionActive(context.Background(), tx, uuid.MustParse(current.SessionID)); !active {
+		t.Error("当前会话不应被吊销")
+	}
+}
+
+func TestSessionService_SwitchWorkspace(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupSessionFixtures(t, tx)
+
+	other := &model.Workspace{Name: "Other", Slug: "other-" + uuid.New().String()[:8]}
+	if err := tx.Create(other).Error; err != nil {
+		t.Fatalf("创建工作区失败: %v", err)
+	}
+	memberStore := store.NewWorkspaceMemberStore(tx)
+
+	access, _, refreshToken := f.login(t, "Chrome")
+	if access.WorkspaceID != f.user.WorkspaceID.String() {
+		t.Errorf("新会话应位于主工作区: %q", access.WorkspaceID)
+	}
+	ctx := f.userCtx(access.SessionID)
+
+	// 不是成员不能切换
+	if _, _, err := f.service.SwitchWorkspace(ctx, other.ID); err == nil || !strings.Contains(err.Error(), "无权限") {
+		t.Fatalf("非成员切换应返回无权限, got %v", err)
+	}
+
+	if err := memberStore.Add(context.Background(), &model.WorkspaceMember{WorkspaceID: other.ID, UserID: f.user.ID, Role: model.RoleMember}); err != nil {
+		t.Fatalf("加入工作区失败: %v", err)
+	}
+	token, role, err := f.service.SwitchWorkspace(ctx, other.ID)
+	if err != nil {
+		t.Fatalf("SwitchWorkspace() error = %v", err)
+	}
+	if role != model.RoleMember {
+		t.Errorf("role = %q, want member", role)
+	}
+	claims, _ := f.jwtService.ValidateToken(token)
+	if claims.WorkspaceID != other.ID.String() || claims.Role != string(model.RoleMember) || claims.SessionID != access.SessionID {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	// 刷新后仍位于切换后的工作区
+	newAccessToken, newRefreshToken, err := f.authService.RefreshToken(context.Background(), refreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken() error = %v", err)
+	}
+	claims, _ = f.jwtService.ValidateToken(newAccessToken)
+	if claims.WorkspaceID != other.ID.String() || claims.Role != string(model.RoleMember) {
+		t.Errorf("刷新后 claims = %+v", claims)
+	}
+
+	// 成员资格被移除后刷新回到主工作区
+	if err := tx.Where("workspace_id = ? AND user_id = ?", other.ID, f.user.ID).Delete(&model.WorkspaceMember{}).Error; err != nil {
+		t.Fatalf("移除成员失败: %v", err)
+	}
+	newAccessToken, _, err = f.authService.RefreshToken(context.Background(), newRefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshToken() error = %v", err)
+	}
+	claims, _ = f.jwtService.ValidateToken(newAccessToken)
+	if claims.WorkspaceID != f.user.WorkspaceID.String() || claims.Role != string(f.user.Role) {
+		t.Errorf("移除成员后 claims = %+v", claims)
+	}
+
+	// 不属于会话的凭证不能切换
+	if _, _, err := f.service.SwitchWorkspace(f.ctx, f.user.WorkspaceID); err == nil || !strings.Contains(err.Error(), "无效") {
+		t.Errorf("无会话切换应返回无效的操作, got %v", err)
+	}
+}
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupSessionFixtures(t, tx)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("OldPassword1"), bcrypt.MinCost)
+	f.user.PasswordHash = string(hash)
+	if err := store.NewUserStore(tx).UpdateUser(f.ctx, f.user); err != nil {
+		t.Fatalf("设置密码失败: %v", err)
+	}
+	current, _, _ := f.login(t, "Chrome")
+	other, _, otherRefreshToken := f.login(t, "Firefox")
+	ctx := f.userCtx(current.SessionID)
+
+	oauthStore := store.NewOAuthStore(tx)
+	app := &model.OAuthApplication{
+		WorkspaceID:  f.workspaceID,
+		Name:         "Tool",
+		ClientID:     "client-" + uuid.New().String(),
+		RedirectURIs: []string{"https://tool.example.com/callback"},
+		Scopes:       []string{model.APIKeyScopeRead},
+	}
+	if err := oauthStore.CreateApplication(f.ctx, app); err != nil {
+		t.Fatalf("创建 OAuth 应用失败: %v", err)
+	}
+	grant := &model.OAuthGrant{
+		ApplicationID:    app.ID,
+		UserID:           f.user.ID,
+		Scopes:           []string{model.APIKeyScopeRead},
+		RefreshTokenHash: model.HashAPIKey(uuid.New().String()),
+		RefreshExpiresAt: time.Now().Add(time.Hour),
+	}
+	if err := oauthStore.CreateGrant(f.ctx, grant); err != nil {
+		t.Fatalf("创建 OAuth 授权失败: %v", err)
+	}
+
+	if _, _, err := f.authService.ChangePassword(ctx, "WrongPassword1", "NewPassword1"); !errors.Is(err, ErrWrongPassword) {
+		t.Errorf("当前密码错误应返回 ErrWrongPassword, got %v", err)
+	}
+	if _, _, err := f.authService.ChangePassword(ctx, "OldPassword1", "weak"); err == nil {
+		t.Error("新密码强度不足应返回错误")
+	}
+
+	accessToken, refreshToken, err := f.authService.ChangePassword(ctx, "OldPassword1", "NewPassword1")
+	if err != nil {
+		t.Fatalf("ChangePassword() error = %v", err)
+	}
+	if accessToken == "" || refreshToken == "" {
+		t.Error("修改密码后应返回新令牌")
+	}
+
+	// 修改密码后所有旧会话失效
+	for _, sessionID := range []string{current.SessionID, other.SessionID} {
+		if active, _ := store.IsSessionActive(context.Background(), tx, uuid.MustParse(sessionID)); active {
+			t.Errorf("会话 %s 应被吊销", sessionID)
+		}
+	}
+	if _, _, err := f.authService.RefreshToken(context.Background(), otherRefreshToken); err == nil {
+		t.Error("修改密码后旧刷新令牌应失效")
+	}
+	// 第三方应用的授权同样吊销
+	if active, _ := store.IsOAuthGrantActive(context.Background(), tx, grant.ID); active {
+		t.Error("修改密码后 OAuth 授权应被吊销")
+	}
+	if _, _, err := f.authService.RefreshToken(context.Background(), refreshToken); err != nil {
+		t.Errorf("新会话的刷新令牌应有效: %v", err)
+	}
+}
+
+func TestAuthService_RefreshToken_LegacyToken(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupSessionFixtures(t, tx)
+
+	// 引入会话之前签发的刷新令牌没有 sid，无法随修改密码失效，不再接受
+	legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":  f.user.ID.String(),
+		"type": "refresh",
+		"exp":  time.Now().Add(time.Hour).Unix(),
+		"iat":  time.Now().Unix(),
+		"jti":  uuid.New().String(),
+	})
+	refreshToken, err := legacy.SignedString([]byte(f.cfg.JWTSecret))
+	if err != nil {
+		t.Fatalf("签发令牌失败: %v", err)
+	}
+	if _, _, err := f.authService.RefreshToken(context.Background(), refreshToken); !errors.Is(err, ErrLegacyRefreshToken) {
+		t.Errorf("旧版刷新令牌应返回 ErrLegacyRefreshToken, got %v", err)
+	}
+	if err := f.authService.Logout(context.Background(), refreshToken); err != nil {
+		t.Errorf("旧版刷新令牌登出不应报错: %v", err)
+	}
+}
@@ -13,10 +13,11 @@ import (
 
 // TeamService 定义团队服务接口
 type TeamService interface {
-	// CreateTeam 创建团队
-	CreateTeam(ctx context.Context, name, key, description string) (*model.Team, error)
-	// ListTeams 获取团队列表
-	ListTeams(ctx context.Context, workspaceID string, page, pageSize int) ([]model.Team, int64, error)
+	// CreateTeam 在当前所在的工作区创建团队，私有团队只对成员可见
+	CreateTeam(ctx context.Context, name, key, description string, isPrivate bool) (*model.Team, error)
+	// ListTeams 获取团队列表，workspaceID 为空时使用当前所在的工作区
+	// 不包括用户不在其中的私有团队；管理员可以通过 includePrivate 列出所有私有团队以便加入
+	ListTeams(ctx context.Context, workspaceID string, includePrivate bool, page, pageSize int) ([]model.Team, int64, error)
 	// GetTeam 获取团队信息
 	GetTeam(ctx context.Context, teamID string) (*model.Team, error)
 	// UpdateTeam 更新团队信息
@@ -31,6 +32,7 @@ type teamService struct {
 	teamMemberStore store.TeamMemberStore
 	userStore       store.UserStore
 	workflowService WorkflowService
+	auditService    AuditService
 }
 
 // NewTeamService 创建团队服务实例
@@ -43,8 +45,19 @@ func NewTeamService(teamStore store.TeamStore, teamMemberStore store.TeamMemberS
 	}
 }
 
+// NewTeamServiceWithAudit 创建记录审计日志的团队服务实例
+func NewTeamServiceWithAudit(teamStore store.TeamStore, teamMemberStore store.TeamMemberStore, userStore store.UserStore, workflowService WorkflowService, auditService AuditService) TeamService {
+	return &teamService{
+		teamStore:       teamStore,
+		teamMemberStore: teamMemberStore,
+		userStore:       userStore,
+		workflowService: workflowService,
+		auditService:    auditService,
+	}
+}
+
 // CreateTeam 创建团队
-func (s *teamService) CreateTeam(ctx context.Context, name, key, description string) (*model.Team, error) {
+func (s *teamService) CreateTeam(ctx context.Context, name, key, description string, isPrivate bool) (*model.Team, error) {
 	// 获取当前用户信息
 	userID, ok := ctx.Value("user_id").(uuid.UUID)
 	if !ok {
@@ -52,22 +65,26 @@ func (s *teamService) CreateTeam(ctx context.Context, name, key, description str
 	}
 
 	userRole, _ := ctx.Value("user_role").(model.Role)
-	workspaceID, ok := ctx.Value("workspace_id").(uuid.UUID)
-	if !ok {
-		return nil, fmt.Errorf("缺少工作区信息")
-	}
 
 	// 只有 Admin 可以创建团队
 	if userRole != model.RoleAdmin && userRole != model.RoleGlobalAdmin {
 		return nil, fmt.Errorf("无权限创建团队")
 	}
 
+	// 团队创建在当前所在的工作区
+	user, err := s.userStore.GetUserByID(ctx, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("用户不存在")
+	}
+	workspaceID := activeWorkspaceID(ctx, user)
+
 	// 创建团队
 	team := &model.Team{
 		WorkspaceID: workspaceID,
 		Name:        name,
 		Key:         key,
 		Description: description,
+		IsPrivate:   isPrivate,
 	}
 
 	if err := s.teamStore.Create(ctx, team); err != nil {
@@ -117,12 +134,40 @@ func (s *teamService) CreateTeam(ctx context.Context, name, key, description str
 		}
 	}
 
+	recordAudit(ctx, s.auditService, &AuditEntry{
+		WorkspaceID: team.WorkspaceID,
+		Action:      model.AuditTeamCreated,
+		TargetType:  model.AuditTargetTeam,
+		TargetID:    team.ID.String(),
+		After:       teamAuditValues(team),
+	})
+
 	return team, nil
 }
 
-// ListTeams 获取团队列表
-func (s *teamService) ListTeams(ctx context.Context, workspaceID string, page, pageSize int) ([]model.Team, int64, error) {
-	return s.teamStore.List(ctx, workspaceID, page, pageSize)
+// ListTeams 获取团队列表，workspaceID 为空时使用当前所在的工作区
+func (s *teamService) ListTeams(ctx context.Context, workspaceID string, includePrivate bool, page, pageSize int) ([]model.Team, int64, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, 0, fmt.Errorf("未认证")
+	}
+	if workspaceID == "" {
+		user, err := s.userStore.GetUserByID(ctx, userID.String())
+		if err != nil {
+			return nil, 0, fmt.Errorf("用户不存在")
+		}
+		workspaceID = activeWorkspaceID(ctx, user).String()
+	}
+	userRole, _ := ctx.Value("user_role").(model.Role)
+	switch {
+	case userRole == model.RoleGuest:
+		// 访客只能看到自己所在的团队
+		return s.teamStore.ListByMember(ctx, workspaceID, userID.String(), page, pageSize)
+	case includePrivate && (userRole == model.RoleAdmin || userRole == model.RoleGlobalAdmin):
+		return s.teamStore.List(ctx, workspaceID, page, pageSize)
+	default:
+		return s.teamStore.ListVisible(ctx, workspaceID, userID.String(), page, pageSize)
+	}
 }
 
 // GetTeam 获取团队信息
@@ -146,9 +191,9 @@ func (s *teamService) GetTeam(ctx context.Context, teamID string) (*model.Team,
 		return team, nil
 	}
 
-	// 检查用户是否是团队成员
+	// 公开团队对工作区成员可见；私有团队和访客只能访问自己所在的团队
 	role, _ := s.teamMemberStore.GetRole(ctx, teamID, userID.String())
-	if role == "" {
+	if role == "" && (team.IsPrivate || userRole == model.RoleGuest) {
 		return nil, fmt.Errorf("无权限访问此团队")
 	}
 
@@ -189,6 +234,9 @@ func (s *teamService) UpdateTeam(ctx context.Context, teamID string, updates map
 	if description, ok := updates["description"].(string); ok {
 		team.Description = description
 	}
+	if isPrivate, ok := updates["is_private"].(bool); ok {
+		team.IsPrivate = isPrivate
+	}
 
 	// 保存更新
 	if err := s.teamStore.Update(ctx, team); err != nil {
@@ -209,7 +257,7 @@ func (s *teamService) DeleteTeam(ctx context.Context, teamID string) error {
 	userRole, _ := ctx.Value("user_role").(model.Role)
 
 	// 获取团队（验证存在）
-	_, err := s.teamStore.GetByID(ctx, teamID)
+	team, err := s.teamStore.GetByID(ctx, teamID)
 	if err != nil {
 		return fmt.Errorf("团队不存在")
 	}
@@ -233,5 +281,25 @@ func (s *teamService) DeleteTeam(ctx context.Context, teamID string) error {
 	}
 
 	// 删除团队
-	return s.teamStore.SoftDelete(ctx, teamID)
+	if err := s.teamStore.SoftDelete(ctx, teamID); err != nil {
+		return err
+	}
+
+	recordAudit(ctx, s.auditService, &AuditEntry{
+		WorkspaceID: team.WorkspaceID,
+		Action:      model.AuditTeamDeleted,
+		TargetType:  model.AuditTargetTeam,
+		TargetID:    team.ID.String(),
+		Before:      teamAuditValues(team),
+	})
+	return nil
+}
+
+// teamAuditValues 审计日志中记录的团队字段
+func teamAuditValues(team *model.Team) map[string]interface{} {
+	return map[string]interface{}{
+		"name":       team.Name,
+		"key":        team.Key,
+		"is_private": team.IsPrivate,
+	}
 }
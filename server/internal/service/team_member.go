@@ -21,6 +21,8 @@ type TeamMemberService interface {
 	RemoveMember(ctx context.Context, teamID, userID string) error
 	// UpdateRole 更新成员角色
 	UpdateRole(ctx context.Context, teamID, userID string, role model.Role) error
+	// JoinTeam 管理员主动加入团队（用于访问私有团队），加入方式记录为 admin_join
+	JoinTeam(ctx context.Context, teamID string) (*model.TeamMember, error)
 }
 
 // teamMemberService 实现 TeamMemberService 接口
@@ -28,6 +30,7 @@ type teamMemberService struct {
 	teamMemberStore store.TeamMemberStore
 	userStore       store.UserStore
 	teamStore       store.TeamStore
+	auditService    AuditService
 }
 
 // NewTeamMemberService 创建团队成员服务实例
@@ -39,6 +42,16 @@ func NewTeamMemberService(teamMemberStore store.TeamMemberStore, userStore store
 	}
 }
 
+// NewTeamMemberServiceWithAudit 创建记录审计日志的团队成员服务实例
+func NewTeamMemberServiceWithAudit(teamMemberStore store.TeamMemberStore, userStore store.UserStore, teamStore store.TeamStore, auditService AuditService) TeamMemberService {
+	return &teamMemberService{
+		teamMemberStore: teamMemberStore,
+		userStore:       userStore,
+		teamStore:       teamStore,
+		auditService:    auditService,
+	}
+}
+
 // ListMembers 获取团队成员列表
 func (s *teamMemberService) ListMembers(ctx context.Context, teamID string) ([]model.TeamMember, error) {
 	// 获取当前用户信息
@@ -98,7 +111,12 @@ func (s *teamMemberService) AddMember(ctx context.Context, teamID, userID string
 		JoinedAt: time.Now(),
 	}
 
-	return s.teamMemberStore.Add(ctx, member)
+	if err := s.teamMemberStore.Add(ctx, member); err != nil {
+		return err
+	}
+
+	s.recordAudit(ctx, model.AuditTeamMemberAdded, teamUUID, targetUserID.String(), nil, map[string]interface{}{"role": role})
+	return nil
 }
 
 // RemoveMember 移除团队成员
@@ -119,7 +137,15 @@ func (s *teamMemberService) RemoveMember(ctx context.Context, teamID, userID str
 		}
 	}
 
-	return s.teamMemberStore.Remove(ctx, teamID, userID)
+	oldRole, _ := s.teamMemberStore.GetRole(ctx, teamID, userID)
+	if err := s.teamMemberStore.Remove(ctx, teamID, userID); err != nil {
+		return err
+	}
+
+	if teamUUID, err := uuid.Parse(teamID); err == nil {
+		s.recordAudit(ctx, model.AuditTeamMemberRemoved, teamUUID, userID, map[string]interface{}{"role": oldRole}, nil)
+	}
+	return nil
 }
 
 // UpdateRole 更新成员角色
@@ -140,5 +166,98 @@ func (s *teamMemberService) UpdateRole(ctx context.Context, teamID, userID strin
 		}
 	}
 
-	return s.teamMemberStore.UpdateRole(ctx, teamID, userID, role)
+	oldRole, _ := s.teamMemberStore.GetRole(ctx, teamID, userID)
+	if err := s.teamMemberStore.UpdateRole(ctx, teamID, userID, role); err != nil {
+		return err
+	}
+
+	if teamUUID, err := uuid.Parse(teamID); err == nil {
+		s.recordAudit(ctx, model.AuditTeamMemberRoleChanged, teamUUID, userID, map[string]interface{}{"role": oldRole}, map[string]interface{}{"role": role})
+	}
+	return nil
+}
+
+// JoinTeam 管理员主动加入团队
+func (s *teamMemberService) JoinTeam(ctx context.Context, teamID string) (*model.TeamMember, error) {
+	currentUserID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+
+	currentUserRole, _ := ctx.Value("user_role").(model.Role)
+	if currentUserRole != model.RoleAdmin && currentUserRole != model.RoleGlobalAdmin {
+		return nil, fmt.Errorf("无权限加入团队: 只有管理员可以主动加入团队")
+	}
+
+	team, err := s.teamStore.GetByID(ctx, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("团队不存在")
+	}
+
+	// 令牌中的管理员角色只在当前所在的工作区有效
+	if currentUserRole != model.RoleGlobalAdmin {
+		user, err := s.userStore.GetUserByID(ctx, currentUserID.String())
+		if err != nil {
+			return nil, fmt.Errorf("用户不存在")
+		}
+		if activeWorkspaceID(ctx, user) != team.WorkspaceID {
+			return nil, fmt.Errorf("团队不存在")
+		}
+	}
+
+	if role, _ := s.teamMemberStore.GetRole(ctx, teamID, currentUserID.String()); role != "" {
+		return nil, fmt.Errorf("团队成员已存在")
+	}
+
+	member := &model.TeamMember{
+		TeamID:    team.ID,
+		UserID:    currentUserID,
+		Role:      model.RoleMember,
+		JoinedAt:  time.Now(),
+		JoinedVia: model.TeamJoinAdminJoin,
+	}
+	if err := s.teamMemberStore.Add(ctx, member); err != nil {
+		return nil, fmt.Errorf("加入团队失败: %w", err)
+	}
+
+	recordAudit(ctx, s.auditService, &AuditEntry{
+		WorkspaceID: team.WorkspaceID,
+		Action:      model.AuditTeamMemberAdded,
+		TargetType:  model.AuditTargetTeamMember,
+		TargetID:    currentUserID.String(),
+		After:       map[string]interface{}{"team_id": team.ID, "role": member.Role, "joined_via": member.JoinedVia},
+	})
+	return member, nil
+}
+
+// recordAudit 记录团队成员变更的审计日志，team_id 写入变更内容以便按团队追溯
+func (s *teamMemberService) recordAudit(ctx context.Context, action model.AuditAction, teamID uuid.UUID, userID string, before, after map[string]interface{}) {
+	if s.auditService == nil {
+		return
+	}
+
+	var workspaceID uuid.UUID
+	if team, err := s.teamStore.GetByID(ctx, teamID.String()); err == nil {
+		workspaceID = team.WorkspaceID
+	}
+	for _, values := range []map[string]interface{}{before, after} {
+		if values != nil {
+			values["team_id"] = teamID
+		}
+	}
+
+	entry := &AuditEntry{
+		WorkspaceID: workspaceID,
+		Action:      action,
+		TargetType:  model.AuditTargetTeamMember,
+		TargetID:    userID,
+	}
+	// 避免把 nil map 作为非空接口值写入
+	if before != nil {
+		entry.Before = before
+	}
+	if after != nil {
+		entry.After = after
+	}
+	s.auditService.Record(ctx, entry)
 }
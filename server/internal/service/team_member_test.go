@@ -447,3 +447,120 @@ func TestTeamMemberService_UpdateRole(t *testing.T) {
 		})
 	}
 }
+
+func TestTeamMemberService_JoinTeam(t *testing.T) {
+	if testTeamServiceDB == nil {
+		t.Skip("数据库连接不可用，跳过集成测试")
+	}
+
+	tx := testTeamServiceDB.Begin()
+	defer tx.Rollback()
+
+	teamMemberStore := store.NewTeamMemberStore(tx)
+	userStore := store.NewUserStore(tx)
+	teamStore := store.NewTeamStore(tx)
+	svc := NewTeamMemberService(teamMemberStore, userStore, teamStore)
+
+	prefix := uuid.New().String()[:8]
+
+	// 创建测试工作区
+	workspace := &model.Workspace{
+		Name: "JoinTeam Test " + prefix,
+		Slug: "jointeam-test-" + prefix,
+	}
+	tx.Create(workspace)
+
+	// 创建私有团队
+	team := &model.Team{
+		WorkspaceID: workspace.ID,
+		Name:        "Private " + prefix,
+		Key:         "JT" + "ABC",
+		IsPrivate:   true,
+	}
+	tx.Create(team)
+
+	// 创建用户
+	admin := &model.User{
+		WorkspaceID:  workspace.ID,
+		Email:        prefix + "_admin@example.com",
+		Username:     prefix + "_admin",
+		Name:         "Admin",
+		PasswordHash: "hash",
+		Role:         model.RoleAdmin,
+	}
+	member := &model.User{
+		WorkspaceID:  workspace.ID,
+		Email:        prefix + "_member@example.com",
+		Username:     prefix + "_member",
+		Name:         "Member",
+		PasswordHash: "hash",
+		Role:         model.RoleMember,
+	}
+	tx.Create(admin)
+	tx.Create(member)
+
+	tests := []struct {
+		name     string
+		userID   uuid.UUID
+		userRole model.Role
+		teamID   string
+		wantErr  bool
+		errMsg   string
+	}{
+		{
+			name:     "普通成员不能主动加入",
+			userID:   member.ID,
+			userRole: model.RoleMember,
+			teamID:   team.ID.String(),
+			wantErr:  true,
+			errMsg:   "无权限",
+		},
+		{
+			name:     "管理员加入私有团队",
+			userID:   admin.ID,
+			userRole: model.RoleAdmin,
+			teamID:   team.ID.String(),
+			wantErr:  false,
+		},
+		{
+			name:     "重复加入",
+			userID:   admin.ID,
+			userRole: model.RoleAdmin,
+			teamID:   team.ID.String(),
+			wantErr:  true,
+			errMsg:   "已存在",
+		},
+		{
+			name:     "团队不存在",
+			userID:   admin.ID,
+			userRole: model.RoleAdmin,
+			teamID:   uuid.New().String(),
+			wantErr:  true,
+			errMsg:   "不存在",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), "user_id", tt.userID)
+			ctx = context.WithValue(ctx, "user_role", tt.userRole)
+
+			joined, err := svc.JoinTeam(ctx, tt.teamID)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("JoinTeam() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.wantErr && tt.errMsg != "" {
+				assert.Contains(t, err.Error(), tt.errMsg)
+			}
+
+			if !tt.wantErr {
+				// 加入方式记录为管理员主动加入
+				assert.Equal(t, model.TeamJoinAdminJoin, joined.JoinedVia)
+				assert.Equal(t, model.RoleMember, joined.Role)
+			}
+		})
+	}
+}
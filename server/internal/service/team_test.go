@@ -9,6 +9,7 @@ import (
 	"github.com/liwei0526vip/mylinear/internal/model"
 	"github.com/liwei0526vip/mylinear/internal/store"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
@@ -110,7 +111,7 @@ func TestTeamService_CreateTeam(t *testing.T) {
 			ctx = context.WithValue(ctx, "user_role", tt.userRole)
 			ctx = context.WithValue(ctx, "workspace_id", workspace.ID)
 
-			team, err := svc.CreateTeam(ctx, tt.teamName, tt.teamKey, "")
+			team, err := svc.CreateTeam(ctx, tt.teamName, tt.teamKey, "", false)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("CreateTeam() error = %v, wantErr %v", err, tt.wantErr)
@@ -176,6 +177,7 @@ func TestTeamService_ListTeams(t *testing.T) {
 	tx.Create(admin)
 
 	// 创建多个团队
+	var teams []*model.Team
 	for i := 0; i < 5; i++ {
 		team := &model.Team{
 			WorkspaceID: workspace.ID,
@@ -183,8 +185,21 @@ func TestTeamService_ListTeams(t *testing.T) {
 			Key:         "TL" + string(rune('A'+i)) + "ABC",
 		}
 		tx.Create(team)
+		teams = append(teams, team)
 	}
 
+	// 创建访客，只加入其中一个团队
+	guest := &model.User{
+		WorkspaceID:  workspace.ID,
+		Email:        prefix + "_guest@example.com",
+		Username:     prefix + "_guest",
+		Name:         "Guest",
+		PasswordHash: "hash",
+		Role:         model.RoleGuest,
+	}
+	tx.Create(guest)
+	tx.Create(&model.TeamMember{TeamID: teams[2].ID, UserID: guest.ID, Role: model.RoleMember})
+
 	tests := []struct {
 		name        string
 		userID      uuid.UUID
@@ -232,7 +247,7 @@ func TestTeamService_ListTeams(t *testing.T) {
 			ctx := context.WithValue(context.Background(), "user_id", tt.userID)
 			ctx = context.WithValue(ctx, "workspace_id", tt.workspaceID)
 
-			teams, total, err := svc.ListTeams(ctx, tt.workspaceID.String(), tt.page, tt.pageSize)
+			teams, total, err := svc.ListTeams(ctx, tt.workspaceID.String(), false, tt.page, tt.pageSize)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ListTeams() error = %v, wantErr %v", err, tt.wantErr)
@@ -243,6 +258,17 @@ func TestTeamService_ListTeams(t *testing.T) {
 			assert.Len(t, teams, tt.wantCount)
 		})
 	}
+
+	t.Run("访客只能看到所在的团队", func(t *testing.T) {
+		ctx := context.WithValue(context.Background(), "user_id", guest.ID)
+		ctx = context.WithValue(ctx, "user_role", model.RoleGuest)
+
+		result, total, err := svc.ListTeams(ctx, "", false, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, result, 1)
+		assert.Equal(t, teams[2].ID, result[0].ID)
+	})
 }
 
 // =============================================================================
@@ -280,6 +306,13 @@ func TestTeamService_GetTeam(t *testing.T) {
 		Key:         "GT" + "ABC",
 	}
 	tx.Create(team)
+	privateTeam := &model.Team{
+		WorkspaceID: workspace.ID,
+		Name:        "Private Team " + prefix,
+		Key:         "GTP",
+		IsPrivate:   true,
+	}
+	tx.Create(privateTeam)
 
 	// 创建用户
 	member := &model.User{
@@ -322,9 +355,24 @@ func TestTeamService_GetTeam(t *testing.T) {
 			wantErr:  false,
 		},
 		{
-			name:     "非团队成员拒绝",
+			name:     "非团队成员访问公开团队",
+			userID:   nonMember.ID,
+			userRole: model.RoleMember,
+			teamID:   team.ID.String(),
+			wantErr:  false,
+		},
+		{
+			name:     "非团队成员拒绝访问私有团队",
 			userID:   nonMember.ID,
 			userRole: model.RoleMember,
+			teamID:   privateTeam.ID.String(),
+			wantErr:  true,
+			errMsg:   "无权限",
+		},
+		{
+			name:     "访客非团队成员拒绝",
+			userID:   nonMember.ID,
+			userRole: model.RoleGuest,
 			teamID:   team.ID.String(),
 			wantErr:  true,
 			errMsg:   "无权限",
@@ -0,0 +1,483 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"github.com/liwei0526vip/mylinear/internal/totp"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	// recoveryCodeCount 每次生成的恢复码数量
+	recoveryCodeCount = 10
+	// totpSkew 允许的时钟偏差（前后各一个时间步）
+	totpSkew = 1
+	// twoFactorMaxAttempts 每个挑战令牌允许的验证失败次数
+	twoFactorMaxAttempts = 5
+)
+
+// ErrInvalidTwoFactorCode 验证码或恢复码错误
+var ErrInvalidTwoFactorCode = errors.New("验证码错误")
+
+// TwoFactorRequiredError 密码验证通过但需要两步验证，使用 ChallengeToken 完成登录
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+	// EnrollmentRequired 工作区要求两步验证但用户尚未启用，需要先绑定验证器
+	EnrollmentRequired bool
+}
+
+// Error 实现 error 接口
+func (e *TwoFactorRequiredError) Error() string {
+	if e.EnrollmentRequired {
+		return "工作区要求启用两步验证"
+	}
+	return "需要两步验证"
+}
+
+// TwoFactorStatus 当前用户的两步验证状态
+type TwoFactorStatus struct {
+	Enabled bool `json:"enabled"`
+	// Required 所在工作区要求启用两步验证
+	Required               bool  `json:"required"`
+	RecoveryCodesRemaining int64 `json:"recovery_codes_remaining"`
+}
+
+// TOTPEnrollment 待确认的验证器密钥
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// TwoFactorLoginResult 两步验证登录结果；登录时完成绑定的用户会同时返回恢复码
+type TwoFactorLoginResult struct {
+	User          *model.User
+	AccessToken   string
+	RefreshToken  string
+	RecoveryCodes []string
+}
+
+// TwoFactorService 定义两步验证服务接口
+type TwoFactorService interface {
+	// Status 获取当前用户的两步验证状态
+	Status(ctx context.Context) (*TwoFactorStatus, error)
+	// BeginEnrollment 为当前用户生成验证器密钥，确认前不生效
+	BeginEnrollment(ctx context.Context) (*TOTPEnrollment, error)
+	// ConfirmEnrollment 使用验证码确认绑定并启用两步验证，返回恢复码
+	ConfirmEnrollment(ctx context.Context, code string) ([]string, error)
+	// Disable 使用验证码或恢复码关闭两步验证
+	Disable(ctx context.Context, code string) error
+	// RegenerateRecoveryCodes 使用验证码或恢复码重新生成恢复码，旧恢复码失效
+	RegenerateRecoveryCodes(ctx context.Context, code string) ([]string, error)
+	// BeginChallengeEnrollment 登录中绑定验证器（工作区要求两步验证但用户未启用时）
+	BeginChallengeEnrollment(ctx context.Context, challengeToken string) (*TOTPEnrollment, error)
+	// CompleteLogin 使用挑战令牌和验证码（或恢复码）完成登录
+	CompleteLogin(ctx context.Context, challengeToken, code string) (*TwoFactorLoginResult, error)
+}
+
+// twoFactorService 实现 TwoFactorService 接口
+type twoFactorService struct {
+	userStore      store.UserStore
+	workspaceStore store.WorkspaceStore
+	codeStore      store.RecoveryCodeStore
+	jwtService     JWTService
+	sessionService SessionService
+	redis          *redis.Client
+	cfg            *config.Config
+	auditService   AuditService
+	lockout        loginLockout
+}
+
+// NewTwoFactorService 创建两步验证服务实例；redis 为 nil 时不限制挑战令牌的重试次数，也不锁定账号
+func NewTwoFactorService(userStore store.UserStore, workspaceStore store.WorkspaceStore, codeStore store.RecoveryCodeStore, jwtService JWTService, sessionService SessionService, redis *redis.Client, cfg *config.Config) TwoFactorService {
+	return &twoFactorService{
+		userStore:      userStore,
+		workspaceStore: workspaceStore,
+		codeStore:      codeStore,
+		jwtService:     jwtService,
+		sessionService: sessionService,
+		redis:          redis,
+		cfg:            cfg,
+		lockout:        loginLockout{redis: redis, cfg: cfg},
+	}
+}
+
+// NewTwoFactorServiceWithAudit 创建记录登录审计日志的两步验证服务实例
+func NewTwoFactorServiceWithAudit(userStore store.UserStore, workspaceStore store.WorkspaceStore, codeStore store.RecoveryCodeStore, jwtService JWTService, sessionService SessionService, redis *redis.Client, cfg *config.Config, auditService AuditService) TwoFactorService {
+	s := NewTwoFactorService(userStore, workspaceStore, codeStore, jwtService, sessionService, redis, cfg).(*twoFactorService)
+	s.auditService = auditService
+	return s
+}
+
+// Status 获取当前用户的两步验证状态
+func (s *twoFactorService) Status(ctx context.Context) (*TwoFactorStatus, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := workspaceAuthSettings(ctx, s.workspaceStore, user.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &TwoFactorStatus{Enabled: user.TwoFactorEnabled(), Required: settings.TwoFactorRequired}
+	if status.Enabled {
+		status.RecoveryCodesRemaining, err = s.codeStore.CountUnused(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// BeginEnrollment 为当前用户生成验证器密钥，确认前不生效
+func (s *twoFactorService) BeginEnrollment(ctx context.Context) (*TOTPEnrollment, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.beginEnrollment(ctx, user)
+}
+
+// ConfirmEnrollment 使用验证码确认绑定并启用两步验证，返回恢复码
+func (s *twoFactorService) ConfirmEnrollment(ctx context.Context, code string) ([]string, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.confirmEnrollment(ctx, user, code)
+}
+
+// Disable 使用验证码或恢复码关闭两步验证
+func (s *twoFactorService) Disable(ctx context.Context, code string) error {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled() {
+		return fmt.Errorf("无效的请求: 未启用两步验证")
+	}
+	settings, err := workspaceAuthSettings(ctx, s.workspaceStore, user.WorkspaceID)
+	if err != nil {
+		return err
+	}
+	if settings.TwoFactorRequired {
+		return fmt.Errorf("无权限: 工作区要求启用两步验证")
+	}
+
+	ok, err := s.verifyCode(ctx, user, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	user.TOTPSecret = ""
+	user.TOTPEnabledAt = nil
+	user.TOTPLastStep = 0
+	if err := s.userStore.UpdateUser(ctx, user); err != nil {
+		return fmt.Errorf("关闭两步验证失败: %w", err)
+	}
+	return s.codeStore.DeleteByUser(ctx, user.ID)
+}
+
+// RegenerateRecoveryCodes 使用验证码或恢复码重新生成恢复码，旧恢复码失效
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, code string) ([]string, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TwoFactorEnabled() {
+		return nil, fmt.Errorf("无效的请求: 未启用两步验证")
+	}
+
+	ok, err := s.verifyCode(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+	return s.generateRecoveryCodes(ctx, user.ID)
+}
+
+// BeginChallengeEnrollment 登录中绑定验证器（工作区要求两步验证但用户未启用时）
+func (s *twoFactorService) BeginChallengeEnrollment(ctx context.Context, challengeToken string) (*TOTPEnrollment, error) {
+	user, _, err := s.challengeUser(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	return s.beginEnrollment(ctx, user)
+}
+
+// CompleteLogin 使用挑战令牌和验证码（或恢复码）完成登录
+//
+// 用户尚未启用两步验证时（工作区要求启用），验证码用于确认登录中绑定的验证器，
+// 成功后启用两步验证并返回恢复码。每个挑战令牌只能成功使用一次，且最多允许 5 次错误；
+// 错误的验证码同时按邮箱计入连续登录失败次数，避免通过反复登录获取新的挑战令牌绕过限制。
+func (s *twoFactorService) CompleteLogin(ctx context.Context, challengeToken, code string) (*TwoFactorLoginResult, error) {
+	user, jti, err := s.challengeUser(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.lockout.check(ctx, user.Email); err != nil {
+		recordLogin(ctx, s.auditService, auditLoginTwoFactor, "", user, err)
+		return nil, err
+	}
+
+	result := &TwoFactorLoginResult{User: user}
+	if user.TwoFactorEnabled() {
+		ok, err := s.verifyCode(ctx, user, code)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			s.recordFailure(ctx, jti)
+			s.lockout.recordFailure(ctx, user.Email)
+			recordLogin(ctx, s.auditService, auditLoginTwoFactor, "", user, ErrInvalidTwoFactorCode)
+			return nil, ErrInvalidTwoFactorCode
+		}
+	} else {
+		result.RecoveryCodes, err = s.confirmEnrollment(ctx, user, code)
+		if err != nil {
+			if errors.Is(err, ErrInvalidTwoFactorCode) {
+				s.recordFailure(ctx, jti)
+				s.lockout.recordFailure(ctx, user.Email)
+				recordLogin(ctx, s.auditService, auditLoginTwoFactor, "", user, err)
+			}
+			return nil, err
+		}
+	}
+
+	// 挑战令牌只能使用一次
+	if s.redis != nil {
+		if err := s.redis.Set(ctx, "token_blacklist:"+jti, "1", twoFactorTokenExpiry).Err(); err != nil {
+			return nil, fmt.Errorf("令牌失效处理失败: %w", err)
+		}
+	}
+
+	s.lockout.reset(ctx, user.Email)
+
+	result.AccessToken, result.RefreshToken, err = s.sessionService.Create(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	recordLogin(ctx, s.auditService, auditLoginTwoFactor, "", user, nil)
+	return result, nil
+}
+
+// currentUser 获取当前登录用户
+func (s *twoFactorService) currentUser(ctx context.Context) (*model.User, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+	user, err := s.userStore.GetUserByID(ctx, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("用户不存在")
+	}
+	return user, nil
+}
+
+// challengeUser 校验挑战令牌，返回对应的用户和令牌 ID
+func (s *twoFactorService) challengeUser(ctx context.Context, challengeToken string) (*model.User, string, error) {
+	claims, err := s.jwtService.ValidateToken(challengeToken)
+	if err != nil {
+		return nil, "", fmt.Errorf("未认证: 挑战令牌无效或已过期")
+	}
+	if claims.Type != TokenTypeTwoFactor {
+		return nil, "", fmt.Errorf("未认证: 无效的令牌类型")
+	}
+
+	if s.redis != nil {
+		used, err := s.redis.Exists(ctx, "token_blacklist:"+claims.JTI).Result()
+		if err != nil {
+			return nil, "", fmt.Errorf("检查令牌状态失败: %w", err)
+		}
+		if used > 0 {
+			return nil, "", fmt.Errorf("未认证: 令牌已失效")
+		}
+		attempts, err := s.redis.Get(ctx, "two_factor_attempts:"+claims.JTI).Int()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return nil, "", fmt.Errorf("检查令牌状态失败: %w", err)
+		}
+		if attempts >= twoFactorMaxAttempts {
+			return nil, "", fmt.Errorf("未认证: 验证失败次数过多，请重新登录")
+		}
+	}
+
+	user, err := s.userStore.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, "", fmt.Errorf("未认证: 用户不存在")
+	}
+	if !user.IsActive() {
+		return nil, "", ErrUserDeactivated
+	}
+	return user, claims.JTI, nil
+}
+
+// recordFailure 记录挑战令牌的验证失败次数
+func (s *twoFactorService) recordFailure(ctx context.Context, jti string) {
+	if s.redis == nil {
+		return
+	}
+	key := "two_factor_attempts:" + jti
+	pipe := s.redis.TxPipeline()
+	pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, twoFactorTokenExpiry)
+	_, _ = pipe.Exec(ctx)
+}
+
+// beginEnrollment 生成新的验证器密钥，已启用时不能重新生成
+func (s *twoFactorService) beginEnrollment(ctx context.Context, user *model.User) (*TOTPEnrollment, error) {
+	if user.TwoFactorEnabled() {
+		return nil, fmt.Errorf("两步验证已存在，请先关闭")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := totp.Seal(s.encryptionKey(), secret)
+	if err != nil {
+		return nil, err
+	}
+	user.TOTPSecret = sealed
+	user.TOTPLastStep = 0
+	if err := s.userStore.UpdateUser(ctx, user); err != nil {
+		return nil, fmt.Errorf("保存验证器密钥失败: %w", err)
+	}
+
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(s.cfg.TOTPIssuer, user.Email, secret),
+	}, nil
+}
+
+// confirmEnrollment 使用验证码确认绑定，启用两步验证并生成恢复码
+func (s *twoFactorService) confirmEnrollment(ctx context.Context, user *model.User, code string) ([]string, error) {
+	if user.TwoFactorEnabled() {
+		return nil, fmt.Errorf("两步验证已存在，请先关闭")
+	}
+	if user.TOTPSecret == "" {
+		return nil, fmt.Errorf("无效的请求: 请先生成验证器密钥")
+	}
+
+	ok, err := s.verifyTOTP(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	now := time.Now()
+	user.TOTPEnabledAt = &now
+	if err := s.userStore.UpdateUser(ctx, user); err != nil {
+		return nil, fmt.Errorf("启用两步验证失败: %w", err)
+	}
+	return s.generateRecoveryCodes(ctx, user.ID)
+}
+
+// verifyCode 校验验证码，非 6 位数字时按恢复码校验
+func (s *twoFactorService) verifyCode(ctx context.Context, user *model.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits && strings.Trim(code, "0123456789") == "" {
+		return s.verifyTOTP(ctx, user, code)
+	}
+	if code == "" {
+		return false, nil
+	}
+	return s.codeStore.Use(ctx, user.ID, model.HashRecoveryCode(code))
+}
+
+// verifyTOTP 校验验证码并记录使用的时间步，同一验证码不能重复使用
+func (s *twoFactorService) verifyTOTP(ctx context.Context, user *model.User, code string) (bool, error) {
+	secret, err := totp.Open(s.encryptionKey(), user.TOTPSecret)
+	if err != nil {
+		return false, err
+	}
+	step, ok := totp.Validate(secret, code, time.Now(), totpSkew)
+	if !ok || step <= user.TOTPLastStep {
+		return false, nil
+	}
+
+	user.TOTPLastStep = step
+	if err := s.userStore.UpdateUser(ctx, user); err != nil {
+		return false, fmt.Errorf("更新验证码状态失败: %w", err)
+	}
+	return true, nil
+}
+
+// generateRecoveryCodes 生成新的恢复码（形如 abcde-23456），只保存摘要
+func (s *twoFactorService) generateRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	buf := make([]byte, 10)
+	for i := 0; i < recoveryCodeCount; i++ {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("生成恢复码失败: %w", err)
+		}
+		encoded := strings.ToLower(base32.StdEncoding.EncodeToString(buf))[:10]
+		code := encoded[:5] + "-" + encoded[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, model.HashRecoveryCode(code))
+	}
+	if err := s.codeStore.Replace(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// encryptionKey 加密 TOTP 密钥使用的密钥
+func (s *twoFactorService) encryptionKey() string {
+	if s.cfg.TOTPEncryptionKey != "" {
+		return s.cfg.TOTPEncryptionKey
+	}
+	return s.cfg.JWTSecret
+}
+
+// requireTwoFactor 检查密码验证通过的用户是否需要两步验证，
+// 需要时返回带挑战令牌的 *TwoFactorRequiredError，不需要时返回 nil
+func requireTwoFactor(ctx context.Context, workspaceStore store.WorkspaceStore, jwtService JWTService, user *model.User) error {
+	enrollmentRequired := false
+	if !user.TwoFactorEnabled() {
+		settings, err := workspaceAuthSettings(ctx, workspaceStore, user.WorkspaceID)
+		if err != nil {
+			return err
+		}
+		if !settings.TwoFactorRequired {
+			return nil
+		}
+		enrollmentRequired = true
+	}
+
+	token, err := jwtService.GenerateTwoFactorToken(user.ID)
+	if err != nil {
+		return fmt.Errorf("生成挑战令牌失败: %w", err)
+	}
+	return &TwoFactorRequiredError{ChallengeToken: token, EnrollmentRequired: enrollmentRequired}
+}
+
+// workspaceAuthSettings 获取工作区认证设置，工作区不存在时使用默认设置
+func workspaceAuthSettings(ctx context.Context, workspaceStore store.WorkspaceStore, workspaceID uuid.UUID) (*AuthSettings, error) {
+	workspace, err := workspaceStore.GetByID(ctx, workspaceID.String())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &AuthSettings{}, nil
+		}
+		return nil, fmt.Errorf("获取工作区失败: %w", err)
+	}
+	return parseAuthSettings(workspace.Settings)
+}
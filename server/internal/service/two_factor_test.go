@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/config"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/liwei0526vip/mylinear/internal/store"
+	"github.com/liwei0526vip/mylinear/internal/totp"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+func TestTwoFactorRequiredError(t *testing.T) {
+	var err error = &TwoFactorRequiredError{ChallengeToken: "token"}
+	var required *TwoFactorRequiredError
+	if !errors.As(err, &required) || required.ChallengeToken != "token" {
+		t.Fatalf("errors.As() 失败: %v", err)
+	}
+	if err.Error() != "需要两步验证" {
+		t.Errorf("Error() = %q", err.Error())
+	}
+	if (&TwoFactorRequiredError{EnrollmentRequired: true}).Error() != "工作区要求启用两步验证" {
+		t.Error("需要绑定时的错误信息不正确")
+	}
+}
+
+func TestTwoFactorService_RejectsNonChallengeToken(t *testing.T) {
+	cfg := &config.Config{JWTSecret: "2fa-test-secret", JWTAccessExpiry: time.Minute, JWTRefreshExpiry: time.Hour}
+	jwtService := NewJWTService(cfg)
+	svc := NewTwoFactorService(nil, nil, nil, jwtService, nil, nil, cfg)
+
+	user := &model.User{Email: "alice@example.com", Role: model.RoleMember}
+	accessToken, _ := jwtService.GenerateAccessToken(user.ID, user.Email, user.Role)
+	refreshToken, _ := jwtService.GenerateRefreshToken(user.ID, uuid.New())
+
+	for _, token := range []string{"", "invalid", accessToken, refreshToken} {
+		if _, err := svc.CompleteLogin(context.Background(), token, "123456"); err == nil {
+			t.Errorf("CompleteLogin(%q) 应返回错误", token)
+		}
+		if _, err := svc.BeginChallengeEnrollment(context.Background(), token); err == nil {
+			t.Errorf("BeginChallengeEnrollment(%q) 应返回错误", token)
+		}
+	}
+}
+
+type twoFactorFixtures struct {
+	*issueServiceFixtures
+	service        TwoFactorService
+	userStore      store.UserStore
+	workspaceStore store.WorkspaceStore
+	jwtService     JWTService
+}
+
+func setupTwoFactorFixtures(t *testing.T, db *gorm.DB) *twoFactorFixtures {
+	base := setupIssueServiceFixtures(t, db)
+	cfg := &config.Config{
+		JWTSecret:        "2fa-test-secret",
+		JWTAccessExpiry:  15 * time.Minute,
+		JWTRefreshExpiry: 7 * 24 * time.Hour,
+		TOTPIssuer:       "MyLinear",
+	}
+	userStore := store.NewUserStore(db)
+	workspaceStore := store.NewWorkspaceStore(db)
+	jwtService := NewJWTService(cfg)
+
+	return &twoFactorFixtures{
+		issueServiceFixtures: base,
+		service:              NewTwoFactorService(userStore, workspaceStore, store.NewRecoveryCodeStore(db), jwtService, NewSessionService(store.NewSessionStore(db), userStore, store.NewWorkspaceMemberStore(db), store.NewOAuthStore(db), jwtService, cfg), nil, cfg),
+		userStore:            userStore,
+		workspaceStore:       workspaceStore,
+		jwtService:           jwtService,
+	}
+}
+
+// enroll 为当前用户启用两步验证，返回验证器密钥和恢复码
+func (f *twoFactorFixtures) enroll(t *testing.T) (string, []string) {
+	t.Helper()
+	enrollment, err := f.service.BeginEnrollment(f.ctx)
+	if err != nil {
+		t.Fatalf("BeginEnrollment() error = %v", err)
+	}
+	code, _ := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	codes, err := f.service.ConfirmEnrollment(f.ctx, code)
+	if err != nil {
+		t.Fatalf("ConfirmEnrollment() error = %v", err)
+	}
+	return enrollment.Secret, codes
+}
+
+// challenge 模拟密码验证通过后获取挑战令牌
+func (f *twoFactorFixtures) challenge(t *testing.T) *TwoFactorRequiredError {
+	t.Helper()
+	user, err := f.userStore.GetUserByID(f.ctx, f.userID.String())
+	if err != nil {
+		t.Fatalf("获取用户失败: %v", err)
+	}
+	var required *TwoFactorRequiredError
+	if err := requireTwoFactor(f.ctx, f.workspaceStore, f.jwtService, user); !errors.As(err, &required) {
+		t.Fatalf("requireTwoFactor() = %v, want *TwoFactorRequiredError", err)
+	}
+	return required
+}
+
+// requireForWorkspace 设置工作区是否要求两步验证
+func (f *twoFactorFixtures) requireForWorkspace(t *testing.T, required bool) {
+	t.Helper()
+	workspace, err := f.workspaceStore.GetByID(f.ctx, f.workspaceID.String())
+	if err != nil {
+		t.Fatalf("获取工作区失败: %v", err)
+	}
+	settings, err := mergeAuthSettings(workspace.Settings, &AuthSettings{TwoFactorRequired: required})
+	if err != nil {
+		t.Fatalf("mergeAuthSettings() error = %v", err)
+	}
+	workspace.Settings = settings
+	if err := f.workspaceStore.Update(f.ctx, workspace); err != nil {
+		t.Fatalf("更新工作区失败: %v", err)
+	}
+}
+
+func TestTwoFactorService_Enrollment(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupTwoFactorFixtures(t, tx)
+
+	enrollment, err := f.service.BeginEnrollment(f.ctx)
+	if err != nil {
+		t.Fatalf("BeginEnrollment() error = %v", err)
+	}
+	if enrollment.Secret == "" || enrollment.URI == "" {
+		t.Fatalf("BeginEnrollment() = %+v", enrollment)
+	}
+
+	// 密钥加密保存
+	user, _ := f.userStore.GetUserByID(f.ctx, f.userID.String())
+	if user.TOTPSecret == "" || user.TOTPSecret == enrollment.Secret {
+		t.Error("验证器密钥应加密保存")
+	}
+	if user.TwoFactorEnabled() {
+		t.Error("确认前不应启用两步验证")
+	}
+	// 未启用时登录不需要两步验证
+	if err := requireTwoFactor(f.ctx, f.workspaceStore, f.jwtService, user); err != nil {
+		t.Errorf("requireTwoFactor() = %v, want nil", err)
+	}
+
+	if _, err := f.service.ConfirmEnrollment(f.ctx, "000000"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("错误的验证码应返回 ErrInvalidTwoFactorCode, got %v", err)
+	}
+
+	code, _ := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	codes, err := f.service.ConfirmEnrollment(f.ctx, code)
+	if err != nil {
+		t.Fatalf("ConfirmEnrollment() error = %v", err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Errorf("len(codes) = %d, want %d", len(codes), recoveryCodeCount)
+	}
+
+	status, err := f.service.Status(f.ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if !status.Enabled || status.RecoveryCodesRemaining != recoveryCodeCount {
+		t.Errorf("Status() = %+v", status)
+	}
+
+	if _, err := f.service.BeginEnrollment(f.ctx); err == nil {
+		t.Error("已启用时不能重新生成密钥")
+	}
+}
+
+func TestTwoFactorService_CompleteLogin(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupTwoFactorFixtures(t, tx)
+	secret, codes := f.enroll(t)
+
+	required := f.challenge(t)
+	if required.EnrollmentRequired {
+		t.Error("已启用两步验证时不需要绑定")
+	}
+
+	// 挑战令牌不能作为访问令牌使用
+	claims, err := f.jwtService.ValidateToken(required.ChallengeToken)
+	if err != nil || claims.Type != TokenTypeTwoFactor {
+		t.Fatalf("挑战令牌类型错误: %+v, %v", claims, err)
+	}
+
+	if _, err := f.service.CompleteLogin(f.ctx, required.ChallengeToken, "000000"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("错误的验证码应返回 ErrInvalidTwoFactorCode, got %v", err)
+	}
+
+	// 绑定时使用的验证码不能重复使用，下一个时间步的验证码有效
+	used, _ := totp.Code(secret, totp.Step(time.Now()))
+	if _, err := f.service.CompleteLogin(f.ctx, required.ChallengeToken, used); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("重复使用的验证码应无效, got %v", err)
+	}
+	next, _ := totp.Code(secret, totp.Step(time.Now())+1)
+	result, err := f.service.CompleteLogin(f.ctx, required.ChallengeToken, next)
+	if err != nil {
+		t.Fatalf("CompleteLogin() error = %v", err)
+	}
+	if result.AccessToken == "" || result.RefreshToken == "" || result.User.ID != f.userID {
+		t.Errorf("CompleteLogin() = %+v", result)
+	}
+	if len(result.RecoveryCodes) != 0 {
+		t.Error("已启用两步验证时不应返回恢复码")
+	}
+
+	// 恢复码只能使用一次
+	required = f.challenge(t)
+	if _, err := f.service.CompleteLogin(f.ctx, required.ChallengeToken, codes[0]); err != nil {
+		t.Fatalf("使用恢复码登录失败: %v", err)
+	}
+	required = f.challenge(t)
+	if _, err := f.service.CompleteLogin(f.ctx, required.ChallengeToken, codes[0]); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("已使用的恢复码应无效, got %v", err)
+	}
+
+	status, _ := f.service.Status(f.ctx)
+	if status.RecoveryCodesRemaining != recoveryCodeCount-1 {
+		t.Errorf("RecoveryCodesRemaining = %d, want %d", status.RecoveryCodesRemaining, recoveryCodeCount-1)
+	}
+}
+
+func TestTwoFactorService_CompleteLogin_Lockout(t *testing.T) {
+	rdb := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	defer rdb.Close()
+	if err := rdb.Ping(context.Background()).Err(); err != nil {
+		t.Skip("无法连接 Redis")
+	}
+
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupTwoFactorFixtures(t, tx)
+	secret, _ := f.enroll(t)
+
+	// 连续失败 3 次后锁定，每次使用新的挑战令牌
+	f.service.(*twoFactorService).lockout = loginLockout{
+		redis: rdb,
+		cfg:   &config.Config{LoginLockoutThreshold: 3, LoginLockoutDuration: time.Minute},
+	}
+	user, err := f.userStore.GetUserByID(f.ctx, f.userID.String())
+	if err != nil {
+		t.Fatalf("获取用户失败: %v", err)
+	}
+	email := NormalizeEmail(user.Email)
+	defer rdb.Del(f.ctx, "login_lockout:"+email, "login_failures:"+email)
+
+	for i := 0; i < 3; i++ {
+		required := f.challenge(t)
+		if _, err := f.service.CompleteLogin(f.ctx, required.ChallengeToken, "000000"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+			t.Fatalf("错误的验证码应返回 ErrInvalidTwoFactorCode, got %v", err)
+		}
+	}
+
+	// 锁定期间验证码正确也不能登录
+	required := f.challenge(t)
+	next, _ := totp.Code(secret, totp.Step(time.Now())+1)
+	var locked *AccountLockedError
+	if _, err := f.service.CompleteLogin(f.ctx, required.ChallengeToken, next); !errors.As(err, &locked) {
+		t.Errorf("CompleteLogin() error = %v, want AccountLockedError", err)
+	}
+}
+
+func TestTwoFactorService_Disable(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupTwoFactorFixtures(t, tx)
+	_, codes := f.enroll(t)
+
+	if err := f.service.Disable(f.ctx, "wrong-code"); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("错误的恢复码应返回 ErrInvalidTwoFactorCode, got %v", err)
+	}
+
+	// 工作区要求两步验证时不能关闭
+	f.requireForWorkspace(t, true)
+	if err := f.service.Disable(f.ctx, codes[0]); err == nil {
+		t.Error("工作区要求两步验证时不能关闭")
+	}
+	f.requireForWorkspace(t, false)
+
+	if err := f.service.Disable(f.ctx, codes[1]); err != nil {
+		t.Fatalf("Disable() error = %v", err)
+	}
+	status, _ := f.service.Status(f.ctx)
+	if status.Enabled || status.RecoveryCodesRemaining != 0 {
+		t.Errorf("Status() = %+v", status)
+	}
+	if err := f.service.Disable(f.ctx, codes[2]); err == nil {
+		t.Error("未启用时 Disable 应返回错误")
+	}
+}
+
+func TestTwoFactorService_RegenerateRecoveryCodes(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupTwoFactorFixtures(t, tx)
+	_, codes := f.enroll(t)
+
+	newCodes, err := f.service.RegenerateRecoveryCodes(f.ctx, codes[0])
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes() error = %v", err)
+	}
+	if len(newCodes) != recoveryCodeCount {
+		t.Errorf("len(newCodes) = %d", len(newCodes))
+	}
+	// 旧恢复码失效
+	if _, err := f.service.RegenerateRecoveryCodes(f.ctx, codes[1]); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("旧恢复码应失效, got %v", err)
+	}
+}
+
+func TestTwoFactorService_WorkspaceEnforcement(t *testing.T) {
+	tx := testSvcDB.Begin()
+	defer tx.Rollback()
+	f := setupTwoFactorFixtures(t, tx)
+	f.requireForWorkspace(t, true)
+
+	status, err := f.service.Status(f.ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if !status.Required || status.Enabled {
+		t.Errorf("Status() = %+v", status)
+	}
+
+	// 未启用的用户登录时需要先绑定验证器
+	required := f.challenge(t)
+	if !required.EnrollmentRequired {
+		t.Fatal("工作区要求两步验证时应要求绑定")
+	}
+	enrollment, err := f.service.BeginChallengeEnrollment(context.Background(), required.ChallengeToken)
+	if err != nil {
+		t.Fatalf("BeginChallengeEnrollment() error = %v", err)
+	}
+
+	code, _ := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	result, err := f.service.CompleteLogin(context.Background(), required.ChallengeToken, code)
+	if err != nil {
+		t.Fatalf("CompleteLogin() error = %v", err)
+	}
+	if result.AccessToken == "" || len(result.RecoveryCodes) != recoveryCodeCount {
+		t.Errorf("登录中完成绑定应返回令牌和恢复码: %+v", result)
+	}
+
+	user, _ := f.userStore.GetUserByID(f.ctx, f.userID.String())
+	if !user.TwoFactorEnabled() {
+		t.Error("登录中完成绑定后应启用两步验证")
+	}
+}
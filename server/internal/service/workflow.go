@@ -34,8 +34,9 @@ type UpdateStateParams struct {
 }
 
 type workflowService struct {
-	stateStore store.WorkflowStateStore
-	teamStore  store.TeamStore
+	stateStore   store.WorkflowStateStore
+	teamStore    store.TeamStore
+	auditService AuditService
 }
 
 func NewWorkflowService(stateStore store.WorkflowStateStore, teamStore store.TeamStore) WorkflowService {
@@ -45,6 +46,15 @@ func NewWorkflowService(stateStore store.WorkflowStateStore, teamStore store.Tea
 	}
 }
 
+// NewWorkflowServiceWithAudit 创建记录审计日志的工作流服务实例
+func NewWorkflowServiceWithAudit(stateStore store.WorkflowStateStore, teamStore store.TeamStore, auditService AuditService) WorkflowService {
+	return &workflowService{
+		stateStore:   stateStore,
+		teamStore:    teamStore,
+		auditService: auditService,
+	}
+}
+
 // CreateState creates a new workflow state
 func (s *workflowService) CreateState(ctx context.Context, cmd *CreateStateParams) (*model.WorkflowState, error) {
 	// 1. Basic Validation
@@ -91,6 +101,7 @@ func (s *workflowService) CreateState(ctx context.Context, cmd *CreateStateParam
 		return nil, err
 	}
 
+	s.recordAudit(ctx, model.AuditWorkflowStateCreated, state, nil, workflowStateAuditValues(state))
 	return state, nil
 }
 
@@ -106,6 +117,7 @@ func (s *workflowService) UpdateState(ctx context.Context, id uuid.UUID, cmd *Up
 	if state == nil {
 		return nil, errors.New("state not found")
 	}
+	before := workflowStateAuditValues(state)
 
 	if cmd.Name != nil {
 		state.Name = *cmd.Name
@@ -123,6 +135,8 @@ func (s *workflowService) UpdateState(ctx context.Context, id uuid.UUID, cmd *Up
 	if err := s.stateStore.Update(ctx, state); err != nil {
 		return nil, err
 	}
+
+	s.recordAudit(ctx, model.AuditWorkflowStateUpdated, state, before, workflowStateAuditValues(state))
 	return state, nil
 }
 
@@ -164,5 +178,46 @@ func (s *workflowService) DeleteState(ctx context.Context, id uuid.UUID) error {
 		return fmt.Errorf("cannot delete the last state of type %s", state.Type)
 	}
 
-	return s.stateStore.Delete(ctx, id)
+	if err := s.stateStore.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.recordAudit(ctx, model.AuditWorkflowStateDeleted, state, workflowStateAuditValues(state), nil)
+	return nil
+}
+
+// recordAudit 记录工作流状态变更的审计日志
+func (s *workflowService) recordAudit(ctx context.Context, action model.AuditAction, state *model.WorkflowState, before, after map[string]interface{}) {
+	if s.auditService == nil {
+		return
+	}
+
+	entry := &AuditEntry{
+		Action:     action,
+		TargetType: model.AuditTargetWorkflowState,
+		TargetID:   state.ID.String(),
+	}
+	if team, err := s.teamStore.GetByID(ctx, state.TeamID.String()); err == nil {
+		entry.WorkspaceID = team.WorkspaceID
+	}
+	// 避免把 nil map 作为非空接口值写入
+	if before != nil {
+		entry.Before = before
+	}
+	if after != nil {
+		entry.After = after
+	}
+	s.auditService.Record(ctx, entry)
+}
+
+// workflowStateAuditValues 审计日志中记录的工作流状态字段
+func workflowStateAuditValues(state *model.WorkflowState) map[string]interface{} {
+	return map[string]interface{}{
+		"team_id":     state.TeamID,
+		"name":        state.Name,
+		"type":        state.Type,
+		"color":       state.Color,
+		"position":    state.Position,
+		"description": state.Description,
+	}
 }
@@ -3,13 +3,39 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/liwei0526vip/mylinear/internal/model"
 	"github.com/liwei0526vip/mylinear/internal/store"
+	"gorm.io/gorm"
 )
 
+// workspaceSlugRegex 工作区 Slug 格式：小写字母、数字和连字符，不能以连字符开头或结尾
+var workspaceSlugRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,48}[a-z0-9])?$`)
+
+// WorkspaceMembership 当前用户加入的工作区
+type WorkspaceMembership struct {
+	*model.Workspace
+	// Role 用户在该工作区的角色
+	Role model.Role `json:"role"`
+	// Home 是否为用户的主工作区
+	Home bool `json:"home"`
+	// Current 是否为当前所在的工作区
+	Current bool `json:"current"`
+}
+
+// SwitchedWorkspace 切换工作区的结果
+type SwitchedWorkspace struct {
+	Workspace   *model.Workspace `json:"workspace"`
+	Role        model.Role       `json:"role"`
+	AccessToken string           `json:"access_token"`
+}
+
 // WorkspaceService 定义工作区服务接口
 type WorkspaceService interface {
 	// GetWorkspace 获取工作区信息
@@ -18,19 +44,41 @@ type WorkspaceService interface {
 	UpdateWorkspace(ctx context.Context, workspaceID string, updates map[string]interface{}) (*model.Workspace, error)
 	// GetWorkspaceStats 获取工作区统计信息
 	GetWorkspaceStats(ctx context.Context, workspaceID string) (*store.WorkspaceStats, error)
+	// ListWorkspaces 获取当前用户加入的工作区（主工作区在前）
+	ListWorkspaces(ctx context.Context) ([]WorkspaceMembership, error)
+	// CreateWorkspace 创建工作区，创建者成为该工作区的管理员（仅管理员）
+	CreateWorkspace(ctx context.Context, name, slug string) (*model.Workspace, error)
+	// SwitchWorkspace 将当前会话切换到指定工作区，返回作用于该工作区的新访问令牌
+	SwitchWorkspace(ctx context.Context, workspaceID string) (*SwitchedWorkspace, error)
 }
 
 // workspaceService 实现 WorkspaceService 接口
 type workspaceService struct {
-	workspaceStore store.WorkspaceStore
-	userStore      store.UserStore
+	workspaceStore       store.WorkspaceStore
+	userStore            store.UserStore
+	workspaceMemberStore store.WorkspaceMemberStore
+	sessionService       SessionService
+	auditService         AuditService
 }
 
 // NewWorkspaceService 创建工作区服务实例
-func NewWorkspaceService(workspaceStore store.WorkspaceStore, userStore store.UserStore) WorkspaceService {
+func NewWorkspaceService(workspaceStore store.WorkspaceStore, userStore store.UserStore, workspaceMemberStore store.WorkspaceMemberStore, sessionService SessionService) WorkspaceService {
 	return &workspaceService{
-		workspaceStore: workspaceStore,
-		userStore:      userStore,
+		workspaceStore:       workspaceStore,
+		userStore:            userStore,
+		workspaceMemberStore: workspaceMemberStore,
+		sessionService:       sessionService,
+	}
+}
+
+// NewWorkspaceServiceWithAudit 创建记录审计日志的工作区服务实例
+func NewWorkspaceServiceWithAudit(workspaceStore store.WorkspaceStore, userStore store.UserStore, workspaceMemberStore store.WorkspaceMemberStore, sessionService SessionService, auditService AuditService) WorkspaceService {
+	return &workspaceService{
+		workspaceStore:       workspaceStore,
+		userStore:            userStore,
+		workspaceMemberStore: workspaceMemberStore,
+		sessionService:       sessionService,
+		auditService:         auditService,
 	}
 }
 
@@ -54,8 +102,8 @@ func (s *workspaceService) GetWorkspace(ctx context.Context, workspaceID string)
 		return nil, fmt.Errorf("用户不存在")
 	}
 
-	if user.WorkspaceID.String() != workspaceID {
-		return nil, fmt.Errorf("无权限访问此工作区")
+	if err := s.checkMember(ctx, user, workspace.ID); err != nil {
+		return nil, err
 	}
 
 	return workspace, nil
@@ -83,15 +131,17 @@ func (s *workspaceService) UpdateWorkspace(ctx context.Context, workspaceID stri
 		return nil, fmt.Errorf("用户不存在")
 	}
 
-	if user.WorkspaceID.String() != workspaceID {
-		return nil, fmt.Errorf("无权限访问此工作区")
+	if err := s.checkMember(ctx, user, workspace.ID); err != nil {
+		return nil, err
 	}
 
-	// 只有 Admin 可以更新工作区
-	if userRole != model.RoleAdmin && userRole != model.RoleGlobalAdmin {
+	// 只有 Admin 可以更新工作区；令牌中的角色只在当前所在的工作区有效
+	if userRole != model.RoleGlobalAdmin && (userRole != model.RoleAdmin || activeWorkspaceID(ctx, user) != workspace.ID) {
 		return nil, fmt.Errorf("无权限更新工作区")
 	}
 
+	before := workspaceAuditValues(workspace)
+
 	// 应用更新
 	if name, ok := updates["name"].(string); ok {
 		workspace.Name = name
@@ -105,9 +155,27 @@ func (s *workspaceService) UpdateWorkspace(ctx context.Context, workspaceID stri
 		return nil, fmt.Errorf("更新工作区失败: %w", err)
 	}
 
+	recordAudit(ctx, s.auditService, &AuditEntry{
+		WorkspaceID: workspace.ID,
+		Action:      model.AuditWorkspaceUpdated,
+		TargetType:  model.AuditTargetWorkspace,
+		TargetID:    workspace.ID.String(),
+		Before:      before,
+		After:       workspaceAuditValues(workspace),
+	})
+
 	return workspace, nil
 }
 
+// workspaceAuditValues 审计日志中记录的工作区字段
+func workspaceAuditValues(workspace *model.Workspace) map[string]interface{} {
+	values := map[string]interface{}{"name": workspace.Name, "logo_url": nil}
+	if workspace.LogoURL != nil {
+		values["logo_url"] = *workspace.LogoURL
+	}
+	return values
+}
+
 // GetWorkspaceStats 获取工作区统计信息
 func (s *workspaceService) GetWorkspaceStats(ctx context.Context, workspaceID string) (*store.WorkspaceStats, error) {
 	// 获取当前用户 ID
@@ -117,7 +185,7 @@ func (s *workspaceService) GetWorkspaceStats(ctx context.Context, workspaceID st
 	}
 
 	// 获取工作区
-	_, err := s.workspaceStore.GetByID(ctx, workspaceID)
+	workspace, err := s.workspaceStore.GetByID(ctx, workspaceID)
 	if err != nil {
 		return nil, fmt.Errorf("工作区不存在")
 	}
@@ -128,10 +196,166 @@ func (s *workspaceService) GetWorkspaceStats(ctx context.Context, workspaceID st
 		return nil, fmt.Errorf("用户不存在")
 	}
 
-	if user.WorkspaceID.String() != workspaceID {
-		return nil, fmt.Errorf("无权限访问此工作区")
+	if err := s.checkMember(ctx, user, workspace.ID); err != nil {
+		return nil, err
+	}
+
+	// 获取统计信息（不包括用户不在其中的私有团队）
+	return s.workspaceStore.GetStats(ctx, workspaceID, userID)
+}
+
+// ListWorkspaces 获取当前用户加入的工作区
+func (s *workspaceService) ListWorkspaces(ctx context.Context) ([]WorkspaceMembership, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+	user, err := s.userStore.GetUserByID(ctx, userID.String())
+	if err != nil {
+		return nil, fmt.Errorf("用户不存在")
+	}
+	members, err := s.workspaceMemberStore.ListByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := activeWorkspaceID(ctx, user)
+	home, err := s.workspaceStore.GetByID(ctx, user.WorkspaceID.String())
+	if err != nil {
+		return nil, fmt.Errorf("获取工作区失败: %w", err)
 	}
+	// 主工作区的角色以 User.Role 为准
+	result := []WorkspaceMembership{{Workspace: home, Role: user.Role, Home: true, Current: home.ID == current}}
+	for _, member := range members {
+		if member.WorkspaceID == user.WorkspaceID || member.Workspace == nil {
+			continue
+		}
+		role := member.Role
+		if user.Role == model.RoleGlobalAdmin {
+			role = model.RoleGlobalAdmin
+		}
+		result = append(result, WorkspaceMembership{
+			Workspace: member.Workspace,
+			Role:      role,
+			Current:   member.WorkspaceID == current,
+		})
+	}
+	return result, nil
+}
 
-	// 获取统计信息
-	return s.workspaceStore.GetStats(ctx, workspaceID)
+// CreateWorkspace 创建工作区，创建者成为该工作区的管理员
+func (s *workspaceService) CreateWorkspace(ctx context.Context, name, slug string) (*model.Workspace, error) {
+	userID, ok := ctx.Value("user_id").(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("未认证")
+	}
+	userRole, _ := ctx.Value("user_role").(model.Role)
+	if userRole != model.RoleAdmin && userRole != model.RoleGlobalAdmin {
+		return nil, fmt.Errorf("无权限创建工作区")
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("无效的工作区名称")
+	}
+	if slug == "" {
+		slug = slugify(name)
+	}
+	if !workspaceSlugRegex.MatchString(slug) {
+		return nil, fmt.Errorf("无效的工作区标识: 只能包含小写字母、数字和连字符，最长 50 个字符")
+	}
+	if _, err := s.workspaceStore.GetBySlug(ctx, slug); err == nil {
+		return nil, fmt.Errorf("工作区标识已被使用")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("获取工作区失败: %w", err)
+	}
+
+	workspace := &model.Workspace{Name: name, Slug: slug}
+	if err := s.workspaceStore.Create(ctx, workspace); err != nil {
+		return nil, fmt.Errorf("创建工作区失败: %w", err)
+	}
+	member := &model.WorkspaceMember{
+		WorkspaceID: workspace.ID,
+		UserID:      userID,
+		Role:        model.RoleAdmin,
+		JoinedAt:    time.Now(),
+	}
+	if err := s.workspaceMemberStore.Add(ctx, member); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// SwitchWorkspace 将当前会话切换到指定工作区
+func (s *workspaceService) SwitchWorkspace(ctx context.Context, workspaceID string) (*SwitchedWorkspace, error) {
+	id, err := uuid.Parse(workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("无效的工作区ID")
+	}
+	workspace, err := s.workspaceStore.GetByID(ctx, id.String())
+	if err != nil {
+		return nil, fmt.Errorf("工作区不存在")
+	}
+
+	accessToken, role, err := s.sessionService.SwitchWorkspace(ctx, workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &SwitchedWorkspace{Workspace: workspace, Role: role, AccessToken: accessToken}, nil
+}
+
+// checkMember 检查用户是工作区成员，全局管理员可以访问所有工作区
+func (s *workspaceService) checkMember(ctx context.Context, user *model.User, workspaceID uuid.UUID) error {
+	if user.WorkspaceID == workspaceID || user.Role == model.RoleGlobalAdmin {
+		return nil
+	}
+	if _, err := s.workspaceMemberStore.Get(ctx, workspaceID, user.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("无权限访问此工作区")
+		}
+		return fmt.Errorf("获取工作区成员失败: %w", err)
+	}
+	return nil
+}
+
+// activeWorkspaceID 获取当前所在的工作区，令牌未指定时使用用户的主工作区
+func activeWorkspaceID(ctx context.Context, user *model.User) uuid.UUID {
+	if workspaceID, ok := ctx.Value("workspace_id").(uuid.UUID); ok && workspaceID != uuid.Nil {
+		return workspaceID
+	}
+	return user.WorkspaceID
+}
+
+// isAdminOfWorkspace 当前用户能否以管理员身份访问指定工作区的资源
+// 全局管理员不受限制；工作区管理员只能访问当前所在工作区的资源，上下文中没有工作区时不按管理员处理
+func isAdminOfWorkspace(ctx context.Context, workspaceID uuid.UUID) bool {
+	switch role, _ := ctx.Value("user_role").(model.Role); role {
+	case model.RoleGlobalAdmin:
+		return true
+	case model.RoleAdmin:
+		current, _ := ctx.Value("workspace_id").(uuid.UUID)
+		return current != uuid.Nil && current == workspaceID
+	}
+	return false
+}
+
+// slugify 根据名称生成工作区 Slug，无法生成时返回空字符串
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(b.String(), "-")
+	if len(slug) > 50 {
+		slug = strings.TrimSuffix(slug[:50], "-")
+	}
+	return slug
 }
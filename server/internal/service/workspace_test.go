@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -49,7 +50,7 @@ func TestWorkspaceService_GetWorkspace(t *testing.T) {
 
 	workspaceStore := store.NewWorkspaceStore(tx)
 	userStore := store.NewUserStore(tx)
-	svc := NewWorkspaceService(workspaceStore, userStore)
+	svc := NewWorkspaceService(workspaceStore, userStore, store.NewWorkspaceMemberStore(tx), nil)
 
 	ctx := context.Background()
 	prefix := uuid.New().String()[:8]
@@ -163,7 +164,7 @@ func TestWorkspaceService_UpdateWorkspace(t *testing.T) {
 
 	workspaceStore := store.NewWorkspaceStore(tx)
 	userStore := store.NewUserStore(tx)
-	svc := NewWorkspaceService(workspaceStore, userStore)
+	svc := NewWorkspaceService(workspaceStore, userStore, store.NewWorkspaceMemberStore(tx), nil)
 
 	prefix := uuid.New().String()[:8]
 
@@ -283,7 +284,7 @@ func TestWorkspaceService_GetWorkspaceStats(t *testing.T) {
 
 	workspaceStore := store.NewWorkspaceStore(tx)
 	userStore := store.NewUserStore(tx)
-	svc := NewWorkspaceService(workspaceStore, userStore)
+	svc := NewWorkspaceService(workspaceStore, userStore, store.NewWorkspaceMemberStore(tx), nil)
 
 	ctx := context.Background()
 	prefix := uuid.New().String()[:8]
@@ -359,3 +360,109 @@ func TestWorkspaceService_GetWorkspaceStats(t *testing.T) {
 		})
 	}
 }
+
+// =============================================================================
+// 多工作区测试
+// =============================================================================
+
+func TestWorkspaceService_Memberships(t *testing.T) {
+	if testWorkspaceServiceDB == nil {
+		t.Skip("数据库连接不可用，跳过集成测试")
+	}
+
+	tx := testWorkspaceServiceDB.Begin()
+	defer tx.Rollback()
+
+	workspaceStore := store.NewWorkspaceStore(tx)
+	userStore := store.NewUserStore(tx)
+	memberStore := store.NewWorkspaceMemberStore(tx)
+	svc := NewWorkspaceService(workspaceStore, userStore, memberStore, nil)
+
+	prefix := uuid.New().String()[:8]
+	home := &model.Workspace{Name: "Home " + prefix, Slug: "home-" + prefix}
+	if err := tx.Create(home).Error; err != nil {
+		t.Fatalf("创建测试工作区失败: %v", err)
+	}
+	admin := &model.User{
+		WorkspaceID: home.ID, Email: prefix + "_admin@example.com", Username: prefix + "_admin",
+		Name: "Admin", PasswordHash: "hash", Role: model.RoleAdmin,
+	}
+	member := &model.User{
+		WorkspaceID: home.ID, Email: prefix + "_member@example.com", Username: prefix + "_member",
+		Name: "Member", PasswordHash: "hash", Role: model.RoleMember,
+	}
+	assert.NoError(t, userStore.CreateUser(context.Background(), admin))
+	assert.NoError(t, userStore.CreateUser(context.Background(), member))
+
+	adminCtx := context.WithValue(context.Background(), "user_id", admin.ID)
+	adminCtx = context.WithValue(adminCtx, "user_role", admin.Role)
+	memberCtx := context.WithValue(context.Background(), "user_id", member.ID)
+	memberCtx = context.WithValue(memberCtx, "user_role", member.Role)
+
+	// 只有管理员可以创建工作区
+	_, err := svc.CreateWorkspace(memberCtx, "Member WS", "")
+	assert.ErrorContains(t, err, "无权限")
+	_, err = svc.CreateWorkspace(adminCtx, "Bad", "-bad-")
+	assert.ErrorContains(t, err, "无效的工作区标识")
+	_, err = svc.CreateWorkspace(adminCtx, "Duplicate", home.Slug)
+	assert.ErrorContains(t, err, "已被使用")
+
+	other, err := svc.CreateWorkspace(adminCtx, "Other WS "+prefix, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "other-ws-"+prefix, other.Slug)
+
+	// 创建者以管理员身份加入，主工作区排在最前
+	workspaces, err := svc.ListWorkspaces(adminCtx)
+	assert.NoError(t, err)
+	if assert.Len(t, workspaces, 2) {
+		assert.Equal(t, home.ID, workspaces[0].ID)
+		assert.True(t, workspaces[0].Home)
+		assert.True(t, workspaces[0].Current)
+		assert.Equal(t, other.ID, workspaces[1].ID)
+		assert.Equal(t, model.RoleAdmin, workspaces[1].Role)
+		assert.False(t, workspaces[1].Current)
+	}
+
+	// 切换后的工作区标记为当前工作区
+	switchedCtx := context.WithValue(adminCtx, "workspace_id", other.ID)
+	workspaces, err = svc.ListWorkspaces(switchedCtx)
+	assert.NoError(t, err)
+	if assert.Len(t, workspaces, 2) {
+		assert.False(t, workspaces[0].Current)
+		assert.True(t, workspaces[1].Current)
+	}
+
+	// 非成员不能访问其他工作区
+	_, err = svc.GetWorkspace(memberCtx, other.ID.String())
+	assert.ErrorContains(t, err, "无权限")
+	_, err = svc.GetWorkspace(adminCtx, other.ID.String())
+	assert.NoError(t, err)
+
+	// 管理员需要先切换到该工作区才能更新
+	_, err = svc.UpdateWorkspace(adminCtx, other.ID.String(), map[string]interface{}{"name": "Renamed"})
+	assert.ErrorContains(t, err, "无权限")
+	updated, err := svc.UpdateWorkspace(switchedCtx, other.ID.String(), map[string]interface{}{"name": "Renamed"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Renamed", updated.Name)
+}
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Acme Corp", "acme-corp"},
+		{"  Hello,  World!  ", "hello-world"},
+		{"研发中心 R&D 2024", "r-d-2024"},
+		{"研发中心", ""},
+		{strings.Repeat("ab-", 30), strings.TrimSuffix(strings.Repeat("ab-", 17), "-")},
+	}
+	for _, tt := range tests {
+		if got := slugify(tt.name); got != tt.want {
+			t.Errorf("slugify(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if tt.want != "" && !workspaceSlugRegex.MatchString(tt.want) {
+			t.Errorf("%q 不符合 Slug 格式", tt.want)
+		}
+	}
+}
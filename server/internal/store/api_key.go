@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"gorm.io/gorm"
+)
+
+// apiKeyTouchInterval 最近使用时间的更新间隔，避免每个请求都写库
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyStore 定义 API Key 数据访问接口
+type APIKeyStore interface {
+	// Create 创建 API Key
+	Create(ctx context.Context, key *model.APIKey) error
+	// GetByID 根据 ID 获取 API Key
+	GetByID(ctx context.Context, id uuid.UUID) (*model.APIKey, error)
+	// ListByUser 获取用户的所有 API Key（按创建时间倒序）
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]model.APIKey, error)
+	// Revoke 吊销 API Key，已吊销的 Key 不会重复更新
+	Revoke(ctx context.Context, id uuid.UUID) error
+}
+
+// apiKeyStore 实现 APIKeyStore 接口
+type apiKeyStore struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyStore 创建 API Key 存储实例
+func NewAPIKeyStore(db *gorm.DB) APIKeyStore {
+	return &apiKeyStore{db: db}
+}
+
+// Create 创建 API Key
+func (s *apiKeyStore) Create(ctx context.Context, key *model.APIKey) error {
+	if err := s.db.WithContext(ctx).Create(key).Error; err != nil {
+		return fmt.Errorf("创建 API Key 失败: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取 API Key
+func (s *apiKeyStore) GetByID(ctx context.Context, id uuid.UUID) (*model.APIKey, error) {
+	var key model.APIKey
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListByUser 获取用户的所有 API Key（按创建时间倒序）
+func (s *apiKeyStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&keys).Error
+	if err != nil {
+		return nil, fmt.Errorf("获取 API Key 列表失败: %w", err)
+	}
+	return keys, nil
+}
+
+// Revoke 吊销 API Key，已吊销的 Key 不会重复更新
+func (s *apiKeyStore) Revoke(ctx context.Context, id uuid.UUID) error {
+	err := s.db.WithContext(ctx).Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at": time.Now(),
+			"updated_at": time.Now(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("吊销 API Key 失败: %w", err)
+	}
+	return nil
+}
+
+// AuthenticateAPIKey 根据明文 Key 查找可用的 API Key（预加载用户）并更新最近使用时间
+// Key 不存在、已吊销或已过期时返回 gorm.ErrRecordNotFound
+func AuthenticateAPIKey(ctx context.Context, db *gorm.DB, rawKey string) (*model.APIKey, error) {
+	var key model.APIKey
+	err := db.WithContext(ctx).
+		Preload("User").
+		Where("key_hash = ?", model.HashAPIKey(rawKey)).
+		First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !key.IsActive(now) || key.User == nil || !key.User.IsActive() {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		// 更新失败不影响认证
+		db.WithContext(ctx).Model(&model.APIKey{}).
+			Where("id = ?", key.ID).
+			UpdateColumn("last_used_at", now)
+		key.LastUsedAt = &now
+	}
+	return &key, nil
+}
@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestAPIKeyStore(t *testing.T) {
+	if testDB == nil {
+		t.Skip("数据库连接不可用")
+	}
+
+	tx := testDB.Begin()
+	defer tx.Rollback()
+
+	ctx := context.Background()
+	keyStore := NewAPIKeyStore(tx)
+	_, user, _, _ := setupIssueTestFixtures(t, tx)
+
+	rawKey := model.APIKeyPrefix + "store_test_" + randomKeySuffix()
+	key := &model.APIKey{
+		UserID:  user.ID,
+		Name:    "CI",
+		Prefix:  rawKey[:model.APIKeyDisplayLength],
+		KeyHash: model.HashAPIKey(rawKey),
+		Scopes:  []string{model.APIKeyScopeRead},
+	}
+	assert.NoError(t, keyStore.Create(ctx, key))
+
+	t.Run("明文 Key 认证并记录最近使用时间", func(t *testing.T) {
+		found, err := AuthenticateAPIKey(ctx, tx, rawKey)
+		assert.NoError(t, err)
+		assert.Equal(t, key.ID, found.ID)
+		assert.NotNil(t, found.User)
+
+		stored, err := keyStore.GetByID(ctx, key.ID)
+		assert.NoError(t, err)
+		assert.NotNil(t, stored.LastUsedAt)
+	})
+
+	t.Run("错误的 Key", func(t *testing.T) {
+		_, err := AuthenticateAPIKey(ctx, tx, rawKey+"x")
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	})
+
+	t.Run("列表", func(t *testing.T) {
+		keys, err := keyStore.ListByUser(ctx, user.ID)
+		assert.NoError(t, err)
+		assert.Len(t, keys, 1)
+	})
+
+	t.Run("过期的 Key 不可用", func(t *testing.T) {
+		expiredRaw := rawKey + "_expired"
+		past := time.Now().Add(-time.Hour)
+		expired := &model.APIKey{
+			UserID:    user.ID,
+			Name:      "Expired",
+			Prefix:    expiredRaw[:model.APIKeyDisplayLength],
+			KeyHash:   model.HashAPIKey(expiredRaw),
+			Scopes:    []string{model.APIKeyScopeRead},
+			ExpiresAt: &past,
+		}
+		assert.NoError(t, keyStore.Create(ctx, expired))
+
+		_, err := AuthenticateAPIKey(ctx, tx, expiredRaw)
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	})
+
+	t.Run("吊销后不可用", func(t *testing.T) {
+		assert.NoError(t, keyStore.Revoke(ctx, key.ID))
+
+		_, err := AuthenticateAPIKey(ctx, tx, rawKey)
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+		stored, err := keyStore.GetByID(ctx, key.ID)
+		assert.NoError(t, err)
+		assert.NotNil(t, stored.RevokedAt)
+	})
+}
@@ -0,0 +1,148 @@
+// Package store 提供数据访问层
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter 审计日志查询条件
+type AuditLogFilter struct {
+	WorkspaceID uuid.UUID
+	ActorID     *uuid.UUID
+	Actions     []model.AuditAction
+	TargetType  string
+	TargetID    string
+	// Since / Until 按创建时间过滤，左闭右开
+	Since *time.Time
+	Until *time.Time
+}
+
+// AuditLogStore 定义审计日志数据访问接口
+// 审计日志只允许追加，不提供修改接口；DeleteBefore 仅用于保留策略
+type AuditLogStore interface {
+	// Create 追加审计日志
+	Create(ctx context.Context, log *model.AuditLog) error
+	// List 按条件分页查询审计日志（最新在前）
+	List(ctx context.Context, filter *AuditLogFilter, page, pageSize int) ([]model.AuditLog, int64, error)
+	// FindInBatches 按时间顺序分批遍历符合条件的审计日志（用于导出）
+	FindInBatches(ctx context.Context, filter *AuditLogFilter, batchSize int, fn func(logs []model.AuditLog) error) error
+	// DeleteBefore 删除指定时间之前的审计日志，返回删除数量
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// auditLogStore 实现 AuditLogStore 接口
+type auditLogStore struct {
+	db *gorm.DB
+}
+
+// NewAuditLogStore 创建审计日志存储实例
+func NewAuditLogStore(db *gorm.DB) AuditLogStore {
+	return &auditLogStore{db: db}
+}
+
+// Create 追加审计日志
+func (s *auditLogStore) Create(ctx context.Context, log *model.AuditLog) error {
+	if log == nil {
+		return fmt.Errorf("audit log 不能为 nil")
+	}
+	if err := s.db.WithContext(ctx).Create(log).Error; err != nil {
+		return fmt.Errorf("创建审计日志失败: %w", err)
+	}
+	return nil
+}
+
+// List 按条件分页查询审计日志（最新在前）
+func (s *auditLogStore) List(ctx context.Context, filter *AuditLogFilter, page, pageSize int) ([]model.AuditLog, int64, error) {
+	var logs []model.AuditLog
+	var total int64
+
+	query := applyAuditLogFilter(s.db.WithContext(ctx).Model(&model.AuditLog{}), filter)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计审计日志数量失败: %w", err)
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("created_at DESC, id DESC").Offset(offset).Limit(pageSize).Find(&logs).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询审计日志失败: %w", err)
+	}
+
+	return logs, total, nil
+}
+
+// FindInBatches 按时间顺序分批遍历符合条件的审计日志（用于导出）
+func (s *auditLogStore) FindInBatches(ctx context.Context, filter *AuditLogFilter, batchSize int, fn func(logs []model.AuditLog) error) error {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	query := applyAuditLogFilter(s.db.WithContext(ctx).Model(&model.AuditLog{}), filter)
+
+	// 按 (created_at, id) 做游标分页，避免大偏移量带来的性能问题
+	var last *model.AuditLog
+	for {
+		batchQuery := query.Session(&gorm.Session{})
+		if last != nil {
+			batchQuery = batchQuery.Where("(created_at, id) > (?, ?)", last.CreatedAt, last.ID)
+		}
+
+		var logs []model.AuditLog
+		if err := batchQuery.Order("created_at ASC, id ASC").Limit(batchSize).Find(&logs).Error; err != nil {
+			return fmt.Errorf("批量查询审计日志失败: %w", err)
+		}
+		if len(logs) == 0 {
+			return nil
+		}
+
+		if err := fn(logs); err != nil {
+			return err
+		}
+
+		if len(logs) < batchSize {
+			return nil
+		}
+		last = &logs[len(logs)-1]
+	}
+}
+
+// DeleteBefore 删除指定时间之前的审计日志，返回删除数量
+func (s *auditLogStore) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := s.db.WithContext(ctx).Where("created_at < ?", before).Delete(&model.AuditLog{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("清理审计日志失败: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// applyAuditLogFilter 将过滤条件应用到查询上
+func applyAuditLogFilter(query *gorm.DB, filter *AuditLogFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+	query = query.Where("workspace_id = ?", filter.WorkspaceID)
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if len(filter.Actions) > 0 {
+		query = query.Where("action IN ?", filter.Actions)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("created_at < ?", *filter.Until)
+	}
+	return query
+}
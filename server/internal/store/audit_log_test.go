@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAuditLogStore_Interface 测试 AuditLogStore 接口定义存在
+func TestAuditLogStore_Interface(t *testing.T) {
+	var _ AuditLogStore = (*auditLogStore)(nil)
+}
+
+func TestAuditLogStore(t *testing.T) {
+	if testDB == nil {
+		t.Skip("数据库连接不可用")
+	}
+
+	tx := testDB.Begin()
+	defer tx.Rollback()
+
+	ctx := context.Background()
+	auditStore := NewAuditLogStore(tx)
+
+	workspaceID := uuid.New()
+	otherWorkspaceID := uuid.New()
+	actorID := uuid.New()
+	now := time.Now()
+
+	newLog := func(workspaceID uuid.UUID, action model.AuditAction, createdAt time.Time) {
+		assert.NoError(t, auditStore.Create(ctx, &model.AuditLog{
+			WorkspaceID: &workspaceID,
+			ActorID:     &actorID,
+			Action:      action,
+			TargetType:  model.AuditTargetTeam,
+			TargetID:    "team-1",
+			CreatedAt:   createdAt,
+		}))
+	}
+	newLog(workspaceID, model.AuditTeamCreated, now.Add(-3*time.Hour))
+	newLog(workspaceID, model.AuditTeamDeleted, now.Add(-2*time.Hour))
+	newLog(workspaceID, model.AuditLoginSucceeded, now.Add(-time.Hour))
+	newLog(otherWorkspaceID, model.AuditTeamCreated, now)
+
+	// 只返回指定工作区的记录，最新在前
+	logs, total, err := auditStore.List(ctx, &AuditLogFilter{WorkspaceID: workspaceID}, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	if assert.Len(t, logs, 3) {
+		assert.Equal(t, model.AuditLoginSucceeded, logs[0].Action)
+	}
+
+	// 按事件类型和时间过滤
+	since := now.Add(-150 * time.Minute)
+	_, total, err = auditStore.List(ctx, &AuditLogFilter{
+		WorkspaceID: workspaceID,
+		Actions:     []model.AuditAction{model.AuditTeamCreated, model.AuditTeamDeleted},
+		Since:       &since,
+	}, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+
+	// 分批遍历按时间顺序返回所有记录
+	var actions []model.AuditAction
+	err = auditStore.FindInBatches(ctx, &AuditLogFilter{WorkspaceID: workspaceID}, 2, func(logs []model.AuditLog) error {
+		for _, log := range logs {
+			actions = append(actions, log.Action)
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.AuditAction{model.AuditTeamCreated, model.AuditTeamDeleted, model.AuditLoginSucceeded}, actions)
+
+	// 保留策略清理过期记录
+	deleted, err := auditStore.DeleteBefore(ctx, now.Add(-90*time.Minute))
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, deleted, int64(2))
+	_, total, _ = auditStore.List(ctx, &AuditLogFilter{WorkspaceID: workspaceID}, 1, 10)
+	assert.Equal(t, int64(1), total)
+}
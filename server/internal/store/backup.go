@@ -0,0 +1,140 @@
+// Package store 提供数据访问层
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+// backupIDBatchSize 查询已有 ID 时每批的数量
+const backupIDBatchSize = 1000
+
+// backupTableRegex 表名只能由小写字母和下划线组成（表名会直接拼入 SQL）
+var backupTableRegex = regexp.MustCompile(`^[a-z_]+$`)
+
+// BackupStore 定义工作区备份恢复的数据访问接口
+// 以整行 JSON（row_to_json / json_populate_record）读写，不经过模型，备份内容与数据库结构完全一致
+type BackupStore interface {
+	// SchemaVersion 读取 golang-migrate 记录的迁移版本
+	SchemaVersion(ctx context.Context) (version uint, dirty bool, err error)
+	// ExportRows 逐行读取表中符合条件的记录，where 中可以使用 sql.Named 参数
+	ExportRows(ctx context.Context, table, where string, args []interface{}, fn func(row json.RawMessage) error) error
+	// ExistingIDs 返回 ids 中已存在于表中的 ID
+	ExistingIDs(ctx context.Context, table string, ids []string) (map[string]bool, error)
+	// InsertRow 插入一行，row 中缺少的列为 NULL
+	InsertRow(ctx context.Context, table string, row json.RawMessage) error
+	// Snapshot 在只读的可重复读事务中执行，保证导出的各表数据一致
+	Snapshot(ctx context.Context, fn func(tx BackupStore) error) error
+	// Transaction 在事务中执行
+	Transaction(ctx context.Context, fn func(tx BackupStore) error) error
+}
+
+// backupStore 实现 BackupStore 接口
+type backupStore struct {
+	db *gorm.DB
+}
+
+// NewBackupStore 创建备份存储实例
+func NewBackupStore(db *gorm.DB) BackupStore {
+	return &backupStore{db: db}
+}
+
+// SchemaVersion 读取迁移版本
+func (s *backupStore) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	var state struct {
+		Version uint
+		Dirty   bool
+	}
+	result := s.db.WithContext(ctx).Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&state)
+	if result.Error != nil {
+		return 0, false, fmt.Errorf("读取迁移版本失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return 0, false, errors.New("尚未执行数据库迁移")
+	}
+	return state.Version, state.Dirty, nil
+}
+
+// ExportRows 逐行读取表中符合条件的记录
+func (s *backupStore) ExportRows(ctx context.Context, table, where string, args []interface{}, fn func(row json.RawMessage) error) error {
+	if !backupTableRegex.MatchString(table) {
+		return fmt.Errorf("无效的表名 %q", table)
+	}
+	rows, err := s.db.WithContext(ctx).
+		Raw(fmt.Sprintf("SELECT row_to_json(t) FROM %s t WHERE %s", table, where), args...).
+		Rows()
+	if err != nil {
+		return fmt.Errorf("读取 %s 失败: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row []byte
+		if err := rows.Scan(&row); err != nil {
+			return fmt.Errorf("读取 %s 失败: %w", table, err)
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("读取 %s 失败: %w", table, err)
+	}
+	return nil
+}
+
+// ExistingIDs 分批查询已存在的 ID
+func (s *backupStore) ExistingIDs(ctx context.Context, table string, ids []string) (map[string]bool, error) {
+	if !backupTableRegex.MatchString(table) {
+		return nil, fmt.Errorf("无效的表名 %q", table)
+	}
+	existing := make(map[string]bool)
+	for start := 0; start < len(ids); start += backupIDBatchSize {
+		end := min(start+backupIDBatchSize, len(ids))
+		var found []string
+		err := s.db.WithContext(ctx).
+			Raw(fmt.Sprintf("SELECT id::text FROM %s WHERE id IN ?", table), ids[start:end]).
+			Scan(&found).Error
+		if err != nil {
+			return nil, fmt.Errorf("查询 %s 失败: %w", table, err)
+		}
+		for _, id := range found {
+			existing[id] = true
+		}
+	}
+	return existing, nil
+}
+
+// InsertRow 通过 json_populate_record 按表结构还原一行
+func (s *backupStore) InsertRow(ctx context.Context, table string, row json.RawMessage) error {
+	if !backupTableRegex.MatchString(table) {
+		return fmt.Errorf("无效的表名 %q", table)
+	}
+	err := s.db.WithContext(ctx).
+		Exec(fmt.Sprintf("INSERT INTO %s SELECT * FROM json_populate_record(NULL::%s, CAST(? AS json))", table, table), string(row)).
+		Error
+	if err != nil {
+		return fmt.Errorf("写入 %s 失败: %w", table, err)
+	}
+	return nil
+}
+
+// Snapshot 在只读的可重复读事务中执行
+func (s *backupStore) Snapshot(ctx context.Context, fn func(tx BackupStore) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&backupStore{db: tx})
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+}
+
+// Transaction 在事务中执行
+func (s *backupStore) Transaction(ctx context.Context, fn func(tx BackupStore) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&backupStore{db: tx})
+	})
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBackupStore_Interface 测试 BackupStore 接口定义存在
+func TestBackupStore_Interface(t *testing.T) {
+	var _ BackupStore = (*backupStore)(nil)
+}
+
+func TestBackupStore_ExportAndInsert(t *testing.T) {
+	if testDB == nil {
+		t.Skip("数据库连接不可用")
+	}
+
+	tx := testDB.Begin()
+	defer tx.Rollback()
+
+	ctx := context.Background()
+	backupStore := NewBackupStore(tx)
+
+	description := "原始团队"
+	team := &model.Team{WorkspaceID: testWorkspaceID, Name: "Backup", Key: "BAK", Description: description, IsPrivate: true}
+	require.NoError(t, tx.Create(team).Error)
+
+	// 导出整行 JSON
+	var rows []map[string]interface{}
+	err := backupStore.ExportRows(ctx, "teams", "id = @team", []interface{}{sql.Named("team", team.ID)}, func(row json.RawMessage) error {
+		var values map[string]interface{}
+		if err := json.Unmarshal(row, &values); err != nil {
+			return err
+		}
+		rows = append(rows, values)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "BAK", rows[0]["key"])
+	assert.Equal(t, true, rows[0]["is_private"])
+
+	// 换一个 ID 后写回，其余列保持一致
+	copied := rows[0]
+	newID := uuid.New()
+	copied["id"] = newID.String()
+	copied["key"] = "BAK2"
+	data, _ := json.Marshal(copied)
+	require.NoError(t, backupStore.InsertRow(ctx, "teams", data))
+
+	var restored model.Team
+	require.NoError(t, tx.First(&restored, "id = ?", newID).Error)
+	assert.Equal(t, "Backup", restored.Name)
+	assert.Equal(t, description, restored.Description)
+	assert.True(t, restored.IsPrivate)
+	assert.WithinDuration(t, team.CreatedAt, restored.CreatedAt, 0)
+
+	existing, err := backupStore.ExistingIDs(ctx, "teams", []string{team.ID.String(), newID.String(), uuid.NewString()})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{team.ID.String(): true, newID.String(): true}, existing)
+}
+
+func TestBackupStore_InvalidTable(t *testing.T) {
+	backupStore := NewBackupStore(nil)
+	ctx := context.Background()
+
+	assert.Error(t, backupStore.InsertRow(ctx, "teams; DROP TABLE users", json.RawMessage(`{}`)))
+	_, err := backupStore.ExistingIDs(ctx, "Teams", nil)
+	assert.Error(t, err)
+	assert.Error(t, backupStore.ExportRows(ctx, "teams t, users", "true", nil, nil))
+}
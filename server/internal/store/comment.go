@@ -172,3 +172,17 @@ func (s *commentStore) DeleteComment(ctx context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+// CommentTeamID 获取评论所在 Issue 所属的团队，评论不存在时返回 gorm.ErrRecordNotFound
+func CommentTeamID(ctx context.Context, db *gorm.DB, commentID uuid.UUID) (uuid.UUID, error) {
+	var issue model.Issue
+	err := db.WithContext(ctx).Unscoped().
+		Select("issues.team_id").
+		Joins("JOIN comments ON comments.issue_id = issues.id").
+		Where("comments.id = ?", commentID).
+		First(&issue).Error
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return issue.TeamID, nil
+}
@@ -0,0 +1,78 @@
+// Package store 提供数据访问层
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"gorm.io/gorm"
+)
+
+// ExternalReferenceStore 定义外部引用数据访问接口
+type ExternalReferenceStore interface {
+	// Find 查找外部引用，不存在时返回 nil
+	Find(ctx context.Context, teamID uuid.UUID, source, entityType, externalID string) (*model.ExternalReference, error)
+	// CreateIssue 在同一事务中创建 Issue 及其外部引用
+	CreateIssue(ctx context.Context, issue *model.Issue, ref *model.ExternalReference) error
+	// CreateComment 在同一事务中创建评论及其外部引用
+	CreateComment(ctx context.Context, comment *model.Comment, ref *model.ExternalReference) error
+}
+
+// externalReferenceStore 实现 ExternalReferenceStore 接口
+type externalReferenceStore struct {
+	db *gorm.DB
+}
+
+// NewExternalReferenceStore 创建外部引用存储实例
+func NewExternalReferenceStore(db *gorm.DB) ExternalReferenceStore {
+	return &externalReferenceStore{db: db}
+}
+
+// Find 查找外部引用，不存在时返回 nil
+func (s *externalReferenceStore) Find(ctx context.Context, teamID uuid.UUID, source, entityType, externalID string) (*model.ExternalReference, error) {
+	var ref model.ExternalReference
+	err := s.db.WithContext(ctx).
+		Where("team_id = ? AND source = ? AND entity_type = ? AND external_id = ?", teamID, source, entityType, externalID).
+		First(&ref).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询外部引用失败: %w", err)
+	}
+	return &ref, nil
+}
+
+// CreateIssue 在同一事务中创建 Issue 及其外部引用
+// 保证任务中断后重新执行时不会出现"Issue 已创建但引用缺失"导致的重复导入
+func (s *externalReferenceStore) CreateIssue(ctx context.Context, issue *model.Issue, ref *model.ExternalReference) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := NewIssueStore(tx).Create(ctx, issue); err != nil {
+			return err
+		}
+		ref.EntityType = model.ExternalEntityIssue
+		ref.EntityID = issue.ID
+		if err := tx.Create(ref).Error; err != nil {
+			return fmt.Errorf("创建外部引用失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// CreateComment 在同一事务中创建评论及其外部引用
+func (s *externalReferenceStore) CreateComment(ctx context.Context, comment *model.Comment, ref *model.ExternalReference) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return fmt.Errorf("创建评论失败: %w", err)
+		}
+		ref.EntityType = model.ExternalEntityComment
+		ref.EntityID = comment.ID
+		if err := tx.Create(ref).Error; err != nil {
+			return fmt.Errorf("创建外部引用失败: %w", err)
+		}
+		return nil
+	})
+}
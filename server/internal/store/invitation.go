@@ -0,0 +1,174 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"gorm.io/gorm"
+)
+
+// InvitationStore 定义工作区邀请数据访问接口
+type InvitationStore interface {
+	// Create 创建邀请
+	Create(ctx context.Context, invitation *model.Invitation) error
+	// GetByID 根据 ID 获取邀请
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Invitation, error)
+	// GetByTokenHash 根据令牌摘要获取邀请
+	GetByTokenHash(ctx context.Context, tokenHash string) (*model.Invitation, error)
+	// GetPendingByEmail 获取工作区内指定邮箱待接受的邀请，不存在时返回 gorm.ErrRecordNotFound
+	GetPendingByEmail(ctx context.Context, workspaceID uuid.UUID, email string) (*model.Invitation, error)
+	// ListPending 获取工作区内待接受的邀请（按创建时间倒序）
+	ListPending(ctx context.Context, workspaceID uuid.UUID) ([]model.Invitation, error)
+	// UpdateToken 更换邀请令牌并延长有效期（重新发送）
+	UpdateToken(ctx context.Context, invitation *model.Invitation) error
+	// MarkSent 记录邀请邮件发送时间
+	MarkSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error
+	// Accept 记录一次邀请使用；指定邮箱的邀请同时标记为已接受
+	// 邀请已撤销、已过期或已被接受时返回 false
+	Accept(ctx context.Context, id uuid.UUID) (bool, error)
+	// Revoke 撤销邀请，已撤销的邀请不会重复更新
+	Revoke(ctx context.Context, id uuid.UUID) error
+	// RevokePendingByEmail 撤销工作区内指定邮箱待接受的邀请
+	RevokePendingByEmail(ctx context.Context, workspaceID uuid.UUID, email string) error
+}
+
+// invitationStore 实现 InvitationStore 接口
+type invitationStore struct {
+	db *gorm.DB
+}
+
+// NewInvitationStore 创建邀请存储实例
+func NewInvitationStore(db *gorm.DB) InvitationStore {
+	return &invitationStore{db: db}
+}
+
+// pendingInvitations 待接受邀请的查询条件
+func pendingInvitations(db *gorm.DB) *gorm.DB {
+	return db.Where("revoked_at IS NULL AND expires_at > ? AND (email = '' OR accepted_at IS NULL)", time.Now())
+}
+
+// Create 创建邀请
+func (s *invitationStore) Create(ctx context.Context, invitation *model.Invitation) error {
+	if err := s.db.WithContext(ctx).Create(invitation).Error; err != nil {
+		return fmt.Errorf("创建邀请失败: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取邀请
+func (s *invitationStore) GetByID(ctx context.Context, id uuid.UUID) (*model.Invitation, error) {
+	var invitation model.Invitation
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&invitation).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// GetByTokenHash 根据令牌摘要获取邀请
+func (s *invitationStore) GetByTokenHash(ctx context.Context, tokenHash string) (*model.Invitation, error) {
+	var invitation model.Invitation
+	if err := s.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&invitation).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// GetPendingByEmail 获取工作区内指定邮箱待接受的邀请
+func (s *invitationStore) GetPendingByEmail(ctx context.Context, workspaceID uuid.UUID, email string) (*model.Invitation, error) {
+	var invitation model.Invitation
+	err := pendingInvitations(s.db.WithContext(ctx)).
+		Where("workspace_id = ? AND email = ?", workspaceID, email).
+		Order("created_at DESC").
+		First(&invitation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// ListPending 获取工作区内待接受的邀请（按创建时间倒序）
+func (s *invitationStore) ListPending(ctx context.Context, workspaceID uuid.UUID) ([]model.Invitation, error) {
+	var invitations []model.Invitation
+	err := pendingInvitations(s.db.WithContext(ctx)).
+		Where("workspace_id = ?", workspaceID).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, fmt.Errorf("获取邀请列表失败: %w", err)
+	}
+	return invitations, nil
+}
+
+// UpdateToken 更换邀请令牌并延长有效期
+func (s *invitationStore) UpdateToken(ctx context.Context, invitation *model.Invitation) error {
+	err := s.db.WithContext(ctx).Model(&model.Invitation{}).
+		Where("id = ?", invitation.ID).
+		Updates(map[string]interface{}{
+			"token_hash": invitation.TokenHash,
+			"expires_at": invitation.ExpiresAt,
+			"updated_at": time.Now(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("更新邀请失败: %w", err)
+	}
+	return nil
+}
+
+// MarkSent 记录邀请邮件发送时间
+func (s *invitationStore) MarkSent(ctx context.Context, id uuid.UUID, sentAt time.Time) error {
+	err := s.db.WithContext(ctx).Model(&model.Invitation{}).
+		Where("id = ?", id).
+		UpdateColumn("sent_at", sentAt).Error
+	if err != nil {
+		return fmt.Errorf("更新邀请失败: %w", err)
+	}
+	return nil
+}
+
+// Accept 记录一次邀请使用
+// 使用条件更新，指定邮箱的邀请并发使用时只有一个请求成功
+func (s *invitationStore) Accept(ctx context.Context, id uuid.UUID) (bool, error) {
+	now := time.Now()
+	result := pendingInvitations(s.db.WithContext(ctx).Model(&model.Invitation{})).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"use_count":   gorm.Expr("use_count + 1"),
+			"accepted_at": gorm.Expr("CASE WHEN email = '' THEN accepted_at ELSE ? END", now),
+			"updated_at":  now,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("接受邀请失败: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Revoke 撤销邀请，已撤销的邀请不会重复更新
+func (s *invitationStore) Revoke(ctx context.Context, id uuid.UUID) error {
+	err := s.db.WithContext(ctx).Model(&model.Invitation{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at": time.Now(),
+			"updated_at": time.Now(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("撤销邀请失败: %w", err)
+	}
+	return nil
+}
+
+// RevokePendingByEmail 撤销工作区内指定邮箱待接受的邀请
+func (s *invitationStore) RevokePendingByEmail(ctx context.Context, workspaceID uuid.UUID, email string) error {
+	err := pendingInvitations(s.db.WithContext(ctx).Model(&model.Invitation{})).
+		Where("workspace_id = ? AND email = ?", workspaceID, email).
+		Updates(map[string]interface{}{
+			"revoked_at": time.Now(),
+			"updated_at": time.Now(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("撤销邀请失败: %w", err)
+	}
+	return nil
+}
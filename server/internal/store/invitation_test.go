@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestInvitationStore(t *testing.T) {
+	if testDB == nil {
+		t.Skip("数据库连接不可用")
+	}
+
+	tx := testDB.Begin()
+	defer tx.Rollback()
+
+	ctx := context.Background()
+	invitationStore := NewInvitationStore(tx)
+	workspace, user, team, _ := setupIssueTestFixtures(t, tx)
+
+	newInvitation := func(email string, expiresIn time.Duration) *model.Invitation {
+		invitation := &model.Invitation{
+			WorkspaceID: workspace.ID,
+			Email:       email,
+			Role:        model.RoleMember,
+			TeamIDs:     []string{team.ID.String()},
+			TokenHash:   uuid.New().String(),
+			InvitedByID: &user.ID,
+			ExpiresAt:   time.Now().Add(expiresIn),
+		}
+		assert.NoError(t, invitationStore.Create(ctx, invitation))
+		return invitation
+	}
+	emailInvite := newInvitation("alice@example.com", time.Hour)
+	link := newInvitation("", time.Hour)
+	newInvitation("expired@example.com", -time.Minute)
+
+	pending, err := invitationStore.ListPending(ctx, workspace.ID)
+	assert.NoError(t, err)
+	assert.Len(t, pending, 2)
+
+	got, err := invitationStore.GetByTokenHash(ctx, emailInvite.TokenHash)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{team.ID.String()}, []string(got.TeamIDs))
+
+	got, err = invitationStore.GetPendingByEmail(ctx, workspace.ID, "alice@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, emailInvite.ID, got.ID)
+	_, err = invitationStore.GetPendingByEmail(ctx, workspace.ID, "expired@example.com")
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	// 指定邮箱的邀请只能接受一次
+	ok, err := invitationStore.Accept(ctx, emailInvite.ID)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = invitationStore.Accept(ctx, emailInvite.ID)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// 邀请链接可多次使用
+	for i := 0; i < 2; i++ {
+		ok, err = invitationStore.Accept(ctx, link.ID)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+	got, _ = invitationStore.GetByID(ctx, link.ID)
+	assert.Equal(t, 2, got.UseCount)
+	assert.Nil(t, got.AcceptedAt)
+
+	// 撤销后不可使用
+	assert.NoError(t, invitationStore.Revoke(ctx, link.ID))
+	ok, err = invitationStore.Accept(ctx, link.ID)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// 按邮箱撤销
+	bob := newInvitation("bob@example.com", time.Hour)
+	assert.NoError(t, invitationStore.RevokePendingByEmail(ctx, workspace.ID, "bob@example.com"))
+	got, _ = invitationStore.GetByID(ctx, bob.ID)
+	assert.NotNil(t, got.RevokedAt)
+}
@@ -3,6 +3,8 @@ package store
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -10,6 +12,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrIssueParentCycle 父 Issue 是自身或自身的子 Issue
+var ErrIssueParentCycle = errors.New("不能将 Issue 设为自身或其子 Issue 的子 Issue")
+
 // IssueFilter Issue 列表过滤条件
 type IssueFilter struct {
 	StatusID    *uuid.UUID
@@ -19,6 +24,17 @@ type IssueFilter struct {
 	CycleID     *uuid.UUID
 	LabelIDs    []uuid.UUID
 	CreatedByID *uuid.UUID
+	// VisibleTo 只返回该用户可见团队的 Issue（不包括用户不在其中的私有团队）
+	VisibleTo *uuid.UUID
+}
+
+// IssueMove 单个 Issue 的跨团队移动
+type IssueMove struct {
+	// Issue 已设置目标 TeamID、StatusID、Labels 等字段，Number 由存储层分配
+	Issue *model.Issue
+	// OldTeamKey / OldNumber 移动前的标识符，保留为历史标识符
+	OldTeamKey string
+	OldNumber  int
 }
 
 // IssueStore 定义 Issue 数据访问接口
@@ -27,6 +43,11 @@ type IssueStore interface {
 	Create(ctx context.Context, issue *model.Issue) error
 	// GetByID 通过 ID 获取 Issue（预加载关联）
 	GetByID(ctx context.Context, id uuid.UUID) (*model.Issue, error)
+	// GetByIdentifier 通过团队 Key 和编号获取工作区内的 Issue（如 ENG-123）
+	// workspaceID 为 uuid.Nil 时在所有工作区中查找，匹配到多个时返回 ErrIdentifierAmbiguous
+	GetByIdentifier(ctx context.Context, workspaceID uuid.UUID, teamKey string, number int) (*model.Issue, error)
+	// ResolveIdentifier 通过当前或历史标识符获取工作区内的 Issue
+	ResolveIdentifier(ctx context.Context, workspaceID uuid.UUID, teamKey string, number int) (*model.Issue, error)
 	// List 获取 Issue 列表（支持过滤和分页）
 	List(ctx context.Context, teamID uuid.UUID, filter *IssueFilter, page, pageSize int) ([]model.Issue, int64, error)
 	// Update 更新 Issue
@@ -41,6 +62,16 @@ type IssueStore interface {
 	GetMaxNumber(ctx context.Context, teamID uuid.UUID) (int, error)
 	// ListBySubscription 获取用户订阅的 Issue 列表
 	ListBySubscription(ctx context.Context, userID uuid.UUID) ([]model.Issue, error)
+	// UpdateParent 设置父 Issue（parentID 为 nil 时取消），并同步更新闭包表；形成环时返回 ErrIssueParentCycle
+	UpdateParent(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) error
+	// ListChildren 获取直接子 Issue
+	ListChildren(ctx context.Context, parentID uuid.UUID) ([]model.Issue, error)
+	// MoveToTeams 在同一事务中将 Issue 移动到其他团队（分配新 Number 并保留旧标识符）
+	MoveToTeams(ctx context.Context, moves []IssueMove) error
+	// FindInBatches 按批次遍历团队内符合条件的 Issue（用于导出等大批量场景）
+	FindInBatches(ctx context.Context, teamID uuid.UUID, filter *IssueFilter, batchSize int, fn func(issues []model.Issue) error) error
+	// RebuildClosure 根据 parent_id 重建 Issue 层级闭包表，返回写入的行数
+	RebuildClosure(ctx context.Context) (int64, error)
 }
 
 // issueStore 实现 IssueStore 接口
@@ -56,18 +87,12 @@ func NewIssueStore(db *gorm.DB) IssueStore {
 // Create 创建 Issue（在事务中自动生成 Number）
 func (s *issueStore) Create(ctx context.Context, issue *model.Issue) error {
 	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		// 获取团队内最大 Number
-		var maxNumber int
-		err := tx.Model(&model.Issue{}).
-			Where("team_id = ?", issue.TeamID).
-			Select("COALESCE(MAX(number), 0)").
-			Scan(&maxNumber).Error
+		// 设置新 Number
+		number, err := nextIssueNumber(tx, issue.TeamID)
 		if err != nil {
-			return fmt.Errorf("获取最大 Number 失败: %w", err)
+			return err
 		}
-
-		// 设置新 Number
-		issue.Number = maxNumber + 1
+		issue.Number = number
 
 		// 如果 Position 为 0，设置默认值
 		if issue.Position == 0 {
@@ -83,6 +108,37 @@ func (s *issueStore) Create(ctx context.Context, issue *model.Issue) error {
 	})
 }
 
+// nextIssueNumber 获取团队内下一个可用的 Issue Number
+// 已移出团队的 Issue 保留了旧标识符，其编号不能再分配给新 Issue
+func nextIssueNumber(tx *gorm.DB, teamID uuid.UUID) (int, error) {
+	var maxNumber int
+	err := tx.Model(&model.Issue{}).
+		Where("team_id = ?", teamID).
+		Select("COALESCE(MAX(number), 0)").
+		Scan(&maxNumber).Error
+	if err != nil {
+		return 0, fmt.Errorf("获取最大 Number 失败: %w", err)
+	}
+
+	var maxAlias int
+	var team model.Team
+	if err := tx.Select("workspace_id", "key").Where("id = ?", teamID).First(&team).Error; err != nil {
+		return 0, fmt.Errorf("获取团队失败: %w", err)
+	}
+	err = tx.Model(&model.IssueIdentifierAlias{}).
+		Where("workspace_id = ? AND team_key = ?", team.WorkspaceID, team.Key).
+		Select("COALESCE(MAX(number), 0)").
+		Scan(&maxAlias).Error
+	if err != nil {
+		return 0, fmt.Errorf("获取最大 Number 失败: %w", err)
+	}
+
+	if maxAlias > maxNumber {
+		maxNumber = maxAlias
+	}
+	return maxNumber + 1, nil
+}
+
 // GetByID 通过 ID 获取 Issue（预加载关联）
 func (s *issueStore) GetByID(ctx context.Context, id uuid.UUID) (*model.Issue, error) {
 	var issue model.Issue
@@ -100,6 +156,38 @@ func (s *issueStore) GetByID(ctx context.Context, id uuid.UUID) (*model.Issue, e
 	return &issue, nil
 }
 
+// GetByIdentifier 通过团队 Key 和编号获取工作区内的 Issue（如 ENG-123）
+func (s *issueStore) GetByIdentifier(ctx context.Context, workspaceID uuid.UUID, teamKey string, number int) (*model.Issue, error) {
+	query := s.db.WithContext(ctx).
+		Preload("Team").
+		Preload("Status").
+		Joins("JOIN teams ON teams.id = issues.team_id").
+		Where("teams.key = ? AND issues.number = ?", teamKey, number)
+	if workspaceID != uuid.Nil {
+		query = query.Where("teams.workspace_id = ?", workspaceID)
+	}
+	var issues []model.Issue
+	if err := query.Limit(2).Find(&issues).Error; err != nil {
+		return nil, err
+	}
+	if len(issues) > 1 {
+		return nil, ErrIdentifierAmbiguous
+	}
+	if len(issues) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &issues[0], nil
+}
+
+// ResolveIdentifier 通过当前或历史标识符获取工作区内的 Issue
+func (s *issueStore) ResolveIdentifier(ctx context.Context, workspaceID uuid.UUID, teamKey string, number int) (*model.Issue, error) {
+	id, err := ResolveIssueIdentifier(ctx, s.db, workspaceID, teamKey, number)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, id)
+}
+
 // List 获取 Issue 列表（支持过滤和分页）
 func (s *issueStore) List(ctx context.Context, teamID uuid.UUID, filter *IssueFilter, page, pageSize int) ([]model.Issue, int64, error) {
 	var issues []model.Issue
@@ -109,30 +197,7 @@ func (s *issueStore) List(ctx context.Context, teamID uuid.UUID, filter *IssueFi
 	query := s.db.WithContext(ctx).Model(&model.Issue{}).Where("team_id = ?", teamID)
 
 	// 应用过滤条件
-	if filter != nil {
-		if filter.StatusID != nil {
-			query = query.Where("status_id = ?", filter.StatusID)
-		}
-		if filter.Priority != nil {
-			query = query.Where("priority = ?", filter.Priority)
-		}
-		if filter.AssigneeID != nil {
-			query = query.Where("assignee_id = ?", filter.AssigneeID)
-		}
-		if filter.ProjectID != nil {
-			query = query.Where("project_id = ?", filter.ProjectID)
-		}
-		if filter.CycleID != nil {
-			query = query.Where("cycle_id = ?", filter.CycleID)
-		}
-		if filter.CreatedByID != nil {
-			query = query.Where("created_by_id = ?", filter.CreatedByID)
-		}
-		if len(filter.LabelIDs) > 0 {
-			// 使用数组重叠查询
-			query = query.Where("labels && ?", filter.LabelIDs)
-		}
-	}
+	query = applyIssueFilter(query, filter)
 
 	// 统计总数
 	if err := query.Count(&total).Error; err != nil {
@@ -219,6 +284,38 @@ func (s *issueStore) GetMaxNumber(ctx context.Context, teamID uuid.UUID) (int, e
 	return maxNumber, nil
 }
 
+// rebuildClosureSQL 从每个未删除的 Issue 出发沿 parent_id 向下展开；
+// 限制深度并忽略重复行，避免错误数据中的环导致无限递归
+const rebuildClosureSQL = `
+WITH RECURSIVE tree AS (
+	SELECT id AS ancestor_id, id AS descendant_id, 0 AS depth
+	FROM issues WHERE deleted_at IS NULL
+	UNION ALL
+	SELECT tree.ancestor_id, issues.id, tree.depth + 1
+	FROM tree JOIN issues ON issues.parent_id = tree.descendant_id
+	WHERE issues.deleted_at IS NULL AND tree.depth < 100
+)
+INSERT INTO issue_closure (ancestor_id, descendant_id, depth)
+SELECT ancestor_id, descendant_id, MIN(depth) FROM tree
+GROUP BY ancestor_id, descendant_id`
+
+// RebuildClosure 在事务中清空并重建闭包表
+func (s *issueStore) RebuildClosure(ctx context.Context) (int64, error) {
+	var rows int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM issue_closure").Error; err != nil {
+			return fmt.Errorf("清空闭包表失败: %w", err)
+		}
+		result := tx.Exec(rebuildClosureSQL)
+		if result.Error != nil {
+			return fmt.Errorf("重建闭包表失败: %w", result.Error)
+		}
+		rows = result.RowsAffected
+		return nil
+	})
+	return rows, err
+}
+
 // ListBySubscription 获取用户订阅的 Issue 列表
 func (s *issueStore) ListBySubscription(ctx context.Context, userID uuid.UUID) ([]model.Issue, error) {
 	var issues []model.Issue
@@ -238,3 +335,209 @@ func (s *issueStore) ListBySubscription(ctx context.Context, userID uuid.UUID) (
 
 	return issues, nil
 }
+
+// issueAncestorsSQL 沿 parent_id 向上查找 @parent 及其所有祖先
+const issueAncestorsSQL = `
+WITH RECURSIVE up AS (
+	SELECT id, parent_id, 0 AS depth FROM issues WHERE id = @parent
+	UNION ALL
+	SELECT issues.id, issues.parent_id, up.depth + 1
+	FROM up JOIN issues ON issues.id = up.parent_id
+	WHERE up.depth < 100
+)
+SELECT COUNT(*) FROM up WHERE id = @id`
+
+// issueSubtreeSQL @id 及其所有未删除的后代
+const issueSubtreeSQL = `
+WITH RECURSIVE subtree AS (
+	SELECT id FROM issues WHERE id = @id
+	UNION
+	SELECT issues.id FROM issues JOIN subtree ON issues.parent_id = subtree.id
+	WHERE issues.deleted_at IS NULL
+)`
+
+// UpdateParent 设置父 Issue，并重新计算该 Issue 子树在闭包表中的祖先关系
+func (s *issueStore) UpdateParent(ctx context.Context, id uuid.UUID, parentID *uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if parentID != nil {
+			var cycles int64
+			if err := tx.Raw(issueAncestorsSQL, sql.Named("parent", *parentID), sql.Named("id", id)).Scan(&cycles).Error; err != nil {
+				return fmt.Errorf("检查父 Issue 失败: %w", err)
+			}
+			if cycles > 0 {
+				return ErrIssueParentCycle
+			}
+		}
+
+		if err := tx.Model(&model.Issue{}).Where("id = ?", id).Update("parent_id", parentID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Exec(issueSubtreeSQL+`
+DELETE FROM issue_closure WHERE descendant_id IN (SELECT id FROM subtree)`, sql.Named("id", id)).Error; err != nil {
+			return fmt.Errorf("更新闭包表失败: %w", err)
+		}
+		err := tx.Exec(issueSubtreeSQL+`, up AS (
+	SELECT id AS descendant_id, id AS ancestor_id, parent_id, 0 AS depth
+	FROM issues WHERE id IN (SELECT id FROM subtree)
+	UNION ALL
+	SELECT up.descendant_id, issues.id, issues.parent_id, up.depth + 1
+	FROM up JOIN issues ON issues.id = up.parent_id
+	WHERE issues.deleted_at IS NULL AND up.depth < 100
+)
+INSERT INTO issue_closure (ancestor_id, descendant_id, depth)
+SELECT ancestor_id, descendant_id, MIN(depth) FROM up
+GROUP BY ancestor_id, descendant_id`, sql.Named("id", id)).Error
+		if err != nil {
+			return fmt.Errorf("更新闭包表失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// FindInBatches 按批次遍历团队内符合条件的 Issue（用于导出等大批量场景）
+func (s *issueStore) FindInBatches(ctx context.Context, teamID uuid.UUID, filter *IssueFilter, batchSize int, fn func(issues []model.Issue) error) error {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	query := s.db.WithContext(ctx).Model(&model.Issue{}).Where("team_id = ?", teamID)
+	query = applyIssueFilter(query, filter)
+
+	// 按 Number 做游标分页，避免大偏移量带来的性能问题
+	lastNumber := 0
+	for {
+		var issues []model.Issue
+		err := query.Session(&gorm.Session{}).
+			Preload("Team").
+			Preload("Status").
+			Preload("Assignee").
+			Where("number > ?", lastNumber).
+			Order("number ASC").
+			Limit(batchSize).
+			Find(&issues).Error
+		if err != nil {
+			return fmt.Errorf("批量查询 Issue 失败: %w", err)
+		}
+		if len(issues) == 0 {
+			return nil
+		}
+
+		if err := fn(issues); err != nil {
+			return err
+		}
+
+		if len(issues) < batchSize {
+			return nil
+		}
+		lastNumber = issues[len(issues)-1].Number
+	}
+}
+
+// applyIssueFilter 将过滤条件应用到查询上
+func applyIssueFilter(query *gorm.DB, filter *IssueFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+	if filter.StatusID != nil {
+		query = query.Where("status_id = ?", filter.StatusID)
+	}
+	if filter.Priority != nil {
+		query = query.Where("priority = ?", filter.Priority)
+	}
+	if filter.AssigneeID != nil {
+		query = query.Where("assignee_id = ?", filter.AssigneeID)
+	}
+	if filter.ProjectID != nil {
+		query = query.Where("project_id = ?", filter.ProjectID)
+	}
+	if filter.CycleID != nil {
+		query = query.Where("cycle_id = ?", filter.CycleID)
+	}
+	if filter.CreatedByID != nil {
+		query = query.Where("created_by_id = ?", filter.CreatedByID)
+	}
+	if len(filter.LabelIDs) > 0 {
+		// 使用数组重叠查询
+		query = query.Where("labels && ?", filter.LabelIDs)
+	}
+	if filter.VisibleTo != nil {
+		query = query.Where("team_id IN (?)", visibleTeamIDs(query.Session(&gorm.Session{NewDB: true}), filter.VisibleTo.String()))
+	}
+	return query
+}
+
+// ListChildren 获取直接子 Issue
+func (s *issueStore) ListChildren(ctx context.Context, parentID uuid.UUID) ([]model.Issue, error) {
+	var issues []model.Issue
+	err := s.db.WithContext(ctx).
+		Preload("Team").
+		Preload("Status").
+		Where("parent_id = ?", parentID).
+		Order("number ASC").
+		Find(&issues).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询子 Issue 失败: %w", err)
+	}
+	return issues, nil
+}
+
+// MoveToTeams 在同一事务中将 Issue 移动到其他团队（分配新 Number 并保留旧标识符）
+func (s *issueStore) MoveToTeams(ctx context.Context, moves []IssueMove) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, move := range moves {
+			issue := move.Issue
+
+			number, err := nextIssueNumber(tx, issue.TeamID)
+			if err != nil {
+				return err
+			}
+			issue.Number = number
+
+			err = tx.Model(issue).Select(
+				"TeamID",
+				"Number",
+				"StatusID",
+				"Labels",
+				"CycleID",
+				"CompletedAt",
+				"CancelledAt",
+			).Updates(issue).Error
+			if err != nil {
+				return fmt.Errorf("移动 Issue 失败: %w", err)
+			}
+
+			// Issue 只能在同一工作区内移动，历史标识符属于目标团队所在的工作区
+			var workspaceID uuid.UUID
+			if err := tx.Model(&model.Team{}).Where("id = ?", issue.TeamID).Select("workspace_id").Scan(&workspaceID).Error; err != nil {
+				return fmt.Errorf("获取团队失败: %w", err)
+			}
+
+			// 旧标识符指向移动后的 Issue（同一标识符只保留最近一次）
+			if err := tx.Where("workspace_id = ? AND team_key = ? AND number = ?", workspaceID, move.OldTeamKey, move.OldNumber).
+				Delete(&model.IssueIdentifierAlias{}).Error; err != nil {
+				return fmt.Errorf("更新历史标识符失败: %w", err)
+			}
+			alias := &model.IssueIdentifierAlias{
+				IssueID:     issue.ID,
+				WorkspaceID: workspaceID,
+				TeamKey:     move.OldTeamKey,
+				Number:      move.OldNumber,
+			}
+			if err := tx.Create(alias).Error; err != nil {
+				return fmt.Errorf("记录历史标识符失败: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// IssueTeamID 获取 Issue 所属的团队（包括已删除的 Issue），Issue 不存在时返回 gorm.ErrRecordNotFound
+func IssueTeamID(ctx context.Context, db *gorm.DB, issueID uuid.UUID) (uuid.UUID, error) {
+	var issue model.Issue
+	err := db.WithContext(ctx).Unscoped().Select("team_id").Where("id = ?", issueID).First(&issue).Error
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return issue.TeamID, nil
+}
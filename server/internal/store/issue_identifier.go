@@ -0,0 +1,91 @@
+// Package store 提供数据访问层
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"gorm.io/gorm"
+)
+
+// ErrIdentifierAmbiguous 未指定工作区时标识符匹配到多个工作区的 Issue，同时满足 errors.Is(err, gorm.ErrRecordNotFound)
+var ErrIdentifierAmbiguous = fmt.Errorf("标识符匹配到多个工作区的 Issue: %w", gorm.ErrRecordNotFound)
+
+// ResolveIssueIdentifier 将工作区内的 Issue 标识符（团队 Key + 编号）解析为 Issue ID
+//
+// 依次匹配：当前团队 Key 下的 Issue、Issue 的历史标识符（移动团队前）、
+// 团队的历史 Key（修改 Key 前）。当前标识符优先于历史标识符。
+// 包含已软删除的 Issue，以便恢复操作也能使用标识符。
+// 团队 Key 只在工作区内唯一，workspaceID 为 uuid.Nil 时在所有工作区中查找，
+// 匹配到多个工作区的 Issue 时返回 ErrIdentifierAmbiguous。
+// 找不到时返回 gorm.ErrRecordNotFound。
+func ResolveIssueIdentifier(ctx context.Context, db *gorm.DB, workspaceID uuid.UUID, teamKey string, number int) (uuid.UUID, error) {
+	id, err := resolveCurrentOrAlias(ctx, db, workspaceID, teamKey, number)
+	if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+		return id, err
+	}
+
+	// 团队历史 Key：换成团队当前 Key 后再解析
+	var teams []model.Team
+	query := db.WithContext(ctx).
+		Model(&model.Team{}).
+		Select("teams.workspace_id, teams.key").
+		Joins("JOIN team_key_aliases ON team_key_aliases.team_id = teams.id").
+		Where("team_key_aliases.key = ?", teamKey)
+	if workspaceID != uuid.Nil {
+		query = query.Where("team_key_aliases.workspace_id = ?", workspaceID)
+	}
+	if err := query.Limit(2).Find(&teams).Error; err != nil {
+		return uuid.Nil, err
+	}
+	if len(teams) != 1 || teams[0].Key == teamKey {
+		return uuid.Nil, gorm.ErrRecordNotFound
+	}
+	return resolveCurrentOrAlias(ctx, db, teams[0].WorkspaceID, teams[0].Key, number)
+}
+
+// resolveCurrentOrAlias 按当前标识符或 Issue 历史标识符查找 Issue ID
+func resolveCurrentOrAlias(ctx context.Context, db *gorm.DB, workspaceID uuid.UUID, teamKey string, number int) (uuid.UUID, error) {
+	var ids []uuid.UUID
+	query := db.WithContext(ctx).Unscoped().
+		Model(&model.Issue{}).
+		Joins("JOIN teams ON teams.id = issues.team_id").
+		Where("teams.key = ? AND issues.number = ?", teamKey, number)
+	if workspaceID != uuid.Nil {
+		query = query.Where("teams.workspace_id = ?", workspaceID)
+	}
+	if err := query.Limit(2).Pluck("issues.id", &ids).Error; err != nil {
+		return uuid.Nil, err
+	}
+	if len(ids) > 0 {
+		return uniqueID(ids)
+	}
+
+	query = db.WithContext(ctx).
+		Model(&model.IssueIdentifierAlias{}).
+		Where("team_key = ? AND number = ?", teamKey, number)
+	if workspaceID != uuid.Nil {
+		query = query.Where("workspace_id = ?", workspaceID)
+	}
+	if err := query.Limit(2).Pluck("issue_id", &ids).Error; err != nil {
+		return uuid.Nil, err
+	}
+	if len(ids) > 0 {
+		return uniqueID(ids)
+	}
+	return uuid.Nil, gorm.ErrRecordNotFound
+}
+
+// uniqueID 未指定工作区时，标识符可能匹配多个工作区的 Issue，此时无法确定目标
+func uniqueID(ids []uuid.UUID) (uuid.UUID, error) {
+	if len(ids) > 1 {
+		return uuid.Nil, ErrIdentifierAmbiguous
+	}
+	if len(ids) == 0 {
+		return uuid.Nil, gorm.ErrRecordNotFound
+	}
+	return ids[0], nil
+}
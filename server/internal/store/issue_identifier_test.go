@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestResolveIssueIdentifier(t *testing.T) {
+	if testDB == nil {
+		t.Skip("数据库连接不可用")
+	}
+
+	tx := testDB.Begin()
+	defer tx.Rollback()
+
+	ctx := context.Background()
+	issueStore := NewIssueStore(tx)
+	teamStore := NewTeamStore(tx)
+	workspace, user, team, state := setupIssueTestFixtures(t, tx)
+
+	// 使用符合格式的 Key
+	key := "R" + randomKeySuffix()
+	team.Key = key
+	assert.NoError(t, tx.Save(team).Error)
+
+	issue := &model.Issue{TeamID: team.ID, Title: "Resolve me", StatusID: state.ID, CreatedByID: user.ID}
+	assert.NoError(t, issueStore.Create(ctx, issue))
+
+	t.Run("当前标识符", func(t *testing.T) {
+		id, err := ResolveIssueIdentifier(ctx, tx, workspace.ID, key, issue.Number)
+		assert.NoError(t, err)
+		assert.Equal(t, issue.ID, id)
+	})
+
+	t.Run("不存在的编号", func(t *testing.T) {
+		_, err := ResolveIssueIdentifier(ctx, tx, workspace.ID, key, issue.Number+100)
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	})
+
+	t.Run("修改团队 Key 后旧标识符仍可解析", func(t *testing.T) {
+		newKey := "N" + randomKeySuffix()
+		team.Key = newKey
+		assert.NoError(t, teamStore.Update(ctx, team))
+
+		id, err := ResolveIssueIdentifier(ctx, tx, workspace.ID, key, issue.Number)
+		assert.NoError(t, err)
+		assert.Equal(t, issue.ID, id)
+
+		resolved, err := issueStore.ResolveIdentifier(ctx, workspace.ID, newKey, issue.Number)
+		assert.NoError(t, err)
+		assert.Equal(t, issue.ID, resolved.ID)
+
+		// 改回旧 Key 后不再保留该历史记录
+		team.Key = key
+		assert.NoError(t, teamStore.Update(ctx, team))
+		var count int64
+		tx.Model(&model.TeamKeyAlias{}).Where("team_id = ? AND key = ?", team.ID, key).Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("Issue 历史标识符", func(t *testing.T) {
+		assert.NoError(t, tx.Create(&model.IssueIdentifierAlias{IssueID: issue.ID, WorkspaceID: workspace.ID, TeamKey: "OLD" + randomKeySuffix()[:3], Number: 7}).Error)
+		var alias model.IssueIdentifierAlias
+		assert.NoError(t, tx.Where("issue_id = ?", issue.ID).First(&alias).Error)
+
+		id, err := ResolveIssueIdentifier(ctx, tx, workspace.ID, alias.TeamKey, 7)
+		assert.NoError(t, err)
+		assert.Equal(t, issue.ID, id)
+	})
+
+	t.Run("团队 Key 只在工作区内唯一", func(t *testing.T) {
+		other := &model.Workspace{Name: "Other WS", Slug: "other-ws-" + uuid.New().String()[:8]}
+		assert.NoError(t, tx.Create(other).Error)
+		otherTeam := &model.Team{WorkspaceID: other.ID, Name: "Other Team", Key: key}
+		assert.NoError(t, tx.Create(otherTeam).Error)
+		otherIssue := &model.Issue{TeamID: otherTeam.ID, Title: "Other", StatusID: state.ID, CreatedByID: user.ID}
+		assert.NoError(t, issueStore.Create(ctx, otherIssue))
+		assert.Equal(t, issue.Number, otherIssue.Number)
+
+		id, err := ResolveIssueIdentifier(ctx, tx, other.ID, key, otherIssue.Number)
+		assert.NoError(t, err)
+		assert.Equal(t, otherIssue.ID, id)
+		id, err = ResolveIssueIdentifier(ctx, tx, workspace.ID, key, issue.Number)
+		assert.NoError(t, err)
+		assert.Equal(t, issue.ID, id)
+
+		// 未指定工作区时标识符有歧义
+		_, err = ResolveIssueIdentifier(ctx, tx, uuid.Nil, key, issue.Number)
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+		assert.True(t, errors.Is(err, ErrIdentifierAmbiguous))
+		_, err = issueStore.GetByIdentifier(ctx, uuid.Nil, key, issue.Number)
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+		assert.True(t, errors.Is(err, ErrIdentifierAmbiguous))
+		found, err := issueStore.GetByIdentifier(ctx, other.ID, key, issue.Number)
+		assert.NoError(t, err)
+		assert.Equal(t, otherIssue.ID, found.ID)
+	})
+}
+
+// randomKeySuffix 生成随机的大写字母数字后缀（用于团队 Key）
+func randomKeySuffix() string {
+	const chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	b := uuid.New()
+	out := make([]byte, 6)
+	for i := range out {
+		out[i] = chars[int(b[i])%len(chars)]
+	}
+	return string(out)
+}
@@ -0,0 +1,73 @@
+// Package store 提供数据访问层
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"gorm.io/gorm"
+)
+
+// IssueLinkStore 定义 Issue 外部链接数据访问接口
+type IssueLinkStore interface {
+	// Upsert 创建或更新链接（按 Issue + URL 去重），返回是否为新建
+	Upsert(ctx context.Context, link *model.IssueLink) (bool, error)
+	// ListByIssue 获取 Issue 的所有链接
+	ListByIssue(ctx context.Context, issueID uuid.UUID) ([]model.IssueLink, error)
+}
+
+// issueLinkStore 实现 IssueLinkStore 接口
+type issueLinkStore struct {
+	db *gorm.DB
+}
+
+// NewIssueLinkStore 创建 Issue 外部链接存储实例
+func NewIssueLinkStore(db *gorm.DB) IssueLinkStore {
+	return &issueLinkStore{db: db}
+}
+
+// Upsert 创建或更新链接（按 Issue + URL 去重），返回是否为新建
+func (s *issueLinkStore) Upsert(ctx context.Context, link *model.IssueLink) (bool, error) {
+	created := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing model.IssueLink
+		err := tx.Where("issue_id = ? AND url = ?", link.IssueID, link.URL).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			created = true
+			return tx.Create(link).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		link.ID = existing.ID
+		link.CreatedAt = existing.CreatedAt
+		return tx.Model(&existing).Updates(map[string]interface{}{
+			"title":      link.Title,
+			"branch":     link.Branch,
+			"state":      link.State,
+			"author":     link.Author,
+			"updated_at": gorm.Expr("NOW()"),
+		}).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("保存 Issue 链接失败: %w", err)
+	}
+	return created, nil
+}
+
+// ListByIssue 获取 Issue 的所有链接
+func (s *issueLinkStore) ListByIssue(ctx context.Context, issueID uuid.UUID) ([]model.IssueLink, error) {
+	var links []model.IssueLink
+	err := s.db.WithContext(ctx).
+		Where("issue_id = ?", issueID).
+		Order("created_at ASC").
+		Find(&links).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询 Issue 链接失败: %w", err)
+	}
+	return links, nil
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/google/uuid"
@@ -753,3 +754,134 @@ func setupIssueTestFixtures(t *testing.T, tx *gorm.DB) (*model.Workspace, *model
 func ptr(s string) *string {
 	return &s
 }
+
+func TestIssueStore_RebuildClosure(t *testing.T) {
+	if testDB == nil {
+		t.Skip("数据库连接不可用")
+	}
+
+	tx := testDB.Begin()
+	defer tx.Rollback()
+
+	store := NewIssueStore(tx)
+	ctx := context.Background()
+	_, user, team, state := setupIssueTestFixtures(t, tx)
+
+	// 父 -> 子 -> 孙
+	var parentID *uuid.UUID
+	var ids []uuid.UUID
+	for _, title := range []string{"Parent", "Child", "Grandchild"} {
+		issue := &model.Issue{
+			TeamID:      team.ID,
+			Title:       title,
+			StatusID:    state.ID,
+			CreatedByID: user.ID,
+			ParentID:    parentID,
+		}
+		if err := store.Create(ctx, issue); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		parentID = &issue.ID
+		ids = append(ids, issue.ID)
+	}
+
+	if _, err := store.RebuildClosure(ctx); err != nil {
+		t.Fatalf("RebuildClosure() error = %v", err)
+	}
+
+	var closures []model.IssueClosure
+	if err := tx.Where("ancestor_id IN ?", ids).Find(&closures).Error; err != nil {
+		t.Fatalf("查询闭包表失败: %v", err)
+	}
+	// 每个 Issue 的自引用 3 行 + 父子 2 行 + 祖孙 1 行
+	if len(closures) != 6 {
+		t.Fatalf("闭包表行数 = %d, want 6", len(closures))
+	}
+	for _, c := range closures {
+		if c.AncestorID == ids[0] && c.DescendantID == ids[2] && c.Depth != 2 {
+			t.Errorf("祖孙深度 = %d, want 2", c.Depth)
+		}
+	}
+}
+
+func TestIssueStore_UpdateParent(t *testing.T) {
+	if testDB == nil {
+		t.Skip("数据库连接不可用")
+	}
+
+	tx := testDB.Begin()
+	defer tx.Rollback()
+
+	store := NewIssueStore(tx)
+	ctx := context.Background()
+	_, user, team, state := setupIssueTestFixtures(t, tx)
+
+	// A、B、C 互相独立，B 已有子 Issue D
+	issues := make(map[string]*model.Issue)
+	for _, title := range []string{"A", "B", "C", "D"} {
+		issue := &model.Issue{TeamID: team.ID, Title: title, StatusID: state.ID, CreatedByID: user.ID}
+		if title == "D" {
+			issue.ParentID = &issues["B"].ID
+		}
+		if err := store.Create(ctx, issue); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		issues[title] = issue
+	}
+	if _, err := store.RebuildClosure(ctx); err != nil {
+		t.Fatalf("RebuildClosure() error = %v", err)
+	}
+
+	depth := func(ancestor, descendant string) int {
+		var c model.IssueClosure
+		err := tx.Where("ancestor_id = ? AND descendant_id = ?", issues[ancestor].ID, issues[descendant].ID).First(&c).Error
+		if err != nil {
+			return -1
+		}
+		return c.Depth
+	}
+
+	// B（连同子 Issue D）挂到 A 下
+	if err := store.UpdateParent(ctx, issues["B"].ID, &issues["A"].ID); err != nil {
+		t.Fatalf("UpdateParent() error = %v", err)
+	}
+	if got := depth("A", "B"); got != 1 {
+		t.Errorf("A -> B 深度 = %d, want 1", got)
+	}
+	if got := depth("A", "D"); got != 2 {
+		t.Errorf("A -> D 深度 = %d, want 2", got)
+	}
+	if got := depth("B", "D"); got != 1 {
+		t.Errorf("B -> D 深度 = %d, want 1", got)
+	}
+
+	// 不能挂到自身或后代下
+	if err := store.UpdateParent(ctx, issues["A"].ID, &issues["D"].ID); !errors.Is(err, ErrIssueParentCycle) {
+		t.Errorf("UpdateParent() error = %v, want ErrIssueParentCycle", err)
+	}
+	if err := store.UpdateParent(ctx, issues["A"].ID, &issues["A"].ID); !errors.Is(err, ErrIssueParentCycle) {
+		t.Errorf("UpdateParent() error = %v, want ErrIssueParentCycle", err)
+	}
+
+	// B 移到 C 下后，A 不再是 B、D 的祖先
+	if err := store.UpdateParent(ctx, issues["B"].ID, &issues["C"].ID); err != nil {
+		t.Fatalf("UpdateParent() error = %v", err)
+	}
+	if got := depth("A", "D"); got != -1 {
+		t.Errorf("A -> D 应已删除，深度 = %d", got)
+	}
+	if got := depth("C", "D"); got != 2 {
+		t.Errorf("C -> D 深度 = %d, want 2", got)
+	}
+
+	// 取消父 Issue 后只保留子树内部的关系
+	if err := store.UpdateParent(ctx, issues["B"].ID, nil); err != nil {
+		t.Fatalf("UpdateParent() error = %v", err)
+	}
+	if got := depth("C", "B"); got != -1 {
+		t.Errorf("C -> B 应已删除，深度 = %d", got)
+	}
+	if got := depth("B", "D"); got != 1 {
+		t.Errorf("B -> D 深度 = %d, want 1", got)
+	}
+}
@@ -0,0 +1,107 @@
+// Package store 提供数据访问层
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"gorm.io/gorm"
+)
+
+// JobStore 定义后台任务数据访问接口
+type JobStore interface {
+	// Create 创建任务
+	Create(ctx context.Context, job *model.Job) error
+	// GetByID 通过 ID 获取任务
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Job, error)
+	// MarkRunning 将任务标记为执行中
+	MarkRunning(ctx context.Context, id uuid.UUID) error
+	// UpdateProgress 更新任务进度
+	UpdateProgress(ctx context.Context, id uuid.UUID, progress, total int) error
+	// Finish 结束任务并保存结果
+	Finish(ctx context.Context, id uuid.UUID, status model.JobStatus, result []byte, errMsg *string) error
+	// ListUnfinished 获取未结束的任务（用于重启后恢复或标记中断）
+	ListUnfinished(ctx context.Context) ([]model.Job, error)
+}
+
+// jobStore 实现 JobStore 接口
+type jobStore struct {
+	db *gorm.DB
+}
+
+// NewJobStore 创建后台任务存储实例
+func NewJobStore(db *gorm.DB) JobStore {
+	return &jobStore{db: db}
+}
+
+// Create 创建任务
+func (s *jobStore) Create(ctx context.Context, job *model.Job) error {
+	if job.Status == "" {
+		job.Status = model.JobStatusPending
+	}
+	if err := s.db.WithContext(ctx).Create(job).Error; err != nil {
+		return fmt.Errorf("创建任务失败: %w", err)
+	}
+	return nil
+}
+
+// GetByID 通过 ID 获取任务
+func (s *jobStore) GetByID(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	var job model.Job
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// MarkRunning 将任务标记为执行中
+func (s *jobStore) MarkRunning(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	return s.db.WithContext(ctx).Model(&model.Job{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     model.JobStatusRunning,
+			"started_at": now,
+		}).Error
+}
+
+// UpdateProgress 更新任务进度
+func (s *jobStore) UpdateProgress(ctx context.Context, id uuid.UUID, progress, total int) error {
+	return s.db.WithContext(ctx).Model(&model.Job{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"progress": progress,
+			"total":    total,
+		}).Error
+}
+
+// Finish 结束任务并保存结果
+func (s *jobStore) Finish(ctx context.Context, id uuid.UUID, status model.JobStatus, result []byte, errMsg *string) error {
+	updates := map[string]interface{}{
+		"status":      status,
+		"error":       errMsg,
+		"finished_at": time.Now(),
+	}
+	if result != nil {
+		updates["result"] = result
+	}
+	return s.db.WithContext(ctx).Model(&model.Job{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+// ListUnfinished 获取未结束的任务（用于重启后恢复或标记中断）
+func (s *jobStore) ListUnfinished(ctx context.Context) ([]model.Job, error) {
+	var jobs []model.Job
+	err := s.db.WithContext(ctx).
+		Where("status IN ?", []model.JobStatus{model.JobStatusPending, model.JobStatusRunning}).
+		Order("created_at ASC").
+		Find(&jobs).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询未完成任务失败: %w", err)
+	}
+	return jobs, nil
+}
@@ -80,7 +80,8 @@ func (s *notificationStore) GetNotificationByID(ctx context.Context, id uuid.UUI
 // ListNotifications 获取用户的通知列表
 func (s *notificationStore) ListNotifications(ctx context.Context, userID uuid.UUID, opts *ListNotificationsOptions) ([]model.Notification, int64, error) {
 	query := s.db.WithContext(ctx).
-		Where("user_id = ?", userID)
+		Where("user_id = ?", userID).
+		Where(visibleNotifications(s.db, userID))
 
 	// 应用已读状态过滤
 	if opts != nil && opts.Read != nil {
@@ -128,6 +129,7 @@ func (s *notificationStore) CountUnread(ctx context.Context, userID uuid.UUID) (
 	if err := s.db.WithContext(ctx).
 		Model(&model.Notification{}).
 		Where("user_id = ? AND read_at IS NULL", userID).
+		Where(visibleNotifications(s.db, userID)).
 		Count(&count).Error; err != nil {
 		return 0, fmt.Errorf("统计未读通知数量失败: %w", err)
 	}
@@ -186,3 +188,9 @@ func (s *notificationStore) MarkBatchAsRead(ctx context.Context, ids []uuid.UUID
 
 	return result.RowsAffected, nil
 }
+
+// visibleNotifications 过滤掉用户已无权查看的 Issue 通知（Issue 属于用户不在其中的私有团队）
+func visibleNotifications(db *gorm.DB, userID uuid.UUID) *gorm.DB {
+	return db.Where("resource_type <> ? OR resource_id IS NULL OR resource_id IN (?)", "issue",
+		db.Unscoped().Model(&model.Issue{}).Select("id").Where("team_id IN (?)", visibleTeamIDs(db, userID.String())))
+}
@@ -0,0 +1,247 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrOAuthCodeUsed 授权码已被使用（可能被截获重放）
+var ErrOAuthCodeUsed = errors.New("授权码已使用")
+
+// OAuthStore 定义 OAuth2 应用、授权码和授权的数据访问接口
+type OAuthStore interface {
+	// CreateApplication 创建应用
+	CreateApplication(ctx context.Context, app *model.OAuthApplication) error
+	// GetApplicationByID 根据 ID 获取应用
+	GetApplicationByID(ctx context.Context, id uuid.UUID) (*model.OAuthApplication, error)
+	// GetApplicationByClientID 根据 client_id 获取应用
+	GetApplicationByClientID(ctx context.Context, clientID string) (*model.OAuthApplication, error)
+	// ListApplications 获取工作区的应用
+	ListApplications(ctx context.Context, workspaceID uuid.UUID) ([]model.OAuthApplication, error)
+	// DeleteApplication 删除应用（级联删除授权码和授权）
+	DeleteApplication(ctx context.Context, id uuid.UUID) error
+
+	// CreateAuthorizationCode 保存授权码
+	CreateAuthorizationCode(ctx context.Context, code *model.OAuthAuthorizationCode) error
+	// ConsumeAuthorizationCode 根据摘要取出授权码并标记为已使用
+	// 授权码被重复使用时吊销由它签发的授权，并返回 ErrOAuthCodeUsed
+	ConsumeAuthorizationCode(ctx context.Context, codeHash string) (*model.OAuthAuthorizationCode, error)
+
+	// CreateGrant 创建授权
+	CreateGrant(ctx context.Context, grant *model.OAuthGrant) error
+	// GetGrantByID 根据 ID 获取授权
+	GetGrantByID(ctx context.Context, id uuid.UUID) (*model.OAuthGrant, error)
+	// GetGrantByRefreshToken 根据刷新令牌摘要获取授权
+	GetGrantByRefreshToken(ctx context.Context, refreshTokenHash string) (*model.OAuthGrant, error)
+	// RotateRefreshToken 轮换刷新令牌，旧令牌已被轮换时返回 gorm.ErrRecordNotFound
+	RotateRefreshToken(ctx context.Context, grantID uuid.UUID, oldHash, newHash string, expiresAt time.Time) error
+	// RevokeGrant 吊销授权
+	RevokeGrant(ctx context.Context, id uuid.UUID) error
+	// RevokeGrantsByUser 吊销用户对所有应用的授权
+	RevokeGrantsByUser(ctx context.Context, userID uuid.UUID) error
+}
+
+// oauthStore 实现 OAuthStore 接口
+type oauthStore struct {
+	db *gorm.DB
+}
+
+// NewOAuthStore 创建 OAuth2 存储实例
+func NewOAuthStore(db *gorm.DB) OAuthStore {
+	return &oauthStore{db: db}
+}
+
+// CreateApplication 创建应用
+func (s *oauthStore) CreateApplication(ctx context.Context, app *model.OAuthApplication) error {
+	if err := s.db.WithContext(ctx).Create(app).Error; err != nil {
+		return fmt.Errorf("创建 OAuth 应用失败: %w", err)
+	}
+	return nil
+}
+
+// GetApplicationByID 根据 ID 获取应用
+func (s *oauthStore) GetApplicationByID(ctx context.Context, id uuid.UUID) (*model.OAuthApplication, error) {
+	var app model.OAuthApplication
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&app).Error; err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+// GetApplicationByClientID 根据 client_id 获取应用
+func (s *oauthStore) GetApplicationByClientID(ctx context.Context, clientID string) (*model.OAuthApplication, error) {
+	var app model.OAuthApplication
+	if err := s.db.WithContext(ctx).Where("client_id = ?", clientID).First(&app).Error; err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+// ListApplications 获取工作区的应用
+func (s *oauthStore) ListApplications(ctx context.Context, workspaceID uuid.UUID) ([]model.OAuthApplication, error) {
+	var apps []model.OAuthApplication
+	err := s.db.WithContext(ctx).
+		Where("workspace_id = ?", workspaceID).
+		Order("created_at ASC").
+		Find(&apps).Error
+	if err != nil {
+		return nil, fmt.Errorf("获取 OAuth 应用列表失败: %w", err)
+	}
+	return apps, nil
+}
+
+// DeleteApplication 删除应用（级联删除授权码和授权）
+func (s *oauthStore) DeleteApplication(ctx context.Context, id uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("application_id = ?", id).Delete(&model.OAuthGrant{}).Error; err != nil {
+			return fmt.Errorf("删除 OAuth 授权失败: %w", err)
+		}
+		if err := tx.Where("application_id = ?", id).Delete(&model.OAuthAuthorizationCode{}).Error; err != nil {
+			return fmt.Errorf("删除 OAuth 授权码失败: %w", err)
+		}
+		if err := tx.Where("id = ?", id).Delete(&model.OAuthApplication{}).Error; err != nil {
+			return fmt.Errorf("删除 OAuth 应用失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// CreateAuthorizationCode 保存授权码
+func (s *oauthStore) CreateAuthorizationCode(ctx context.Context, code *model.OAuthAuthorizationCode) error {
+	if err := s.db.WithContext(ctx).Create(code).Error; err != nil {
+		return fmt.Errorf("保存授权码失败: %w", err)
+	}
+	return nil
+}
+
+// ConsumeAuthorizationCode 根据摘要取出授权码并标记为已使用
+func (s *oauthStore) ConsumeAuthorizationCode(ctx context.Context, codeHash string) (*model.OAuthAuthorizationCode, error) {
+	var code model.OAuthAuthorizationCode
+	reused := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code_hash = ?", codeHash).
+			First(&code).Error
+		if err != nil {
+			return err
+		}
+
+		if code.UsedAt != nil {
+			// RFC 6749 4.1.2：重复使用授权码时吊销已签发的令牌
+			if err := tx.Model(&model.OAuthGrant{}).
+				Where("authorization_code_id = ? AND revoked_at IS NULL", code.ID).
+				Update("revoked_at", time.Now()).Error; err != nil {
+				return err
+			}
+			// 吊销需要提交，事务外再返回错误
+			reused = true
+			return nil
+		}
+
+		now := time.Now()
+		code.UsedAt = &now
+		return tx.Model(&code).Update("used_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, ErrOAuthCodeUsed
+	}
+	return &code, nil
+}
+
+// CreateGrant 创建授权
+func (s *oauthStore) CreateGrant(ctx context.Context, grant *model.OAuthGrant) error {
+	if err := s.db.WithContext(ctx).Create(grant).Error; err != nil {
+		return fmt.Errorf("创建 OAuth 授权失败: %w", err)
+	}
+	return nil
+}
+
+// GetGrantByID 根据 ID 获取授权
+func (s *oauthStore) GetGrantByID(ctx context.Context, id uuid.UUID) (*model.OAuthGrant, error) {
+	var grant model.OAuthGrant
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&grant).Error; err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+// GetGrantByRefreshToken 根据刷新令牌摘要获取授权
+func (s *oauthStore) GetGrantByRefreshToken(ctx context.Context, refreshTokenHash string) (*model.OAuthGrant, error) {
+	var grant model.OAuthGrant
+	if err := s.db.WithContext(ctx).Where("refresh_token_hash = ?", refreshTokenHash).First(&grant).Error; err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+// RotateRefreshToken 轮换刷新令牌，旧令牌已被轮换时返回 gorm.ErrRecordNotFound
+func (s *oauthStore) RotateRefreshToken(ctx context.Context, grantID uuid.UUID, oldHash, newHash string, expiresAt time.Time) error {
+	now := time.Now()
+	// 以旧摘要为条件，保证并发刷新时只有一个请求成功
+	result := s.db.WithContext(ctx).Model(&model.OAuthGrant{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", grantID, oldHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash": newHash,
+			"refresh_expires_at": expiresAt,
+			"last_used_at":       now,
+			"updated_at":         now,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("轮换刷新令牌失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RevokeGrant 吊销授权
+func (s *oauthStore) RevokeGrant(ctx context.Context, id uuid.UUID) error {
+	err := s.db.WithContext(ctx).Model(&model.OAuthGrant{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at": time.Now(),
+			"updated_at": time.Now(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("吊销 OAuth 授权失败: %w", err)
+	}
+	return nil
+}
+
+// RevokeGrantsByUser 吊销用户对所有应用的授权
+func (s *oauthStore) RevokeGrantsByUser(ctx context.Context, userID uuid.UUID) error {
+	now := time.Now()
+	err := s.db.WithContext(ctx).Model(&model.OAuthGrant{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"revoked_at": now,
+			"updated_at": now,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("吊销 OAuth 授权失败: %w", err)
+	}
+	return nil
+}
+
+// IsOAuthGrantActive 检查 OAuth 授权是否有效，供认证中间件校验访问令牌
+func IsOAuthGrantActive(ctx context.Context, db *gorm.DB, grantID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.WithContext(ctx).Model(&model.OAuthGrant{}).
+		Where("id = ? AND revoked_at IS NULL", grantID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
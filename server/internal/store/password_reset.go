@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"gorm.io/gorm"
+)
+
+// PasswordResetTokenStore 定义密码重置令牌数据访问接口
+type PasswordResetTokenStore interface {
+	// Create 创建令牌
+	Create(ctx context.Context, token *model.PasswordResetToken) error
+	// Use 将未使用且未过期的令牌标记为已使用并返回；令牌不存在或不可用时返回 gorm.ErrRecordNotFound
+	Use(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error)
+	// InvalidateByUser 使用户所有未使用的令牌失效
+	InvalidateByUser(ctx context.Context, userID uuid.UUID) error
+}
+
+// passwordResetTokenStore 实现 PasswordResetTokenStore 接口
+type passwordResetTokenStore struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetTokenStore 创建密码重置令牌存储实例
+func NewPasswordResetTokenStore(db *gorm.DB) PasswordResetTokenStore {
+	return &passwordResetTokenStore{db: db}
+}
+
+// Create 创建令牌
+func (s *passwordResetTokenStore) Create(ctx context.Context, token *model.PasswordResetToken) error {
+	if err := s.db.WithContext(ctx).Create(token).Error; err != nil {
+		return fmt.Errorf("创建密码重置令牌失败: %w", err)
+	}
+	return nil
+}
+
+// Use 将令牌标记为已使用并返回
+// 使用条件更新，并发使用同一令牌时只有一个请求成功
+func (s *passwordResetTokenStore) Use(ctx context.Context, tokenHash string) (*model.PasswordResetToken, error) {
+	now := time.Now()
+	result := s.db.WithContext(ctx).Model(&model.PasswordResetToken{}).
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, now).
+		Updates(map[string]interface{}{
+			"used_at":    now,
+			"updated_at": now,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("使用密码重置令牌失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var token model.PasswordResetToken
+	if err := s.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// InvalidateByUser 使用户所有未使用的令牌失效
+func (s *passwordResetTokenStore) InvalidateByUser(ctx context.Context, userID uuid.UUID) error {
+	err := s.db.WithContext(ctx).Model(&model.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"used_at":    time.Now(),
+			"updated_at": time.Now(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("使密码重置令牌失效失败: %w", err)
+	}
+	return nil
+}
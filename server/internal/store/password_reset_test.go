@@ -0,0 +1,55 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestPasswordResetTokenStore(t *testing.T) {
+	if testDB == nil {
+		t.Skip("数据库连接不可用")
+	}
+
+	tx := testDB.Begin()
+	defer tx.Rollback()
+
+	ctx := context.Background()
+	tokenStore := NewPasswordResetTokenStore(tx)
+	_, user, _, _ := setupIssueTestFixtures(t, tx)
+
+	newToken := func(hash string, expiresIn time.Duration) {
+		assert.NoError(t, tokenStore.Create(ctx, &model.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: hash,
+			ExpiresAt: time.Now().Add(expiresIn),
+		}))
+	}
+	newToken("hash-valid", time.Hour)
+	newToken("hash-expired", -time.Minute)
+	newToken("hash-other", time.Hour)
+
+	// 令牌只能使用一次
+	token, err := tokenStore.Use(ctx, "hash-valid")
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, token.UserID)
+	assert.NotNil(t, token.UsedAt)
+	_, err = tokenStore.Use(ctx, "hash-valid")
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	// 过期和不存在的令牌不可用
+	_, err = tokenStore.Use(ctx, "hash-expired")
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	_, err = tokenStore.Use(ctx, "hash-missing")
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	// 失效用户的所有令牌
+	assert.NoError(t, tokenStore.InvalidateByUser(ctx, user.ID))
+	_, err = tokenStore.Use(ctx, "hash-other")
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+}
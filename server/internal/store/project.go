@@ -261,17 +261,7 @@ func (s *projectStore) ListIssues(ctx context.Context, projectID uuid.UUID, filt
 	query := s.db.WithContext(ctx).Model(&model.Issue{}).Where("project_id = ?", projectID)
 
 	// 应用过滤条件
-	if filter != nil {
-		if filter.StatusID != nil {
-			query = query.Where("status_id = ?", filter.StatusID)
-		}
-		if filter.Priority != nil {
-			query = query.Where("priority = ?", filter.Priority)
-		}
-		if filter.AssigneeID != nil {
-			query = query.Where("assignee_id = ?", filter.AssigneeID)
-		}
-	}
+	query = applyIssueFilter(query, filter)
 
 	// 统计总数
 	if err := query.Count(&total).Error; err != nil {
@@ -295,3 +285,20 @@ func (s *projectStore) ListIssues(ctx context.Context, projectID uuid.UUID, filt
 
 	return issues, total, nil
 }
+
+// ProjectTeamIDs 获取项目关联的团队，项目不存在时返回 gorm.ErrRecordNotFound
+func ProjectTeamIDs(ctx context.Context, db *gorm.DB, projectID uuid.UUID) ([]uuid.UUID, error) {
+	var project model.Project
+	if err := db.WithContext(ctx).Select("teams").Where("id = ?", projectID).First(&project).Error; err != nil {
+		return nil, err
+	}
+	teamIDs := make([]uuid.UUID, 0, len(project.Teams))
+	for _, id := range project.Teams {
+		teamID, err := uuid.Parse(id)
+		if err != nil {
+			continue
+		}
+		teamIDs = append(teamIDs, teamID)
+	}
+	return teamIDs, nil
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"gorm.io/gorm"
+)
+
+// RecoveryCodeStore 定义两步验证恢复码数据访问接口
+type RecoveryCodeStore interface {
+	// Replace 删除用户的所有恢复码并保存新的恢复码摘要
+	Replace(ctx context.Context, userID uuid.UUID, hashes []string) error
+	// Use 将匹配的未使用恢复码标记为已使用，返回是否匹配
+	Use(ctx context.Context, userID uuid.UUID, hash string) (bool, error)
+	// CountUnused 统计用户未使用的恢复码数量
+	CountUnused(ctx context.Context, userID uuid.UUID) (int64, error)
+	// DeleteByUser 删除用户的所有恢复码
+	DeleteByUser(ctx context.Context, userID uuid.UUID) error
+}
+
+// recoveryCodeStore 实现 RecoveryCodeStore 接口
+type recoveryCodeStore struct {
+	db *gorm.DB
+}
+
+// NewRecoveryCodeStore 创建恢复码存储实例
+func NewRecoveryCodeStore(db *gorm.DB) RecoveryCodeStore {
+	return &recoveryCodeStore{db: db}
+}
+
+// Replace 删除用户的所有恢复码并保存新的恢复码摘要
+func (s *recoveryCodeStore) Replace(ctx context.Context, userID uuid.UUID, hashes []string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return fmt.Errorf("删除恢复码失败: %w", err)
+		}
+		if len(hashes) == 0 {
+			return nil
+		}
+		codes := make([]model.RecoveryCode, 0, len(hashes))
+		for _, hash := range hashes {
+			codes = append(codes, model.RecoveryCode{UserID: userID, CodeHash: hash})
+		}
+		if err := tx.Create(&codes).Error; err != nil {
+			return fmt.Errorf("保存恢复码失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// Use 将匹配的未使用恢复码标记为已使用，返回是否匹配
+// 通过条件更新保证并发请求中同一恢复码只有一个成功
+func (s *recoveryCodeStore) Use(ctx context.Context, userID uuid.UUID, hash string) (bool, error) {
+	result := s.db.WithContext(ctx).
+		Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("使用恢复码失败: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CountUnused 统计用户未使用的恢复码数量
+func (s *recoveryCodeStore) CountUnused(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := s.db.WithContext(ctx).
+		Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("统计恢复码失败: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteByUser 删除用户的所有恢复码
+func (s *recoveryCodeStore) DeleteByUser(ctx context.Context, userID uuid.UUID) error {
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return fmt.Errorf("删除恢复码失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoveryCodeStore(t *testing.T) {
+	if testDB == nil {
+		t.Skip("数据库连接不可用")
+	}
+
+	tx := testDB.Begin()
+	defer tx.Rollback()
+
+	ctx := context.Background()
+	codeStore := NewRecoveryCodeStore(tx)
+	_, user, _, _ := setupIssueTestFixtures(t, tx)
+
+	hashes := []string{model.HashRecoveryCode("aaaaa-11111"), model.HashRecoveryCode("bbbbb-22222")}
+	assert.NoError(t, codeStore.Replace(ctx, user.ID, hashes))
+
+	count, err := codeStore.CountUnused(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// 恢复码只能使用一次
+	ok, err := codeStore.Use(ctx, user.ID, hashes[0])
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = codeStore.Use(ctx, user.ID, hashes[0])
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	count, _ = codeStore.CountUnused(ctx, user.ID)
+	assert.Equal(t, int64(1), count)
+
+	// 重新生成后旧恢复码失效
+	assert.NoError(t, codeStore.Replace(ctx, user.ID, []string{model.HashRecoveryCode("ccccc-33333")}))
+	ok, _ = codeStore.Use(ctx, user.ID, hashes[1])
+	assert.False(t, ok)
+
+	assert.NoError(t, codeStore.DeleteByUser(ctx, user.ID))
+	count, _ = codeStore.CountUnused(ctx, user.ID)
+	assert.Equal(t, int64(0), count)
+}
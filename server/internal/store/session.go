@@ -0,0 +1,145 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"gorm.io/gorm"
+)
+
+// SessionStore 定义登录会话数据访问接口
+type SessionStore interface {
+	// Create 创建会话
+	Create(ctx context.Context, session *model.Session) error
+	// GetByID 根据 ID 获取会话
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Session, error)
+	// ListActiveByUser 获取用户未吊销且未过期的会话（按最近使用时间倒序）
+	ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]model.Session, error)
+	// Rotate 将会话的刷新令牌从 oldJTI 轮换为 session.RefreshTokenJTI，同时更新设备信息和过期时间
+	// 当前令牌不是 oldJTI 或会话已吊销时返回 false（令牌已被使用过）
+	Rotate(ctx context.Context, session *model.Session, oldJTI string) (bool, error)
+	// SetWorkspace 切换会话当前所在的工作区
+	SetWorkspace(ctx context.Context, id, workspaceID uuid.UUID) error
+	// Revoke 吊销会话，已吊销的会话不会重复更新
+	Revoke(ctx context.Context, id uuid.UUID, reason string) error
+	// RevokeByUser 吊销用户的所有会话（except 除外，uuid.Nil 表示不排除），返回吊销数量
+	RevokeByUser(ctx context.Context, userID, except uuid.UUID, reason string) (int64, error)
+}
+
+// sessionStore 实现 SessionStore 接口
+type sessionStore struct {
+	db *gorm.DB
+}
+
+// NewSessionStore 创建会话存储实例
+func NewSessionStore(db *gorm.DB) SessionStore {
+	return &sessionStore{db: db}
+}
+
+// Create 创建会话
+func (s *sessionStore) Create(ctx context.Context, session *model.Session) error {
+	if err := s.db.WithContext(ctx).Create(session).Error; err != nil {
+		return fmt.Errorf("创建会话失败: %w", err)
+	}
+	return nil
+}
+
+// GetByID 根据 ID 获取会话
+func (s *sessionStore) GetByID(ctx context.Context, id uuid.UUID) (*model.Session, error) {
+	var session model.Session
+	if err := s.db.WithContext(ctx).Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListActiveByUser 获取用户未吊销且未过期的会话（按最近使用时间倒序）
+func (s *sessionStore) ListActiveByUser(ctx context.Context, userID uuid.UUID) ([]model.Session, error) {
+	var sessions []model.Session
+	err := s.db.WithContext(ctx).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("获取会话列表失败: %w", err)
+	}
+	return sessions, nil
+}
+
+// Rotate 将会话的刷新令牌从 oldJTI 轮换为 session.RefreshTokenJTI
+// 使用条件更新，并发刷新同一令牌时只有一个请求成功
+func (s *sessionStore) Rotate(ctx context.Context, session *model.Session, oldJTI string) (bool, error) {
+	result := s.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND refresh_token_jti = ? AND revoked_at IS NULL", session.ID, oldJTI).
+		Updates(map[string]interface{}{
+			"refresh_token_jti": session.RefreshTokenJTI,
+			"user_agent":        session.UserAgent,
+			"ip_address":        session.IPAddress,
+			"last_used_at":      session.LastUsedAt,
+			"expires_at":        session.ExpiresAt,
+			"updated_at":        time.Now(),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("轮换刷新令牌失败: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SetWorkspace 切换会话当前所在的工作区
+func (s *sessionStore) SetWorkspace(ctx context.Context, id, workspaceID uuid.UUID) error {
+	if err := s.db.WithContext(ctx).
+		Model(&model.Session{}).
+		Where("id = ?", id).
+		Update("workspace_id", workspaceID).Error; err != nil {
+		return fmt.Errorf("切换工作区失败: %w", err)
+	}
+	return nil
+}
+
+// Revoke 吊销会话，已吊销的会话不会重复更新
+func (s *sessionStore) Revoke(ctx context.Context, id uuid.UUID, reason string) error {
+	err := s.db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at":    time.Now(),
+			"revoke_reason": reason,
+			"updated_at":    time.Now(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("吊销会话失败: %w", err)
+	}
+	return nil
+}
+
+// RevokeByUser 吊销用户的所有会话（except 除外，uuid.Nil 表示不排除），返回吊销数量
+func (s *sessionStore) RevokeByUser(ctx context.Context, userID, except uuid.UUID, reason string) (int64, error) {
+	query := s.db.WithContext(ctx).Model(&model.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID)
+	if except != uuid.Nil {
+		query = query.Where("id <> ?", except)
+	}
+	result := query.Updates(map[string]interface{}{
+		"revoked_at":    time.Now(),
+		"revoke_reason": reason,
+		"updated_at":    time.Now(),
+	})
+	if result.Error != nil {
+		return 0, fmt.Errorf("吊销会话失败: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// IsSessionActive 检查会话是否未吊销且未过期，会话不存在时返回 false
+func IsSessionActive(ctx context.Context, db *gorm.DB, sessionID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.WithContext(ctx).Model(&model.Session{}).
+		Where("id = ? AND revoked_at IS NULL AND expires_at > ?", sessionID, time.Now()).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,82 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionStore(t *testing.T) {
+	if testDB == nil {
+		t.Skip("数据库连接不可用")
+	}
+
+	tx := testDB.Begin()
+	defer tx.Rollback()
+
+	ctx := context.Background()
+	sessionStore := NewSessionStore(tx)
+	_, user, _, _ := setupIssueTestFixtures(t, tx)
+
+	newSession := func() *model.Session {
+		now := time.Now()
+		session := &model.Session{
+			UserID:          user.ID,
+			RefreshTokenJTI: uuid.New().String(),
+			UserAgent:       "Mozilla/5.0",
+			IPAddress:       "127.0.0.1",
+			LastUsedAt:      now,
+			ExpiresAt:       now.Add(time.Hour),
+		}
+		assert.NoError(t, sessionStore.Create(ctx, session))
+		return session
+	}
+	first, second, third := newSession(), newSession(), newSession()
+
+	sessions, err := sessionStore.ListActiveByUser(ctx, user.ID)
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 3)
+
+	// 轮换：只有当前令牌可以轮换，旧令牌再次轮换失败
+	oldJTI := first.RefreshTokenJTI
+	first.RefreshTokenJTI = uuid.New().String()
+	first.IPAddress = "10.0.0.1"
+	ok, err := sessionStore.Rotate(ctx, first, oldJTI)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = sessionStore.Rotate(ctx, first, oldJTI)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	got, err := sessionStore.GetByID(ctx, first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, first.RefreshTokenJTI, got.RefreshTokenJTI)
+	assert.Equal(t, "10.0.0.1", got.IPAddress)
+
+	// 吊销单个会话
+	assert.NoError(t, sessionStore.Revoke(ctx, second.ID, model.SessionRevokeReasonRevoked))
+	active, err := IsSessionActive(ctx, tx, second.ID)
+	assert.NoError(t, err)
+	assert.False(t, active)
+	got, _ = sessionStore.GetByID(ctx, second.ID)
+	assert.Equal(t, model.SessionRevokeReasonRevoked, got.RevokeReason)
+
+	// 吊销除当前会话外的所有会话
+	count, err := sessionStore.RevokeByUser(ctx, user.ID, first.ID, model.SessionRevokeReasonRevoked)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	active, _ = IsSessionActive(ctx, tx, third.ID)
+	assert.False(t, active)
+	active, _ = IsSessionActive(ctx, tx, first.ID)
+	assert.True(t, active)
+
+	count, err = sessionStore.RevokeByUser(ctx, user.ID, uuid.Nil, model.SessionRevokeReasonPasswordChanged)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	sessions, _ = sessionStore.ListActiveByUser(ctx, user.ID)
+	assert.Empty(t, sessions)
+}
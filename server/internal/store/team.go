@@ -14,8 +14,14 @@ import (
 type TeamStore interface {
 	// List 获取团队列表
 	List(ctx context.Context, workspaceID string, page, pageSize int) ([]model.Team, int64, error)
+	// ListVisible 获取用户可见的团队列表：公开团队和用户所在的私有团队
+	ListVisible(ctx context.Context, workspaceID, userID string, page, pageSize int) ([]model.Team, int64, error)
+	// ListByMember 获取用户所在的团队列表
+	ListByMember(ctx context.Context, workspaceID, userID string, page, pageSize int) ([]model.Team, int64, error)
 	// GetByID 通过 ID 获取团队
 	GetByID(ctx context.Context, id string) (*model.Team, error)
+	// GetByKey 通过 Key 获取工作区内的团队
+	GetByKey(ctx context.Context, workspaceID, key string) (*model.Team, error)
 	// Create 创建团队
 	Create(ctx context.Context, team *model.Team) error
 	// Update 更新团队
@@ -58,6 +64,48 @@ func (s *teamStore) List(ctx context.Context, workspaceID string, page, pageSize
 	return teams, total, nil
 }
 
+// ListVisible 获取用户可见的团队列表：公开团队和用户所在的私有团队
+func (s *teamStore) ListVisible(ctx context.Context, workspaceID, userID string, page, pageSize int) ([]model.Team, int64, error) {
+	var teams []model.Team
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&model.Team{}).
+		Where("workspace_id = ?", workspaceID).
+		Where("id IN (?)", visibleTeamIDs(s.db, userID))
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计团队数量失败: %w", err)
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&teams).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询团队列表失败: %w", err)
+	}
+
+	return teams, total, nil
+}
+
+// ListByMember 获取用户所在的团队列表
+func (s *teamStore) ListByMember(ctx context.Context, workspaceID, userID string, page, pageSize int) ([]model.Team, int64, error) {
+	var teams []model.Team
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&model.Team{}).
+		Where("workspace_id = ?", workspaceID).
+		Where("id IN (?)", s.db.Model(&model.TeamMember{}).Select("team_id").Where("user_id = ?", userID))
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("统计团队数量失败: %w", err)
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&teams).Error; err != nil {
+		return nil, 0, fmt.Errorf("查询团队列表失败: %w", err)
+	}
+
+	return teams, total, nil
+}
+
 // GetByID 通过 ID 获取团队
 func (s *teamStore) GetByID(ctx context.Context, id string) (*model.Team, error) {
 	var team model.Team
@@ -68,6 +116,16 @@ func (s *teamStore) GetByID(ctx context.Context, id string) (*model.Team, error)
 	return &team, nil
 }
 
+// GetByKey 通过 Key 获取工作区内的团队
+func (s *teamStore) GetByKey(ctx context.Context, workspaceID, key string) (*model.Team, error) {
+	var team model.Team
+	err := s.db.WithContext(ctx).Where("workspace_id = ? AND key = ?", workspaceID, key).First(&team).Error
+	if err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
 // Create 创建团队
 func (s *teamStore) Create(ctx context.Context, team *model.Team) error {
 	// 校验 Key 格式
@@ -101,8 +159,33 @@ func ValidateTeamKey(key string) error {
 }
 
 // Update 更新团队
+// Key 变更时记录旧 Key，使旧标识符（如 OLD-42）仍可解析
 func (s *teamStore) Update(ctx context.Context, team *model.Team) error {
-	return s.db.WithContext(ctx).Save(team).Error
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var oldKey string
+		if err := tx.Model(&model.Team{}).Where("id = ?", team.ID).Pluck("key", &oldKey).Error; err != nil {
+			return err
+		}
+
+		if oldKey != "" && oldKey != team.Key {
+			if err := ValidateTeamKey(team.Key); err != nil {
+				return err
+			}
+			// 同一工作区内同一个 Key 只保留最近一次使用它的团队
+			if err := tx.Where("workspace_id = ? AND key = ?", team.WorkspaceID, oldKey).Delete(&model.TeamKeyAlias{}).Error; err != nil {
+				return fmt.Errorf("更新团队历史 Key 失败: %w", err)
+			}
+			if err := tx.Create(&model.TeamKeyAlias{TeamID: team.ID, WorkspaceID: team.WorkspaceID, Key: oldKey}).Error; err != nil {
+				return fmt.Errorf("记录团队历史 Key 失败: %w", err)
+			}
+			// 改回曾经使用过的 Key 时，该 Key 不再是历史 Key
+			if err := tx.Where("team_id = ? AND key = ?", team.ID, team.Key).Delete(&model.TeamKeyAlias{}).Error; err != nil {
+				return fmt.Errorf("更新团队历史 Key 失败: %w", err)
+			}
+		}
+
+		return tx.Save(team).Error
+	})
 }
 
 // SoftDelete 软删除团队（使用 gorm.DeletedAt 需要模型支持，这里用硬删除）
@@ -288,3 +371,19 @@ func IsTeamMember(ctx context.Context, db *gorm.DB, userID, teamID uuid.UUID) (b
 	}
 	return role != "", nil
 }
+
+// IsTeamPrivate 检查团队是否为私有团队，团队不存在时返回 gorm.ErrRecordNotFound
+func IsTeamPrivate(ctx context.Context, db *gorm.DB, teamID uuid.UUID) (bool, error) {
+	var team model.Team
+	if err := db.WithContext(ctx).Select("is_private").Where("id = ?", teamID).First(&team).Error; err != nil {
+		return false, err
+	}
+	return team.IsPrivate, nil
+}
+
+// visibleTeamIDs 用户可见团队 ID 的子查询：公开团队和用户所在的私有团队
+func visibleTeamIDs(db *gorm.DB, userID string) *gorm.DB {
+	return db.Model(&model.Team{}).Select("id").
+		Where("is_private = ? OR id IN (?)", false,
+			db.Model(&model.TeamMember{}).Select("team_id").Where("user_id = ?", userID))
+}
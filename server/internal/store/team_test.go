@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/liwei0526vip/mylinear/internal/model"
@@ -158,6 +159,66 @@ func TestTeamStore_List(t *testing.T) {
 	}
 }
 
+func TestTeamStore_ListVisible(t *testing.T) {
+	if testWorkspaceDB == nil {
+		t.Skip("数据库连接不可用，跳过集成测试")
+	}
+
+	tx := testWorkspaceDB.Begin()
+	defer tx.Rollback()
+
+	store := NewTeamStore(tx)
+	ctx := context.Background()
+	prefix := uuid.New().String()[:8]
+
+	workspace := &model.Workspace{
+		Name: "Team Visible Test " + prefix,
+		Slug: "team-visible-test-" + prefix,
+	}
+	if err := tx.Create(workspace).Error; err != nil {
+		t.Fatalf("创建测试工作区失败: %v", err)
+	}
+
+	user := &model.User{
+		WorkspaceID:  workspace.ID,
+		Email:        prefix + "_visible@example.com",
+		Username:     prefix + "_visible",
+		Name:         "Visible",
+		PasswordHash: "hash",
+		Role:         model.RoleMember,
+	}
+	if err := tx.Create(user).Error; err != nil {
+		t.Fatalf("创建测试用户失败: %v", err)
+	}
+
+	publicTeam := &model.Team{WorkspaceID: workspace.ID, Name: "Public " + prefix, Key: "VP"}
+	joinedTeam := &model.Team{WorkspaceID: workspace.ID, Name: "Joined " + prefix, Key: "VJ", IsPrivate: true}
+	hiddenTeam := &model.Team{WorkspaceID: workspace.ID, Name: "Hidden " + prefix, Key: "VH", IsPrivate: true}
+	for _, team := range []*model.Team{publicTeam, joinedTeam, hiddenTeam} {
+		if err := tx.Create(team).Error; err != nil {
+			t.Fatalf("创建测试团队失败: %v", err)
+		}
+	}
+	tx.Create(&model.TeamMember{TeamID: joinedTeam.ID, UserID: user.ID, Role: model.RoleMember, JoinedAt: time.Now()})
+
+	teams, total, err := store.ListVisible(ctx, workspace.ID.String(), user.ID.String(), 1, 10)
+	if err != nil {
+		t.Fatalf("ListVisible() error = %v", err)
+	}
+
+	assert.Equal(t, int64(2), total)
+	for _, team := range teams {
+		assert.NotEqual(t, hiddenTeam.ID, team.ID, "ListVisible() 返回了未加入的私有团队")
+	}
+
+	// 其他用户只能看到公开团队
+	_, total, err = store.ListVisible(ctx, workspace.ID.String(), uuid.New().String(), 1, 10)
+	if err != nil {
+		t.Fatalf("ListVisible() error = %v", err)
+	}
+	assert.Equal(t, int64(1), total)
+}
+
 // =============================================================================
 // GetByID 测试
 // =============================================================================
@@ -279,6 +340,13 @@ func TestTeamStore_Create(t *testing.T) {
 	if err := tx.Create(workspace).Error; err != nil {
 		t.Fatalf("创建测试工作区失败: %v", err)
 	}
+	otherWorkspace := &model.Workspace{
+		Name: "Team Create Other " + prefix,
+		Slug: "team-create-other-" + prefix,
+	}
+	if err := tx.Create(otherWorkspace).Error; err != nil {
+		t.Fatalf("创建测试工作区失败: %v", err)
+	}
 
 	tests := []struct {
 		name    string
@@ -303,6 +371,15 @@ func TestTeamStore_Create(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "不同工作区可使用相同 Key",
+			team: &model.Team{
+				WorkspaceID: otherWorkspace.ID,
+				Name:        "Other Workspace Team",
+				Key:         "NTABC",
+			},
+			wantErr: false,
+		},
 		{
 			name: "Key 格式错误 - 小写",
 			team: &model.Team{
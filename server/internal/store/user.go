@@ -3,7 +3,9 @@ package store
 
 import (
 	"context"
+	"strings"
 
+	"github.com/google/uuid"
 	"github.com/liwei0526vip/mylinear/internal/model"
 	"gorm.io/gorm"
 )
@@ -18,8 +20,12 @@ type UserStore interface {
 	GetUserByID(ctx context.Context, id string) (*model.User, error)
 	// GetUserByUsername 通过用户名获取用户
 	GetUserByUsername(ctx context.Context, username string) (*model.User, error)
+	// GetWorkspaceUser 在工作区成员中按邮箱（包含 @ 时）或用户名查找用户，不是成员时返回 gorm.ErrRecordNotFound
+	GetWorkspaceUser(ctx context.Context, workspaceID uuid.UUID, login string) (*model.User, error)
 	// UpdateUser 更新用户信息
 	UpdateUser(ctx context.Context, user *model.User) error
+	// HasUsers 系统中是否已有用户（包括已停用的用户）
+	HasUsers(ctx context.Context) (bool, error)
 }
 
 // userStore 实现 UserStore 接口
@@ -32,9 +38,19 @@ func NewUserStore(db *gorm.DB) UserStore {
 	return &userStore{db: db}
 }
 
-// CreateUser 创建新用户（存根实现，后续 TDD 完善）
+// CreateUser 创建新用户，并在同一事务中加入主工作区
 func (s *userStore) CreateUser(ctx context.Context, user *model.User) error {
-	return s.db.WithContext(ctx).Create(user).Error
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.WorkspaceMember{
+			WorkspaceID: user.WorkspaceID,
+			UserID:      user.ID,
+			Role:        user.Role,
+			JoinedAt:    user.CreatedAt,
+		}).Error
+	})
 }
 
 // GetUserByEmail 通过邮箱获取用户（存根实现，后续 TDD 完善）
@@ -67,6 +83,32 @@ func (s *userStore) GetUserByUsername(ctx context.Context, username string) (*mo
 	return &user, nil
 }
 
+// HasUsers 系统中是否已有用户
+func (s *userStore) HasUsers(ctx context.Context) (bool, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&model.User{}).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetWorkspaceUser 在工作区成员中查找用户
+func (s *userStore) GetWorkspaceUser(ctx context.Context, workspaceID uuid.UUID, login string) (*model.User, error) {
+	column := "users.username"
+	if strings.Contains(login, "@") {
+		column = "users.email"
+	}
+	var user model.User
+	err := s.db.WithContext(ctx).
+		Joins("JOIN workspace_members ON workspace_members.user_id = users.id").
+		Where("workspace_members.workspace_id = ? AND "+column+" = ?", workspaceID, login).
+		First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 // UpdateUser 更新用户信息（存根实现，后续 TDD 完善）
 func (s *userStore) UpdateUser(ctx context.Context, user *model.User) error {
 	return s.db.WithContext(ctx).Save(user).Error
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"gorm.io/gorm"
+)
+
+// UserIdentityStore 定义外部身份数据访问接口
+type UserIdentityStore interface {
+	// GetBySubject 根据 issuer 和 subject 获取外部身份
+	GetBySubject(ctx context.Context, issuer, subject string) (*model.UserIdentity, error)
+	// ListByIssuer 获取指定 issuer 的所有外部身份
+	ListByIssuer(ctx context.Context, issuer string) ([]model.UserIdentity, error)
+	// Create 创建外部身份
+	Create(ctx context.Context, identity *model.UserIdentity) error
+	// TouchLogin 更新最近登录时间和邮箱
+	TouchLogin(ctx context.Context, id uuid.UUID, email string) error
+}
+
+// userIdentityStore 实现 UserIdentityStore 接口
+type userIdentityStore struct {
+	db *gorm.DB
+}
+
+// NewUserIdentityStore 创建外部身份存储实例
+func NewUserIdentityStore(db *gorm.DB) UserIdentityStore {
+	return &userIdentityStore{db: db}
+}
+
+// GetBySubject 根据 issuer 和 subject 获取外部身份
+func (s *userIdentityStore) GetBySubject(ctx context.Context, issuer, subject string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := s.db.WithContext(ctx).
+		Where("issuer = ? AND subject = ?", issuer, subject).
+		First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// ListByIssuer 获取指定 issuer 的所有外部身份
+func (s *userIdentityStore) ListByIssuer(ctx context.Context, issuer string) ([]model.UserIdentity, error) {
+	var identities []model.UserIdentity
+	err := s.db.WithContext(ctx).
+		Where("issuer = ?", issuer).
+		Order("created_at ASC").
+		Find(&identities).Error
+	if err != nil {
+		return nil, fmt.Errorf("查询外部身份失败: %w", err)
+	}
+	return identities, nil
+}
+
+// Create 创建外部身份
+func (s *userIdentityStore) Create(ctx context.Context, identity *model.UserIdentity) error {
+	if err := s.db.WithContext(ctx).Create(identity).Error; err != nil {
+		return fmt.Errorf("创建外部身份失败: %w", err)
+	}
+	return nil
+}
+
+// TouchLogin 更新最近登录时间和邮箱
+func (s *userIdentityStore) TouchLogin(ctx context.Context, id uuid.UUID, email string) error {
+	err := s.db.WithContext(ctx).
+		Model(&model.UserIdentity{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"last_login_at": time.Now(), "email": email}).Error
+	if err != nil {
+		return fmt.Errorf("更新外部身份失败: %w", err)
+	}
+	return nil
+}
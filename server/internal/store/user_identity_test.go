@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserIdentityStore(t *testing.T) {
+	if testDB == nil {
+		t.Skip("数据库连接不可用")
+	}
+
+	tx := testDB.Begin()
+	defer tx.Rollback()
+
+	ctx := context.Background()
+	identityStore := NewUserIdentityStore(tx)
+	_, user, _, _ := setupIssueTestFixtures(t, tx)
+
+	identity := &model.UserIdentity{
+		UserID:  user.ID,
+		Issuer:  "https://idp.example.com",
+		Subject: "user-1",
+		Email:   user.Email,
+	}
+	assert.NoError(t, identityStore.Create(ctx, identity))
+
+	found, err := identityStore.GetBySubject(ctx, identity.Issuer, identity.Subject)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, found.UserID)
+
+	// 不同 issuer 的相同 subject 是不同身份
+	_, err = identityStore.GetBySubject(ctx, "https://other.example.com", identity.Subject)
+	assert.Error(t, err)
+
+	// (issuer, subject) 唯一
+	duplicate := &model.UserIdentity{UserID: user.ID, Issuer: identity.Issuer, Subject: identity.Subject}
+	assert.NoError(t, tx.SavePoint("dup").Error)
+	assert.Error(t, identityStore.Create(ctx, duplicate))
+	tx.RollbackTo("dup")
+
+	assert.NoError(t, identityStore.TouchLogin(ctx, identity.ID, "new@example.com"))
+	found, err = identityStore.GetBySubject(ctx, identity.Issuer, identity.Subject)
+	assert.NoError(t, err)
+	assert.Equal(t, "new@example.com", found.Email)
+	assert.NotNil(t, found.LastLoginAt)
+
+	identities, err := identityStore.ListByIssuer(ctx, identity.Issuer)
+	assert.NoError(t, err)
+	assert.Len(t, identities, 1)
+	identities, err = identityStore.ListByIssuer(ctx, "https://other.example.com")
+	assert.NoError(t, err)
+	assert.Empty(t, identities)
+}
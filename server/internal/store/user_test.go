@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -39,9 +40,15 @@ func TestMain(m *testing.M) {
 	// 运行迁移（自动迁移测试表）
 	// 先清理旧表，避免 schema 不一致
 	// 注意：CASCADE 会删除依赖表，顺序不重要，但全面清理更安全
+	db.Exec("DROP TABLE IF EXISTS audit_logs CASCADE")
+	db.Exec("DROP TABLE IF EXISTS workspace_members CASCADE")
+	db.Exec("DROP TABLE IF EXISTS team_key_aliases CASCADE")
+	db.Exec("DROP TABLE IF EXISTS issue_identifier_aliases CASCADE")
+	db.Exec("DROP TABLE IF EXISTS user_sessions CASCADE")
 	db.Exec("DROP TABLE IF EXISTS comments CASCADE")
 	db.Exec("DROP TABLE IF EXISTS activities CASCADE")
 	db.Exec("DROP TABLE IF EXISTS issue_subscriptions CASCADE")
+	db.Exec("DROP TABLE IF EXISTS issue_closure CASCADE")
 	db.Exec("DROP TABLE IF EXISTS issues CASCADE")
 	db.Exec("DROP TABLE IF EXISTS labels CASCADE")
 	db.Exec("DROP TABLE IF EXISTS workflow_states CASCADE")
@@ -54,14 +61,28 @@ func TestMain(m *testing.M) {
 	err = db.AutoMigrate(
 		&model.Workspace{},
 		&model.User{},
+		&model.WorkspaceMember{},
 		&model.Team{},
 		&model.TeamMember{},
 		&model.WorkflowState{},
 		&model.Label{},
 		&model.Issue{},
+		&model.IssueClosure{},
 		&model.IssueSubscription{},
 		&model.Activity{},
 		&model.Comment{},
+		&model.IssueIdentifierAlias{},
+		&model.TeamKeyAlias{},
+		&model.APIKey{},
+		&model.OAuthApplication{},
+		&model.OAuthAuthorizationCode{},
+		&model.OAuthGrant{},
+		&model.UserIdentity{},
+		&model.RecoveryCode{},
+		&model.Session{},
+		&model.PasswordResetToken{},
+		&model.Invitation{},
+		&model.AuditLog{},
 	)
 	if err != nil {
 		fmt.Printf("警告: 自动迁移失败: %v\n", err)
@@ -449,6 +470,86 @@ func TestUserStore_GetUserByUsername(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// GetWorkspaceUser 测试
+// =============================================================================
+
+func TestUserStore_GetWorkspaceUser(t *testing.T) {
+	tx := testDB.Begin()
+	defer tx.Rollback()
+
+	store := NewUserStore(tx)
+	ctx := context.Background()
+	prefix := uuid.New().String()[:8]
+
+	user := &model.User{
+		WorkspaceID:  testWorkspaceID,
+		Email:        prefix + "_member@example.com",
+		Username:     prefix + "_member",
+		Name:         "Workspace Member",
+		PasswordHash: "hash",
+		Role:         model.RoleMember,
+	}
+	if err := store.CreateUser(ctx, user); err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	other := &model.Workspace{Name: "Other", Slug: prefix + "-other"}
+	if err := tx.Create(other).Error; err != nil {
+		t.Fatalf("创建工作区失败: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		workspaceID uuid.UUID
+		login       string
+		wantFound   bool
+	}{
+		{name: "按邮箱查找", workspaceID: testWorkspaceID, login: user.Email, wantFound: true},
+		{name: "按用户名查找", workspaceID: testWorkspaceID, login: user.Username, wantFound: true},
+		{name: "不是工作区成员", workspaceID: other.ID, login: user.Email, wantFound: false},
+		{name: "用户不存在", workspaceID: testWorkspaceID, login: prefix + "_nobody", wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := store.GetWorkspaceUser(ctx, tt.workspaceID, tt.login)
+			if tt.wantFound {
+				if err != nil || found == nil || found.ID != user.ID {
+					t.Errorf("GetWorkspaceUser() = %v, %v, want 用户 %v", found, err, user.ID)
+				}
+				return
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				t.Errorf("GetWorkspaceUser() error = %v, want gorm.ErrRecordNotFound", err)
+			}
+		})
+	}
+}
+
+func TestUserStore_HasUsers(t *testing.T) {
+	tx := testDB.Begin()
+	defer tx.Rollback()
+
+	store := NewUserStore(tx)
+	ctx := context.Background()
+	prefix := uuid.New().String()[:8]
+
+	user := &model.User{
+		WorkspaceID:  testWorkspaceID,
+		Email:        prefix + "_hasusers@example.com",
+		Username:     prefix + "_hasusers",
+		Name:         "Has Users",
+		PasswordHash: "hash",
+		Role:         model.RoleMember,
+	}
+	if err := store.CreateUser(ctx, user); err != nil {
+		t.Fatalf("创建用户失败: %v", err)
+	}
+	if has, err := store.HasUsers(ctx); err != nil || !has {
+		t.Errorf("HasUsers() = %v, %v, want true", has, err)
+	}
+}
+
 // =============================================================================
 // UpdateUser 测试
 // =============================================================================
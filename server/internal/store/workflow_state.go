@@ -107,3 +107,12 @@ func (s *workflowStateStore) CountByTeamID(ctx context.Context, teamID uuid.UUID
 		Count(&count).Error
 	return count, err
 }
+
+// WorkflowStateTeamID 获取工作流状态所属的团队，状态不存在时返回 gorm.ErrRecordNotFound
+func WorkflowStateTeamID(ctx context.Context, db *gorm.DB, stateID uuid.UUID) (uuid.UUID, error) {
+	var state model.WorkflowState
+	if err := db.WithContext(ctx).Select("team_id").Where("id = ?", stateID).First(&state).Error; err != nil {
+		return uuid.Nil, err
+	}
+	return state.TeamID, nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/google/uuid"
 	"github.com/liwei0526vip/mylinear/internal/model"
 	"gorm.io/gorm"
 )
@@ -13,12 +14,14 @@ import (
 type WorkspaceStore interface {
 	// GetByID 通过 ID 获取工作区
 	GetByID(ctx context.Context, id string) (*model.Workspace, error)
+	// GetBySlug 通过 Slug 获取工作区
+	GetBySlug(ctx context.Context, slug string) (*model.Workspace, error)
 	// Create 创建工作区
 	Create(ctx context.Context, workspace *model.Workspace) error
 	// Update 更新工作区信息
 	Update(ctx context.Context, workspace *model.Workspace) error
-	// GetStats 获取工作区统计信息
-	GetStats(ctx context.Context, id string) (*WorkspaceStats, error)
+	// GetStats 获取工作区统计信息，只统计 userID 可见的团队（uuid.Nil 时只统计公开团队）
+	GetStats(ctx context.Context, id string, userID uuid.UUID) (*WorkspaceStats, error)
 }
 
 // WorkspaceStats 工作区统计信息
@@ -48,6 +51,16 @@ func (s *workspaceStore) GetByID(ctx context.Context, id string) (*model.Workspa
 	return &workspace, nil
 }
 
+// GetBySlug 通过 Slug 获取工作区
+func (s *workspaceStore) GetBySlug(ctx context.Context, slug string) (*model.Workspace, error) {
+	var workspace model.Workspace
+	err := s.db.WithContext(ctx).Where("slug = ?", slug).First(&workspace).Error
+	if err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
 // Create 创建工作区
 func (s *workspaceStore) Create(ctx context.Context, workspace *model.Workspace) error {
 	return s.db.WithContext(ctx).Create(workspace).Error
@@ -59,21 +72,23 @@ func (s *workspaceStore) Update(ctx context.Context, workspace *model.Workspace)
 }
 
 // GetStats 获取工作区统计信息
-func (s *workspaceStore) GetStats(ctx context.Context, id string) (*WorkspaceStats, error) {
+func (s *workspaceStore) GetStats(ctx context.Context, id string, userID uuid.UUID) (*WorkspaceStats, error) {
 	stats := &WorkspaceStats{}
 
-	// 统计团队数量
+	// 统计团队数量（不包括用户不在其中的私有团队）
 	if err := s.db.WithContext(ctx).
 		Model(&model.Team{}).
 		Where("workspace_id = ?", id).
+		Where("id IN (?)", visibleTeamIDs(s.db, userID.String())).
 		Count(&stats.TeamsCount).Error; err != nil {
 		return nil, fmt.Errorf("统计团队数量失败: %w", err)
 	}
 
-	// 统计成员数量
+	// 统计成员数量（主工作区为该工作区的用户和加入该工作区的其他用户）
 	if err := s.db.WithContext(ctx).
 		Model(&model.User{}).
-		Where("workspace_id = ?", id).
+		Where("workspace_id = ? OR id IN (?)", id,
+			s.db.Model(&model.WorkspaceMember{}).Select("user_id").Where("workspace_id = ?", id)).
 		Count(&stats.MembersCount).Error; err != nil {
 		return nil, fmt.Errorf("统计成员数量失败: %w", err)
 	}
@@ -83,6 +98,7 @@ func (s *workspaceStore) GetStats(ctx context.Context, id string) (*WorkspaceSta
 		Table("issues").
 		Joins("JOIN teams ON teams.id = issues.team_id").
 		Where("teams.workspace_id = ?", id).
+		Where("teams.id IN (?)", visibleTeamIDs(s.db, userID.String())).
 		Count(&stats.IssuesCount).Error; err != nil {
 		return nil, fmt.Errorf("统计 Issue 数量失败: %w", err)
 	}
@@ -0,0 +1,129 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WorkspaceMemberStore 定义工作区成员数据访问接口
+type WorkspaceMemberStore interface {
+	// Add 添加工作区成员，已是成员时不修改角色
+	Add(ctx context.Context, member *model.WorkspaceMember) error
+	// Get 获取用户在工作区的成员记录，不是成员时返回 gorm.ErrRecordNotFound
+	Get(ctx context.Context, workspaceID, userID uuid.UUID) (*model.WorkspaceMember, error)
+	// ListByUser 获取用户加入的所有工作区（预加载工作区，按加入时间排序）
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]model.WorkspaceMember, error)
+	// SetRole 设置用户在工作区的角色，不是成员时加入工作区
+	SetRole(ctx context.Context, workspaceID, userID uuid.UUID, role model.Role) error
+}
+
+// workspaceMemberStore 实现 WorkspaceMemberStore 接口
+type workspaceMemberStore struct {
+	db *gorm.DB
+}
+
+// NewWorkspaceMemberStore 创建工作区成员存储实例
+func NewWorkspaceMemberStore(db *gorm.DB) WorkspaceMemberStore {
+	return &workspaceMemberStore{db: db}
+}
+
+// Add 添加工作区成员
+func (s *workspaceMemberStore) Add(ctx context.Context, member *model.WorkspaceMember) error {
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(member).Error
+	if err != nil {
+		return fmt.Errorf("加入工作区失败: %w", err)
+	}
+	return nil
+}
+
+// Get 获取用户在工作区的成员记录
+func (s *workspaceMemberStore) Get(ctx context.Context, workspaceID, userID uuid.UUID) (*model.WorkspaceMember, error) {
+	var member model.WorkspaceMember
+	err := s.db.WithContext(ctx).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ListByUser 获取用户加入的所有工作区
+func (s *workspaceMemberStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]model.WorkspaceMember, error) {
+	var members []model.WorkspaceMember
+	err := s.db.WithContext(ctx).
+		Preload("Workspace").
+		Where("user_id = ?", userID).
+		Order("joined_at ASC").
+		Find(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("获取工作区列表失败: %w", err)
+	}
+	return members, nil
+}
+
+// SetRole 设置用户在工作区的角色
+func (s *workspaceMemberStore) SetRole(ctx context.Context, workspaceID, userID uuid.UUID, role model.Role) error {
+	err := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "workspace_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"role"}),
+		}).
+		Create(&model.WorkspaceMember{WorkspaceID: workspaceID, UserID: userID, Role: role, JoinedAt: time.Now()}).Error
+	if err != nil {
+		return fmt.Errorf("设置工作区角色失败: %w", err)
+	}
+	return nil
+}
+
+// UserWorkspaceID 获取用户的主工作区，用户不存在时返回 gorm.ErrRecordNotFound
+func UserWorkspaceID(ctx context.Context, db *gorm.DB, userID uuid.UUID) (uuid.UUID, error) {
+	var user model.User
+	err := db.WithContext(ctx).Select("workspace_id").Where("id = ?", userID).First(&user).Error
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return user.WorkspaceID, nil
+}
+
+// ProjectWorkspaceID 获取项目所属的工作区，项目不存在时返回 gorm.ErrRecordNotFound
+func ProjectWorkspaceID(ctx context.Context, db *gorm.DB, projectID uuid.UUID) (uuid.UUID, error) {
+	var project model.Project
+	err := db.WithContext(ctx).Select("workspace_id").Where("id = ?", projectID).First(&project).Error
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return project.WorkspaceID, nil
+}
+
+// TeamWorkspaceID 获取团队所属的工作区，团队不存在时返回 gorm.ErrRecordNotFound
+func TeamWorkspaceID(ctx context.Context, db *gorm.DB, teamID uuid.UUID) (uuid.UUID, error) {
+	var team model.Team
+	err := db.WithContext(ctx).Select("workspace_id").Where("id = ?", teamID).First(&team).Error
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return team.WorkspaceID, nil
+}
+
+// IssueWorkspaceID 获取 Issue 所属的工作区（包括已删除的 Issue），Issue 不存在时返回 gorm.ErrRecordNotFound
+func IssueWorkspaceID(ctx context.Context, db *gorm.DB, issueID uuid.UUID) (uuid.UUID, error) {
+	var team model.Team
+	err := db.WithContext(ctx).Unscoped().
+		Select("teams.workspace_id").
+		Joins("JOIN issues ON issues.team_id = teams.id").
+		Where("issues.id = ?", issueID).
+		First(&team).Error
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return team.WorkspaceID, nil
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/liwei0526vip/mylinear/internal/model"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestWorkspaceMemberStore(t *testing.T) {
+	if testDB == nil {
+		t.Skip("数据库连接不可用")
+	}
+
+	tx := testDB.Begin()
+	defer tx.Rollback()
+
+	ctx := context.Background()
+	memberStore := NewWorkspaceMemberStore(tx)
+	home, _, _, _ := setupIssueTestFixtures(t, tx)
+
+	// 通过 CreateUser 创建的用户自动成为主工作区成员
+	user := &model.User{
+		WorkspaceID:  home.ID,
+		Email:        "member-" + uuid.New().String()[:8] + "@example.com",
+		Username:     "member" + uuid.New().String()[:8],
+		Name:         "Member",
+		PasswordHash: "hash",
+		Role:         model.RoleAdmin,
+	}
+	assert.NoError(t, NewUserStore(tx).CreateUser(ctx, user))
+	member, err := memberStore.Get(ctx, home.ID, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.RoleAdmin, member.Role)
+
+	other := &model.Workspace{Name: "Other WS", Slug: "other-ws-" + uuid.New().String()[:8]}
+	assert.NoError(t, tx.Create(other).Error)
+	_, err = memberStore.Get(ctx, other.ID, user.ID)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	// 重复加入不修改角色
+	assert.NoError(t, memberStore.Add(ctx, &model.WorkspaceMember{WorkspaceID: other.ID, UserID: user.ID, Role: model.RoleGuest}))
+	assert.NoError(t, memberStore.Add(ctx, &model.WorkspaceMember{WorkspaceID: other.ID, UserID: user.ID, Role: model.RoleAdmin}))
+	member, err = memberStore.Get(ctx, other.ID, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.RoleGuest, member.Role)
+
+	// SetRole 修改已有成员的角色，或加入新的工作区
+	assert.NoError(t, memberStore.SetRole(ctx, other.ID, user.ID, model.RoleMember))
+	member, err = memberStore.Get(ctx, other.ID, user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.RoleMember, member.Role)
+
+	members, err := memberStore.ListByUser(ctx, user.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, members, 2) {
+		assert.Equal(t, home.ID, members[0].WorkspaceID)
+		assert.NotNil(t, members[1].Workspace)
+		assert.Equal(t, "Other WS", members[1].Workspace.Name)
+	}
+
+	stats, err := NewWorkspaceStore(tx).GetStats(ctx, other.ID.String(), uuid.Nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), stats.MembersCount)
+}
@@ -290,6 +290,17 @@ func TestWorkspaceStore_GetStats(t *testing.T) {
 		t.Fatalf("创建测试团队失败: %v", err)
 	}
 
+	// 创建私有团队，用户不是成员，不计入统计
+	privateTeam := &model.Team{
+		WorkspaceID: workspace.ID,
+		Name:        "Private " + prefix,
+		Key:         "TP" + prefix[:3],
+		IsPrivate:   true,
+	}
+	if err := tx.Create(privateTeam).Error; err != nil {
+		t.Fatalf("创建私有团队失败: %v", err)
+	}
+
 	// 创建测试用户
 	user := &model.User{
 		WorkspaceID:  workspace.ID,
@@ -314,7 +325,7 @@ func TestWorkspaceStore_GetStats(t *testing.T) {
 			workspaceID: workspace.ID.String(),
 			wantErr:     false,
 			checkStats: func(stats *WorkspaceStats) bool {
-				return stats.TeamsCount >= 1 && stats.MembersCount >= 1
+				return stats.TeamsCount == 1 && stats.MembersCount >= 1
 			},
 		},
 		{
@@ -329,7 +340,7 @@ func TestWorkspaceStore_GetStats(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			stats, err := store.GetStats(ctx, tt.workspaceID)
+			stats, err := store.GetStats(ctx, tt.workspaceID, user.ID)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetStats() error = %v, wantErr %v", err, tt.wantErr)
 				return
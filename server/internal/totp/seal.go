@@ -0,0 +1,54 @@
+package totp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// Seal 使用 AES-256-GCM 加密密钥以便保存到数据库，加密密钥由 key 派生
+func Seal(key, secret string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open 解密 Seal 加密的密钥
+func Open(key, sealed string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.RawStdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("无效的加密 TOTP 密钥")
+	}
+	secret, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("解密 TOTP 密钥失败: %w", err)
+	}
+	return string(secret), nil
+}
+
+// newGCM 由 key 派生 AES-256 密钥
+func newGCM(key string) (cipher.AEAD, error) {
+	if key == "" {
+		return nil, fmt.Errorf("未配置 TOTP 加密密钥")
+	}
+	sum := sha256.Sum256([]byte("mylinear-totp:" + key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
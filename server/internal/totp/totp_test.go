@@ -0,0 +1,107 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret RFC 6238 附录 B 中 SHA1 测试向量使用的密钥
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode_RFC6238(t *testing.T) {
+	// RFC 6238 给出的是 8 位验证码，6 位验证码为其后 6 位
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	code, _ := Code(secret, Step(now))
+
+	step, ok := Validate(secret, code, now, 1)
+	if !ok || step != Step(now) {
+		t.Errorf("Validate() = %d, %v", step, ok)
+	}
+	// 允许一个时间步的时钟偏差
+	if _, ok := Validate(secret, code, now.Add(Period*time.Second), 1); !ok {
+		t.Error("应允许一个时间步的偏差")
+	}
+	if _, ok := Validate(secret, code, now.Add(2*Period*time.Second), 1); ok {
+		t.Error("超出偏差范围的验证码应无效")
+	}
+	// 小写、带空格的密钥同样有效
+	if _, ok := Validate(" "+strings.ToLower(secret), code, now, 0); !ok {
+		t.Error("密钥应忽略大小写和空格")
+	}
+	for _, bad := range []string{"", "12345", "1234567", "abcdef"} {
+		if _, ok := Validate(secret, bad, now, 1); ok {
+			t.Errorf("Validate(%q) 应无效", bad)
+		}
+	}
+	if _, ok := Validate("not base32!", code, now, 1); ok {
+		t.Error("无效的密钥应校验失败")
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("My Linear", "alice@example.com", "JBSWY3DPEHPK3PXP")
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" {
+		t.Errorf("Unexpected URI: %s", uri)
+	}
+	if u.Path != "/My Linear:alice@example.com" {
+		t.Errorf("Path = %q", u.Path)
+	}
+	q := u.Query()
+	if q.Get("secret") != "JBSWY3DPEHPK3PXP" || q.Get("issuer") != "My Linear" || q.Get("digits") != "6" {
+		t.Errorf("Query = %v", q)
+	}
+}
+
+func TestSeal(t *testing.T) {
+	sealed, err := Seal("key", "JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if strings.Contains(sealed, "JBSWY3DPEHPK3PXP") {
+		t.Error("密钥不应以明文保存")
+	}
+	opened, err := Open("key", sealed)
+	if err != nil || opened != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("Open() = %q, err = %v", opened, err)
+	}
+	if _, err := Open("other-key", sealed); err == nil {
+		t.Error("使用错误的密钥解密应失败")
+	}
+	if _, err := Seal("", "secret"); err == nil {
+		t.Error("未配置密钥时应返回错误")
+	}
+}
@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"errors"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// gormSpanKey 当前 SQL 的 span 在 gorm.Statement 中的键
+const gormSpanKey = "tracing:span"
+
+// gormPlugin 通过 GORM 回调为每条 SQL 建立子 span
+type gormPlugin struct{}
+
+// NewGormPlugin 创建 GORM 链路追踪插件，通过 db.Use 注册
+func NewGormPlugin() gorm.Plugin {
+	return gormPlugin{}
+}
+
+// Name 插件名称
+func (gormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize 在各类操作的回调链首尾注册 span 回调
+func (p gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("*").Register("tracing:before_create", p.before("create")),
+		cb.Create().After("*").Register("tracing:after_create", p.after("create")),
+		cb.Query().Before("*").Register("tracing:before_query", p.before("query")),
+		cb.Query().After("*").Register("tracing:after_query", p.after("query")),
+		cb.Update().Before("*").Register("tracing:before_update", p.before("update")),
+		cb.Update().After("*").Register("tracing:after_update", p.after("update")),
+		cb.Delete().Before("*").Register("tracing:before_delete", p.before("delete")),
+		cb.Delete().After("*").Register("tracing:after_delete", p.after("delete")),
+		cb.Row().Before("*").Register("tracing:before_row", p.before("row")),
+		cb.Row().After("*").Register("tracing:after_row", p.after("row")),
+		cb.Raw().Before("*").Register("tracing:before_raw", p.before("raw")),
+		cb.Raw().After("*").Register("tracing:after_raw", p.after("raw")),
+	)
+}
+
+// before 开始 span；Preload 等关联查询使用同一个上下文，会成为并列的子 span
+func (gormPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !hasParent(ctx) {
+			return
+		}
+		_, span := Tracer().Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemNamePostgreSQL, semconv.DBOperationName(operation)),
+		)
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+// after 补充表名和 SQL（只含占位符，不含参数值）后结束 span；记录不存在不计为错误
+func (gormPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(gormSpanKey)
+		if !ok {
+			return
+		}
+		span, ok := value.(trace.Span)
+		if !ok {
+			return
+		}
+		defer span.End()
+
+		if table := db.Statement.Table; table != "" {
+			span.SetName("gorm." + operation + " " + table)
+			span.SetAttributes(semconv.DBCollectionName(table))
+		}
+		span.SetAttributes(semconv.DBQueryText(db.Statement.SQL.String()), semconv.DBResponseReturnedRows(int(db.Statement.RowsAffected)))
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			RecordError(span, db.Error)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// redisHook 为每条 Redis 命令建立子 span（不记录参数，避免泄露令牌等敏感数据）
+type redisHook struct{}
+
+// NewRedisHook 创建 Redis 链路追踪钩子，通过 rdb.AddHook 注册
+func NewRedisHook() redis.Hook {
+	return redisHook{}
+}
+
+// DialHook 不记录建立连接
+func (redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook 记录单条命令
+func (redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !hasParent(ctx) {
+			return next(ctx, cmd)
+		}
+		ctx, span := startRedisSpan(ctx, cmd.Name())
+		defer span.End()
+
+		err := next(ctx, cmd)
+		recordRedisError(span, err)
+		return err
+	}
+}
+
+// ProcessPipelineHook 管道（含事务）作为一个 span 记录
+func (redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !hasParent(ctx) {
+			return next(ctx, cmds)
+		}
+		ctx, span := startRedisSpan(ctx, "pipeline")
+		defer span.End()
+		span.SetAttributes(attribute.Int("db.operation.batch.size", len(cmds)))
+
+		err := next(ctx, cmds)
+		recordRedisError(span, err)
+		return err
+	}
+}
+
+// startRedisSpan 开始 Redis 命令的 span
+func startRedisSpan(ctx context.Context, command string) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, "redis."+command,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemNameRedis, semconv.DBOperationName(command)),
+	)
+}
+
+// recordRedisError 键不存在属于正常结果，不计为错误
+func recordRedisError(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, redis.Nil) {
+		RecordError(span, err)
+	}
+}
@@ -0,0 +1,107 @@
+// Package tracing 初始化 OpenTelemetry 链路追踪（W3C traceparent 传播，导出到 OTLP 收集器或标准输出），
+// 并提供服务方法、GORM、Redis 的埋点辅助
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName 服务自身埋点使用的 Tracer 名称
+const instrumentationName = "github.com/liwei0526vip/mylinear"
+
+// 导出方式
+const (
+	// ExporterNone 不导出（仍然传播上游的 traceparent）
+	ExporterNone = "none"
+	// ExporterOTLP 通过 OTLP/HTTP 导出到收集器
+	ExporterOTLP = "otlp"
+	// ExporterStdout 输出到标准输出，用于本地调试
+	ExporterStdout = "stdout"
+)
+
+// Config 链路追踪配置
+type Config struct {
+	// Exporter 导出方式：none、otlp 或 stdout
+	Exporter string
+	// Endpoint OTLP/HTTP 收集器地址，如 http://localhost:4318
+	Endpoint string
+	// ServiceName 上报的服务名称
+	ServiceName string
+	// SampleRatio 根 span 的采样比例（0~1），上游已采样的请求始终采样
+	SampleRatio float64
+}
+
+// Setup 设置全局 TracerProvider 和 W3C 传播器，返回服务关闭时调用的清理函数（导出剩余的 span）
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("不支持的链路追踪导出方式 %q，可选 %s、%s 或 %s", cfg.Exporter, ExporterNone, ExporterOTLP, ExporterStdout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("创建链路追踪导出器失败: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("创建链路追踪资源失败: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracer 返回服务自身埋点使用的 Tracer
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start 开始一个内部 span，名称通常为 "<服务>.<方法>"（如 IssueService.UpdateIssue）
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// RecordError 在 span 上记录错误并标记为失败，err 为 nil 时不做任何事
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// hasParent 上下文中是否已有 span；没有上游 span 的数据库和 Redis 调用（如健康检查、后台清理）不单独建立链路
+func hasParent(ctx context.Context) bool {
+	return trace.SpanContextFromContext(ctx).IsValid()
+}
@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// useRecorder 安装记录 span 的 TracerProvider，测试结束后恢复
+func useRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return recorder
+}
+
+func TestSetup(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterNone})
+	if err != nil {
+		t.Fatalf("Setup(none) error = %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+
+	if _, err := Setup(context.Background(), Config{Exporter: "zipkin"}); err == nil {
+		t.Error("不支持的导出方式应返回错误")
+	}
+}
+
+func TestStartAndRecordError(t *testing.T) {
+	recorder := useRecorder(t)
+
+	ctx, parent := Start(context.Background(), "IssueService.UpdateIssue")
+	_, child := Start(ctx, "CommentService.CreateComment")
+	RecordError(child, errors.New("评论不存在"))
+	RecordError(child, nil)
+	child.End()
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("span 数量 = %d, want 2", len(spans))
+	}
+	if spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+		t.Error("子 span 的父级应为外层 span")
+	}
+	if spans[0].Status().Code != codes.Error || len(spans[0].Events()) != 1 {
+		t.Errorf("子 span 状态 = %v, 事件数 = %d", spans[0].Status().Code, len(spans[0].Events()))
+	}
+	if spans[1].Status().Code == codes.Error {
+		t.Error("外层 span 不应标记为失败")
+	}
+}
+
+func TestHasParent(t *testing.T) {
+	useRecorder(t)
+
+	if hasParent(context.Background()) {
+		t.Error("空上下文不应有父级 span")
+	}
+	ctx, span := Start(context.Background(), "test")
+	defer span.End()
+	if !hasParent(ctx) {
+		t.Error("应识别出父级 span")
+	}
+}
+
+func TestGormPlugin_Register(t *testing.T) {
+	// 不连接数据库，只验证回调能注册到各类操作上
+	db, err := gorm.Open(postgres.Open("postgres://localhost:1/tracing_test"), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	if err := db.Use(NewGormPlugin()); err != nil {
+		t.Fatalf("db.Use() error = %v", err)
+	}
+	if db.Callback().Query().Get("tracing:after_query") == nil {
+		t.Error("未注册查询回调")
+	}
+}
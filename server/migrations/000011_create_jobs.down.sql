@@ -0,0 +1,3 @@
+-- 000011_create_jobs.down.sql
+-- 删除后台任务表
+DROP TABLE IF EXISTS jobs;
@@ -0,0 +1,30 @@
+-- 000011_create_jobs.up.sql
+-- 后台任务表：用于 Issue 导入等耗时操作
+
+CREATE TABLE jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID REFERENCES workspaces(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    payload JSONB DEFAULT '{}',
+    progress INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0,
+    result JSONB,
+    error TEXT,
+    created_by_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_job_status CHECK (status IN ('pending', 'running', 'succeeded', 'failed'))
+);
+
+CREATE INDEX idx_jobs_workspace_id ON jobs(workspace_id);
+CREATE INDEX idx_jobs_type ON jobs(type);
+CREATE INDEX idx_jobs_status ON jobs(status);
+CREATE INDEX idx_jobs_created_by_id ON jobs(created_by_id);
+
+COMMENT ON TABLE jobs IS '后台任务表';
+COMMENT ON COLUMN jobs.type IS '任务类型：issue_import 等';
+COMMENT ON COLUMN jobs.payload IS '任务输入参数';
+COMMENT ON COLUMN jobs.result IS '任务执行结果（含行级错误等）';
@@ -0,0 +1,2 @@
+-- 删除外部引用表
+DROP TABLE IF EXISTS external_references;
@@ -0,0 +1,18 @@
+-- 外部引用表：记录从 Jira / GitHub / Linear 导入的数据与本地实体的对应关系
+CREATE TABLE external_references (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    source VARCHAR(30) NOT NULL,
+    entity_type VARCHAR(20) NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    entity_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_external_ref_entity_type CHECK (entity_type IN ('issue', 'comment'))
+);
+
+-- 同一团队内同一来源的外部 ID 只能导入一次
+CREATE UNIQUE INDEX idx_external_ref_unique ON external_references(team_id, source, entity_type, external_id);
+CREATE INDEX idx_external_references_entity_id ON external_references(entity_id);
+
+COMMENT ON TABLE external_references IS '外部系统 ID 映射表（导入幂等）';
+COMMENT ON COLUMN external_references.source IS '导入来源：jira_xml, jira_csv, github_json, linear_csv';
@@ -0,0 +1,2 @@
+-- 删除 Issue 外部链接表
+DROP TABLE IF EXISTS issue_links;
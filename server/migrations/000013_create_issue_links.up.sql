@@ -0,0 +1,23 @@
+-- Issue 外部链接表：记录通过 GitHub / GitLab Webhook 关联到 Issue 的 PR 和提交
+CREATE TABLE issue_links (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    issue_id UUID NOT NULL REFERENCES issues(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    url TEXT NOT NULL,
+    title VARCHAR(500),
+    ref VARCHAR(100),
+    branch VARCHAR(255),
+    state VARCHAR(20),
+    author VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT chk_issue_link_provider CHECK (provider IN ('github', 'gitlab')),
+    CONSTRAINT chk_issue_link_type CHECK (type IN ('pull_request', 'commit'))
+);
+
+-- 同一链接在同一 Issue 上只记录一次，重复事件更新状态
+CREATE UNIQUE INDEX idx_issue_link_unique ON issue_links(issue_id, url);
+
+COMMENT ON TABLE issue_links IS 'Issue 关联的 PR / 提交';
+COMMENT ON COLUMN issue_links.state IS 'PR 状态：open, merged, closed；提交为空';
@@ -0,0 +1,3 @@
+-- 删除历史标识符表
+DROP TABLE IF EXISTS team_key_aliases;
+DROP TABLE IF EXISTS issue_identifier_aliases;
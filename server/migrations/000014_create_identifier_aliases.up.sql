@@ -0,0 +1,25 @@
+-- Issue 历史标识符：Issue 移动到其他团队后，旧标识符（如 ENG-42）仍可解析
+CREATE TABLE issue_identifier_aliases (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    issue_id UUID NOT NULL REFERENCES issues(id) ON DELETE CASCADE,
+    team_key VARCHAR(10) NOT NULL,
+    number INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_issue_alias_identifier ON issue_identifier_aliases(team_key, number);
+CREATE INDEX idx_issue_identifier_aliases_issue_id ON issue_identifier_aliases(issue_id);
+
+-- 团队历史 Key：修改团队 Key 后，旧 Key 开头的标识符仍可解析
+CREATE TABLE team_key_aliases (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    key VARCHAR(10) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_team_key_aliases_key ON team_key_aliases(key);
+CREATE INDEX idx_team_key_aliases_team_id ON team_key_aliases(team_id);
+
+COMMENT ON TABLE issue_identifier_aliases IS 'Issue 历史标识符';
+COMMENT ON TABLE team_key_aliases IS '团队历史 Key';
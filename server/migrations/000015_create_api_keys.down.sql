@@ -0,0 +1,2 @@
+-- 删除个人 API Key 表
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,21 @@
+-- 个人 API Key 表：用于脚本和集成调用 API，只保存 SHA-256 摘要
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(20) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_api_keys_key_hash ON api_keys(key_hash);
+CREATE INDEX idx_api_keys_user_id ON api_keys(user_id);
+
+COMMENT ON TABLE api_keys IS '个人 API Key';
+COMMENT ON COLUMN api_keys.prefix IS '明文前缀，用于在列表中识别 Key';
+COMMENT ON COLUMN api_keys.scopes IS '权限范围：read, write, admin';
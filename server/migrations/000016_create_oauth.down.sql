@@ -0,0 +1,4 @@
+-- 删除 OAuth2 授权服务相关表
+DROP TABLE IF EXISTS oauth_grants;
+DROP TABLE IF EXISTS oauth_authorization_codes;
+DROP TABLE IF EXISTS oauth_applications;
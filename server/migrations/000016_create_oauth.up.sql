@@ -0,0 +1,59 @@
+-- OAuth2 授权服务：第三方应用、授权码和用户授权
+CREATE TABLE oauth_applications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    client_id VARCHAR(64) NOT NULL,
+    client_secret_hash VARCHAR(64),
+    redirect_uris TEXT[] NOT NULL,
+    scopes TEXT[] NOT NULL,
+    confidential BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_oauth_applications_client_id ON oauth_applications(client_id);
+CREATE INDEX idx_oauth_applications_workspace_id ON oauth_applications(workspace_id);
+
+-- 授权码：只保存摘要，单次使用，短期有效
+CREATE TABLE oauth_authorization_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code_hash VARCHAR(64) NOT NULL,
+    application_id UUID NOT NULL REFERENCES oauth_applications(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    redirect_uri TEXT NOT NULL,
+    scopes TEXT[] NOT NULL,
+    code_challenge VARCHAR(128) NOT NULL,
+    code_challenge_method VARCHAR(10) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_oauth_authorization_codes_code_hash ON oauth_authorization_codes(code_hash);
+CREATE INDEX idx_oauth_authorization_codes_application_id ON oauth_authorization_codes(application_id);
+
+-- 用户授权：保存刷新令牌摘要，吊销后访问令牌同时失效
+CREATE TABLE oauth_grants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    application_id UUID NOT NULL REFERENCES oauth_applications(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    authorization_code_id UUID,
+    scopes TEXT[] NOT NULL,
+    refresh_token_hash VARCHAR(64) NOT NULL,
+    refresh_expires_at TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_oauth_grants_refresh_token_hash ON oauth_grants(refresh_token_hash);
+CREATE INDEX idx_oauth_grants_application_id ON oauth_grants(application_id);
+CREATE INDEX idx_oauth_grants_user_id ON oauth_grants(user_id);
+CREATE INDEX idx_oauth_grants_authorization_code_id ON oauth_grants(authorization_code_id);
+
+COMMENT ON TABLE oauth_applications IS 'OAuth2 客户端应用';
+COMMENT ON TABLE oauth_authorization_codes IS 'OAuth2 授权码';
+COMMENT ON TABLE oauth_grants IS 'OAuth2 用户授权';
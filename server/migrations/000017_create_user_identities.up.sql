@@ -0,0 +1,18 @@
+-- 外部身份表：记录用户在 OIDC 身份提供方的身份，用于单点登录
+CREATE TABLE user_identities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issuer VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    last_login_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_user_identities_issuer_subject ON user_identities(issuer, subject);
+CREATE INDEX idx_user_identities_user_id ON user_identities(user_id);
+
+COMMENT ON TABLE user_identities IS '外部身份（OIDC）';
+COMMENT ON COLUMN user_identities.subject IS 'ID Token 中的 sub 声明';
+COMMENT ON COLUMN user_identities.email IS '最近一次登录时 IdP 提供的邮箱';
@@ -0,0 +1,2 @@
+-- 删除用户停用时间
+ALTER TABLE users DROP COLUMN IF EXISTS deactivated_at;
@@ -0,0 +1,4 @@
+-- 用户停用时间：目录同步中已删除或停用的账号被停用而不是删除，保留其 Issue 和评论
+ALTER TABLE users ADD COLUMN deactivated_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN users.deactivated_at IS '停用时间，非空时禁止登录';
@@ -0,0 +1,22 @@
+-- 两步验证：用户的 TOTP 密钥和一次性恢复码
+ALTER TABLE users ADD COLUMN totp_secret TEXT;
+ALTER TABLE users ADD COLUMN totp_enabled_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN users.totp_secret IS '加密保存的 TOTP 密钥';
+COMMENT ON COLUMN users.totp_enabled_at IS '启用两步验证的时间，为空表示未启用';
+COMMENT ON COLUMN users.totp_last_step IS '最近一次使用的验证码时间步，防止重放';
+
+CREATE TABLE user_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_recovery_codes_user_id ON user_recovery_codes(user_id);
+
+COMMENT ON TABLE user_recovery_codes IS '两步验证恢复码';
+COMMENT ON COLUMN user_recovery_codes.code_hash IS '恢复码的 SHA-256 摘要';
@@ -0,0 +1,2 @@
+-- 删除登录会话表
+DROP TABLE IF EXISTS user_sessions;
@@ -0,0 +1,21 @@
+-- 登录会话：每个会话对应一个刷新令牌家族，刷新时轮换令牌
+CREATE TABLE user_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    refresh_token_jti VARCHAR(64) NOT NULL,
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    revoke_reason VARCHAR(32) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_user_sessions_refresh_token_jti ON user_sessions(refresh_token_jti);
+CREATE INDEX idx_user_sessions_user_id ON user_sessions(user_id);
+
+COMMENT ON TABLE user_sessions IS '登录会话';
+COMMENT ON COLUMN user_sessions.refresh_token_jti IS '当前有效的刷新令牌 ID，旧令牌再次使用时吊销整个会话';
+COMMENT ON COLUMN user_sessions.revoke_reason IS '吊销原因：logout/revoked/password_changed/token_reuse';
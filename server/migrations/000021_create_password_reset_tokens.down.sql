@@ -0,0 +1,2 @@
+-- 删除密码重置令牌表
+DROP TABLE IF EXISTS password_reset_tokens;
@@ -0,0 +1,17 @@
+-- 密码重置令牌：只保存令牌哈希，使用一次后失效
+CREATE TABLE password_reset_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_password_reset_tokens_token_hash ON password_reset_tokens(token_hash);
+CREATE INDEX idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
+
+COMMENT ON TABLE password_reset_tokens IS '密码重置令牌';
+COMMENT ON COLUMN password_reset_tokens.token_hash IS '令牌的 SHA-256 哈希（十六进制）';
+COMMENT ON COLUMN password_reset_tokens.used_at IS '使用时间，非空表示令牌已失效';
@@ -0,0 +1,2 @@
+-- 删除工作区邀请表
+DROP TABLE IF EXISTS workspace_invitations;
@@ -0,0 +1,26 @@
+-- 工作区邀请：指定邮箱的邀请或可多人使用的邀请链接
+CREATE TABLE workspace_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    team_ids UUID[] NOT NULL DEFAULT '{}',
+    token_hash VARCHAR(64) NOT NULL,
+    invited_by_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ,
+    use_count INTEGER NOT NULL DEFAULT 0,
+    accepted_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_workspace_invitations_token_hash ON workspace_invitations(token_hash);
+CREATE INDEX idx_workspace_invitations_workspace_id ON workspace_invitations(workspace_id);
+CREATE INDEX idx_workspace_invitations_email ON workspace_invitations(workspace_id, email) WHERE email <> '';
+
+COMMENT ON TABLE workspace_invitations IS '工作区邀请';
+COMMENT ON COLUMN workspace_invitations.email IS '受邀邮箱，为空表示邀请链接（过期或撤销前可多人使用）';
+COMMENT ON COLUMN workspace_invitations.team_ids IS '注册后自动加入的团队';
+COMMENT ON COLUMN workspace_invitations.token_hash IS '邀请令牌的 SHA-256 摘要（十六进制）';
@@ -0,0 +1,15 @@
+-- 回滚多工作区：团队 Key 恢复为全局唯一（存在重复 Key 时回滚会失败）
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS workspace_id;
+
+DROP INDEX IF EXISTS idx_issue_alias_identifier;
+CREATE UNIQUE INDEX idx_issue_alias_identifier ON issue_identifier_aliases(team_key, number);
+ALTER TABLE issue_identifier_aliases DROP COLUMN IF EXISTS workspace_id;
+
+DROP INDEX IF EXISTS idx_team_key_aliases_key;
+CREATE UNIQUE INDEX idx_team_key_aliases_key ON team_key_aliases(key);
+ALTER TABLE team_key_aliases DROP COLUMN IF EXISTS workspace_id;
+
+DROP INDEX IF EXISTS idx_teams_workspace_key;
+ALTER TABLE teams ADD CONSTRAINT teams_key_key UNIQUE (key);
+
+DROP TABLE IF EXISTS workspace_members;
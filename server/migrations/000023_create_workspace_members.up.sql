@@ -0,0 +1,41 @@
+-- 多工作区：用户通过成员关系加入多个工作区，每个工作区拥有独立的角色
+CREATE TABLE workspace_members (
+    workspace_id UUID NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL DEFAULT 'member',
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (workspace_id, user_id)
+);
+
+CREATE INDEX idx_workspace_members_user_id ON workspace_members(user_id);
+
+-- 已有用户成为主工作区的成员
+INSERT INTO workspace_members (workspace_id, user_id, role, joined_at)
+SELECT workspace_id, id, role, created_at FROM users;
+
+-- 团队 Key 改为工作区内唯一
+ALTER TABLE teams DROP CONSTRAINT IF EXISTS teams_key_key;
+CREATE UNIQUE INDEX idx_teams_workspace_key ON teams(workspace_id, key);
+
+-- 历史标识符和历史 Key 同样按工作区区分
+ALTER TABLE team_key_aliases ADD COLUMN workspace_id UUID;
+UPDATE team_key_aliases SET workspace_id = teams.workspace_id FROM teams WHERE teams.id = team_key_aliases.team_id;
+ALTER TABLE team_key_aliases ALTER COLUMN workspace_id SET NOT NULL;
+DROP INDEX IF EXISTS idx_team_key_aliases_key;
+CREATE UNIQUE INDEX idx_team_key_aliases_key ON team_key_aliases(workspace_id, key);
+
+-- Issue 只能在同一工作区内移动，历史标识符属于 Issue 当前团队所在的工作区
+ALTER TABLE issue_identifier_aliases ADD COLUMN workspace_id UUID;
+UPDATE issue_identifier_aliases SET workspace_id = teams.workspace_id
+FROM issues JOIN teams ON teams.id = issues.team_id
+WHERE issues.id = issue_identifier_aliases.issue_id;
+ALTER TABLE issue_identifier_aliases ALTER COLUMN workspace_id SET NOT NULL;
+DROP INDEX IF EXISTS idx_issue_alias_identifier;
+CREATE UNIQUE INDEX idx_issue_alias_identifier ON issue_identifier_aliases(workspace_id, team_key, number);
+
+-- 登录会话记录当前所在的工作区，切换工作区后刷新令牌仍留在该工作区
+ALTER TABLE user_sessions ADD COLUMN workspace_id UUID REFERENCES workspaces(id) ON DELETE SET NULL;
+
+COMMENT ON TABLE workspace_members IS '工作区成员';
+COMMENT ON COLUMN workspace_members.role IS '成员在该工作区的角色；主工作区以 users.role 为准';
+COMMENT ON COLUMN user_sessions.workspace_id IS '会话当前所在的工作区，为空时使用用户的主工作区';
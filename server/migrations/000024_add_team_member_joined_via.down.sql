@@ -0,0 +1,2 @@
+-- 回滚私有团队成员加入方式
+ALTER TABLE team_members DROP COLUMN IF EXISTS joined_via;
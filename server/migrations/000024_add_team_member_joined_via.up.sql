@@ -0,0 +1,2 @@
+-- 私有团队：记录成员加入团队的方式，管理员主动加入私有团队时为 admin_join
+ALTER TABLE team_members ADD COLUMN joined_via VARCHAR(20) NOT NULL DEFAULT 'added';
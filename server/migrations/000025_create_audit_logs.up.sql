@@ -0,0 +1,26 @@
+-- 审计日志：记录管理和安全相关操作，只允许追加（过期记录由保留策略清理）
+-- 不使用外键，用户、团队删除后仍保留审计记录
+CREATE TABLE audit_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    workspace_id UUID,
+    actor_id UUID,
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32) NOT NULL DEFAULT '',
+    target_id VARCHAR(64) NOT NULL DEFAULT '',
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    changes JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_logs_workspace_created ON audit_logs(workspace_id, created_at DESC);
+CREATE INDEX idx_audit_logs_actor_id ON audit_logs(actor_id);
+CREATE INDEX idx_audit_logs_action ON audit_logs(action);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs(created_at);
+
+-- 审计记录写入后不允许修改
+CREATE RULE audit_logs_no_update AS ON UPDATE TO audit_logs DO INSTEAD NOTHING;
+
+COMMENT ON TABLE audit_logs IS '审计日志';
+COMMENT ON COLUMN audit_logs.actor_id IS '操作者，登录失败且用户不存在时为空';
+COMMENT ON COLUMN audit_logs.changes IS '变更前后的值：{"before": {...}, "after": {...}}';